@@ -3,18 +3,21 @@ module booking
 go 1.21.3
 
 require (
+	github.com/XANi/loremipsum v1.1.0
+	github.com/go-pdf/fpdf v0.9.0
 	github.com/gofiber/fiber/v2 v2.52.1
 	github.com/gofiber/storage/memory/v2 v2.0.0
 	github.com/gofiber/storage/redis/v3 v3.1.0
 	github.com/golang-jwt/jwt/v5 v5.2.0
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
 	github.com/spf13/viper v1.18.2
 	github.com/stretchr/testify v1.8.4
+	github.com/teambition/rrule-go v1.8.2
 	go.uber.org/zap v1.27.0
 	gorm.io/driver/postgres v1.5.6
 )
 
 require (
-	github.com/XANi/loremipsum v1.1.0 // indirect
 	github.com/cespare/xxhash/v2 v2.2.0 // indirect
 	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc // indirect
 	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
@@ -5,10 +5,22 @@ import (
 	"booking/internal/cache"
 	"booking/internal/controller"
 	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/ical"
+	"booking/internal/invoice"
+	"booking/internal/logs"
+	"booking/internal/notification"
+	"booking/internal/payment"
 	"booking/internal/repository"
 	"booking/internal/service"
+	"booking/internal/storage"
 	"booking/internal/util"
+	"booking/middleware"
 	"booking/middleware/validator"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
 
 	"github.com/gofiber/contrib/fiberi18n/v2"
 	"github.com/gofiber/fiber/v2"
@@ -17,18 +29,31 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/spf13/viper"
 	"gorm.io/gorm"
 )
 
 var app *fiber.App
 
+// MULTIPART_OVERHEAD_BYTES is the room for the fields and boundaries of a
+// multipart upload besides its file.
+const MULTIPART_OVERHEAD_BYTES = 1 << 20
+
 func GetApp(cache cache.ICache, db *gorm.DB) *fiber.App {
 
 	if app != nil {
 		return app
 	}
 
-	app = fiber.New(config.AppConfig)
+	appConfig := config.AppConfig
+	// media uploads are read whole, with room left for the rest of the form
+	appConfig.BodyLimit = max(fiber.DefaultBodyLimit, viper.GetInt("media.max_upload_bytes")+MULTIPART_OVERHEAD_BYTES)
+	// the fake payment provider keeps its intents in the memory of the
+	// process, which the children of a prefork don't share
+	if viper.GetString("payment.provider") == payment.FAKE_PROVIDER {
+		appConfig.Prefork = false
+	}
+	app = fiber.New(appConfig)
 	app.Use(recover.New())
 	app.Use(fiberi18n.New(config.I18nConfig))
 	app.Use(compress.New(config.CompressConfig))
@@ -50,5 +75,328 @@ func GetApp(cache cache.ICache, db *gorm.DB) *fiber.App {
 	auth.Post("/register", validator.BodyValidator[dto.RegisterDTO](), authCtrl.Register)
 	auth.Post("/login", validator.BodyValidator[dto.LoginDTO](), authCtrl.Login)
 	auth.Post("/refresh-token", validator.BodyValidator[dto.RefreshTokenDTO](), authCtrl.RefreshToken)
+
+	verifyUser := middleware.VerifyUser(cache, util)
+
+	// resources and everything about them belong to an organization, the
+	// requests about them naming it in a header that scopes their queries
+	organizations := v1.Group("/organizations", verifyUser)
+	organizationRepository := repository.NewOrganizationRepository(db)
+	organizationService := service.NewOrganizationService(cache, authRepository, organizationRepository)
+	organizationCtrl := controller.NewOrganizationController(organizationService)
+	organizations.Post("/", validator.BodyValidator[dto.CreateOrganizationDTO](), organizationCtrl.CreateOrganization)
+	organizations.Get("/", organizationCtrl.GetOrganizations)
+	organizations.Put("/:id/members", validator.ParamValidator[dto.IDParamDTO](), validator.BodyValidator[dto.SaveOrganizationMemberDTO](), organizationCtrl.SaveOrganizationMember)
+	organizations.Delete("/:id/members/:userId", validator.ParamValidator[dto.OrganizationMemberParamDTO](), organizationCtrl.RemoveOrganizationMember)
+	verifyOrganization := middleware.VerifyOrganization(cache, authRepository, organizationRepository)
+	requireOrganizationAdmin := middleware.RequireOrganizationRole(entity.ORGANIZATION_ROLE_OWNER, entity.ORGANIZATION_ROLE_ADMIN)
+
+	resources := v1.Group("/resources", verifyUser, verifyOrganization)
+	resourceRepository := repository.NewResourceRepository(db)
+	scheduleRepository := repository.NewScheduleRepository(db)
+	resourceService := service.NewResourceService(authRepository, resourceRepository, scheduleRepository)
+	resourceCtrl := controller.NewResourceController(resourceService)
+	resources.Post("/", requireOrganizationAdmin, validator.BodyValidator[dto.CreateResourceDTO](), resourceCtrl.CreateResource)
+	resources.Get("/search", validator.QueryValidator[dto.ResourceSearchQueryDTO](), resourceCtrl.SearchResources)
+	resources.Get("/:id", validator.ParamValidator[dto.IDParamDTO](), resourceCtrl.GetResource)
+	resources.Get("/:id/cancellation-policy", validator.ParamValidator[dto.IDParamDTO](), resourceCtrl.GetCancellationPolicy)
+	resources.Put("/:id/cancellation-policy", requireOrganizationAdmin, validator.ParamValidator[dto.IDParamDTO](), validator.BodyValidator[dto.CancellationPolicyDTO](), resourceCtrl.SaveCancellationPolicy)
+	resources.Get("/:id/reschedule-policy", validator.ParamValidator[dto.IDParamDTO](), resourceCtrl.GetReschedulePolicy)
+	resources.Put("/:id/reschedule-policy", requireOrganizationAdmin, validator.ParamValidator[dto.IDParamDTO](), validator.BodyValidator[dto.ReschedulePolicyDTO](), resourceCtrl.SaveReschedulePolicy)
+	resources.Get("/:id/payment-schedule", validator.ParamValidator[dto.IDParamDTO](), resourceCtrl.GetPaymentSchedule)
+	resources.Put("/:id/payment-schedule", requireOrganizationAdmin, validator.ParamValidator[dto.IDParamDTO](), validator.BodyValidator[dto.PaymentScheduleDTO](), resourceCtrl.SavePaymentSchedule)
+
+	scheduleService := service.NewScheduleService(authRepository, resourceRepository, scheduleRepository)
+	scheduleCtrl := controller.NewScheduleController(scheduleService)
+	resources.Get("/:id/schedule", validator.ParamValidator[dto.IDParamDTO](), scheduleCtrl.GetSchedule)
+	resources.Put("/:id/opening-hours", requireOrganizationAdmin, validator.ParamValidator[dto.IDParamDTO](), validator.BodyValidator[dto.UpdateOpeningHoursDTO](), scheduleCtrl.UpdateOpeningHours)
+	resources.Put("/:id/date-overrides/:date", requireOrganizationAdmin, validator.ParamValidator[dto.DateParamDTO](), validator.BodyValidator[dto.DateOverrideDTO](), scheduleCtrl.SaveDateOverride)
+	resources.Delete("/:id/date-overrides/:date", requireOrganizationAdmin, validator.ParamValidator[dto.DateParamDTO](), scheduleCtrl.DeleteDateOverride)
+	resources.Post("/:id/blackouts", requireOrganizationAdmin, validator.ParamValidator[dto.IDParamDTO](), validator.BodyValidator[dto.CreateBlackoutPeriodDTO](), scheduleCtrl.CreateBlackoutPeriod)
+	resources.Delete("/:id/blackouts/:blackoutId", requireOrganizationAdmin, validator.ParamValidator[dto.BlackoutParamDTO](), scheduleCtrl.DeleteBlackoutPeriod)
+
+	// calendars the resources are also managed in block them where busy, those
+	// with a URL being synced in the background, fetched from public
+	// addresses only
+	externalCalendarRepository := repository.NewExternalCalendarRepository(db)
+	calendarClient := ical.NewClient(viper.GetDuration("calendar.fetch_timeout_seconds") * time.Second)
+	externalCalendarService := service.NewExternalCalendarService(authRepository, resourceRepository, externalCalendarRepository, calendarClient)
+	externalCalendarCtrl := controller.NewExternalCalendarController(externalCalendarService)
+	resources.Get("/:id/external-calendars", requireOrganizationAdmin, validator.ParamValidator[dto.IDParamDTO](), externalCalendarCtrl.GetExternalCalendars)
+	resources.Post("/:id/external-calendars", requireOrganizationAdmin, validator.ParamValidator[dto.IDParamDTO](), validator.BodyValidator[dto.CreateExternalCalendarDTO](), externalCalendarCtrl.CreateExternalCalendar)
+	resources.Post("/:id/external-calendars/upload", requireOrganizationAdmin, validator.ParamValidator[dto.IDParamDTO](), validator.BodyValidator[dto.UploadExternalCalendarDTO](), externalCalendarCtrl.UploadExternalCalendar)
+	resources.Post("/:id/external-calendars/:calendarId/sync", requireOrganizationAdmin, validator.ParamValidator[dto.ExternalCalendarParamDTO](), externalCalendarCtrl.SyncExternalCalendar)
+	resources.Delete("/:id/external-calendars/:calendarId", requireOrganizationAdmin, validator.ParamValidator[dto.ExternalCalendarParamDTO](), externalCalendarCtrl.DeleteExternalCalendar)
+
+	promoCodeRepository := repository.NewPromoCodeRepository(db)
+	pricingService := service.NewPricingService(authRepository, resourceRepository, promoCodeRepository)
+	pricingCtrl := controller.NewPricingController(pricingService)
+	resources.Get("/:id/pricing-policy", validator.ParamValidator[dto.IDParamDTO](), pricingCtrl.GetPricingPolicy)
+	resources.Put("/:id/pricing-policy", requireOrganizationAdmin, validator.ParamValidator[dto.IDParamDTO](), validator.BodyValidator[dto.PricingPolicyDTO](), pricingCtrl.SavePricingPolicy)
+	resources.Post("/:id/members", requireOrganizationAdmin, validator.ParamValidator[dto.IDParamDTO](), validator.BodyValidator[dto.AddResourceMemberDTO](), pricingCtrl.AddResourceMember)
+	resources.Delete("/:id/members/:userId", requireOrganizationAdmin, validator.ParamValidator[dto.MemberParamDTO](), pricingCtrl.RemoveResourceMember)
+
+	quotes := v1.Group("/quotes", verifyUser, verifyOrganization)
+	quotes.Post("/", validator.BodyValidator[dto.CreateQuoteDTO](), pricingCtrl.CreateQuote)
+
+	promoCodes := v1.Group("/promo-codes", verifyUser, verifyOrganization)
+	promoCodeService := service.NewPromoCodeService(authRepository, resourceRepository, promoCodeRepository)
+	promoCodeCtrl := controller.NewPromoCodeController(promoCodeService)
+	promoCodes.Post("/", requireOrganizationAdmin, validator.BodyValidator[dto.CreatePromoCodeDTO](), promoCodeCtrl.CreatePromoCode)
+	promoCodes.Get("/", requireOrganizationAdmin, promoCodeCtrl.GetPromoCodes)
+
+	holidayCalendars := v1.Group("/holiday-calendars", verifyUser)
+	holidayCalendars.Get("/:code/holidays", validator.ParamValidator[dto.HolidayCalendarParamDTO](), validator.QueryValidator[dto.HolidayQueryDTO](), scheduleCtrl.GetHolidays)
+
+	bookings := v1.Group("/bookings", verifyUser, verifyOrganization)
+	bookingRepository := repository.NewBookingRepository(db)
+	waitlistRepository := repository.NewWaitlistRepository(db)
+	paymentRepository := repository.NewPaymentRepository(db)
+	ledgerRepository := repository.NewLedgerRepository(db)
+	notificationRepository := repository.NewNotificationRepository(db)
+	providerRepository := repository.NewProviderRepository(db)
+	promptPayProvider := payment.NewPromptPayProvider(viper.GetString("payment.promptpay.id"), viper.GetString("payment.promptpay.secret"))
+	paymentProvider := newPaymentProvider(promptPayProvider)
+	bookingService := service.NewBookingService(authRepository, resourceRepository, bookingRepository, waitlistRepository, scheduleRepository, providerRepository, promoCodeRepository, paymentRepository, ledgerRepository, notificationRepository, paymentProvider)
+	bookingCtrl := controller.NewBookingController(bookingService)
+	bookings.Post("/", validator.BodyValidator[dto.CreateBookingDTO](), bookingCtrl.CreateBooking)
+	bookings.Get("/:id", validator.ParamValidator[dto.IDParamDTO](), bookingCtrl.GetBooking)
+	bookings.Post("/:id/cancel", validator.ParamValidator[dto.IDParamDTO](), validator.BodyValidator[dto.CancelBookingDTO](), bookingCtrl.CancelBooking)
+	bookings.Post("/:id/reschedule", validator.ParamValidator[dto.IDParamDTO](), validator.BodyValidator[dto.RescheduleBookingDTO](), bookingCtrl.RescheduleBooking)
+	bookings.Get("/:id/history", validator.ParamValidator[dto.IDParamDTO](), bookingCtrl.GetBookingHistory)
+	resources.Get("/:id/availability", validator.ParamValidator[dto.IDParamDTO](), validator.QueryValidator[dto.AvailabilityQueryDTO](), bookingCtrl.GetAvailability)
+	v1.Get("/no-shows", verifyUser, verifyOrganization, bookingCtrl.GetNoShowRecord)
+
+	providers := v1.Group("/providers", verifyUser, verifyOrganization)
+	providerService := service.NewProviderService(authRepository, resourceRepository, bookingRepository, waitlistRepository, scheduleRepository, providerRepository)
+	providerCtrl := controller.NewProviderController(providerService)
+	providers.Post("/", requireOrganizationAdmin, validator.BodyValidator[dto.CreateProviderDTO](), providerCtrl.CreateProvider)
+	providers.Get("/", providerCtrl.GetProviders)
+	providers.Get("/:id", validator.ParamValidator[dto.IDParamDTO](), providerCtrl.GetProvider)
+	providers.Put("/:id/hours", requireOrganizationAdmin, validator.ParamValidator[dto.IDParamDTO](), validator.BodyValidator[dto.UpdateProviderHoursDTO](), providerCtrl.UpdateProviderHours)
+	providers.Post("/:id/time-off", requireOrganizationAdmin, validator.ParamValidator[dto.IDParamDTO](), validator.BodyValidator[dto.CreateProviderTimeOffDTO](), providerCtrl.CreateProviderTimeOff)
+	providers.Delete("/:id/time-off/:timeOffId", requireOrganizationAdmin, validator.ParamValidator[dto.ProviderTimeOffParamDTO](), providerCtrl.DeleteProviderTimeOff)
+	providers.Put("/:id/services/:serviceId", requireOrganizationAdmin, validator.ParamValidator[dto.ProviderServiceParamDTO](), validator.BodyValidator[dto.SaveProviderServiceDTO](), providerCtrl.SaveProviderService)
+	providers.Delete("/:id/services/:serviceId", requireOrganizationAdmin, validator.ParamValidator[dto.ProviderServiceParamDTO](), providerCtrl.RemoveProviderService)
+	services := v1.Group("/services", verifyUser, verifyOrganization)
+	services.Post("/", requireOrganizationAdmin, validator.BodyValidator[dto.CreateServiceDTO](), providerCtrl.CreateService)
+	services.Get("/", providerCtrl.GetServices)
+	services.Get("/:id/slots", validator.ParamValidator[dto.IDParamDTO](), validator.QueryValidator[dto.AppointmentSlotQueryDTO](), providerCtrl.GetAppointmentSlots)
+
+	bookingSeries := v1.Group("/booking-series", verifyUser, verifyOrganization)
+	bookingSeriesService := service.NewBookingSeriesService(authRepository, resourceRepository, bookingRepository, waitlistRepository, scheduleRepository)
+	bookingSeriesCtrl := controller.NewBookingSeriesController(bookingSeriesService)
+	bookingSeries.Post("/", validator.BodyValidator[dto.CreateBookingSeriesDTO](), bookingSeriesCtrl.CreateBookingSeries)
+	bookingSeries.Get("/:id", validator.ParamValidator[dto.IDParamDTO](), bookingSeriesCtrl.GetBookingSeries)
+	bookingSeries.Put("/:id", validator.ParamValidator[dto.IDParamDTO](), validator.BodyValidator[dto.UpdateBookingSeriesDTO](), bookingSeriesCtrl.UpdateBookingSeries)
+	bookingSeries.Post("/:id/cancel", validator.ParamValidator[dto.IDParamDTO](), validator.BodyValidator[dto.CancelBookingSeriesDTO](), bookingSeriesCtrl.CancelBookingSeries)
+
+	waitlist := v1.Group("/waitlist", verifyUser, verifyOrganization)
+	waitlistService := service.NewWaitlistService(authRepository, resourceRepository, bookingRepository, waitlistRepository, scheduleRepository, paymentRepository, ledgerRepository, notificationRepository, paymentProvider)
+	waitlistCtrl := controller.NewWaitlistController(waitlistService)
+	waitlist.Post("/", validator.BodyValidator[dto.JoinWaitlistDTO](), waitlistCtrl.JoinWaitlist)
+	waitlist.Get("/", waitlistCtrl.GetWaitlistEntries)
+	waitlist.Delete("/:id", validator.ParamValidator[dto.IDParamDTO](), waitlistCtrl.LeaveWaitlist)
+	waitlist.Post("/:id/claim", validator.ParamValidator[dto.IDParamDTO](), waitlistCtrl.ClaimOffer)
+
+	// the webhook is called by the payment provider and authenticated by its
+	// signature
+	payments := v1.Group("/payments")
+	paymentService := service.NewPaymentService(authRepository, bookingRepository, waitlistRepository, scheduleRepository, paymentRepository, ledgerRepository, notificationRepository, paymentProvider, promptPayProvider)
+	paymentCtrl := controller.NewPaymentController(paymentService)
+	payments.Post("/webhook", paymentCtrl.HandleWebhook)
+	payments.Post("/promptpay/reconcile", paymentCtrl.ReconcilePromptPay)
+	payments.Get("/:id/qr.png", verifyUser, verifyOrganization, validator.ParamValidator[dto.IDParamDTO](), paymentCtrl.GetPaymentQRCode)
+	// payments of the fake provider are completed by the customer, which is
+	// only for development and tests
+	if paymentProvider.Name() == payment.FAKE_PROVIDER {
+		payments.Post("/:intentId/simulate", verifyUser, verifyOrganization, validator.ParamValidator[dto.IntentParamDTO](), validator.BodyValidator[dto.SimulatePaymentDTO](), paymentCtrl.SimulatePayment)
+	}
+	bookings.Get("/:id/payments", validator.ParamValidator[dto.IDParamDTO](), paymentCtrl.GetBookingPayments)
+	bookings.Post("/:id/payments", validator.ParamValidator[dto.IDParamDTO](), validator.BodyValidator[dto.CreatePaymentDTO](), paymentCtrl.CreatePayment)
+	bookings.Post("/:id/promptpay", validator.ParamValidator[dto.IDParamDTO](), paymentCtrl.CreatePromptPayPayment)
+
+	billingProfile := v1.Group("/billing-profile", verifyUser)
+	taxDocumentRepository := repository.NewTaxDocumentRepository(db)
+	invoiceRenderer, err := invoice.NewRenderer(viper.GetString("invoice.font_path"))
+	if err != nil {
+		logs.Error(err)
+	}
+	invoiceService := service.NewInvoiceService(authRepository, resourceRepository, bookingRepository, taxDocumentRepository, invoiceRenderer)
+	invoiceCtrl := controller.NewInvoiceController(invoiceService)
+	billingProfile.Get("/", invoiceCtrl.GetBillingProfile)
+	billingProfile.Put("/", validator.BodyValidator[dto.BillingProfileDTO](), invoiceCtrl.SaveBillingProfile)
+	// the organization issues the tax documents of the bookings of its
+	// resources
+	billingProfile.Get("/organization", verifyOrganization, requireOrganizationAdmin, invoiceCtrl.GetOrganizationBillingProfile)
+	billingProfile.Put("/organization", verifyOrganization, requireOrganizationAdmin, validator.BodyValidator[dto.BillingProfileDTO](), invoiceCtrl.SaveOrganizationBillingProfile)
+	bookings.Get("/:id/invoice", validator.ParamValidator[dto.IDParamDTO](), validator.QueryValidator[dto.InvoiceQueryDTO](), invoiceCtrl.GetBookingInvoice)
+
+	// the feed is read by calendar applications which can't log in, its
+	// secret token is what authenticates it
+	calendar := v1.Group("/calendar")
+	calendarRepository := repository.NewCalendarRepository(db)
+	calendarService := service.NewCalendarService(authRepository, resourceRepository, bookingRepository, calendarRepository)
+	calendarCtrl := controller.NewCalendarController(calendarService)
+	calendar.Get("/feed", verifyUser, calendarCtrl.GetCalendarFeed)
+	calendar.Post("/feed", verifyUser, calendarCtrl.CreateCalendarFeed)
+	calendar.Get("/feeds/:token.ics", validator.ParamValidator[dto.CalendarTokenParamDTO](), calendarCtrl.GetFeedCalendar)
+	bookings.Get("/:id/calendar.ics", validator.ParamValidator[dto.IDParamDTO](), calendarCtrl.GetBookingCalendar)
+
+	// booking changes queue their notifications in the outbox, which is sent
+	// in the background and retried until delivered
+	httpClient := &http.Client{Timeout: viper.GetDuration("calendar.fetch_timeout_seconds") * time.Second}
+	notificationService := service.NewNotificationService(authRepository, resourceRepository, bookingRepository, notificationRepository, notificationSenders(httpClient)...)
+	notificationCtrl := controller.NewNotificationController(notificationService)
+	notificationPreferences := v1.Group("/notification-preferences", verifyUser)
+	notificationPreferences.Get("/", notificationCtrl.GetNotificationPreference)
+	notificationPreferences.Put("/", validator.BodyValidator[dto.NotificationPreferenceDTO](), notificationCtrl.SaveNotificationPreference)
+
+	// confirmed bookings carry a signed code, shown as a QR code, that staff
+	// of the resource scan to check the customer in on the day
+	checkInService := service.NewCheckInService(authRepository, resourceRepository, bookingRepository)
+	checkInCtrl := controller.NewCheckInController(checkInService)
+	bookings.Get("/:id/checkin-code", validator.ParamValidator[dto.IDParamDTO](), checkInCtrl.GetCheckInCode)
+	bookings.Get("/:id/checkin-code.png", validator.ParamValidator[dto.IDParamDTO](), checkInCtrl.GetCheckInQRCode)
+	resources.Post("/:id/staff", requireOrganizationAdmin, validator.ParamValidator[dto.IDParamDTO](), validator.BodyValidator[dto.AddResourceStaffDTO](), checkInCtrl.AddResourceStaff)
+	resources.Delete("/:id/staff/:userId", requireOrganizationAdmin, validator.ParamValidator[dto.StaffParamDTO](), checkInCtrl.RemoveResourceStaff)
+	checkins := v1.Group("/checkins", verifyUser, verifyOrganization)
+	checkins.Post("/", validator.BodyValidator[dto.CheckInDTO](), checkInCtrl.CheckIn)
+
+	// customers review the resources of their completed bookings, the rating
+	// of each resource following its published reviews
+	reviews := v1.Group("/reviews", verifyUser, verifyOrganization)
+	reviewRepository := repository.NewReviewRepository(db)
+	reviewService := service.NewReviewService(authRepository, resourceRepository, bookingRepository, reviewRepository)
+	reviewCtrl := controller.NewReviewController(reviewService)
+	bookings.Post("/:id/review", validator.ParamValidator[dto.IDParamDTO](), validator.BodyValidator[dto.CreateReviewDTO](), reviewCtrl.CreateReview)
+	resources.Get("/:id/reviews", validator.ParamValidator[dto.IDParamDTO](), validator.QueryValidator[dto.ReviewQueryDTO](), reviewCtrl.GetResourceReviews)
+	reviews.Put("/:id/reply", requireOrganizationAdmin, validator.ParamValidator[dto.IDParamDTO](), validator.BodyValidator[dto.ReplyReviewDTO](), reviewCtrl.ReplyReview)
+	reviews.Put("/:id/status", requireOrganizationAdmin, validator.ParamValidator[dto.IDParamDTO](), validator.BodyValidator[dto.ModerateReviewDTO](), reviewCtrl.ModerateReview)
+
+	// the photos and floor plans of resources are kept in the blob store,
+	// their files read through the media URLs, which are signed for
+	// private files since downloads don't log in
+	media := v1.Group("/media")
+	mediaRepository := repository.NewMediaRepository(db)
+	mediaService := service.NewMediaService(authRepository, resourceRepository, mediaRepository, blobStore())
+	mediaCtrl := controller.NewMediaController(mediaService)
+	resources.Post("/:id/media", requireOrganizationAdmin, validator.ParamValidator[dto.IDParamDTO](), validator.BodyValidator[dto.CreateMediaDTO](), mediaCtrl.UploadMedia)
+	resources.Get("/:id/media", validator.ParamValidator[dto.IDParamDTO](), mediaCtrl.GetResourceMedia)
+	resources.Put("/:id/media/order", requireOrganizationAdmin, validator.ParamValidator[dto.IDParamDTO](), validator.BodyValidator[dto.ReorderMediaDTO](), mediaCtrl.ReorderMedia)
+	resources.Delete("/:id/media/:mediaId", requireOrganizationAdmin, validator.ParamValidator[dto.MediaParamDTO](), mediaCtrl.DeleteMedia)
+	media.Get("/:id/file", validator.ParamValidator[dto.IDParamDTO](), validator.QueryValidator[dto.MediaFileQueryDTO](), mediaCtrl.GetMediaFile)
+	media.Get("/:id/thumbnail", validator.ParamValidator[dto.IDParamDTO](), validator.QueryValidator[dto.MediaFileQueryDTO](), mediaCtrl.GetMediaThumbnail)
+
+	// the ledger of the organization holds the money of the bookings of its
+	// resources
+	ledger := v1.Group("/ledger", verifyUser, verifyOrganization, requireOrganizationAdmin)
+	ledgerService := service.NewLedgerService(authRepository, ledgerRepository)
+	ledgerCtrl := controller.NewLedgerController(ledgerService)
+	ledger.Get("/balances", validator.QueryValidator[dto.LedgerBalanceQueryDTO](), ledgerCtrl.GetBalances)
+	ledger.Get("/accounts/:code/statement", validator.ParamValidator[dto.LedgerAccountParamDTO](), validator.QueryValidator[dto.LedgerStatementQueryDTO](), ledgerCtrl.GetStatement)
+
+	// background work runs as jobs in the database rather than in-process
+	// timers, so that each run is done once however many processes serve
+	// the API, a process that dies while running a job leaving it to another
+	// once its lease expires
+	jobRepository := repository.NewJobRepository(db)
+	hostname, _ := os.Hostname()
+	jobService := service.NewJobService(authRepository, jobRepository, fmt.Sprintf("%s:%d", hostname, os.Getpid()))
+	jobCtrl := controller.NewJobController(jobService)
+	registerJobs(jobService, bookingService, notificationService, externalCalendarService)
+	if interval := viper.GetDuration("jobs.poll_seconds") * time.Second; interval > 0 {
+		go func() {
+			for now := range time.Tick(interval) {
+				err := jobService.RunJobs(now)
+				if err != nil {
+					logs.Error(err)
+				}
+			}
+		}()
+	}
+	admin := v1.Group("/admin", verifyUser)
+	admin.Get("/jobs", validator.QueryValidator[dto.JobQueryDTO](), jobCtrl.GetJobs)
+	admin.Post("/jobs/:id/retry", validator.ParamValidator[dto.IDParamDTO](), jobCtrl.RetryJob)
 	return app
 }
+
+// newPaymentProvider returns the configured provider of the payments of
+// bookings, PromptPay unless the fake provider is asked for. The secret
+// signing its webhooks must be configured.
+func newPaymentProvider(promptPayProvider payment.IPaymentProvider) payment.IPaymentProvider {
+	switch name := viper.GetString("payment.provider"); name {
+	case payment.FAKE_PROVIDER:
+		if viper.GetString("payment.webhook_secret") == "" {
+			panic("Missing payment.webhook_secret")
+		}
+		return payment.NewFakeProvider(viper.GetString("payment.webhook_secret"))
+	case payment.PROMPTPAY_PROVIDER, "":
+		if viper.GetString("payment.promptpay.secret") == "" {
+			panic("Missing payment.promptpay.secret")
+		}
+		return promptPayProvider
+	default:
+		panic("Unknown payment provider " + name)
+	}
+}
+
+// notificationSenders returns the configured driver of each channel, the
+// fake one logging messages unless another is configured.
+func notificationSenders(httpClient *http.Client) []notification.ISender {
+	senders := []notification.ISender{}
+	if viper.GetString("notification.email.driver") == "smtp" {
+		senders = append(senders, notification.NewSMTPSender(viper.GetString("notification.email.host"), viper.GetInt("notification.email.port"), viper.GetString("notification.email.username"), viper.GetString("notification.email.password"), viper.GetString("notification.email.from")))
+	} else {
+		senders = append(senders, notification.NewFakeSender(notification.CHANNEL_EMAIL))
+	}
+	if viper.GetString("notification.sms.driver") == "http" {
+		senders = append(senders, notification.NewSMSSender(httpClient, viper.GetString("notification.sms.url"), viper.GetString("notification.sms.api_key"), viper.GetString("notification.sms.from")))
+	} else {
+		senders = append(senders, notification.NewFakeSender(notification.CHANNEL_SMS))
+	}
+	if viper.GetString("notification.line.driver") == "line" {
+		senders = append(senders, notification.NewLineSender(httpClient, notification.LINE_API_URL, viper.GetString("notification.line.channel_access_token")))
+	} else {
+		senders = append(senders, notification.NewFakeSender(notification.CHANNEL_LINE))
+	}
+	return senders
+}
+
+// blobStore returns the configured store of uploaded files, a directory of
+// the local filesystem unless an S3-compatible store is configured.
+func blobStore() storage.BlobStore {
+	if viper.GetString("media.driver") == "s3" {
+		return storage.NewS3Store(&http.Client{}, viper.GetString("media.s3.endpoint"), viper.GetString("media.s3.region"), viper.GetString("media.s3.bucket"), viper.GetString("media.s3.access_key"), viper.GetString("media.s3.secret_key"))
+	}
+	return storage.NewLocalStore(viper.GetString("media.local.path"))
+}
+
+// registerJobs schedules the periodic background jobs that are turned on.
+func registerJobs(jobService service.IJobService, bookingService service.IBookingService, notificationService service.INotificationService, externalCalendarService service.IExternalCalendarService) {
+	run := func(task func(now time.Time) error) service.JobHandler {
+		return func(job *entity.Job, now time.Time) error {
+			return task(now)
+		}
+	}
+	if interval := viper.GetDuration("notification.dispatch_seconds") * time.Second; interval > 0 {
+		jobService.Every(service.JOB_KIND_DISPATCH_NOTIFICATIONS, interval, run(notificationService.DispatchNotifications))
+		jobService.Every(service.JOB_KIND_SEND_REMINDERS, time.Minute, run(notificationService.SendReminders))
+	}
+	if viper.GetInt("booking.hold_minutes") > 0 {
+		jobService.Every(service.JOB_KIND_EXPIRE_HOLDS, time.Minute, run(bookingService.ExpireStaleHolds))
+	}
+	if viper.GetInt("booking.no_show.window_days") > 0 {
+		jobService.Every(service.JOB_KIND_MARK_NO_SHOWS, time.Minute, run(bookingService.MarkNoShows))
+	}
+	// each booking is locked while it is handled, so a run taking longer than
+	// its interval doesn't handle it twice
+	if interval := viper.GetDuration("payment.overdue_check_minutes") * time.Minute; interval > 0 {
+		jobService.Every(service.JOB_KIND_HANDLE_OVERDUE_BOOKINGS, interval, run(bookingService.HandleOverdueBookings))
+	}
+	// calendars due for a sync are looked for every minute
+	if viper.GetInt("calendar.sync_minutes") > 0 {
+		jobService.Every(service.JOB_KIND_SYNC_EXTERNAL_CALENDARS, time.Minute, run(externalCalendarService.SyncExternalCalendars))
+	}
+	jobService.Every(service.JOB_KIND_DELETE_FINISHED_JOBS, time.Hour, run(jobService.DeleteFinishedJobs))
+}
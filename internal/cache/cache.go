@@ -1,10 +1,17 @@
 package cache
 
 import (
+	"time"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 	"github.com/spf13/viper"
 )
 
+// noOrganizationRole is cached for users who aren't members of the
+// organization, as storages don't keep empty values.
+const noOrganizationRole = "NONE"
+
 type cache struct {
 	redis fiber.Storage
 }
@@ -16,6 +23,12 @@ type ICache interface {
 	GetRefreshTokenKey(username string) string
 	GetAccessTokenString(username string) (string, error)
 	GetAccessTokenKey(username string) string
+	SaveOrganizationRole(organizationID uuid.UUID, username string, role string) error
+	// GetOrganizationRole returns whether the role is cached, the role being
+	// empty for users who aren't members of the organization.
+	GetOrganizationRole(organizationID uuid.UUID, username string) (string, bool, error)
+	DeleteOrganizationRole(organizationID uuid.UUID, username string) error
+	GetOrganizationRoleKey(organizationID uuid.UUID, username string) string
 }
 
 func New(redis fiber.Storage) *cache {
@@ -30,6 +43,13 @@ func (cache *cache) GetAccessTokenKey(username string) string {
 	return viper.GetString("redis.prefix_key.access_token") + ":" + username
 }
 
+// GetOrganizationRoleKey returns the key of the role of the user in the
+// organization, the organization being part of it so that roles of the same
+// user in different organizations don't collide.
+func (cache *cache) GetOrganizationRoleKey(organizationID uuid.UUID, username string) string {
+	return viper.GetString("redis.prefix_key.organization_role") + ":" + organizationID.String() + ":" + username
+}
+
 func (cache *cache) SaveRefreshTokenString(username string, refreshTokenString string) error {
 	return cache.redis.Set(
 		cache.GetRefreshTokenKey(username),
@@ -63,3 +83,30 @@ func (cache *cache) GetAccessTokenString(username string) (string, error) {
 
 	return string(accessTokenBytes), nil
 }
+
+func (cache *cache) SaveOrganizationRole(organizationID uuid.UUID, username string, role string) error {
+	if role == "" {
+		role = noOrganizationRole
+	}
+	return cache.redis.Set(
+		cache.GetOrganizationRoleKey(organizationID, username),
+		[]byte(role),
+		viper.GetDuration("organization.role_cache_seconds")*time.Second,
+	)
+}
+
+func (cache *cache) GetOrganizationRole(organizationID uuid.UUID, username string) (string, bool, error) {
+	roleBytes, err := cache.redis.Get(cache.GetOrganizationRoleKey(organizationID, username))
+	if err != nil || roleBytes == nil {
+		return "", false, err
+	}
+	if string(roleBytes) == noOrganizationRole {
+		return "", true, nil
+	}
+
+	return string(roleBytes), true, nil
+}
+
+func (cache *cache) DeleteOrganizationRole(organizationID uuid.UUID, username string) error {
+	return cache.redis.Delete(cache.GetOrganizationRoleKey(organizationID, username))
+}
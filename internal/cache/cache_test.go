@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/gofiber/storage/memory/v2"
+	"github.com/google/uuid"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 )
@@ -95,3 +96,43 @@ func TestGetAccessTokenKey(t *testing.T) {
 
 	assert.Equal(t, PREFIX_KEY+":"+USERNAME, accessTokenKey)
 }
+
+func TestGetOrganizationRoleKey(t *testing.T) {
+	mockRedis := memory.New()
+	cache := cache.New(mockRedis)
+	viper.Set("redis.prefix_key.organization_role", PREFIX_KEY)
+	organizationID := uuid.New()
+
+	organizationRoleKey := cache.GetOrganizationRoleKey(organizationID, USERNAME)
+
+	assert.Equal(t, PREFIX_KEY+":"+organizationID.String()+":"+USERNAME, organizationRoleKey)
+}
+
+func TestOrganizationRole(t *testing.T) {
+	mockRedis := memory.New()
+	cache := cache.New(mockRedis)
+	viper.Set("organization.role_cache_seconds", 60)
+	viper.Set("redis.prefix_key.organization_role", PREFIX_KEY)
+	organizationID := uuid.New()
+	otherOrganizationID := uuid.New()
+
+	err := cache.SaveOrganizationRole(organizationID, USERNAME, "OWNER")
+	assert.Nil(t, err)
+	err = cache.SaveOrganizationRole(otherOrganizationID, USERNAME, "")
+	assert.Nil(t, err)
+
+	role, cached, err := cache.GetOrganizationRole(organizationID, USERNAME)
+	assert.Nil(t, err)
+	assert.True(t, cached)
+	assert.Equal(t, "OWNER", role)
+	role, cached, err = cache.GetOrganizationRole(otherOrganizationID, USERNAME)
+	assert.Nil(t, err)
+	assert.True(t, cached)
+	assert.Empty(t, role)
+
+	err = cache.DeleteOrganizationRole(organizationID, USERNAME)
+	assert.Nil(t, err)
+	_, cached, err = cache.GetOrganizationRole(organizationID, USERNAME)
+	assert.Nil(t, err)
+	assert.False(t, cached)
+}
@@ -1,10 +1,95 @@
 package constant
 
 const (
-	ERROR_MESSAGE_INVALID_PARAMETERS           = "ERROR_MESSAGE_INVALID_PARAMETERS"
-	ERROR_MESSAGE_INVALID_USERNAME_OR_PASSWORD = "ERROR_MESSAGE_INVALID_USERNAME_OR_PASSWORD"
-	ERROR_MESSAGE_INTERNAL_SERVER_ERROR        = "ERROR_MESSAGE_INTERNAL_SERVER_ERROR"
-	ERROR_MESSAGE_USERNAME_ALREADY_EXISTS      = "ERROR_MESSAGE_USERNAME_ALREADY_EXISTS"
-	ERROR_MESSAGE_UNAUTHORIZED                 = "ERROR_MESSAGE_UNAUTHORIZED"
-	ERROR_MESSAGE_TOKEN_EXPIRED                = "ERROR_MESSAGE_TOKEN_EXPIRED"
+	ERROR_MESSAGE_INVALID_PARAMETERS               = "ERROR_MESSAGE_INVALID_PARAMETERS"
+	ERROR_MESSAGE_INVALID_USERNAME_OR_PASSWORD     = "ERROR_MESSAGE_INVALID_USERNAME_OR_PASSWORD"
+	ERROR_MESSAGE_INTERNAL_SERVER_ERROR            = "ERROR_MESSAGE_INTERNAL_SERVER_ERROR"
+	ERROR_MESSAGE_USERNAME_ALREADY_EXISTS          = "ERROR_MESSAGE_USERNAME_ALREADY_EXISTS"
+	ERROR_MESSAGE_UNAUTHORIZED                     = "ERROR_MESSAGE_UNAUTHORIZED"
+	ERROR_MESSAGE_TOKEN_EXPIRED                    = "ERROR_MESSAGE_TOKEN_EXPIRED"
+	ERROR_MESSAGE_FORBIDDEN                        = "ERROR_MESSAGE_FORBIDDEN"
+	ERROR_MESSAGE_RESOURCE_NOT_FOUND               = "ERROR_MESSAGE_RESOURCE_NOT_FOUND"
+	ERROR_MESSAGE_BOOKING_NOT_FOUND                = "ERROR_MESSAGE_BOOKING_NOT_FOUND"
+	ERROR_MESSAGE_BOOKING_SLOT_UNAVAILABLE         = "ERROR_MESSAGE_BOOKING_SLOT_UNAVAILABLE"
+	ERROR_MESSAGE_BOOKING_IN_THE_PAST              = "ERROR_MESSAGE_BOOKING_IN_THE_PAST"
+	ERROR_MESSAGE_BOOKING_ALREADY_CANCELLED        = "ERROR_MESSAGE_BOOKING_ALREADY_CANCELLED"
+	ERROR_MESSAGE_BOOKING_ALREADY_STARTED          = "ERROR_MESSAGE_BOOKING_ALREADY_STARTED"
+	ERROR_MESSAGE_BOOKING_SERIES_NOT_FOUND         = "ERROR_MESSAGE_BOOKING_SERIES_NOT_FOUND"
+	ERROR_MESSAGE_BOOKING_SERIES_CONFLICT          = "ERROR_MESSAGE_BOOKING_SERIES_CONFLICT"
+	ERROR_MESSAGE_BOOKING_SERIES_DATE_CHANGED      = "ERROR_MESSAGE_BOOKING_SERIES_DATE_CHANGED"
+	ERROR_MESSAGE_INVALID_RECURRENCE_RULE          = "ERROR_MESSAGE_INVALID_RECURRENCE_RULE"
+	ERROR_MESSAGE_TOO_MANY_OCCURRENCES             = "ERROR_MESSAGE_TOO_MANY_OCCURRENCES"
+	ERROR_MESSAGE_BOOKING_SLOT_AVAILABLE           = "ERROR_MESSAGE_BOOKING_SLOT_AVAILABLE"
+	ERROR_MESSAGE_ALREADY_WAITLISTED               = "ERROR_MESSAGE_ALREADY_WAITLISTED"
+	ERROR_MESSAGE_WAITLIST_ENTRY_NOT_FOUND         = "ERROR_MESSAGE_WAITLIST_ENTRY_NOT_FOUND"
+	ERROR_MESSAGE_WAITLIST_ENTRY_CLOSED            = "ERROR_MESSAGE_WAITLIST_ENTRY_CLOSED"
+	ERROR_MESSAGE_WAITLIST_OFFER_NOT_AVAILABLE     = "ERROR_MESSAGE_WAITLIST_OFFER_NOT_AVAILABLE"
+	ERROR_MESSAGE_SEATS_EXCEED_CAPACITY            = "ERROR_MESSAGE_SEATS_EXCEED_CAPACITY"
+	ERROR_MESSAGE_RESOURCE_CLOSED                  = "ERROR_MESSAGE_RESOURCE_CLOSED"
+	ERROR_MESSAGE_RESOURCE_BLACKED_OUT             = "ERROR_MESSAGE_RESOURCE_BLACKED_OUT"
+	ERROR_MESSAGE_INVALID_OPENING_HOURS            = "ERROR_MESSAGE_INVALID_OPENING_HOURS"
+	ERROR_MESSAGE_INVALID_TIME_ZONE                = "ERROR_MESSAGE_INVALID_TIME_ZONE"
+	ERROR_MESSAGE_HOLIDAY_CALENDAR_NOT_FOUND       = "ERROR_MESSAGE_HOLIDAY_CALENDAR_NOT_FOUND"
+	ERROR_MESSAGE_DATE_OVERRIDE_NOT_FOUND          = "ERROR_MESSAGE_DATE_OVERRIDE_NOT_FOUND"
+	ERROR_MESSAGE_BLACKOUT_PERIOD_NOT_FOUND        = "ERROR_MESSAGE_BLACKOUT_PERIOD_NOT_FOUND"
+	ERROR_MESSAGE_INVALID_PRICING_RULE             = "ERROR_MESSAGE_INVALID_PRICING_RULE"
+	ERROR_MESSAGE_USER_NOT_FOUND                   = "ERROR_MESSAGE_USER_NOT_FOUND"
+	ERROR_MESSAGE_RESOURCE_MEMBER_NOT_FOUND        = "ERROR_MESSAGE_RESOURCE_MEMBER_NOT_FOUND"
+	ERROR_MESSAGE_PROMO_CODE_NOT_FOUND             = "ERROR_MESSAGE_PROMO_CODE_NOT_FOUND"
+	ERROR_MESSAGE_PROMO_CODE_NOT_VALID             = "ERROR_MESSAGE_PROMO_CODE_NOT_VALID"
+	ERROR_MESSAGE_PROMO_CODE_NOT_APPLICABLE        = "ERROR_MESSAGE_PROMO_CODE_NOT_APPLICABLE"
+	ERROR_MESSAGE_PROMO_CODE_MIN_SPEND_NOT_MET     = "ERROR_MESSAGE_PROMO_CODE_MIN_SPEND_NOT_MET"
+	ERROR_MESSAGE_PROMO_CODE_EXHAUSTED             = "ERROR_MESSAGE_PROMO_CODE_EXHAUSTED"
+	ERROR_MESSAGE_PROMO_CODE_LIMIT_REACHED         = "ERROR_MESSAGE_PROMO_CODE_LIMIT_REACHED"
+	ERROR_MESSAGE_PROMO_CODE_ALREADY_EXISTS        = "ERROR_MESSAGE_PROMO_CODE_ALREADY_EXISTS"
+	ERROR_MESSAGE_INVALID_WEBHOOK_SIGNATURE        = "ERROR_MESSAGE_INVALID_WEBHOOK_SIGNATURE"
+	ERROR_MESSAGE_PAYMENT_NOT_FOUND                = "ERROR_MESSAGE_PAYMENT_NOT_FOUND"
+	ERROR_MESSAGE_PAYMENT_ALREADY_COMPLETED        = "ERROR_MESSAGE_PAYMENT_ALREADY_COMPLETED"
+	ERROR_MESSAGE_PAYMENT_AMOUNT_MISMATCH          = "ERROR_MESSAGE_PAYMENT_AMOUNT_MISMATCH"
+	ERROR_MESSAGE_BOOKING_ALREADY_PAID             = "ERROR_MESSAGE_BOOKING_ALREADY_PAID"
+	ERROR_MESSAGE_BOOKING_NOT_PAYABLE              = "ERROR_MESSAGE_BOOKING_NOT_PAYABLE"
+	ERROR_MESSAGE_PAYMENT_HAS_NO_QR_CODE           = "ERROR_MESSAGE_PAYMENT_HAS_NO_QR_CODE"
+	ERROR_MESSAGE_PAYMENT_EXCEEDS_BALANCE          = "ERROR_MESSAGE_PAYMENT_EXCEEDS_BALANCE"
+	ERROR_MESSAGE_INVALID_PAYMENT_SCHEDULE         = "ERROR_MESSAGE_INVALID_PAYMENT_SCHEDULE"
+	ERROR_MESSAGE_LEDGER_ACCOUNT_NOT_FOUND         = "ERROR_MESSAGE_LEDGER_ACCOUNT_NOT_FOUND"
+	ERROR_MESSAGE_BILLING_PROFILE_NOT_FOUND        = "ERROR_MESSAGE_BILLING_PROFILE_NOT_FOUND"
+	ERROR_MESSAGE_BILLING_PROFILE_REQUIRED         = "ERROR_MESSAGE_BILLING_PROFILE_REQUIRED"
+	ERROR_MESSAGE_BOOKING_NOT_PAID                 = "ERROR_MESSAGE_BOOKING_NOT_PAID"
+	ERROR_MESSAGE_EXTERNAL_CALENDAR_NOT_FOUND      = "ERROR_MESSAGE_EXTERNAL_CALENDAR_NOT_FOUND"
+	ERROR_MESSAGE_EXTERNAL_CALENDAR_UNREACHABLE    = "ERROR_MESSAGE_EXTERNAL_CALENDAR_UNREACHABLE"
+	ERROR_MESSAGE_INVALID_CALENDAR                 = "ERROR_MESSAGE_INVALID_CALENDAR"
+	ERROR_MESSAGE_EXTERNAL_CALENDAR_HAS_NO_URL     = "ERROR_MESSAGE_EXTERNAL_CALENDAR_HAS_NO_URL"
+	ERROR_MESSAGE_CALENDAR_FEED_NOT_FOUND          = "ERROR_MESSAGE_CALENDAR_FEED_NOT_FOUND"
+	ERROR_MESSAGE_JOB_NOT_FOUND                    = "ERROR_MESSAGE_JOB_NOT_FOUND"
+	ERROR_MESSAGE_JOB_NOT_FAILED                   = "ERROR_MESSAGE_JOB_NOT_FAILED"
+	ERROR_MESSAGE_RESOURCE_STAFF_NOT_FOUND         = "ERROR_MESSAGE_RESOURCE_STAFF_NOT_FOUND"
+	ERROR_MESSAGE_INVALID_CHECK_IN_TOKEN           = "ERROR_MESSAGE_INVALID_CHECK_IN_TOKEN"
+	ERROR_MESSAGE_BOOKING_NOT_CONFIRMED            = "ERROR_MESSAGE_BOOKING_NOT_CONFIRMED"
+	ERROR_MESSAGE_BOOKING_NOT_TODAY                = "ERROR_MESSAGE_BOOKING_NOT_TODAY"
+	ERROR_MESSAGE_BOOKING_ALREADY_CHECKED_IN       = "ERROR_MESSAGE_BOOKING_ALREADY_CHECKED_IN"
+	ERROR_MESSAGE_BOOKING_BANNED                   = "ERROR_MESSAGE_BOOKING_BANNED"
+	ERROR_MESSAGE_ORGANIZATION_REQUIRED            = "ERROR_MESSAGE_ORGANIZATION_REQUIRED"
+	ERROR_MESSAGE_ORGANIZATION_NOT_FOUND           = "ERROR_MESSAGE_ORGANIZATION_NOT_FOUND"
+	ERROR_MESSAGE_ORGANIZATION_SLUG_ALREADY_EXISTS = "ERROR_MESSAGE_ORGANIZATION_SLUG_ALREADY_EXISTS"
+	ERROR_MESSAGE_ORGANIZATION_MEMBER_NOT_FOUND    = "ERROR_MESSAGE_ORGANIZATION_MEMBER_NOT_FOUND"
+	ERROR_MESSAGE_CANNOT_CHANGE_OWN_MEMBERSHIP     = "ERROR_MESSAGE_CANNOT_CHANGE_OWN_MEMBERSHIP"
+	ERROR_MESSAGE_SERVICE_NOT_FOUND                = "ERROR_MESSAGE_SERVICE_NOT_FOUND"
+	ERROR_MESSAGE_PROVIDER_NOT_FOUND               = "ERROR_MESSAGE_PROVIDER_NOT_FOUND"
+	ERROR_MESSAGE_PROVIDER_UNAVAILABLE             = "ERROR_MESSAGE_PROVIDER_UNAVAILABLE"
+	ERROR_MESSAGE_PROVIDER_TIME_OFF_NOT_FOUND      = "ERROR_MESSAGE_PROVIDER_TIME_OFF_NOT_FOUND"
+	ERROR_MESSAGE_PROVIDER_SERVICE_NOT_FOUND       = "ERROR_MESSAGE_PROVIDER_SERVICE_NOT_FOUND"
+	ERROR_MESSAGE_RESCHEDULE_NOT_ALLOWED           = "ERROR_MESSAGE_RESCHEDULE_NOT_ALLOWED"
+	ERROR_MESSAGE_RESCHEDULE_CUTOFF_PASSED         = "ERROR_MESSAGE_RESCHEDULE_CUTOFF_PASSED"
+	ERROR_MESSAGE_RESCHEDULE_LIMIT_REACHED         = "ERROR_MESSAGE_RESCHEDULE_LIMIT_REACHED"
+	ERROR_MESSAGE_RESCHEDULE_SAME_SLOT             = "ERROR_MESSAGE_RESCHEDULE_SAME_SLOT"
+	ERROR_MESSAGE_REVIEW_NOT_FOUND                 = "ERROR_MESSAGE_REVIEW_NOT_FOUND"
+	ERROR_MESSAGE_BOOKING_NOT_COMPLETED            = "ERROR_MESSAGE_BOOKING_NOT_COMPLETED"
+	ERROR_MESSAGE_BOOKING_ALREADY_REVIEWED         = "ERROR_MESSAGE_BOOKING_ALREADY_REVIEWED"
+	ERROR_MESSAGE_MEDIA_NOT_FOUND                  = "ERROR_MESSAGE_MEDIA_NOT_FOUND"
+	ERROR_MESSAGE_FILE_REQUIRED                    = "ERROR_MESSAGE_FILE_REQUIRED"
+	ERROR_MESSAGE_FILE_TOO_LARGE                   = "ERROR_MESSAGE_FILE_TOO_LARGE"
+	ERROR_MESSAGE_UNSUPPORTED_MEDIA_TYPE           = "ERROR_MESSAGE_UNSUPPORTED_MEDIA_TYPE"
+	ERROR_MESSAGE_INVALID_MEDIA_ORDER              = "ERROR_MESSAGE_INVALID_MEDIA_ORDER"
+	ERROR_MESSAGE_INVALID_MEDIA_SIGNATURE          = "ERROR_MESSAGE_INVALID_MEDIA_SIGNATURE"
+	ERROR_MESSAGE_NO_SHOW_FEE_CHARGED              = "ERROR_MESSAGE_NO_SHOW_FEE_CHARGED"
 )
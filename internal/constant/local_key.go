@@ -1,5 +1,7 @@
 package constant
 
 const (
-	LOCAL_KEY_ACCESS_TOKEN = "LOCAL_KEY_ACCESS_TOKEN"
+	LOCAL_KEY_ACCESS_TOKEN      = "LOCAL_KEY_ACCESS_TOKEN"
+	LOCAL_KEY_ORGANIZATION_ID   = "LOCAL_KEY_ORGANIZATION_ID"
+	LOCAL_KEY_ORGANIZATION_ROLE = "LOCAL_KEY_ORGANIZATION_ROLE"
 )
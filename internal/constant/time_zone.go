@@ -0,0 +1,5 @@
+package constant
+
+const (
+	DEFAULT_TIME_ZONE = "Asia/Bangkok"
+)
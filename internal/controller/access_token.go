@@ -0,0 +1,18 @@
+package controller
+
+import (
+	"booking/internal/constant"
+	"booking/internal/vo"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// getUsername returns the username of the access token verified by the
+// VerifyUser middleware.
+func getUsername(c *fiber.Ctx) string {
+	accessToken, ok := c.Locals(constant.LOCAL_KEY_ACCESS_TOKEN).(*vo.AccessToken)
+	if !ok {
+		return ""
+	}
+	return accessToken.Username
+}
@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"booking/internal/dto"
+	"booking/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type bookingController struct {
+	bookingService service.IBookingService
+}
+
+func NewBookingController(bookingService service.IBookingService) *bookingController {
+	return &bookingController{bookingService: bookingService}
+}
+
+func (bookingCtrl *bookingController) CreateBooking(c *fiber.Ctx) error {
+	body := new(dto.CreateBookingDTO)
+	c.BodyParser(body)
+	res, statusCode := bookingCtrl.bookingService.WithContext(c.UserContext()).CreateBooking(getUsername(c), body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (bookingCtrl *bookingController) GetBooking(c *fiber.Ctx) error {
+	bookingID, _ := uuid.Parse(c.Params("id"))
+	res, statusCode := bookingCtrl.bookingService.WithContext(c.UserContext()).GetBooking(getUsername(c), bookingID)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (bookingCtrl *bookingController) GetAvailability(c *fiber.Ctx) error {
+	resourceID, _ := uuid.Parse(c.Params("id"))
+	query := new(dto.AvailabilityQueryDTO)
+	c.QueryParser(query)
+	res, statusCode := bookingCtrl.bookingService.WithContext(c.UserContext()).GetAvailability(getUsername(c), resourceID, query)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (bookingCtrl *bookingController) CancelBooking(c *fiber.Ctx) error {
+	bookingID, _ := uuid.Parse(c.Params("id"))
+	body := new(dto.CancelBookingDTO)
+	c.BodyParser(body)
+	res, statusCode := bookingCtrl.bookingService.WithContext(c.UserContext()).CancelBooking(getUsername(c), bookingID, body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (bookingCtrl *bookingController) RescheduleBooking(c *fiber.Ctx) error {
+	bookingID, _ := uuid.Parse(c.Params("id"))
+	body := new(dto.RescheduleBookingDTO)
+	c.BodyParser(body)
+	res, statusCode := bookingCtrl.bookingService.WithContext(c.UserContext()).RescheduleBooking(getUsername(c), bookingID, body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (bookingCtrl *bookingController) GetBookingHistory(c *fiber.Ctx) error {
+	bookingID, _ := uuid.Parse(c.Params("id"))
+	res, statusCode := bookingCtrl.bookingService.WithContext(c.UserContext()).GetBookingHistory(getUsername(c), bookingID)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (bookingCtrl *bookingController) GetNoShowRecord(c *fiber.Ctx) error {
+	res, statusCode := bookingCtrl.bookingService.WithContext(c.UserContext()).GetNoShowRecord(getUsername(c))
+	return c.Status(statusCode).JSON(res)
+}
@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"booking/internal/dto"
+	"booking/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type bookingSeriesController struct {
+	bookingSeriesService service.IBookingSeriesService
+}
+
+func NewBookingSeriesController(bookingSeriesService service.IBookingSeriesService) *bookingSeriesController {
+	return &bookingSeriesController{bookingSeriesService: bookingSeriesService}
+}
+
+func (bookingSeriesCtrl *bookingSeriesController) CreateBookingSeries(c *fiber.Ctx) error {
+	body := new(dto.CreateBookingSeriesDTO)
+	c.BodyParser(body)
+	res, statusCode := bookingSeriesCtrl.bookingSeriesService.WithContext(c.UserContext()).CreateBookingSeries(getUsername(c), body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (bookingSeriesCtrl *bookingSeriesController) GetBookingSeries(c *fiber.Ctx) error {
+	seriesID, _ := uuid.Parse(c.Params("id"))
+	res, statusCode := bookingSeriesCtrl.bookingSeriesService.WithContext(c.UserContext()).GetBookingSeries(getUsername(c), seriesID)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (bookingSeriesCtrl *bookingSeriesController) UpdateBookingSeries(c *fiber.Ctx) error {
+	seriesID, _ := uuid.Parse(c.Params("id"))
+	body := new(dto.UpdateBookingSeriesDTO)
+	c.BodyParser(body)
+	res, statusCode := bookingSeriesCtrl.bookingSeriesService.WithContext(c.UserContext()).UpdateBookingSeries(getUsername(c), seriesID, body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (bookingSeriesCtrl *bookingSeriesController) CancelBookingSeries(c *fiber.Ctx) error {
+	seriesID, _ := uuid.Parse(c.Params("id"))
+	body := new(dto.CancelBookingSeriesDTO)
+	c.BodyParser(body)
+	res, statusCode := bookingSeriesCtrl.bookingSeriesService.WithContext(c.UserContext()).CancelBookingSeries(getUsername(c), seriesID, body)
+	return c.Status(statusCode).JSON(res)
+}
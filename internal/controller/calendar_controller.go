@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"booking/internal/service"
+	"booking/internal/vo"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type calendarController struct {
+	calendarService service.ICalendarService
+}
+
+func NewCalendarController(calendarService service.ICalendarService) *calendarController {
+	return &calendarController{calendarService: calendarService}
+}
+
+func (calendarCtrl *calendarController) GetCalendarFeed(c *fiber.Ctx) error {
+	res, statusCode := calendarCtrl.calendarService.WithContext(c.UserContext()).GetCalendarFeed(getUsername(c))
+	return c.Status(statusCode).JSON(res)
+}
+
+func (calendarCtrl *calendarController) CreateCalendarFeed(c *fiber.Ctx) error {
+	res, statusCode := calendarCtrl.calendarService.WithContext(c.UserContext()).CreateCalendarFeed(getUsername(c))
+	return c.Status(statusCode).JSON(res)
+}
+
+func (calendarCtrl *calendarController) GetFeedCalendar(c *fiber.Ctx) error {
+	res, statusCode := calendarCtrl.calendarService.WithContext(c.UserContext()).GetFeedCalendar(c.Params("token"))
+	return sendCalendar(c, res, statusCode)
+}
+
+func (calendarCtrl *calendarController) GetBookingCalendar(c *fiber.Ctx) error {
+	bookingID, _ := uuid.Parse(c.Params("id"))
+	res, statusCode := calendarCtrl.calendarService.WithContext(c.UserContext()).GetBookingCalendar(getUsername(c), bookingID)
+	return sendCalendar(c, res, statusCode)
+}
+
+func sendCalendar(c *fiber.Ctx, res vo.Response, statusCode int) error {
+	file, ok := res.Data.(vo.FileResponse)
+	if statusCode != fiber.StatusOK || !ok {
+		return c.Status(statusCode).JSON(res)
+	}
+	c.Set(fiber.HeaderContentType, "text/calendar; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", file.FileName))
+	return c.Send(file.Content)
+}
@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"booking/internal/dto"
+	"booking/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type checkInController struct {
+	checkInService service.ICheckInService
+}
+
+func NewCheckInController(checkInService service.ICheckInService) *checkInController {
+	return &checkInController{checkInService: checkInService}
+}
+
+func (checkInCtrl *checkInController) GetCheckInCode(c *fiber.Ctx) error {
+	bookingID, _ := uuid.Parse(c.Params("id"))
+	res, statusCode := checkInCtrl.checkInService.WithContext(c.UserContext()).GetCheckInCode(getUsername(c), bookingID)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (checkInCtrl *checkInController) GetCheckInQRCode(c *fiber.Ctx) error {
+	bookingID, _ := uuid.Parse(c.Params("id"))
+	res, statusCode := checkInCtrl.checkInService.WithContext(c.UserContext()).GetCheckInQRCode(getUsername(c), bookingID)
+	png, ok := res.Data.([]byte)
+	if statusCode != fiber.StatusOK || !ok {
+		return c.Status(statusCode).JSON(res)
+	}
+	c.Type("png")
+	return c.Send(png)
+}
+
+func (checkInCtrl *checkInController) CheckIn(c *fiber.Ctx) error {
+	body := new(dto.CheckInDTO)
+	c.BodyParser(body)
+	res, statusCode := checkInCtrl.checkInService.WithContext(c.UserContext()).CheckIn(getUsername(c), body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (checkInCtrl *checkInController) AddResourceStaff(c *fiber.Ctx) error {
+	resourceID, _ := uuid.Parse(c.Params("id"))
+	body := new(dto.AddResourceStaffDTO)
+	c.BodyParser(body)
+	res, statusCode := checkInCtrl.checkInService.WithContext(c.UserContext()).AddResourceStaff(getUsername(c), resourceID, body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (checkInCtrl *checkInController) RemoveResourceStaff(c *fiber.Ctx) error {
+	resourceID, _ := uuid.Parse(c.Params("id"))
+	userID, _ := uuid.Parse(c.Params("userId"))
+	res, statusCode := checkInCtrl.checkInService.WithContext(c.UserContext()).RemoveResourceStaff(getUsername(c), resourceID, userID)
+	return c.Status(statusCode).JSON(res)
+}
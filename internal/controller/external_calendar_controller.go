@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"booking/internal/dto"
+	"booking/internal/service"
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type externalCalendarController struct {
+	externalCalendarService service.IExternalCalendarService
+}
+
+func NewExternalCalendarController(externalCalendarService service.IExternalCalendarService) *externalCalendarController {
+	return &externalCalendarController{externalCalendarService: externalCalendarService}
+}
+
+func (externalCalendarCtrl *externalCalendarController) GetExternalCalendars(c *fiber.Ctx) error {
+	resourceID, _ := uuid.Parse(c.Params("id"))
+	res, statusCode := externalCalendarCtrl.externalCalendarService.WithContext(c.UserContext()).GetExternalCalendars(getUsername(c), resourceID)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (externalCalendarCtrl *externalCalendarController) CreateExternalCalendar(c *fiber.Ctx) error {
+	resourceID, _ := uuid.Parse(c.Params("id"))
+	body := new(dto.CreateExternalCalendarDTO)
+	c.BodyParser(body)
+	res, statusCode := externalCalendarCtrl.externalCalendarService.WithContext(c.UserContext()).CreateExternalCalendar(getUsername(c), resourceID, body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (externalCalendarCtrl *externalCalendarController) UploadExternalCalendar(c *fiber.Ctx) error {
+	resourceID, _ := uuid.Parse(c.Params("id"))
+	body := new(dto.UploadExternalCalendarDTO)
+	c.BodyParser(body)
+	// a missing file is read as an empty calendar, which isn't valid
+	var content []byte
+	if header, err := c.FormFile("file"); err == nil {
+		if file, err := header.Open(); err == nil {
+			content, _ = io.ReadAll(file)
+			file.Close()
+		}
+	}
+	res, statusCode := externalCalendarCtrl.externalCalendarService.WithContext(c.UserContext()).UploadExternalCalendar(getUsername(c), resourceID, body.Name, content)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (externalCalendarCtrl *externalCalendarController) SyncExternalCalendar(c *fiber.Ctx) error {
+	resourceID, _ := uuid.Parse(c.Params("id"))
+	calendarID, _ := uuid.Parse(c.Params("calendarId"))
+	res, statusCode := externalCalendarCtrl.externalCalendarService.WithContext(c.UserContext()).SyncExternalCalendar(getUsername(c), resourceID, calendarID)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (externalCalendarCtrl *externalCalendarController) DeleteExternalCalendar(c *fiber.Ctx) error {
+	resourceID, _ := uuid.Parse(c.Params("id"))
+	calendarID, _ := uuid.Parse(c.Params("calendarId"))
+	res, statusCode := externalCalendarCtrl.externalCalendarService.WithContext(c.UserContext()).DeleteExternalCalendar(getUsername(c), resourceID, calendarID)
+	return c.Status(statusCode).JSON(res)
+}
@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"booking/internal/dto"
+	"booking/internal/service"
+	"booking/internal/vo"
+	"fmt"
+	"path/filepath"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type invoiceController struct {
+	invoiceService service.IInvoiceService
+}
+
+func NewInvoiceController(invoiceService service.IInvoiceService) *invoiceController {
+	return &invoiceController{invoiceService: invoiceService}
+}
+
+func (invoiceCtrl *invoiceController) GetBillingProfile(c *fiber.Ctx) error {
+	res, statusCode := invoiceCtrl.invoiceService.WithContext(c.UserContext()).GetBillingProfile(getUsername(c))
+	return c.Status(statusCode).JSON(res)
+}
+
+func (invoiceCtrl *invoiceController) SaveBillingProfile(c *fiber.Ctx) error {
+	body := new(dto.BillingProfileDTO)
+	c.BodyParser(body)
+	res, statusCode := invoiceCtrl.invoiceService.WithContext(c.UserContext()).SaveBillingProfile(getUsername(c), body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (invoiceCtrl *invoiceController) GetOrganizationBillingProfile(c *fiber.Ctx) error {
+	res, statusCode := invoiceCtrl.invoiceService.WithContext(c.UserContext()).GetOrganizationBillingProfile()
+	return c.Status(statusCode).JSON(res)
+}
+
+func (invoiceCtrl *invoiceController) SaveOrganizationBillingProfile(c *fiber.Ctx) error {
+	body := new(dto.BillingProfileDTO)
+	c.BodyParser(body)
+	res, statusCode := invoiceCtrl.invoiceService.WithContext(c.UserContext()).SaveOrganizationBillingProfile(body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (invoiceCtrl *invoiceController) GetBookingInvoice(c *fiber.Ctx) error {
+	bookingID, _ := uuid.Parse(c.Params("id"))
+	query := new(dto.InvoiceQueryDTO)
+	c.QueryParser(query)
+	res, statusCode := invoiceCtrl.invoiceService.WithContext(c.UserContext()).GetBookingInvoice(getUsername(c), bookingID, getLanguage(c), query)
+	file, ok := res.Data.(vo.FileResponse)
+	if statusCode != fiber.StatusOK || !ok {
+		return c.Status(statusCode).JSON(res)
+	}
+	c.Type(filepath.Ext(file.FileName)[1:])
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", file.FileName))
+	return c.Send(file.Content)
+}
@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"booking/internal/dto"
+	"booking/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type jobController struct {
+	jobService service.IJobService
+}
+
+func NewJobController(jobService service.IJobService) *jobController {
+	return &jobController{jobService: jobService}
+}
+
+func (jobCtrl *jobController) GetJobs(c *fiber.Ctx) error {
+	query := new(dto.JobQueryDTO)
+	c.QueryParser(query)
+	res, statusCode := jobCtrl.jobService.GetJobs(getUsername(c), query)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (jobCtrl *jobController) RetryJob(c *fiber.Ctx) error {
+	jobID, _ := uuid.Parse(c.Params("id"))
+	res, statusCode := jobCtrl.jobService.RetryJob(getUsername(c), jobID)
+	return c.Status(statusCode).JSON(res)
+}
@@ -0,0 +1,16 @@
+package controller
+
+import (
+	"github.com/gofiber/contrib/fiberi18n/v2"
+	"github.com/gofiber/fiber/v2"
+)
+
+// getLanguage returns the language fiberi18n picked for the request, from
+// the lang query parameter or the Accept-Language header.
+func getLanguage(c *fiber.Ctx) string {
+	language, err := fiberi18n.Localize(c, "LANGUAGE")
+	if err != nil {
+		return ""
+	}
+	return language
+}
@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"booking/internal/dto"
+	"booking/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ledgerController struct {
+	ledgerService service.ILedgerService
+}
+
+func NewLedgerController(ledgerService service.ILedgerService) *ledgerController {
+	return &ledgerController{ledgerService: ledgerService}
+}
+
+func (ledgerCtrl *ledgerController) GetBalances(c *fiber.Ctx) error {
+	query := new(dto.LedgerBalanceQueryDTO)
+	c.QueryParser(query)
+	res, statusCode := ledgerCtrl.ledgerService.WithContext(c.UserContext()).GetBalances(getUsername(c), query)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (ledgerCtrl *ledgerController) GetStatement(c *fiber.Ctx) error {
+	query := new(dto.LedgerStatementQueryDTO)
+	c.QueryParser(query)
+	res, statusCode := ledgerCtrl.ledgerService.WithContext(c.UserContext()).GetStatement(getUsername(c), c.Params("code"), query)
+	return c.Status(statusCode).JSON(res)
+}
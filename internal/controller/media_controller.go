@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"booking/internal/dto"
+	"booking/internal/service"
+	"booking/internal/vo"
+	"fmt"
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type mediaController struct {
+	mediaService service.IMediaService
+}
+
+func NewMediaController(mediaService service.IMediaService) *mediaController {
+	return &mediaController{mediaService: mediaService}
+}
+
+func (mediaCtrl *mediaController) UploadMedia(c *fiber.Ctx) error {
+	resourceID, _ := uuid.Parse(c.Params("id"))
+	body := new(dto.CreateMediaDTO)
+	c.BodyParser(body)
+	// a missing file is read as an empty one, which the service refuses
+	fileName := ""
+	var content []byte
+	if header, err := c.FormFile("file"); err == nil {
+		fileName = header.Filename
+		if file, err := header.Open(); err == nil {
+			content, _ = io.ReadAll(file)
+			file.Close()
+		}
+	}
+	res, statusCode := mediaCtrl.mediaService.WithContext(c.UserContext()).UploadMedia(getUsername(c), resourceID, fileName, content, body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (mediaCtrl *mediaController) GetResourceMedia(c *fiber.Ctx) error {
+	resourceID, _ := uuid.Parse(c.Params("id"))
+	res, statusCode := mediaCtrl.mediaService.WithContext(c.UserContext()).GetResourceMedia(getUsername(c), resourceID)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (mediaCtrl *mediaController) ReorderMedia(c *fiber.Ctx) error {
+	resourceID, _ := uuid.Parse(c.Params("id"))
+	body := new(dto.ReorderMediaDTO)
+	c.BodyParser(body)
+	res, statusCode := mediaCtrl.mediaService.WithContext(c.UserContext()).ReorderMedia(getUsername(c), resourceID, body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (mediaCtrl *mediaController) DeleteMedia(c *fiber.Ctx) error {
+	resourceID, _ := uuid.Parse(c.Params("id"))
+	mediaID, _ := uuid.Parse(c.Params("mediaId"))
+	res, statusCode := mediaCtrl.mediaService.WithContext(c.UserContext()).DeleteMedia(getUsername(c), resourceID, mediaID)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (mediaCtrl *mediaController) GetMediaFile(c *fiber.Ctx) error {
+	return mediaCtrl.getMediaFile(c, service.MEDIA_VARIANT_FILE)
+}
+
+func (mediaCtrl *mediaController) GetMediaThumbnail(c *fiber.Ctx) error {
+	return mediaCtrl.getMediaFile(c, service.MEDIA_VARIANT_THUMBNAIL)
+}
+
+func (mediaCtrl *mediaController) getMediaFile(c *fiber.Ctx, variant string) error {
+	mediaID, _ := uuid.Parse(c.Params("id"))
+	query := new(dto.MediaFileQueryDTO)
+	c.QueryParser(query)
+	res, statusCode := mediaCtrl.mediaService.WithContext(c.UserContext()).GetMediaFile(mediaID, variant, query)
+	file, ok := res.Data.(vo.FileResponse)
+	if statusCode != fiber.StatusOK || !ok {
+		return c.Status(statusCode).JSON(res)
+	}
+	c.Set(fiber.HeaderContentType, file.ContentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", file.FileName))
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	// signed URLs of private files must not outlive their expiry in caches
+	if query.Signature != "" {
+		c.Set(fiber.HeaderCacheControl, "private, no-store")
+	}
+	return c.Send(file.Content)
+}
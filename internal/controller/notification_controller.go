@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"booking/internal/dto"
+	"booking/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type notificationController struct {
+	notificationService service.INotificationService
+}
+
+func NewNotificationController(notificationService service.INotificationService) *notificationController {
+	return &notificationController{notificationService: notificationService}
+}
+
+func (notificationCtrl *notificationController) GetNotificationPreference(c *fiber.Ctx) error {
+	res, statusCode := notificationCtrl.notificationService.GetNotificationPreference(getUsername(c))
+	return c.Status(statusCode).JSON(res)
+}
+
+func (notificationCtrl *notificationController) SaveNotificationPreference(c *fiber.Ctx) error {
+	body := new(dto.NotificationPreferenceDTO)
+	c.BodyParser(body)
+	res, statusCode := notificationCtrl.notificationService.SaveNotificationPreference(getUsername(c), body)
+	return c.Status(statusCode).JSON(res)
+}
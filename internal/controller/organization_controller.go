@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"booking/internal/dto"
+	"booking/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type organizationController struct {
+	organizationService service.IOrganizationService
+}
+
+func NewOrganizationController(organizationService service.IOrganizationService) *organizationController {
+	return &organizationController{organizationService: organizationService}
+}
+
+func (organizationCtrl *organizationController) CreateOrganization(c *fiber.Ctx) error {
+	body := new(dto.CreateOrganizationDTO)
+	c.BodyParser(body)
+	res, statusCode := organizationCtrl.organizationService.CreateOrganization(getUsername(c), body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (organizationCtrl *organizationController) GetOrganizations(c *fiber.Ctx) error {
+	res, statusCode := organizationCtrl.organizationService.GetOrganizations(getUsername(c))
+	return c.Status(statusCode).JSON(res)
+}
+
+func (organizationCtrl *organizationController) SaveOrganizationMember(c *fiber.Ctx) error {
+	organizationID, _ := uuid.Parse(c.Params("id"))
+	body := new(dto.SaveOrganizationMemberDTO)
+	c.BodyParser(body)
+	res, statusCode := organizationCtrl.organizationService.SaveOrganizationMember(getUsername(c), organizationID, body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (organizationCtrl *organizationController) RemoveOrganizationMember(c *fiber.Ctx) error {
+	organizationID, _ := uuid.Parse(c.Params("id"))
+	userID, _ := uuid.Parse(c.Params("userId"))
+	res, statusCode := organizationCtrl.organizationService.RemoveOrganizationMember(getUsername(c), organizationID, userID)
+	return c.Status(statusCode).JSON(res)
+}
@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"booking/internal/dto"
+	"booking/internal/payment"
+	"booking/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type paymentController struct {
+	paymentService service.IPaymentService
+}
+
+func NewPaymentController(paymentService service.IPaymentService) *paymentController {
+	return &paymentController{paymentService: paymentService}
+}
+
+func (paymentCtrl *paymentController) HandleWebhook(c *fiber.Ctx) error {
+	res, statusCode := paymentCtrl.paymentService.WithContext(c.UserContext()).HandleWebhook(c.Body(), c.Get(payment.SIGNATURE_HEADER))
+	return c.Status(statusCode).JSON(res)
+}
+
+func (paymentCtrl *paymentController) SimulatePayment(c *fiber.Ctx) error {
+	body := new(dto.SimulatePaymentDTO)
+	c.BodyParser(body)
+	res, statusCode := paymentCtrl.paymentService.WithContext(c.UserContext()).SimulatePayment(getUsername(c), c.Params("intentId"), body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (paymentCtrl *paymentController) ReconcilePromptPay(c *fiber.Ctx) error {
+	res, statusCode := paymentCtrl.paymentService.WithContext(c.UserContext()).ReconcilePromptPay(c.Body(), c.Get(payment.SIGNATURE_HEADER))
+	return c.Status(statusCode).JSON(res)
+}
+
+func (paymentCtrl *paymentController) GetBookingPayments(c *fiber.Ctx) error {
+	bookingID, _ := uuid.Parse(c.Params("id"))
+	res, statusCode := paymentCtrl.paymentService.WithContext(c.UserContext()).GetBookingPayments(getUsername(c), bookingID)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (paymentCtrl *paymentController) CreatePayment(c *fiber.Ctx) error {
+	bookingID, _ := uuid.Parse(c.Params("id"))
+	body := new(dto.CreatePaymentDTO)
+	c.BodyParser(body)
+	res, statusCode := paymentCtrl.paymentService.WithContext(c.UserContext()).CreatePayment(getUsername(c), bookingID, body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (paymentCtrl *paymentController) CreatePromptPayPayment(c *fiber.Ctx) error {
+	bookingID, _ := uuid.Parse(c.Params("id"))
+	res, statusCode := paymentCtrl.paymentService.WithContext(c.UserContext()).CreatePromptPayPayment(getUsername(c), bookingID)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (paymentCtrl *paymentController) GetPaymentQRCode(c *fiber.Ctx) error {
+	paymentID, _ := uuid.Parse(c.Params("id"))
+	res, statusCode := paymentCtrl.paymentService.WithContext(c.UserContext()).GetPaymentQRCode(getUsername(c), paymentID)
+	png, ok := res.Data.([]byte)
+	if statusCode != fiber.StatusOK || !ok {
+		return c.Status(statusCode).JSON(res)
+	}
+	c.Type("png")
+	return c.Send(png)
+}
@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"booking/internal/dto"
+	"booking/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type pricingController struct {
+	pricingService service.IPricingService
+}
+
+func NewPricingController(pricingService service.IPricingService) *pricingController {
+	return &pricingController{pricingService: pricingService}
+}
+
+func (pricingCtrl *pricingController) GetPricingPolicy(c *fiber.Ctx) error {
+	resourceID, _ := uuid.Parse(c.Params("id"))
+	res, statusCode := pricingCtrl.pricingService.WithContext(c.UserContext()).GetPricingPolicy(resourceID)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (pricingCtrl *pricingController) SavePricingPolicy(c *fiber.Ctx) error {
+	resourceID, _ := uuid.Parse(c.Params("id"))
+	body := new(dto.PricingPolicyDTO)
+	c.BodyParser(body)
+	res, statusCode := pricingCtrl.pricingService.WithContext(c.UserContext()).SavePricingPolicy(getUsername(c), resourceID, body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (pricingCtrl *pricingController) CreateQuote(c *fiber.Ctx) error {
+	body := new(dto.CreateQuoteDTO)
+	c.BodyParser(body)
+	res, statusCode := pricingCtrl.pricingService.WithContext(c.UserContext()).CreateQuote(getUsername(c), body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (pricingCtrl *pricingController) AddResourceMember(c *fiber.Ctx) error {
+	resourceID, _ := uuid.Parse(c.Params("id"))
+	body := new(dto.AddResourceMemberDTO)
+	c.BodyParser(body)
+	res, statusCode := pricingCtrl.pricingService.WithContext(c.UserContext()).AddResourceMember(getUsername(c), resourceID, body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (pricingCtrl *pricingController) RemoveResourceMember(c *fiber.Ctx) error {
+	resourceID, _ := uuid.Parse(c.Params("id"))
+	userID, _ := uuid.Parse(c.Params("userId"))
+	res, statusCode := pricingCtrl.pricingService.WithContext(c.UserContext()).RemoveResourceMember(getUsername(c), resourceID, userID)
+	return c.Status(statusCode).JSON(res)
+}
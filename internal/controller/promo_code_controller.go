@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"booking/internal/dto"
+	"booking/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type promoCodeController struct {
+	promoCodeService service.IPromoCodeService
+}
+
+func NewPromoCodeController(promoCodeService service.IPromoCodeService) *promoCodeController {
+	return &promoCodeController{promoCodeService: promoCodeService}
+}
+
+func (promoCodeCtrl *promoCodeController) CreatePromoCode(c *fiber.Ctx) error {
+	body := new(dto.CreatePromoCodeDTO)
+	c.BodyParser(body)
+	res, statusCode := promoCodeCtrl.promoCodeService.WithContext(c.UserContext()).CreatePromoCode(getUsername(c), body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (promoCodeCtrl *promoCodeController) GetPromoCodes(c *fiber.Ctx) error {
+	res, statusCode := promoCodeCtrl.promoCodeService.WithContext(c.UserContext()).GetPromoCodes(getUsername(c))
+	return c.Status(statusCode).JSON(res)
+}
@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"booking/internal/dto"
+	"booking/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type providerController struct {
+	providerService service.IProviderService
+}
+
+func NewProviderController(providerService service.IProviderService) *providerController {
+	return &providerController{providerService: providerService}
+}
+
+func (providerCtrl *providerController) CreateProvider(c *fiber.Ctx) error {
+	body := new(dto.CreateProviderDTO)
+	c.BodyParser(body)
+	res, statusCode := providerCtrl.providerService.WithContext(c.UserContext()).CreateProvider(body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (providerCtrl *providerController) GetProviders(c *fiber.Ctx) error {
+	res, statusCode := providerCtrl.providerService.WithContext(c.UserContext()).GetProviders()
+	return c.Status(statusCode).JSON(res)
+}
+
+func (providerCtrl *providerController) GetProvider(c *fiber.Ctx) error {
+	providerID, _ := uuid.Parse(c.Params("id"))
+	res, statusCode := providerCtrl.providerService.WithContext(c.UserContext()).GetProvider(providerID)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (providerCtrl *providerController) UpdateProviderHours(c *fiber.Ctx) error {
+	providerID, _ := uuid.Parse(c.Params("id"))
+	body := new(dto.UpdateProviderHoursDTO)
+	c.BodyParser(body)
+	res, statusCode := providerCtrl.providerService.WithContext(c.UserContext()).UpdateProviderHours(providerID, body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (providerCtrl *providerController) CreateProviderTimeOff(c *fiber.Ctx) error {
+	providerID, _ := uuid.Parse(c.Params("id"))
+	body := new(dto.CreateProviderTimeOffDTO)
+	c.BodyParser(body)
+	res, statusCode := providerCtrl.providerService.WithContext(c.UserContext()).CreateProviderTimeOff(providerID, body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (providerCtrl *providerController) DeleteProviderTimeOff(c *fiber.Ctx) error {
+	providerID, _ := uuid.Parse(c.Params("id"))
+	timeOffID, _ := uuid.Parse(c.Params("timeOffId"))
+	res, statusCode := providerCtrl.providerService.WithContext(c.UserContext()).DeleteProviderTimeOff(providerID, timeOffID)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (providerCtrl *providerController) SaveProviderService(c *fiber.Ctx) error {
+	providerID, _ := uuid.Parse(c.Params("id"))
+	serviceID, _ := uuid.Parse(c.Params("serviceId"))
+	body := new(dto.SaveProviderServiceDTO)
+	c.BodyParser(body)
+	res, statusCode := providerCtrl.providerService.WithContext(c.UserContext()).SaveProviderService(providerID, serviceID, body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (providerCtrl *providerController) RemoveProviderService(c *fiber.Ctx) error {
+	providerID, _ := uuid.Parse(c.Params("id"))
+	serviceID, _ := uuid.Parse(c.Params("serviceId"))
+	res, statusCode := providerCtrl.providerService.WithContext(c.UserContext()).RemoveProviderService(providerID, serviceID)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (providerCtrl *providerController) CreateService(c *fiber.Ctx) error {
+	body := new(dto.CreateServiceDTO)
+	c.BodyParser(body)
+	res, statusCode := providerCtrl.providerService.WithContext(c.UserContext()).CreateService(body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (providerCtrl *providerController) GetServices(c *fiber.Ctx) error {
+	res, statusCode := providerCtrl.providerService.WithContext(c.UserContext()).GetServices()
+	return c.Status(statusCode).JSON(res)
+}
+
+func (providerCtrl *providerController) GetAppointmentSlots(c *fiber.Ctx) error {
+	serviceID, _ := uuid.Parse(c.Params("id"))
+	query := new(dto.AppointmentSlotQueryDTO)
+	c.QueryParser(query)
+	res, statusCode := providerCtrl.providerService.WithContext(c.UserContext()).GetAppointmentSlots(getUsername(c), serviceID, query)
+	return c.Status(statusCode).JSON(res)
+}
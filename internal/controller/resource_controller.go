@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"booking/internal/dto"
+	"booking/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type resourceController struct {
+	resourceService service.IResourceService
+}
+
+func NewResourceController(resourceService service.IResourceService) *resourceController {
+	return &resourceController{resourceService: resourceService}
+}
+
+func (resourceCtrl *resourceController) CreateResource(c *fiber.Ctx) error {
+	body := new(dto.CreateResourceDTO)
+	c.BodyParser(body)
+	res, statusCode := resourceCtrl.resourceService.WithContext(c.UserContext()).CreateResource(getUsername(c), body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (resourceCtrl *resourceController) GetResource(c *fiber.Ctx) error {
+	resourceID, _ := uuid.Parse(c.Params("id"))
+	res, statusCode := resourceCtrl.resourceService.WithContext(c.UserContext()).GetResource(resourceID)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (resourceCtrl *resourceController) SearchResources(c *fiber.Ctx) error {
+	query := new(dto.ResourceSearchQueryDTO)
+	c.QueryParser(query)
+	res, statusCode := resourceCtrl.resourceService.WithContext(c.UserContext()).SearchResources(query)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (resourceCtrl *resourceController) GetCancellationPolicy(c *fiber.Ctx) error {
+	resourceID, _ := uuid.Parse(c.Params("id"))
+	res, statusCode := resourceCtrl.resourceService.WithContext(c.UserContext()).GetCancellationPolicy(resourceID)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (resourceCtrl *resourceController) SaveCancellationPolicy(c *fiber.Ctx) error {
+	resourceID, _ := uuid.Parse(c.Params("id"))
+	body := new(dto.CancellationPolicyDTO)
+	c.BodyParser(body)
+	res, statusCode := resourceCtrl.resourceService.WithContext(c.UserContext()).SaveCancellationPolicy(getUsername(c), resourceID, body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (resourceCtrl *resourceController) GetReschedulePolicy(c *fiber.Ctx) error {
+	resourceID, _ := uuid.Parse(c.Params("id"))
+	res, statusCode := resourceCtrl.resourceService.WithContext(c.UserContext()).GetReschedulePolicy(resourceID)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (resourceCtrl *resourceController) SaveReschedulePolicy(c *fiber.Ctx) error {
+	resourceID, _ := uuid.Parse(c.Params("id"))
+	body := new(dto.ReschedulePolicyDTO)
+	c.BodyParser(body)
+	res, statusCode := resourceCtrl.resourceService.WithContext(c.UserContext()).SaveReschedulePolicy(getUsername(c), resourceID, body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (resourceCtrl *resourceController) GetPaymentSchedule(c *fiber.Ctx) error {
+	resourceID, _ := uuid.Parse(c.Params("id"))
+	res, statusCode := resourceCtrl.resourceService.WithContext(c.UserContext()).GetPaymentSchedule(resourceID)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (resourceCtrl *resourceController) SavePaymentSchedule(c *fiber.Ctx) error {
+	resourceID, _ := uuid.Parse(c.Params("id"))
+	body := new(dto.PaymentScheduleDTO)
+	c.BodyParser(body)
+	res, statusCode := resourceCtrl.resourceService.WithContext(c.UserContext()).SavePaymentSchedule(getUsername(c), resourceID, body)
+	return c.Status(statusCode).JSON(res)
+}
@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"booking/internal/dto"
+	"booking/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type reviewController struct {
+	reviewService service.IReviewService
+}
+
+func NewReviewController(reviewService service.IReviewService) *reviewController {
+	return &reviewController{reviewService: reviewService}
+}
+
+func (reviewCtrl *reviewController) CreateReview(c *fiber.Ctx) error {
+	bookingID, _ := uuid.Parse(c.Params("id"))
+	body := new(dto.CreateReviewDTO)
+	c.BodyParser(body)
+	res, statusCode := reviewCtrl.reviewService.WithContext(c.UserContext()).CreateReview(getUsername(c), bookingID, body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (reviewCtrl *reviewController) GetResourceReviews(c *fiber.Ctx) error {
+	resourceID, _ := uuid.Parse(c.Params("id"))
+	query := new(dto.ReviewQueryDTO)
+	c.QueryParser(query)
+	res, statusCode := reviewCtrl.reviewService.WithContext(c.UserContext()).GetResourceReviews(resourceID, query)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (reviewCtrl *reviewController) ReplyReview(c *fiber.Ctx) error {
+	reviewID, _ := uuid.Parse(c.Params("id"))
+	body := new(dto.ReplyReviewDTO)
+	c.BodyParser(body)
+	res, statusCode := reviewCtrl.reviewService.WithContext(c.UserContext()).ReplyReview(getUsername(c), reviewID, body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (reviewCtrl *reviewController) ModerateReview(c *fiber.Ctx) error {
+	reviewID, _ := uuid.Parse(c.Params("id"))
+	body := new(dto.ModerateReviewDTO)
+	c.BodyParser(body)
+	res, statusCode := reviewCtrl.reviewService.WithContext(c.UserContext()).ModerateReview(getUsername(c), reviewID, body)
+	return c.Status(statusCode).JSON(res)
+}
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"booking/internal/dto"
+	"booking/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type scheduleController struct {
+	scheduleService service.IScheduleService
+}
+
+func NewScheduleController(scheduleService service.IScheduleService) *scheduleController {
+	return &scheduleController{scheduleService: scheduleService}
+}
+
+func (scheduleCtrl *scheduleController) GetSchedule(c *fiber.Ctx) error {
+	resourceID, _ := uuid.Parse(c.Params("id"))
+	res, statusCode := scheduleCtrl.scheduleService.WithContext(c.UserContext()).GetSchedule(resourceID)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (scheduleCtrl *scheduleController) UpdateOpeningHours(c *fiber.Ctx) error {
+	resourceID, _ := uuid.Parse(c.Params("id"))
+	body := new(dto.UpdateOpeningHoursDTO)
+	c.BodyParser(body)
+	res, statusCode := scheduleCtrl.scheduleService.WithContext(c.UserContext()).UpdateOpeningHours(getUsername(c), resourceID, body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (scheduleCtrl *scheduleController) SaveDateOverride(c *fiber.Ctx) error {
+	resourceID, _ := uuid.Parse(c.Params("id"))
+	body := new(dto.DateOverrideDTO)
+	c.BodyParser(body)
+	res, statusCode := scheduleCtrl.scheduleService.WithContext(c.UserContext()).SaveDateOverride(getUsername(c), resourceID, c.Params("date"), body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (scheduleCtrl *scheduleController) DeleteDateOverride(c *fiber.Ctx) error {
+	resourceID, _ := uuid.Parse(c.Params("id"))
+	res, statusCode := scheduleCtrl.scheduleService.WithContext(c.UserContext()).DeleteDateOverride(getUsername(c), resourceID, c.Params("date"))
+	return c.Status(statusCode).JSON(res)
+}
+
+func (scheduleCtrl *scheduleController) CreateBlackoutPeriod(c *fiber.Ctx) error {
+	resourceID, _ := uuid.Parse(c.Params("id"))
+	body := new(dto.CreateBlackoutPeriodDTO)
+	c.BodyParser(body)
+	res, statusCode := scheduleCtrl.scheduleService.WithContext(c.UserContext()).CreateBlackoutPeriod(getUsername(c), resourceID, body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (scheduleCtrl *scheduleController) DeleteBlackoutPeriod(c *fiber.Ctx) error {
+	resourceID, _ := uuid.Parse(c.Params("id"))
+	blackoutID, _ := uuid.Parse(c.Params("blackoutId"))
+	res, statusCode := scheduleCtrl.scheduleService.WithContext(c.UserContext()).DeleteBlackoutPeriod(getUsername(c), resourceID, blackoutID)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (scheduleCtrl *scheduleController) GetHolidays(c *fiber.Ctx) error {
+	query := new(dto.HolidayQueryDTO)
+	c.QueryParser(query)
+	res, statusCode := scheduleCtrl.scheduleService.WithContext(c.UserContext()).GetHolidays(c.Params("code"), query.Year)
+	return c.Status(statusCode).JSON(res)
+}
@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"booking/internal/dto"
+	"booking/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type waitlistController struct {
+	waitlistService service.IWaitlistService
+}
+
+func NewWaitlistController(waitlistService service.IWaitlistService) *waitlistController {
+	return &waitlistController{waitlistService: waitlistService}
+}
+
+func (waitlistCtrl *waitlistController) JoinWaitlist(c *fiber.Ctx) error {
+	body := new(dto.JoinWaitlistDTO)
+	c.BodyParser(body)
+	res, statusCode := waitlistCtrl.waitlistService.WithContext(c.UserContext()).JoinWaitlist(getUsername(c), body)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (waitlistCtrl *waitlistController) GetWaitlistEntries(c *fiber.Ctx) error {
+	res, statusCode := waitlistCtrl.waitlistService.WithContext(c.UserContext()).GetWaitlistEntries(getUsername(c))
+	return c.Status(statusCode).JSON(res)
+}
+
+func (waitlistCtrl *waitlistController) LeaveWaitlist(c *fiber.Ctx) error {
+	entryID, _ := uuid.Parse(c.Params("id"))
+	res, statusCode := waitlistCtrl.waitlistService.WithContext(c.UserContext()).LeaveWaitlist(getUsername(c), entryID)
+	return c.Status(statusCode).JSON(res)
+}
+
+func (waitlistCtrl *waitlistController) ClaimOffer(c *fiber.Ctx) error {
+	entryID, _ := uuid.Parse(c.Params("id"))
+	res, statusCode := waitlistCtrl.waitlistService.WithContext(c.UserContext()).ClaimOffer(getUsername(c), entryID)
+	return c.Status(statusCode).JSON(res)
+}
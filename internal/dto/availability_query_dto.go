@@ -0,0 +1,6 @@
+package dto
+
+type AvailabilityQueryDTO struct {
+	StartAt string `query:"startAt" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
+	EndAt   string `query:"endAt" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
+}
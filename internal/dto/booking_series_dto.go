@@ -0,0 +1,34 @@
+package dto
+
+import "time"
+
+const (
+	BOOKING_SERIES_SCOPE_THIS               = "THIS"
+	BOOKING_SERIES_SCOPE_THIS_AND_FOLLOWING = "THIS_AND_FOLLOWING"
+	BOOKING_SERIES_SCOPE_ALL                = "ALL"
+)
+
+type CreateBookingSeriesDTO struct {
+	ResourceID string `json:"resourceId" validate:"required,uuid"`
+	// StartAt and EndAt are the first occurrence, RRule is an RFC 5545 RRULE value.
+	StartAt  time.Time   `json:"startAt" validate:"required"`
+	EndAt    time.Time   `json:"endAt" validate:"required,gtfield=StartAt"`
+	RRule    string      `json:"rrule" validate:"required,max=500"`
+	ExDates  []time.Time `json:"exdates" validate:"max=500"`
+	TimeZone string      `json:"timeZone" validate:"omitempty,timezone"`
+	Seats    int         `json:"seats" validate:"omitempty,min=1"`
+}
+
+type UpdateBookingSeriesDTO struct {
+	Scope     string    `json:"scope" validate:"required,oneof=THIS THIS_AND_FOLLOWING ALL"`
+	BookingID string    `json:"bookingId" validate:"required,uuid"`
+	StartAt   time.Time `json:"startAt" validate:"required"`
+	EndAt     time.Time `json:"endAt" validate:"required,gtfield=StartAt"`
+}
+
+type CancelBookingSeriesDTO struct {
+	Scope     string `json:"scope" validate:"required,oneof=THIS THIS_AND_FOLLOWING ALL"`
+	BookingID string `json:"bookingId" validate:"required_unless=Scope ALL,omitempty,uuid"`
+	// Confirm applies the cancellation. Without it the refunds are only previewed.
+	Confirm bool `json:"confirm"`
+}
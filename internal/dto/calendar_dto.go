@@ -0,0 +1,5 @@
+package dto
+
+type CalendarTokenParamDTO struct {
+	Token string `params:"token" validate:"required,hexadecimal,len=64"`
+}
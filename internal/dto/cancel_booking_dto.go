@@ -0,0 +1,6 @@
+package dto
+
+type CancelBookingDTO struct {
+	// Confirm applies the cancellation. Without it the refund is only previewed.
+	Confirm bool `json:"confirm"`
+}
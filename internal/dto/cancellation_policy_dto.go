@@ -0,0 +1,10 @@
+package dto
+
+type CancellationPolicyDTO struct {
+	Rules []CancellationRuleDTO `json:"rules" validate:"required,min=1,max=10,dive"`
+}
+
+type CancellationRuleDTO struct {
+	HoursBeforeStart int `json:"hoursBeforeStart" validate:"min=0"`
+	RefundPercent    int `json:"refundPercent" validate:"min=0,max=100"`
+}
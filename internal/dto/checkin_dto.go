@@ -0,0 +1,15 @@
+package dto
+
+type CheckInDTO struct {
+	// Token is read from the QR code the customer shows.
+	Token string `json:"token" validate:"required,max=128"`
+}
+
+type AddResourceStaffDTO struct {
+	Username string `json:"username" validate:"required"`
+}
+
+type StaffParamDTO struct {
+	ID     string `params:"id" validate:"required,uuid"`
+	UserID string `params:"userId" validate:"required,uuid"`
+}
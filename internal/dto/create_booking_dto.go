@@ -0,0 +1,17 @@
+package dto
+
+import "time"
+
+type CreateBookingDTO struct {
+	ResourceID string    `json:"resourceId" validate:"required_without=ServiceID,omitempty,uuid"`
+	StartAt    time.Time `json:"startAt" validate:"required"`
+	EndAt      time.Time `json:"endAt" validate:"required_without=ServiceID,omitempty,gtfield=StartAt"`
+	// ServiceID books an appointment for the service instead, in its resource
+	// for as long as the provider takes. ProviderID is the provider asked
+	// for, any available one being assigned if empty.
+	ServiceID  string `json:"serviceId" validate:"omitempty,uuid"`
+	ProviderID string `json:"providerId" validate:"omitempty,uuid"`
+	// Seats defaults to 1.
+	Seats     int    `json:"seats" validate:"omitempty,min=1"`
+	PromoCode string `json:"promoCode" validate:"max=32"`
+}
@@ -0,0 +1,18 @@
+package dto
+
+type CreateResourceDTO struct {
+	Name        string   `json:"name" validate:"required,max=255"`
+	Description string   `json:"description" validate:"max=4000"`
+	Category    string   `json:"category" validate:"max=64"`
+	HourlyRate  int64    `json:"hourlyRate" validate:"min=0"`
+	Amenities   []string `json:"amenities" validate:"max=50,dive,required,max=64"`
+	// Capacity defaults to 1 for resources booked by one party at a time.
+	Capacity int    `json:"capacity" validate:"omitempty,min=1,max=10000"`
+	Address  string `json:"address" validate:"max=500"`
+	// Latitude and Longitude are in decimal degrees, given together.
+	Latitude  *float64 `json:"latitude" validate:"required_with=Longitude,omitempty,min=-90,max=90"`
+	Longitude *float64 `json:"longitude" validate:"required_with=Latitude,omitempty,min=-180,max=180"`
+	// TimeZone is the IANA time zone of the opening hours, Asia/Bangkok if
+	// empty.
+	TimeZone string `json:"timeZone" validate:"max=64"`
+}
@@ -0,0 +1,18 @@
+package dto
+
+type CreateExternalCalendarDTO struct {
+	Name string `json:"name" validate:"required,max=100"`
+	// URL is an http, https or webcal address of an ICS feed.
+	URL string `json:"url" validate:"required,url,max=2000"`
+}
+
+// UploadExternalCalendarDTO is the multipart form uploading an ICS file as
+// "file".
+type UploadExternalCalendarDTO struct {
+	Name string `form:"name" validate:"required,max=100"`
+}
+
+type ExternalCalendarParamDTO struct {
+	ID         string `params:"id" validate:"required,uuid"`
+	CalendarID string `params:"calendarId" validate:"required,uuid"`
+}
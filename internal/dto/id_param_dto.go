@@ -0,0 +1,5 @@
+package dto
+
+type IDParamDTO struct {
+	ID string `params:"id" validate:"required,uuid"`
+}
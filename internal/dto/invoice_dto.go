@@ -0,0 +1,17 @@
+package dto
+
+type BillingProfileDTO struct {
+	Name string `json:"name" validate:"required,max=200"`
+	// TaxID is the 13 digit tax identification number, BranchCode the 5 digit
+	// branch of a VAT registered business, 00000 or empty for the head office.
+	TaxID      string `json:"taxId" validate:"omitempty,numeric,len=13"`
+	BranchCode string `json:"branchCode" validate:"omitempty,numeric,len=5"`
+	Address    string `json:"address" validate:"max=500"`
+}
+
+type InvoiceQueryDTO struct {
+	// Type is invoice, the default, or receipt.
+	Type string `query:"type" validate:"omitempty,oneof=invoice receipt"`
+	// Format is pdf, the default, or html.
+	Format string `query:"format" validate:"omitempty,oneof=pdf html"`
+}
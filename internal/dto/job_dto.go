@@ -0,0 +1,8 @@
+package dto
+
+type JobQueryDTO struct {
+	Status string `query:"status" validate:"omitempty,oneof=PENDING RUNNING SUCCEEDED FAILED"`
+	Kind   string `query:"kind" validate:"omitempty,max=64"`
+	Page   uint   `query:"page" validate:"omitempty,min=1"`
+	Size   uint   `query:"size" validate:"omitempty,min=1,max=100"`
+}
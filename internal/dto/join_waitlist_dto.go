@@ -0,0 +1,10 @@
+package dto
+
+import "time"
+
+type JoinWaitlistDTO struct {
+	ResourceID string    `json:"resourceId" validate:"required,uuid"`
+	StartAt    time.Time `json:"startAt" validate:"required"`
+	EndAt      time.Time `json:"endAt" validate:"required,gtfield=StartAt"`
+	Seats      int       `json:"seats" validate:"omitempty,min=1"`
+}
@@ -0,0 +1,17 @@
+package dto
+
+type LedgerBalanceQueryDTO struct {
+	// At is the time the balances are taken at, now if empty.
+	At string `query:"at" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+}
+
+type LedgerAccountParamDTO struct {
+	Code string `params:"code" validate:"required,max=64"`
+}
+
+type LedgerStatementQueryDTO struct {
+	StartAt string `query:"startAt" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
+	EndAt   string `query:"endAt" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
+	Page    uint   `query:"page" validate:"omitempty,min=1"`
+	Size    uint   `query:"size" validate:"omitempty,min=1,max=100"`
+}
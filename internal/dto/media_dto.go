@@ -0,0 +1,24 @@
+package dto
+
+// CreateMediaDTO is the multipart form uploading a file as "file".
+type CreateMediaDTO struct {
+	// Kind is PHOTO when omitted.
+	Kind    string `form:"kind" validate:"omitempty,oneof=PHOTO FLOOR_PLAN"`
+	Private bool   `form:"private"`
+}
+
+type ReorderMediaDTO struct {
+	// IDs lists every file of the resource in their new order.
+	IDs []string `json:"ids" validate:"required,max=200,dive,uuid"`
+}
+
+type MediaParamDTO struct {
+	ID      string `params:"id" validate:"required,uuid"`
+	MediaID string `params:"mediaId" validate:"required,uuid"`
+}
+
+// MediaFileQueryDTO carries the signature of the URLs of private files.
+type MediaFileQueryDTO struct {
+	Expires   int64  `query:"expires"`
+	Signature string `query:"signature"`
+}
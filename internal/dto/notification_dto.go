@@ -0,0 +1,13 @@
+package dto
+
+// NotificationPreferenceDTO turns on the channels a user is notified on. A
+// phone number in E.164 format is needed for SMS and the LINE user ID of the
+// user for LINE.
+type NotificationPreferenceDTO struct {
+	Email      bool   `json:"email"`
+	SMS        bool   `json:"sms"`
+	Phone      string `json:"phone" validate:"required_if=SMS true,omitempty,e164"`
+	Line       bool   `json:"line"`
+	LineUserID string `json:"lineUserId" validate:"required_if=Line true,max=64"`
+	Language   string `json:"language" validate:"omitempty,oneof=en th"`
+}
@@ -0,0 +1,17 @@
+package dto
+
+type CreateOrganizationDTO struct {
+	Name string `json:"name" validate:"required,max=255"`
+	// Slug identifies the organization in URLs, unique across organizations.
+	Slug string `json:"slug" validate:"required,max=64,lowercase"`
+}
+
+type SaveOrganizationMemberDTO struct {
+	Username string `json:"username" validate:"required"`
+	Role     string `json:"role" validate:"required,oneof=OWNER ADMIN MEMBER"`
+}
+
+type OrganizationMemberParamDTO struct {
+	ID     string `params:"id" validate:"required,uuid"`
+	UserID string `params:"userId" validate:"required,uuid"`
+}
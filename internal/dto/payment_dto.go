@@ -0,0 +1,15 @@
+package dto
+
+type IntentParamDTO struct {
+	IntentID string `params:"intentId" validate:"required,max=100"`
+}
+
+type SimulatePaymentDTO struct {
+	Outcome       string `json:"outcome" validate:"required,oneof=AUTHORIZED FAILED"`
+	FailureReason string `json:"failureReason" validate:"max=200"`
+}
+
+type CreatePaymentDTO struct {
+	// Amount is in satang, 0 pays what is due next.
+	Amount int64 `json:"amount" validate:"min=0"`
+}
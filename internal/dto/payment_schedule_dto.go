@@ -0,0 +1,14 @@
+package dto
+
+type PaymentScheduleDTO struct {
+	// MinAmount is in satang, smaller bookings are paid in full when booking.
+	MinAmount      int64                  `json:"minAmount" validate:"min=0"`
+	DepositPercent int                    `json:"depositPercent" validate:"min=1,max=100"`
+	Instalments    []PaymentInstalmentDTO `json:"instalments" validate:"max=10,dive"`
+	OverdueAction  string                 `json:"overdueAction" validate:"required,oneof=CANCEL FLAG"`
+}
+
+type PaymentInstalmentDTO struct {
+	DaysBeforeStart int `json:"daysBeforeStart" validate:"min=0,max=365"`
+	Percent         int `json:"percent" validate:"min=1,max=100"`
+}
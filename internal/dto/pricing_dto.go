@@ -0,0 +1,45 @@
+package dto
+
+import "time"
+
+type PricingPolicyDTO struct {
+	// Rules are applied in order. An empty list charges the hourly rate.
+	Rules []PricingRuleDTO `json:"rules" validate:"max=20,dive"`
+}
+
+type PricingRuleDTO struct {
+	Name string `json:"name" validate:"required,max=100"`
+	Type string `json:"type" validate:"required,oneof=PEAK_HOURS WEEKEND SEASONAL DURATION_TIER MEMBER_DISCOUNT"`
+	// Percent adjusts the price, negative for discounts.
+	Percent int `json:"percent" validate:"min=-100,max=1000"`
+	// Weekdays, StartTime and EndTime are for PEAK_HOURS. Weekdays are 0 for
+	// Sunday to 6 for Saturday, times are "15:04".
+	Weekdays  []int  `json:"weekdays" validate:"max=7,dive,min=0,max=6"`
+	StartTime string `json:"startTime" validate:"omitempty,len=5"`
+	EndTime   string `json:"endTime" validate:"omitempty,len=5"`
+	// StartDate and EndDate are for SEASONAL, both inclusive.
+	StartDate string `json:"startDate" validate:"omitempty,datetime=2006-01-02"`
+	EndDate   string `json:"endDate" validate:"omitempty,datetime=2006-01-02"`
+	// MinMinutes and MaxMinutes are for DURATION_TIER, MaxMinutes 0 for no
+	// upper bound.
+	MinMinutes int `json:"minMinutes" validate:"min=0"`
+	MaxMinutes int `json:"maxMinutes" validate:"min=0"`
+}
+
+type CreateQuoteDTO struct {
+	ResourceID string    `json:"resourceId" validate:"required,uuid"`
+	StartAt    time.Time `json:"startAt" validate:"required"`
+	EndAt      time.Time `json:"endAt" validate:"required,gtfield=StartAt"`
+	// Seats defaults to 1.
+	Seats     int    `json:"seats" validate:"omitempty,min=1"`
+	PromoCode string `json:"promoCode" validate:"max=32"`
+}
+
+type AddResourceMemberDTO struct {
+	Username string `json:"username" validate:"required"`
+}
+
+type MemberParamDTO struct {
+	ID     string `params:"id" validate:"required,uuid"`
+	UserID string `params:"userId" validate:"required,uuid"`
+}
@@ -0,0 +1,19 @@
+package dto
+
+import "time"
+
+type CreatePromoCodeDTO struct {
+	Code         string `json:"code" validate:"required,alphanum,min=3,max=32"`
+	DiscountType string `json:"discountType" validate:"required,oneof=PERCENT FIXED"`
+	// DiscountValue is a percent for PERCENT codes, satang for FIXED codes.
+	DiscountValue int64      `json:"discountValue" validate:"required,min=1"`
+	ValidFrom     *time.Time `json:"validFrom"`
+	ValidUntil    *time.Time `json:"validUntil"`
+	// MinSpend is in satang.
+	MinSpend int64 `json:"minSpend" validate:"min=0"`
+	// MaxRedemptions and MaxRedemptionsPerUser are unlimited when 0.
+	MaxRedemptions        int      `json:"maxRedemptions" validate:"min=0"`
+	MaxRedemptionsPerUser int      `json:"maxRedemptionsPerUser" validate:"min=0"`
+	ResourceIDs           []string `json:"resourceIds" validate:"max=50,dive,uuid"`
+	Categories            []string `json:"categories" validate:"max=20,dive,required,max=64"`
+}
@@ -0,0 +1,50 @@
+package dto
+
+import "time"
+
+type CreateProviderDTO struct {
+	Name string `json:"name" validate:"required,max=255"`
+	// Username links the provider to the account of the user, if given.
+	Username string `json:"username" validate:"max=255"`
+}
+
+type UpdateProviderHoursDTO struct {
+	// TimeZone is the IANA time zone the hours are in, Asia/Bangkok if empty.
+	TimeZone string `json:"timeZone" validate:"max=64"`
+	// Hours lists the working periods of each weekday the provider works.
+	// Without hours the provider works whenever the resources are open.
+	Hours []WeekdayHoursDTO `json:"hours" validate:"max=7,dive"`
+}
+
+type CreateProviderTimeOffDTO struct {
+	StartAt time.Time `json:"startAt" validate:"required"`
+	EndAt   time.Time `json:"endAt" validate:"required,gtfield=StartAt"`
+	Reason  string    `json:"reason" validate:"max=255"`
+}
+
+type ProviderTimeOffParamDTO struct {
+	ID        string `params:"id" validate:"required,uuid"`
+	TimeOffID string `params:"timeOffId" validate:"required,uuid"`
+}
+
+type CreateServiceDTO struct {
+	ResourceID      string `json:"resourceId" validate:"required,uuid"`
+	Name            string `json:"name" validate:"required,max=255"`
+	DurationMinutes int    `json:"durationMinutes" validate:"required,min=5,max=1440"`
+}
+
+type ProviderServiceParamDTO struct {
+	ID        string `params:"id" validate:"required,uuid"`
+	ServiceID string `params:"serviceId" validate:"required,uuid"`
+}
+
+type SaveProviderServiceDTO struct {
+	// DurationMinutes is how long the provider takes, the duration of the
+	// service if zero.
+	DurationMinutes int `json:"durationMinutes" validate:"omitempty,min=5,max=1440"`
+}
+
+type AppointmentSlotQueryDTO struct {
+	Date       string `query:"date" validate:"required,datetime=2006-01-02"`
+	ProviderID string `query:"providerId" validate:"omitempty,uuid"`
+}
@@ -0,0 +1,10 @@
+package dto
+
+import "time"
+
+type RescheduleBookingDTO struct {
+	// StartAt is the new start of the booking, which keeps its duration.
+	StartAt time.Time `json:"startAt" validate:"required"`
+	// Confirm moves the booking. Without it the change is only previewed.
+	Confirm bool `json:"confirm"`
+}
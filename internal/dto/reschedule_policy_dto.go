@@ -0,0 +1,7 @@
+package dto
+
+type ReschedulePolicyDTO struct {
+	Disabled       bool `json:"disabled"`
+	CutoffHours    int  `json:"cutoffHours" validate:"min=0,max=8760"`
+	MaxReschedules int  `json:"maxReschedules" validate:"min=0,max=100"`
+}
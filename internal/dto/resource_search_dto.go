@@ -0,0 +1,36 @@
+package dto
+
+type ResourceSearchQueryDTO struct {
+	// Q is matched against the name and description of the resources.
+	Q        string `query:"q" validate:"max=200"`
+	Category string `query:"category" validate:"max=64"`
+	// Capacity is the least number of seats the resource must have, and have
+	// free when searching by availability.
+	Capacity int    `query:"capacity" validate:"omitempty,min=1,max=10000"`
+	MinPrice *int64 `query:"minPrice" validate:"omitempty,min=0"`
+	MaxPrice *int64 `query:"maxPrice" validate:"omitempty,min=0"`
+	// Amenities are all required, each given as its own amenities parameter.
+	Amenities []string `query:"amenities" validate:"max=20,dive,required,max=64"`
+	MinRating float64  `query:"minRating" validate:"omitempty,min=0,max=5"`
+	// From and To restrict the results to resources free for the whole
+	// period, given together.
+	From string `query:"from" validate:"required_with=To,omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	To   string `query:"to" validate:"required_with=From,omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	// Lat and Lng are the point distances are measured from, RadiusKm how far
+	// from it resources may be.
+	Lat      *float64 `query:"lat" validate:"required_with=Lng RadiusKm,omitempty,min=-90,max=90"`
+	Lng      *float64 `query:"lng" validate:"required_with=Lat,omitempty,min=-180,max=180"`
+	RadiusKm float64  `query:"radiusKm" validate:"omitempty,gt=0,max=20000"`
+	// MinLat, MaxLat, MinLng and MaxLng bound the area of the map shown,
+	// given together. MinLng is above MaxLng for areas across the
+	// antimeridian.
+	MinLat *float64 `query:"minLat" validate:"required_with=MaxLat MinLng MaxLng,omitempty,min=-90,max=90"`
+	MaxLat *float64 `query:"maxLat" validate:"required_with=MinLat MinLng MaxLng,omitempty,min=-90,max=90"`
+	MinLng *float64 `query:"minLng" validate:"required_with=MinLat MaxLat MaxLng,omitempty,min=-180,max=180"`
+	MaxLng *float64 `query:"maxLng" validate:"required_with=MinLat MaxLat MinLng,omitempty,min=-180,max=180"`
+	// Sort defaults to relevance when searching text, to distance when
+	// searching around a point.
+	Sort string `query:"sort" validate:"omitempty,oneof=relevance price price_desc rating distance"`
+	Page uint   `query:"page" validate:"omitempty,min=1"`
+	Size uint   `query:"size" validate:"omitempty,min=1,max=100"`
+}
@@ -0,0 +1,20 @@
+package dto
+
+type CreateReviewDTO struct {
+	Rating int    `json:"rating" validate:"required,min=1,max=5"`
+	Text   string `json:"text" validate:"max=4000"`
+}
+
+type ReviewQueryDTO struct {
+	Page uint `query:"page" validate:"omitempty,min=1"`
+	Size uint `query:"size" validate:"omitempty,min=1,max=100"`
+}
+
+type ReplyReviewDTO struct {
+	// Reply replaces the previous reply, an empty one removes it.
+	Reply string `json:"reply" validate:"max=4000"`
+}
+
+type ModerateReviewDTO struct {
+	Status string `json:"status" validate:"required,oneof=PUBLISHED HIDDEN"`
+}
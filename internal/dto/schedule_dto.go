@@ -0,0 +1,57 @@
+package dto
+
+import "time"
+
+type UpdateOpeningHoursDTO struct {
+	// TimeZone is the IANA time zone the hours are in, Asia/Bangkok if empty.
+	TimeZone string `json:"timeZone" validate:"max=64"`
+	// HolidayCalendar is the code of the public holiday calendar the resource
+	// closes on, or empty to stay open on public holidays.
+	HolidayCalendar string `json:"holidayCalendar" validate:"max=16"`
+	// Hours lists the opening periods of each weekday the resource opens.
+	// Without hours the resource is open around the clock.
+	Hours []WeekdayHoursDTO `json:"hours" validate:"max=7,dive"`
+}
+
+type WeekdayHoursDTO struct {
+	// Weekday is 0 for Sunday to 6 for Saturday.
+	Weekday int                `json:"weekday" validate:"min=0,max=6"`
+	Periods []OpeningPeriodDTO `json:"periods" validate:"max=10,dive"`
+}
+
+type OpeningPeriodDTO struct {
+	// OpensAt and ClosesAt are local times of day as "15:04". ClosesAt may be
+	// "24:00" for periods open until midnight.
+	OpensAt  string `json:"opensAt" validate:"required,len=5"`
+	ClosesAt string `json:"closesAt" validate:"required,len=5"`
+}
+
+type DateParamDTO struct {
+	ID   string `params:"id" validate:"required,uuid"`
+	Date string `params:"date" validate:"required,datetime=2006-01-02"`
+}
+
+type DateOverrideDTO struct {
+	// Periods replace the opening hours of the date, no periods close it.
+	Periods []OpeningPeriodDTO `json:"periods" validate:"max=10,dive"`
+	Reason  string             `json:"reason" validate:"max=255"`
+}
+
+type CreateBlackoutPeriodDTO struct {
+	StartAt time.Time `json:"startAt" validate:"required"`
+	EndAt   time.Time `json:"endAt" validate:"required,gtfield=StartAt"`
+	Reason  string    `json:"reason" validate:"max=255"`
+}
+
+type BlackoutParamDTO struct {
+	ID         string `params:"id" validate:"required,uuid"`
+	BlackoutID string `params:"blackoutId" validate:"required,uuid"`
+}
+
+type HolidayCalendarParamDTO struct {
+	Code string `params:"code" validate:"required,max=16"`
+}
+
+type HolidayQueryDTO struct {
+	Year int `query:"year" validate:"omitempty,min=2000,max=2100"`
+}
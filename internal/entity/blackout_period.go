@@ -0,0 +1,24 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BlackoutPeriod blocks bookings of a resource, e.g. for maintenance,
+// regardless of its opening hours.
+type BlackoutPeriod struct {
+	ID         uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	ResourceID uuid.UUID `gorm:"type:uuid;index"`
+	StartAt    time.Time `gorm:"index"`
+	EndAt      time.Time `gorm:"index"`
+	Reason     string
+	// ExternalCalendarID is set on the periods imported from an external
+	// calendar, which are replaced each time it is synced.
+	ExternalCalendarID *uuid.UUID `gorm:"type:uuid;index"`
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	DeletedAt          gorm.DeletedAt `gorm:"index"`
+}
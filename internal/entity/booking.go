@@ -0,0 +1,119 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	BOOKING_STATUS_PENDING   = "PENDING"
+	BOOKING_STATUS_CONFIRMED = "CONFIRMED"
+	BOOKING_STATUS_CANCELLED = "CANCELLED"
+	BOOKING_STATUS_FAILED    = "FAILED"
+	// BOOKING_STATUS_EXPIRED is a pending booking whose deposit wasn't paid in
+	// time to keep holding its slot.
+	BOOKING_STATUS_EXPIRED = "EXPIRED"
+	// BOOKING_STATUS_NO_SHOW is a confirmed booking the customer wasn't
+	// checked in to within the grace period after it started.
+	BOOKING_STATUS_NO_SHOW = "NO_SHOW"
+)
+
+type Booking struct {
+	ID             uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;index"`
+	UserID         uuid.UUID `gorm:"type:uuid;index"`
+	ResourceID     uuid.UUID `gorm:"type:uuid;index"`
+	StartAt        time.Time `gorm:"index"`
+	EndAt          time.Time `gorm:"index"`
+	Status         string    `gorm:"index"`
+	Seats          int       `gorm:"default:1"`
+	// SeriesID is set on the occurrences of a recurring booking, RecurrenceID
+	// is the start the occurrence was generated with by the series rule.
+	SeriesID     *uuid.UUID `gorm:"type:uuid;index"`
+	RecurrenceID *time.Time
+	// ServiceID and ProviderID are set on appointments, bookings of the
+	// resource of a service together with the provider performing it.
+	ServiceID  *uuid.UUID `gorm:"type:uuid;index"`
+	ProviderID *uuid.UUID `gorm:"type:uuid;index"`
+	// Amount and RefundAmount are in satang. Amount is the total of Quote.
+	Amount       int64
+	RefundAmount int64
+	// Quote is the price breakdown the booking was charged, with the pricing
+	// rules it was computed from.
+	Quote Quote `gorm:"type:jsonb"`
+	// CancellationPolicy is a copy of the resource policy at booking time so
+	// later edits to the policy don't change the terms of existing bookings.
+	CancellationPolicy CancellationRules `gorm:"type:jsonb"`
+	// RescheduleCount is how many times the booking was moved to another
+	// slot.
+	RescheduleCount int
+	// PaymentSchedule is when the amount is due, the deposit first, computed
+	// from the schedule of the resource at booking time. PaidAmount is what
+	// has been captured so far and NextDueAt is when the first due it does
+	// not cover is due.
+	PaymentSchedule PaymentDues `gorm:"type:jsonb"`
+	PaidAmount      int64
+	NextDueAt       *time.Time `gorm:"index"`
+	// OverdueAction is copied from the schedule of the resource. OverdueAt is
+	// set when a booking is flagged for its balance being overdue.
+	OverdueAction string
+	OverdueAt     *time.Time
+	// Sequence is the revision of the time and status of the booking as
+	// published in calendars, raised when it is moved or cancelled.
+	Sequence int
+	// RemindedAt is when the reminder of the upcoming booking was queued.
+	RemindedAt *time.Time
+	// CheckedInAt is when the customer arrived, checked in by the member of
+	// staff CheckedInBy.
+	CheckedInAt *time.Time
+	CheckedInBy *uuid.UUID `gorm:"type:uuid"`
+	// NoShowFeeBookingID is set on a no-show once its fee was charged on a
+	// later booking of the customer, so it is charged only once. It is
+	// cleared when that booking is cancelled, expires or fails, so the fee is
+	// charged on the next one instead.
+	NoShowFeeBookingID *uuid.UUID `gorm:"type:uuid"`
+	CancelledAt        *time.Time
+	// LastError is why the background handling of the booking, such as
+	// expiring its hold, last failed at FailedAt.
+	LastError string
+	FailedAt  *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+// IsActive reports whether the booking still holds its slot.
+func (booking *Booking) IsActive() bool {
+	return booking.Status == BOOKING_STATUS_PENDING || booking.Status == BOOKING_STATUS_CONFIRMED
+}
+
+// IsCompleted reports whether the booking was confirmed and is over.
+func (booking *Booking) IsCompleted(now time.Time) bool {
+	return booking.Status == BOOKING_STATUS_CONFIRMED && !booking.EndAt.After(now)
+}
+
+// DepositAmount returns the amount due when booking, which must be paid for
+// the booking to be confirmed.
+func (booking *Booking) DepositAmount() int64 {
+	if len(booking.PaymentSchedule) == 0 {
+		return 0
+	}
+	return booking.PaymentSchedule[0].Amount
+}
+
+// HasPaymentSchedule reports whether the booking is paid through payments,
+// which the ledger records.
+func (booking *Booking) HasPaymentSchedule() bool {
+	return len(booking.PaymentSchedule) > 0
+}
+
+// OutstandingAmount returns what is left to pay of the booking.
+func (booking *Booking) OutstandingAmount() int64 {
+	return max(booking.Amount-booking.PaidAmount, 0)
+}
+
+func (booking *Booking) SeatPeriod() SeatPeriod {
+	return SeatPeriod{StartAt: booking.StartAt, EndAt: booking.EndAt, Seats: booking.Seats}
+}
@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	BOOKING_CHANGE_RESCHEDULED = "RESCHEDULED"
+)
+
+// BookingChange is an entry of the history of a booking, recording the time
+// and amount it had before and after a change made by the user ChangedBy.
+// Amounts are in satang.
+type BookingChange struct {
+	ID              uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	OrganizationID  uuid.UUID `gorm:"type:uuid;index"`
+	BookingID       uuid.UUID `gorm:"type:uuid;index"`
+	ChangedBy       uuid.UUID `gorm:"type:uuid"`
+	Type            string
+	PreviousStartAt time.Time
+	PreviousEndAt   time.Time
+	StartAt         time.Time
+	EndAt           time.Time
+	PreviousAmount  int64
+	Amount          int64
+	CreatedAt       time.Time
+}
@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	BOOKING_SERIES_STATUS_ACTIVE    = "ACTIVE"
+	BOOKING_SERIES_STATUS_CANCELLED = "CANCELLED"
+)
+
+// BookingSeries is a recurring booking. Its occurrences are materialized as
+// bookings up to the recurrence horizon when the series is created.
+type BookingSeries struct {
+	ID             uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;index"`
+	UserID         uuid.UUID `gorm:"type:uuid;index"`
+	ResourceID     uuid.UUID `gorm:"type:uuid;index"`
+	// StartAt is the DTSTART of the rule, RRule its RFC 5545 RRULE value.
+	StartAt         time.Time
+	DurationMinutes int
+	Seats           int `gorm:"default:1"`
+	RRule           string
+	ExDates         TimeList `gorm:"type:jsonb"`
+	TimeZone        string
+	Status          string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	DeletedAt       gorm.DeletedAt `gorm:"index"`
+}
+
+func (series *BookingSeries) Location() *time.Location {
+	location, err := time.LoadLocation(series.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+type TimeList []time.Time
+
+func (times TimeList) Value() (driver.Value, error) {
+	if times == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(times)
+	return string(b), err
+}
+
+func (times *TimeList) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*times = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, times)
+	case string:
+		return json.Unmarshal([]byte(v), times)
+	}
+	return fmt.Errorf("cannot scan %T into TimeList", value)
+}
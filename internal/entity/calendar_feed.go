@@ -0,0 +1,18 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CalendarFeed is the secret iCalendar subscription of the bookings of a
+// user. The token in its URL is all that is needed to read it, so it is
+// replaced rather than shown again when the user suspects it leaked.
+type CalendarFeed struct {
+	ID        uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	UserID    uuid.UUID `gorm:"type:uuid;uniqueIndex"`
+	Token     string    `gorm:"uniqueIndex"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
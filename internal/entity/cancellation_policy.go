@@ -0,0 +1,80 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CancellationPolicy struct {
+	ID             uuid.UUID         `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	OrganizationID uuid.UUID         `gorm:"type:uuid;index"`
+	ResourceID     uuid.UUID         `gorm:"type:uuid;uniqueIndex"`
+	Rules          CancellationRules `gorm:"type:jsonb"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DeletedAt      gorm.DeletedAt `gorm:"index"`
+}
+
+// CancellationRule refunds RefundPercent of the booking amount when the booking
+// is cancelled at least HoursBeforeStart hours before it starts.
+type CancellationRule struct {
+	HoursBeforeStart int `json:"hoursBeforeStart"`
+	RefundPercent    int `json:"refundPercent"`
+}
+
+type CancellationRules []CancellationRule
+
+func (rules CancellationRules) Value() (driver.Value, error) {
+	if rules == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(rules)
+	return string(b), err
+}
+
+func (rules *CancellationRules) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*rules = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, rules)
+	case string:
+		return json.Unmarshal([]byte(v), rules)
+	}
+	return fmt.Errorf("cannot scan %T into CancellationRules", value)
+}
+
+// MatchRule returns the most generous rule that applies when cancelling at the
+// given time, or nil when no rule applies and nothing is refunded.
+func (rules CancellationRules) MatchRule(cancelAt time.Time, startAt time.Time) *CancellationRule {
+	sorted := make(CancellationRules, len(rules))
+	copy(sorted, rules)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].HoursBeforeStart > sorted[j].HoursBeforeStart
+	})
+
+	noticePeriod := startAt.Sub(cancelAt)
+	for i := range sorted {
+		if noticePeriod >= time.Duration(sorted[i].HoursBeforeStart)*time.Hour {
+			return &sorted[i]
+		}
+	}
+	return nil
+}
+
+// RefundAmount returns the amount in satang refunded when cancelling at the
+// given time. Fractions of a satang are rounded down.
+func (rules CancellationRules) RefundAmount(amount int64, cancelAt time.Time, startAt time.Time) int64 {
+	rule := rules.MatchRule(cancelAt, startAt)
+	if rule == nil {
+		return 0
+	}
+	return amount * int64(rule.RefundPercent) / 100
+}
@@ -0,0 +1,56 @@
+package entity_test
+
+import (
+	"booking/internal/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCancellationRulesRefundAmount(t *testing.T) {
+	rules := entity.CancellationRules{
+		{HoursBeforeStart: 24, RefundPercent: 50},
+		{HoursBeforeStart: 48, RefundPercent: 100},
+	}
+	startAt := time.Date(2024, 5, 10, 10, 0, 0, 0, time.UTC)
+	const amount = 150000
+
+	testCases := []struct {
+		Name     string
+		CancelAt time.Time
+		Expected int64
+	}{
+		{Name: "More than 48 hours before start, should refund in full", CancelAt: startAt.Add(-72 * time.Hour), Expected: 150000},
+		{Name: "Exactly 48 hours before start, should refund in full", CancelAt: startAt.Add(-48 * time.Hour), Expected: 150000},
+		{Name: "Between 24 and 48 hours before start, should refund a half", CancelAt: startAt.Add(-30 * time.Hour), Expected: 75000},
+		{Name: "Less than 24 hours before start, should not refund", CancelAt: startAt.Add(-2 * time.Hour), Expected: 0},
+		{Name: "After the booking started, should not refund", CancelAt: startAt.Add(time.Hour), Expected: 0},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			assert.Equal(t, testCase.Expected, rules.RefundAmount(amount, testCase.CancelAt, startAt))
+		})
+	}
+
+	t.Run("Without any rule, should not refund", func(t *testing.T) {
+		assert.Equal(t, int64(0), entity.CancellationRules{}.RefundAmount(amount, startAt.Add(-72*time.Hour), startAt))
+	})
+
+	t.Run("Should round fractions of a satang down", func(t *testing.T) {
+		rules := entity.CancellationRules{{HoursBeforeStart: 0, RefundPercent: 33}}
+		assert.Equal(t, int64(33), rules.RefundAmount(101, startAt.Add(-time.Hour), startAt))
+	})
+}
+
+func TestCancellationRulesValueAndScan(t *testing.T) {
+	rules := entity.CancellationRules{{HoursBeforeStart: 48, RefundPercent: 100}}
+	value, err := rules.Value()
+	assert.Nil(t, err)
+
+	scanned := entity.CancellationRules{}
+	err = scanned.Scan([]byte(value.(string)))
+	assert.Nil(t, err)
+	assert.Equal(t, rules, scanned)
+}
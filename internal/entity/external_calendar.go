@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExternalCalendar is a calendar the resource is also managed in. Its busy
+// events are imported as blackout periods of the resource, again each time it
+// is synced if it has a URL. Uploaded calendars are imported once.
+type ExternalCalendar struct {
+	ID         uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	ResourceID uuid.UUID `gorm:"type:uuid;index"`
+	Name       string
+	URL        string
+	// SyncedAt is when the calendar was last imported, SyncError the error
+	// message of why the latest attempt since failed, if it did.
+	SyncedAt  *time.Time `gorm:"index"`
+	SyncError string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
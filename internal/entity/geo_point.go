@@ -0,0 +1,27 @@
+package entity
+
+import "math"
+
+// EARTH_RADIUS_KM is the mean radius of the Earth distances are computed
+// with.
+const EARTH_RADIUS_KM = 6371.0
+
+// GeoPoint is a position on the Earth in decimal degrees.
+type GeoPoint struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// DistanceKm returns the great-circle distance to the other point by the
+// haversine formula, which the resource search computes the same way.
+func (point GeoPoint) DistanceKm(other GeoPoint) float64 {
+	lat1, lat2 := radians(point.Latitude), radians(other.Latitude)
+	dLat := lat2 - lat1
+	dLng := radians(other.Longitude - point.Longitude)
+	a := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLng/2), 2)
+	return EARTH_RADIUS_KM * 2 * math.Asin(math.Sqrt(min(a, 1)))
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
@@ -0,0 +1,30 @@
+package entity_test
+
+import (
+	"booking/internal/entity"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeoPointDistanceKm(t *testing.T) {
+	siam := entity.GeoPoint{Latitude: 13.7462, Longitude: 100.5347}
+
+	t.Run("When measure between two cities, should return the great-circle distance", func(t *testing.T) {
+		chiangMai := entity.GeoPoint{Latitude: 18.7883, Longitude: 98.9853}
+
+		assert.InDelta(t, 584, siam.DistanceKm(chiangMai), 5)
+		assert.InDelta(t, siam.DistanceKm(chiangMai), chiangMai.DistanceKm(siam), 1e-9)
+	})
+
+	t.Run("When measure to the same point, should return zero", func(t *testing.T) {
+		assert.Zero(t, siam.DistanceKm(siam))
+	})
+
+	t.Run("When measure across the antimeridian, should go the short way around", func(t *testing.T) {
+		fiji := entity.GeoPoint{Latitude: -17.7134, Longitude: 178.065}
+		samoa := entity.GeoPoint{Latitude: -13.759, Longitude: -172.1046}
+
+		assert.Less(t, fiji.DistanceKm(samoa), 1200.0)
+	})
+}
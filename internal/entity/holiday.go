@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HolidayCalendar is a set of public holidays resources can observe.
+type HolidayCalendar struct {
+	Code      string `gorm:"primarykey"`
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Holiday closes the resources observing its calendar on a local date.
+type Holiday struct {
+	ID           uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	CalendarCode string    `gorm:"uniqueIndex:idx_holiday_date"`
+	Date         string    `gorm:"uniqueIndex:idx_holiday_date"`
+	Name         string
+	NameTH       string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
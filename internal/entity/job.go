@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	JOB_STATUS_PENDING   = "PENDING"
+	JOB_STATUS_RUNNING   = "RUNNING"
+	JOB_STATUS_SUCCEEDED = "SUCCEEDED"
+	JOB_STATUS_FAILED    = "FAILED"
+)
+
+// Job is a run of background work of a kind, due at RunAt. Key is unique so
+// that every process of every instance can schedule the same run and only one
+// is kept. A worker leases the job until LockedUntil while running it, after
+// which another worker may take it over.
+type Job struct {
+	ID          uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	Kind        string    `gorm:"index"`
+	Key         string    `gorm:"uniqueIndex"`
+	Status      string    `gorm:"index"`
+	RunAt       time.Time `gorm:"index"`
+	Attempts    int
+	MaxAttempts int
+	LockedBy    string
+	LockedUntil *time.Time
+	LastError   string
+	StartedAt   *time.Time
+	FinishedAt  *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// IsLeaseExpired reports whether the worker running the job stopped renewing
+// its lease, e.g. because its process died.
+func (job *Job) IsLeaseExpired(now time.Time) bool {
+	return job.Status == JOB_STATUS_RUNNING && job.LockedUntil != nil && !now.Before(*job.LockedUntil)
+}
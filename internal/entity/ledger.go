@@ -0,0 +1,132 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Accounts of the ledger of each organization, the business the money of the
+// bookings of its resources belongs to. Cash is kept per payment provider.
+const (
+	LEDGER_ACCOUNT_RECEIVABLE        = "accounts-receivable"
+	LEDGER_ACCOUNT_CASH              = "cash"
+	LEDGER_ACCOUNT_REVENUE           = "booking-revenue"
+	LEDGER_ACCOUNT_DISCOUNTS         = "promo-discounts"
+	LEDGER_ACCOUNT_CANCELLATION_FEES = "cancellation-fees"
+	LEDGER_ACCOUNT_REFUNDS_PAYABLE   = "refunds-payable"
+)
+
+const (
+	LEDGER_ACCOUNT_TYPE_ASSET          = "ASSET"
+	LEDGER_ACCOUNT_TYPE_LIABILITY      = "LIABILITY"
+	LEDGER_ACCOUNT_TYPE_REVENUE        = "REVENUE"
+	LEDGER_ACCOUNT_TYPE_CONTRA_REVENUE = "CONTRA_REVENUE"
+)
+
+const (
+	JOURNAL_ENTRY_BOOKING      = "BOOKING"
+	JOURNAL_ENTRY_PAYMENT      = "PAYMENT"
+	JOURNAL_ENTRY_CANCELLATION = "CANCELLATION"
+	JOURNAL_ENTRY_REFUND       = "REFUND"
+	// JOURNAL_ENTRY_RESCHEDULE adjusts the booking entry by the change of the
+	// price of a rescheduled booking.
+	JOURNAL_ENTRY_RESCHEDULE = "RESCHEDULE"
+)
+
+var ErrUnbalancedJournalEntry = errors.New("the debits and credits of the journal entry don't balance")
+
+type LedgerAccount struct {
+	ID             uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_ledger_accounts_organization_code"`
+	Code           string    `gorm:"uniqueIndex:idx_ledger_accounts_organization_code"`
+	Type           string
+	CreatedAt      time.Time
+}
+
+// JournalEntry records a money movement of a booking as lines debiting and
+// crediting the accounts of the organization by the same total. Entries are never
+// updated or deleted, a movement is undone by an entry reversing it.
+type JournalEntry struct {
+	ID             uuid.UUID  `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	OrganizationID uuid.UUID  `gorm:"type:uuid;index"`
+	BookingID      *uuid.UUID `gorm:"type:uuid;index"`
+	PaymentID      *uuid.UUID `gorm:"type:uuid"`
+	Type           string
+	Description    string
+	PostedAt       time.Time     `gorm:"index"`
+	Lines          []JournalLine `gorm:"foreignKey:JournalEntryID"`
+}
+
+// JournalLine is in satang, either a debit or a credit.
+type JournalLine struct {
+	ID             uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	JournalEntryID uuid.UUID `gorm:"type:uuid;index"`
+	AccountID      uuid.UUID `gorm:"type:uuid;index"`
+	Debit          int64
+	Credit         int64
+}
+
+// AccountBalance is the total debited and credited to an account.
+type AccountBalance struct {
+	Code   string
+	Type   string
+	Debit  int64
+	Credit int64
+}
+
+// StatementLine is a line posted to an account with the entry it belongs to.
+// Balance is the debits less the credits of the account up to the line.
+type StatementLine struct {
+	ID          uuid.UUID
+	EntryID     uuid.UUID
+	BookingID   *uuid.UUID
+	Type        string
+	Description string
+	PostedAt    time.Time
+	Debit       int64
+	Credit      int64
+	Balance     int64
+}
+
+// Validate checks that the entry has lines and that they balance.
+func (entry *JournalEntry) Validate() error {
+	var debit, credit int64
+	for _, line := range entry.Lines {
+		if line.Debit < 0 || line.Credit < 0 {
+			return ErrUnbalancedJournalEntry
+		}
+		debit += line.Debit
+		credit += line.Credit
+	}
+	if len(entry.Lines) == 0 || debit != credit {
+		return ErrUnbalancedJournalEntry
+	}
+	return nil
+}
+
+// CashAccount returns the code of the account of the money held by the
+// payment provider.
+func CashAccount(provider string) string {
+	return LEDGER_ACCOUNT_CASH + ":" + provider
+}
+
+func LedgerAccountType(code string) string {
+	switch {
+	case code == LEDGER_ACCOUNT_RECEIVABLE, strings.HasPrefix(code, LEDGER_ACCOUNT_CASH+":"):
+		return LEDGER_ACCOUNT_TYPE_ASSET
+	case code == LEDGER_ACCOUNT_REFUNDS_PAYABLE:
+		return LEDGER_ACCOUNT_TYPE_LIABILITY
+	case code == LEDGER_ACCOUNT_DISCOUNTS:
+		return LEDGER_ACCOUNT_TYPE_CONTRA_REVENUE
+	}
+	return LEDGER_ACCOUNT_TYPE_REVENUE
+}
+
+// IsDebitNormal reports whether debits increase the balance of accounts of
+// the type.
+func IsDebitNormal(accountType string) bool {
+	return accountType == LEDGER_ACCOUNT_TYPE_ASSET || accountType == LEDGER_ACCOUNT_TYPE_CONTRA_REVENUE
+}
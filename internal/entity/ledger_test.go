@@ -0,0 +1,44 @@
+package entity_test
+
+import (
+	"booking/internal/entity"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournalEntryValidate(t *testing.T) {
+	receivable, revenue := uuid.New(), uuid.New()
+
+	t.Run("When the debits equal the credits, should accept the entry", func(t *testing.T) {
+		entry := &entity.JournalEntry{Lines: []entity.JournalLine{
+			{AccountID: receivable, Debit: 75000},
+			{AccountID: revenue, Credit: 75000},
+		}}
+
+		assert.NoError(t, entry.Validate())
+	})
+
+	t.Run("When the debits differ from the credits, should reject the entry", func(t *testing.T) {
+		entry := &entity.JournalEntry{Lines: []entity.JournalLine{
+			{AccountID: receivable, Debit: 75000},
+			{AccountID: revenue, Credit: 70000},
+		}}
+
+		assert.ErrorIs(t, entry.Validate(), entity.ErrUnbalancedJournalEntry)
+	})
+
+	t.Run("When a line is negative, should reject the entry", func(t *testing.T) {
+		entry := &entity.JournalEntry{Lines: []entity.JournalLine{
+			{AccountID: receivable, Debit: -75000},
+			{AccountID: revenue, Debit: 75000},
+		}}
+
+		assert.ErrorIs(t, entry.Validate(), entity.ErrUnbalancedJournalEntry)
+	})
+
+	t.Run("When the entry has no lines, should reject it", func(t *testing.T) {
+		assert.ErrorIs(t, (&entity.JournalEntry{}).Validate(), entity.ErrUnbalancedJournalEntry)
+	})
+}
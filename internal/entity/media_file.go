@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MEDIA_KIND_PHOTO      = "PHOTO"
+	MEDIA_KIND_FLOOR_PLAN = "FLOOR_PLAN"
+)
+
+// MediaFile is a file uploaded to the gallery of a resource, its content in
+// the blob store under Key.
+type MediaFile struct {
+	ID             uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;index"`
+	ResourceID     uuid.UUID `gorm:"type:uuid;index:idx_media_files_resource_position"`
+	Kind           string
+	// FileName is the name the file was uploaded with.
+	FileName    string
+	ContentType string
+	Size        int64
+	Key         string
+	// ThumbnailKey is empty for files that aren't images. Width and Height
+	// are the size of the image.
+	ThumbnailKey string
+	Width        int
+	Height       int
+	// Position orders the gallery of the resource, from 0.
+	Position int `gorm:"index:idx_media_files_resource_position"`
+	// Private files are only listed to the owner and staff of the resource,
+	// and only downloaded with a signed URL.
+	Private    bool
+	UploadedBy uuid.UUID `gorm:"type:uuid"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// HasThumbnail reports whether a thumbnail was made of the file.
+func (file *MediaFile) HasThumbnail() bool {
+	return file.ThumbnailKey != ""
+}
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	NOTIFICATION_STATUS_PENDING = "PENDING"
+	NOTIFICATION_STATUS_SENT    = "SENT"
+	NOTIFICATION_STATUS_DEAD    = "DEAD"
+)
+
+// NotificationPreference is how a user wants to be notified. Users without
+// one are emailed at their username in English.
+type NotificationPreference struct {
+	UserID     uuid.UUID `gorm:"primarykey;type:uuid"`
+	Email      bool      `gorm:"default:true"`
+	Phone      string
+	SMS        bool
+	LineUserID string
+	Line       bool
+	Language   string `gorm:"default:en"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Notification is a message in the outbox. It is written in the transaction
+// of the change it is about and delivered afterwards by the dispatcher, which
+// retries it at NextAttemptAt until it is sent or given up on as dead.
+type Notification struct {
+	ID            uuid.UUID  `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	UserID        uuid.UUID  `gorm:"type:uuid;index"`
+	BookingID     *uuid.UUID `gorm:"type:uuid;index"`
+	Event         string
+	Channel       string
+	Recipient     string
+	Subject       string
+	Body          string
+	Status        string `gorm:"index"`
+	Attempts      int
+	NextAttemptAt time.Time `gorm:"index"`
+	LastError     string
+	SentAt        *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OpeningPeriod is a period of a day in minutes since local midnight in the
+// time zone of the resource.
+type OpeningPeriod struct {
+	OpensAt  int `json:"opensAt"`
+	ClosesAt int `json:"closesAt"`
+}
+
+type OpeningPeriods []OpeningPeriod
+
+func (periods OpeningPeriods) Value() (driver.Value, error) {
+	if periods == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(periods)
+	return string(b), err
+}
+
+func (periods *OpeningPeriods) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*periods = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, periods)
+	case string:
+		return json.Unmarshal([]byte(v), periods)
+	}
+	return fmt.Errorf("cannot scan %T into OpeningPeriods", value)
+}
+
+// OpeningHours are the weekly opening periods of a resource on a weekday.
+// Once a resource has opening hours, weekdays without them are closed.
+// Rows are replaced as a whole rather than soft deleted.
+type OpeningHours struct {
+	ID         uuid.UUID      `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	ResourceID uuid.UUID      `gorm:"type:uuid;uniqueIndex:idx_opening_hours_weekday"`
+	Weekday    int            `gorm:"uniqueIndex:idx_opening_hours_weekday"`
+	Periods    OpeningPeriods `gorm:"type:jsonb"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// DateOverride replaces the weekly opening hours of a resource on a local
+// date, such as opening on a holiday or closing early. An override without
+// periods closes the resource for the day.
+type DateOverride struct {
+	ID         uuid.UUID      `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	ResourceID uuid.UUID      `gorm:"type:uuid;uniqueIndex:idx_date_override_date"`
+	Date       string         `gorm:"uniqueIndex:idx_date_override_date"`
+	Periods    OpeningPeriods `gorm:"type:jsonb"`
+	Reason     string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
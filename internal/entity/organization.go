@@ -0,0 +1,39 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// ORGANIZATION_ROLE_OWNER manages the organization and its members.
+	ORGANIZATION_ROLE_OWNER = "OWNER"
+	// ORGANIZATION_ROLE_ADMIN manages the resources and promo codes of the
+	// organization.
+	ORGANIZATION_ROLE_ADMIN  = "ADMIN"
+	ORGANIZATION_ROLE_MEMBER = "MEMBER"
+)
+
+// Organization is a tenant of the deployment, a venue owning resources,
+// their bookings and their settings. Users book its resources without being
+// members of it.
+type Organization struct {
+	ID        uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	Name      string
+	Slug      string `gorm:"uniqueIndex"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+// OrganizationMember is the role of a user in an organization.
+type OrganizationMember struct {
+	ID             uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_organization_member"`
+	UserID         uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_organization_member"`
+	Role           string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
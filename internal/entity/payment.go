@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	PAYMENT_STATUS_PENDING  = "PENDING"
+	PAYMENT_STATUS_CAPTURED = "CAPTURED"
+	PAYMENT_STATUS_FAILED   = "FAILED"
+	// PAYMENT_STATUS_VOIDED is an authorization left uncaptured because the
+	// booking was no longer pending when it arrived.
+	PAYMENT_STATUS_VOIDED = "VOIDED"
+)
+
+// Payment is an intent of a payment provider collecting the amount of a
+// booking.
+type Payment struct {
+	ID             uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;index"`
+	BookingID      uuid.UUID `gorm:"type:uuid;index"`
+	UserID         uuid.UUID `gorm:"type:uuid;index"`
+	Provider       string
+	IntentID       string `gorm:"uniqueIndex"`
+	// Amount and RefundedAmount are in satang.
+	Amount         int64
+	RefundedAmount int64
+	Currency       string
+	Status         string `gorm:"index"`
+	FailureReason  string
+	// QRPayload is the QR code the customer pays by, for providers paid by
+	// QR code.
+	QRPayload string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// PaymentEvent records a webhook event once it is handled so redelivered
+// events are ignored.
+type PaymentEvent struct {
+	Provider  string `gorm:"primaryKey"`
+	EventID   string `gorm:"primaryKey"`
+	Type      string
+	IntentID  string `gorm:"index"`
+	CreatedAt time.Time
+}
@@ -0,0 +1,233 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	OVERDUE_ACTION_CANCEL = "CANCEL"
+	OVERDUE_ACTION_FLAG   = "FLAG"
+)
+
+// PaymentSchedule lets bookings of at least MinAmount satang be paid with a
+// deposit of DepositPercent up front and the balance in instalments before
+// they start.
+type PaymentSchedule struct {
+	ID             uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;index"`
+	ResourceID     uuid.UUID `gorm:"type:uuid;uniqueIndex"`
+	MinAmount      int64
+	DepositPercent int
+	Instalments    PaymentInstalments `gorm:"type:jsonb"`
+	// OverdueAction is what happens to a booking whose balance is not paid by
+	// the due date, OVERDUE_ACTION_CANCEL or OVERDUE_ACTION_FLAG.
+	OverdueAction string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	DeletedAt     gorm.DeletedAt `gorm:"index"`
+}
+
+// PaymentInstalment is Percent of the booking amount due DaysBeforeStart days
+// before the booking starts.
+type PaymentInstalment struct {
+	DaysBeforeStart int `json:"daysBeforeStart"`
+	Percent         int `json:"percent"`
+}
+
+type PaymentInstalments []PaymentInstalment
+
+func (instalments PaymentInstalments) Value() (driver.Value, error) {
+	if instalments == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(instalments)
+	return string(b), err
+}
+
+func (instalments *PaymentInstalments) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*instalments = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, instalments)
+	case string:
+		return json.Unmarshal([]byte(v), instalments)
+	}
+	return fmt.Errorf("cannot scan %T into PaymentInstalments", value)
+}
+
+// PaymentDue is an amount in satang of a booking due at DueAt.
+type PaymentDue struct {
+	DueAt  time.Time `json:"dueAt"`
+	Amount int64     `json:"amount"`
+}
+
+type PaymentDues []PaymentDue
+
+func (dues PaymentDues) Value() (driver.Value, error) {
+	if dues == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(dues)
+	return string(b), err
+}
+
+func (dues *PaymentDues) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*dues = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, dues)
+	case string:
+		return json.Unmarshal([]byte(v), dues)
+	}
+	return fmt.Errorf("cannot scan %T into PaymentDues", value)
+}
+
+// Dues splits the amount of a booking made at bookedAt into the deposit due
+// when booking and the instalments of the balance, oldest first. Instalments
+// that would already be due are added to the deposit and the last due takes
+// the satang lost to rounding. Bookings under the minimum amount, or without
+// a schedule, are paid in full when booking.
+func (schedule *PaymentSchedule) Dues(amount int64, bookedAt time.Time, startAt time.Time) PaymentDues {
+	if amount <= 0 {
+		return PaymentDues{}
+	}
+	if schedule == nil || amount < schedule.MinAmount || schedule.DepositPercent >= 100 {
+		return PaymentDues{{DueAt: bookedAt, Amount: amount}}
+	}
+
+	sorted := make(PaymentInstalments, len(schedule.Instalments))
+	copy(sorted, schedule.Instalments)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].DaysBeforeStart > sorted[j].DaysBeforeStart
+	})
+
+	dues := PaymentDues{{DueAt: bookedAt, Amount: amount * int64(schedule.DepositPercent) / 100}}
+	for _, instalment := range sorted {
+		due := PaymentDue{
+			DueAt:  startAt.AddDate(0, 0, -instalment.DaysBeforeStart),
+			Amount: amount * int64(instalment.Percent) / 100,
+		}
+		if !due.DueAt.After(bookedAt) {
+			dues[0].Amount += due.Amount
+			continue
+		}
+		last := &dues[len(dues)-1]
+		if last.DueAt.Equal(due.DueAt) {
+			last.Amount += due.Amount
+			continue
+		}
+		dues = append(dues, due)
+	}
+
+	var total int64
+	for _, due := range dues {
+		total += due.Amount
+	}
+	dues[len(dues)-1].Amount += amount - total
+	return dues
+}
+
+// NextDueAt returns when the first due not covered by the paid amount is due,
+// or nil when the dues are paid.
+func (dues PaymentDues) NextDueAt(paidAmount int64) *time.Time {
+	var total int64
+	for _, due := range dues {
+		total += due.Amount
+		if total > paidAmount {
+			dueAt := due.DueAt
+			return &dueAt
+		}
+	}
+	return nil
+}
+
+// AmountDue returns the amount of the dues due by now that the paid amount
+// does not cover.
+func (dues PaymentDues) AmountDue(paidAmount int64, now time.Time) int64 {
+	var total int64
+	for _, due := range dues {
+		if due.DueAt.After(now) {
+			break
+		}
+		total += due.Amount
+	}
+	return max(total-paidAmount, 0)
+}
+
+// WithDeposit returns the dues with at least the deposit due first, brought
+// forward from the earliest of the following dues.
+func (dues PaymentDues) WithDeposit(deposit int64) PaymentDues {
+	if len(dues) == 0 || dues[0].Amount >= deposit {
+		return dues
+	}
+
+	raised := PaymentDues{dues[0]}
+	for _, due := range dues[1:] {
+		moved := min(due.Amount, deposit-raised[0].Amount)
+		raised[0].Amount += moved
+		due.Amount -= moved
+		if due.Amount > 0 {
+			raised = append(raised, due)
+		}
+	}
+	return raised
+}
+
+// Rescheduled returns the dues of a booking moved by shift whose amount
+// changed to amount, at now. The dues not due yet move with the booking but
+// not before now. A higher amount adds the difference as due now and a lower
+// one is taken off the latest dues first.
+func (dues PaymentDues) Rescheduled(shift time.Duration, amount int64, now time.Time) PaymentDues {
+	moved := PaymentDues{}
+	var total int64
+	for _, due := range dues {
+		if due.DueAt.After(now) {
+			due.DueAt = due.DueAt.Add(shift)
+			if !due.DueAt.After(now) {
+				due.DueAt = now
+			}
+		}
+		total += due.Amount
+		moved = moved.add(due)
+	}
+
+	if amount > total {
+		moved = moved.add(PaymentDue{DueAt: now, Amount: amount - total})
+		sort.SliceStable(moved, func(i, j int) bool {
+			return moved[i].DueAt.Before(moved[j].DueAt)
+		})
+	}
+	for excess := total - amount; excess > 0 && len(moved) > 0; {
+		last := &moved[len(moved)-1]
+		taken := min(excess, last.Amount)
+		last.Amount -= taken
+		excess -= taken
+		if last.Amount == 0 {
+			moved = moved[:len(moved)-1]
+		}
+	}
+	return moved
+}
+
+// add appends the due, or adds its amount to the due at the same time if
+// there is one.
+func (dues PaymentDues) add(due PaymentDue) PaymentDues {
+	for i := range dues {
+		if dues[i].DueAt.Equal(due.DueAt) {
+			dues[i].Amount += due.Amount
+			return dues
+		}
+	}
+	return append(dues, due)
+}
@@ -0,0 +1,137 @@
+package entity_test
+
+import (
+	"booking/internal/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaymentScheduleDues(t *testing.T) {
+	schedule := &entity.PaymentSchedule{
+		MinAmount:      100000,
+		DepositPercent: 30,
+		Instalments: entity.PaymentInstalments{
+			{DaysBeforeStart: 7, Percent: 40},
+			{DaysBeforeStart: 30, Percent: 30},
+		},
+	}
+	bookedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	startAt := time.Date(2024, 8, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("When the booking is far enough ahead, should split the amount into the deposit and the instalments", func(t *testing.T) {
+		dues := schedule.Dues(200000, bookedAt, startAt)
+
+		assert.Equal(t, entity.PaymentDues{
+			{DueAt: bookedAt, Amount: 60000},
+			{DueAt: startAt.AddDate(0, 0, -30), Amount: 60000},
+			{DueAt: startAt.AddDate(0, 0, -7), Amount: 80000},
+		}, dues)
+	})
+
+	t.Run("When an instalment would already be due, should add it to the deposit", func(t *testing.T) {
+		dues := schedule.Dues(200000, startAt.AddDate(0, 0, -10), startAt)
+
+		assert.Equal(t, entity.PaymentDues{
+			{DueAt: startAt.AddDate(0, 0, -10), Amount: 120000},
+			{DueAt: startAt.AddDate(0, 0, -7), Amount: 80000},
+		}, dues)
+	})
+
+	t.Run("When the amount is split into fractions of a satang, should add the rest to the last due", func(t *testing.T) {
+		dues := schedule.Dues(100001, bookedAt, startAt)
+
+		assert.Equal(t, int64(30000), dues[0].Amount)
+		assert.Equal(t, int64(40001), dues[2].Amount)
+	})
+
+	t.Run("When the amount is under the minimum amount, should be paid in full when booking", func(t *testing.T) {
+		assert.Equal(t, entity.PaymentDues{{DueAt: bookedAt, Amount: 50000}}, schedule.Dues(50000, bookedAt, startAt))
+	})
+
+	t.Run("Without a schedule, should be paid in full when booking", func(t *testing.T) {
+		var noSchedule *entity.PaymentSchedule
+		assert.Equal(t, entity.PaymentDues{{DueAt: bookedAt, Amount: 50000}}, noSchedule.Dues(50000, bookedAt, startAt))
+	})
+}
+
+func TestPaymentDues(t *testing.T) {
+	bookedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	balanceDueAt := time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC)
+	dues := entity.PaymentDues{{DueAt: bookedAt, Amount: 60000}, {DueAt: balanceDueAt, Amount: 140000}}
+
+	t.Run("When the deposit is paid, should be due next when the balance is due", func(t *testing.T) {
+		assert.Equal(t, &balanceDueAt, dues.NextDueAt(60000))
+		assert.Equal(t, int64(0), dues.AmountDue(60000, balanceDueAt.Add(-time.Hour)))
+		assert.Equal(t, int64(140000), dues.AmountDue(60000, balanceDueAt))
+	})
+
+	t.Run("When the balance is partly paid, should still be due for the rest of it", func(t *testing.T) {
+		assert.Equal(t, &balanceDueAt, dues.NextDueAt(150000))
+		assert.Equal(t, int64(50000), dues.AmountDue(150000, balanceDueAt))
+	})
+
+	t.Run("When everything is paid, should not be due any more", func(t *testing.T) {
+		assert.Nil(t, dues.NextDueAt(200000))
+		assert.Equal(t, int64(0), dues.AmountDue(200000, balanceDueAt))
+	})
+}
+
+func TestPaymentDuesWithDeposit(t *testing.T) {
+	bookedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	firstDueAt := time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC)
+	secondDueAt := time.Date(2024, 7, 25, 10, 0, 0, 0, time.UTC)
+	dues := entity.PaymentDues{{DueAt: bookedAt, Amount: 60000}, {DueAt: firstDueAt, Amount: 60000}, {DueAt: secondDueAt, Amount: 80000}}
+
+	t.Run("When the deposit is raised, should bring it forward from the earliest dues", func(t *testing.T) {
+		assert.Equal(t, entity.PaymentDues{
+			{DueAt: bookedAt, Amount: 150000},
+			{DueAt: secondDueAt, Amount: 50000},
+		}, dues.WithDeposit(150000))
+	})
+
+	t.Run("When the deposit already covers it, should keep the dues", func(t *testing.T) {
+		assert.Equal(t, dues, dues.WithDeposit(50000))
+	})
+}
+
+func TestPaymentDuesRescheduled(t *testing.T) {
+	bookedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	now := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	firstDueAt := time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC)
+	secondDueAt := time.Date(2024, 7, 25, 10, 0, 0, 0, time.UTC)
+	dues := entity.PaymentDues{{DueAt: bookedAt, Amount: 60000}, {DueAt: firstDueAt, Amount: 60000}, {DueAt: secondDueAt, Amount: 80000}}
+
+	t.Run("When the booking is moved later at the same price, should move the dues not due yet", func(t *testing.T) {
+		assert.Equal(t, entity.PaymentDues{
+			{DueAt: bookedAt, Amount: 60000},
+			{DueAt: firstDueAt.AddDate(0, 0, 7), Amount: 60000},
+			{DueAt: secondDueAt.AddDate(0, 0, 7), Amount: 80000},
+		}, dues.Rescheduled(7*24*time.Hour, 200000, now))
+	})
+
+	t.Run("When a due would move before now, should be due now", func(t *testing.T) {
+		assert.Equal(t, entity.PaymentDues{
+			{DueAt: bookedAt, Amount: 60000},
+			{DueAt: now, Amount: 60000},
+			{DueAt: secondDueAt.AddDate(0, 0, -40), Amount: 80000},
+		}, dues.Rescheduled(-40*24*time.Hour, 200000, now))
+	})
+
+	t.Run("When the price goes up, should add the difference as due now", func(t *testing.T) {
+		assert.Equal(t, entity.PaymentDues{
+			{DueAt: bookedAt, Amount: 60000},
+			{DueAt: now, Amount: 30000},
+			{DueAt: firstDueAt, Amount: 60000},
+			{DueAt: secondDueAt, Amount: 80000},
+		}, dues.Rescheduled(0, 230000, now))
+	})
+
+	t.Run("When the price goes down, should take the difference off the latest dues", func(t *testing.T) {
+		assert.Equal(t, entity.PaymentDues{
+			{DueAt: bookedAt, Amount: 60000},
+			{DueAt: firstDueAt, Amount: 40000},
+		}, dues.Rescheduled(0, 100000, now))
+	})
+}
@@ -0,0 +1,87 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// PRICING_RULE_PEAK_HOURS adjusts the minutes booked between StartTime and
+	// EndTime on the listed weekdays, or on every day when none are listed.
+	PRICING_RULE_PEAK_HOURS = "PEAK_HOURS"
+	// PRICING_RULE_WEEKEND adjusts the minutes booked on Saturdays and Sundays.
+	PRICING_RULE_WEEKEND = "WEEKEND"
+	// PRICING_RULE_SEASONAL adjusts the minutes booked from StartDate to
+	// EndDate inclusive.
+	PRICING_RULE_SEASONAL = "SEASONAL"
+	// PRICING_RULE_DURATION_TIER adjusts the subtotal of bookings lasting at
+	// least MinMinutes and, when set, less than MaxMinutes.
+	PRICING_RULE_DURATION_TIER = "DURATION_TIER"
+	// PRICING_RULE_MEMBER_DISCOUNT adjusts the subtotal of bookings made by
+	// members of the resource.
+	PRICING_RULE_MEMBER_DISCOUNT = "MEMBER_DISCOUNT"
+)
+
+// PricingPolicy prices bookings of a resource from its hourly rate with rules
+// applied in order.
+type PricingPolicy struct {
+	ID             uuid.UUID    `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	OrganizationID uuid.UUID    `gorm:"type:uuid;index"`
+	ResourceID     uuid.UUID    `gorm:"type:uuid;uniqueIndex"`
+	Rules          PricingRules `gorm:"type:jsonb"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DeletedAt      gorm.DeletedAt `gorm:"index"`
+}
+
+// PricingRule adds Percent of the base rate of the minutes it matches, or of
+// the subtotal so far for rules that match the whole booking. A negative
+// Percent is a discount. Times of day and dates are local to the resource.
+type PricingRule struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Percent    int    `json:"percent"`
+	Weekdays   []int  `json:"weekdays,omitempty"`
+	StartTime  string `json:"startTime,omitempty"`
+	EndTime    string `json:"endTime,omitempty"`
+	StartDate  string `json:"startDate,omitempty"`
+	EndDate    string `json:"endDate,omitempty"`
+	MinMinutes int    `json:"minMinutes,omitempty"`
+	MaxMinutes int    `json:"maxMinutes,omitempty"`
+}
+
+type PricingRules []PricingRule
+
+func (rules PricingRules) Value() (driver.Value, error) {
+	if rules == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(rules)
+	return string(b), err
+}
+
+func (rules *PricingRules) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*rules = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, rules)
+	case string:
+		return json.Unmarshal([]byte(v), rules)
+	}
+	return fmt.Errorf("cannot scan %T into PricingRules", value)
+}
+
+// ResourceMember is a user entitled to the member discounts of a resource.
+type ResourceMember struct {
+	ID         uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	ResourceID uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_resource_member"`
+	UserID     uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_resource_member"`
+	CreatedAt  time.Time
+}
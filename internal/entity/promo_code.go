@@ -0,0 +1,156 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	PROMO_DISCOUNT_PERCENT = "PERCENT"
+	PROMO_DISCOUNT_FIXED   = "FIXED"
+)
+
+// PromoCode discounts bookings of the resources of its organization. Limits of 0 are
+// unlimited. RedemptionCount is only changed while the row is locked so the
+// global limit holds under concurrent bookings.
+type PromoCode struct {
+	ID             uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_promo_codes_organization_code"`
+	OwnerID        uuid.UUID `gorm:"type:uuid;index"`
+	// Code is stored in upper case and matched case-insensitively, unique
+	// within the organization.
+	Code         string `gorm:"uniqueIndex:idx_promo_codes_organization_code"`
+	DiscountType string
+	// DiscountValue is a percent for PERCENT codes, satang for FIXED codes.
+	DiscountValue int64
+	ValidFrom     *time.Time
+	ValidUntil    *time.Time
+	// MinSpend is the least a booking must cost before the discount, in
+	// satang.
+	MinSpend              int64
+	MaxRedemptions        int
+	MaxRedemptionsPerUser int
+	RedemptionCount       int
+	// ResourceIDs and Categories restrict the resources the code applies to
+	// when not empty.
+	ResourceIDs UUIDList   `gorm:"type:jsonb"`
+	Categories  StringList `gorm:"type:jsonb"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+}
+
+// PromoRedemption records a use of a promo code by a booking.
+type PromoRedemption struct {
+	ID          uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	PromoCodeID uuid.UUID `gorm:"type:uuid;index:idx_promo_redemption_user"`
+	UserID      uuid.UUID `gorm:"type:uuid;index:idx_promo_redemption_user"`
+	BookingID   uuid.UUID `gorm:"type:uuid;uniqueIndex"`
+	// Discount is in satang.
+	Discount  int64
+	CreatedAt time.Time
+}
+
+// IsValidAt reports whether the code can be used at the given time.
+func (promoCode *PromoCode) IsValidAt(now time.Time) bool {
+	if promoCode.ValidFrom != nil && now.Before(*promoCode.ValidFrom) {
+		return false
+	}
+	return promoCode.ValidUntil == nil || now.Before(*promoCode.ValidUntil)
+}
+
+// AppliesTo reports whether the code discounts bookings of the resource.
+func (promoCode *PromoCode) AppliesTo(resource *Resource) bool {
+	if resource.OrganizationID != promoCode.OrganizationID {
+		return false
+	}
+	if len(promoCode.ResourceIDs) > 0 && !promoCode.ResourceIDs.Contains(resource.ID) {
+		return false
+	}
+	return len(promoCode.Categories) == 0 || promoCode.Categories.Contains(resource.Category)
+}
+
+// IsExhausted reports whether the code has been redeemed as many times as it
+// can be.
+func (promoCode *PromoCode) IsExhausted() bool {
+	return promoCode.MaxRedemptions > 0 && promoCode.RedemptionCount >= promoCode.MaxRedemptions
+}
+
+// Discount returns the discount in satang on a price, never more than the
+// price. Fractions of a satang are rounded down.
+func (promoCode *PromoCode) Discount(price int64) int64 {
+	discount := promoCode.DiscountValue
+	if promoCode.DiscountType == PROMO_DISCOUNT_PERCENT {
+		discount = price * promoCode.DiscountValue / 100
+	}
+	return min(discount, price)
+}
+
+type UUIDList []uuid.UUID
+
+func (list UUIDList) Contains(id uuid.UUID) bool {
+	for _, item := range list {
+		if item == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (list UUIDList) Value() (driver.Value, error) {
+	if list == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(list)
+	return string(b), err
+}
+
+func (list *UUIDList) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*list = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, list)
+	case string:
+		return json.Unmarshal([]byte(v), list)
+	}
+	return fmt.Errorf("cannot scan %T into UUIDList", value)
+}
+
+type StringList []string
+
+func (list StringList) Contains(s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (list StringList) Value() (driver.Value, error) {
+	if list == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(list)
+	return string(b), err
+}
+
+func (list *StringList) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*list = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, list)
+	case string:
+		return json.Unmarshal([]byte(v), list)
+	}
+	return fmt.Errorf("cannot scan %T into StringList", value)
+}
@@ -0,0 +1,87 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Provider is a staff member customers book for a service, such as a dentist
+// or a stylist, the service being performed in a resource at the same time.
+type Provider struct {
+	ID             uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;index"`
+	// UserID is the account of the provider, if they have one.
+	UserID *uuid.UUID `gorm:"type:uuid;index"`
+	Name   string
+	// TimeZone is the IANA time zone the working hours are read in.
+	TimeZone  string `gorm:"default:Asia/Bangkok"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func (provider *Provider) Location() *time.Location {
+	return loadLocation(provider.TimeZone)
+}
+
+// ProviderHours are the weekly working periods of a provider on a weekday,
+// like the opening hours of a resource. A provider without hours works
+// whenever the resources are open.
+type ProviderHours struct {
+	ID         uuid.UUID      `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	ProviderID uuid.UUID      `gorm:"type:uuid;uniqueIndex:idx_provider_hours_weekday"`
+	Weekday    int            `gorm:"uniqueIndex:idx_provider_hours_weekday"`
+	Periods    OpeningPeriods `gorm:"type:jsonb"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// ProviderTimeOff is a period a provider doesn't work, such as a leave,
+// regardless of their working hours.
+type ProviderTimeOff struct {
+	ID         uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	ProviderID uuid.UUID `gorm:"type:uuid;index"`
+	StartAt    time.Time `gorm:"index"`
+	EndAt      time.Time `gorm:"index"`
+	Reason     string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	DeletedAt  gorm.DeletedAt `gorm:"index"`
+}
+
+// Service is an appointment customers book with a provider, performed in a
+// resource for one of its seats.
+type Service struct {
+	ID             uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;index"`
+	ResourceID     uuid.UUID `gorm:"type:uuid;index"`
+	Name           string
+	// DurationMinutes is how long the service takes unless the provider
+	// takes another time.
+	DurationMinutes int
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	DeletedAt       gorm.DeletedAt `gorm:"index"`
+}
+
+// ProviderService is a service a provider performs.
+type ProviderService struct {
+	ID         uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	ProviderID uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_provider_service"`
+	ServiceID  uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_provider_service"`
+	// DurationMinutes is how long the provider takes for the service, the
+	// duration of the service if zero.
+	DurationMinutes int
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// Duration returns how long the provider takes for the service.
+func (providerService *ProviderService) Duration(service *Service) time.Duration {
+	if providerService.DurationMinutes > 0 {
+		return time.Duration(providerService.DurationMinutes) * time.Minute
+	}
+	return time.Duration(service.DurationMinutes) * time.Minute
+}
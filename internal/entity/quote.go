@@ -0,0 +1,113 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// QUOTE_LINE_BASE_RATE is the type of the first line of a quote, the
+	// hourly rate of the resource for the booked minutes and seats.
+	QUOTE_LINE_BASE_RATE = "BASE_RATE"
+	// QUOTE_LINE_PROMO_CODE is the type of the last line of a quote a promo
+	// code was applied to.
+	QUOTE_LINE_PROMO_CODE = "PROMO_CODE"
+	// QUOTE_LINE_NO_SHOW_FEE is the fee charged on bookings of users with
+	// recent no-shows, after any promo code.
+	QUOTE_LINE_NO_SHOW_FEE = "NO_SHOW_FEE"
+)
+
+// QuoteLine is an item of a price breakdown. Amount is in satang.
+type QuoteLine struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Percent int    `json:"percent,omitempty"`
+	// Minutes is how many of the booked minutes the line applies to, for
+	// lines priced by time.
+	Minutes int   `json:"minutes,omitempty"`
+	Amount  int64 `json:"amount"`
+}
+
+// Quote is an itemized price. It is stored on bookings with the rules it was
+// priced with, so the price can be reproduced after the rules change.
+type Quote struct {
+	HourlyRate int64         `json:"hourlyRate"`
+	Minutes    int           `json:"minutes"`
+	Seats      int           `json:"seats"`
+	Member     bool          `json:"member"`
+	Rules      []PricingRule `json:"rules"`
+	Lines      []QuoteLine   `json:"lines"`
+	PromoCode  string        `json:"promoCode,omitempty"`
+	Total      int64         `json:"total"`
+}
+
+// ApplyPromoCode discounts the total of the quote with the promo code.
+func (quote *Quote) ApplyPromoCode(promoCode *PromoCode) {
+	discount := promoCode.Discount(quote.Total)
+	line := QuoteLine{Name: promoCode.Code, Type: QUOTE_LINE_PROMO_CODE, Amount: -discount}
+	if promoCode.DiscountType == PROMO_DISCOUNT_PERCENT {
+		line.Percent = -int(promoCode.DiscountValue)
+	}
+	quote.Lines = append(quote.Lines, line)
+	quote.PromoCode = promoCode.Code
+	quote.Total -= discount
+}
+
+// AddNoShowFee adds the fee in satang to the total of the quote.
+func (quote *Quote) AddNoShowFee(fee int64) {
+	quote.Lines = append(quote.Lines, QuoteLine{Name: "No-show fee", Type: QUOTE_LINE_NO_SHOW_FEE, Amount: fee})
+	quote.Total += fee
+}
+
+// ApplyAdjustments applies the promo code and no-show fee of the previous
+// quote of the booking to the quote it is priced at after being moved. A
+// percent discount takes the same percent off the new total and a fixed one
+// the same amount, at most the new total.
+func (quote *Quote) ApplyAdjustments(previous *Quote) {
+	for _, line := range previous.Lines {
+		switch line.Type {
+		case QUOTE_LINE_PROMO_CODE:
+			discount := -line.Amount
+			if line.Percent != 0 {
+				discount = quote.Total * int64(-line.Percent) / 100
+			}
+			discount = min(discount, quote.Total)
+			line.Amount = -discount
+			quote.Lines = append(quote.Lines, line)
+			quote.PromoCode = previous.PromoCode
+			quote.Total -= discount
+		case QUOTE_LINE_NO_SHOW_FEE:
+			quote.AddNoShowFee(line.Amount)
+		}
+	}
+}
+
+// Discount returns the amount taken off the quote by its promo code.
+func (quote *Quote) Discount() int64 {
+	var discount int64
+	for _, line := range quote.Lines {
+		if line.Type == QUOTE_LINE_PROMO_CODE {
+			discount -= line.Amount
+		}
+	}
+	return discount
+}
+
+func (quote Quote) Value() (driver.Value, error) {
+	b, err := json.Marshal(quote)
+	return string(b), err
+}
+
+func (quote *Quote) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*quote = Quote{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, quote)
+	case string:
+		return json.Unmarshal([]byte(v), quote)
+	}
+	return fmt.Errorf("cannot scan %T into Quote", value)
+}
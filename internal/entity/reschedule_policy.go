@@ -0,0 +1,62 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ReschedulePolicy struct {
+	ID             uuid.UUID       `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	OrganizationID uuid.UUID       `gorm:"type:uuid;index"`
+	ResourceID     uuid.UUID       `gorm:"type:uuid;uniqueIndex"`
+	Rules          RescheduleRules `gorm:"type:jsonb"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DeletedAt      gorm.DeletedAt `gorm:"index"`
+}
+
+// RescheduleRules limit how a booking may be moved to another slot. The zero
+// value lets bookings be moved any number of times until they start.
+type RescheduleRules struct {
+	Disabled bool `json:"disabled"`
+	// CutoffHours is how many hours before it starts a booking can last be
+	// moved.
+	CutoffHours int `json:"cutoffHours"`
+	// MaxReschedules is how many times a booking can be moved, 0 for no
+	// limit.
+	MaxReschedules int `json:"maxReschedules"`
+}
+
+func (rules RescheduleRules) Value() (driver.Value, error) {
+	b, err := json.Marshal(rules)
+	return string(b), err
+}
+
+func (rules *RescheduleRules) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*rules = RescheduleRules{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, rules)
+	case string:
+		return json.Unmarshal([]byte(v), rules)
+	}
+	return fmt.Errorf("cannot scan %T into RescheduleRules", value)
+}
+
+// CutoffAt returns the last time a booking starting at startAt can be moved.
+func (rules RescheduleRules) CutoffAt(startAt time.Time) time.Time {
+	return startAt.Add(-time.Duration(rules.CutoffHours) * time.Hour)
+}
+
+// LimitReached reports whether a booking moved the given number of times
+// can't be moved again.
+func (rules RescheduleRules) LimitReached(rescheduleCount int) bool {
+	return rules.MaxReschedules > 0 && rescheduleCount >= rules.MaxReschedules
+}
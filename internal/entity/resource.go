@@ -0,0 +1,66 @@
+package entity
+
+import (
+	"booking/internal/constant"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Resource struct {
+	ID uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	// OrganizationID is the organization the resource belongs to, with its
+	// bookings and settings.
+	OrganizationID uuid.UUID `gorm:"type:uuid;index"`
+	OwnerID        uuid.UUID `gorm:"type:uuid;index"`
+	Name           string
+	Description    string
+	// Category groups resources for promo codes, e.g. "meeting-room".
+	Category string `gorm:"index"`
+	// Amenities are what the resource offers, e.g. "projector", in lower
+	// case for search.
+	Amenities StringList `gorm:"type:jsonb"`
+	// HourlyRate is the price of one hour in satang.
+	HourlyRate int64
+	// Address is where the resource is, Latitude and Longitude its position
+	// when known for searching nearby.
+	Address   string
+	Latitude  *float64 `gorm:"index:idx_resources_position"`
+	Longitude *float64 `gorm:"index:idx_resources_position"`
+	// Capacity is how many seats can be booked at the same time.
+	Capacity int `gorm:"default:1"`
+	// TimeZone is the IANA time zone opening hours and holidays are read in.
+	TimeZone string `gorm:"default:Asia/Bangkok"`
+	// HolidayCalendarCode is the public holiday calendar the resource closes
+	// on, if any.
+	HolidayCalendarCode string
+	// RatingAverage and RatingCount aggregate the published reviews of the
+	// resource, kept up to date as they change so listings can sort by them.
+	RatingAverage float64 `gorm:"index"`
+	RatingCount   int     `gorm:"index"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	DeletedAt     gorm.DeletedAt `gorm:"index"`
+}
+
+func (resource *Resource) Location() *time.Location {
+	return loadLocation(resource.TimeZone)
+}
+
+// GeoPoint returns the position of the resource, false if it has none.
+func (resource *Resource) GeoPoint() (point GeoPoint, ok bool) {
+	if resource.Latitude == nil || resource.Longitude == nil {
+		return GeoPoint{}, false
+	}
+	return GeoPoint{Latitude: *resource.Latitude, Longitude: *resource.Longitude}, true
+}
+
+// loadLocation loads the IANA time zone, the default one if it is unknown.
+func loadLocation(timeZone string) *time.Location {
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		location, _ = time.LoadLocation(constant.DEFAULT_TIME_ZONE)
+	}
+	return location
+}
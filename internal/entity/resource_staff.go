@@ -0,0 +1,16 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ResourceStaff is a user working the front desk of a resource for its
+// organization, allowed to check customers in.
+type ResourceStaff struct {
+	ID         uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	ResourceID uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_resource_staff"`
+	UserID     uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_resource_staff"`
+	CreatedAt  time.Time
+}
@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// REVIEW_STATUS_PUBLISHED reviews are listed and counted in the rating of
+	// their resource, as reviews are once written.
+	REVIEW_STATUS_PUBLISHED = "PUBLISHED"
+	// REVIEW_STATUS_HIDDEN reviews were taken down by a moderator.
+	REVIEW_STATUS_HIDDEN = "HIDDEN"
+)
+
+// Review is the rating of a resource by the customer of a completed booking,
+// one per booking.
+type Review struct {
+	ID             uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;index"`
+	ResourceID     uuid.UUID `gorm:"type:uuid;index:idx_reviews_resource_status"`
+	BookingID      uuid.UUID `gorm:"type:uuid;uniqueIndex"`
+	UserID         uuid.UUID `gorm:"type:uuid;index"`
+	// Rating is from 1 to 5 stars.
+	Rating int
+	Text   string
+	Status string `gorm:"index:idx_reviews_resource_status"`
+	// Reply is the answer of an admin of the organization of the resource,
+	// RepliedAt when it was last written.
+	Reply     string
+	RepliedAt *time.Time
+	// ModeratedBy is the organization admin who last changed the status.
+	ModeratedBy *uuid.UUID `gorm:"type:uuid"`
+	ModeratedAt *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"sort"
+	"time"
+)
+
+// SeatPeriod is a number of seats of a resource taken for a period.
+type SeatPeriod struct {
+	StartAt time.Time
+	EndAt   time.Time
+	Seats   int
+}
+
+// PeakSeats returns the most seats taken at the same time between startAt and
+// endAt. Periods that only touch the range at its ends don't count.
+func PeakSeats(periods []SeatPeriod, startAt time.Time, endAt time.Time) int {
+	type change struct {
+		at    time.Time
+		seats int
+	}
+	changes := []change{}
+	for _, period := range periods {
+		if !period.StartAt.Before(endAt) || !period.EndAt.After(startAt) {
+			continue
+		}
+		changes = append(changes, change{at: period.StartAt, seats: period.Seats}, change{at: period.EndAt, seats: -period.Seats})
+	}
+	// a period ending when another starts frees its seats first
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].at.Equal(changes[j].at) {
+			return changes[i].seats < changes[j].seats
+		}
+		return changes[i].at.Before(changes[j].at)
+	})
+
+	seats, peak := 0, 0
+	for _, change := range changes {
+		seats += change.seats
+		if seats > peak {
+			peak = seats
+		}
+	}
+	return peak
+}
@@ -0,0 +1,38 @@
+package entity_test
+
+import (
+	"booking/internal/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeakSeats(t *testing.T) {
+	startAt := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	period := func(startHour int, endHour int, seats int) entity.SeatPeriod {
+		return entity.SeatPeriod{
+			StartAt: startAt.Add(time.Duration(startHour) * time.Hour),
+			EndAt:   startAt.Add(time.Duration(endHour) * time.Hour),
+			Seats:   seats,
+		}
+	}
+
+	t.Run("When periods overlap each other, should sum their seats", func(t *testing.T) {
+		periods := []entity.SeatPeriod{period(0, 2, 3), period(1, 3, 4)}
+
+		assert.Equal(t, 7, entity.PeakSeats(periods, startAt, startAt.Add(3*time.Hour)))
+	})
+
+	t.Run("When periods overlap the range but not each other, should response the largest", func(t *testing.T) {
+		periods := []entity.SeatPeriod{period(0, 1, 3), period(1, 2, 4), period(2, 3, 2)}
+
+		assert.Equal(t, 4, entity.PeakSeats(periods, startAt, startAt.Add(3*time.Hour)))
+	})
+
+	t.Run("When periods only touch the range, should not count them", func(t *testing.T) {
+		periods := []entity.SeatPeriod{period(0, 1, 3), period(2, 3, 4)}
+
+		assert.Equal(t, 0, entity.PeakSeats(periods, startAt.Add(time.Hour), startAt.Add(2*time.Hour)))
+	})
+}
@@ -0,0 +1,131 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// TAX_DOCUMENT_INVOICE is a tax invoice of the amount of a booking.
+	TAX_DOCUMENT_INVOICE = "INVOICE"
+	// TAX_DOCUMENT_RECEIPT is a receipt of a booking paid in full.
+	TAX_DOCUMENT_RECEIPT = "RECEIPT"
+)
+
+// VAT_RATE_PERCENT is the Thai VAT rate prices include.
+const VAT_RATE_PERCENT = 7
+
+// BillingProfile is how a user is named on the tax documents issued to them
+// as a customer.
+type BillingProfile struct {
+	ID     uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	UserID uuid.UUID `gorm:"type:uuid;uniqueIndex"`
+	Name   string
+	// TaxID is the 13 digit tax identification number, BranchCode is
+	// "00000" for the head office.
+	TaxID      string
+	BranchCode string
+	Address    string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// DocumentParty is a copy of a billing profile on a tax document.
+type DocumentParty struct {
+	Name       string
+	TaxID      string
+	BranchCode string
+	Address    string
+}
+
+func NewDocumentParty(profile *BillingProfile) DocumentParty {
+	return DocumentParty{Name: profile.Name, TaxID: profile.TaxID, BranchCode: profile.BranchCode, Address: profile.Address}
+}
+
+// OrganizationBillingProfile is how an organization is named on the tax
+// documents it issues as the business.
+type OrganizationBillingProfile struct {
+	OrganizationID uuid.UUID `gorm:"primarykey;type:uuid"`
+	DocumentParty  `gorm:"embedded"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// DocumentSequence is the last number given to the documents of a type an
+// organization issued in a year. Numbers are taken inside the transaction issuing
+// the document so they are gap-free.
+type DocumentSequence struct {
+	OrganizationID uuid.UUID `gorm:"primarykey;type:uuid"`
+	Type           string    `gorm:"primarykey"`
+	Year           int       `gorm:"primarykey"`
+	LastNumber     int64
+}
+
+// TaxDocument is an invoice or receipt of a booking. The parties and the
+// lines are copied when it is issued so it reads the same afterwards. Amounts
+// are in satang, VAT included in the total.
+type TaxDocument struct {
+	ID             uuid.UUID     `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	OrganizationID uuid.UUID     `gorm:"type:uuid;uniqueIndex:idx_tax_documents_organization_number"`
+	BookingID      uuid.UUID     `gorm:"type:uuid;uniqueIndex:idx_tax_documents_booking_type"`
+	Type           string        `gorm:"uniqueIndex:idx_tax_documents_booking_type;uniqueIndex:idx_tax_documents_organization_number"`
+	Number         string        `gorm:"uniqueIndex:idx_tax_documents_organization_number"`
+	Seller         DocumentParty `gorm:"embedded;embeddedPrefix:seller_"`
+	Buyer          DocumentParty `gorm:"embedded;embeddedPrefix:buyer_"`
+	// Description names the resource and the period booked.
+	Description string
+	Lines       DocumentLines `gorm:"type:jsonb"`
+	VATRate     int
+	Subtotal    int64
+	VATAmount   int64
+	Total       int64
+	IssuedAt    time.Time
+}
+
+// DocumentLines are the quote lines of the booking a document is issued for.
+type DocumentLines []QuoteLine
+
+func (lines DocumentLines) Value() (driver.Value, error) {
+	b, err := json.Marshal(lines)
+	return string(b), err
+}
+
+func (lines *DocumentLines) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*lines = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, lines)
+	case string:
+		return json.Unmarshal([]byte(v), lines)
+	}
+	return fmt.Errorf("cannot scan %T into DocumentLines", value)
+}
+
+// DocumentNumber formats the number of a document, e.g. INV-2024-000042.
+func DocumentNumber(documentType string, year int, sequence int64) string {
+	prefix := "INV"
+	if documentType == TAX_DOCUMENT_RECEIPT {
+		prefix = "RCT"
+	}
+	return fmt.Sprintf("%s-%d-%06d", prefix, year, sequence)
+}
+
+// IncludedVAT returns the VAT included in the total, rounded half up to the
+// satang.
+func IncludedVAT(total int64) int64 {
+	return (total*VAT_RATE_PERCENT*2 + 100 + VAT_RATE_PERCENT) / ((100 + VAT_RATE_PERCENT) * 2)
+}
+
+// SetTotal sets the total of the document and splits out the VAT it includes.
+func (document *TaxDocument) SetTotal(total int64) {
+	document.VATRate = VAT_RATE_PERCENT
+	document.Total = total
+	document.VATAmount = IncludedVAT(total)
+	document.Subtotal = total - document.VATAmount
+}
@@ -0,0 +1,32 @@
+package entity_test
+
+import (
+	"booking/internal/entity"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaxDocumentSetTotal(t *testing.T) {
+	t.Run("When the total includes VAT, should split out 7 percent of the value before VAT", func(t *testing.T) {
+		document := &entity.TaxDocument{}
+
+		document.SetTotal(107000)
+
+		assert.Equal(t, int64(100000), document.Subtotal)
+		assert.Equal(t, int64(7000), document.VATAmount)
+		assert.Equal(t, entity.VAT_RATE_PERCENT, document.VATRate)
+	})
+
+	t.Run("When the VAT is a fraction of a satang, should round it half up", func(t *testing.T) {
+		assert.Equal(t, int64(65), entity.IncludedVAT(1000))
+		assert.Equal(t, int64(7), entity.IncludedVAT(107))
+		assert.Equal(t, int64(0), entity.IncludedVAT(7))
+		assert.Equal(t, int64(1), entity.IncludedVAT(8))
+	})
+}
+
+func TestDocumentNumber(t *testing.T) {
+	assert.Equal(t, "INV-2024-000042", entity.DocumentNumber(entity.TAX_DOCUMENT_INVOICE, 2024, 42))
+	assert.Equal(t, "RCT-2025-000001", entity.DocumentNumber(entity.TAX_DOCUMENT_RECEIPT, 2025, 1))
+}
@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	WAITLIST_STATUS_WAITING   = "WAITING"
+	WAITLIST_STATUS_OFFERED   = "OFFERED"
+	WAITLIST_STATUS_CLAIMED   = "CLAIMED"
+	WAITLIST_STATUS_EXPIRED   = "EXPIRED"
+	WAITLIST_STATUS_CANCELLED = "CANCELLED"
+)
+
+// WaitlistEntry queues a user for a time range of a resource. When the range
+// is free again, the earliest waiting entry is offered the slot until
+// OfferExpiresAt. Expiry is stored rather than timed in-process so any worker
+// or instance can move the offer on to the next entry.
+type WaitlistEntry struct {
+	ID             uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;index"`
+	UserID         uuid.UUID `gorm:"type:uuid;index"`
+	ResourceID     uuid.UUID `gorm:"type:uuid;index"`
+	StartAt        time.Time
+	EndAt          time.Time
+	Seats          int    `gorm:"default:1"`
+	Status         string `gorm:"index"`
+	OfferedAt      *time.Time
+	OfferExpiresAt *time.Time `gorm:"index"`
+	BookingID      *uuid.UUID `gorm:"type:uuid"`
+	CreatedAt      time.Time  `gorm:"index"`
+	UpdatedAt      time.Time
+	DeletedAt      gorm.DeletedAt `gorm:"index"`
+}
+
+func (entry *WaitlistEntry) SeatPeriod() SeatPeriod {
+	return SeatPeriod{StartAt: entry.StartAt, EndAt: entry.EndAt, Seats: entry.Seats}
+}
@@ -0,0 +1,139 @@
+package ical
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// MAX_CALENDAR_SIZE is the size in bytes a calendar may have to be read.
+const MAX_CALENDAR_SIZE = 5 << 20
+
+// MAX_REDIRECTS is the number of redirects followed fetching a calendar.
+const MAX_REDIRECTS = 5
+
+var (
+	ErrUnsupportedURL   = errors.New("the calendar URL must be http, https or webcal")
+	ErrCalendarTooLarge = errors.New("the calendar is too large")
+	ErrPrivateAddress   = errors.New("the calendar URL must not resolve to a private address")
+	ErrTooManyRedirects = errors.New("fetching the calendar redirected too many times")
+)
+
+// nonPublicPrefixes are the ranges not reachable on the internet that the
+// methods of netip.Addr don't cover.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// NewClient returns the client to fetch the calendars of owner-supplied URLs
+// with, which only connects to public addresses. The address is checked as
+// it is dialled, after the host name was resolved, so a name resolving to
+// the host, its network or its cloud metadata service (169.254.169.254) is
+// refused however it is reached, redirects included. Proxies from the
+// environment are not used since the proxy would be dialled instead.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: dialPublicAddress}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: checkRedirect,
+	}
+}
+
+// dialPublicAddress refuses to connect to an address that isn't public.
+func dialPublicAddress(network string, address string, conn syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !IsPublicAddress(addrPort.Addr()) {
+		return ErrPrivateAddress
+	}
+	return nil
+}
+
+// checkRedirect follows redirects to http and https URLs only, their
+// addresses being checked as they are dialled like the first one.
+func checkRedirect(request *http.Request, via []*http.Request) error {
+	if len(via) >= MAX_REDIRECTS {
+		return ErrTooManyRedirects
+	}
+	switch request.URL.Scheme {
+	case "http", "https":
+		return nil
+	default:
+		return ErrUnsupportedURL
+	}
+}
+
+// IsPublicAddress reports whether the address is reachable on the internet,
+// unlike loopback, private, link-local, multicast and reserved addresses.
+func IsPublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// Fetch downloads the calendar at the URL with the client, which should be
+// one of NewClient for URLs given by users. webcal URLs, as handed out by
+// calendar applications for subscriptions, are fetched over https.
+func Fetch(client *http.Client, rawURL string) ([]byte, error) {
+	address, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, ErrUnsupportedURL
+	}
+	switch strings.ToLower(address.Scheme) {
+	case "webcal":
+		address.Scheme = "https"
+	case "http", "https":
+	default:
+		return nil, ErrUnsupportedURL
+	}
+
+	request, err := http.NewRequest(http.MethodGet, address.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "text/calendar")
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching the calendar responded %s", response.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(response.Body, MAX_CALENDAR_SIZE+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MAX_CALENDAR_SIZE {
+		return nil, ErrCalendarTooLarge
+	}
+	return data, nil
+}
@@ -0,0 +1,265 @@
+package ical
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// PRODUCT_ID identifies the calendars written, as PRODID.
+const PRODUCT_ID = "-//booking//bookings//EN"
+
+const (
+	STATUS_TENTATIVE = "TENTATIVE"
+	STATUS_CONFIRMED = "CONFIRMED"
+	STATUS_CANCELLED = "CANCELLED"
+)
+
+const (
+	// METHOD_PUBLISH is the method of a single event to import, subscribed
+	// feeds have none.
+	METHOD_PUBLISH = "PUBLISH"
+)
+
+// lineLimit is how many octets a content line may have before it is folded.
+const lineLimit = 75
+
+// Event is a VEVENT. Start and End are written in the time zone of TimeZone,
+// which must not be nil.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Status      string
+	Sequence    int
+	Start       time.Time
+	End         time.Time
+	TimeZone    *time.Location
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Calendar is a VCALENDAR. RefreshInterval tells subscribers how often to
+// fetch it again, if it is a feed.
+type Calendar struct {
+	Name            string
+	Method          string
+	RefreshInterval time.Duration
+	Events          []Event
+}
+
+// Marshal writes the calendar as RFC 5545 text with a VTIMEZONE for each time
+// zone its events are in.
+func Marshal(calendar *Calendar) []byte {
+	w := &writer{}
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.line("PRODID:" + PRODUCT_ID)
+	w.line("CALSCALE:GREGORIAN")
+	if calendar.Method != "" {
+		w.line("METHOD:" + calendar.Method)
+	}
+	if calendar.Name != "" {
+		w.line("X-WR-CALNAME:" + escape(calendar.Name))
+	}
+	if calendar.RefreshInterval > 0 {
+		w.line("REFRESH-INTERVAL;VALUE=DURATION:" + duration(calendar.RefreshInterval))
+		w.line("X-PUBLISHED-TTL:" + duration(calendar.RefreshInterval))
+	}
+
+	for _, zone := range zonesOf(calendar.Events) {
+		writeTimeZone(w, zone.location, zone.from, zone.to)
+	}
+	for _, event := range calendar.Events {
+		writeEvent(w, &event)
+	}
+
+	w.line("END:VCALENDAR")
+	return []byte(w.String())
+}
+
+func writeEvent(w *writer, event *Event) {
+	tzid := event.TimeZone.String()
+	w.line("BEGIN:VEVENT")
+	w.line("UID:" + escape(event.UID))
+	w.line("DTSTAMP:" + utc(event.UpdatedAt))
+	if !event.CreatedAt.IsZero() {
+		w.line("CREATED:" + utc(event.CreatedAt))
+	}
+	if !event.UpdatedAt.IsZero() {
+		w.line("LAST-MODIFIED:" + utc(event.UpdatedAt))
+	}
+	w.line(fmt.Sprintf("DTSTART;TZID=%s:%s", tzid, local(event.Start.In(event.TimeZone))))
+	w.line(fmt.Sprintf("DTEND;TZID=%s:%s", tzid, local(event.End.In(event.TimeZone))))
+	w.line(fmt.Sprintf("SEQUENCE:%d", event.Sequence))
+	if event.Status != "" {
+		w.line("STATUS:" + event.Status)
+	}
+	w.line("SUMMARY:" + escape(event.Summary))
+	if event.Description != "" {
+		w.line("DESCRIPTION:" + escape(event.Description))
+	}
+	if event.Location != "" {
+		w.line("LOCATION:" + escape(event.Location))
+	}
+	// cancelled events stop blocking time in the calendars of subscribers
+	if event.Status == STATUS_CANCELLED {
+		w.line("TRANSP:TRANSPARENT")
+	} else {
+		w.line("TRANSP:OPAQUE")
+	}
+	w.line("END:VEVENT")
+}
+
+type zoneRange struct {
+	location *time.Location
+	from     time.Time
+	to       time.Time
+}
+
+// zonesOf returns the time zones of the events, sorted by name, with the
+// period they are used over.
+func zonesOf(events []Event) []zoneRange {
+	ranges := map[string]*zoneRange{}
+	for _, event := range events {
+		name := event.TimeZone.String()
+		zone, ok := ranges[name]
+		if !ok {
+			ranges[name] = &zoneRange{location: event.TimeZone, from: event.Start, to: event.End}
+			continue
+		}
+		if event.Start.Before(zone.from) {
+			zone.from = event.Start
+		}
+		if event.End.After(zone.to) {
+			zone.to = event.End
+		}
+	}
+
+	zones := []zoneRange{}
+	for _, zone := range ranges {
+		zones = append(zones, *zone)
+	}
+	sort.Slice(zones, func(i, j int) bool {
+		return zones[i].location.String() < zones[j].location.String()
+	})
+	return zones
+}
+
+// writeTimeZone writes the VTIMEZONE of the location as the offset it has at
+// the start of the period and every transition up to its end.
+func writeTimeZone(w *writer, location *time.Location, from time.Time, to time.Time) {
+	w.line("BEGIN:VTIMEZONE")
+	w.line("TZID:" + location.String())
+
+	start := from.AddDate(-1, 0, 0).In(location)
+	_, offset := start.Zone()
+	writeObservance(w, start, offset)
+	for t := start; t.Before(to); {
+		next := nextTransition(t, to)
+		if next.IsZero() {
+			break
+		}
+		writeObservance(w, next, offset)
+		_, offset = next.Zone()
+		t = next
+	}
+
+	w.line("END:VTIMEZONE")
+}
+
+// writeObservance writes the offset taking effect at the time, changing from
+// the offset before it.
+func writeObservance(w *writer, t time.Time, offsetFrom int) {
+	name, offsetTo := t.Zone()
+	component := "STANDARD"
+	if t.IsDST() {
+		component = "DAYLIGHT"
+	}
+	w.line("BEGIN:" + component)
+	// the onset is the local time before the transition
+	w.line("DTSTART:" + local(t.UTC().Add(time.Duration(offsetFrom)*time.Second)))
+	w.line("TZOFFSETFROM:" + offsetString(offsetFrom))
+	w.line("TZOFFSETTO:" + offsetString(offsetTo))
+	w.line("TZNAME:" + escape(name))
+	w.line("END:" + component)
+}
+
+// nextTransition returns the first time after t, up to the limit, the offset
+// of its location changes, or the zero time if it doesn't.
+func nextTransition(t time.Time, limit time.Time) time.Time {
+	_, offset := t.Zone()
+	for day := t; day.Before(limit); {
+		next := day.Add(24 * time.Hour)
+		if _, nextOffset := next.Zone(); nextOffset != offset {
+			// the change is within the day, narrowed down to the second
+			low, high := day, next
+			for high.Sub(low) > time.Second {
+				mid := low.Add(high.Sub(low) / 2)
+				if _, midOffset := mid.Zone(); midOffset == offset {
+					low = mid
+				} else {
+					high = mid
+				}
+			}
+			return high.Truncate(time.Second)
+		}
+		day = next
+	}
+	return time.Time{}
+}
+
+func offsetString(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, offset/3600, offset%3600/60)
+}
+
+func utc(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+func local(t time.Time) string {
+	return t.Format("20060102T150405")
+}
+
+func duration(d time.Duration) string {
+	if d%time.Hour == 0 {
+		return fmt.Sprintf("PT%dH", d/time.Hour)
+	}
+	return fmt.Sprintf("PT%dM", d/time.Minute)
+}
+
+// escape escapes a TEXT value.
+func escape(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(text)
+}
+
+// writer writes content lines ended by CRLF and folded at 75 octets without
+// splitting UTF-8 characters.
+type writer struct {
+	strings.Builder
+}
+
+func (w *writer) line(content string) {
+	limit := lineLimit
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		w.WriteString(content[:cut])
+		w.WriteString("\r\n ")
+		content = content[cut:]
+		// the space starting a continuation line counts towards its octets
+		limit = lineLimit - 1
+	}
+	w.WriteString(content)
+	w.WriteString("\r\n")
+}
@@ -0,0 +1,78 @@
+package ical_test
+
+import (
+	"booking/internal/ical"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newEvent(t *testing.T, zone string, start time.Time) ical.Event {
+	location, err := time.LoadLocation(zone)
+	assert.NoError(t, err)
+	return ical.Event{
+		UID:       "42@booking",
+		Summary:   "Meeting Room",
+		Status:    ical.STATUS_CONFIRMED,
+		Start:     start,
+		End:       start.Add(time.Hour),
+		TimeZone:  location,
+		UpdatedAt: start.Add(-24 * time.Hour),
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	t.Run("When the event is in a zone without daylight saving, should write its start in local time with a single standard observance", func(t *testing.T) {
+		event := newEvent(t, "Asia/Bangkok", time.Date(2024, 5, 1, 3, 0, 0, 0, time.UTC))
+
+		content := string(ical.Marshal(&ical.Calendar{Events: []ical.Event{event}}))
+
+		assert.True(t, strings.HasPrefix(content, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+		assert.True(t, strings.HasSuffix(content, "END:VCALENDAR\r\n"))
+		assert.Contains(t, content, "DTSTART;TZID=Asia/Bangkok:20240501T100000\r\n")
+		assert.Contains(t, content, "TZOFFSETTO:+0700\r\n")
+		assert.Equal(t, 1, strings.Count(content, "BEGIN:STANDARD"))
+		assert.NotContains(t, content, "BEGIN:DAYLIGHT")
+	})
+
+	t.Run("When the event is in a zone with daylight saving, should write the transitions around it", func(t *testing.T) {
+		event := newEvent(t, "Europe/London", time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC))
+
+		content := string(ical.Marshal(&ical.Calendar{Events: []ical.Event{event}}))
+
+		assert.Contains(t, content, "DTSTART;TZID=Europe/London:20240701T100000\r\n")
+		assert.Contains(t, content, "BEGIN:DAYLIGHT\r\nDTSTART:20240331T010000\r\nTZOFFSETFROM:+0000\r\nTZOFFSETTO:+0100\r\nTZNAME:BST\r\n")
+		assert.Contains(t, content, "BEGIN:STANDARD\r\nDTSTART:20231029T020000\r\nTZOFFSETFROM:+0100\r\nTZOFFSETTO:+0000\r\nTZNAME:GMT\r\n")
+	})
+
+	t.Run("When the event is cancelled, should write its status and free its time", func(t *testing.T) {
+		event := newEvent(t, "Asia/Bangkok", time.Date(2024, 5, 1, 3, 0, 0, 0, time.UTC))
+		event.Status = ical.STATUS_CANCELLED
+		event.Sequence = 3
+
+		content := string(ical.Marshal(&ical.Calendar{Events: []ical.Event{event}}))
+
+		assert.Contains(t, content, "STATUS:CANCELLED\r\n")
+		assert.Contains(t, content, "SEQUENCE:3\r\n")
+		assert.Contains(t, content, "TRANSP:TRANSPARENT\r\n")
+	})
+
+	t.Run("When a text is long or has special characters, should escape it and fold its line at 75 octets", func(t *testing.T) {
+		event := newEvent(t, "Asia/Bangkok", time.Date(2024, 5, 1, 3, 0, 0, 0, time.UTC))
+		event.Summary = "ห้องประชุม; ชั้น 2, อาคาร A"
+		event.Description = strings.Repeat("ห้องประชุมขนาดใหญ่ ", 10)
+
+		content := string(ical.Marshal(&ical.Calendar{Events: []ical.Event{event}}))
+
+		assert.Contains(t, content, `SUMMARY:ห้องประชุม\; ชั้น 2\, อาคาร A`)
+		for _, line := range strings.Split(strings.TrimSuffix(content, "\r\n"), "\r\n") {
+			assert.LessOrEqual(t, len(line), 75)
+			assert.True(t, utf8.ValidString(line))
+		}
+		unfolded := strings.ReplaceAll(content, "\r\n ", "")
+		assert.Contains(t, unfolded, "DESCRIPTION:"+event.Description+"\r\n")
+	})
+}
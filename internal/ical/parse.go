@@ -0,0 +1,460 @@
+package ical
+
+import (
+	"booking/internal/recurrence"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MAX_OCCURRENCES is how many busy periods a calendar may have in the window
+// it is read over.
+const MAX_OCCURRENCES = 10000
+
+var (
+	ErrInvalidCalendar     = errors.New("the calendar is not valid iCalendar")
+	ErrTooManyOccurrences  = errors.New("the calendar has too many events")
+	durationPattern        = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+	errInvalidPropertyLine = fmt.Errorf("%w: malformed content line", ErrInvalidCalendar)
+)
+
+// Busy is a period an event of a calendar keeps busy.
+type Busy struct {
+	UID     string
+	Summary string
+	Start   time.Time
+	End     time.Time
+}
+
+// ParseBusy reads the calendar and returns the periods its events keep busy
+// between from and to, in order, with their recurrences expanded. Cancelled
+// and transparent events are free. Times without a time zone, and all-day
+// events, are read in the location.
+func ParseBusy(data []byte, location *time.Location, from time.Time, to time.Time) ([]Busy, error) {
+	root, err := parseComponents(string(data))
+	if err != nil {
+		return nil, err
+	}
+	calendar := root.child("VCALENDAR")
+	if calendar == nil {
+		return nil, ErrInvalidCalendar
+	}
+
+	zones := map[string]*time.Location{}
+	for _, timeZone := range calendar.children {
+		if timeZone.name == "VTIMEZONE" {
+			tzid := timeZone.value("TZID")
+			zones[tzid] = timeZoneLocation(timeZone, location)
+		}
+	}
+
+	// the events of a recurrence share their UID, the ones with a
+	// RECURRENCE-ID replacing an occurrence of the rule of the other
+	masters := []*event{}
+	overrides := map[string][]*event{}
+	for _, component := range calendar.children {
+		if component.name != "VEVENT" {
+			continue
+		}
+		parsed, err := parseEvent(component, zones, location)
+		if err != nil {
+			return nil, err
+		}
+		if parsed.recurrenceID != nil {
+			overrides[parsed.uid] = append(overrides[parsed.uid], parsed)
+		} else {
+			masters = append(masters, parsed)
+		}
+	}
+
+	busy := []Busy{}
+	add := func(e *event, start time.Time) error {
+		end := e.endOf(start)
+		if !e.busy || !end.After(start) || !start.Before(to) || !end.After(from) {
+			return nil
+		}
+		if len(busy) == MAX_OCCURRENCES {
+			return ErrTooManyOccurrences
+		}
+		busy = append(busy, Busy{UID: e.uid, Summary: e.summary, Start: start, End: end})
+		return nil
+	}
+	for _, master := range masters {
+		starts, err := master.occurrences(from, to)
+		if err != nil {
+			return nil, err
+		}
+		for _, start := range starts {
+			if master.isOverridden(start, overrides[master.uid]) {
+				continue
+			}
+			if err := add(master, start); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, uidOverrides := range overrides {
+		for _, override := range uidOverrides {
+			if err := add(override, override.start); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	sort.SliceStable(busy, func(i, j int) bool {
+		return busy[i].Start.Before(busy[j].Start)
+	})
+	return busy, nil
+}
+
+type event struct {
+	uid          string
+	summary      string
+	busy         bool
+	start        time.Time
+	end          time.Time
+	allDay       bool
+	rule         string
+	rdates       []time.Time
+	exdates      []time.Time
+	recurrenceID *time.Time
+}
+
+func parseEvent(component *component, zones map[string]*time.Location, location *time.Location) (*event, error) {
+	e := &event{
+		uid:     component.value("UID"),
+		summary: unescape(component.value("SUMMARY")),
+		busy:    component.value("STATUS") != STATUS_CANCELLED && component.value("TRANSP") != "TRANSPARENT",
+		rule:    component.value("RRULE"),
+	}
+
+	start := component.property("DTSTART")
+	if start == nil {
+		return nil, fmt.Errorf("%w: event %q has no DTSTART", ErrInvalidCalendar, e.uid)
+	}
+	var err error
+	e.start, e.allDay, err = parseTime(start, start.value, zones, location)
+	if err != nil {
+		return nil, err
+	}
+
+	end := component.property("DTEND")
+	switch {
+	case end != nil:
+		e.end, _, err = parseTime(end, end.value, zones, location)
+	case component.property("DURATION") != nil:
+		var d time.Duration
+		d, err = parseDuration(component.value("DURATION"))
+		e.end = e.start.Add(d)
+	case e.allDay:
+		// an all-day event without an end lasts the day
+		e.end = e.start.AddDate(0, 0, 1)
+	default:
+		e.end = e.start
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, property := range component.properties {
+		switch property.name {
+		case "RDATE", "EXDATE":
+			for _, value := range strings.Split(property.value, ",") {
+				// RDATE periods are taken by their start
+				value, _, _ = strings.Cut(value, "/")
+				t, _, err := parseTime(&property, value, zones, location)
+				if err != nil {
+					return nil, err
+				}
+				if property.name == "RDATE" {
+					e.rdates = append(e.rdates, t)
+				} else {
+					e.exdates = append(e.exdates, t)
+				}
+			}
+		case "RECURRENCE-ID":
+			t, _, err := parseTime(&property, property.value, zones, location)
+			if err != nil {
+				return nil, err
+			}
+			e.recurrenceID = &t
+		}
+	}
+	return e, nil
+}
+
+// occurrences returns the starts of the event whose occurrences may overlap
+// the period.
+func (e *event) occurrences(from time.Time, to time.Time) ([]time.Time, error) {
+	starts := []time.Time{e.start}
+	if e.rule != "" {
+		rule, err := recurrence.Parse(e.rule, e.start)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCalendar, err)
+		}
+		// occurrences starting up to the length of the event before the
+		// period still overlap it
+		starts = rule.Between(from.Add(-e.end.Sub(e.start)-24*time.Hour), to, true)
+		if len(starts) > MAX_OCCURRENCES {
+			return nil, ErrTooManyOccurrences
+		}
+	}
+	starts = append(starts, e.rdates...)
+
+	kept := starts[:0]
+	for _, start := range starts {
+		if !containsTime(e.exdates, start) {
+			kept = append(kept, start)
+		}
+	}
+	return kept, nil
+}
+
+// endOf returns the end of the occurrence starting at start. All-day events
+// last whole days whatever the daylight saving changes in between.
+func (e *event) endOf(start time.Time) time.Time {
+	if e.allDay {
+		days := int(e.end.Sub(e.start).Round(24*time.Hour) / (24 * time.Hour))
+		return start.AddDate(0, 0, days)
+	}
+	return start.Add(e.end.Sub(e.start))
+}
+
+func (e *event) isOverridden(start time.Time, overrides []*event) bool {
+	for _, override := range overrides {
+		if override.recurrenceID.Equal(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTime parses a DATE or DATE-TIME value of the property. Times are read
+// in the zone of their TZID, UTC when they end with Z, or else the location.
+func parseTime(property *property, value string, zones map[string]*time.Location, location *time.Location) (t time.Time, allDay bool, err error) {
+	if property.params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err = time.ParseInLocation("20060102", value, location)
+		if err != nil {
+			return t, false, fmt.Errorf("%w: %s", ErrInvalidCalendar, err)
+		}
+		return t, true, nil
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err = time.Parse("20060102T150405Z", value)
+	} else {
+		if tzid, ok := property.params["TZID"]; ok {
+			location = zoneLocation(tzid, zones, location)
+		}
+		t, err = time.ParseInLocation("20060102T150405", value, location)
+	}
+	if err != nil {
+		return t, false, fmt.Errorf("%w: %s", ErrInvalidCalendar, err)
+	}
+	return t, false, nil
+}
+
+// zoneLocation returns the location of the TZID, which is an IANA name in the
+// calendars of most applications.
+func zoneLocation(tzid string, zones map[string]*time.Location, location *time.Location) *time.Location {
+	if zone, ok := zones[tzid]; ok {
+		return zone
+	}
+	if zone, err := time.LoadLocation(strings.TrimPrefix(tzid, "/")); err == nil {
+		return zone
+	}
+	return location
+}
+
+// timeZoneLocation returns the location a VTIMEZONE stands for. Zones that
+// aren't named after an IANA zone, e.g. the Windows names of Outlook, are
+// taken as the fixed offset of their latest standard time.
+func timeZoneLocation(timeZone *component, location *time.Location) *time.Location {
+	for _, name := range []string{timeZone.value("TZID"), timeZone.value("X-LIC-LOCATION")} {
+		if name == "" {
+			continue
+		}
+		if zone, err := time.LoadLocation(strings.TrimPrefix(name, "/")); err == nil {
+			return zone
+		}
+	}
+
+	var latest *component
+	for _, observance := range timeZone.children {
+		if observance.name == "STANDARD" && (latest == nil || observance.value("DTSTART") > latest.value("DTSTART")) {
+			latest = observance
+		}
+	}
+	if latest == nil && len(timeZone.children) > 0 {
+		latest = timeZone.children[0]
+	}
+	if latest == nil {
+		return location
+	}
+	offset, err := parseOffset(latest.value("TZOFFSETTO"))
+	if err != nil {
+		return location
+	}
+	return time.FixedZone(timeZone.value("TZID"), offset)
+}
+
+func parseOffset(value string) (int, error) {
+	if len(value) != 5 && len(value) != 7 {
+		return 0, ErrInvalidCalendar
+	}
+	hours, err := strconv.Atoi(value[1:3])
+	if err != nil {
+		return 0, ErrInvalidCalendar
+	}
+	minutes, err := strconv.Atoi(value[3:5])
+	if err != nil {
+		return 0, ErrInvalidCalendar
+	}
+	offset := hours*3600 + minutes*60
+	if value[0] == '-' {
+		offset = -offset
+	}
+	return offset, nil
+}
+
+// parseDuration parses a DURATION value such as PT1H30M or P1D. Days are
+// taken as 24 hours.
+func parseDuration(value string) (time.Duration, error) {
+	match := durationPattern.FindStringSubmatch(value)
+	if match == nil || value == "P" || strings.HasSuffix(value, "T") {
+		return 0, fmt.Errorf("%w: invalid duration %q", ErrInvalidCalendar, value)
+	}
+	var d time.Duration
+	for i, unit := range []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second} {
+		if match[i+2] == "" {
+			continue
+		}
+		n, _ := strconv.Atoi(match[i+2])
+		d += time.Duration(n) * unit
+	}
+	if match[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+func unescape(text string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(text)
+}
+
+func containsTime(times []time.Time, t time.Time) bool {
+	for _, value := range times {
+		if value.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+type component struct {
+	name       string
+	properties []property
+	children   []*component
+}
+
+func (c *component) child(name string) *component {
+	for _, child := range c.children {
+		if child.name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+func (c *component) property(name string) *property {
+	for i := range c.properties {
+		if c.properties[i].name == name {
+			return &c.properties[i]
+		}
+	}
+	return nil
+}
+
+func (c *component) value(name string) string {
+	if property := c.property(name); property != nil {
+		return property.value
+	}
+	return ""
+}
+
+// parseComponents unfolds the content lines of the text and nests the
+// components they begin and end.
+func parseComponents(text string) (*component, error) {
+	text = strings.TrimPrefix(text, "\ufeff")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.NewReplacer("\n ", "", "\n\t", "").Replace(text)
+
+	root := &component{}
+	stack := []*component{root}
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		property, err := parseProperty(line)
+		if err != nil {
+			return nil, err
+		}
+		current := stack[len(stack)-1]
+		switch property.name {
+		case "BEGIN":
+			child := &component{name: strings.ToUpper(property.value)}
+			current.children = append(current.children, child)
+			stack = append(stack, child)
+		case "END":
+			if len(stack) == 1 || current.name != strings.ToUpper(property.value) {
+				return nil, fmt.Errorf("%w: unexpected END:%s", ErrInvalidCalendar, property.value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			current.properties = append(current.properties, property)
+		}
+	}
+	if len(stack) != 1 {
+		return nil, fmt.Errorf("%w: %s is not ended", ErrInvalidCalendar, stack[len(stack)-1].name)
+	}
+	return root, nil
+}
+
+// parseProperty splits a content line into its name, parameters and value.
+// Parameter values may be quoted to hold the delimiters.
+func parseProperty(line string) (property, error) {
+	p := property{params: map[string]string{}}
+	quoted := false
+	start := 0
+	name := ""
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == ';' || c == ':':
+			part := line[start:i]
+			if name == "" {
+				name = part
+			} else if key, value, ok := strings.Cut(part, "="); ok {
+				p.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+			}
+			start = i + 1
+			if c == ':' {
+				p.name = strings.ToUpper(name)
+				p.value = line[start:]
+				return p, nil
+			}
+		}
+	}
+	return p, errInvalidPropertyLine
+}
@@ -0,0 +1,139 @@
+package ical_test
+
+import (
+	"booking/internal/ical"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	data, err := os.ReadFile("testdata/" + name)
+	assert.NoError(t, err)
+	return data
+}
+
+func TestParseBusy(t *testing.T) {
+	bangkok, _ := time.LoadLocation("Asia/Bangkok")
+
+	t.Run("When events recur across a daylight saving change, should keep their local time and apply exceptions and overrides", func(t *testing.T) {
+		from := time.Date(2024, 2, 26, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2024, 3, 26, 0, 0, 0, 0, time.UTC)
+
+		busy, err := ical.ParseBusy(readFixture(t, "weekly.ics"), bangkok, from, to)
+
+		assert.NoError(t, err)
+		starts := []time.Time{}
+		for _, period := range busy {
+			starts = append(starts, period.Start.UTC())
+		}
+		assert.Equal(t, []time.Time{
+			time.Date(2024, 2, 26, 14, 0, 0, 0, time.UTC),
+			// the all-day offsite is read in the location
+			time.Date(2024, 3, 6, 17, 0, 0, 0, time.UTC),
+			// New York is on daylight saving time from March 10
+			time.Date(2024, 3, 11, 13, 0, 0, 0, time.UTC),
+			time.Date(2024, 3, 19, 18, 0, 0, 0, time.UTC),
+			time.Date(2024, 3, 25, 13, 0, 0, 0, time.UTC),
+		}, starts)
+		assert.Equal(t, "Team standup, weekly sync of the facilities team about the meeting rooms", busy[0].Summary)
+		assert.Equal(t, time.Hour, busy[0].End.Sub(busy[0].Start))
+		assert.Equal(t, time.Date(2024, 3, 8, 17, 0, 0, 0, time.UTC), busy[1].End.UTC())
+		assert.Equal(t, "Team standup (moved)", busy[3].Summary)
+	})
+
+	t.Run("When the time zone is a Windows name, should read it from its definition", func(t *testing.T) {
+		from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+		busy, err := ical.ParseBusy(readFixture(t, "outlook.ics"), time.UTC, from, to)
+
+		assert.NoError(t, err)
+		assert.Len(t, busy, 3)
+		assert.Equal(t, time.Date(2024, 5, 1, 2, 0, 0, 0, time.UTC), busy[0].Start.UTC())
+		assert.Equal(t, time.Date(2024, 5, 3, 5, 0, 0, 0, time.UTC), busy[2].End.UTC())
+	})
+
+	t.Run("When the window is before the events, should return none", func(t *testing.T) {
+		from := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		busy, err := ical.ParseBusy(readFixture(t, "weekly.ics"), bangkok, from, from.AddDate(0, 1, 0))
+
+		assert.NoError(t, err)
+		assert.Empty(t, busy)
+	})
+
+	t.Run("When the calendar is malformed, should fail", func(t *testing.T) {
+		from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		_, err := ical.ParseBusy(readFixture(t, "invalid.ics"), bangkok, from, from.AddDate(1, 0, 0))
+
+		assert.ErrorIs(t, err, ical.ErrInvalidCalendar)
+	})
+}
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer server.Close()
+
+	t.Run("When the calendar is served, should return it", func(t *testing.T) {
+		data, err := ical.Fetch(server.Client(), server.URL+"/outlook.ics")
+
+		assert.NoError(t, err)
+		assert.Equal(t, readFixture(t, "outlook.ics"), data)
+	})
+
+	t.Run("When the calendar is not found, should fail", func(t *testing.T) {
+		_, err := ical.Fetch(server.Client(), server.URL+"/missing.ics")
+
+		assert.Error(t, err)
+	})
+
+	t.Run("When the URL is not http, should fail", func(t *testing.T) {
+		_, err := ical.Fetch(server.Client(), "file:///etc/passwd")
+
+		assert.ErrorIs(t, err, ical.ErrUnsupportedURL)
+	})
+}
+
+func TestNewClient(t *testing.T) {
+	server := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer server.Close()
+	client := ical.NewClient(time.Second)
+
+	t.Run("When the URL is of the loopback, should refuse to connect", func(t *testing.T) {
+		_, err := ical.Fetch(client, server.URL+"/outlook.ics")
+
+		assert.ErrorIs(t, err, ical.ErrPrivateAddress)
+	})
+
+	t.Run("When the host name resolves to the loopback, should refuse to connect", func(t *testing.T) {
+		_, err := ical.Fetch(client, strings.Replace(server.URL, "127.0.0.1", "localhost", 1)+"/outlook.ics")
+
+		assert.ErrorIs(t, err, ical.ErrPrivateAddress)
+	})
+}
+
+func TestIsPublicAddress(t *testing.T) {
+	t.Run("When the address is on the internet, should report it public", func(t *testing.T) {
+		for _, address := range []string{"8.8.8.8", "1.1.1.1", "2001:4860:4860::8888"} {
+			assert.True(t, ical.IsPublicAddress(netip.MustParseAddr(address)), address)
+		}
+	})
+
+	t.Run("When the address is of the host, its network or a reserved range, should report it not public", func(t *testing.T) {
+		addresses := []string{
+			"127.0.0.1", "::1", "0.0.0.0", "::", "10.0.0.1", "172.16.0.1", "192.168.1.1", "100.64.0.1",
+			"169.254.169.254", "fe80::1", "fd00::1", "224.0.0.1", "255.255.255.255", "::ffff:127.0.0.1", "::ffff:169.254.169.254",
+		}
+		for _, address := range addresses {
+			assert.False(t, ical.IsPublicAddress(netip.MustParseAddr(address)), address)
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package invoice
+
+import (
+	"bytes"
+	"html/template"
+)
+
+var htmlTemplate = template.Must(template.New("document").Parse(`<!DOCTYPE html>
+<html lang="{{.Language}}">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} {{.Number}}</title>
+<style>
+body { font-family: "Sarabun", "Noto Sans Thai", sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; width: 100%; }
+th, td { padding: 0.4em; border-bottom: 1px solid #ccc; text-align: left; }
+.amount { text-align: right; }
+.parties { display: flex; gap: 4em; margin: 1.5em 0; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{.Labels.number}} {{.Number}}<br>{{.Labels.date}} {{.IssuedAt}}</p>
+<div class="parties">
+{{range .Parties}}<div>
+<strong>{{.Label}}</strong><br>
+{{.Name}}<br>
+{{if .TaxID}}{{$.Labels.taxID}} {{.TaxID}} ({{.Branch}})<br>{{end}}
+{{if .Address}}{{$.Labels.address}} {{.Address}}{{end}}
+</div>
+{{end}}</div>
+{{if .Description}}<p>{{.Description}}</p>{{end}}
+<table>
+<tr><th>{{.Labels.description}}</th><th class="amount">{{.Labels.amount}}</th></tr>
+{{range .Lines}}<tr><td>{{.Name}}</td><td class="amount">{{.Amount}}</td></tr>
+{{end}}<tr><td>{{.Labels.subtotal}}</td><td class="amount">{{.Subtotal}}</td></tr>
+<tr><td>{{.VATLabel}}</td><td class="amount">{{.VATAmount}}</td></tr>
+<tr><th>{{.Labels.total}}</th><th class="amount">{{.Total}}</th></tr>
+</table>
+<p>{{.Labels.vatIncluded}}</p>
+</body>
+</html>
+`))
+
+func renderHTML(v view) ([]byte, error) {
+	var buf bytes.Buffer
+	err := htmlTemplate.Execute(&buf, v)
+	return buf.Bytes(), err
+}
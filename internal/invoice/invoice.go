@@ -0,0 +1,207 @@
+package invoice
+
+import (
+	"booking/internal/entity"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	LANGUAGE_EN = "en"
+	LANGUAGE_TH = "th"
+)
+
+const (
+	FORMAT_PDF  = "pdf"
+	FORMAT_HTML = "html"
+)
+
+// TIME_ZONE is the time zone dates are printed in.
+const TIME_ZONE = "Asia/Bangkok"
+
+var ErrUnknownFormat = errors.New("the document format must be pdf or html")
+
+// IRenderer renders tax documents for download.
+type IRenderer interface {
+	Render(document *entity.TaxDocument, language string, format string) ([]byte, error)
+}
+
+type renderer struct {
+	// font is a TrueType font with Thai glyphs for PDFs. PDFs fall back to
+	// a core font, which only has Latin glyphs, without it.
+	font []byte
+}
+
+// NewRenderer returns a renderer printing PDFs in the TrueType font at the
+// path. The renderer is still returned, without the font, when the font
+// can't be read.
+func NewRenderer(fontPath string) (*renderer, error) {
+	if fontPath == "" {
+		return &renderer{}, nil
+	}
+	font, err := os.ReadFile(fontPath)
+	if err != nil {
+		return &renderer{}, err
+	}
+	return &renderer{font: font}, nil
+}
+
+func (renderer *renderer) Render(document *entity.TaxDocument, language string, format string) ([]byte, error) {
+	switch format {
+	case FORMAT_HTML:
+		return renderHTML(newView(document, language))
+	case FORMAT_PDF:
+		// Thai can't be printed in a core font
+		if renderer.font == nil {
+			language = LANGUAGE_EN
+		}
+		return renderPDF(newView(document, language), renderer.font)
+	}
+	return nil, ErrUnknownFormat
+}
+
+// view is a document with its labels and amounts formatted in a language.
+type view struct {
+	Language string
+	Labels   map[string]string
+	Title    string
+	Number   string
+	IssuedAt string
+	// Parties are the seller then the buyer.
+	Parties     []partyView
+	Description string
+	Lines       []lineView
+	Subtotal    string
+	VATLabel    string
+	VATAmount   string
+	Total       string
+}
+
+type partyView struct {
+	Label   string
+	Name    string
+	TaxID   string
+	Branch  string
+	Address string
+}
+
+type lineView struct {
+	Name   string
+	Amount string
+}
+
+func newView(document *entity.TaxDocument, language string) view {
+	labels, ok := translations[language]
+	if !ok {
+		language = LANGUAGE_EN
+		labels = translations[language]
+	}
+	v := view{
+		Language:    language,
+		Labels:      labels,
+		Title:       labels[document.Type],
+		Number:      document.Number,
+		IssuedAt:    formatDate(document.IssuedAt, language),
+		Parties:     []partyView{newPartyView(labels["seller"], document.Seller, labels)},
+		Description: document.Description,
+		Subtotal:    FormatAmount(document.Subtotal),
+		VATLabel:    fmt.Sprintf(labels["vat"], document.VATRate),
+		VATAmount:   FormatAmount(document.VATAmount),
+		Total:       FormatAmount(document.Total),
+	}
+	// abbreviated documents don't name the customer
+	if document.Buyer.Name != "" {
+		v.Parties = append(v.Parties, newPartyView(labels["buyer"], document.Buyer, labels))
+	}
+	for _, line := range document.Lines {
+		v.Lines = append(v.Lines, lineView{Name: line.Name, Amount: FormatAmount(line.Amount)})
+	}
+	return v
+}
+
+func newPartyView(label string, party entity.DocumentParty, labels map[string]string) partyView {
+	branch := ""
+	if party.TaxID != "" {
+		branch = labels["headOffice"]
+		if party.BranchCode != "" && party.BranchCode != "00000" {
+			branch = fmt.Sprintf(labels["branch"], party.BranchCode)
+		}
+	}
+	return partyView{Label: label, Name: party.Name, TaxID: party.TaxID, Branch: branch, Address: party.Address}
+}
+
+// FormatAmount formats an amount in satang as baht with thousands separators,
+// e.g. 1,234.50.
+func FormatAmount(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	baht := fmt.Sprint(amount / 100)
+	var grouped strings.Builder
+	for i, digit := range baht {
+		if i > 0 && (len(baht)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+	return fmt.Sprintf("%s%s.%02d", sign, grouped.String(), amount%100)
+}
+
+// formatDate formats the date in the language, Thai dates in the Buddhist
+// era.
+func formatDate(t time.Time, language string) string {
+	location, err := time.LoadLocation(TIME_ZONE)
+	if err == nil {
+		t = t.In(location)
+	}
+	if language == LANGUAGE_TH {
+		return fmt.Sprintf("%d %s %d", t.Day(), thaiMonths[t.Month()-1], t.Year()+543)
+	}
+	return t.Format("2 January 2006")
+}
+
+var thaiMonths = []string{"มกราคม", "กุมภาพันธ์", "มีนาคม", "เมษายน", "พฤษภาคม", "มิถุนายน", "กรกฎาคม", "สิงหาคม", "กันยายน", "ตุลาคม", "พฤศจิกายน", "ธันวาคม"}
+
+var translations = map[string]map[string]string{
+	LANGUAGE_EN: {
+		entity.TAX_DOCUMENT_INVOICE: "Tax Invoice",
+		entity.TAX_DOCUMENT_RECEIPT: "Receipt / Tax Invoice",
+		"number":                    "No.",
+		"date":                      "Date",
+		"seller":                    "Issued by",
+		"buyer":                     "Customer",
+		"taxID":                     "Tax ID",
+		"headOffice":                "Head office",
+		"branch":                    "Branch %s",
+		"address":                   "Address",
+		"description":               "Description",
+		"amount":                    "Amount (THB)",
+		"subtotal":                  "Value before VAT",
+		"vat":                       "VAT %d%%",
+		"total":                     "Total",
+		"vatIncluded":               "Prices include VAT.",
+	},
+	LANGUAGE_TH: {
+		entity.TAX_DOCUMENT_INVOICE: "ใบแจ้งหนี้/ใบกำกับภาษี",
+		entity.TAX_DOCUMENT_RECEIPT: "ใบเสร็จรับเงิน/ใบกำกับภาษี",
+		"number":                    "เลขที่",
+		"date":                      "วันที่",
+		"seller":                    "ผู้ออกเอกสาร",
+		"buyer":                     "ลูกค้า",
+		"taxID":                     "เลขประจำตัวผู้เสียภาษี",
+		"headOffice":                "สำนักงานใหญ่",
+		"branch":                    "สาขา %s",
+		"address":                   "ที่อยู่",
+		"description":               "รายการ",
+		"amount":                    "จำนวนเงิน (บาท)",
+		"subtotal":                  "มูลค่าก่อนภาษีมูลค่าเพิ่ม",
+		"vat":                       "ภาษีมูลค่าเพิ่ม %d%%",
+		"total":                     "จำนวนเงินรวมทั้งสิ้น",
+		"vatIncluded":               "ราคารวมภาษีมูลค่าเพิ่มแล้ว",
+	},
+}
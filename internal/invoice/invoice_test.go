@@ -0,0 +1,65 @@
+package invoice_test
+
+import (
+	"booking/internal/entity"
+	"booking/internal/invoice"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newDocument() *entity.TaxDocument {
+	document := &entity.TaxDocument{
+		Type:     entity.TAX_DOCUMENT_RECEIPT,
+		Number:   "RCT-2024-000003",
+		Seller:   entity.DocumentParty{Name: "Example Co., Ltd.", TaxID: "0105561234567", BranchCode: "00001", Address: "Bangkok"},
+		Lines:    entity.DocumentLines{{Name: "Base rate", Amount: 1284000}, {Name: "SAVE10", Amount: -128400}},
+		IssuedAt: time.Date(2024, 5, 1, 3, 0, 0, 0, time.UTC),
+	}
+	document.SetTotal(1155600)
+	return document
+}
+
+func TestFormatAmount(t *testing.T) {
+	assert.Equal(t, "0.05", invoice.FormatAmount(5))
+	assert.Equal(t, "1,234,567.80", invoice.FormatAmount(123456780))
+	assert.Equal(t, "-1,284.00", invoice.FormatAmount(-128400))
+}
+
+func TestRender(t *testing.T) {
+	renderer, err := invoice.NewRenderer("")
+	assert.NoError(t, err)
+
+	t.Run("When the language is Thai, should render the HTML with Thai labels and a Buddhist era date", func(t *testing.T) {
+		html, err := renderer.Render(newDocument(), invoice.LANGUAGE_TH, invoice.FORMAT_HTML)
+
+		assert.NoError(t, err)
+		assert.Contains(t, string(html), "ใบเสร็จรับเงิน/ใบกำกับภาษี")
+		assert.Contains(t, string(html), "1 พฤษภาคม 2567")
+		assert.Contains(t, string(html), "สาขา 00001")
+		assert.Contains(t, string(html), "756.00")
+	})
+
+	t.Run("When the language is English, should render the HTML with English labels", func(t *testing.T) {
+		html, err := renderer.Render(newDocument(), invoice.LANGUAGE_EN, invoice.FORMAT_HTML)
+
+		assert.NoError(t, err)
+		assert.Contains(t, string(html), "Receipt / Tax Invoice")
+		assert.Contains(t, string(html), "1 May 2024")
+		assert.Contains(t, string(html), "11,556.00")
+	})
+
+	t.Run("When no Thai font is configured, should render the PDF in English", func(t *testing.T) {
+		pdf, err := renderer.Render(newDocument(), invoice.LANGUAGE_TH, invoice.FORMAT_PDF)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "%PDF", string(pdf[:4]))
+	})
+
+	t.Run("When the format is unknown, should return an error", func(t *testing.T) {
+		_, err := renderer.Render(newDocument(), invoice.LANGUAGE_EN, "docx")
+
+		assert.ErrorIs(t, err, invoice.ErrUnknownFormat)
+	})
+}
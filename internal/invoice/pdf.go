@@ -0,0 +1,71 @@
+package invoice
+
+import (
+	"bytes"
+
+	"github.com/go-pdf/fpdf"
+)
+
+const (
+	pdfFontFamily  = "document"
+	pdfLineHeight  = 6.0
+	pdfPageWidth   = 180.0
+	pdfAmountWidth = 45.0
+)
+
+func renderPDF(v view, font []byte) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetCreator("booking", true)
+	pdf.SetTitle(v.Title+" "+v.Number, true)
+
+	// core fonts are encoded in cp1252 rather than UTF-8
+	family, translate := "Helvetica", pdf.UnicodeTranslatorFromDescriptor("")
+	if font != nil {
+		pdf.AddUTF8FontFromBytes(pdfFontFamily, "", font)
+		family, translate = pdfFontFamily, func(s string) string { return s }
+	}
+	pdf.AddPage()
+
+	pdf.SetFont(family, "", 18)
+	pdf.CellFormat(pdfPageWidth, 10, translate(v.Title), "", 1, "L", false, 0, "")
+	pdf.SetFont(family, "", 11)
+	pdf.CellFormat(pdfPageWidth, pdfLineHeight, translate(v.Labels["number"]+" "+v.Number), "", 1, "L", false, 0, "")
+	pdf.CellFormat(pdfPageWidth, pdfLineHeight, translate(v.Labels["date"]+" "+v.IssuedAt), "", 1, "L", false, 0, "")
+	pdf.Ln(pdfLineHeight)
+
+	for _, party := range v.Parties {
+		pdf.CellFormat(pdfPageWidth, pdfLineHeight, translate(party.Label), "B", 1, "L", false, 0, "")
+		pdf.MultiCell(pdfPageWidth, pdfLineHeight, translate(party.Name), "", "L", false)
+		if party.TaxID != "" {
+			pdf.MultiCell(pdfPageWidth, pdfLineHeight, translate(v.Labels["taxID"]+" "+party.TaxID+" ("+party.Branch+")"), "", "L", false)
+		}
+		if party.Address != "" {
+			pdf.MultiCell(pdfPageWidth, pdfLineHeight, translate(v.Labels["address"]+" "+party.Address), "", "L", false)
+		}
+		pdf.Ln(pdfLineHeight / 2)
+	}
+
+	if v.Description != "" {
+		pdf.MultiCell(pdfPageWidth, pdfLineHeight, translate(v.Description), "", "L", false)
+		pdf.Ln(pdfLineHeight / 2)
+	}
+
+	row := func(label string, amount string, border string) {
+		pdf.CellFormat(pdfPageWidth-pdfAmountWidth, pdfLineHeight+1, translate(label), border, 0, "L", false, 0, "")
+		pdf.CellFormat(pdfAmountWidth, pdfLineHeight+1, translate(amount), border, 1, "R", false, 0, "")
+	}
+	row(v.Labels["description"], v.Labels["amount"], "TB")
+	for _, line := range v.Lines {
+		row(line.Name, line.Amount, "B")
+	}
+	row(v.Labels["subtotal"], v.Subtotal, "")
+	row(v.VATLabel, v.VATAmount, "")
+	row(v.Labels["total"], v.Total, "TB")
+	pdf.Ln(pdfLineHeight)
+	pdf.CellFormat(pdfPageWidth, pdfLineHeight, translate(v.Labels["vatIncluded"]), "", 1, "L", false, 0, "")
+
+	var buf bytes.Buffer
+	err := pdf.Output(&buf)
+	return buf.Bytes(), err
+}
@@ -0,0 +1,106 @@
+// Package media makes the thumbnails of the photos uploaded to resources.
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+
+	_ "image/gif"
+	_ "image/png"
+)
+
+// MAX_PIXELS bounds the images decoded for a thumbnail, about 100 MB once
+// decoded, so a small file claiming a huge size can't exhaust the memory.
+const MAX_PIXELS = 25_000_000
+
+const THUMBNAIL_QUALITY = 80
+
+var (
+	ErrUnsupportedImage = errors.New("the image must be a JPEG, PNG or GIF")
+	ErrImageTooLarge    = errors.New("the image has too many pixels")
+)
+
+// Thumbnail is a JPEG of an image scaled down to fit a square, and the size
+// of the original image.
+type Thumbnail struct {
+	Content []byte
+	Width   int
+	Height  int
+}
+
+// MakeThumbnail scales the JPEG, PNG or GIF image down so its longest side is
+// at most maxSide, never up. Transparent pixels become white since JPEG has
+// no alpha.
+func MakeThumbnail(data []byte, maxSide int) (thumbnail Thumbnail, err error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return thumbnail, ErrUnsupportedImage
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > MAX_PIXELS {
+		return thumbnail, ErrImageTooLarge
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return thumbnail, ErrUnsupportedImage
+	}
+
+	bounds := src.Bounds()
+	opaque := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(opaque, opaque.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(opaque, opaque.Bounds(), src, bounds.Min, draw.Over)
+
+	width, height := fit(bounds.Dx(), bounds.Dy(), maxSide)
+	var buf bytes.Buffer
+	if err = jpeg.Encode(&buf, downscale(opaque, width, height), &jpeg.Options{Quality: THUMBNAIL_QUALITY}); err != nil {
+		return thumbnail, err
+	}
+	thumbnail.Content = buf.Bytes()
+	thumbnail.Width = bounds.Dx()
+	thumbnail.Height = bounds.Dy()
+	return
+}
+
+// fit returns the size keeping the aspect ratio with the longest side at most
+// maxSide, and each side at least one pixel.
+func fit(width int, height int, maxSide int) (int, int) {
+	if maxSide <= 0 || (width <= maxSide && height <= maxSide) {
+		return width, height
+	}
+	if width >= height {
+		return maxSide, max(1, height*maxSide/width)
+	}
+	return max(1, width*maxSide/height), maxSide
+}
+
+// downscale averages the source pixels covered by each pixel of the result,
+// which keeps fine details such as text from aliasing as sampling would.
+func downscale(src *image.RGBA, width int, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	srcWidth, srcHeight := src.Bounds().Dx(), src.Bounds().Dy()
+	for y := 0; y < height; y++ {
+		y0, y1 := y*srcHeight/height, max((y+1)*srcHeight/height, y*srcHeight/height+1)
+		for x := 0; x < width; x++ {
+			x0, x1 := x*srcWidth/width, max((x+1)*srcWidth/width, x*srcWidth/width+1)
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride+x0*4 : sy*src.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += int(row[i])
+					sum[1] += int(row[i+1])
+					sum[2] += int(row[i+2])
+					sum[3] += int(row[i+3])
+				}
+			}
+			count := (y1 - y0) * (x1 - x0)
+			offset := y*dst.Stride + x*4
+			for i := range sum {
+				dst.Pix[offset+i] = uint8(sum[i] / count)
+			}
+		}
+	}
+	return dst
+}
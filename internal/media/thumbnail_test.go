@@ -0,0 +1,103 @@
+package media_test
+
+import (
+	"booking/internal/media"
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func encodePNG(img image.Image) []byte {
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	return buf.Bytes()
+}
+
+func TestMakeThumbnail(t *testing.T) {
+	t.Run("When the image is larger than the thumbnail, should scale it down keeping the aspect ratio", func(t *testing.T) {
+		img := image.NewRGBA(image.Rect(0, 0, 800, 400))
+
+		thumbnail, err := media.MakeThumbnail(encodePNG(img), 320)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, 800, thumbnail.Width)
+			assert.Equal(t, 400, thumbnail.Height)
+			config, err := jpeg.DecodeConfig(bytes.NewReader(thumbnail.Content))
+			if assert.NoError(t, err) {
+				assert.Equal(t, 320, config.Width)
+				assert.Equal(t, 160, config.Height)
+			}
+		}
+	})
+
+	t.Run("When the image is smaller than the thumbnail, should not scale it up", func(t *testing.T) {
+		img := image.NewRGBA(image.Rect(0, 0, 40, 100))
+
+		thumbnail, err := media.MakeThumbnail(encodePNG(img), 320)
+
+		if assert.NoError(t, err) {
+			config, _ := jpeg.DecodeConfig(bytes.NewReader(thumbnail.Content))
+			assert.Equal(t, 40, config.Width)
+			assert.Equal(t, 100, config.Height)
+		}
+	})
+
+	t.Run("When the image is transparent, should make it white", func(t *testing.T) {
+		img := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+
+		thumbnail, err := media.MakeThumbnail(encodePNG(img), 16)
+
+		if assert.NoError(t, err) {
+			decoded, _ := jpeg.Decode(bytes.NewReader(thumbnail.Content))
+			r, g, b, _ := decoded.At(8, 8).RGBA()
+			assert.Greater(t, r, uint32(0xf000))
+			assert.Greater(t, g, uint32(0xf000))
+			assert.Greater(t, b, uint32(0xf000))
+		}
+	})
+
+	t.Run("When scaling stripes down, should average them rather than keep one color", func(t *testing.T) {
+		img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+		for y := 0; y < 64; y++ {
+			for x := 0; x < 64; x += 2 {
+				img.Set(x, y, color.White)
+				img.Set(x+1, y, color.Black)
+			}
+		}
+
+		thumbnail, err := media.MakeThumbnail(encodePNG(img), 8)
+
+		if assert.NoError(t, err) {
+			decoded, _ := jpeg.Decode(bytes.NewReader(thumbnail.Content))
+			gray, _, _, _ := decoded.At(4, 4).RGBA()
+			assert.InDelta(t, 0x7fff, gray, 0x1000)
+		}
+	})
+
+	t.Run("When the file is not an image, should return ErrUnsupportedImage", func(t *testing.T) {
+		_, err := media.MakeThumbnail([]byte("%PDF-1.4"), 320)
+
+		assert.ErrorIs(t, err, media.ErrUnsupportedImage)
+	})
+
+	t.Run("When the image has too many pixels, should return ErrImageTooLarge without decoding it", func(t *testing.T) {
+		var buf bytes.Buffer
+		png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1)))
+		data := buf.Bytes()
+		// The width and height are the first fields of the IHDR chunk, 10000
+		// pixels each, followed by the checksum of the chunk.
+		copy(data[16:24], []byte{0, 0, 0x27, 0x10, 0, 0, 0x27, 0x10})
+		binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(data[12:29]))
+
+		_, err := media.MakeThumbnail(data, 320)
+
+		assert.ErrorIs(t, err, media.ErrImageTooLarge)
+	})
+}
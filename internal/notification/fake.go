@@ -0,0 +1,51 @@
+package notification
+
+import (
+	"booking/internal/logs"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// fakeSender logs the messages of a channel instead of delivering them, for
+// development and tests.
+type fakeSender struct {
+	channel string
+	mutex   sync.Mutex
+	sent    []Message
+	err     error
+}
+
+func NewFakeSender(channel string) *fakeSender {
+	return &fakeSender{channel: channel}
+}
+
+func (sender *fakeSender) Channel() string {
+	return sender.channel
+}
+
+func (sender *fakeSender) Send(message Message) (err error) {
+	sender.mutex.Lock()
+	defer sender.mutex.Unlock()
+	if sender.err != nil {
+		return sender.err
+	}
+	sender.sent = append(sender.sent, message)
+	logs.Info("notification", zap.String("channel", sender.channel), zap.String("to", message.To), zap.String("subject", message.Subject))
+	return nil
+}
+
+// Sent returns the messages sent so far.
+func (sender *fakeSender) Sent() []Message {
+	sender.mutex.Lock()
+	defer sender.mutex.Unlock()
+	return append([]Message{}, sender.sent...)
+}
+
+// Fail makes the following sends fail with the error, or succeed again when
+// it is nil.
+func (sender *fakeSender) Fail(err error) {
+	sender.mutex.Lock()
+	defer sender.mutex.Unlock()
+	sender.err = err
+}
@@ -0,0 +1,41 @@
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// postJSON posts the payload to an HTTP API. Client errors other than rate
+// limiting are permanent.
+func postJSON(client *http.Client, url string, headers map[string]string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return nil
+	}
+	detail, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+	err = fmt.Errorf("%s responded %s: %s", url, response.Status, bytes.TrimSpace(detail))
+	if response.StatusCode >= 400 && response.StatusCode < 500 && response.StatusCode != http.StatusTooManyRequests {
+		return Permanent(err)
+	}
+	return err
+}
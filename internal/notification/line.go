@@ -0,0 +1,45 @@
+package notification
+
+import "net/http"
+
+// LINE_API_URL is the address of the LINE Messaging API.
+const LINE_API_URL = "https://api.line.me"
+
+// lineSender pushes messages to LINE users who added the official account of
+// the channel as a friend.
+type lineSender struct {
+	client             *http.Client
+	url                string
+	channelAccessToken string
+}
+
+func NewLineSender(client *http.Client, url string, channelAccessToken string) *lineSender {
+	return &lineSender{client: client, url: url, channelAccessToken: channelAccessToken}
+}
+
+func (sender *lineSender) Channel() string {
+	return CHANNEL_LINE
+}
+
+type lineTextMessage struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type linePushRequest struct {
+	To       string            `json:"to"`
+	Messages []lineTextMessage `json:"messages"`
+}
+
+// Send pushes the message as text. The retry key makes LINE deliver a
+// message retried after a timeout only once.
+func (sender *lineSender) Send(message Message) (err error) {
+	headers := map[string]string{"Authorization": "Bearer " + sender.channelAccessToken}
+	if message.ID != "" {
+		headers["X-Line-Retry-Key"] = message.ID
+	}
+	return postJSON(sender.client, sender.url+"/v2/bot/message/push", headers, linePushRequest{
+		To:       message.To,
+		Messages: []lineTextMessage{{Type: "text", Text: message.Body}},
+	})
+}
@@ -0,0 +1,73 @@
+package notification
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	CHANNEL_EMAIL = "email"
+	CHANNEL_SMS   = "sms"
+	CHANNEL_LINE  = "line"
+)
+
+const (
+	EVENT_BOOKING_CONFIRMED   = "BOOKING_CONFIRMED"
+	EVENT_BOOKING_CANCELLED   = "BOOKING_CANCELLED"
+	EVENT_BOOKING_REMINDER    = "BOOKING_REMINDER"
+	EVENT_BOOKING_RESCHEDULED = "BOOKING_RESCHEDULED"
+)
+
+const (
+	// RETRY_BASE is how long a message is retried after its first failed
+	// attempt, doubling with each attempt up to RETRY_MAX.
+	RETRY_BASE = 30 * time.Second
+	RETRY_MAX  = 6 * time.Hour
+)
+
+// ErrPermanent marks failures retrying won't fix, such as an unknown
+// recipient.
+var ErrPermanent = errors.New("permanent delivery failure")
+
+// Message is a notification to deliver to the address of a user on a channel,
+// an email address, a phone number or a LINE user ID. ID identifies the
+// message across retries.
+type Message struct {
+	ID      string
+	To      string
+	Subject string
+	Body    string
+}
+
+// ISender delivers messages on a channel.
+type ISender interface {
+	Channel() string
+	Send(message Message) (err error)
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() []error {
+	return []error{e.err, ErrPermanent}
+}
+
+// Permanent marks the error as a failure not to retry.
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// Backoff returns how long to wait before retrying a message that failed the
+// given number of attempts.
+func Backoff(attempts int) time.Duration {
+	backoff := RETRY_BASE
+	for i := 1; i < attempts && backoff < RETRY_MAX; i++ {
+		backoff *= 2
+	}
+	return min(backoff, RETRY_MAX)
+}
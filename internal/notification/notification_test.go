@@ -0,0 +1,68 @@
+package notification_test
+
+import (
+	"booking/internal/notification"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff(t *testing.T) {
+	t.Run("When the message is retried, should double the wait with each attempt", func(t *testing.T) {
+		assert.Equal(t, 30*time.Second, notification.Backoff(1))
+		assert.Equal(t, time.Minute, notification.Backoff(2))
+		assert.Equal(t, 4*time.Minute, notification.Backoff(4))
+	})
+
+	t.Run("When the message has been retried many times, should wait at most the maximum", func(t *testing.T) {
+		assert.Equal(t, notification.RETRY_MAX, notification.Backoff(20))
+		assert.Equal(t, notification.RETRY_MAX, notification.Backoff(1000))
+	})
+}
+
+func TestPermanent(t *testing.T) {
+	t.Run("When the error is marked permanent, should keep its message and cause", func(t *testing.T) {
+		cause := errors.New("unknown recipient")
+
+		err := notification.Permanent(cause)
+
+		assert.ErrorIs(t, err, notification.ErrPermanent)
+		assert.ErrorIs(t, err, cause)
+		assert.Equal(t, "unknown recipient", err.Error())
+	})
+}
+
+func TestRenderBooking(t *testing.T) {
+	location, _ := time.LoadLocation("Asia/Bangkok")
+	data := notification.BookingData{
+		BookingID:    "1f0c",
+		ResourceName: "Meeting Room",
+		StartAt:      time.Date(2026, 3, 2, 9, 0, 0, 0, location),
+		EndAt:        time.Date(2026, 3, 2, 10, 30, 0, 0, location),
+		RefundAmount: 50050,
+	}
+
+	t.Run("When the booking is cancelled, should tell the refund in baht", func(t *testing.T) {
+		subject, body, err := notification.RenderBooking(notification.EVENT_BOOKING_CANCELLED, notification.LANGUAGE_EN, data)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Booking cancelled: Meeting Room", subject)
+		assert.Equal(t, "Your booking of Meeting Room on Mon 2 Mar 2026 09:00 +07 - 10:30 is cancelled.\nRefund: 500.50 THB\nBooking ID: 1f0c", body)
+	})
+
+	t.Run("When the user reads Thai, should write the message in Thai", func(t *testing.T) {
+		subject, _, err := notification.RenderBooking(notification.EVENT_BOOKING_CONFIRMED, notification.LANGUAGE_TH, data)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "ยืนยันการจอง: Meeting Room", subject)
+	})
+
+	t.Run("When the language has no messages, should write the message in English", func(t *testing.T) {
+		subject, _, err := notification.RenderBooking(notification.EVENT_BOOKING_REMINDER, "fr", data)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Reminder: Meeting Room on Mon 2 Mar 2026 09:00 +07", subject)
+	})
+}
@@ -0,0 +1,102 @@
+package notification_test
+
+import (
+	"booking/internal/notification"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newAPIServer answers every request with the status code, recording the last
+// request and its JSON body.
+func newAPIServer(t *testing.T, statusCode int) (*httptest.Server, *http.Request, map[string]any) {
+	request := &http.Request{}
+	body := map[string]any{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*request = *r
+		json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(statusCode)
+	}))
+	t.Cleanup(server.Close)
+	return server, request, body
+}
+
+func TestLineSender(t *testing.T) {
+	message := notification.Message{ID: "6f2c5f0e-1d2b-4c43-9f52-3f1b2d7c8a10", To: "U4af4980629", Body: "Your booking is confirmed."}
+
+	t.Run("When the message is pushed, should send it as text with a retry key", func(t *testing.T) {
+		server, request, body := newAPIServer(t, http.StatusOK)
+		sender := notification.NewLineSender(server.Client(), server.URL, "channeltoken")
+
+		err := sender.Send(message)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "/v2/bot/message/push", request.URL.Path)
+		assert.Equal(t, "Bearer channeltoken", request.Header.Get("Authorization"))
+		assert.Equal(t, message.ID, request.Header.Get("X-Line-Retry-Key"))
+		assert.Equal(t, map[string]any{"to": "U4af4980629", "messages": []any{map[string]any{"type": "text", "text": "Your booking is confirmed."}}}, body)
+	})
+
+	t.Run("When LINE rejects the request, should fail permanently", func(t *testing.T) {
+		server, _, _ := newAPIServer(t, http.StatusBadRequest)
+		sender := notification.NewLineSender(server.Client(), server.URL, "channeltoken")
+
+		err := sender.Send(message)
+
+		assert.ErrorIs(t, err, notification.ErrPermanent)
+	})
+
+	t.Run("When LINE limits the rate of requests, should fail to be retried", func(t *testing.T) {
+		server, _, _ := newAPIServer(t, http.StatusTooManyRequests)
+		sender := notification.NewLineSender(server.Client(), server.URL, "channeltoken")
+
+		err := sender.Send(message)
+
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, notification.ErrPermanent)
+	})
+}
+
+func TestSMSSender(t *testing.T) {
+	message := notification.Message{To: "+66812345678", Body: "Your booking is confirmed."}
+
+	t.Run("When the message is sent, should post it to the gateway", func(t *testing.T) {
+		server, request, body := newAPIServer(t, http.StatusAccepted)
+		sender := notification.NewSMSSender(server.Client(), server.URL+"/messages", "apikey", "Booking")
+
+		err := sender.Send(message)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Bearer apikey", request.Header.Get("Authorization"))
+		assert.Equal(t, map[string]any{"from": "Booking", "to": "+66812345678", "text": "Your booking is confirmed."}, body)
+	})
+
+	t.Run("When the gateway is down, should fail to be retried", func(t *testing.T) {
+		server, _, _ := newAPIServer(t, http.StatusServiceUnavailable)
+		sender := notification.NewSMSSender(server.Client(), server.URL+"/messages", "apikey", "Booking")
+
+		err := sender.Send(message)
+
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, notification.ErrPermanent)
+	})
+}
+
+func TestBuildEmail(t *testing.T) {
+	t.Run("When the message is in Thai, should encode the subject and body", func(t *testing.T) {
+		message := notification.Message{ID: "1f0c", To: "example@gmail.com", Subject: "ยืนยันการจอง", Body: "การจองได้รับการยืนยันแล้ว"}
+
+		email := string(notification.BuildEmail("no-reply@booking.local", message, time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)))
+
+		assert.Contains(t, email, "To: example@gmail.com\r\n")
+		assert.Contains(t, email, "Subject: =?utf-8?q?")
+		assert.Contains(t, email, "Message-ID: <1f0c@booking>\r\n")
+		assert.Contains(t, email, "Content-Transfer-Encoding: quoted-printable\r\n\r\n=E0=B8")
+		assert.False(t, strings.ContainsFunc(email, func(r rune) bool { return r > 127 }))
+	})
+}
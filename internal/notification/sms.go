@@ -0,0 +1,28 @@
+package notification
+
+import "net/http"
+
+// smsSender texts messages through the JSON API of an SMS gateway, posting
+// the sender name, the phone number and the text.
+type smsSender struct {
+	client *http.Client
+	url    string
+	apiKey string
+	from   string
+}
+
+func NewSMSSender(client *http.Client, url string, apiKey string, from string) *smsSender {
+	return &smsSender{client: client, url: url, apiKey: apiKey, from: from}
+}
+
+func (sender *smsSender) Channel() string {
+	return CHANNEL_SMS
+}
+
+func (sender *smsSender) Send(message Message) (err error) {
+	return postJSON(sender.client, sender.url, map[string]string{"Authorization": "Bearer " + sender.apiKey}, map[string]string{
+		"from": sender.from,
+		"to":   message.To,
+		"text": message.Body,
+	})
+}
@@ -0,0 +1,64 @@
+package notification
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"time"
+)
+
+// smtpSender emails messages through an SMTP server.
+type smtpSender struct {
+	address string
+	from    string
+	auth    smtp.Auth
+}
+
+// NewSMTPSender sends from the address through the server, authenticating
+// when a username is given.
+func NewSMTPSender(host string, port int, username string, password string, from string) *smtpSender {
+	sender := &smtpSender{address: net.JoinHostPort(host, strconv.Itoa(port)), from: from}
+	if username != "" {
+		sender.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return sender
+}
+
+func (sender *smtpSender) Channel() string {
+	return CHANNEL_EMAIL
+}
+
+func (sender *smtpSender) Send(message Message) (err error) {
+	err = smtp.SendMail(sender.address, sender.auth, sender.from, []string{message.To}, BuildEmail(sender.from, message, time.Now()))
+	// 5xx replies, such as a mailbox that doesn't exist, are final
+	var protocolErr *textproto.Error
+	if errors.As(err, &protocolErr) && protocolErr.Code >= 500 {
+		return Permanent(err)
+	}
+	return err
+}
+
+// BuildEmail writes the message as a plain text UTF-8 email.
+func BuildEmail(from string, message Message, date time.Time) []byte {
+	var email bytes.Buffer
+	fmt.Fprintf(&email, "From: %s\r\n", from)
+	fmt.Fprintf(&email, "To: %s\r\n", message.To)
+	fmt.Fprintf(&email, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&email, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	if message.ID != "" {
+		fmt.Fprintf(&email, "Message-ID: <%s@booking>\r\n", message.ID)
+	}
+	email.WriteString("MIME-Version: 1.0\r\n")
+	email.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	email.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	body := quotedprintable.NewWriter(&email)
+	body.Write([]byte(message.Body))
+	body.Close()
+	return email.Bytes()
+}
@@ -0,0 +1,102 @@
+package notification
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+	"time"
+)
+
+const (
+	LANGUAGE_EN = "en"
+	LANGUAGE_TH = "th"
+)
+
+// BookingData is what the messages about a booking say. StartAt and EndAt
+// are in the time zone of the resource and RefundAmount is in satang.
+type BookingData struct {
+	BookingID    string
+	ResourceName string
+	StartAt      time.Time
+	EndAt        time.Time
+	RefundAmount int64
+}
+
+type messageTemplate struct {
+	subject string
+	body    string
+}
+
+var templateFuncs = template.FuncMap{
+	"datetime": func(t time.Time) string { return t.Format("Mon 2 Jan 2006 15:04 MST") },
+	"time":     func(t time.Time) string { return t.Format("15:04") },
+	"baht":     func(satang int64) string { return fmt.Sprintf("%d.%02d", satang/100, satang%100) },
+}
+
+var messageTemplates = map[string]map[string]messageTemplate{
+	LANGUAGE_EN: {
+		EVENT_BOOKING_CONFIRMED: {
+			subject: "Booking confirmed: {{.ResourceName}}",
+			body:    "Your booking of {{.ResourceName}} on {{datetime .StartAt}} - {{time .EndAt}} is confirmed.\nBooking ID: {{.BookingID}}",
+		},
+		EVENT_BOOKING_CANCELLED: {
+			subject: "Booking cancelled: {{.ResourceName}}",
+			body:    "Your booking of {{.ResourceName}} on {{datetime .StartAt}} - {{time .EndAt}} is cancelled.{{if .RefundAmount}}\nRefund: {{baht .RefundAmount}} THB{{end}}\nBooking ID: {{.BookingID}}",
+		},
+		EVENT_BOOKING_REMINDER: {
+			subject: "Reminder: {{.ResourceName}} on {{datetime .StartAt}}",
+			body:    "This is a reminder of your booking of {{.ResourceName}} on {{datetime .StartAt}} - {{time .EndAt}}.\nBooking ID: {{.BookingID}}",
+		},
+		EVENT_BOOKING_RESCHEDULED: {
+			subject: "Booking rescheduled: {{.ResourceName}}",
+			body:    "Your booking of {{.ResourceName}} is moved to {{datetime .StartAt}} - {{time .EndAt}}.\nBooking ID: {{.BookingID}}",
+		},
+	},
+	LANGUAGE_TH: {
+		EVENT_BOOKING_CONFIRMED: {
+			subject: "ยืนยันการจอง: {{.ResourceName}}",
+			body:    "การจอง {{.ResourceName}} วันที่ {{datetime .StartAt}} - {{time .EndAt}} ได้รับการยืนยันแล้ว\nรหัสการจอง: {{.BookingID}}",
+		},
+		EVENT_BOOKING_CANCELLED: {
+			subject: "ยกเลิกการจอง: {{.ResourceName}}",
+			body:    "การจอง {{.ResourceName}} วันที่ {{datetime .StartAt}} - {{time .EndAt}} ถูกยกเลิกแล้ว{{if .RefundAmount}}\nยอดคืนเงิน: {{baht .RefundAmount}} บาท{{end}}\nรหัสการจอง: {{.BookingID}}",
+		},
+		EVENT_BOOKING_REMINDER: {
+			subject: "แจ้งเตือน: {{.ResourceName}} วันที่ {{datetime .StartAt}}",
+			body:    "แจ้งเตือนการจอง {{.ResourceName}} วันที่ {{datetime .StartAt}} - {{time .EndAt}}\nรหัสการจอง: {{.BookingID}}",
+		},
+		EVENT_BOOKING_RESCHEDULED: {
+			subject: "เลื่อนการจอง: {{.ResourceName}}",
+			body:    "การจอง {{.ResourceName}} ถูกเลื่อนเป็นวันที่ {{datetime .StartAt}} - {{time .EndAt}}\nรหัสการจอง: {{.BookingID}}",
+		},
+	},
+}
+
+// RenderBooking writes the subject and body of the message of the event in
+// the language, English when it has no messages in that language.
+func RenderBooking(event string, language string, data BookingData) (subject string, body string, err error) {
+	templates, ok := messageTemplates[language]
+	if !ok {
+		templates = messageTemplates[LANGUAGE_EN]
+	}
+	message, ok := templates[event]
+	if !ok {
+		return "", "", fmt.Errorf("no message for event %s", event)
+	}
+	subject, err = execute(message.subject, data)
+	if err != nil {
+		return "", "", err
+	}
+	body, err = execute(message.body, data)
+	return subject, body, err
+}
+
+func execute(text string, data BookingData) (string, error) {
+	tmpl, err := template.New("").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	err = tmpl.Execute(&out, data)
+	return out.String(), err
+}
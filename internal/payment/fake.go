@@ -0,0 +1,139 @@
+package payment
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+const FAKE_PROVIDER = "fake"
+
+// ISimulator completes intents of a provider running in-process, returning the
+// signed webhook the provider would deliver.
+type ISimulator interface {
+	Authorize(intentID string) (payload []byte, signature string, err error)
+	Decline(intentID string, reason string) (payload []byte, signature string, err error)
+}
+
+type fakeIntent struct {
+	Intent
+	refunded int64
+}
+
+// fakeProvider keeps intents in memory for development and tests. Customers
+// "pay" through Authorize and Decline instead of a hosted checkout.
+//
+// The intents only exist in the process that created them, so the API must
+// run as a single process without prefork, which the app enforces when the
+// fake provider is configured. They are lost on restart, after which the
+// payments made through them can't be captured or refunded.
+type fakeProvider struct {
+	secret  []byte
+	mutex   sync.Mutex
+	intents map[string]*fakeIntent
+}
+
+func NewFakeProvider(secret string) *fakeProvider {
+	return &fakeProvider{secret: []byte(secret), intents: map[string]*fakeIntent{}}
+}
+
+func (provider *fakeProvider) Name() string {
+	return FAKE_PROVIDER
+}
+
+func (provider *fakeProvider) CreateIntent(request IntentRequest) (intent Intent, err error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	intent = Intent{
+		ID:           "pi_fake_" + uuid.NewString(),
+		Reference:    request.Reference,
+		Amount:       request.Amount,
+		Currency:     request.Currency,
+		Status:       INTENT_STATUS_REQUIRES_PAYMENT,
+		ClientSecret: uuid.NewString(),
+	}
+	provider.intents[intent.ID] = &fakeIntent{Intent: intent}
+	return intent, nil
+}
+
+// Capture captures an authorized intent. Capturing it again does nothing.
+func (provider *fakeProvider) Capture(intentID string) (err error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	intent, ok := provider.intents[intentID]
+	if !ok {
+		return ErrIntentNotFound
+	}
+	switch intent.Status {
+	case INTENT_STATUS_CAPTURED:
+		return nil
+	case INTENT_STATUS_AUTHORIZED:
+		intent.Status = INTENT_STATUS_CAPTURED
+		return nil
+	}
+	return ErrNotAuthorized
+}
+
+func (provider *fakeProvider) Refund(intentID string, amount int64) (err error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	intent, ok := provider.intents[intentID]
+	if !ok {
+		return ErrIntentNotFound
+	}
+	if intent.Status != INTENT_STATUS_CAPTURED {
+		return ErrNotCaptured
+	}
+	if intent.refunded+amount > intent.Amount {
+		return ErrRefundTooLarge
+	}
+	intent.refunded += amount
+	return nil
+}
+
+func (provider *fakeProvider) VerifyWebhook(payload []byte, signature string) (event Event, err error) {
+	if !VerifySignature(provider.secret, payload, signature) {
+		return event, ErrInvalidSignature
+	}
+	err = json.Unmarshal(payload, &event)
+	return event, err
+}
+
+// Authorize completes the intent as the customer would.
+func (provider *fakeProvider) Authorize(intentID string) (payload []byte, signature string, err error) {
+	return provider.complete(intentID, INTENT_STATUS_AUTHORIZED, "")
+}
+
+// Decline fails the intent as the bank of the customer would.
+func (provider *fakeProvider) Decline(intentID string, reason string) (payload []byte, signature string, err error) {
+	return provider.complete(intentID, INTENT_STATUS_FAILED, reason)
+}
+
+func (provider *fakeProvider) complete(intentID string, status string, reason string) (payload []byte, signature string, err error) {
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+
+	intent, ok := provider.intents[intentID]
+	if !ok {
+		return nil, "", ErrIntentNotFound
+	}
+	if intent.Status != INTENT_STATUS_REQUIRES_PAYMENT {
+		return nil, "", ErrIntentCompleted
+	}
+	intent.Status = status
+
+	event := Event{ID: "evt_" + uuid.NewString(), IntentID: intent.ID, Amount: intent.Amount, FailureReason: reason}
+	event.Type = EVENT_PAYMENT_AUTHORIZED
+	if status == INTENT_STATUS_FAILED {
+		event.Type = EVENT_PAYMENT_FAILED
+	}
+	payload, err = json.Marshal(event)
+	if err != nil {
+		return nil, "", err
+	}
+	return payload, Sign(provider.secret, payload), nil
+}
@@ -0,0 +1,56 @@
+package payment_test
+
+import (
+	"booking/internal/payment"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeProvider(t *testing.T) {
+	const Secret = "webhooksecret"
+
+	t.Run("When the payload is tampered with, should reject the webhook", func(t *testing.T) {
+		provider := payment.NewFakeProvider(Secret)
+		intent, _ := provider.CreateIntent(payment.IntentRequest{Reference: "booking", Amount: 50000, Currency: payment.CURRENCY_THB})
+		payload, signature, err := provider.Authorize(intent.ID)
+		assert.NoError(t, err)
+
+		payload[len(payload)-2] = 'x'
+		_, err = provider.VerifyWebhook(payload, signature)
+
+		assert.ErrorIs(t, err, payment.ErrInvalidSignature)
+	})
+
+	t.Run("When the intent is authorized, should deliver a signed event and allow capturing and refunding it", func(t *testing.T) {
+		provider := payment.NewFakeProvider(Secret)
+		intent, _ := provider.CreateIntent(payment.IntentRequest{Reference: "booking", Amount: 50000, Currency: payment.CURRENCY_THB})
+		assert.ErrorIs(t, provider.Capture(intent.ID), payment.ErrNotAuthorized)
+
+		payload, signature, err := provider.Authorize(intent.ID)
+		assert.NoError(t, err)
+		event, err := provider.VerifyWebhook(payload, signature)
+
+		assert.NoError(t, err)
+		assert.Equal(t, payment.EVENT_PAYMENT_AUTHORIZED, event.Type)
+		assert.Equal(t, intent.ID, event.IntentID)
+		assert.NoError(t, provider.Capture(intent.ID))
+		assert.NoError(t, provider.Capture(intent.ID))
+		assert.NoError(t, provider.Refund(intent.ID, 30000))
+		assert.ErrorIs(t, provider.Refund(intent.ID, 30000), payment.ErrRefundTooLarge)
+	})
+
+	t.Run("When the intent is declined, should not complete it again", func(t *testing.T) {
+		provider := payment.NewFakeProvider(Secret)
+		intent, _ := provider.CreateIntent(payment.IntentRequest{Reference: "booking", Amount: 50000, Currency: payment.CURRENCY_THB})
+
+		payload, signature, err := provider.Decline(intent.ID, "insufficient funds")
+		assert.NoError(t, err)
+		event, _ := provider.VerifyWebhook(payload, signature)
+		_, _, err = provider.Authorize(intent.ID)
+
+		assert.Equal(t, payment.EVENT_PAYMENT_FAILED, event.Type)
+		assert.Equal(t, "insufficient funds", event.FailureReason)
+		assert.ErrorIs(t, err, payment.ErrIntentCompleted)
+	})
+}
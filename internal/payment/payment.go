@@ -0,0 +1,107 @@
+package payment
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+)
+
+// SIGNATURE_HEADER carries the HMAC-SHA256 of the webhook payload, hex encoded.
+const SIGNATURE_HEADER = "X-Payment-Signature"
+
+const CURRENCY_THB = "THB"
+
+const (
+	INTENT_STATUS_REQUIRES_PAYMENT = "REQUIRES_PAYMENT"
+	INTENT_STATUS_AUTHORIZED       = "AUTHORIZED"
+	INTENT_STATUS_CAPTURED         = "CAPTURED"
+	INTENT_STATUS_FAILED           = "FAILED"
+)
+
+const (
+	EVENT_PAYMENT_AUTHORIZED = "payment.authorized"
+	// EVENT_PAYMENT_CAPTURED reports money that has moved without being
+	// captured, such as a bank transfer.
+	EVENT_PAYMENT_CAPTURED = "payment.captured"
+	EVENT_PAYMENT_FAILED   = "payment.failed"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrIntentNotFound   = errors.New("payment intent not found")
+	ErrNotAuthorized    = errors.New("payment intent is not authorized")
+	ErrIntentCompleted  = errors.New("payment intent is already completed")
+	ErrNotCaptured      = errors.New("payment intent is not captured")
+	ErrRefundTooLarge   = errors.New("refund exceeds the captured amount")
+	// ErrRefundUnsupported is returned by providers whose payments are
+	// refunded by hand, such as bank transfers.
+	ErrRefundUnsupported = errors.New("the provider cannot refund payments")
+)
+
+// IntentRequest asks the provider to collect an amount in satang. Reference
+// identifies what is paid for, such as a booking ID.
+type IntentRequest struct {
+	Reference string
+	Amount    int64
+	Currency  string
+}
+
+// Intent is a payment the customer completes with the provider. ClientSecret
+// lets the client complete it and is only returned when it is created.
+type Intent struct {
+	ID           string
+	Reference    string
+	Amount       int64
+	Currency     string
+	Status       string
+	ClientSecret string
+	// QRPayload is the payload of the QR code the customer scans to pay with
+	// providers paid by QR code.
+	QRPayload string
+}
+
+// Event is a change of an intent the provider reports to the webhook. ID is
+// unique per event so redelivered events can be ignored.
+type Event struct {
+	ID            string `json:"id"`
+	Type          string `json:"type"`
+	IntentID      string `json:"intentId"`
+	Amount        int64  `json:"amount"`
+	FailureReason string `json:"failureReason,omitempty"`
+}
+
+// IPaymentProvider collects payments. Payments are authorized by the customer
+// and captured once the booking they pay for is confirmed.
+type IPaymentProvider interface {
+	Name() string
+	CreateIntent(request IntentRequest) (intent Intent, err error)
+	Capture(intentID string) (err error)
+	Refund(intentID string, amount int64) (err error)
+	// VerifyWebhook returns the event of the payload when the signature is
+	// valid, or ErrInvalidSignature.
+	VerifyWebhook(payload []byte, signature string) (event Event, err error)
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the payload.
+func Sign(secret []byte, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature reports whether the signature is the HMAC-SHA256 of the
+// payload, in constant time. No signature is valid without a secret, as
+// anyone could sign with an empty key.
+func VerifySignature(secret []byte, payload []byte, signature string) bool {
+	if len(secret) == 0 {
+		return false
+	}
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
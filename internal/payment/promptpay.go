@@ -0,0 +1,92 @@
+package payment
+
+import (
+	"booking/internal/promptpay"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const PROMPTPAY_PROVIDER = "promptpay"
+
+var ErrIncompleteNotification = errors.New("the notification misses the reference or the transaction")
+
+// Notification is a PromptPay transfer reported by the bank or read from a
+// slip. It is posted to the reconciliation endpoint signed like a webhook.
+type Notification struct {
+	Reference string `json:"reference"`
+	// TransactionID is the bank reference of the transfer, which identifies
+	// the notification.
+	TransactionID string `json:"transactionId"`
+	// Amount is in satang.
+	Amount int64     `json:"amount"`
+	PaidAt time.Time `json:"paidAt"`
+}
+
+// promptPayProvider collects payments by PromptPay transfers to the account of
+// the business. Each intent is a QR code carrying the amount and a reference
+// the transfer is reconciled by.
+type promptPayProvider struct {
+	target string
+	secret []byte
+}
+
+// NewPromptPayProvider returns a provider collecting transfers to the
+// PromptPay ID of the target.
+func NewPromptPayProvider(target string, secret string) *promptPayProvider {
+	return &promptPayProvider{target: target, secret: []byte(secret)}
+}
+
+func (provider *promptPayProvider) Name() string {
+	return PROMPTPAY_PROVIDER
+}
+
+func (provider *promptPayProvider) CreateIntent(request IntentRequest) (intent Intent, err error) {
+	reference := strings.ToUpper(strings.ReplaceAll(uuid.NewString(), "-", ""))[:20]
+	payload, err := promptpay.Payload(provider.target, request.Amount, reference)
+	if err != nil {
+		return intent, err
+	}
+	return Intent{
+		ID:        reference,
+		Reference: request.Reference,
+		Amount:    request.Amount,
+		Currency:  request.Currency,
+		Status:    INTENT_STATUS_REQUIRES_PAYMENT,
+		QRPayload: payload,
+	}, nil
+}
+
+// Capture does nothing as transfers are final.
+func (provider *promptPayProvider) Capture(intentID string) (err error) {
+	return nil
+}
+
+func (provider *promptPayProvider) Refund(intentID string, amount int64) (err error) {
+	return ErrRefundUnsupported
+}
+
+// VerifyWebhook verifies a signed notification and returns the capture of the
+// intent of its reference.
+func (provider *promptPayProvider) VerifyWebhook(payload []byte, signature string) (event Event, err error) {
+	if !VerifySignature(provider.secret, payload, signature) {
+		return event, ErrInvalidSignature
+	}
+	notification := Notification{}
+	err = json.Unmarshal(payload, &notification)
+	if err != nil {
+		return event, err
+	}
+	if notification.Reference == "" || notification.TransactionID == "" {
+		return event, ErrIncompleteNotification
+	}
+	return Event{
+		ID:       notification.TransactionID,
+		Type:     EVENT_PAYMENT_CAPTURED,
+		IntentID: strings.ToUpper(notification.Reference),
+		Amount:   notification.Amount,
+	}, nil
+}
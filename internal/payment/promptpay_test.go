@@ -0,0 +1,60 @@
+package payment_test
+
+import (
+	"booking/internal/payment"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromptPayProvider(t *testing.T) {
+	const Secret = "promptpaysecret"
+
+	t.Run("When an intent is created, should return a QR payload of the amount carrying the intent as reference", func(t *testing.T) {
+		provider := payment.NewPromptPayProvider("081-234-5678", Secret)
+
+		intent, err := provider.CreateIntent(payment.IntentRequest{Reference: "booking", Amount: 12550, Currency: payment.CURRENCY_THB})
+
+		assert.NoError(t, err)
+		assert.Len(t, intent.ID, 20)
+		assert.Contains(t, intent.QRPayload, "01130066812345678")
+		assert.Contains(t, intent.QRPayload, "5406125.50")
+		assert.Contains(t, intent.QRPayload, "0520"+intent.ID)
+		assert.ErrorIs(t, provider.Refund(intent.ID, 12550), payment.ErrRefundUnsupported)
+	})
+
+	t.Run("When a signed notification is received, should return the capture of the intent of its reference", func(t *testing.T) {
+		provider := payment.NewPromptPayProvider("0812345678", Secret)
+		intent, _ := provider.CreateIntent(payment.IntentRequest{Reference: "booking", Amount: 12550, Currency: payment.CURRENCY_THB})
+		payload, _ := json.Marshal(payment.Notification{Reference: strings.ToLower(intent.ID), TransactionID: "202610190001", Amount: 12550})
+
+		event, err := provider.VerifyWebhook(payload, payment.Sign([]byte(Secret), payload))
+
+		assert.NoError(t, err)
+		assert.Equal(t, payment.EVENT_PAYMENT_CAPTURED, event.Type)
+		assert.Equal(t, intent.ID, event.IntentID)
+		assert.Equal(t, "202610190001", event.ID)
+		assert.Equal(t, int64(12550), event.Amount)
+	})
+
+	t.Run("When the notification misses the transaction, should reject it", func(t *testing.T) {
+		provider := payment.NewPromptPayProvider("0812345678", Secret)
+		payload, _ := json.Marshal(payment.Notification{Reference: "REF", Amount: 12550})
+
+		_, err := provider.VerifyWebhook(payload, payment.Sign([]byte(Secret), payload))
+
+		assert.ErrorIs(t, err, payment.ErrIncompleteNotification)
+	})
+
+	t.Run("When no secret is configured, should reject the notifications signed without one", func(t *testing.T) {
+		provider := payment.NewPromptPayProvider("0812345678", "")
+		intent, _ := provider.CreateIntent(payment.IntentRequest{Reference: "booking", Amount: 12550, Currency: payment.CURRENCY_THB})
+		payload, _ := json.Marshal(payment.Notification{Reference: intent.ID, TransactionID: "202610190001", Amount: 12550})
+
+		_, err := provider.VerifyWebhook(payload, payment.Sign(nil, payload))
+
+		assert.ErrorIs(t, err, payment.ErrInvalidSignature)
+	})
+}
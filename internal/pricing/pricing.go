@@ -0,0 +1,199 @@
+package pricing
+
+import (
+	"booking/internal/entity"
+	"booking/internal/schedule"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Pricer prices bookings of a resource for a user.
+type Pricer struct {
+	// HourlyRate is the base rate of one seat for an hour in satang.
+	HourlyRate int64
+	Rules      entity.PricingRules
+	// Location is the time zone the times of day and dates of the rules are
+	// read in.
+	Location *time.Location
+	// Member is whether the user is a member of the resource.
+	Member bool
+}
+
+// Validate reports the first rule that cannot be applied.
+func Validate(rules entity.PricingRules) error {
+	for i, rule := range rules {
+		err := validateRule(rule)
+		if err != nil {
+			return fmt.Errorf("rule %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func validateRule(rule entity.PricingRule) error {
+	switch rule.Type {
+	case entity.PRICING_RULE_PEAK_HOURS:
+		for _, weekday := range rule.Weekdays {
+			if weekday < 0 || weekday > 6 {
+				return errors.New("weekdays must be from 0 to 6")
+			}
+		}
+		startMinute, err := schedule.ParseClock(rule.StartTime)
+		if err != nil {
+			return err
+		}
+		endMinute, err := schedule.ParseClock(rule.EndTime)
+		if err != nil {
+			return err
+		}
+		if endMinute <= startMinute {
+			return errors.New("peak hours must end after they start")
+		}
+	case entity.PRICING_RULE_SEASONAL:
+		startDate, err := time.Parse(schedule.DATE_LAYOUT, rule.StartDate)
+		if err != nil {
+			return err
+		}
+		endDate, err := time.Parse(schedule.DATE_LAYOUT, rule.EndDate)
+		if err != nil {
+			return err
+		}
+		if endDate.Before(startDate) {
+			return errors.New("seasons must end on or after they start")
+		}
+	case entity.PRICING_RULE_DURATION_TIER:
+		if rule.MaxMinutes != 0 && rule.MaxMinutes <= rule.MinMinutes {
+			return errors.New("duration tiers must have a max above their min")
+		}
+	case entity.PRICING_RULE_WEEKEND, entity.PRICING_RULE_MEMBER_DISCOUNT:
+	default:
+		return fmt.Errorf("unknown rule type %q", rule.Type)
+	}
+	return nil
+}
+
+// Quote prices seats of the resource from startAt to endAt. Rules matching
+// part of the booking by time adjust the base rate of the minutes they match,
+// rules matching the whole booking adjust the subtotal of the lines before
+// them, so their order matters. Fractions of a satang are rounded down on each
+// line and the total never goes below zero.
+func (pricer *Pricer) Quote(startAt time.Time, endAt time.Time, seats int) entity.Quote {
+	minutes := int(endAt.Sub(startAt) / time.Minute)
+	quote := entity.Quote{
+		HourlyRate: pricer.HourlyRate,
+		Minutes:    minutes,
+		Seats:      seats,
+		Member:     pricer.Member,
+		Rules:      pricer.Rules,
+		Lines: []entity.QuoteLine{{
+			Name:    "Base rate",
+			Type:    entity.QUOTE_LINE_BASE_RATE,
+			Minutes: minutes,
+			Amount:  pricer.minutesAmount(minutes, seats),
+		}},
+	}
+	if quote.Rules == nil {
+		quote.Rules = entity.PricingRules{}
+	}
+
+	total := quote.Lines[0].Amount
+	for _, rule := range pricer.Rules {
+		line := entity.QuoteLine{Name: rule.Name, Type: rule.Type, Percent: rule.Percent}
+		switch rule.Type {
+		case entity.PRICING_RULE_PEAK_HOURS, entity.PRICING_RULE_WEEKEND, entity.PRICING_RULE_SEASONAL:
+			line.Minutes = pricer.matchedMinutes(rule, startAt, endAt)
+			if line.Minutes == 0 {
+				continue
+			}
+			line.Amount = pricer.minutesAmount(line.Minutes, seats) * int64(rule.Percent) / 100
+		case entity.PRICING_RULE_DURATION_TIER:
+			if minutes < rule.MinMinutes || (rule.MaxMinutes != 0 && minutes >= rule.MaxMinutes) {
+				continue
+			}
+			line.Amount = total * int64(rule.Percent) / 100
+		case entity.PRICING_RULE_MEMBER_DISCOUNT:
+			if !pricer.Member {
+				continue
+			}
+			line.Amount = total * int64(rule.Percent) / 100
+		default:
+			continue
+		}
+		total += line.Amount
+		quote.Lines = append(quote.Lines, line)
+	}
+
+	quote.Total = max(total, 0)
+	return quote
+}
+
+func (pricer *Pricer) minutesAmount(minutes int, seats int) int64 {
+	return pricer.HourlyRate * int64(minutes) * int64(seats) / 60
+}
+
+// matchedMinutes returns how many minutes from startAt to endAt the rule
+// applies to.
+func (pricer *Pricer) matchedMinutes(rule entity.PricingRule, startAt time.Time, endAt time.Time) int {
+	local := startAt.In(pricer.Location)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, pricer.Location)
+	matched := time.Duration(0)
+	for day.Before(endAt) {
+		nextDay := time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, pricer.Location)
+		windowStart, windowEnd, ok := ruleWindow(rule, day, nextDay)
+		if ok {
+			windowStart = latest(windowStart, startAt)
+			windowEnd = earliest(windowEnd, endAt)
+			if windowEnd.After(windowStart) {
+				matched += windowEnd.Sub(windowStart)
+			}
+		}
+		day = nextDay
+	}
+	return int(matched / time.Minute)
+}
+
+// ruleWindow returns when on the local day the rule applies.
+func ruleWindow(rule entity.PricingRule, day time.Time, nextDay time.Time) (startAt time.Time, endAt time.Time, ok bool) {
+	switch rule.Type {
+	case entity.PRICING_RULE_PEAK_HOURS:
+		if len(rule.Weekdays) > 0 && !containsWeekday(rule.Weekdays, day.Weekday()) {
+			return day, day, false
+		}
+		startMinute, _ := schedule.ParseClock(rule.StartTime)
+		endMinute, _ := schedule.ParseClock(rule.EndTime)
+		return time.Date(day.Year(), day.Month(), day.Day(), 0, startMinute, 0, 0, day.Location()),
+			time.Date(day.Year(), day.Month(), day.Day(), 0, endMinute, 0, 0, day.Location()),
+			true
+	case entity.PRICING_RULE_WEEKEND:
+		weekday := day.Weekday()
+		return day, nextDay, weekday == time.Saturday || weekday == time.Sunday
+	case entity.PRICING_RULE_SEASONAL:
+		date := day.Format(schedule.DATE_LAYOUT)
+		return day, nextDay, date >= rule.StartDate && date <= rule.EndDate
+	}
+	return day, day, false
+}
+
+func containsWeekday(weekdays []int, weekday time.Weekday) bool {
+	for _, w := range weekdays {
+		if time.Weekday(w) == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+func latest(a time.Time, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func earliest(a time.Time, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,119 @@
+package pricing_test
+
+import (
+	"booking/internal/entity"
+	"booking/internal/pricing"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuote(t *testing.T) {
+	bangkok, _ := time.LoadLocation("Asia/Bangkok")
+	// 2026-10-23 is a Friday
+	friday := time.Date(2026, 10, 23, 0, 0, 0, 0, bangkok)
+	at := func(day int, hour int) time.Time {
+		return friday.AddDate(0, 0, day).Add(time.Duration(hour) * time.Hour)
+	}
+	peakHours := entity.PricingRule{Name: "Evening peak", Type: entity.PRICING_RULE_PEAK_HOURS, Percent: 50, Weekdays: []int{1, 2, 3, 4, 5}, StartTime: "18:00", EndTime: "21:00"}
+	weekend := entity.PricingRule{Name: "Weekend", Type: entity.PRICING_RULE_WEEKEND, Percent: 20}
+	longBooking := entity.PricingRule{Name: "2 hours or more", Type: entity.PRICING_RULE_DURATION_TIER, Percent: -10, MinMinutes: 120}
+	member := entity.PricingRule{Name: "Member", Type: entity.PRICING_RULE_MEMBER_DISCOUNT, Percent: -20}
+
+	t.Run("When no rule matches, should charge the base rate", func(t *testing.T) {
+		pricer := &pricing.Pricer{HourlyRate: 60000, Rules: entity.PricingRules{peakHours, weekend}, Location: bangkok}
+
+		quote := pricer.Quote(at(0, 9), at(0, 10).Add(30*time.Minute), 2)
+
+		assert.Equal(t, int64(180000), quote.Total)
+		assert.Len(t, quote.Lines, 1)
+		assert.Equal(t, entity.QUOTE_LINE_BASE_RATE, quote.Lines[0].Type)
+	})
+
+	t.Run("When the booking runs into peak hours, should surcharge only the peak minutes", func(t *testing.T) {
+		pricer := &pricing.Pricer{HourlyRate: 60000, Rules: entity.PricingRules{peakHours}, Location: bangkok}
+
+		quote := pricer.Quote(at(0, 17), at(0, 19), 1)
+
+		assert.Equal(t, 60, quote.Lines[1].Minutes)
+		assert.Equal(t, int64(30000), quote.Lines[1].Amount)
+		assert.Equal(t, int64(150000), quote.Total)
+	})
+
+	t.Run("When the booking runs past midnight into the weekend, should surcharge the weekend minutes", func(t *testing.T) {
+		pricer := &pricing.Pricer{HourlyRate: 60000, Rules: entity.PricingRules{peakHours, weekend}, Location: bangkok}
+
+		quote := pricer.Quote(at(0, 23), at(1, 2), 1)
+
+		assert.Len(t, quote.Lines, 2)
+		assert.Equal(t, 120, quote.Lines[1].Minutes)
+		assert.Equal(t, int64(24000), quote.Lines[1].Amount)
+		assert.Equal(t, int64(204000), quote.Total)
+	})
+
+	t.Run("When the booking is in season, should apply the seasonal rate", func(t *testing.T) {
+		season := entity.PricingRule{Name: "High season", Type: entity.PRICING_RULE_SEASONAL, Percent: 30, StartDate: "2026-10-01", EndDate: "2026-10-23"}
+		pricer := &pricing.Pricer{HourlyRate: 60000, Rules: entity.PricingRules{season}, Location: bangkok}
+
+		quote := pricer.Quote(at(0, 22), at(1, 1), 1)
+
+		assert.Equal(t, 120, quote.Lines[1].Minutes)
+		assert.Equal(t, int64(36000), quote.Lines[1].Amount)
+	})
+
+	t.Run("When rules adjust the subtotal, should apply them in order", func(t *testing.T) {
+		pricer := &pricing.Pricer{HourlyRate: 60000, Rules: entity.PricingRules{peakHours, longBooking, member}, Location: bangkok, Member: true}
+
+		quote := pricer.Quote(at(0, 17), at(0, 19), 1)
+
+		amounts := []int64{}
+		for _, line := range quote.Lines {
+			amounts = append(amounts, line.Amount)
+		}
+		assert.Equal(t, []int64{120000, 30000, -15000, -27000}, amounts)
+		assert.Equal(t, int64(108000), quote.Total)
+	})
+
+	t.Run("When the user is not a member or the booking is too short, should skip those rules", func(t *testing.T) {
+		pricer := &pricing.Pricer{HourlyRate: 60000, Rules: entity.PricingRules{longBooking, member}, Location: bangkok}
+
+		quote := pricer.Quote(at(0, 9), at(0, 10), 1)
+
+		assert.Len(t, quote.Lines, 1)
+		assert.Equal(t, int64(60000), quote.Total)
+	})
+
+	t.Run("When discounts exceed the price, should not go below zero", func(t *testing.T) {
+		free := entity.PricingRule{Name: "Free", Type: entity.PRICING_RULE_MEMBER_DISCOUNT, Percent: -100}
+		pricer := &pricing.Pricer{HourlyRate: 60000, Rules: entity.PricingRules{free, free}, Location: bangkok, Member: true}
+
+		quote := pricer.Quote(at(0, 9), at(0, 10), 1)
+
+		assert.Equal(t, int64(0), quote.Total)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("When the rules are valid, should not return an error", func(t *testing.T) {
+		err := pricing.Validate(entity.PricingRules{
+			{Type: entity.PRICING_RULE_PEAK_HOURS, StartTime: "18:00", EndTime: "24:00"},
+			{Type: entity.PRICING_RULE_SEASONAL, StartDate: "2026-12-20", EndDate: "2027-01-05"},
+			{Type: entity.PRICING_RULE_DURATION_TIER, MinMinutes: 60, MaxMinutes: 180},
+		})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("When peak hours end before they start, should return an error", func(t *testing.T) {
+		err := pricing.Validate(entity.PricingRules{{Type: entity.PRICING_RULE_PEAK_HOURS, StartTime: "18:00", EndTime: "09:00"}})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("When the rule type is unknown, should return an error", func(t *testing.T) {
+		err := pricing.Validate(entity.PricingRules{{Type: "HAPPY_HOUR"}})
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,108 @@
+package promptpay
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// AID_CREDIT_TRANSFER identifies PromptPay credit transfers in the merchant
+// account information of the payload.
+const AID_CREDIT_TRANSFER = "A000000677010111"
+
+const (
+	CURRENCY_THB = "764"
+	COUNTRY_TH   = "TH"
+)
+
+var (
+	ErrInvalidTarget    = errors.New("the PromptPay ID must be a phone number, a national ID, a tax ID or an e-wallet ID")
+	ErrInvalidReference = errors.New("the reference must be at most 25 letters and digits")
+)
+
+var (
+	nonDigits = regexp.MustCompile(`\D`)
+	reference = regexp.MustCompile(`^[A-Za-z0-9]{0,25}$`)
+)
+
+// field formats an EMVCo data object: its ID, the length of its value in two
+// digits and the value.
+func field(id string, value string) string {
+	return fmt.Sprintf("%s%02d%s", id, len(value), value)
+}
+
+// Payload returns the EMVCo QR payload transferring the amount in satang to
+// the PromptPay ID of the target. The QR is static and the payer enters the
+// amount when the amount is 0. The reference, if any, is carried as the
+// reference label of the additional data.
+func Payload(target string, amount int64, ref string) (string, error) {
+	account, err := accountField(target)
+	if err != nil {
+		return "", err
+	}
+	if !reference.MatchString(ref) {
+		return "", ErrInvalidReference
+	}
+
+	var builder strings.Builder
+	builder.WriteString(field("00", "01"))
+	if amount > 0 {
+		builder.WriteString(field("01", "12"))
+	} else {
+		builder.WriteString(field("01", "11"))
+	}
+	builder.WriteString(field("29", field("00", AID_CREDIT_TRANSFER)+account))
+	builder.WriteString(field("58", COUNTRY_TH))
+	builder.WriteString(field("53", CURRENCY_THB))
+	if amount > 0 {
+		builder.WriteString(field("54", fmt.Sprintf("%d.%02d", amount/100, amount%100)))
+	}
+	if ref != "" {
+		builder.WriteString(field("62", field("05", ref)))
+	}
+	// the checksum covers its own ID and length
+	builder.WriteString("6304")
+	payload := builder.String()
+	return payload + fmt.Sprintf("%04X", CRC16(payload)), nil
+}
+
+// accountField returns the PromptPay account of the target, which may be
+// formatted with spaces or dashes.
+func accountField(target string) (string, error) {
+	digits := nonDigits.ReplaceAllString(target, "")
+	switch {
+	case len(digits) == 10 && digits[0] == '0':
+		return field("01", "0066"+digits[1:]), nil
+	case len(digits) == 11 && strings.HasPrefix(digits, "66"):
+		return field("01", "00"+digits), nil
+	case len(digits) == 13:
+		return field("02", digits), nil
+	case len(digits) == 15:
+		return field("03", digits), nil
+	}
+	return "", ErrInvalidTarget
+}
+
+// CRC16 is the CRC-16/CCITT-FALSE checksum EMVCo payloads end with.
+func CRC16(data string) uint16 {
+	crc := uint16(0xFFFF)
+	for i := 0; i < len(data); i++ {
+		crc ^= uint16(data[i]) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// QRCode renders the payload as a PNG QR code of size pixels square.
+func QRCode(payload string, size int) ([]byte, error) {
+	return qrcode.Encode(payload, qrcode.Medium, size)
+}
@@ -0,0 +1,59 @@
+package promptpay_test
+
+import (
+	"booking/internal/promptpay"
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPayload(t *testing.T) {
+	t.Run("When no amount is given, should return a static payload", func(t *testing.T) {
+		payload, err := promptpay.Payload("000-000-0000", 0, "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "00020101021129370016A000000677010111011300660000000005802TH530376463048956", payload)
+	})
+
+	t.Run("When an amount is given, should return a dynamic payload with the amount in baht", func(t *testing.T) {
+		payload, err := promptpay.Payload("000-000-0000", 422, "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "00020101021229370016A000000677010111011300660000000005802TH530376454044.226304E469", payload)
+	})
+
+	t.Run("When a reference is given, should carry it as the reference label", func(t *testing.T) {
+		payload, err := promptpay.Payload("1234567890123", 150000, "BK123")
+
+		assert.NoError(t, err)
+		assert.Contains(t, payload, "02131234567890123")
+		assert.Contains(t, payload, "54071500.00")
+		assert.Contains(t, payload, "62090505BK123")
+		assert.Equal(t, fmt.Sprintf("%04X", promptpay.CRC16(payload[:len(payload)-4])), payload[len(payload)-4:])
+	})
+
+	t.Run("When the target is not a PromptPay ID, should return an error", func(t *testing.T) {
+		_, err := promptpay.Payload("12345", 100, "")
+
+		assert.ErrorIs(t, err, promptpay.ErrInvalidTarget)
+	})
+
+	t.Run("When the reference is too long, should return an error", func(t *testing.T) {
+		_, err := promptpay.Payload("0812345678", 100, "ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+
+		assert.ErrorIs(t, err, promptpay.ErrInvalidReference)
+	})
+}
+
+func TestCRC16(t *testing.T) {
+	assert.Equal(t, uint16(0x29B1), promptpay.CRC16("123456789"))
+}
+
+func TestQRCode(t *testing.T) {
+	png, err := promptpay.QRCode("00020101021129370016A000000677010111011300660000000005802TH530376463048956", 256)
+
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(png, []byte("\x89PNG")))
+}
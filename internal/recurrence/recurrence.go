@@ -0,0 +1,112 @@
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/teambition/rrule-go"
+)
+
+var ErrTooManyOccurrences = errors.New("the recurrence rule has too many occurrences")
+
+// Parse parses an RFC 5545 RRULE value, with or without the "RRULE:" prefix.
+// UNTIL values without a time zone are read in the location of dtstart.
+func Parse(rule string, dtstart time.Time) (*rrule.RRule, error) {
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	if strings.ContainsAny(rule, "\r\n") {
+		return nil, fmt.Errorf("the recurrence rule must be a single RRULE value")
+	}
+	option, err := rrule.StrToROptionInLocation(rule, dtstart.Location())
+	if err != nil {
+		return nil, err
+	}
+	option.Dtstart = dtstart
+	return rrule.NewRRule(*option)
+}
+
+// Expand returns the start of every occurrence of the rule that begins before
+// horizon, skipping the ones listed in exdates. It fails with
+// ErrTooManyOccurrences instead of returning more than limit occurrences.
+func Expand(rule string, dtstart time.Time, exdates []time.Time, horizon time.Time, limit int) ([]time.Time, error) {
+	r, err := Parse(rule, dtstart)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrences := []time.Time{}
+	next := r.Iterator()
+	for occurrence, ok := next(); ok && occurrence.Before(horizon); occurrence, ok = next() {
+		if containsTime(exdates, occurrence) {
+			continue
+		}
+		if len(occurrences) == limit {
+			return nil, ErrTooManyOccurrences
+		}
+		occurrences = append(occurrences, occurrence)
+	}
+	return occurrences, nil
+}
+
+// CountBefore returns the number of occurrences of the rule that start before
+// the given time. Like the COUNT part of a rule, it includes excluded dates.
+func CountBefore(rule string, dtstart time.Time, before time.Time) (int, error) {
+	r, err := Parse(rule, dtstart)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	next := r.Iterator()
+	for occurrence, ok := next(); ok && occurrence.Before(before); occurrence, ok = next() {
+		count++
+	}
+	return count, nil
+}
+
+// EndBefore returns the rule cut so that its last occurrence starts before the
+// given time. It is used to end a series where "this and following" occurrences
+// are split off.
+func EndBefore(rule string, dtstart time.Time, before time.Time) (string, error) {
+	r, err := Parse(rule, dtstart)
+	if err != nil {
+		return "", err
+	}
+	option := r.OrigOptions
+	option.Count = 0
+	option.Until = before.Add(-time.Second).UTC()
+	return option.RRuleString(), nil
+}
+
+// WithCount returns the rule limited to count occurrences. A count of zero
+// keeps the rule unbounded by count.
+func WithCount(rule string, dtstart time.Time, count int) (string, error) {
+	r, err := Parse(rule, dtstart)
+	if err != nil {
+		return "", err
+	}
+	option := r.OrigOptions
+	option.Count = count
+	return option.RRuleString(), nil
+}
+
+// RepeatsWithinDay reports whether the rule can start more than one occurrence
+// on the same day, which happens with sub-daily frequencies or BYHOUR, BYMINUTE
+// and BYSECOND parts.
+func RepeatsWithinDay(r *rrule.RRule) bool {
+	option := r.OrigOptions
+	return option.Freq > rrule.DAILY ||
+		len(option.Byhour) > 0 ||
+		len(option.Byminute) > 0 ||
+		len(option.Bysecond) > 0
+}
+
+func containsTime(times []time.Time, t time.Time) bool {
+	for _, value := range times {
+		if value.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package recurrence_test
+
+import (
+	"booking/internal/recurrence"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpand(t *testing.T) {
+	bangkok, _ := time.LoadLocation("Asia/Bangkok")
+	// Tuesday
+	dtstart := time.Date(2024, 5, 7, 10, 0, 0, 0, bangkok)
+	horizon := dtstart.AddDate(1, 0, 0)
+
+	t.Run("Weekly rule with count, should return every occurrence at the same wall clock time", func(t *testing.T) {
+		occurrences, err := recurrence.Expand("FREQ=WEEKLY;BYDAY=TU;COUNT=4", dtstart, nil, horizon, 100)
+		assert.Nil(t, err)
+		assert.Equal(t, []time.Time{
+			dtstart,
+			dtstart.AddDate(0, 0, 7),
+			dtstart.AddDate(0, 0, 14),
+			dtstart.AddDate(0, 0, 21),
+		}, occurrences)
+	})
+
+	t.Run("Rule with RRULE prefix and exdates, should skip the excluded occurrences", func(t *testing.T) {
+		exdates := []time.Time{dtstart.AddDate(0, 0, 7).UTC()}
+		occurrences, err := recurrence.Expand("RRULE:FREQ=WEEKLY;COUNT=3", dtstart, exdates, horizon, 100)
+		assert.Nil(t, err)
+		assert.Equal(t, []time.Time{dtstart, dtstart.AddDate(0, 0, 14)}, occurrences)
+	})
+
+	t.Run("Unbounded rule, should stop at the horizon", func(t *testing.T) {
+		occurrences, err := recurrence.Expand("FREQ=DAILY", dtstart, nil, dtstart.AddDate(0, 0, 10), 100)
+		assert.Nil(t, err)
+		assert.Len(t, occurrences, 10)
+	})
+
+	t.Run("Rule with more occurrences than the limit, should return an error", func(t *testing.T) {
+		_, err := recurrence.Expand("FREQ=DAILY", dtstart, nil, horizon, 30)
+		assert.ErrorIs(t, err, recurrence.ErrTooManyOccurrences)
+	})
+
+	t.Run("Invalid rule, should return an error", func(t *testing.T) {
+		_, err := recurrence.Expand("FREQ=SOMETIMES", dtstart, nil, horizon, 100)
+		assert.NotNil(t, err)
+		_, err = recurrence.Expand("FREQ=DAILY\nRRULE:FREQ=WEEKLY", dtstart, nil, horizon, 100)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestCountBefore(t *testing.T) {
+	dtstart := time.Date(2024, 5, 7, 10, 0, 0, 0, time.UTC)
+
+	count, err := recurrence.CountBefore("FREQ=WEEKLY;COUNT=10", dtstart, dtstart.AddDate(0, 0, 21))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, count)
+
+	count, err = recurrence.CountBefore("FREQ=WEEKLY;COUNT=2", dtstart, dtstart.AddDate(0, 0, 21))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestEndBefore(t *testing.T) {
+	dtstart := time.Date(2024, 5, 7, 10, 0, 0, 0, time.UTC)
+
+	rule, err := recurrence.EndBefore("FREQ=WEEKLY;COUNT=10", dtstart, dtstart.AddDate(0, 0, 21))
+	assert.Nil(t, err)
+
+	occurrences, err := recurrence.Expand(rule, dtstart, nil, dtstart.AddDate(1, 0, 0), 100)
+	assert.Nil(t, err)
+	assert.Len(t, occurrences, 3)
+}
+
+func TestWithCount(t *testing.T) {
+	dtstart := time.Date(2024, 5, 7, 10, 0, 0, 0, time.UTC)
+
+	rule, err := recurrence.WithCount("FREQ=WEEKLY;COUNT=10", dtstart, 4)
+	assert.Nil(t, err)
+	assert.Equal(t, "FREQ=WEEKLY;COUNT=4", rule)
+}
+
+func TestRepeatsWithinDay(t *testing.T) {
+	dtstart := time.Date(2024, 5, 7, 10, 0, 0, 0, time.UTC)
+	testCases := map[string]bool{
+		"FREQ=WEEKLY;BYDAY=TU,TH":   false,
+		"FREQ=DAILY;INTERVAL=2":     false,
+		"FREQ=HOURLY":               true,
+		"FREQ=DAILY;BYHOUR=9,13":    true,
+		"FREQ=WEEKLY;BYMINUTE=0,30": true,
+	}
+
+	for rule, expected := range testCases {
+		t.Run(rule, func(t *testing.T) {
+			r, err := recurrence.Parse(rule, dtstart)
+			assert.Nil(t, err)
+			assert.Equal(t, expected, recurrence.RepeatsWithinDay(r))
+		})
+	}
+}
@@ -3,11 +3,13 @@ package repository
 import (
 	"booking/internal/entity"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type IAuthRepository interface {
 	FindUserByUsername(username string) (user *entity.User, err error)
+	FindUserByID(id uuid.UUID) (user *entity.User, err error)
 	CreateAccount(user *entity.User) (err error)
 }
 
@@ -20,10 +22,17 @@ func NewAuthRepository(db *gorm.DB) *authRepository {
 }
 
 func (repo *authRepository) FindUserByUsername(username string) (user *entity.User, err error) {
+	user = &entity.User{}
 	tx := repo.db.First(user, "username = ?", username)
 	return user, tx.Error
 }
 
+func (repo *authRepository) FindUserByID(id uuid.UUID) (user *entity.User, err error) {
+	user = &entity.User{}
+	tx := repo.db.First(user, "id = ?", id)
+	return user, tx.Error
+}
+
 func (repo *authRepository) CreateAccount(user *entity.User) (err error) {
 	tx := repo.db.Save(user)
 	return tx.Error
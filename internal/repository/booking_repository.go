@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"booking/internal/entity"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IBookingRepository interface {
+	// WithContext returns the repository with its statements scoped to the
+	// organization of the context.
+	WithContext(ctx context.Context) IBookingRepository
+	Transaction(fc func(tx *gorm.DB) error) (err error)
+	WithTx(tx *gorm.DB) IBookingRepository
+	LockResourceByID(id uuid.UUID) (resource *entity.Resource, err error)
+	CreateBooking(booking *entity.Booking) (err error)
+	FindBookingByID(id uuid.UUID) (booking *entity.Booking, err error)
+	LockBookingByID(id uuid.UUID) (booking *entity.Booking, err error)
+	UpdateBooking(booking *entity.Booking) (err error)
+	FindOverlappingBookings(resourceID uuid.UUID, startAt time.Time, endAt time.Time) (bookings []entity.Booking, err error)
+	// FindProviderBookings returns the pending and confirmed appointments of
+	// the providers overlapping the period, in any resource.
+	FindProviderBookings(providerIDs []uuid.UUID, startAt time.Time, endAt time.Time) (bookings []entity.Booking, err error)
+	FindOverdueBookings(now time.Time, limit int) (bookings []entity.Booking, err error)
+	// FindStaleHolds returns pending bookings created before the given time,
+	// the oldest first.
+	FindStaleHolds(createdBefore time.Time, limit int) (bookings []entity.Booking, err error)
+	// FindMissedCheckIns returns confirmed bookings not checked in that started
+	// in the period, the earliest first.
+	FindMissedCheckIns(startedAfter time.Time, startedBefore time.Time, limit int) (bookings []entity.Booking, err error)
+	// FindNoShows returns the no-shows of the user starting since the given
+	// time, the latest first.
+	FindNoShows(userID uuid.UUID, since time.Time) (bookings []entity.Booking, err error)
+	// ChargeNoShowFees records that the fees of the no-shows were charged on
+	// the booking, leaving out no-shows already charged on another one.
+	ChargeNoShowFees(ids []uuid.UUID, bookingID uuid.UUID) (charged int64, err error)
+	// ReleaseNoShowFees lets the no-shows charged on the booking, which won't
+	// go ahead, be charged again.
+	ReleaseNoShowFees(bookingID uuid.UUID) (err error)
+	// RecordBookingFailure records that the background handling of the
+	// booking failed. The batches of bookings to handle take failed ones
+	// after the others, the longest failed first.
+	RecordBookingFailure(id uuid.UUID, lastError string, failedAt time.Time) (err error)
+	// FindBookingsByUserID returns the bookings of the user ending after the
+	// given time, cancelled ones included.
+	FindBookingsByUserID(userID uuid.UUID, endAfter time.Time) (bookings []entity.Booking, err error)
+	CreateBookingSeries(series *entity.BookingSeries) (err error)
+	FindBookingSeriesByID(id uuid.UUID) (series *entity.BookingSeries, err error)
+	LockBookingSeriesByID(id uuid.UUID) (series *entity.BookingSeries, err error)
+	UpdateBookingSeries(series *entity.BookingSeries) (err error)
+	FindBookingsBySeriesID(seriesID uuid.UUID) (bookings []entity.Booking, err error)
+	CreateBookingChange(change *entity.BookingChange) (err error)
+	// FindBookingChanges returns the history of the booking, the oldest
+	// change first.
+	FindBookingChanges(bookingID uuid.UUID) (changes []entity.BookingChange, err error)
+}
+
+type bookingRepository struct {
+	db *gorm.DB
+}
+
+func NewBookingRepository(db *gorm.DB) *bookingRepository {
+	return &bookingRepository{db: db}
+}
+
+func (repo *bookingRepository) WithContext(ctx context.Context) IBookingRepository {
+	return &bookingRepository{db: repo.db.WithContext(ctx)}
+}
+
+func (repo *bookingRepository) Transaction(fc func(tx *gorm.DB) error) (err error) {
+	return repo.db.Transaction(fc)
+}
+
+func (repo *bookingRepository) WithTx(tx *gorm.DB) IBookingRepository {
+	return &bookingRepository{db: tx}
+}
+
+// LockResourceByID loads the resource with a row lock so that concurrent
+// bookings of the same resource are serialized until the transaction ends.
+func (repo *bookingRepository) LockResourceByID(id uuid.UUID) (resource *entity.Resource, err error) {
+	resource = &entity.Resource{}
+	tx := repo.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(resource, "id = ?", id)
+	return resource, tx.Error
+}
+
+func (repo *bookingRepository) FindOverlappingBookings(resourceID uuid.UUID, startAt time.Time, endAt time.Time) (bookings []entity.Booking, err error) {
+	tx := repo.db.
+		Where("resource_id = ?", resourceID).
+		Where("status IN ?", []string{entity.BOOKING_STATUS_PENDING, entity.BOOKING_STATUS_CONFIRMED}).
+		Where("start_at < ? AND end_at > ?", endAt, startAt).
+		Find(&bookings)
+	return bookings, tx.Error
+}
+
+func (repo *bookingRepository) FindProviderBookings(providerIDs []uuid.UUID, startAt time.Time, endAt time.Time) (bookings []entity.Booking, err error) {
+	tx := repo.db.
+		Where("provider_id IN ?", providerIDs).
+		Where("status IN ?", []string{entity.BOOKING_STATUS_PENDING, entity.BOOKING_STATUS_CONFIRMED}).
+		Where("start_at < ? AND end_at > ?", endAt, startAt).
+		Order("start_at").
+		Find(&bookings)
+	return bookings, tx.Error
+}
+
+// FindOverdueBookings returns confirmed bookings with a due not paid by now
+// that have not been flagged yet, the longest overdue first.
+func (repo *bookingRepository) FindOverdueBookings(now time.Time, limit int) (bookings []entity.Booking, err error) {
+	tx := repo.db.
+		Where("status = ? AND next_due_at <= ? AND overdue_at IS NULL", entity.BOOKING_STATUS_CONFIRMED, now).
+		Order("failed_at NULLS FIRST, next_due_at").
+		Limit(limit).
+		Find(&bookings)
+	return bookings, tx.Error
+}
+
+func (repo *bookingRepository) FindMissedCheckIns(startedAfter time.Time, startedBefore time.Time, limit int) (bookings []entity.Booking, err error) {
+	tx := repo.db.
+		Where("status = ? AND checked_in_at IS NULL", entity.BOOKING_STATUS_CONFIRMED).
+		Where("start_at > ? AND start_at <= ?", startedAfter, startedBefore).
+		Order("failed_at NULLS FIRST, start_at").
+		Limit(limit).
+		Find(&bookings)
+	return bookings, tx.Error
+}
+
+func (repo *bookingRepository) FindNoShows(userID uuid.UUID, since time.Time) (bookings []entity.Booking, err error) {
+	tx := repo.db.
+		Where("user_id = ? AND status = ? AND start_at >= ?", userID, entity.BOOKING_STATUS_NO_SHOW, since).
+		Order("start_at DESC").
+		Find(&bookings)
+	return bookings, tx.Error
+}
+
+func (repo *bookingRepository) ChargeNoShowFees(ids []uuid.UUID, bookingID uuid.UUID) (charged int64, err error) {
+	tx := repo.db.Model(&entity.Booking{}).
+		Where("id IN ? AND no_show_fee_booking_id IS NULL", ids).
+		UpdateColumn("no_show_fee_booking_id", bookingID)
+	return tx.RowsAffected, tx.Error
+}
+
+func (repo *bookingRepository) ReleaseNoShowFees(bookingID uuid.UUID) (err error) {
+	tx := repo.db.Model(&entity.Booking{}).
+		Where("no_show_fee_booking_id = ?", bookingID).
+		UpdateColumn("no_show_fee_booking_id", nil)
+	return tx.Error
+}
+
+func (repo *bookingRepository) FindStaleHolds(createdBefore time.Time, limit int) (bookings []entity.Booking, err error) {
+	tx := repo.db.
+		Where("status = ? AND created_at <= ?", entity.BOOKING_STATUS_PENDING, createdBefore).
+		Order("failed_at NULLS FIRST, created_at").
+		Limit(limit).
+		Find(&bookings)
+	return bookings, tx.Error
+}
+
+func (repo *bookingRepository) RecordBookingFailure(id uuid.UUID, lastError string, failedAt time.Time) (err error) {
+	tx := repo.db.Model(&entity.Booking{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]any{"last_error": lastError, "failed_at": failedAt})
+	return tx.Error
+}
+
+func (repo *bookingRepository) FindBookingsByUserID(userID uuid.UUID, endAfter time.Time) (bookings []entity.Booking, err error) {
+	tx := repo.db.Where("user_id = ? AND end_at > ?", userID, endAfter).Order("start_at").Find(&bookings)
+	return bookings, tx.Error
+}
+
+func (repo *bookingRepository) CreateBooking(booking *entity.Booking) (err error) {
+	tx := repo.db.Create(booking)
+	return tx.Error
+}
+
+func (repo *bookingRepository) FindBookingByID(id uuid.UUID) (booking *entity.Booking, err error) {
+	booking = &entity.Booking{}
+	tx := repo.db.First(booking, "id = ?", id)
+	return booking, tx.Error
+}
+
+func (repo *bookingRepository) LockBookingByID(id uuid.UUID) (booking *entity.Booking, err error) {
+	booking = &entity.Booking{}
+	tx := repo.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(booking, "id = ?", id)
+	return booking, tx.Error
+}
+
+func (repo *bookingRepository) UpdateBooking(booking *entity.Booking) (err error) {
+	tx := repo.db.Save(booking)
+	return tx.Error
+}
+
+func (repo *bookingRepository) CreateBookingSeries(series *entity.BookingSeries) (err error) {
+	tx := repo.db.Create(series)
+	return tx.Error
+}
+
+func (repo *bookingRepository) FindBookingSeriesByID(id uuid.UUID) (series *entity.BookingSeries, err error) {
+	series = &entity.BookingSeries{}
+	tx := repo.db.First(series, "id = ?", id)
+	return series, tx.Error
+}
+
+func (repo *bookingRepository) LockBookingSeriesByID(id uuid.UUID) (series *entity.BookingSeries, err error) {
+	series = &entity.BookingSeries{}
+	tx := repo.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(series, "id = ?", id)
+	return series, tx.Error
+}
+
+func (repo *bookingRepository) UpdateBookingSeries(series *entity.BookingSeries) (err error) {
+	tx := repo.db.Save(series)
+	return tx.Error
+}
+
+func (repo *bookingRepository) FindBookingsBySeriesID(seriesID uuid.UUID) (bookings []entity.Booking, err error) {
+	tx := repo.db.Where("series_id = ?", seriesID).Order("start_at").Find(&bookings)
+	return bookings, tx.Error
+}
+
+func (repo *bookingRepository) CreateBookingChange(change *entity.BookingChange) (err error) {
+	tx := repo.db.Create(change)
+	return tx.Error
+}
+
+func (repo *bookingRepository) FindBookingChanges(bookingID uuid.UUID) (changes []entity.BookingChange, err error) {
+	tx := repo.db.Where("booking_id = ?", bookingID).Order("created_at").Find(&changes)
+	return changes, tx.Error
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"booking/internal/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ICalendarRepository interface {
+	FindCalendarFeedByUserID(userID uuid.UUID) (feed *entity.CalendarFeed, err error)
+	FindCalendarFeedByToken(token string) (feed *entity.CalendarFeed, err error)
+	SaveCalendarFeed(feed *entity.CalendarFeed) (err error)
+}
+
+type calendarRepository struct {
+	db *gorm.DB
+}
+
+func NewCalendarRepository(db *gorm.DB) *calendarRepository {
+	return &calendarRepository{db: db}
+}
+
+func (repo *calendarRepository) FindCalendarFeedByUserID(userID uuid.UUID) (feed *entity.CalendarFeed, err error) {
+	feed = &entity.CalendarFeed{}
+	tx := repo.db.First(feed, "user_id = ?", userID)
+	return feed, tx.Error
+}
+
+func (repo *calendarRepository) FindCalendarFeedByToken(token string) (feed *entity.CalendarFeed, err error) {
+	feed = &entity.CalendarFeed{}
+	tx := repo.db.First(feed, "token = ?", token)
+	return feed, tx.Error
+}
+
+func (repo *calendarRepository) SaveCalendarFeed(feed *entity.CalendarFeed) (err error) {
+	tx := repo.db.Save(feed)
+	return tx.Error
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"booking/internal/entity"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IExternalCalendarRepository interface {
+	FindExternalCalendars(resourceID uuid.UUID) (calendars []entity.ExternalCalendar, err error)
+	FindExternalCalendarByID(resourceID uuid.UUID, id uuid.UUID) (calendar *entity.ExternalCalendar, err error)
+	// FindExternalCalendarsToSync returns the calendars with a URL not synced
+	// since the given time, the longest unsynced first.
+	FindExternalCalendarsToSync(syncedBefore time.Time, limit int) (calendars []entity.ExternalCalendar, err error)
+	CreateExternalCalendar(calendar *entity.ExternalCalendar) (err error)
+	UpdateExternalCalendar(calendar *entity.ExternalCalendar) (err error)
+	DeleteExternalCalendar(resourceID uuid.UUID, id uuid.UUID) (deleted int64, err error)
+	// ReplaceImportedBlackouts saves the calendar with the blackout periods
+	// imported from it in place of the ones imported before.
+	ReplaceImportedBlackouts(calendar *entity.ExternalCalendar, blackouts []entity.BlackoutPeriod) (err error)
+}
+
+type externalCalendarRepository struct {
+	db *gorm.DB
+}
+
+func NewExternalCalendarRepository(db *gorm.DB) *externalCalendarRepository {
+	return &externalCalendarRepository{db: db}
+}
+
+func (repo *externalCalendarRepository) FindExternalCalendars(resourceID uuid.UUID) (calendars []entity.ExternalCalendar, err error) {
+	tx := repo.db.Where("resource_id = ?", resourceID).Order("created_at").Find(&calendars)
+	return calendars, tx.Error
+}
+
+func (repo *externalCalendarRepository) FindExternalCalendarByID(resourceID uuid.UUID, id uuid.UUID) (calendar *entity.ExternalCalendar, err error) {
+	calendar = &entity.ExternalCalendar{}
+	tx := repo.db.First(calendar, "resource_id = ? AND id = ?", resourceID, id)
+	return calendar, tx.Error
+}
+
+func (repo *externalCalendarRepository) FindExternalCalendarsToSync(syncedBefore time.Time, limit int) (calendars []entity.ExternalCalendar, err error) {
+	tx := repo.db.
+		Where("url <> '' AND (synced_at IS NULL OR synced_at < ?)", syncedBefore).
+		Order("synced_at NULLS FIRST").
+		Limit(limit).
+		Find(&calendars)
+	return calendars, tx.Error
+}
+
+func (repo *externalCalendarRepository) CreateExternalCalendar(calendar *entity.ExternalCalendar) (err error) {
+	tx := repo.db.Create(calendar)
+	return tx.Error
+}
+
+func (repo *externalCalendarRepository) UpdateExternalCalendar(calendar *entity.ExternalCalendar) (err error) {
+	tx := repo.db.Save(calendar)
+	return tx.Error
+}
+
+func (repo *externalCalendarRepository) DeleteExternalCalendar(resourceID uuid.UUID, id uuid.UUID) (deleted int64, err error) {
+	err = repo.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("resource_id = ? AND id = ?", resourceID, id).Delete(&entity.ExternalCalendar{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		deleted = result.RowsAffected
+		return tx.Unscoped().Where("external_calendar_id = ?", id).Delete(&entity.BlackoutPeriod{}).Error
+	})
+	return deleted, err
+}
+
+func (repo *externalCalendarRepository) ReplaceImportedBlackouts(calendar *entity.ExternalCalendar, blackouts []entity.BlackoutPeriod) (err error) {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		// the calendar is locked so concurrent syncs replace the periods one
+		// after the other rather than both adding theirs
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&entity.ExternalCalendar{}, "id = ?", calendar.ID).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Save(calendar).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Unscoped().Where("external_calendar_id = ?", calendar.ID).Delete(&entity.BlackoutPeriod{}).Error
+		if err != nil || len(blackouts) == 0 {
+			return err
+		}
+		return tx.CreateInBatches(&blackouts, 500).Error
+	})
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"booking/internal/entity"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IJobRepository interface {
+	// EnqueueJob schedules the job unless one with the same key is scheduled
+	// already.
+	EnqueueJob(job *entity.Job) (err error)
+	// ClaimJobs leases to the worker the pending jobs due by now and the
+	// running jobs whose lease expired, oldest first. Jobs locked by another
+	// worker claiming at the same time are skipped.
+	ClaimJobs(worker string, now time.Time, lease time.Duration, limit int) (jobs []entity.Job, err error)
+	// UpdateJob saves the outcome of the job if the worker still holds its
+	// lease, reporting whether it did.
+	UpdateJob(worker string, job *entity.Job) (updated bool, err error)
+	FindJobByID(id uuid.UUID) (job *entity.Job, err error)
+	// RetryJob schedules the failed job to run again now with fresh attempts,
+	// reporting whether it had failed.
+	RetryJob(id uuid.UUID, now time.Time) (retried bool, err error)
+	FindJobs(status string, kind string, offset int, limit int) (jobs []entity.Job, total int64, err error)
+	// DeleteFinishedJobs deletes the jobs that succeeded before the given time.
+	DeleteFinishedJobs(before time.Time) (deleted int64, err error)
+}
+
+type jobRepository struct {
+	db *gorm.DB
+}
+
+func NewJobRepository(db *gorm.DB) *jobRepository {
+	return &jobRepository{db: db}
+}
+
+func (repo *jobRepository) EnqueueJob(job *entity.Job) (err error) {
+	tx := repo.db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "key"}}, DoNothing: true}).Create(job)
+	return tx.Error
+}
+
+func (repo *jobRepository) ClaimJobs(worker string, now time.Time, lease time.Duration, limit int) (jobs []entity.Job, err error) {
+	err = repo.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("(status = ? AND run_at <= ?) OR (status = ? AND locked_until <= ?)", entity.JOB_STATUS_PENDING, now, entity.JOB_STATUS_RUNNING, now).
+			Order("run_at").
+			Limit(limit).
+			Find(&jobs).Error
+		if err != nil {
+			return err
+		}
+		lockedUntil := now.Add(lease)
+		for i := range jobs {
+			job := &jobs[i]
+			// a job whose lease expired was being run by a worker that is
+			// gone, its attempt having failed
+			if job.IsLeaseExpired(now) {
+				job.LastError = "lease expired"
+			}
+			job.Attempts++
+			job.Status = entity.JOB_STATUS_RUNNING
+			job.LockedBy = worker
+			job.LockedUntil = &lockedUntil
+			job.StartedAt = &now
+			err = tx.Save(job).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return jobs, err
+}
+
+func (repo *jobRepository) UpdateJob(worker string, job *entity.Job) (updated bool, err error) {
+	tx := repo.db.Model(job).
+		Where("status = ? AND locked_by = ?", entity.JOB_STATUS_RUNNING, worker).
+		Select("status", "run_at", "attempts", "locked_by", "locked_until", "last_error", "finished_at").
+		Updates(job)
+	return tx.RowsAffected > 0, tx.Error
+}
+
+func (repo *jobRepository) FindJobByID(id uuid.UUID) (job *entity.Job, err error) {
+	job = &entity.Job{}
+	tx := repo.db.First(job, "id = ?", id)
+	return job, tx.Error
+}
+
+func (repo *jobRepository) RetryJob(id uuid.UUID, now time.Time) (retried bool, err error) {
+	tx := repo.db.Model(&entity.Job{}).
+		Where("id = ? AND status = ?", id, entity.JOB_STATUS_FAILED).
+		Updates(map[string]any{"status": entity.JOB_STATUS_PENDING, "run_at": now, "attempts": 0, "finished_at": nil})
+	return tx.RowsAffected > 0, tx.Error
+}
+
+func (repo *jobRepository) FindJobs(status string, kind string, offset int, limit int) (jobs []entity.Job, total int64, err error) {
+	query := repo.db.Model(&entity.Job{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	err = query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	tx := query.Order("run_at DESC").Offset(offset).Limit(limit).Find(&jobs)
+	return jobs, total, tx.Error
+}
+
+func (repo *jobRepository) DeleteFinishedJobs(before time.Time) (deleted int64, err error) {
+	tx := repo.db.Where("status = ? AND finished_at < ?", entity.JOB_STATUS_SUCCEEDED, before).Delete(&entity.Job{})
+	return tx.RowsAffected, tx.Error
+}
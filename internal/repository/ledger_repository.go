@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"booking/internal/entity"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ILedgerRepository interface {
+	WithTx(tx *gorm.DB) ILedgerRepository
+	FindOrCreateAccount(organizationID uuid.UUID, code string) (account *entity.LedgerAccount, err error)
+	FindAccountByCode(organizationID uuid.UUID, code string) (account *entity.LedgerAccount, err error)
+	CreateJournalEntry(entry *entity.JournalEntry) (err error)
+	FindAccountBalances(organizationID uuid.UUID, at time.Time) (balances []entity.AccountBalance, err error)
+	// SumAccountLines returns the debits less the credits of the account
+	// posted before the given time.
+	SumAccountLines(accountID uuid.UUID, before time.Time) (balance int64, err error)
+	FindStatementLines(accountID uuid.UUID, startAt time.Time, endAt time.Time, offset int, limit int) (lines []entity.StatementLine, total int64, err error)
+}
+
+type ledgerRepository struct {
+	db *gorm.DB
+}
+
+func NewLedgerRepository(db *gorm.DB) *ledgerRepository {
+	return &ledgerRepository{db: db}
+}
+
+// MigrateLedger makes the journal append-only and checks that the lines of
+// each entry balance when the transaction posting it commits.
+func MigrateLedger(db *gorm.DB) error {
+	return db.Exec(`
+CREATE OR REPLACE FUNCTION ledger_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'the ledger is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS journal_entries_append_only ON journal_entries;
+CREATE TRIGGER journal_entries_append_only BEFORE UPDATE OR DELETE ON journal_entries
+	FOR EACH ROW EXECUTE FUNCTION ledger_append_only();
+DROP TRIGGER IF EXISTS journal_lines_append_only ON journal_lines;
+CREATE TRIGGER journal_lines_append_only BEFORE UPDATE OR DELETE ON journal_lines
+	FOR EACH ROW EXECUTE FUNCTION ledger_append_only();
+
+CREATE OR REPLACE FUNCTION journal_entry_balanced() RETURNS trigger AS $$
+BEGIN
+	IF (SELECT SUM(debit - credit) FROM journal_lines WHERE journal_entry_id = NEW.journal_entry_id) <> 0 THEN
+		RAISE EXCEPTION 'journal entry % does not balance', NEW.journal_entry_id;
+	END IF;
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS journal_lines_balanced ON journal_lines;
+CREATE CONSTRAINT TRIGGER journal_lines_balanced AFTER INSERT ON journal_lines
+	DEFERRABLE INITIALLY DEFERRED
+	FOR EACH ROW EXECUTE FUNCTION journal_entry_balanced();
+`).Error
+}
+
+func (repo *ledgerRepository) WithTx(tx *gorm.DB) ILedgerRepository {
+	return &ledgerRepository{db: tx}
+}
+
+// FindOrCreateAccount opens the account of the organization the first time it
+// is posted to.
+func (repo *ledgerRepository) FindOrCreateAccount(organizationID uuid.UUID, code string) (account *entity.LedgerAccount, err error) {
+	account = &entity.LedgerAccount{OrganizationID: organizationID, Code: code, Type: entity.LedgerAccountType(code)}
+	tx := repo.db.Clauses(clause.OnConflict{DoNothing: true}).Create(account)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return repo.FindAccountByCode(organizationID, code)
+}
+
+func (repo *ledgerRepository) FindAccountByCode(organizationID uuid.UUID, code string) (account *entity.LedgerAccount, err error) {
+	account = &entity.LedgerAccount{}
+	tx := repo.db.First(account, "organization_id = ? AND code = ?", organizationID, code)
+	return account, tx.Error
+}
+
+func (repo *ledgerRepository) CreateJournalEntry(entry *entity.JournalEntry) (err error) {
+	err = entry.Validate()
+	if err != nil {
+		return err
+	}
+	tx := repo.db.Create(entry)
+	return tx.Error
+}
+
+func (repo *ledgerRepository) FindAccountBalances(organizationID uuid.UUID, at time.Time) (balances []entity.AccountBalance, err error) {
+	tx := repo.db.
+		Table("ledger_accounts AS a").
+		Select("a.code, a.type, COALESCE(SUM(l.debit), 0) AS debit, COALESCE(SUM(l.credit), 0) AS credit").
+		Joins("LEFT JOIN journal_lines AS l ON l.account_id = a.id AND l.journal_entry_id IN (SELECT id FROM journal_entries WHERE posted_at < ?)", at).
+		Where("a.organization_id = ?", organizationID).
+		Group("a.code, a.type").
+		Order("a.code").
+		Scan(&balances)
+	return balances, tx.Error
+}
+
+func (repo *ledgerRepository) SumAccountLines(accountID uuid.UUID, before time.Time) (balance int64, err error) {
+	tx := repo.db.
+		Table("journal_lines AS l").
+		Joins("JOIN journal_entries AS e ON e.id = l.journal_entry_id").
+		Where("l.account_id = ? AND e.posted_at < ?", accountID, before).
+		Select("COALESCE(SUM(l.debit - l.credit), 0)").
+		Scan(&balance)
+	return balance, tx.Error
+}
+
+func (repo *ledgerRepository) FindStatementLines(accountID uuid.UUID, startAt time.Time, endAt time.Time, offset int, limit int) (lines []entity.StatementLine, total int64, err error) {
+	tx := repo.db.
+		Table("journal_lines AS l").
+		Joins("JOIN journal_entries AS e ON e.id = l.journal_entry_id").
+		Where("l.account_id = ? AND e.posted_at >= ? AND e.posted_at < ?", accountID, startAt, endAt).
+		Count(&total)
+	if tx.Error != nil {
+		return nil, 0, tx.Error
+	}
+
+	// the running balance is summed over every line of the account so the
+	// first line of a page carries the balance before it
+	tx = repo.db.Raw(`
+SELECT * FROM (
+	SELECT l.id, e.id AS entry_id, e.booking_id, e.type, e.description, e.posted_at, l.debit, l.credit,
+		SUM(l.debit - l.credit) OVER (ORDER BY e.posted_at, l.id) AS balance
+	FROM journal_lines AS l
+	JOIN journal_entries AS e ON e.id = l.journal_entry_id
+	WHERE l.account_id = ? AND e.posted_at < ?
+) AS statement
+WHERE posted_at >= ?
+ORDER BY posted_at, id
+LIMIT ? OFFSET ?`, accountID, endAt, startAt, limit, offset).Scan(&lines)
+	return lines, total, tx.Error
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"booking/internal/entity"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IMediaRepository interface {
+	WithContext(ctx context.Context) IMediaRepository
+	FindMediaFileByID(id uuid.UUID) (file *entity.MediaFile, err error)
+	// FindMediaFilesByResourceID returns the gallery of the resource in
+	// order.
+	FindMediaFilesByResourceID(resourceID uuid.UUID) (files []entity.MediaFile, err error)
+	// CreateMediaFile adds the file at the end of the gallery of its
+	// resource.
+	CreateMediaFile(file *entity.MediaFile) (err error)
+	// ReorderMediaFiles sets the position of each file of the resource to its
+	// index in ids.
+	ReorderMediaFiles(resourceID uuid.UUID, ids []uuid.UUID) (err error)
+	DeleteMediaFile(file *entity.MediaFile) (err error)
+}
+
+type mediaRepository struct {
+	db *gorm.DB
+}
+
+func NewMediaRepository(db *gorm.DB) *mediaRepository {
+	return &mediaRepository{db: db}
+}
+
+func (repo *mediaRepository) WithContext(ctx context.Context) IMediaRepository {
+	return &mediaRepository{db: repo.db.WithContext(ctx)}
+}
+
+func (repo *mediaRepository) FindMediaFileByID(id uuid.UUID) (file *entity.MediaFile, err error) {
+	file = &entity.MediaFile{}
+	tx := repo.db.First(file, "id = ?", id)
+	return file, tx.Error
+}
+
+func (repo *mediaRepository) FindMediaFilesByResourceID(resourceID uuid.UUID) (files []entity.MediaFile, err error) {
+	tx := repo.db.Where("resource_id = ?", resourceID).Order("position, created_at").Find(&files)
+	return files, tx.Error
+}
+
+func (repo *mediaRepository) CreateMediaFile(file *entity.MediaFile) (err error) {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		// The resource is locked so concurrent uploads don't take the same
+		// position.
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&entity.Resource{}, "id = ?", file.ResourceID).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&entity.MediaFile{}).
+			Select("COALESCE(MAX(position) + 1, 0)").
+			Where("resource_id = ?", file.ResourceID).
+			Scan(&file.Position).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(file).Error
+	})
+}
+
+func (repo *mediaRepository) ReorderMediaFiles(resourceID uuid.UUID, ids []uuid.UUID) (err error) {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		for position, id := range ids {
+			err := tx.Model(&entity.MediaFile{}).
+				Where("id = ? AND resource_id = ?", id, resourceID).
+				Update("position", position).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (repo *mediaRepository) DeleteMediaFile(file *entity.MediaFile) (err error) {
+	tx := repo.db.Delete(file)
+	return tx.Error
+}
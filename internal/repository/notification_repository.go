@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"booking/internal/entity"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type INotificationRepository interface {
+	WithTx(tx *gorm.DB) INotificationRepository
+	// FindRecipient returns the user with their notification preference, the
+	// default one when they haven't saved any.
+	FindRecipient(userID uuid.UUID) (user *entity.User, preference *entity.NotificationPreference, err error)
+	SavePreference(preference *entity.NotificationPreference) (err error)
+	CreateNotifications(notifications []entity.Notification) (err error)
+	// ClaimNotifications returns pending notifications due by now and leases
+	// them by pushing their next attempt past the lease, so that other
+	// dispatchers skip them while they are being sent.
+	ClaimNotifications(now time.Time, lease time.Duration, limit int) (notifications []entity.Notification, err error)
+	UpdateNotification(notification *entity.Notification) (err error)
+	// FindBookingsToRemind returns confirmed bookings starting between the
+	// given times that haven't been reminded of yet, the soonest first.
+	FindBookingsToRemind(startAfter time.Time, startBefore time.Time, limit int) (bookings []entity.Booking, err error)
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) *notificationRepository {
+	return &notificationRepository{db: db}
+}
+
+func (repo *notificationRepository) WithTx(tx *gorm.DB) INotificationRepository {
+	return &notificationRepository{db: tx}
+}
+
+func (repo *notificationRepository) FindRecipient(userID uuid.UUID) (user *entity.User, preference *entity.NotificationPreference, err error) {
+	user = &entity.User{}
+	tx := repo.db.First(user, "id = ?", userID)
+	if tx.Error != nil {
+		return nil, nil, tx.Error
+	}
+	preference = &entity.NotificationPreference{}
+	tx = repo.db.First(preference, "user_id = ?", userID)
+	if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		return user, &entity.NotificationPreference{UserID: userID, Email: true, Language: "en"}, nil
+	}
+	return user, preference, tx.Error
+}
+
+func (repo *notificationRepository) SavePreference(preference *entity.NotificationPreference) (err error) {
+	// Select writes the false flags too, which Save would leave to their
+	// defaults on the first save
+	tx := repo.db.Select("*").Omit("created_at").Save(preference)
+	return tx.Error
+}
+
+func (repo *notificationRepository) CreateNotifications(notifications []entity.Notification) (err error) {
+	if len(notifications) == 0 {
+		return nil
+	}
+	tx := repo.db.Create(&notifications)
+	return tx.Error
+}
+
+func (repo *notificationRepository) ClaimNotifications(now time.Time, lease time.Duration, limit int) (notifications []entity.Notification, err error) {
+	err = repo.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", entity.NOTIFICATION_STATUS_PENDING, now).
+			Order("next_attempt_at").
+			Limit(limit).
+			Find(&notifications).Error
+		if err != nil || len(notifications) == 0 {
+			return err
+		}
+		ids := []uuid.UUID{}
+		for i := range notifications {
+			ids = append(ids, notifications[i].ID)
+		}
+		return tx.Model(&entity.Notification{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(lease)).Error
+	})
+	return notifications, err
+}
+
+func (repo *notificationRepository) UpdateNotification(notification *entity.Notification) (err error) {
+	tx := repo.db.Save(notification)
+	return tx.Error
+}
+
+func (repo *notificationRepository) FindBookingsToRemind(startAfter time.Time, startBefore time.Time, limit int) (bookings []entity.Booking, err error) {
+	tx := repo.db.
+		Where("status = ? AND start_at > ? AND start_at <= ? AND reminded_at IS NULL", entity.BOOKING_STATUS_CONFIRMED, startAfter, startBefore).
+		Order("start_at").
+		Limit(limit).
+		Find(&bookings)
+	return bookings, tx.Error
+}
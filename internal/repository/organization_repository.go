@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"booking/internal/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IOrganizationRepository interface {
+	// CreateOrganization creates the organization with its first member.
+	CreateOrganization(organization *entity.Organization, owner *entity.OrganizationMember) (err error)
+	FindOrganizationByID(id uuid.UUID) (organization *entity.Organization, err error)
+	FindOrganizationBySlug(slug string) (organization *entity.Organization, err error)
+	FindOrganizationsByIDs(ids []uuid.UUID) (organizations []entity.Organization, err error)
+	FindOrganizationMember(organizationID uuid.UUID, userID uuid.UUID) (member *entity.OrganizationMember, err error)
+	FindOrganizationMembersByUserID(userID uuid.UUID) (members []entity.OrganizationMember, err error)
+	// SaveOrganizationMember adds the member, or changes their role when the
+	// user already is one.
+	SaveOrganizationMember(member *entity.OrganizationMember) (err error)
+	RemoveOrganizationMember(organizationID uuid.UUID, userID uuid.UUID) (deleted int64, err error)
+}
+
+type organizationRepository struct {
+	db *gorm.DB
+}
+
+func NewOrganizationRepository(db *gorm.DB) *organizationRepository {
+	return &organizationRepository{db: db}
+}
+
+func (repo *organizationRepository) CreateOrganization(organization *entity.Organization, owner *entity.OrganizationMember) (err error) {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(organization).Error
+		if err != nil {
+			return err
+		}
+		owner.OrganizationID = organization.ID
+		return tx.Create(owner).Error
+	})
+}
+
+func (repo *organizationRepository) FindOrganizationByID(id uuid.UUID) (organization *entity.Organization, err error) {
+	organization = &entity.Organization{}
+	tx := repo.db.First(organization, "id = ?", id)
+	return organization, tx.Error
+}
+
+func (repo *organizationRepository) FindOrganizationBySlug(slug string) (organization *entity.Organization, err error) {
+	organization = &entity.Organization{}
+	tx := repo.db.First(organization, "slug = ?", slug)
+	return organization, tx.Error
+}
+
+func (repo *organizationRepository) FindOrganizationsByIDs(ids []uuid.UUID) (organizations []entity.Organization, err error) {
+	tx := repo.db.Where("id IN ?", ids).Order("name").Find(&organizations)
+	return organizations, tx.Error
+}
+
+func (repo *organizationRepository) FindOrganizationMember(organizationID uuid.UUID, userID uuid.UUID) (member *entity.OrganizationMember, err error) {
+	member = &entity.OrganizationMember{}
+	tx := repo.db.First(member, "organization_id = ? AND user_id = ?", organizationID, userID)
+	return member, tx.Error
+}
+
+func (repo *organizationRepository) FindOrganizationMembersByUserID(userID uuid.UUID) (members []entity.OrganizationMember, err error) {
+	tx := repo.db.Where("user_id = ?", userID).Find(&members)
+	return members, tx.Error
+}
+
+func (repo *organizationRepository) SaveOrganizationMember(member *entity.OrganizationMember) (err error) {
+	tx := repo.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organization_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role", "updated_at"}),
+	}).Create(member)
+	return tx.Error
+}
+
+func (repo *organizationRepository) RemoveOrganizationMember(organizationID uuid.UUID, userID uuid.UUID) (deleted int64, err error) {
+	tx := repo.db.Where("organization_id = ? AND user_id = ?", organizationID, userID).Delete(&entity.OrganizationMember{})
+	return tx.RowsAffected, tx.Error
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"booking/internal/entity"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IPaymentRepository interface {
+	WithContext(ctx context.Context) IPaymentRepository
+	WithTx(tx *gorm.DB) IPaymentRepository
+	CreatePayment(payment *entity.Payment) (err error)
+	FindPaymentByID(id uuid.UUID) (payment *entity.Payment, err error)
+	FindPaymentByIntentID(intentID string) (payment *entity.Payment, err error)
+	LockPaymentByIntentID(intentID string) (payment *entity.Payment, err error)
+	FindPaymentsByBookingID(bookingID uuid.UUID) (payments []entity.Payment, err error)
+	UpdatePayment(payment *entity.Payment) (err error)
+	// CreatePaymentEvent records the event unless it was recorded before, and
+	// reports whether it was.
+	CreatePaymentEvent(event *entity.PaymentEvent) (created bool, err error)
+}
+
+type paymentRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentRepository(db *gorm.DB) *paymentRepository {
+	return &paymentRepository{db: db}
+}
+
+func (repo *paymentRepository) WithContext(ctx context.Context) IPaymentRepository {
+	return &paymentRepository{db: repo.db.WithContext(ctx)}
+}
+
+func (repo *paymentRepository) WithTx(tx *gorm.DB) IPaymentRepository {
+	return &paymentRepository{db: tx}
+}
+
+func (repo *paymentRepository) CreatePayment(payment *entity.Payment) (err error) {
+	tx := repo.db.Create(payment)
+	return tx.Error
+}
+
+func (repo *paymentRepository) FindPaymentByID(id uuid.UUID) (payment *entity.Payment, err error) {
+	payment = &entity.Payment{}
+	tx := repo.db.First(payment, "id = ?", id)
+	return payment, tx.Error
+}
+
+func (repo *paymentRepository) FindPaymentByIntentID(intentID string) (payment *entity.Payment, err error) {
+	payment = &entity.Payment{}
+	tx := repo.db.First(payment, "intent_id = ?", intentID)
+	return payment, tx.Error
+}
+
+func (repo *paymentRepository) LockPaymentByIntentID(intentID string) (payment *entity.Payment, err error) {
+	payment = &entity.Payment{}
+	tx := repo.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(payment, "intent_id = ?", intentID)
+	return payment, tx.Error
+}
+
+func (repo *paymentRepository) FindPaymentsByBookingID(bookingID uuid.UUID) (payments []entity.Payment, err error) {
+	tx := repo.db.Where("booking_id = ?", bookingID).Order("created_at").Find(&payments)
+	return payments, tx.Error
+}
+
+func (repo *paymentRepository) UpdatePayment(payment *entity.Payment) (err error) {
+	tx := repo.db.Save(payment)
+	return tx.Error
+}
+
+func (repo *paymentRepository) CreatePaymentEvent(event *entity.PaymentEvent) (created bool, err error) {
+	tx := repo.db.Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+	return tx.RowsAffected > 0, tx.Error
+}
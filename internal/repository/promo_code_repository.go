@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"booking/internal/entity"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IPromoCodeRepository interface {
+	WithContext(ctx context.Context) IPromoCodeRepository
+	WithTx(tx *gorm.DB) IPromoCodeRepository
+	CreatePromoCode(promoCode *entity.PromoCode) (err error)
+	FindPromoCodeByCode(code string) (promoCode *entity.PromoCode, err error)
+	LockPromoCodeByCode(code string) (promoCode *entity.PromoCode, err error)
+	// FindPromoCodes returns the promo codes of the organization of the
+	// context, the latest first.
+	FindPromoCodes() (promoCodes []entity.PromoCode, err error)
+	CountRedemptionsOfUser(promoCodeID uuid.UUID, userID uuid.UUID) (count int64, err error)
+	// RedeemPromoCode records the redemption and counts it on the promo code,
+	// which must be locked.
+	RedeemPromoCode(promoCode *entity.PromoCode, redemption *entity.PromoRedemption) (err error)
+}
+
+type promoCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewPromoCodeRepository(db *gorm.DB) *promoCodeRepository {
+	return &promoCodeRepository{db: db}
+}
+
+func (repo *promoCodeRepository) WithContext(ctx context.Context) IPromoCodeRepository {
+	return &promoCodeRepository{db: repo.db.WithContext(ctx)}
+}
+
+func (repo *promoCodeRepository) WithTx(tx *gorm.DB) IPromoCodeRepository {
+	return &promoCodeRepository{db: tx}
+}
+
+func (repo *promoCodeRepository) CreatePromoCode(promoCode *entity.PromoCode) (err error) {
+	tx := repo.db.Create(promoCode)
+	return tx.Error
+}
+
+func (repo *promoCodeRepository) FindPromoCodeByCode(code string) (promoCode *entity.PromoCode, err error) {
+	promoCode = &entity.PromoCode{}
+	tx := repo.db.First(promoCode, "code = ?", code)
+	return promoCode, tx.Error
+}
+
+func (repo *promoCodeRepository) LockPromoCodeByCode(code string) (promoCode *entity.PromoCode, err error) {
+	promoCode = &entity.PromoCode{}
+	tx := repo.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(promoCode, "code = ?", code)
+	return promoCode, tx.Error
+}
+
+func (repo *promoCodeRepository) FindPromoCodes() (promoCodes []entity.PromoCode, err error) {
+	tx := repo.db.Order("created_at DESC").Find(&promoCodes)
+	return promoCodes, tx.Error
+}
+
+func (repo *promoCodeRepository) CountRedemptionsOfUser(promoCodeID uuid.UUID, userID uuid.UUID) (count int64, err error) {
+	tx := repo.db.Model(&entity.PromoRedemption{}).Where("promo_code_id = ? AND user_id = ?", promoCodeID, userID).Count(&count)
+	return count, tx.Error
+}
+
+func (repo *promoCodeRepository) RedeemPromoCode(promoCode *entity.PromoCode, redemption *entity.PromoRedemption) (err error) {
+	err = repo.db.Create(redemption).Error
+	if err != nil {
+		return err
+	}
+	promoCode.RedemptionCount++
+	tx := repo.db.Model(promoCode).Update("redemption_count", gorm.Expr("redemption_count + 1"))
+	return tx.Error
+}
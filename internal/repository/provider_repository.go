@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"booking/internal/entity"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IProviderRepository interface {
+	WithTx(tx *gorm.DB) IProviderRepository
+	WithContext(ctx context.Context) IProviderRepository
+	CreateProvider(provider *entity.Provider) (err error)
+	FindProviderByID(id uuid.UUID) (provider *entity.Provider, err error)
+	FindProviders() (providers []entity.Provider, err error)
+	// LockProvidersByIDs loads the providers with row locks in the order of
+	// their IDs, so that concurrent appointments lock them without deadlocks.
+	LockProvidersByIDs(ids []uuid.UUID) (providers []entity.Provider, err error)
+	FindProviderHours(providerID uuid.UUID) (hours []entity.ProviderHours, err error)
+	// ReplaceProviderHours saves the time zone of the provider with their new
+	// weekly hours.
+	ReplaceProviderHours(provider *entity.Provider, hours []entity.ProviderHours) (err error)
+	FindProviderTimeOff(providerID uuid.UUID, startAt time.Time, endAt time.Time) (timeOff []entity.ProviderTimeOff, err error)
+	CreateProviderTimeOff(timeOff *entity.ProviderTimeOff) (err error)
+	DeleteProviderTimeOff(providerID uuid.UUID, id uuid.UUID) (deleted int64, err error)
+	CreateService(service *entity.Service) (err error)
+	FindServiceByID(id uuid.UUID) (service *entity.Service, err error)
+	FindServices() (services []entity.Service, err error)
+	FindProviderServicesByServiceID(serviceID uuid.UUID) (providerServices []entity.ProviderService, err error)
+	// SaveProviderService adds the service to the provider, or changes its
+	// duration when the provider already performs it.
+	SaveProviderService(providerService *entity.ProviderService) (err error)
+	RemoveProviderService(providerID uuid.UUID, serviceID uuid.UUID) (deleted int64, err error)
+}
+
+type providerRepository struct {
+	db *gorm.DB
+}
+
+func NewProviderRepository(db *gorm.DB) *providerRepository {
+	return &providerRepository{db: db}
+}
+
+func (repo *providerRepository) WithTx(tx *gorm.DB) IProviderRepository {
+	return &providerRepository{db: tx}
+}
+
+func (repo *providerRepository) WithContext(ctx context.Context) IProviderRepository {
+	return &providerRepository{db: repo.db.WithContext(ctx)}
+}
+
+func (repo *providerRepository) CreateProvider(provider *entity.Provider) (err error) {
+	tx := repo.db.Create(provider)
+	return tx.Error
+}
+
+func (repo *providerRepository) FindProviderByID(id uuid.UUID) (provider *entity.Provider, err error) {
+	provider = &entity.Provider{}
+	tx := repo.db.First(provider, "id = ?", id)
+	return provider, tx.Error
+}
+
+func (repo *providerRepository) FindProviders() (providers []entity.Provider, err error) {
+	tx := repo.db.Order("name").Find(&providers)
+	return providers, tx.Error
+}
+
+func (repo *providerRepository) LockProvidersByIDs(ids []uuid.UUID) (providers []entity.Provider, err error) {
+	tx := repo.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id IN ?", ids).Order("id").Find(&providers)
+	return providers, tx.Error
+}
+
+func (repo *providerRepository) FindProviderHours(providerID uuid.UUID) (hours []entity.ProviderHours, err error) {
+	tx := repo.db.Where("provider_id = ?", providerID).Order("weekday").Find(&hours)
+	return hours, tx.Error
+}
+
+func (repo *providerRepository) ReplaceProviderHours(provider *entity.Provider, hours []entity.ProviderHours) (err error) {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(provider).Update("time_zone", provider.TimeZone).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("provider_id = ?", provider.ID).Delete(&entity.ProviderHours{}).Error
+		if err != nil || len(hours) == 0 {
+			return err
+		}
+		return tx.Create(&hours).Error
+	})
+}
+
+func (repo *providerRepository) FindProviderTimeOff(providerID uuid.UUID, startAt time.Time, endAt time.Time) (timeOff []entity.ProviderTimeOff, err error) {
+	tx := repo.db.
+		Where("provider_id = ? AND start_at < ? AND end_at > ?", providerID, endAt, startAt).
+		Order("start_at").
+		Find(&timeOff)
+	return timeOff, tx.Error
+}
+
+func (repo *providerRepository) CreateProviderTimeOff(timeOff *entity.ProviderTimeOff) (err error) {
+	tx := repo.db.Create(timeOff)
+	return tx.Error
+}
+
+func (repo *providerRepository) DeleteProviderTimeOff(providerID uuid.UUID, id uuid.UUID) (deleted int64, err error) {
+	tx := repo.db.Where("provider_id = ? AND id = ?", providerID, id).Delete(&entity.ProviderTimeOff{})
+	return tx.RowsAffected, tx.Error
+}
+
+func (repo *providerRepository) CreateService(service *entity.Service) (err error) {
+	tx := repo.db.Create(service)
+	return tx.Error
+}
+
+func (repo *providerRepository) FindServiceByID(id uuid.UUID) (service *entity.Service, err error) {
+	service = &entity.Service{}
+	tx := repo.db.First(service, "id = ?", id)
+	return service, tx.Error
+}
+
+func (repo *providerRepository) FindServices() (services []entity.Service, err error) {
+	tx := repo.db.Order("name").Find(&services)
+	return services, tx.Error
+}
+
+func (repo *providerRepository) FindProviderServicesByServiceID(serviceID uuid.UUID) (providerServices []entity.ProviderService, err error) {
+	tx := repo.db.Where("service_id = ?", serviceID).Order("provider_id").Find(&providerServices)
+	return providerServices, tx.Error
+}
+
+func (repo *providerRepository) SaveProviderService(providerService *entity.ProviderService) (err error) {
+	tx := repo.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "provider_id"}, {Name: "service_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"duration_minutes", "updated_at"}),
+	}).Create(providerService)
+	return tx.Error
+}
+
+func (repo *providerRepository) RemoveProviderService(providerID uuid.UUID, serviceID uuid.UUID) (deleted int64, err error) {
+	tx := repo.db.Where("provider_id = ? AND service_id = ?", providerID, serviceID).Delete(&entity.ProviderService{})
+	return tx.RowsAffected, tx.Error
+}
@@ -0,0 +1,317 @@
+package repository
+
+import (
+	"booking/internal/entity"
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IResourceRepository interface {
+	WithContext(ctx context.Context) IResourceRepository
+	FindResourceByID(id uuid.UUID) (resource *entity.Resource, err error)
+	CreateResource(resource *entity.Resource) (err error)
+	UpdateResource(resource *entity.Resource) (err error)
+	// SearchResources returns a page of the resources matching the search
+	// and how many match in total.
+	SearchResources(search ResourceSearch, offset int, limit int) (resources []entity.Resource, total int64, err error)
+	FindCancellationPolicyByResourceID(resourceID uuid.UUID) (policy *entity.CancellationPolicy, err error)
+	SaveCancellationPolicy(policy *entity.CancellationPolicy) (err error)
+	FindReschedulePolicyByResourceID(resourceID uuid.UUID) (policy *entity.ReschedulePolicy, err error)
+	SaveReschedulePolicy(policy *entity.ReschedulePolicy) (err error)
+	FindPaymentScheduleByResourceID(resourceID uuid.UUID) (schedule *entity.PaymentSchedule, err error)
+	SavePaymentSchedule(schedule *entity.PaymentSchedule) (err error)
+	FindPricingPolicyByResourceID(resourceID uuid.UUID) (policy *entity.PricingPolicy, err error)
+	SavePricingPolicy(policy *entity.PricingPolicy) (err error)
+	IsResourceMember(resourceID uuid.UUID, userID uuid.UUID) (member bool, err error)
+	AddResourceMember(member *entity.ResourceMember) (err error)
+	RemoveResourceMember(resourceID uuid.UUID, userID uuid.UUID) (deleted int64, err error)
+	IsResourceStaff(resourceID uuid.UUID, userID uuid.UUID) (staff bool, err error)
+	AddResourceStaff(staff *entity.ResourceStaff) (err error)
+	RemoveResourceStaff(resourceID uuid.UUID, userID uuid.UUID) (deleted int64, err error)
+}
+
+const (
+	RESOURCE_SORT_RELEVANCE  = "relevance"
+	RESOURCE_SORT_PRICE      = "price"
+	RESOURCE_SORT_PRICE_DESC = "price_desc"
+	RESOURCE_SORT_RATING     = "rating"
+	RESOURCE_SORT_DISTANCE   = "distance"
+)
+
+// ResourceSearch filters resources, zero fields not filtering.
+type ResourceSearch struct {
+	Query     string
+	Category  string
+	Capacity  int
+	MinPrice  *int64
+	MaxPrice  *int64
+	Amenities []string
+	MinRating float64
+	// AvailableFrom and AvailableTo keep the resources with Capacity seats,
+	// at least one, free for the whole period and not blacked out. Opening
+	// hours, date overrides and holidays are checked by the caller.
+	AvailableFrom *time.Time
+	AvailableTo   *time.Time
+	// Near is the point distances are measured from, RadiusKm how far from
+	// it the resources may be when not zero.
+	Near     *entity.GeoPoint
+	RadiusKm float64
+	Bounds   *GeoBounds
+	Sort     string
+}
+
+// GeoBounds is an area of the map in decimal degrees. MinLongitude is above
+// MaxLongitude for areas across the antimeridian.
+type GeoBounds struct {
+	MinLatitude  float64
+	MaxLatitude  float64
+	MinLongitude float64
+	MaxLongitude float64
+}
+
+// resourceSearchDocument is the text of a resource searched, and
+// resourceSearchVector its English words, the name weighing more. They are
+// the expressions of the search indexes, which only serve queries written
+// the same.
+const (
+	resourceSearchDocument = "(resources.name || ' ' || resources.description)"
+	resourceSearchVector   = "(setweight(to_tsvector('english', resources.name), 'A') || setweight(to_tsvector('english', resources.description), 'B'))"
+)
+
+// MigrateResourceSearch creates the indexes of the resource search: the
+// English words of the resources, and the trigrams of their text for Thai,
+// which isn't written with spaces between words for the full-text search to
+// split it on.
+func MigrateResourceSearch(db *gorm.DB) error {
+	return db.Exec(`
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS idx_resources_search_vector ON resources USING GIN (` + resourceSearchVector + `);
+CREATE INDEX IF NOT EXISTS idx_resources_search_trigram ON resources USING GIN (` + resourceSearchDocument + ` gin_trgm_ops);
+`).Error
+}
+
+type resourceRepository struct {
+	db *gorm.DB
+}
+
+func NewResourceRepository(db *gorm.DB) *resourceRepository {
+	return &resourceRepository{db: db}
+}
+
+func (repo *resourceRepository) WithContext(ctx context.Context) IResourceRepository {
+	return &resourceRepository{db: repo.db.WithContext(ctx)}
+}
+
+func (repo *resourceRepository) FindResourceByID(id uuid.UUID) (resource *entity.Resource, err error) {
+	resource = &entity.Resource{}
+	tx := repo.db.First(resource, "id = ?", id)
+	return resource, tx.Error
+}
+
+func (repo *resourceRepository) CreateResource(resource *entity.Resource) (err error) {
+	tx := repo.db.Create(resource)
+	return tx.Error
+}
+
+func (repo *resourceRepository) UpdateResource(resource *entity.Resource) (err error) {
+	tx := repo.db.Save(resource)
+	return tx.Error
+}
+
+// SearchResources matches the query against the English words of the
+// resources, or each of its terms anywhere in their text so Thai words are
+// found inside the sentences they are written in. Relevance ranks the
+// matching words, then how closely the text holds the query.
+func (repo *resourceRepository) SearchResources(search ResourceSearch, offset int, limit int) (resources []entity.Resource, total int64, err error) {
+	query := repo.db.Model(&entity.Resource{})
+	if search.Query != "" {
+		terms := []string{}
+		vars := []any{search.Query}
+		for _, term := range strings.Fields(search.Query) {
+			terms = append(terms, resourceSearchDocument+" ILIKE ?")
+			vars = append(vars, "%"+escapeLike(term)+"%")
+		}
+		query = query.Where(resourceSearchVector+" @@ websearch_to_tsquery('english', ?) OR ("+strings.Join(terms, " AND ")+")", vars...)
+	}
+	if search.Category != "" {
+		query = query.Where("resources.category = ?", search.Category)
+	}
+	if search.Capacity > 0 {
+		query = query.Where("resources.capacity >= ?", search.Capacity)
+	}
+	if search.MinPrice != nil {
+		query = query.Where("resources.hourly_rate >= ?", *search.MinPrice)
+	}
+	if search.MaxPrice != nil {
+		query = query.Where("resources.hourly_rate <= ?", *search.MaxPrice)
+	}
+	if len(search.Amenities) > 0 {
+		query = query.Where("resources.amenities @> ?", entity.StringList(search.Amenities))
+	}
+	if search.MinRating > 0 {
+		query = query.Where("resources.rating_average >= ?", search.MinRating)
+	}
+	if search.AvailableFrom != nil && search.AvailableTo != nil {
+		// the seats of all the bookings overlapping the period are counted
+		// as taken together, which may leave out a resource whose bookings
+		// follow one another but never one that is full
+		query = query.
+			Where("NOT EXISTS (SELECT 1 FROM blackout_periods WHERE blackout_periods.resource_id = resources.id AND blackout_periods.deleted_at IS NULL AND blackout_periods.start_at < ? AND blackout_periods.end_at > ?)", search.AvailableTo, search.AvailableFrom).
+			Where("resources.capacity - (SELECT COALESCE(SUM(bookings.seats), 0) FROM bookings WHERE bookings.resource_id = resources.id AND bookings.deleted_at IS NULL AND bookings.status IN ? AND bookings.start_at < ? AND bookings.end_at > ?) >= ?",
+				[]string{entity.BOOKING_STATUS_PENDING, entity.BOOKING_STATUS_CONFIRMED}, search.AvailableTo, search.AvailableFrom, max(search.Capacity, 1))
+	}
+	if search.Bounds != nil {
+		query = query.Where("resources.latitude BETWEEN ? AND ?", search.Bounds.MinLatitude, search.Bounds.MaxLatitude)
+		if search.Bounds.MinLongitude <= search.Bounds.MaxLongitude {
+			query = query.Where("resources.longitude BETWEEN ? AND ?", search.Bounds.MinLongitude, search.Bounds.MaxLongitude)
+		} else {
+			query = query.Where("(resources.longitude >= ? OR resources.longitude <= ?)", search.Bounds.MinLongitude, search.Bounds.MaxLongitude)
+		}
+	}
+	if search.Near != nil && search.RadiusKm > 0 {
+		query = whereWithinRadius(query, *search.Near, search.RadiusKm)
+	}
+	err = query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// the order is written as one expression, as the ranks are expressions
+	// which gorm drops when merging further columns to order by
+	order := []string{}
+	vars := []any{}
+	switch {
+	case search.Sort == RESOURCE_SORT_PRICE:
+		order = append(order, "resources.hourly_rate ASC")
+	case search.Sort == RESOURCE_SORT_PRICE_DESC:
+		order = append(order, "resources.hourly_rate DESC")
+	case search.Sort == RESOURCE_SORT_RELEVANCE && search.Query != "":
+		order = append(order, "ts_rank("+resourceSearchVector+", websearch_to_tsquery('english', ?)) + word_similarity(?, "+resourceSearchDocument+") DESC")
+		vars = append(vars, search.Query, search.Query)
+	case search.Sort == RESOURCE_SORT_DISTANCE && search.Near != nil:
+		order = append(order, "? ASC NULLS LAST")
+		vars = append(vars, resourceDistanceKm(*search.Near))
+	}
+	// the best rated come first among equals, and without a query to rank by
+	order = append(order, "resources.rating_average DESC", "resources.rating_count DESC", "resources.id")
+	tx := query.
+		Clauses(clause.OrderBy{Expression: clause.Expr{SQL: strings.Join(order, ", "), Vars: vars, WithoutParentheses: true}}).
+		Offset(offset).Limit(limit).Find(&resources)
+	return resources, total, tx.Error
+}
+
+// resourceDistanceKm is the distance of the resources from the point by the
+// haversine formula, as entity.GeoPoint computes it.
+func resourceDistanceKm(point entity.GeoPoint) clause.Expr {
+	return clause.Expr{
+		SQL:  "2 * ASIN(SQRT(LEAST(POWER(SIN(RADIANS(resources.latitude - ?) / 2), 2) + COS(RADIANS(?)) * COS(RADIANS(resources.latitude)) * POWER(SIN(RADIANS(resources.longitude - ?) / 2), 2), 1))) * ?",
+		Vars: []any{point.Latitude, point.Latitude, point.Longitude, entity.EARTH_RADIUS_KM},
+	}
+}
+
+// whereWithinRadius keeps the resources within the distance of the point.
+// They are first narrowed down to the box around the circle, which the
+// position index serves, the longitudes only where the box doesn't reach a
+// pole or the antimeridian.
+func whereWithinRadius(query *gorm.DB, point entity.GeoPoint, radiusKm float64) *gorm.DB {
+	angle := radiusKm / entity.EARTH_RADIUS_KM
+	latitudeDelta := angle * 180 / math.Pi
+	minLatitude, maxLatitude := point.Latitude-latitudeDelta, point.Latitude+latitudeDelta
+	query = query.Where("resources.latitude BETWEEN ? AND ?", minLatitude, maxLatitude)
+	if minLatitude > -90 && maxLatitude < 90 {
+		longitudeDelta := math.Asin(math.Sin(angle)/math.Cos(point.Latitude*math.Pi/180)) * 180 / math.Pi
+		if point.Longitude-longitudeDelta >= -180 && point.Longitude+longitudeDelta <= 180 {
+			query = query.Where("resources.longitude BETWEEN ? AND ?", point.Longitude-longitudeDelta, point.Longitude+longitudeDelta)
+		}
+	}
+	return query.Where("? <= ?", resourceDistanceKm(point), radiusKm)
+}
+
+// escapeLike escapes the wildcards of LIKE patterns in the string.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+func (repo *resourceRepository) FindCancellationPolicyByResourceID(resourceID uuid.UUID) (policy *entity.CancellationPolicy, err error) {
+	policy = &entity.CancellationPolicy{}
+	tx := repo.db.First(policy, "resource_id = ?", resourceID)
+	return policy, tx.Error
+}
+
+func (repo *resourceRepository) SaveCancellationPolicy(policy *entity.CancellationPolicy) (err error) {
+	tx := repo.db.Save(policy)
+	return tx.Error
+}
+
+func (repo *resourceRepository) FindReschedulePolicyByResourceID(resourceID uuid.UUID) (policy *entity.ReschedulePolicy, err error) {
+	policy = &entity.ReschedulePolicy{}
+	tx := repo.db.First(policy, "resource_id = ?", resourceID)
+	return policy, tx.Error
+}
+
+func (repo *resourceRepository) SaveReschedulePolicy(policy *entity.ReschedulePolicy) (err error) {
+	tx := repo.db.Save(policy)
+	return tx.Error
+}
+
+func (repo *resourceRepository) FindPaymentScheduleByResourceID(resourceID uuid.UUID) (schedule *entity.PaymentSchedule, err error) {
+	schedule = &entity.PaymentSchedule{}
+	tx := repo.db.First(schedule, "resource_id = ?", resourceID)
+	return schedule, tx.Error
+}
+
+func (repo *resourceRepository) SavePaymentSchedule(schedule *entity.PaymentSchedule) (err error) {
+	tx := repo.db.Save(schedule)
+	return tx.Error
+}
+
+func (repo *resourceRepository) FindPricingPolicyByResourceID(resourceID uuid.UUID) (policy *entity.PricingPolicy, err error) {
+	policy = &entity.PricingPolicy{}
+	tx := repo.db.First(policy, "resource_id = ?", resourceID)
+	return policy, tx.Error
+}
+
+func (repo *resourceRepository) SavePricingPolicy(policy *entity.PricingPolicy) (err error) {
+	tx := repo.db.Save(policy)
+	return tx.Error
+}
+
+func (repo *resourceRepository) IsResourceMember(resourceID uuid.UUID, userID uuid.UUID) (member bool, err error) {
+	var count int64
+	tx := repo.db.Model(&entity.ResourceMember{}).Where("resource_id = ? AND user_id = ?", resourceID, userID).Count(&count)
+	return count > 0, tx.Error
+}
+
+// AddResourceMember adds the member unless the user already is one.
+func (repo *resourceRepository) AddResourceMember(member *entity.ResourceMember) (err error) {
+	tx := repo.db.Clauses(clause.OnConflict{DoNothing: true}).Create(member)
+	return tx.Error
+}
+
+func (repo *resourceRepository) RemoveResourceMember(resourceID uuid.UUID, userID uuid.UUID) (deleted int64, err error) {
+	tx := repo.db.Where("resource_id = ? AND user_id = ?", resourceID, userID).Delete(&entity.ResourceMember{})
+	return tx.RowsAffected, tx.Error
+}
+
+func (repo *resourceRepository) IsResourceStaff(resourceID uuid.UUID, userID uuid.UUID) (staff bool, err error) {
+	var count int64
+	tx := repo.db.Model(&entity.ResourceStaff{}).Where("resource_id = ? AND user_id = ?", resourceID, userID).Count(&count)
+	return count > 0, tx.Error
+}
+
+// AddResourceStaff adds the member of staff unless the user already is one.
+func (repo *resourceRepository) AddResourceStaff(staff *entity.ResourceStaff) (err error) {
+	tx := repo.db.Clauses(clause.OnConflict{DoNothing: true}).Create(staff)
+	return tx.Error
+}
+
+func (repo *resourceRepository) RemoveResourceStaff(resourceID uuid.UUID, userID uuid.UUID) (deleted int64, err error) {
+	tx := repo.db.Where("resource_id = ? AND user_id = ?", resourceID, userID).Delete(&entity.ResourceStaff{})
+	return tx.RowsAffected, tx.Error
+}
@@ -0,0 +1,74 @@
+package repository_test
+
+import (
+	"booking/internal/entity"
+	"booking/internal/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// newDryRunDB returns a database building the statements it is given rather
+// than running them, and the SQL of the queries built.
+func newDryRunDB(t *testing.T) (*gorm.DB, *[]string) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	queries := []string{}
+	err = db.Callback().Query().After("gorm:query").Register("test:capture", func(tx *gorm.DB) {
+		queries = append(queries, tx.Statement.SQL.String())
+		// a real run resets the statement once executed, which a dry run
+		// doesn't, leaving its SQL to the next query of the same statement
+		tx.Statement.SQL.Reset()
+		tx.Statement.Vars = nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, &queries
+}
+
+func TestSearchResources(t *testing.T) {
+	t.Run("When sort by relevance, should order by the rank of the query before the rating", func(t *testing.T) {
+		db, queries := newDryRunDB(t)
+
+		repository.NewResourceRepository(db).SearchResources(repository.ResourceSearch{Query: "meeting room", Sort: repository.RESOURCE_SORT_RELEVANCE}, 0, 20)
+
+		if assert.Len(t, *queries, 2) {
+			assert.Regexp(t, `ORDER BY ts_rank\(.+websearch_to_tsquery\('english', \$\d+\)\) \+ word_similarity\(\$\d+, .+\) DESC, resources.rating_average DESC, resources.rating_count DESC, resources.id LIMIT`, (*queries)[1])
+		}
+	})
+
+	t.Run("When sort by price, should order by the hourly rate before the rating", func(t *testing.T) {
+		db, queries := newDryRunDB(t)
+
+		repository.NewResourceRepository(db).SearchResources(repository.ResourceSearch{Query: "hall", Sort: repository.RESOURCE_SORT_PRICE}, 0, 20)
+
+		if assert.Len(t, *queries, 2) {
+			assert.Contains(t, (*queries)[1], "ORDER BY resources.hourly_rate ASC, resources.rating_average DESC, resources.rating_count DESC, resources.id LIMIT")
+		}
+	})
+
+	t.Run("When sort by rating, should order by the rating average then the rating count", func(t *testing.T) {
+		db, queries := newDryRunDB(t)
+
+		repository.NewResourceRepository(db).SearchResources(repository.ResourceSearch{Query: "hall", Sort: repository.RESOURCE_SORT_RATING}, 0, 20)
+
+		if assert.Len(t, *queries, 2) {
+			assert.Contains(t, (*queries)[1], "ORDER BY resources.rating_average DESC, resources.rating_count DESC, resources.id LIMIT")
+		}
+	})
+
+	t.Run("When sort by distance, should order by the distance from the point before the rating", func(t *testing.T) {
+		db, queries := newDryRunDB(t)
+
+		repository.NewResourceRepository(db).SearchResources(repository.ResourceSearch{Near: &entity.GeoPoint{Latitude: 13.75, Longitude: 100.5}, Sort: repository.RESOURCE_SORT_DISTANCE}, 0, 20)
+
+		if assert.Len(t, *queries, 2) {
+			assert.Regexp(t, `ORDER BY 2 \* ASIN\(.+\) \* \$\d+ ASC NULLS LAST, resources.rating_average DESC, resources.rating_count DESC, resources.id LIMIT`, (*queries)[1])
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"booking/internal/entity"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IReviewRepository interface {
+	WithContext(ctx context.Context) IReviewRepository
+	FindReviewByID(id uuid.UUID) (review *entity.Review, err error)
+	FindReviewByBookingID(bookingID uuid.UUID) (review *entity.Review, err error)
+	// FindResourceReviews returns a page of the reviews of the resource with
+	// the status, the latest first, and how many there are in total.
+	FindResourceReviews(resourceID uuid.UUID, status string, offset int, limit int) (reviews []entity.Review, total int64, err error)
+	// CreateReview and UpdateReview save the review and update the rating of
+	// its resource in the same transaction.
+	CreateReview(review *entity.Review) (err error)
+	UpdateReview(review *entity.Review) (err error)
+}
+
+type reviewRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewRepository(db *gorm.DB) *reviewRepository {
+	return &reviewRepository{db: db}
+}
+
+func (repo *reviewRepository) WithContext(ctx context.Context) IReviewRepository {
+	return &reviewRepository{db: repo.db.WithContext(ctx)}
+}
+
+func (repo *reviewRepository) FindReviewByID(id uuid.UUID) (review *entity.Review, err error) {
+	review = &entity.Review{}
+	tx := repo.db.First(review, "id = ?", id)
+	return review, tx.Error
+}
+
+func (repo *reviewRepository) FindReviewByBookingID(bookingID uuid.UUID) (review *entity.Review, err error) {
+	review = &entity.Review{}
+	tx := repo.db.First(review, "booking_id = ?", bookingID)
+	return review, tx.Error
+}
+
+func (repo *reviewRepository) FindResourceReviews(resourceID uuid.UUID, status string, offset int, limit int) (reviews []entity.Review, total int64, err error) {
+	query := repo.db.Model(&entity.Review{}).Where("resource_id = ? AND status = ?", resourceID, status)
+	err = query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	tx := query.Order("created_at DESC").Offset(offset).Limit(limit).Find(&reviews)
+	return reviews, total, tx.Error
+}
+
+func (repo *reviewRepository) CreateReview(review *entity.Review) (err error) {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		resource, err := lockReviewedResource(tx, review.ResourceID)
+		if err != nil {
+			return err
+		}
+		err = tx.Create(review).Error
+		if err != nil {
+			return err
+		}
+		return updateResourceRating(tx, resource)
+	})
+}
+
+func (repo *reviewRepository) UpdateReview(review *entity.Review) (err error) {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		resource, err := lockReviewedResource(tx, review.ResourceID)
+		if err != nil {
+			return err
+		}
+		err = tx.Save(review).Error
+		if err != nil {
+			return err
+		}
+		return updateResourceRating(tx, resource)
+	})
+}
+
+// lockReviewedResource locks the resource before its reviews change, so that
+// the rating computed after is not missing the reviews of a concurrent
+// transaction.
+func lockReviewedResource(tx *gorm.DB, resourceID uuid.UUID) (resource *entity.Resource, err error) {
+	resource = &entity.Resource{}
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(resource, "id = ?", resourceID).Error
+	return resource, err
+}
+
+// updateResourceRating recomputes the rating of the resource from its
+// published reviews.
+func updateResourceRating(tx *gorm.DB, resource *entity.Resource) (err error) {
+	var rating struct {
+		Average float64
+		Count   int
+	}
+	err = tx.Model(&entity.Review{}).
+		Select("COALESCE(AVG(rating), 0) AS average, COUNT(*) AS count").
+		Where("resource_id = ? AND status = ?", resource.ID, entity.REVIEW_STATUS_PUBLISHED).
+		Scan(&rating).Error
+	if err != nil {
+		return err
+	}
+	return tx.Model(resource).Updates(map[string]any{"rating_average": rating.Average, "rating_count": rating.Count}).Error
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"booking/internal/entity"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IScheduleRepository interface {
+	FindOpeningHours(resourceID uuid.UUID) (hours []entity.OpeningHours, err error)
+	ReplaceOpeningHours(resourceID uuid.UUID, hours []entity.OpeningHours) (err error)
+	FindDateOverrides(resourceID uuid.UUID, fromDate string, toDate string) (overrides []entity.DateOverride, err error)
+	SaveDateOverride(override *entity.DateOverride) (err error)
+	DeleteDateOverride(resourceID uuid.UUID, date string) (deleted int64, err error)
+	FindHolidayCalendar(code string) (calendar *entity.HolidayCalendar, err error)
+	FindHolidays(calendarCode string, fromDate string, toDate string) (holidays []entity.Holiday, err error)
+	SaveHolidays(calendar *entity.HolidayCalendar, holidays []entity.Holiday) (err error)
+	FindBlackoutPeriods(resourceID uuid.UUID, startAt time.Time, endAt time.Time) (blackouts []entity.BlackoutPeriod, err error)
+	CreateBlackoutPeriod(blackout *entity.BlackoutPeriod) (err error)
+	DeleteBlackoutPeriod(resourceID uuid.UUID, id uuid.UUID) (deleted int64, err error)
+}
+
+type scheduleRepository struct {
+	db *gorm.DB
+}
+
+func NewScheduleRepository(db *gorm.DB) *scheduleRepository {
+	return &scheduleRepository{db: db}
+}
+
+func (repo *scheduleRepository) FindOpeningHours(resourceID uuid.UUID) (hours []entity.OpeningHours, err error) {
+	tx := repo.db.Where("resource_id = ?", resourceID).Order("weekday").Find(&hours)
+	return hours, tx.Error
+}
+
+func (repo *scheduleRepository) ReplaceOpeningHours(resourceID uuid.UUID, hours []entity.OpeningHours) (err error) {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("resource_id = ?", resourceID).Delete(&entity.OpeningHours{}).Error
+		if err != nil || len(hours) == 0 {
+			return err
+		}
+		return tx.Create(&hours).Error
+	})
+}
+
+// FindDateOverrides finds the overrides of the local dates from fromDate to
+// toDate inclusive.
+func (repo *scheduleRepository) FindDateOverrides(resourceID uuid.UUID, fromDate string, toDate string) (overrides []entity.DateOverride, err error) {
+	tx := repo.db.
+		Where("resource_id = ? AND date >= ? AND date <= ?", resourceID, fromDate, toDate).
+		Order("date").
+		Find(&overrides)
+	return overrides, tx.Error
+}
+
+func (repo *scheduleRepository) SaveDateOverride(override *entity.DateOverride) (err error) {
+	tx := repo.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "resource_id"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"periods", "reason", "updated_at"}),
+	}).Create(override)
+	return tx.Error
+}
+
+func (repo *scheduleRepository) DeleteDateOverride(resourceID uuid.UUID, date string) (deleted int64, err error) {
+	tx := repo.db.Where("resource_id = ? AND date = ?", resourceID, date).Delete(&entity.DateOverride{})
+	return tx.RowsAffected, tx.Error
+}
+
+func (repo *scheduleRepository) FindHolidayCalendar(code string) (calendar *entity.HolidayCalendar, err error) {
+	calendar = &entity.HolidayCalendar{}
+	tx := repo.db.First(calendar, "code = ?", code)
+	return calendar, tx.Error
+}
+
+func (repo *scheduleRepository) FindHolidays(calendarCode string, fromDate string, toDate string) (holidays []entity.Holiday, err error) {
+	tx := repo.db.
+		Where("calendar_code = ? AND date >= ? AND date <= ?", calendarCode, fromDate, toDate).
+		Order("date").
+		Find(&holidays)
+	return holidays, tx.Error
+}
+
+// SaveHolidays creates the calendar and the holidays it doesn't have yet, so
+// seeding it again is harmless.
+func (repo *scheduleRepository) SaveHolidays(calendar *entity.HolidayCalendar, holidays []entity.Holiday) (err error) {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(calendar).Error
+		if err != nil || len(holidays) == 0 {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&holidays).Error
+	})
+}
+
+func (repo *scheduleRepository) FindBlackoutPeriods(resourceID uuid.UUID, startAt time.Time, endAt time.Time) (blackouts []entity.BlackoutPeriod, err error) {
+	tx := repo.db.
+		Where("resource_id = ? AND start_at < ? AND end_at > ?", resourceID, endAt, startAt).
+		Order("start_at").
+		Find(&blackouts)
+	return blackouts, tx.Error
+}
+
+func (repo *scheduleRepository) CreateBlackoutPeriod(blackout *entity.BlackoutPeriod) (err error) {
+	tx := repo.db.Create(blackout)
+	return tx.Error
+}
+
+func (repo *scheduleRepository) DeleteBlackoutPeriod(resourceID uuid.UUID, id uuid.UUID) (deleted int64, err error) {
+	tx := repo.db.Where("resource_id = ? AND id = ?", resourceID, id).Delete(&entity.BlackoutPeriod{})
+	return tx.RowsAffected, tx.Error
+}
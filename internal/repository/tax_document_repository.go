@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"booking/internal/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ITaxDocumentRepository interface {
+	WithTx(tx *gorm.DB) ITaxDocumentRepository
+	FindBillingProfileByUserID(userID uuid.UUID) (profile *entity.BillingProfile, err error)
+	SaveBillingProfile(profile *entity.BillingProfile) (err error)
+	FindOrganizationBillingProfile(organizationID uuid.UUID) (profile *entity.OrganizationBillingProfile, err error)
+	SaveOrganizationBillingProfile(profile *entity.OrganizationBillingProfile) (err error)
+	FindTaxDocument(bookingID uuid.UUID, documentType string) (document *entity.TaxDocument, err error)
+	// NextDocumentNumber takes the next number of the documents of the type
+	// the organization issues in the year. The sequence stays locked until the
+	// transaction ends, so a rolled back document gives its number back.
+	NextDocumentNumber(organizationID uuid.UUID, documentType string, year int) (number int64, err error)
+	CreateTaxDocument(document *entity.TaxDocument) (err error)
+}
+
+type taxDocumentRepository struct {
+	db *gorm.DB
+}
+
+func NewTaxDocumentRepository(db *gorm.DB) *taxDocumentRepository {
+	return &taxDocumentRepository{db: db}
+}
+
+func (repo *taxDocumentRepository) WithTx(tx *gorm.DB) ITaxDocumentRepository {
+	return &taxDocumentRepository{db: tx}
+}
+
+func (repo *taxDocumentRepository) FindBillingProfileByUserID(userID uuid.UUID) (profile *entity.BillingProfile, err error) {
+	profile = &entity.BillingProfile{}
+	tx := repo.db.First(profile, "user_id = ?", userID)
+	return profile, tx.Error
+}
+
+func (repo *taxDocumentRepository) SaveBillingProfile(profile *entity.BillingProfile) (err error) {
+	tx := repo.db.Save(profile)
+	return tx.Error
+}
+
+func (repo *taxDocumentRepository) FindOrganizationBillingProfile(organizationID uuid.UUID) (profile *entity.OrganizationBillingProfile, err error) {
+	profile = &entity.OrganizationBillingProfile{}
+	tx := repo.db.First(profile, "organization_id = ?", organizationID)
+	return profile, tx.Error
+}
+
+func (repo *taxDocumentRepository) SaveOrganizationBillingProfile(profile *entity.OrganizationBillingProfile) (err error) {
+	tx := repo.db.Save(profile)
+	return tx.Error
+}
+
+func (repo *taxDocumentRepository) FindTaxDocument(bookingID uuid.UUID, documentType string) (document *entity.TaxDocument, err error) {
+	document = &entity.TaxDocument{}
+	tx := repo.db.First(document, "booking_id = ? AND type = ?", bookingID, documentType)
+	return document, tx.Error
+}
+
+func (repo *taxDocumentRepository) NextDocumentNumber(organizationID uuid.UUID, documentType string, year int) (number int64, err error) {
+	tx := repo.db.Raw(`
+INSERT INTO document_sequences (organization_id, type, year, last_number) VALUES (?, ?, ?, 1)
+ON CONFLICT (organization_id, type, year) DO UPDATE SET last_number = document_sequences.last_number + 1
+RETURNING last_number`, organizationID, documentType, year).Scan(&number)
+	return number, tx.Error
+}
+
+func (repo *taxDocumentRepository) CreateTaxDocument(document *entity.TaxDocument) (err error) {
+	tx := repo.db.Create(document)
+	return tx.Error
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"booking/internal/entity"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IWaitlistRepository interface {
+	WithContext(ctx context.Context) IWaitlistRepository
+	WithTx(tx *gorm.DB) IWaitlistRepository
+	CreateEntry(entry *entity.WaitlistEntry) (err error)
+	FindEntryByID(id uuid.UUID) (entry *entity.WaitlistEntry, err error)
+	LockEntryByID(id uuid.UUID) (entry *entity.WaitlistEntry, err error)
+	UpdateEntry(entry *entity.WaitlistEntry) (err error)
+	FindEntriesByUserID(userID uuid.UUID) (entries []entity.WaitlistEntry, err error)
+	FindWaitingEntries(resourceID uuid.UUID) (entries []entity.WaitlistEntry, err error)
+	ExpireEntries(resourceID uuid.UUID, now time.Time) (err error)
+	FindActiveOffers(resourceID uuid.UUID, startAt time.Time, endAt time.Time, now time.Time, excludedUserID uuid.UUID) (entries []entity.WaitlistEntry, err error)
+	CountActiveEntriesOfUser(userID uuid.UUID, resourceID uuid.UUID, startAt time.Time, endAt time.Time) (count int64, err error)
+	CountWaitingAhead(entry *entity.WaitlistEntry) (count int64, err error)
+}
+
+type waitlistRepository struct {
+	db *gorm.DB
+}
+
+func NewWaitlistRepository(db *gorm.DB) *waitlistRepository {
+	return &waitlistRepository{db: db}
+}
+
+func (repo *waitlistRepository) WithContext(ctx context.Context) IWaitlistRepository {
+	return &waitlistRepository{db: repo.db.WithContext(ctx)}
+}
+
+func (repo *waitlistRepository) WithTx(tx *gorm.DB) IWaitlistRepository {
+	return &waitlistRepository{db: tx}
+}
+
+func (repo *waitlistRepository) CreateEntry(entry *entity.WaitlistEntry) (err error) {
+	tx := repo.db.Create(entry)
+	return tx.Error
+}
+
+func (repo *waitlistRepository) FindEntryByID(id uuid.UUID) (entry *entity.WaitlistEntry, err error) {
+	entry = &entity.WaitlistEntry{}
+	tx := repo.db.First(entry, "id = ?", id)
+	return entry, tx.Error
+}
+
+func (repo *waitlistRepository) LockEntryByID(id uuid.UUID) (entry *entity.WaitlistEntry, err error) {
+	entry = &entity.WaitlistEntry{}
+	tx := repo.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(entry, "id = ?", id)
+	return entry, tx.Error
+}
+
+func (repo *waitlistRepository) UpdateEntry(entry *entity.WaitlistEntry) (err error) {
+	tx := repo.db.Save(entry)
+	return tx.Error
+}
+
+func (repo *waitlistRepository) FindEntriesByUserID(userID uuid.UUID) (entries []entity.WaitlistEntry, err error) {
+	tx := repo.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&entries)
+	return entries, tx.Error
+}
+
+func (repo *waitlistRepository) FindWaitingEntries(resourceID uuid.UUID) (entries []entity.WaitlistEntry, err error) {
+	tx := repo.db.
+		Where("resource_id = ? AND status = ?", resourceID, entity.WAITLIST_STATUS_WAITING).
+		Order("created_at").
+		Find(&entries)
+	return entries, tx.Error
+}
+
+// ExpireEntries expires the offers past their claim time and the entries whose
+// slot has already started.
+func (repo *waitlistRepository) ExpireEntries(resourceID uuid.UUID, now time.Time) (err error) {
+	tx := repo.db.Model(&entity.WaitlistEntry{}).
+		Where("resource_id = ?", resourceID).
+		Where(
+			repo.db.Where("status = ? AND offer_expires_at <= ?", entity.WAITLIST_STATUS_OFFERED, now).
+				Or("status IN ? AND start_at <= ?", []string{entity.WAITLIST_STATUS_WAITING, entity.WAITLIST_STATUS_OFFERED}, now),
+		).
+		Update("status", entity.WAITLIST_STATUS_EXPIRED)
+	return tx.Error
+}
+
+func (repo *waitlistRepository) FindActiveOffers(resourceID uuid.UUID, startAt time.Time, endAt time.Time, now time.Time, excludedUserID uuid.UUID) (entries []entity.WaitlistEntry, err error) {
+	tx := repo.db.
+		Where("resource_id = ? AND status = ? AND offer_expires_at > ?", resourceID, entity.WAITLIST_STATUS_OFFERED, now).
+		Where("start_at < ? AND end_at > ?", endAt, startAt).
+		Where("user_id <> ?", excludedUserID).
+		Find(&entries)
+	return entries, tx.Error
+}
+
+func (repo *waitlistRepository) CountActiveEntriesOfUser(userID uuid.UUID, resourceID uuid.UUID, startAt time.Time, endAt time.Time) (count int64, err error) {
+	tx := repo.db.Model(&entity.WaitlistEntry{}).
+		Where("user_id = ? AND resource_id = ?", userID, resourceID).
+		Where("status IN ?", []string{entity.WAITLIST_STATUS_WAITING, entity.WAITLIST_STATUS_OFFERED}).
+		Where("start_at < ? AND end_at > ?", endAt, startAt).
+		Count(&count)
+	return count, tx.Error
+}
+
+// CountWaitingAhead counts the entries queued earlier for an overlapping range
+// of the same resource.
+func (repo *waitlistRepository) CountWaitingAhead(entry *entity.WaitlistEntry) (count int64, err error) {
+	tx := repo.db.Model(&entity.WaitlistEntry{}).
+		Where("resource_id = ? AND status = ? AND created_at < ?", entry.ResourceID, entity.WAITLIST_STATUS_WAITING, entry.CreatedAt).
+		Where("start_at < ? AND end_at > ?", entry.EndAt, entry.StartAt).
+		Count(&count)
+	return count, tx.Error
+}
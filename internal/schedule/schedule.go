@@ -0,0 +1,123 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DATE_LAYOUT formats the local dates overrides and holidays are keyed by.
+const DATE_LAYOUT = "2006-01-02"
+
+var (
+	ErrClosed   = errors.New("the resource is closed")
+	ErrBlackout = errors.New("the resource is blacked out")
+)
+
+// Period is an opening period of a day in minutes since local midnight.
+// ClosesAt may be 1440 for periods open until the end of the day.
+type Period struct {
+	OpensAt  int
+	ClosesAt int
+}
+
+type Range struct {
+	StartAt time.Time
+	EndAt   time.Time
+}
+
+// Schedule is when a resource can be booked, in the time zone of the resource.
+type Schedule struct {
+	Location *time.Location
+	// Weekly lists the opening periods of each weekday. A schedule without
+	// weekly hours is open around the clock, a weekday without periods is
+	// closed.
+	Weekly map[time.Weekday][]Period
+	// Overrides replace the periods of a local date. A date overridden with
+	// no periods is closed.
+	Overrides map[string][]Period
+	// Holidays are local dates closed unless they are overridden.
+	Holidays map[string]bool
+	// Blackouts are ad-hoc periods the resource cannot be booked.
+	Blackouts []Range
+}
+
+// ParseClock parses "15:04" into minutes since midnight. "24:00" is the end
+// of the day.
+func ParseClock(clock string) (int, error) {
+	if clock == "24:00" {
+		return 24 * 60, nil
+	}
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q", clock)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// FormatClock formats minutes since midnight as "15:04".
+func FormatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// PeriodsOn returns the opening periods of the local date.
+func (schedule *Schedule) PeriodsOn(date time.Time) []Period {
+	key := date.Format(DATE_LAYOUT)
+	if periods, ok := schedule.Overrides[key]; ok {
+		return periods
+	}
+	if schedule.Holidays[key] {
+		return nil
+	}
+	if len(schedule.Weekly) == 0 {
+		return []Period{{OpensAt: 0, ClosesAt: 24 * 60}}
+	}
+	return schedule.Weekly[date.Weekday()]
+}
+
+// openRanges returns the periods the resource is open on the local dates
+// from startAt to endAt, in order.
+func (schedule *Schedule) openRanges(startAt time.Time, endAt time.Time) []Range {
+	local := startAt.In(schedule.Location)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, schedule.Location)
+	ranges := []Range{}
+	for day.Before(endAt) {
+		for _, period := range schedule.PeriodsOn(day) {
+			ranges = append(ranges, Range{
+				StartAt: time.Date(day.Year(), day.Month(), day.Day(), 0, period.OpensAt, 0, 0, schedule.Location),
+				EndAt:   time.Date(day.Year(), day.Month(), day.Day(), 0, period.ClosesAt, 0, 0, schedule.Location),
+			})
+		}
+		day = time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, schedule.Location)
+	}
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].StartAt.Before(ranges[j].StartAt)
+	})
+	return ranges
+}
+
+// Check returns ErrClosed when the resource is not open for the whole period,
+// which may span consecutive opening periods, or ErrBlackout when the period
+// overlaps a blackout.
+func (schedule *Schedule) Check(startAt time.Time, endAt time.Time) error {
+	covered := startAt
+	for _, open := range schedule.openRanges(startAt, endAt) {
+		if !open.StartAt.After(covered) && open.EndAt.After(covered) {
+			covered = open.EndAt
+		}
+		if !covered.Before(endAt) {
+			break
+		}
+	}
+	if covered.Before(endAt) {
+		return ErrClosed
+	}
+
+	for _, blackout := range schedule.Blackouts {
+		if blackout.StartAt.Before(endAt) && blackout.EndAt.After(startAt) {
+			return ErrBlackout
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package schedule_test
+
+import (
+	"booking/internal/schedule"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	bangkok, _ := time.LoadLocation("Asia/Bangkok")
+	// 2024-01-01 is a Monday
+	monday := time.Date(2024, 1, 1, 0, 0, 0, 0, bangkok)
+	at := func(day int, hour int) time.Time {
+		return monday.AddDate(0, 0, day).Add(time.Duration(hour) * time.Hour)
+	}
+	newSchedule := func() *schedule.Schedule {
+		return &schedule.Schedule{
+			Location: bangkok,
+			Weekly: map[time.Weekday][]schedule.Period{
+				time.Monday:    {{OpensAt: 9 * 60, ClosesAt: 12 * 60}, {OpensAt: 13 * 60, ClosesAt: 18 * 60}},
+				time.Tuesday:   {{OpensAt: 9 * 60, ClosesAt: 24 * 60}},
+				time.Wednesday: {{OpensAt: 0, ClosesAt: 6 * 60}},
+			},
+			Overrides: map[string][]schedule.Period{},
+			Holidays:  map[string]bool{},
+		}
+	}
+
+	t.Run("When the period is within the opening hours, should be open", func(t *testing.T) {
+		assert.NoError(t, newSchedule().Check(at(0, 9), at(0, 12)))
+	})
+
+	t.Run("When the period spans the lunch break, should be closed", func(t *testing.T) {
+		assert.ErrorIs(t, newSchedule().Check(at(0, 11), at(0, 14)), schedule.ErrClosed)
+	})
+
+	t.Run("When the period runs past midnight into the next opening period, should be open", func(t *testing.T) {
+		assert.NoError(t, newSchedule().Check(at(1, 22), at(2, 2)))
+	})
+
+	t.Run("When the weekday has no opening hours, should be closed", func(t *testing.T) {
+		assert.ErrorIs(t, newSchedule().Check(at(3, 10), at(3, 11)), schedule.ErrClosed)
+	})
+
+	t.Run("When the time is given in another time zone, should check it in the time zone of the schedule", func(t *testing.T) {
+		// 02:00 UTC is 09:00 in Bangkok
+		startAt := time.Date(2024, 1, 1, 2, 0, 0, 0, time.UTC)
+
+		assert.NoError(t, newSchedule().Check(startAt, startAt.Add(time.Hour)))
+		assert.ErrorIs(t, newSchedule().Check(startAt.Add(-time.Hour), startAt), schedule.ErrClosed)
+	})
+
+	t.Run("When the date is a holiday, should be closed unless it is overridden", func(t *testing.T) {
+		s := newSchedule()
+		s.Holidays["2024-01-01"] = true
+		assert.ErrorIs(t, s.Check(at(0, 9), at(0, 10)), schedule.ErrClosed)
+
+		s.Overrides["2024-01-01"] = []schedule.Period{{OpensAt: 8 * 60, ClosesAt: 10 * 60}}
+		assert.NoError(t, s.Check(at(0, 8), at(0, 10)))
+	})
+
+	t.Run("When the date is overridden without opening hours, should be closed", func(t *testing.T) {
+		s := newSchedule()
+		s.Overrides["2024-01-02"] = []schedule.Period{}
+
+		assert.ErrorIs(t, s.Check(at(1, 10), at(1, 11)), schedule.ErrClosed)
+	})
+
+	t.Run("When the period overlaps a blackout, should be blacked out", func(t *testing.T) {
+		s := newSchedule()
+		s.Blackouts = []schedule.Range{{StartAt: at(0, 10), EndAt: at(0, 11)}}
+
+		assert.ErrorIs(t, s.Check(at(0, 9), at(0, 12)), schedule.ErrBlackout)
+		assert.NoError(t, s.Check(at(0, 11), at(0, 12)))
+	})
+
+	t.Run("When the schedule has no weekly hours, should be open around the clock", func(t *testing.T) {
+		s := &schedule.Schedule{Location: bangkok}
+
+		assert.NoError(t, s.Check(at(5, 23), at(6, 3)))
+	})
+}
+
+func TestThaiPublicHolidays(t *testing.T) {
+	holidays := map[string]string{}
+	thaiPublicHolidays, ok := schedule.ThaiPublicHolidays(2025, nil)
+	for _, holiday := range thaiPublicHolidays {
+		holidays[holiday.Date] = holiday.Name
+	}
+
+	t.Run("When a holiday falls on a weekend, should add a substitution day on the next working day", func(t *testing.T) {
+		// Songkran 2025-04-13 is a Sunday, the 14th and 15th are holidays already
+		assert.Equal(t, "Substitution for Songkran Festival", holidays["2025-04-16"])
+		assert.Equal(t, "Substitution for Coronation Day", holidays["2025-05-05"])
+	})
+
+	t.Run("When the year has announced Buddhist holidays, should include them", func(t *testing.T) {
+		assert.True(t, ok)
+		assert.Equal(t, "Makha Bucha Day", holidays["2025-02-12"])
+		assert.Equal(t, "New Year's Day", holidays["2025-01-01"])
+	})
+
+	t.Run("When the Buddhist holidays of the year are configured, should use them instead", func(t *testing.T) {
+		configured := []schedule.Holiday{
+			{Date: "2025-02-13", Name: "Makha Bucha Day", NameTH: "วันมาฆบูชา"},
+			{Date: "2028-02-10", Name: "Makha Bucha Day", NameTH: "วันมาฆบูชา"},
+		}
+
+		thaiPublicHolidays, ok := schedule.ThaiPublicHolidays(2025, configured)
+
+		assert.True(t, ok)
+		dates := []string{}
+		for _, holiday := range thaiPublicHolidays {
+			if holiday.Name == "Makha Bucha Day" {
+				dates = append(dates, holiday.Date)
+			}
+		}
+		assert.Equal(t, []string{"2025-02-13"}, dates)
+	})
+
+	t.Run("When the Buddhist holidays of the year are not known, should not be ok", func(t *testing.T) {
+		thaiPublicHolidays, ok := schedule.ThaiPublicHolidays(2099, nil)
+
+		assert.False(t, ok)
+		assert.Equal(t, "2099-01-01", thaiPublicHolidays[0].Date)
+	})
+}
@@ -0,0 +1,128 @@
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const THAI_HOLIDAY_CALENDAR = "TH"
+
+type Holiday struct {
+	Date   string `mapstructure:"date"`
+	Name   string `mapstructure:"name"`
+	NameTH string `mapstructure:"name_th"`
+}
+
+type annualHoliday struct {
+	month  time.Month
+	day    int
+	name   string
+	nameTH string
+}
+
+var thaiAnnualHolidays = []annualHoliday{
+	{time.January, 1, "New Year's Day", "วันขึ้นปีใหม่"},
+	{time.April, 6, "Chakri Memorial Day", "วันจักรี"},
+	{time.April, 13, "Songkran Festival", "วันสงกรานต์"},
+	{time.April, 14, "Songkran Festival", "วันสงกรานต์"},
+	{time.April, 15, "Songkran Festival", "วันสงกรานต์"},
+	{time.May, 1, "National Labour Day", "วันแรงงานแห่งชาติ"},
+	{time.May, 4, "Coronation Day", "วันฉัตรมงคล"},
+	{time.June, 3, "H.M. Queen Suthida's Birthday", "วันเฉลิมพระชนมพรรษาสมเด็จพระนางเจ้าฯ พระบรมราชินี"},
+	{time.July, 28, "H.M. King Maha Vajiralongkorn's Birthday", "วันเฉลิมพระชนมพรรษาพระบาทสมเด็จพระเจ้าอยู่หัว"},
+	{time.August, 12, "H.M. Queen Sirikit The Queen Mother's Birthday and Mother's Day", "วันแม่แห่งชาติ"},
+	{time.October, 13, "H.M. King Bhumibol Adulyadej The Great Memorial Day", "วันนวมินทรมหาราช"},
+	{time.October, 23, "King Chulalongkorn Memorial Day", "วันปิยมหาราช"},
+	{time.December, 5, "H.M. King Bhumibol Adulyadej The Great's Birthday and Father's Day", "วันพ่อแห่งชาติ"},
+	{time.December, 10, "Constitution Day", "วันรัฐธรรมนูญ"},
+	{time.December, 31, "New Year's Eve", "วันสิ้นปี"},
+}
+
+// thaiBuddhistHolidays follow the lunar calendar and are announced each year.
+// Add the dates of a new year here once they are announced, or configure them
+// until then.
+var thaiBuddhistHolidays = map[int][]Holiday{
+	2024: {
+		{Date: "2024-02-24", Name: "Makha Bucha Day", NameTH: "วันมาฆบูชา"},
+		{Date: "2024-05-22", Name: "Visakha Bucha Day", NameTH: "วันวิสาขบูชา"},
+		{Date: "2024-07-20", Name: "Asarnha Bucha Day", NameTH: "วันอาสาฬหบูชา"},
+		{Date: "2024-07-21", Name: "Buddhist Lent Day", NameTH: "วันเข้าพรรษา"},
+	},
+	2025: {
+		{Date: "2025-02-12", Name: "Makha Bucha Day", NameTH: "วันมาฆบูชา"},
+		{Date: "2025-05-11", Name: "Visakha Bucha Day", NameTH: "วันวิสาขบูชา"},
+		{Date: "2025-07-10", Name: "Asarnha Bucha Day", NameTH: "วันอาสาฬหบูชา"},
+		{Date: "2025-07-11", Name: "Buddhist Lent Day", NameTH: "วันเข้าพรรษา"},
+	},
+	2026: {
+		{Date: "2026-03-03", Name: "Makha Bucha Day", NameTH: "วันมาฆบูชา"},
+		{Date: "2026-05-31", Name: "Visakha Bucha Day", NameTH: "วันวิสาขบูชา"},
+		{Date: "2026-07-29", Name: "Asarnha Bucha Day", NameTH: "วันอาสาฬหบูชา"},
+		{Date: "2026-07-30", Name: "Buddhist Lent Day", NameTH: "วันเข้าพรรษา"},
+	},
+	2027: {
+		{Date: "2027-02-21", Name: "Makha Bucha Day", NameTH: "วันมาฆบูชา"},
+		{Date: "2027-05-20", Name: "Visakha Bucha Day", NameTH: "วันวิสาขบูชา"},
+		{Date: "2027-07-18", Name: "Asarnha Bucha Day", NameTH: "วันอาสาฬหบูชา"},
+		{Date: "2027-07-19", Name: "Buddhist Lent Day", NameTH: "วันเข้าพรรษา"},
+	},
+}
+
+// ThaiPublicHolidays returns the Thai public holidays of the year. The Buddhist
+// holidays are the configured ones of the year, or the built-in ones when none
+// are configured, and ok is false when the year has neither. A holiday falling
+// on a weekend is followed by a substitution day on the next working day.
+func ThaiPublicHolidays(year int, configured []Holiday) (holidays []Holiday, ok bool) {
+	buddhistHolidays := []Holiday{}
+	prefix := fmt.Sprintf("%04d-", year)
+	for _, holiday := range configured {
+		if strings.HasPrefix(holiday.Date, prefix) {
+			buddhistHolidays = append(buddhistHolidays, holiday)
+		}
+	}
+	if len(buddhistHolidays) == 0 {
+		buddhistHolidays = thaiBuddhistHolidays[year]
+	}
+
+	holidays = []Holiday{}
+	for _, holiday := range thaiAnnualHolidays {
+		holidays = append(holidays, Holiday{
+			Date:   time.Date(year, holiday.month, holiday.day, 0, 0, 0, 0, time.UTC).Format(DATE_LAYOUT),
+			Name:   holiday.name,
+			NameTH: holiday.nameTH,
+		})
+	}
+	holidays = append(holidays, buddhistHolidays...)
+	sort.Slice(holidays, func(i, j int) bool {
+		return holidays[i].Date < holidays[j].Date
+	})
+
+	taken := map[string]bool{}
+	for _, holiday := range holidays {
+		taken[holiday.Date] = true
+	}
+	substitutions := []Holiday{}
+	for _, holiday := range holidays {
+		date, _ := time.Parse(DATE_LAYOUT, holiday.Date)
+		if date.Weekday() != time.Saturday && date.Weekday() != time.Sunday {
+			continue
+		}
+		for taken[date.Format(DATE_LAYOUT)] || date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+			date = date.AddDate(0, 0, 1)
+		}
+		taken[date.Format(DATE_LAYOUT)] = true
+		substitutions = append(substitutions, Holiday{
+			Date:   date.Format(DATE_LAYOUT),
+			Name:   "Substitution for " + holiday.Name,
+			NameTH: "ชดเชย" + holiday.NameTH,
+		})
+	}
+
+	holidays = append(holidays, substitutions...)
+	sort.SliceStable(holidays, func(i, j int) bool {
+		return holidays[i].Date < holidays[j].Date
+	})
+	return holidays, len(buddhistHolidays) > 0
+}
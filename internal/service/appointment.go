@@ -0,0 +1,231 @@
+package service
+
+import (
+	"booking/internal/constant"
+	"booking/internal/entity"
+	"booking/internal/repository"
+	"booking/internal/schedule"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	errServiceNotFound     = errors.New(constant.ERROR_MESSAGE_SERVICE_NOT_FOUND)
+	errProviderNotFound    = errors.New(constant.ERROR_MESSAGE_PROVIDER_NOT_FOUND)
+	errProviderUnavailable = errors.New(constant.ERROR_MESSAGE_PROVIDER_UNAVAILABLE)
+)
+
+// appointment is a booking of a service with the providers it may be
+// assigned to.
+type appointment struct {
+	service *entity.Service
+	// providers perform the service, in the order of their IDs.
+	providers []entity.ProviderService
+}
+
+// longest returns the longest time one of the providers takes for the
+// service.
+func (appointment *appointment) longest() time.Duration {
+	longest := time.Duration(0)
+	for i := range appointment.providers {
+		longest = max(longest, appointment.providers[i].Duration(appointment.service))
+	}
+	return longest
+}
+
+// findAppointment finds the service and the providers performing it, only the
+// given provider if one is.
+func findAppointment(providerRepository repository.IProviderRepository, serviceID uuid.UUID, providerID *uuid.UUID) (*appointment, error) {
+	service, err := providerRepository.FindServiceByID(serviceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errServiceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	providerServices, err := providerRepository.FindProviderServicesByServiceID(service.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	found := &appointment{service: service}
+	for _, providerService := range providerServices {
+		if providerID == nil || providerService.ProviderID == *providerID {
+			found.providers = append(found.providers, providerService)
+		}
+	}
+	if providerID != nil && len(found.providers) == 0 {
+		return nil, errProviderNotFound
+	}
+	if len(found.providers) == 0 {
+		return nil, errProviderUnavailable
+	}
+	return found, nil
+}
+
+// loadProviderSchedule loads when the provider works between startAt and
+// endAt, their time off and the given appointments blocking it.
+func loadProviderSchedule(providerRepository repository.IProviderRepository, provider *entity.Provider, appointments []entity.Booking, startAt time.Time, endAt time.Time) (*schedule.Schedule, error) {
+	providerSchedule := &schedule.Schedule{
+		Location: provider.Location(),
+		Weekly:   map[time.Weekday][]schedule.Period{},
+	}
+	hours, err := providerRepository.FindProviderHours(provider.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, weekdayHours := range hours {
+		providerSchedule.Weekly[time.Weekday(weekdayHours.Weekday)] = toSchedulePeriods(weekdayHours.Periods)
+	}
+
+	timeOff, err := providerRepository.FindProviderTimeOff(provider.ID, startAt, endAt)
+	if err != nil {
+		return nil, err
+	}
+	for _, period := range timeOff {
+		providerSchedule.Blackouts = append(providerSchedule.Blackouts, schedule.Range{StartAt: period.StartAt, EndAt: period.EndAt})
+	}
+	for _, booking := range appointments {
+		providerSchedule.Blackouts = append(providerSchedule.Blackouts, schedule.Range{StartAt: booking.StartAt, EndAt: booking.EndAt})
+	}
+	return providerSchedule, nil
+}
+
+// localDay returns the local day of the time in the location.
+func localDay(at time.Time, location *time.Location) (startAt time.Time, endAt time.Time) {
+	local := at.In(location)
+	startAt = time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location)
+	return startAt, time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, location)
+}
+
+// bookedMinutes returns how long the appointments take between startAt and
+// endAt.
+func bookedMinutes(appointments []entity.Booking, startAt time.Time, endAt time.Time) int {
+	minutes := 0
+	for _, booking := range appointments {
+		from, to := booking.StartAt, booking.EndAt
+		if from.Before(startAt) {
+			from = startAt
+		}
+		if to.After(endAt) {
+			to = endAt
+		}
+		if to.After(from) {
+			minutes += int(to.Sub(from) / time.Minute)
+		}
+	}
+	return minutes
+}
+
+// assignProvider assigns the appointment to a provider free from its start for
+// as long as they take, with a seat of the resource free for that time, and
+// sets its end. The work is spread by assigning the provider with the fewest
+// minutes booked on the day, the first in the order of IDs on a tie. The
+// providers are locked until the transaction ends so that concurrent
+// appointments aren't assigned the same time.
+func assignProvider(bookingRepository repository.IBookingRepository, waitlistRepository repository.IWaitlistRepository, providerRepository repository.IProviderRepository, resource *entity.Resource, resourceSchedule *schedule.Schedule, appointment *appointment, booking *entity.Booking, now time.Time) error {
+	providerIDs := []uuid.UUID{}
+	for _, providerService := range appointment.providers {
+		providerIDs = append(providerIDs, providerService.ProviderID)
+	}
+	providers, err := providerRepository.LockProvidersByIDs(providerIDs)
+	if err != nil {
+		return err
+	}
+	dayStartAt, dayEndAt := localDay(booking.StartAt, resource.Location())
+	searchEndAt := booking.StartAt.Add(appointment.longest())
+	if searchEndAt.Before(dayEndAt) {
+		searchEndAt = dayEndAt
+	}
+	appointments, err := bookingRepository.FindProviderBookings(providerIDs, dayStartAt, searchEndAt)
+	if err != nil {
+		return err
+	}
+	appointmentsOf := map[uuid.UUID][]entity.Booking{}
+	for _, other := range appointments {
+		appointmentsOf[*other.ProviderID] = append(appointmentsOf[*other.ProviderID], other)
+	}
+
+	var assigned *entity.ProviderService
+	assignedMinutes := 0
+	resourceConflict := ""
+	providerBusy := false
+	for i := range providers {
+		providerService := providerServiceOf(appointment, providers[i].ID)
+		endAt := booking.StartAt.Add(providerService.Duration(appointment.service))
+		reason, err := findSlotConflict(bookingRepository, waitlistRepository, resource, resourceSchedule, booking.StartAt, endAt, booking.Seats, now, booking.UserID, nil)
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			resourceConflict = reason
+			continue
+		}
+		providerSchedule, err := loadProviderSchedule(providerRepository, &providers[i], appointmentsOf[providers[i].ID], booking.StartAt, endAt)
+		if err != nil {
+			return err
+		}
+		if providerSchedule.Check(booking.StartAt, endAt) != nil {
+			providerBusy = true
+			continue
+		}
+		minutes := bookedMinutes(appointmentsOf[providers[i].ID], dayStartAt, dayEndAt)
+		if assigned == nil || minutes < assignedMinutes {
+			assigned, assignedMinutes = providerService, minutes
+		}
+	}
+	if assigned == nil {
+		if resourceConflict != "" && !providerBusy {
+			return slotConflictError(resourceConflict)
+		}
+		return errProviderUnavailable
+	}
+
+	booking.EndAt = booking.StartAt.Add(assigned.Duration(appointment.service))
+	booking.ServiceID = &appointment.service.ID
+	booking.ProviderID = &assigned.ProviderID
+	return nil
+}
+
+func providerServiceOf(appointment *appointment, providerID uuid.UUID) *entity.ProviderService {
+	for i := range appointment.providers {
+		if appointment.providers[i].ProviderID == providerID {
+			return &appointment.providers[i]
+		}
+	}
+	return nil
+}
+
+// checkProviderFree returns errProviderUnavailable unless the provider of the
+// appointment is free between startAt and endAt, other than for the
+// appointment itself.
+func checkProviderFree(bookingRepository repository.IBookingRepository, providerRepository repository.IProviderRepository, booking *entity.Booking, startAt time.Time, endAt time.Time) error {
+	providers, err := providerRepository.LockProvidersByIDs([]uuid.UUID{*booking.ProviderID})
+	if err != nil {
+		return err
+	}
+	if len(providers) == 0 {
+		return errProviderUnavailable
+	}
+	appointments, err := bookingRepository.FindProviderBookings([]uuid.UUID{*booking.ProviderID}, startAt, endAt)
+	if err != nil {
+		return err
+	}
+	others := []entity.Booking{}
+	for _, other := range appointments {
+		if other.ID != booking.ID {
+			others = append(others, other)
+		}
+	}
+	providerSchedule, err := loadProviderSchedule(providerRepository, &providers[0], others, startAt, endAt)
+	if err != nil {
+		return err
+	}
+	if providerSchedule.Check(startAt, endAt) != nil {
+		return errProviderUnavailable
+	}
+	return nil
+}
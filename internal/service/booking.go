@@ -0,0 +1,216 @@
+package service
+
+import (
+	"booking/internal/constant"
+	"booking/internal/entity"
+	"booking/internal/logs"
+	"booking/internal/pricing"
+	"booking/internal/repository"
+	"booking/internal/schedule"
+	"booking/internal/vo"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	errBookingSlotUnavailable   = errors.New(constant.ERROR_MESSAGE_BOOKING_SLOT_UNAVAILABLE)
+	errBookingNotFound          = errors.New(constant.ERROR_MESSAGE_BOOKING_NOT_FOUND)
+	errBookingAlreadyCancelled  = errors.New(constant.ERROR_MESSAGE_BOOKING_ALREADY_CANCELLED)
+	errBookingAlreadyStarted    = errors.New(constant.ERROR_MESSAGE_BOOKING_ALREADY_STARTED)
+	errBookingSeriesNotFound    = errors.New(constant.ERROR_MESSAGE_BOOKING_SERIES_NOT_FOUND)
+	errBookingSeriesDateChanged = errors.New(constant.ERROR_MESSAGE_BOOKING_SERIES_DATE_CHANGED)
+	errInvalidRecurrenceRule    = errors.New(constant.ERROR_MESSAGE_INVALID_RECURRENCE_RULE)
+	errSeatsExceedCapacity      = errors.New(constant.ERROR_MESSAGE_SEATS_EXCEED_CAPACITY)
+	errResourceClosed           = errors.New(constant.ERROR_MESSAGE_RESOURCE_CLOSED)
+	errResourceBlackedOut       = errors.New(constant.ERROR_MESSAGE_RESOURCE_BLACKED_OUT)
+	errRescheduleNotAllowed     = errors.New(constant.ERROR_MESSAGE_RESCHEDULE_NOT_ALLOWED)
+	errRescheduleCutoffPassed   = errors.New(constant.ERROR_MESSAGE_RESCHEDULE_CUTOFF_PASSED)
+	errRescheduleLimitReached   = errors.New(constant.ERROR_MESSAGE_RESCHEDULE_LIMIT_REACHED)
+	errRescheduleSameSlot       = errors.New(constant.ERROR_MESSAGE_RESCHEDULE_SAME_SLOT)
+)
+
+// occurrenceConflictsError lists the occurrences of a recurring booking that
+// cannot be booked.
+type occurrenceConflictsError struct {
+	conflicts []vo.OccurrenceConflict
+}
+
+func (err *occurrenceConflictsError) Error() string {
+	return constant.ERROR_MESSAGE_BOOKING_SERIES_CONFLICT
+}
+
+// setBookingError sets the error returned inside a booking transaction on the
+// response and returns the status code to respond with.
+func setBookingError(res *vo.Response, err error) (statusCode int) {
+	conflictsErr := &occurrenceConflictsError{}
+	switch {
+	case errors.As(err, &conflictsErr):
+		res.SetErrorMessage(conflictsErr.Error())
+		for _, conflict := range conflictsErr.conflicts {
+			res.AppendErrors(conflict)
+		}
+		return fiber.StatusConflict
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		res.SetErrorMessage(constant.ERROR_MESSAGE_RESOURCE_NOT_FOUND)
+		return fiber.StatusNotFound
+	case errors.Is(err, errBookingNotFound),
+		errors.Is(err, errBookingSeriesNotFound),
+		errors.Is(err, errWaitlistEntryNotFound),
+		errors.Is(err, errPromoCodeNotFound),
+		errors.Is(err, errServiceNotFound),
+		errors.Is(err, errProviderNotFound):
+		res.SetErrorMessage(err.Error())
+		return fiber.StatusNotFound
+	case errors.Is(err, errBookingSlotUnavailable),
+		errors.Is(err, errBookingAlreadyCancelled),
+		errors.Is(err, errBookingAlreadyStarted),
+		errors.Is(err, errWaitlistOfferNotAvailable),
+		errors.Is(err, errWaitlistEntryClosed),
+		errors.Is(err, errAlreadyWaitlisted),
+		errors.Is(err, errBookingSlotAvailable),
+		errors.Is(err, errResourceClosed),
+		errors.Is(err, errResourceBlackedOut),
+		errors.Is(err, errPromoCodeExhausted),
+		errors.Is(err, errPromoCodeLimitReached),
+		errors.Is(err, errBookingNotPayable),
+		errors.Is(err, errBookingNotPaid),
+		errors.Is(err, errBookingNotConfirmed),
+		errors.Is(err, errBookingNotToday),
+		errors.Is(err, errBookingAlreadyCheckedIn),
+		errors.Is(err, errBillingProfileRequired),
+		errors.Is(err, errProviderUnavailable),
+		errors.Is(err, errRescheduleNotAllowed),
+		errors.Is(err, errRescheduleCutoffPassed),
+		errors.Is(err, errRescheduleLimitReached),
+		errors.Is(err, errNoShowFeeCharged):
+		res.SetErrorMessage(err.Error())
+		return fiber.StatusConflict
+	case errors.Is(err, errBookingSeriesDateChanged),
+		errors.Is(err, errInvalidRecurrenceRule),
+		errors.Is(err, errSeatsExceedCapacity),
+		errors.Is(err, errInvalidOpeningHours),
+		errors.Is(err, errPromoCodeNotValid),
+		errors.Is(err, errPromoCodeNotApplicable),
+		errors.Is(err, errPromoCodeMinSpend),
+		errors.Is(err, errPaymentAmountMismatch),
+		errors.Is(err, errRescheduleSameSlot):
+		res.SetErrorMessage(err.Error())
+		return fiber.StatusBadRequest
+	}
+	logs.Error(err)
+	res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+	return fiber.StatusInternalServerError
+}
+
+// findCancellationRules returns the current cancellation policy of the
+// resource to snapshot on new bookings. A resource without a policy never
+// refunds.
+func findCancellationRules(resourceRepository repository.IResourceRepository, resourceID uuid.UUID) (entity.CancellationRules, error) {
+	policy, err := resourceRepository.FindCancellationPolicyByResourceID(resourceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity.CancellationRules{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return policy.Rules, nil
+}
+
+// findRescheduleRules returns the reschedule rules of the resource. Unlike
+// the cancellation policy they aren't copied on bookings, as they only limit
+// when a booking may be moved and not what it costs.
+func findRescheduleRules(resourceRepository repository.IResourceRepository, resourceID uuid.UUID) (entity.RescheduleRules, error) {
+	policy, err := resourceRepository.FindReschedulePolicyByResourceID(resourceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity.RescheduleRules{}, nil
+	}
+	if err != nil {
+		return entity.RescheduleRules{}, err
+	}
+	return policy.Rules, nil
+}
+
+// findPricing returns the current pricing rules of the resource and whether
+// the user is a member of it, to price new bookings with. A resource without
+// a policy charges its hourly rate.
+func findPricing(resourceRepository repository.IResourceRepository, resourceID uuid.UUID, userID uuid.UUID) (rules entity.PricingRules, member bool, err error) {
+	policy, err := resourceRepository.FindPricingPolicyByResourceID(resourceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		policy = &entity.PricingPolicy{}
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	member, err = resourceRepository.IsResourceMember(resourceID, userID)
+	if err != nil {
+		return nil, false, err
+	}
+	return policy.Rules, member, nil
+}
+
+func newPricer(resource *entity.Resource, rules entity.PricingRules, member bool) *pricing.Pricer {
+	return &pricing.Pricer{HourlyRate: resource.HourlyRate, Rules: rules, Location: resource.Location(), Member: member}
+}
+
+// findAvailableSeats returns the seats of the resource left for the user at the
+// busiest moment between startAt and endAt. Bookings listed in excluded are
+// ignored, which lets bookings be moved over their own previous slot. Seats
+// offered to other users from the waitlist are held until the offer expires.
+func findAvailableSeats(bookingRepository repository.IBookingRepository, waitlistRepository repository.IWaitlistRepository, resource *entity.Resource, startAt time.Time, endAt time.Time, now time.Time, userID uuid.UUID, excluded map[uuid.UUID]bool) (seats int, err error) {
+	bookings, err := bookingRepository.FindOverlappingBookings(resource.ID, startAt, endAt)
+	if err != nil {
+		return 0, err
+	}
+	offers, err := waitlistRepository.FindActiveOffers(resource.ID, startAt, endAt, now, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	periods := []entity.SeatPeriod{}
+	for i := range bookings {
+		if !excluded[bookings[i].ID] {
+			periods = append(periods, bookings[i].SeatPeriod())
+		}
+	}
+	for i := range offers {
+		periods = append(periods, offers[i].SeatPeriod())
+	}
+	return resource.Capacity - entity.PeakSeats(periods, startAt, endAt), nil
+}
+
+// findSlotConflict returns the reason the seats cannot be booked by the user
+// for the period, or an empty string when they are available. The schedule
+// must cover the period.
+func findSlotConflict(bookingRepository repository.IBookingRepository, waitlistRepository repository.IWaitlistRepository, resource *entity.Resource, resourceSchedule *schedule.Schedule, startAt time.Time, endAt time.Time, seats int, now time.Time, userID uuid.UUID, excluded map[uuid.UUID]bool) (reason string, err error) {
+	if !startAt.After(now) {
+		return constant.ERROR_MESSAGE_BOOKING_IN_THE_PAST, nil
+	}
+	if reason := scheduleConflict(resourceSchedule, startAt, endAt); reason != "" {
+		return reason, nil
+	}
+
+	available, err := findAvailableSeats(bookingRepository, waitlistRepository, resource, startAt, endAt, now, userID, excluded)
+	if err != nil {
+		return "", err
+	}
+	if available < seats {
+		return constant.ERROR_MESSAGE_BOOKING_SLOT_UNAVAILABLE, nil
+	}
+	return "", nil
+}
+
+// slotConflictError returns the error to fail a booking with for the reason
+// found by findSlotConflict.
+func slotConflictError(reason string) error {
+	switch reason {
+	case constant.ERROR_MESSAGE_RESOURCE_CLOSED:
+		return errResourceClosed
+	case constant.ERROR_MESSAGE_RESOURCE_BLACKED_OUT:
+		return errResourceBlackedOut
+	}
+	return errBookingSlotUnavailable
+}
@@ -0,0 +1,595 @@
+package service
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/logs"
+	"booking/internal/recurrence"
+	"booking/internal/repository"
+	"booking/internal/vo"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+type IBookingSeriesService interface {
+	WithContext(ctx context.Context) IBookingSeriesService
+	CreateBookingSeries(username string, createBookingSeriesDTO *dto.CreateBookingSeriesDTO) (res vo.Response, statusCode int)
+	GetBookingSeries(username string, seriesID uuid.UUID) (res vo.Response, statusCode int)
+	UpdateBookingSeries(username string, seriesID uuid.UUID, updateBookingSeriesDTO *dto.UpdateBookingSeriesDTO) (res vo.Response, statusCode int)
+	CancelBookingSeries(username string, seriesID uuid.UUID, cancelBookingSeriesDTO *dto.CancelBookingSeriesDTO) (res vo.Response, statusCode int)
+}
+
+type bookingSeriesService struct {
+	authRepository     repository.IAuthRepository
+	resourceRepository repository.IResourceRepository
+	bookingRepository  repository.IBookingRepository
+	waitlistRepository repository.IWaitlistRepository
+	scheduleRepository repository.IScheduleRepository
+}
+
+func NewBookingSeriesService(authRepository repository.IAuthRepository, resourceRepository repository.IResourceRepository, bookingRepository repository.IBookingRepository, waitlistRepository repository.IWaitlistRepository, scheduleRepository repository.IScheduleRepository) *bookingSeriesService {
+	return &bookingSeriesService{authRepository: authRepository, resourceRepository: resourceRepository, bookingRepository: bookingRepository, waitlistRepository: waitlistRepository, scheduleRepository: scheduleRepository}
+}
+
+func (bookingSeriesService *bookingSeriesService) WithContext(ctx context.Context) IBookingSeriesService {
+	service := *bookingSeriesService
+	service.resourceRepository = bookingSeriesService.resourceRepository.WithContext(ctx)
+	service.bookingRepository = bookingSeriesService.bookingRepository.WithContext(ctx)
+	service.waitlistRepository = bookingSeriesService.waitlistRepository.WithContext(ctx)
+	return &service
+}
+
+// recurrenceHorizon returns until when and up to how many occurrences of a
+// recurring booking are booked.
+func recurrenceHorizon(now time.Time) (horizon time.Time, limit int) {
+	horizonDays := viper.GetInt("booking.recurrence.horizon_days")
+	if horizonDays <= 0 {
+		horizonDays = 365
+	}
+	limit = viper.GetInt("booking.recurrence.max_occurrences")
+	if limit <= 0 {
+		limit = 200
+	}
+	return now.AddDate(0, 0, horizonDays), limit
+}
+
+// withTimeOfDay returns the date of day at the wall clock time of clock.
+func withTimeOfDay(day time.Time, clock time.Time, location *time.Location) time.Time {
+	day = day.In(location)
+	clock = clock.In(location)
+	return time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, location)
+}
+
+func isSameDate(a time.Time, b time.Time, location *time.Location) bool {
+	a = a.In(location)
+	b = b.In(location)
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
+
+// selectOccurrences returns the upcoming active occurrences the scope applies
+// to, target being the occurrence the user picked.
+func selectOccurrences(bookings []entity.Booking, scope string, target *entity.Booking, now time.Time) []*entity.Booking {
+	if scope == dto.BOOKING_SERIES_SCOPE_THIS {
+		return []*entity.Booking{target}
+	}
+
+	selected := []*entity.Booking{}
+	for i := range bookings {
+		booking := &bookings[i]
+		if !booking.IsActive() || !booking.StartAt.After(now) {
+			continue
+		}
+		if scope == dto.BOOKING_SERIES_SCOPE_THIS_AND_FOLLOWING && booking.RecurrenceID.Before(*target.RecurrenceID) {
+			continue
+		}
+		selected = append(selected, booking)
+	}
+	return selected
+}
+
+// findTargetOccurrence returns the upcoming active occurrence of the series
+// the user picked.
+func findTargetOccurrence(bookings []entity.Booking, bookingID uuid.UUID, now time.Time) (*entity.Booking, error) {
+	for i := range bookings {
+		booking := &bookings[i]
+		if booking.ID != bookingID {
+			continue
+		}
+		if !booking.IsActive() {
+			return nil, errBookingAlreadyCancelled
+		}
+		if !now.Before(booking.StartAt) {
+			return nil, errBookingAlreadyStarted
+		}
+		return booking, nil
+	}
+	return nil, errBookingNotFound
+}
+
+// lockOwnSeries locks the active series of the user.
+func lockOwnSeries(bookingRepository repository.IBookingRepository, seriesID uuid.UUID, user *entity.User) (*entity.BookingSeries, error) {
+	series, err := bookingRepository.LockBookingSeriesByID(seriesID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && series.UserID != user.ID) {
+		return nil, errBookingSeriesNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if series.Status != entity.BOOKING_SERIES_STATUS_ACTIVE {
+		return nil, errBookingAlreadyCancelled
+	}
+	return series, nil
+}
+
+func (bookingSeriesService *bookingSeriesService) CreateBookingSeries(username string, createBookingSeriesDTO *dto.CreateBookingSeriesDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(bookingSeriesService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	timeZone := createBookingSeriesDTO.TimeZone
+	if timeZone == "" {
+		timeZone = constant.DEFAULT_TIME_ZONE
+	}
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INVALID_PARAMETERS)
+		return res, fiber.StatusBadRequest
+	}
+
+	rule := strings.TrimPrefix(strings.TrimSpace(createBookingSeriesDTO.RRule), "RRULE:")
+	dtstart := createBookingSeriesDTO.StartAt.In(location)
+	duration := createBookingSeriesDTO.EndAt.Sub(createBookingSeriesDTO.StartAt)
+	parsedRule, err := recurrence.Parse(rule, dtstart)
+	if err != nil || recurrence.RepeatsWithinDay(parsedRule) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INVALID_RECURRENCE_RULE)
+		return res, fiber.StatusBadRequest
+	}
+
+	now := time.Now()
+	horizon, limit := recurrenceHorizon(now)
+	occurrences, err := recurrence.Expand(rule, dtstart, createBookingSeriesDTO.ExDates, horizon, limit)
+	if errors.Is(err, recurrence.ErrTooManyOccurrences) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_TOO_MANY_OCCURRENCES)
+		return res, fiber.StatusBadRequest
+	}
+	if err != nil || len(occurrences) == 0 {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INVALID_RECURRENCE_RULE)
+		return res, fiber.StatusBadRequest
+	}
+
+	resourceID := uuid.MustParse(createBookingSeriesDTO.ResourceID)
+	cancellationRules, err := findCancellationRules(bookingSeriesService.resourceRepository, resourceID)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	pricingRules, member, err := findPricing(bookingSeriesService.resourceRepository, resourceID, user.ID)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	// occurrences aren't paid through payments, so only the ban of no-shows
+	// applies to series
+	penalty, err := findNoShowPenalty(bookingSeriesService.bookingRepository, user.ID, now)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	if penalty.BannedUntil != nil {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_BOOKING_BANNED)
+		return res, fiber.StatusForbidden
+	}
+
+	series := entity.BookingSeries{
+		UserID:          user.ID,
+		ResourceID:      resourceID,
+		StartAt:         dtstart,
+		DurationMinutes: int(duration / time.Minute),
+		Seats:           max(createBookingSeriesDTO.Seats, 1),
+		RRule:           rule,
+		ExDates:         createBookingSeriesDTO.ExDates,
+		TimeZone:        timeZone,
+		Status:          entity.BOOKING_SERIES_STATUS_ACTIVE,
+	}
+	bookings := []entity.Booking{}
+	err = bookingSeriesService.bookingRepository.Transaction(func(tx *gorm.DB) error {
+		bookingRepository := bookingSeriesService.bookingRepository.WithTx(tx)
+		waitlistRepository := bookingSeriesService.waitlistRepository.WithTx(tx)
+		resource, err := bookingRepository.LockResourceByID(resourceID)
+		if err != nil {
+			return err
+		}
+		if series.Seats > resource.Capacity {
+			return errSeatsExceedCapacity
+		}
+
+		err = promoteWaitlist(bookingRepository, waitlistRepository, bookingSeriesService.scheduleRepository, resource, now)
+		if err != nil {
+			return err
+		}
+
+		resourceSchedule, err := loadSchedule(bookingSeriesService.scheduleRepository, resource, occurrences[0], occurrences[len(occurrences)-1].Add(duration))
+		if err != nil {
+			return err
+		}
+
+		conflicts := []vo.OccurrenceConflict{}
+		for i, startAt := range occurrences {
+			endAt := startAt.Add(duration)
+			reason, err := findSlotConflict(bookingRepository, waitlistRepository, resource, resourceSchedule, startAt, endAt, series.Seats, now, user.ID, nil)
+			if err != nil {
+				return err
+			}
+			// occurrences longer than the recurrence interval overlap each other
+			if reason == "" && i+1 < len(occurrences) && occurrences[i+1].Before(endAt) {
+				reason = constant.ERROR_MESSAGE_BOOKING_SLOT_UNAVAILABLE
+			}
+			if reason != "" {
+				conflicts = append(conflicts, vo.OccurrenceConflict{StartAt: startAt, EndAt: endAt, Reason: reason})
+			}
+		}
+		if len(conflicts) > 0 {
+			return &occurrenceConflictsError{conflicts: conflicts}
+		}
+
+		err = bookingRepository.CreateBookingSeries(&series)
+		if err != nil {
+			return err
+		}
+
+		pricer := newPricer(resource, pricingRules, member)
+		for _, startAt := range occurrences {
+			recurrenceID := startAt
+			quote := pricer.Quote(startAt, startAt.Add(duration), series.Seats)
+			booking := entity.Booking{
+				UserID:             user.ID,
+				ResourceID:         resourceID,
+				StartAt:            startAt,
+				EndAt:              startAt.Add(duration),
+				Status:             entity.BOOKING_STATUS_CONFIRMED,
+				Seats:              series.Seats,
+				SeriesID:           &series.ID,
+				RecurrenceID:       &recurrenceID,
+				Amount:             quote.Total,
+				Quote:              quote,
+				CancellationPolicy: cancellationRules,
+			}
+			err = bookingRepository.CreateBooking(&booking)
+			if err != nil {
+				return err
+			}
+			bookings = append(bookings, booking)
+		}
+		return nil
+	})
+	if err != nil {
+		return res, setBookingError(&res, err)
+	}
+
+	res.SetData(vo.NewBookingSeriesResponse(&series, bookings))
+	return res, fiber.StatusCreated
+}
+
+func (bookingSeriesService *bookingSeriesService) GetBookingSeries(username string, seriesID uuid.UUID) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(bookingSeriesService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	series, err := bookingSeriesService.bookingRepository.FindBookingSeriesByID(seriesID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && series.UserID != user.ID) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_BOOKING_SERIES_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	bookings, err := bookingSeriesService.bookingRepository.FindBookingsBySeriesID(seriesID)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(vo.NewBookingSeriesResponse(series, bookings))
+	return res, fiber.StatusOK
+}
+
+// UpdateBookingSeries moves "this occurrence" anywhere, or changes the time of
+// "this and following" or "all" upcoming occurrences. Editing "this and
+// following" splits the series in two at the picked occurrence.
+func (bookingSeriesService *bookingSeriesService) UpdateBookingSeries(username string, seriesID uuid.UUID, updateBookingSeriesDTO *dto.UpdateBookingSeriesDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(bookingSeriesService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	bookingID := uuid.MustParse(updateBookingSeriesDTO.BookingID)
+	duration := updateBookingSeriesDTO.EndAt.Sub(updateBookingSeriesDTO.StartAt)
+	var series *entity.BookingSeries
+	var bookings []entity.Booking
+	err := bookingSeriesService.bookingRepository.Transaction(func(tx *gorm.DB) error {
+		bookingRepository := bookingSeriesService.bookingRepository.WithTx(tx)
+		var err error
+		series, err = lockOwnSeries(bookingRepository, seriesID, user)
+		if err != nil {
+			return err
+		}
+
+		bookings, err = bookingRepository.FindBookingsBySeriesID(seriesID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		target, err := findTargetOccurrence(bookings, bookingID, now)
+		if err != nil {
+			return err
+		}
+
+		location := series.Location()
+		scope := updateBookingSeriesDTO.Scope
+		if scope == dto.BOOKING_SERIES_SCOPE_THIS_AND_FOLLOWING && target.RecurrenceID.Equal(series.StartAt) {
+			scope = dto.BOOKING_SERIES_SCOPE_ALL
+		}
+		if scope != dto.BOOKING_SERIES_SCOPE_THIS && !isSameDate(target.StartAt, updateBookingSeriesDTO.StartAt, location) {
+			return errBookingSeriesDateChanged
+		}
+
+		resource, err := bookingRepository.LockResourceByID(series.ResourceID)
+		if err != nil {
+			return err
+		}
+		waitlistRepository := bookingSeriesService.waitlistRepository.WithTx(tx)
+		err = promoteWaitlist(bookingRepository, waitlistRepository, bookingSeriesService.scheduleRepository, resource, now)
+		if err != nil {
+			return err
+		}
+
+		affected := selectOccurrences(bookings, scope, target, now)
+		excluded := map[uuid.UUID]bool{}
+		for _, booking := range affected {
+			excluded[booking.ID] = true
+		}
+
+		startAts := make([]time.Time, len(affected))
+		for i, booking := range affected {
+			startAts[i] = updateBookingSeriesDTO.StartAt
+			if scope != dto.BOOKING_SERIES_SCOPE_THIS {
+				startAts[i] = withTimeOfDay(booking.StartAt, updateBookingSeriesDTO.StartAt, location)
+			}
+		}
+		resourceSchedule, err := loadSchedule(bookingSeriesService.scheduleRepository, resource, startAts[0], startAts[len(startAts)-1].Add(duration))
+		if err != nil {
+			return err
+		}
+
+		conflicts := []vo.OccurrenceConflict{}
+		for i, booking := range affected {
+			endAt := startAts[i].Add(duration)
+			reason, err := findSlotConflict(bookingRepository, waitlistRepository, resource, resourceSchedule, startAts[i], endAt, booking.Seats, now, user.ID, excluded)
+			if err != nil {
+				return err
+			}
+			if reason != "" {
+				conflicts = append(conflicts, vo.OccurrenceConflict{StartAt: startAts[i], EndAt: endAt, Reason: reason})
+			}
+		}
+		if len(conflicts) > 0 {
+			return &occurrenceConflictsError{conflicts: conflicts}
+		}
+
+		switch scope {
+		case dto.BOOKING_SERIES_SCOPE_ALL:
+			series.StartAt = withTimeOfDay(series.StartAt, updateBookingSeriesDTO.StartAt, location)
+			series.DurationMinutes = int(duration / time.Minute)
+			for i := range series.ExDates {
+				series.ExDates[i] = withTimeOfDay(series.ExDates[i], updateBookingSeriesDTO.StartAt, location)
+			}
+			err = bookingRepository.UpdateBookingSeries(series)
+		case dto.BOOKING_SERIES_SCOPE_THIS_AND_FOLLOWING:
+			series, err = splitSeries(bookingRepository, series, *target.RecurrenceID, updateBookingSeriesDTO.StartAt, duration)
+		}
+		if err != nil {
+			return err
+		}
+
+		// moved occurrences are priced again under the current rules
+		pricingRules, member, err := findPricing(bookingSeriesService.resourceRepository, resource.ID, user.ID)
+		if err != nil {
+			return err
+		}
+		pricer := newPricer(resource, pricingRules, member)
+		for i, booking := range affected {
+			booking.StartAt = startAts[i]
+			booking.EndAt = startAts[i].Add(duration)
+			booking.Quote = pricer.Quote(booking.StartAt, booking.EndAt, booking.Seats)
+			booking.Amount = booking.Quote.Total
+			booking.Sequence++
+			if scope != dto.BOOKING_SERIES_SCOPE_THIS {
+				recurrenceID := booking.StartAt
+				booking.SeriesID = &series.ID
+				booking.RecurrenceID = &recurrenceID
+			}
+			err = bookingRepository.UpdateBooking(booking)
+			if err != nil {
+				return err
+			}
+		}
+
+		// the slots the occurrences moved away from are offered to the waitlist
+		err = promoteWaitlist(bookingRepository, waitlistRepository, bookingSeriesService.scheduleRepository, resource, now)
+		if err != nil {
+			return err
+		}
+
+		bookings, err = bookingRepository.FindBookingsBySeriesID(series.ID)
+		return err
+	})
+	if err != nil {
+		return res, setBookingError(&res, err)
+	}
+
+	res.SetData(vo.NewBookingSeriesResponse(series, bookings))
+	return res, fiber.StatusOK
+}
+
+// splitSeries ends the series before the occurrence generated at recurrenceID
+// and returns a new series for that occurrence and the following ones, starting
+// at the time of day of startAt.
+func splitSeries(bookingRepository repository.IBookingRepository, series *entity.BookingSeries, recurrenceID time.Time, startAt time.Time, duration time.Duration) (*entity.BookingSeries, error) {
+	location := series.Location()
+	dtstart := series.StartAt.In(location)
+	parsedRule, err := recurrence.Parse(series.RRule, dtstart)
+	if err != nil {
+		return nil, errInvalidRecurrenceRule
+	}
+
+	following := entity.BookingSeries{
+		UserID:          series.UserID,
+		ResourceID:      series.ResourceID,
+		StartAt:         withTimeOfDay(recurrenceID, startAt, location),
+		DurationMinutes: int(duration / time.Minute),
+		Seats:           series.Seats,
+		RRule:           series.RRule,
+		ExDates:         entity.TimeList{},
+		TimeZone:        series.TimeZone,
+		Status:          entity.BOOKING_SERIES_STATUS_ACTIVE,
+	}
+	if parsedRule.OrigOptions.Count > 0 {
+		countBefore, err := recurrence.CountBefore(series.RRule, dtstart, recurrenceID)
+		if err != nil {
+			return nil, err
+		}
+		following.RRule, err = recurrence.WithCount(series.RRule, following.StartAt, parsedRule.OrigOptions.Count-countBefore)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	exdates := entity.TimeList{}
+	for _, exdate := range series.ExDates {
+		if exdate.Before(recurrenceID) {
+			exdates = append(exdates, exdate)
+		} else {
+			following.ExDates = append(following.ExDates, withTimeOfDay(exdate, startAt, location))
+		}
+	}
+	series.ExDates = exdates
+	series.RRule, err = recurrence.EndBefore(series.RRule, dtstart, recurrenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = bookingRepository.UpdateBookingSeries(series)
+	if err != nil {
+		return nil, err
+	}
+	err = bookingRepository.CreateBookingSeries(&following)
+	if err != nil {
+		return nil, err
+	}
+	return &following, nil
+}
+
+// CancelBookingSeries previews the refunds of cancelling "this occurrence",
+// "this and following" or "all" upcoming occurrences under each booking's
+// policy snapshot, and applies it when the user confirms.
+func (bookingSeriesService *bookingSeriesService) CancelBookingSeries(username string, seriesID uuid.UUID, cancelBookingSeriesDTO *dto.CancelBookingSeriesDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(bookingSeriesService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	cancellation := vo.BookingSeriesCancellationResponse{Occurrences: []vo.CancellationResponse{}}
+	err := bookingSeriesService.bookingRepository.Transaction(func(tx *gorm.DB) error {
+		bookingRepository := bookingSeriesService.bookingRepository.WithTx(tx)
+		series, err := lockOwnSeries(bookingRepository, seriesID, user)
+		if err != nil {
+			return err
+		}
+
+		resource, err := bookingRepository.LockResourceByID(series.ResourceID)
+		if err != nil {
+			return err
+		}
+
+		bookings, err := bookingRepository.FindBookingsBySeriesID(seriesID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		scope := cancelBookingSeriesDTO.Scope
+		var target *entity.Booking
+		if scope != dto.BOOKING_SERIES_SCOPE_ALL {
+			target, err = findTargetOccurrence(bookings, uuid.MustParse(cancelBookingSeriesDTO.BookingID), now)
+			if err != nil {
+				return err
+			}
+			if scope == dto.BOOKING_SERIES_SCOPE_THIS_AND_FOLLOWING && target.RecurrenceID.Equal(series.StartAt) {
+				scope = dto.BOOKING_SERIES_SCOPE_ALL
+			}
+		}
+
+		affected := selectOccurrences(bookings, scope, target, now)
+		for _, booking := range affected {
+			occurrence := previewCancellation(booking, now)
+			if cancelBookingSeriesDTO.Confirm {
+				err = applyCancellation(bookingRepository, booking, &occurrence, now)
+				if err != nil {
+					return err
+				}
+			}
+			cancellation.RefundAmount += occurrence.RefundAmount
+			cancellation.Occurrences = append(cancellation.Occurrences, occurrence)
+		}
+		if !cancelBookingSeriesDTO.Confirm {
+			return nil
+		}
+
+		switch scope {
+		case dto.BOOKING_SERIES_SCOPE_THIS:
+			series.ExDates = append(series.ExDates, *target.RecurrenceID)
+		case dto.BOOKING_SERIES_SCOPE_THIS_AND_FOLLOWING:
+			series.RRule, err = recurrence.EndBefore(series.RRule, series.StartAt.In(series.Location()), *target.RecurrenceID)
+			if err != nil {
+				return err
+			}
+		case dto.BOOKING_SERIES_SCOPE_ALL:
+			series.Status = entity.BOOKING_SERIES_STATUS_CANCELLED
+		}
+		cancellation.Applied = true
+		err = bookingRepository.UpdateBookingSeries(series)
+		if err != nil {
+			return err
+		}
+		return promoteWaitlist(bookingRepository, bookingSeriesService.waitlistRepository.WithTx(tx), bookingSeriesService.scheduleRepository, resource, now)
+	})
+	if err != nil {
+		return res, setBookingError(&res, err)
+	}
+
+	res.SetData(cancellation)
+	return res, fiber.StatusOK
+}
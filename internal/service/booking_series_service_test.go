@@ -0,0 +1,306 @@
+package service_test
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/service"
+	"booking/internal/vo"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestCreateBookingSeries(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+	resource := &entity.Resource{ID: uuid.New(), HourlyRate: 50000, Capacity: 1}
+	bangkok, _ := time.LoadLocation(constant.DEFAULT_TIME_ZONE)
+	now := time.Now().In(bangkok)
+	startAt := time.Date(now.Year(), now.Month(), now.Day()+7, 10, 0, 0, 0, bangkok)
+	body := dto.CreateBookingSeriesDTO{
+		ResourceID: resource.ID.String(),
+		StartAt:    startAt,
+		EndAt:      startAt.Add(time.Hour),
+		RRule:      "RRULE:FREQ=WEEKLY;COUNT=4",
+	}
+
+	t.Run("When the rule repeats within a day, should response status code 400 with error message", func(t *testing.T) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		bookingSeriesService := service.NewBookingSeriesService(authRepositoryMock, &resourceRepositoryMock{}, &bookingRepositoryMock{}, newWaitlistRepositoryMock(), newScheduleRepositoryMock())
+		body := body
+		body.RRule = "FREQ=HOURLY;COUNT=4"
+
+		res, statusCode := bookingSeriesService.CreateBookingSeries(Username, &body)
+
+		assert.Equal(t, fiber.StatusBadRequest, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_INVALID_RECURRENCE_RULE, res.ErrorMessage)
+	})
+
+	t.Run("When some occurrences are not available, should response status code 409 with a conflict per occurrence", func(t *testing.T) {
+		conflictAt := startAt.AddDate(0, 0, 14)
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindCancellationPolicyByResourceID", resource.ID).Return(&entity.CancellationPolicy{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("FindPricingPolicyByResourceID", resource.ID).Return(&entity.PricingPolicy{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("IsResourceMember", resource.ID, user.ID).Return(false, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("LockResourceByID", resource.ID).Return(resource, nil)
+		bookingRepositoryMock.On("FindOverlappingBookings", resource.ID, mock.MatchedBy(conflictAt.Equal)).Return([]entity.Booking{{ID: uuid.New(), StartAt: conflictAt, EndAt: conflictAt.Add(time.Hour), Seats: 1}}, nil)
+		bookingRepositoryMock.On("FindOverlappingBookings", resource.ID, mock.Anything).Return([]entity.Booking{}, nil)
+		bookingSeriesService := service.NewBookingSeriesService(authRepositoryMock, resourceRepositoryMock, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock())
+
+		res, statusCode := bookingSeriesService.CreateBookingSeries(Username, &body)
+
+		isPass := assert.Equal(t, fiber.StatusConflict, statusCode)
+		if isPass {
+			assert.Equal(t, constant.ERROR_MESSAGE_BOOKING_SERIES_CONFLICT, res.ErrorMessage)
+			assert.Len(t, res.Errors, 1)
+			conflict := res.Errors[0].(vo.OccurrenceConflict)
+			assert.True(t, conflictAt.Equal(conflict.StartAt))
+			assert.Equal(t, constant.ERROR_MESSAGE_BOOKING_SLOT_UNAVAILABLE, conflict.Reason)
+		}
+		bookingRepositoryMock.AssertNotCalled(t, "CreateBookingSeries", resource.ID)
+	})
+
+	t.Run("When every occurrence is available, should book every occurrence except the exdates", func(t *testing.T) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindCancellationPolicyByResourceID", resource.ID).Return(&entity.CancellationPolicy{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("FindPricingPolicyByResourceID", resource.ID).Return(&entity.PricingPolicy{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("IsResourceMember", resource.ID, user.ID).Return(false, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("LockResourceByID", resource.ID).Return(resource, nil)
+		bookingRepositoryMock.On("FindOverlappingBookings", resource.ID, mock.Anything).Return([]entity.Booking{}, nil)
+		bookingRepositoryMock.On("CreateBookingSeries", resource.ID).Return(nil)
+		bookingRepositoryMock.On("CreateBooking", resource.ID).Return(nil)
+		bookingSeriesService := service.NewBookingSeriesService(authRepositoryMock, resourceRepositoryMock, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock())
+		body := body
+		body.ExDates = []time.Time{startAt.AddDate(0, 0, 7)}
+
+		res, statusCode := bookingSeriesService.CreateBookingSeries(Username, &body)
+
+		isPass := assert.Equal(t, fiber.StatusCreated, statusCode)
+		if isPass {
+			series := res.Data.(vo.BookingSeriesResponse)
+			assert.Equal(t, "FREQ=WEEKLY;COUNT=4", series.RRule)
+			assert.Len(t, series.Occurrences, 3)
+			assert.True(t, startAt.AddDate(0, 0, 14).Equal(series.Occurrences[1].StartAt))
+			assert.Equal(t, int64(50000), series.Occurrences[1].Amount)
+		}
+		bookingRepositoryMock.AssertNumberOfCalls(t, "CreateBooking", 3)
+	})
+}
+
+func newBookingSeriesFixture(user *entity.User, startAt time.Time, count int) (*entity.BookingSeries, []entity.Booking) {
+	series := &entity.BookingSeries{
+		ID:              uuid.New(),
+		UserID:          user.ID,
+		ResourceID:      uuid.New(),
+		StartAt:         startAt,
+		DurationMinutes: 60,
+		Seats:           1,
+		RRule:           "FREQ=WEEKLY;COUNT=4",
+		TimeZone:        constant.DEFAULT_TIME_ZONE,
+		Status:          entity.BOOKING_SERIES_STATUS_ACTIVE,
+	}
+	bookings := []entity.Booking{}
+	for i := 0; i < count; i++ {
+		occurrenceStartAt := startAt.AddDate(0, 0, 7*i)
+		bookings = append(bookings, entity.Booking{
+			ID:                 uuid.New(),
+			UserID:             user.ID,
+			ResourceID:         series.ResourceID,
+			StartAt:            occurrenceStartAt,
+			EndAt:              occurrenceStartAt.Add(time.Hour),
+			Status:             entity.BOOKING_STATUS_CONFIRMED,
+			Seats:              1,
+			SeriesID:           &series.ID,
+			RecurrenceID:       &occurrenceStartAt,
+			Amount:             50000,
+			CancellationPolicy: entity.CancellationRules{{HoursBeforeStart: 48, RefundPercent: 100}},
+		})
+	}
+	return series, bookings
+}
+
+func TestUpdateBookingSeries(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+	bangkok, _ := time.LoadLocation(constant.DEFAULT_TIME_ZONE)
+	now := time.Now().In(bangkok)
+	startAt := time.Date(now.Year(), now.Month(), now.Day()+7, 10, 0, 0, 0, bangkok)
+
+	t.Run("When move the following occurrences to another date, should response status code 400 with error message", func(t *testing.T) {
+		series, bookings := newBookingSeriesFixture(user, startAt, 4)
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("LockBookingSeriesByID", series.ID).Return(series, nil)
+		bookingRepositoryMock.On("FindBookingsBySeriesID", series.ID).Return(bookings, nil)
+		bookingSeriesService := service.NewBookingSeriesService(authRepositoryMock, &resourceRepositoryMock{}, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock())
+		newStartAt := bookings[1].StartAt.AddDate(0, 0, 1)
+
+		res, statusCode := bookingSeriesService.UpdateBookingSeries(Username, series.ID, &dto.UpdateBookingSeriesDTO{
+			Scope:     dto.BOOKING_SERIES_SCOPE_THIS_AND_FOLLOWING,
+			BookingID: bookings[1].ID.String(),
+			StartAt:   newStartAt,
+			EndAt:     newStartAt.Add(time.Hour),
+		})
+
+		assert.Equal(t, fiber.StatusBadRequest, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_BOOKING_SERIES_DATE_CHANGED, res.ErrorMessage)
+	})
+
+	t.Run("When change the time of the whole series, should move every upcoming occurrence", func(t *testing.T) {
+		series, bookings := newBookingSeriesFixture(user, startAt, 4)
+		resource := &entity.Resource{ID: series.ResourceID, HourlyRate: 50000, Capacity: 1}
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("LockBookingSeriesByID", series.ID).Return(series, nil)
+		bookingRepositoryMock.On("FindBookingsBySeriesID", series.ID).Return(bookings, nil)
+		bookingRepositoryMock.On("LockResourceByID", series.ResourceID).Return(resource, nil)
+		// the occurrences overlap their own previous slot, which must not be a conflict
+		bookingRepositoryMock.On("FindOverlappingBookings", series.ResourceID, mock.Anything).Return([]entity.Booking{bookings[0]}, nil)
+		bookingRepositoryMock.On("UpdateBookingSeries", series.ID).Return(nil)
+		bookingRepositoryMock.On("UpdateBooking", mock.Anything).Return(nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindPricingPolicyByResourceID", resource.ID).Return(&entity.PricingPolicy{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("IsResourceMember", resource.ID, user.ID).Return(false, nil)
+		bookingSeriesService := service.NewBookingSeriesService(authRepositoryMock, resourceRepositoryMock, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock())
+		newStartAt := bookings[2].StartAt.Add(30 * time.Minute)
+
+		_, statusCode := bookingSeriesService.UpdateBookingSeries(Username, series.ID, &dto.UpdateBookingSeriesDTO{
+			Scope:     dto.BOOKING_SERIES_SCOPE_ALL,
+			BookingID: bookings[2].ID.String(),
+			StartAt:   newStartAt,
+			EndAt:     newStartAt.Add(2 * time.Hour),
+		})
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			assert.True(t, startAt.Add(30*time.Minute).Equal(series.StartAt))
+			assert.Equal(t, 120, series.DurationMinutes)
+			for _, booking := range bookings {
+				assert.Equal(t, 10, booking.StartAt.In(bangkok).Hour())
+				assert.Equal(t, 30, booking.StartAt.In(bangkok).Minute())
+				assert.Equal(t, int64(100000), booking.Amount)
+			}
+		}
+		bookingRepositoryMock.AssertNumberOfCalls(t, "UpdateBooking", 4)
+	})
+}
+
+func TestCancelBookingSeries(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+	bangkok, _ := time.LoadLocation(constant.DEFAULT_TIME_ZONE)
+	now := time.Now().In(bangkok)
+	startAt := time.Date(now.Year(), now.Month(), now.Day()+7, 10, 0, 0, 0, bangkok)
+
+	t.Run("When preview cancelling this and following occurrences, should response the refund of each occurrence without cancelling", func(t *testing.T) {
+		series, bookings := newBookingSeriesFixture(user, startAt, 4)
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("LockBookingSeriesByID", series.ID).Return(series, nil)
+		bookingRepositoryMock.On("LockResourceByID", series.ResourceID).Return(&entity.Resource{ID: series.ResourceID}, nil)
+		bookingRepositoryMock.On("FindBookingsBySeriesID", series.ID).Return(bookings, nil)
+		bookingSeriesService := service.NewBookingSeriesService(authRepositoryMock, &resourceRepositoryMock{}, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock())
+
+		res, statusCode := bookingSeriesService.CancelBookingSeries(Username, series.ID, &dto.CancelBookingSeriesDTO{
+			Scope:     dto.BOOKING_SERIES_SCOPE_THIS_AND_FOLLOWING,
+			BookingID: bookings[2].ID.String(),
+		})
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			cancellation := res.Data.(vo.BookingSeriesCancellationResponse)
+			assert.False(t, cancellation.Applied)
+			assert.Len(t, cancellation.Occurrences, 2)
+			assert.Equal(t, int64(100000), cancellation.RefundAmount)
+		}
+		bookingRepositoryMock.AssertNotCalled(t, "UpdateBooking", mock.Anything)
+		bookingRepositoryMock.AssertNotCalled(t, "UpdateBookingSeries", series.ID)
+	})
+
+	t.Run("When cancel this and following occurrences, should end the series before the occurrence", func(t *testing.T) {
+		series, bookings := newBookingSeriesFixture(user, startAt, 4)
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("LockBookingSeriesByID", series.ID).Return(series, nil)
+		bookingRepositoryMock.On("LockResourceByID", series.ResourceID).Return(&entity.Resource{ID: series.ResourceID}, nil)
+		bookingRepositoryMock.On("FindBookingsBySeriesID", series.ID).Return(bookings, nil)
+		bookingRepositoryMock.On("UpdateBooking", mock.Anything).Return(nil)
+		bookingRepositoryMock.On("UpdateBookingSeries", series.ID).Return(nil)
+		bookingSeriesService := service.NewBookingSeriesService(authRepositoryMock, &resourceRepositoryMock{}, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock())
+
+		_, statusCode := bookingSeriesService.CancelBookingSeries(Username, series.ID, &dto.CancelBookingSeriesDTO{
+			Scope:     dto.BOOKING_SERIES_SCOPE_THIS_AND_FOLLOWING,
+			BookingID: bookings[2].ID.String(),
+			Confirm:   true,
+		})
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			assert.Equal(t, entity.BOOKING_STATUS_CONFIRMED, bookings[1].Status)
+			assert.Equal(t, entity.BOOKING_STATUS_CANCELLED, bookings[2].Status)
+			assert.Equal(t, entity.BOOKING_STATUS_CANCELLED, bookings[3].Status)
+			assert.Contains(t, series.RRule, "UNTIL=")
+			assert.NotContains(t, series.RRule, "COUNT=")
+		}
+	})
+
+	t.Run("When cancel a single occurrence, should add it to the exdates of the series", func(t *testing.T) {
+		series, bookings := newBookingSeriesFixture(user, startAt, 4)
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("LockBookingSeriesByID", series.ID).Return(series, nil)
+		bookingRepositoryMock.On("LockResourceByID", series.ResourceID).Return(&entity.Resource{ID: series.ResourceID}, nil)
+		bookingRepositoryMock.On("FindBookingsBySeriesID", series.ID).Return(bookings, nil)
+		bookingRepositoryMock.On("UpdateBooking", mock.Anything).Return(nil)
+		bookingRepositoryMock.On("UpdateBookingSeries", series.ID).Return(nil)
+		bookingSeriesService := service.NewBookingSeriesService(authRepositoryMock, &resourceRepositoryMock{}, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock())
+
+		_, statusCode := bookingSeriesService.CancelBookingSeries(Username, series.ID, &dto.CancelBookingSeriesDTO{
+			Scope:     dto.BOOKING_SERIES_SCOPE_THIS,
+			BookingID: bookings[1].ID.String(),
+			Confirm:   true,
+		})
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			assert.Equal(t, entity.BOOKING_STATUS_CANCELLED, bookings[1].Status)
+			assert.Equal(t, entity.BOOKING_STATUS_CONFIRMED, bookings[2].Status)
+			assert.Len(t, series.ExDates, 1)
+			assert.True(t, bookings[1].RecurrenceID.Equal(series.ExDates[0]))
+		}
+	})
+
+	t.Run("When the series belongs to another user, should response status code 404 with error message", func(t *testing.T) {
+		series, _ := newBookingSeriesFixture(&entity.User{ID: uuid.New()}, startAt, 4)
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("LockBookingSeriesByID", series.ID).Return(series, nil)
+		bookingSeriesService := service.NewBookingSeriesService(authRepositoryMock, &resourceRepositoryMock{}, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock())
+
+		res, statusCode := bookingSeriesService.CancelBookingSeries(Username, series.ID, &dto.CancelBookingSeriesDTO{
+			Scope: dto.BOOKING_SERIES_SCOPE_ALL,
+		})
+
+		assert.Equal(t, fiber.StatusNotFound, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_BOOKING_SERIES_NOT_FOUND, res.ErrorMessage)
+	})
+}
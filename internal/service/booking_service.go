@@ -0,0 +1,845 @@
+package service
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/logs"
+	"booking/internal/notification"
+	"booking/internal/payment"
+	"booking/internal/repository"
+	"booking/internal/vo"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type IBookingService interface {
+	WithContext(ctx context.Context) IBookingService
+	CreateBooking(username string, createBookingDTO *dto.CreateBookingDTO) (res vo.Response, statusCode int)
+	GetBooking(username string, bookingID uuid.UUID) (res vo.Response, statusCode int)
+	CancelBooking(username string, bookingID uuid.UUID, cancelBookingDTO *dto.CancelBookingDTO) (res vo.Response, statusCode int)
+	RescheduleBooking(username string, bookingID uuid.UUID, rescheduleBookingDTO *dto.RescheduleBookingDTO) (res vo.Response, statusCode int)
+	GetBookingHistory(username string, bookingID uuid.UUID) (res vo.Response, statusCode int)
+	GetAvailability(username string, resourceID uuid.UUID, availabilityQueryDTO *dto.AvailabilityQueryDTO) (res vo.Response, statusCode int)
+	HandleOverdueBookings(now time.Time) (err error)
+	ExpireStaleHolds(now time.Time) (err error)
+	MarkNoShows(now time.Time) (err error)
+	GetNoShowRecord(username string) (res vo.Response, statusCode int)
+}
+
+const (
+	// OVERDUE_BATCH_SIZE is how many overdue bookings are handled per run.
+	OVERDUE_BATCH_SIZE = 100
+	// HOLD_BATCH_SIZE is how many stale holds are expired per run.
+	HOLD_BATCH_SIZE = 100
+	// NO_SHOW_BATCH_SIZE is how many missed check-ins are marked per run.
+	NO_SHOW_BATCH_SIZE = 100
+	// NO_SHOW_LOOKBACK is how long after its grace period a booking not
+	// checked in is still marked, which covers runs missed while no process
+	// was up without marking bookings from before check-ins were recorded.
+	NO_SHOW_LOOKBACK = 24 * time.Hour
+)
+
+type bookingService struct {
+	authRepository         repository.IAuthRepository
+	resourceRepository     repository.IResourceRepository
+	bookingRepository      repository.IBookingRepository
+	waitlistRepository     repository.IWaitlistRepository
+	scheduleRepository     repository.IScheduleRepository
+	providerRepository     repository.IProviderRepository
+	promoCodeRepository    repository.IPromoCodeRepository
+	paymentRepository      repository.IPaymentRepository
+	ledgerRepository       repository.ILedgerRepository
+	notificationRepository repository.INotificationRepository
+	paymentProvider        payment.IPaymentProvider
+}
+
+func NewBookingService(authRepository repository.IAuthRepository, resourceRepository repository.IResourceRepository, bookingRepository repository.IBookingRepository, waitlistRepository repository.IWaitlistRepository, scheduleRepository repository.IScheduleRepository, providerRepository repository.IProviderRepository, promoCodeRepository repository.IPromoCodeRepository, paymentRepository repository.IPaymentRepository, ledgerRepository repository.ILedgerRepository, notificationRepository repository.INotificationRepository, paymentProvider payment.IPaymentProvider) *bookingService {
+	return &bookingService{authRepository: authRepository, resourceRepository: resourceRepository, bookingRepository: bookingRepository, waitlistRepository: waitlistRepository, scheduleRepository: scheduleRepository, providerRepository: providerRepository, promoCodeRepository: promoCodeRepository, paymentRepository: paymentRepository, ledgerRepository: ledgerRepository, notificationRepository: notificationRepository, paymentProvider: paymentProvider}
+}
+
+func (bookingService *bookingService) WithContext(ctx context.Context) IBookingService {
+	service := *bookingService
+	service.resourceRepository = bookingService.resourceRepository.WithContext(ctx)
+	service.bookingRepository = bookingService.bookingRepository.WithContext(ctx)
+	service.waitlistRepository = bookingService.waitlistRepository.WithContext(ctx)
+	service.providerRepository = bookingService.providerRepository.WithContext(ctx)
+	service.promoCodeRepository = bookingService.promoCodeRepository.WithContext(ctx)
+	service.paymentRepository = bookingService.paymentRepository.WithContext(ctx)
+	return &service
+}
+
+func (bookingService *bookingService) CreateBooking(username string, createBookingDTO *dto.CreateBookingDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(bookingService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	now := time.Now()
+	if !createBookingDTO.StartAt.After(now) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_BOOKING_IN_THE_PAST)
+		return res, fiber.StatusBadRequest
+	}
+
+	resourceID, _ := uuid.Parse(createBookingDTO.ResourceID)
+	var appointment *appointment
+	if createBookingDTO.ServiceID != "" {
+		var providerID *uuid.UUID
+		if createBookingDTO.ProviderID != "" {
+			id := uuid.MustParse(createBookingDTO.ProviderID)
+			providerID = &id
+		}
+		found, err := findAppointment(bookingService.providerRepository, uuid.MustParse(createBookingDTO.ServiceID), providerID)
+		if err != nil {
+			return res, setBookingError(&res, err)
+		}
+		appointment = found
+		resourceID = appointment.service.ResourceID
+	} else if createBookingDTO.ProviderID != "" {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INVALID_PARAMETERS)
+		return res, fiber.StatusBadRequest
+	}
+	cancellationRules, err := findCancellationRules(bookingService.resourceRepository, resourceID)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	pricingRules, member, err := findPricing(bookingService.resourceRepository, resourceID, user.ID)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	paymentSchedule, err := findPaymentSchedule(bookingService.resourceRepository, resourceID)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	penalty, err := findNoShowPenalty(bookingService.bookingRepository, user.ID, now)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	if penalty.BannedUntil != nil {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_BOOKING_BANNED)
+		return res, fiber.StatusForbidden
+	}
+
+	booking := entity.Booking{
+		UserID:             user.ID,
+		ResourceID:         resourceID,
+		StartAt:            createBookingDTO.StartAt,
+		EndAt:              createBookingDTO.EndAt,
+		Status:             entity.BOOKING_STATUS_CONFIRMED,
+		Seats:              max(createBookingDTO.Seats, 1),
+		CancellationPolicy: cancellationRules,
+	}
+	var paymentResponse *vo.PaymentResponse
+	err = bookingService.bookingRepository.Transaction(func(tx *gorm.DB) error {
+		bookingRepository := bookingService.bookingRepository.WithTx(tx)
+		waitlistRepository := bookingService.waitlistRepository.WithTx(tx)
+		resource, err := bookingRepository.LockResourceByID(resourceID)
+		if err != nil {
+			return err
+		}
+
+		if booking.Seats > resource.Capacity {
+			return errSeatsExceedCapacity
+		}
+
+		// users waiting for the slot are offered it before anyone else can book
+		err = promoteWaitlist(bookingRepository, waitlistRepository, bookingService.scheduleRepository, resource, now)
+		if err != nil {
+			return err
+		}
+
+		// appointments end when the provider they are assigned to is done
+		if appointment != nil {
+			booking.EndAt = booking.StartAt.Add(appointment.longest())
+		}
+		resourceSchedule, err := loadSchedule(bookingService.scheduleRepository, resource, booking.StartAt, booking.EndAt)
+		if err != nil {
+			return err
+		}
+		if appointment != nil {
+			err = assignProvider(bookingRepository, waitlistRepository, bookingService.providerRepository.WithTx(tx), resource, resourceSchedule, appointment, &booking, now)
+			if err != nil {
+				return err
+			}
+		}
+		reason, err := findSlotConflict(bookingRepository, waitlistRepository, resource, resourceSchedule, booking.StartAt, booking.EndAt, booking.Seats, now, user.ID, nil)
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			return slotConflictError(reason)
+		}
+
+		booking.Quote = newPricer(resource, pricingRules, member).Quote(booking.StartAt, booking.EndAt, booking.Seats)
+		subtotal := booking.Quote.Total
+		promoCodeRepository := bookingService.promoCodeRepository.WithTx(tx)
+		var promoCode *entity.PromoCode
+		if createBookingDTO.PromoCode != "" {
+			promoCode, err = findPromoCode(promoCodeRepository, createBookingDTO.PromoCode, true)
+			if err != nil {
+				return err
+			}
+			err = checkPromoCode(promoCodeRepository, promoCode, resource, user.ID, &booking.Quote, now)
+			if err != nil {
+				return err
+			}
+			booking.Quote.ApplyPromoCode(promoCode)
+		}
+		booking.Amount = booking.Quote.Total
+		penalty.chargeFee(&booking)
+		// paid bookings hold the slot while pending until the deposit is paid
+		schedulePayments(&booking, paymentSchedule, now)
+		penalty.requireDeposit(&booking)
+		err = bookingRepository.CreateBooking(&booking)
+		if err != nil {
+			return err
+		}
+		err = penalty.chargeNoShows(bookingRepository, &booking)
+		if err != nil {
+			return err
+		}
+
+		if promoCode != nil {
+			err = promoCodeRepository.RedeemPromoCode(promoCode, &entity.PromoRedemption{
+				PromoCodeID: promoCode.ID,
+				UserID:      user.ID,
+				BookingID:   booking.ID,
+				Discount:    subtotal - booking.Quote.Total,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		err = postBooking(bookingService.ledgerRepository.WithTx(tx), resource.OrganizationID, &booking)
+		if err != nil {
+			return err
+		}
+
+		if booking.Status != entity.BOOKING_STATUS_PENDING {
+			return notifyBooking(bookingService.notificationRepository.WithTx(tx), resource, &booking, notification.EVENT_BOOKING_CONFIRMED, now)
+		}
+		paymentResponse, err = startPayment(bookingService.paymentRepository.WithTx(tx), bookingService.paymentProvider, &booking, booking.DepositAmount())
+		return err
+	})
+	if err != nil {
+		return res, setBookingError(&res, err)
+	}
+
+	response := vo.NewBookingResponse(&booking)
+	response.Payment = paymentResponse
+	res.SetData(response)
+	return res, fiber.StatusCreated
+}
+
+func (bookingService *bookingService) GetBooking(username string, bookingID uuid.UUID) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(bookingService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	booking, err := bookingService.bookingRepository.FindBookingByID(bookingID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && booking.UserID != user.ID) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_BOOKING_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(vo.NewBookingResponse(booking))
+	return res, fiber.StatusOK
+}
+
+// CancelBooking previews the refund of cancelling the booking under the policy
+// snapshot taken when it was booked, and applies it when the user confirms.
+func (bookingService *bookingService) CancelBooking(username string, bookingID uuid.UUID, cancelBookingDTO *dto.CancelBookingDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(bookingService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	cancellation := vo.CancellationResponse{}
+	err := bookingService.bookingRepository.Transaction(func(tx *gorm.DB) error {
+		bookingRepository := bookingService.bookingRepository.WithTx(tx)
+		booking, err := bookingRepository.FindBookingByID(bookingID)
+		if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && booking.UserID != user.ID) {
+			return errBookingNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		// the resource is locked first, as when booking, so the freed slot can
+		// be offered to the waitlist
+		resource, err := bookingRepository.LockResourceByID(booking.ResourceID)
+		if err != nil {
+			return err
+		}
+		booking, err = bookingRepository.LockBookingByID(bookingID)
+		if err != nil {
+			return err
+		}
+
+		if !booking.IsActive() {
+			return errBookingAlreadyCancelled
+		}
+
+		now := time.Now()
+		if !now.Before(booking.StartAt) {
+			return errBookingAlreadyStarted
+		}
+
+		cancellation = previewCancellation(booking, now)
+		if !cancelBookingDTO.Confirm {
+			return nil
+		}
+		err = applyCancellation(bookingRepository, booking, &cancellation, now)
+		if err != nil {
+			return err
+		}
+		ledgerRepository := bookingService.ledgerRepository.WithTx(tx)
+		err = postCancellation(ledgerRepository, resource.OrganizationID, booking, "cancellation")
+		if err != nil {
+			return err
+		}
+		err = refundBooking(bookingService.paymentRepository.WithTx(tx), ledgerRepository, resource.OrganizationID, bookingService.paymentProvider, booking)
+		if err != nil {
+			return err
+		}
+		err = notifyBooking(bookingService.notificationRepository.WithTx(tx), resource, booking, notification.EVENT_BOOKING_CANCELLED, now)
+		if err != nil {
+			return err
+		}
+		return promoteWaitlist(bookingRepository, bookingService.waitlistRepository.WithTx(tx), bookingService.scheduleRepository, resource, now)
+	})
+	if err != nil {
+		return res, setBookingError(&res, err)
+	}
+
+	res.SetData(cancellation)
+	return res, fiber.StatusOK
+}
+
+// RescheduleBooking previews moving the booking to another start under the
+// reschedule rules of its resource, priced at the new time with the promo code
+// and fees it was charged, and moves it when the user confirms. A higher price
+// is asked for now and what was paid beyond a lower one is refunded. The
+// change is added to the history of the booking.
+func (bookingService *bookingService) RescheduleBooking(username string, bookingID uuid.UUID, rescheduleBookingDTO *dto.RescheduleBookingDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(bookingService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	if !rescheduleBookingDTO.StartAt.After(time.Now()) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_BOOKING_IN_THE_PAST)
+		return res, fiber.StatusBadRequest
+	}
+
+	reschedule := vo.RescheduleResponse{}
+	err := bookingService.bookingRepository.Transaction(func(tx *gorm.DB) error {
+		bookingRepository := bookingService.bookingRepository.WithTx(tx)
+		waitlistRepository := bookingService.waitlistRepository.WithTx(tx)
+		booking, err := bookingRepository.FindBookingByID(bookingID)
+		if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && booking.UserID != user.ID) {
+			return errBookingNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		resource, err := bookingRepository.LockResourceByID(booking.ResourceID)
+		if err != nil {
+			return err
+		}
+		booking, err = bookingRepository.LockBookingByID(bookingID)
+		if err != nil {
+			return err
+		}
+
+		if !booking.IsActive() {
+			return errBookingAlreadyCancelled
+		}
+		now := time.Now()
+		if !now.Before(booking.StartAt) {
+			return errBookingAlreadyStarted
+		}
+		rules, err := findRescheduleRules(bookingService.resourceRepository, resource.ID)
+		if err != nil {
+			return err
+		}
+		switch {
+		case rules.Disabled:
+			return errRescheduleNotAllowed
+		case now.After(rules.CutoffAt(booking.StartAt)):
+			return errRescheduleCutoffPassed
+		case rules.LimitReached(booking.RescheduleCount):
+			return errRescheduleLimitReached
+		}
+
+		startAt := rescheduleBookingDTO.StartAt
+		endAt := startAt.Add(booking.EndAt.Sub(booking.StartAt))
+		if startAt.Equal(booking.StartAt) {
+			return errRescheduleSameSlot
+		}
+		resourceSchedule, err := loadSchedule(bookingService.scheduleRepository, resource, startAt, endAt)
+		if err != nil {
+			return err
+		}
+		excluded := map[uuid.UUID]bool{booking.ID: true}
+		reason, err := findSlotConflict(bookingRepository, waitlistRepository, resource, resourceSchedule, startAt, endAt, booking.Seats, now, user.ID, excluded)
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			return slotConflictError(reason)
+		}
+		if booking.ProviderID != nil {
+			err = checkProviderFree(bookingRepository, bookingService.providerRepository.WithTx(tx), booking, startAt, endAt)
+			if err != nil {
+				return err
+			}
+		}
+
+		pricingRules, member, err := findPricing(bookingService.resourceRepository, resource.ID, user.ID)
+		if err != nil {
+			return err
+		}
+		quote := newPricer(resource, pricingRules, member).Quote(startAt, endAt, booking.Seats)
+		quote.ApplyAdjustments(&booking.Quote)
+
+		previous := *booking
+		refundAmount := rescheduleBooking(booking, startAt, endAt, quote, now)
+		reschedule = vo.RescheduleResponse{
+			PreviousStartAt: previous.StartAt,
+			PreviousEndAt:   previous.EndAt,
+			PreviousAmount:  previous.Amount,
+			Amount:          booking.Amount,
+			AmountDue:       booking.PaymentSchedule.AmountDue(booking.PaidAmount, now),
+			RefundAmount:    refundAmount,
+			Booking:         vo.NewBookingResponse(booking),
+		}
+		if !rescheduleBookingDTO.Confirm {
+			return nil
+		}
+
+		err = bookingRepository.UpdateBooking(booking)
+		if err != nil {
+			return err
+		}
+		err = bookingRepository.CreateBookingChange(&entity.BookingChange{
+			BookingID:       booking.ID,
+			ChangedBy:       user.ID,
+			Type:            entity.BOOKING_CHANGE_RESCHEDULED,
+			PreviousStartAt: previous.StartAt,
+			PreviousEndAt:   previous.EndAt,
+			StartAt:         booking.StartAt,
+			EndAt:           booking.EndAt,
+			PreviousAmount:  previous.Amount,
+			Amount:          booking.Amount,
+		})
+		if err != nil {
+			return err
+		}
+		ledgerRepository := bookingService.ledgerRepository.WithTx(tx)
+		err = postReschedule(ledgerRepository, resource.OrganizationID, booking, &previous)
+		if err != nil {
+			return err
+		}
+		paymentRepository := bookingService.paymentRepository.WithTx(tx)
+		err = refundPayments(paymentRepository, ledgerRepository, resource.OrganizationID, bookingService.paymentProvider, booking, refundAmount)
+		if err != nil {
+			return err
+		}
+		// payments started for the previous amount no longer match what is due
+		if booking.Amount != previous.Amount && reschedule.AmountDue > 0 {
+			err = voidPendingPayments(paymentRepository, booking, uuid.Nil)
+			if err != nil {
+				return err
+			}
+			reschedule.Payment, err = startPayment(paymentRepository, bookingService.paymentProvider, booking, reschedule.AmountDue)
+			if err != nil {
+				return err
+			}
+		}
+		err = notifyBooking(bookingService.notificationRepository.WithTx(tx), resource, booking, notification.EVENT_BOOKING_RESCHEDULED, now)
+		if err != nil {
+			return err
+		}
+
+		reschedule.Applied = true
+		reschedule.Booking = vo.NewBookingResponse(booking)
+		// the slot the booking left is offered to the waitlist
+		return promoteWaitlist(bookingRepository, waitlistRepository, bookingService.scheduleRepository, resource, now)
+	})
+	if err != nil {
+		return res, setBookingError(&res, err)
+	}
+
+	res.SetData(reschedule)
+	return res, fiber.StatusOK
+}
+
+// GetBookingHistory returns the changes made to the booking, the oldest first.
+func (bookingService *bookingService) GetBookingHistory(username string, bookingID uuid.UUID) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(bookingService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	booking, err := bookingService.bookingRepository.FindBookingByID(bookingID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && booking.UserID != user.ID) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_BOOKING_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	changes, err := bookingService.bookingRepository.FindBookingChanges(booking.ID)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	responses := []vo.BookingChangeResponse{}
+	for i := range changes {
+		responses = append(responses, vo.NewBookingChangeResponse(&changes[i]))
+	}
+	res.SetData(responses)
+	return res, fiber.StatusOK
+}
+
+// GetAvailability reports how many seats of the resource are left for the user
+// at the busiest moment of the period.
+func (bookingService *bookingService) GetAvailability(username string, resourceID uuid.UUID, availabilityQueryDTO *dto.AvailabilityQueryDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(bookingService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	startAt, startErr := time.Parse(time.RFC3339, availabilityQueryDTO.StartAt)
+	endAt, endErr := time.Parse(time.RFC3339, availabilityQueryDTO.EndAt)
+	if startErr != nil || endErr != nil || !endAt.After(startAt) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INVALID_PARAMETERS)
+		return res, fiber.StatusBadRequest
+	}
+
+	resource, err := bookingService.resourceRepository.FindResourceByID(resourceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_RESOURCE_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	available, err := findAvailableSeats(bookingService.bookingRepository, bookingService.waitlistRepository, resource, startAt, endAt, time.Now(), user.ID, nil)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	resourceSchedule, err := loadSchedule(bookingService.scheduleRepository, resource, startAt, endAt)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	availability := vo.AvailabilityResponse{
+		ResourceID:     resource.ID,
+		StartAt:        startAt,
+		EndAt:          endAt,
+		Capacity:       resource.Capacity,
+		BookedSeats:    resource.Capacity - available,
+		RemainingSeats: max(available, 0),
+		Open:           true,
+	}
+	if reason := scheduleConflict(resourceSchedule, startAt, endAt); reason != "" {
+		availability.Open = false
+		availability.ClosedReason = reason
+		availability.RemainingSeats = 0
+	}
+	res.SetData(availability)
+	return res, fiber.StatusOK
+}
+
+// HandleOverdueBookings cancels or flags, as their payment schedule says, the
+// confirmed bookings with a due not paid by now. A cancelled booking is
+// refunded under its cancellation policy as if the customer had cancelled
+// and its slot is offered to the waitlist. Bookings that have started are
+// only flagged.
+func (bookingService *bookingService) HandleOverdueBookings(now time.Time) (err error) {
+	bookings, err := bookingService.bookingRepository.FindOverdueBookings(now, OVERDUE_BATCH_SIZE)
+	if err != nil {
+		return err
+	}
+
+	errs := []error{}
+	for _, overdue := range bookings {
+		err := bookingService.bookingRepository.Transaction(func(tx *gorm.DB) error {
+			bookingRepository := bookingService.bookingRepository.WithTx(tx)
+			resource, err := bookingRepository.LockResourceByID(overdue.ResourceID)
+			if err != nil {
+				return err
+			}
+			booking, err := bookingRepository.LockBookingByID(overdue.ID)
+			if err != nil {
+				return err
+			}
+			// the booking may have been paid or cancelled since it was found
+			if booking.Status != entity.BOOKING_STATUS_CONFIRMED || booking.NextDueAt == nil || booking.NextDueAt.After(now) || booking.OverdueAt != nil {
+				return nil
+			}
+
+			if booking.OverdueAction != entity.OVERDUE_ACTION_CANCEL || !now.Before(booking.StartAt) {
+				booking.OverdueAt = &now
+				return bookingRepository.UpdateBooking(booking)
+			}
+			cancellation := previewCancellation(booking, now)
+			err = applyCancellation(bookingRepository, booking, &cancellation, now)
+			if err != nil {
+				return err
+			}
+			ledgerRepository := bookingService.ledgerRepository.WithTx(tx)
+			err = postCancellation(ledgerRepository, resource.OrganizationID, booking, "overdue balance")
+			if err != nil {
+				return err
+			}
+			err = refundBooking(bookingService.paymentRepository.WithTx(tx), ledgerRepository, resource.OrganizationID, bookingService.paymentProvider, booking)
+			if err != nil {
+				return err
+			}
+			err = notifyBooking(bookingService.notificationRepository.WithTx(tx), resource, booking, notification.EVENT_BOOKING_CANCELLED, now)
+			if err != nil {
+				return err
+			}
+			return promoteWaitlist(bookingRepository, bookingService.waitlistRepository.WithTx(tx), bookingService.scheduleRepository, resource, now)
+		})
+		if err != nil {
+			errs = append(errs, recordBookingFailure(bookingService.bookingRepository, &overdue, err, now))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ExpireStaleHolds frees the slots of pending bookings whose deposit wasn't
+// paid within the configured number of minutes of booking. Their pending
+// payments are voided, so a payment made too late is owed back to the
+// customer.
+func (bookingService *bookingService) ExpireStaleHolds(now time.Time) (err error) {
+	createdBefore := now.Add(-viper.GetDuration("booking.hold_minutes") * time.Minute)
+	bookings, err := bookingService.bookingRepository.FindStaleHolds(createdBefore, HOLD_BATCH_SIZE)
+	if err != nil {
+		return err
+	}
+
+	errs := []error{}
+	for _, stale := range bookings {
+		err := bookingService.bookingRepository.Transaction(func(tx *gorm.DB) error {
+			bookingRepository := bookingService.bookingRepository.WithTx(tx)
+			resource, err := bookingRepository.LockResourceByID(stale.ResourceID)
+			if err != nil {
+				return err
+			}
+			booking, err := bookingRepository.LockBookingByID(stale.ID)
+			if err != nil {
+				return err
+			}
+			// the deposit may have been paid since the booking was found
+			if booking.Status != entity.BOOKING_STATUS_PENDING {
+				return nil
+			}
+
+			booking.Status = entity.BOOKING_STATUS_EXPIRED
+			booking.Sequence++
+			err = bookingRepository.UpdateBooking(booking)
+			if err != nil {
+				return err
+			}
+			err = bookingRepository.ReleaseNoShowFees(booking.ID)
+			if err != nil {
+				return err
+			}
+			err = voidPendingPayments(bookingService.paymentRepository.WithTx(tx), booking, uuid.Nil)
+			if err != nil {
+				return err
+			}
+			err = postCancellation(bookingService.ledgerRepository.WithTx(tx), resource.OrganizationID, booking, "hold expired")
+			if err != nil {
+				return err
+			}
+			err = notifyBooking(bookingService.notificationRepository.WithTx(tx), resource, booking, notification.EVENT_BOOKING_CANCELLED, now)
+			if err != nil {
+				return err
+			}
+			return promoteWaitlist(bookingRepository, bookingService.waitlistRepository.WithTx(tx), bookingService.scheduleRepository, resource, now)
+		})
+		if err != nil {
+			errs = append(errs, recordBookingFailure(bookingService.bookingRepository, &stale, err, now))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// recordBookingFailure logs why the background handling of the booking
+// failed and records it on the booking, so the rest of the batch is handled
+// and the booking is retried after the others. It returns the error for the
+// batch to report.
+func recordBookingFailure(bookingRepository repository.IBookingRepository, booking *entity.Booking, err error, now time.Time) error {
+	logs.Error(err, zap.String("booking", booking.ID.String()))
+	recordErr := bookingRepository.RecordBookingFailure(booking.ID, err.Error(), now)
+	if recordErr != nil {
+		logs.Error(recordErr, zap.String("booking", booking.ID.String()))
+	}
+	return fmt.Errorf("booking %s: %w", booking.ID, err)
+}
+
+// previewCancellation computes the refund of cancelling the booking at the
+// given time under the policy snapshot taken when it was booked.
+func previewCancellation(booking *entity.Booking, now time.Time) vo.CancellationResponse {
+	cancellation := vo.CancellationResponse{
+		Amount:       booking.Amount,
+		MatchedRule:  booking.CancellationPolicy.MatchRule(now, booking.StartAt),
+		RefundAmount: booking.CancellationPolicy.RefundAmount(booking.Amount, now, booking.StartAt),
+		Booking:      vo.NewBookingResponse(booking),
+	}
+	// nothing has been paid for a booking still pending payment, and no more
+	// than the deposit may have been paid for a booking paid in instalments
+	if booking.Status == entity.BOOKING_STATUS_PENDING {
+		cancellation.RefundAmount = 0
+	}
+	if booking.HasPaymentSchedule() {
+		cancellation.RefundAmount = min(cancellation.RefundAmount, booking.PaidAmount)
+	}
+	return cancellation
+}
+
+func applyCancellation(bookingRepository repository.IBookingRepository, booking *entity.Booking, cancellation *vo.CancellationResponse, now time.Time) error {
+	booking.Status = entity.BOOKING_STATUS_CANCELLED
+	booking.RefundAmount = cancellation.RefundAmount
+	booking.CancelledAt = &now
+	booking.Sequence++
+	err := bookingRepository.UpdateBooking(booking)
+	if err != nil {
+		return err
+	}
+	err = bookingRepository.ReleaseNoShowFees(booking.ID)
+	if err != nil {
+		return err
+	}
+
+	cancellation.Applied = true
+	cancellation.Booking = vo.NewBookingResponse(booking)
+	return nil
+}
+
+// rescheduleBooking moves the booking to the slot at the price of the quote,
+// moving its dues not due yet with it, and returns what was paid beyond the
+// new amount to refund. The booking is reminded of again before its new
+// start.
+func rescheduleBooking(booking *entity.Booking, startAt time.Time, endAt time.Time, quote entity.Quote, now time.Time) (refundAmount int64) {
+	refundAmount = max(max(booking.PaidAmount-quote.Total, 0)-max(booking.PaidAmount-booking.Amount, 0), 0)
+	booking.PaymentSchedule = booking.PaymentSchedule.Rescheduled(startAt.Sub(booking.StartAt), quote.Total, now)
+	booking.StartAt = startAt
+	booking.EndAt = endAt
+	booking.Quote = quote
+	booking.Amount = quote.Total
+	booking.PaidAmount -= refundAmount
+	booking.NextDueAt = booking.PaymentSchedule.NextDueAt(booking.PaidAmount)
+	booking.RescheduleCount++
+	booking.Sequence++
+	booking.RemindedAt = nil
+	return refundAmount
+}
+
+// MarkNoShows marks confirmed bookings whose customer wasn't checked in within
+// the grace period after they started as no-shows, which count towards the
+// penalties of the customer.
+func (bookingService *bookingService) MarkNoShows(now time.Time) (err error) {
+	startedBefore := now.Add(-viper.GetDuration("booking.no_show.grace_minutes") * time.Minute)
+	bookings, err := bookingService.bookingRepository.FindMissedCheckIns(startedBefore.Add(-NO_SHOW_LOOKBACK), startedBefore, NO_SHOW_BATCH_SIZE)
+	if err != nil {
+		return err
+	}
+
+	errs := []error{}
+	for _, missed := range bookings {
+		err := bookingService.bookingRepository.Transaction(func(tx *gorm.DB) error {
+			bookingRepository := bookingService.bookingRepository.WithTx(tx)
+			booking, err := bookingRepository.LockBookingByID(missed.ID)
+			if err != nil {
+				return err
+			}
+			// the customer may have been checked in since the booking was found
+			if booking.Status != entity.BOOKING_STATUS_CONFIRMED || booking.CheckedInAt != nil {
+				return nil
+			}
+
+			booking.Status = entity.BOOKING_STATUS_NO_SHOW
+			return bookingRepository.UpdateBooking(booking)
+		})
+		if err != nil {
+			errs = append(errs, recordBookingFailure(bookingService.bookingRepository, &missed, err, now))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// GetNoShowRecord returns the no-shows of the user in the rolling window and
+// the penalties they bring on new bookings.
+func (bookingService *bookingService) GetNoShowRecord(username string) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(bookingService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	penalty, err := findNoShowPenalty(bookingService.bookingRepository, user.ID, time.Now())
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(vo.NoShowRecordResponse{
+		NoShows:        penalty.NoShows,
+		WindowDays:     viper.GetInt("booking.no_show.window_days"),
+		Fee:            penalty.Fee,
+		DepositPercent: penalty.DepositPercent,
+		BannedUntil:    penalty.BannedUntil,
+	})
+	return res, fiber.StatusOK
+}
@@ -0,0 +1,951 @@
+package service_test
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/notification"
+	"booking/internal/payment"
+	"booking/internal/service"
+	"booking/internal/vo"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestCreateBooking(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+	resource := &entity.Resource{ID: uuid.New(), HourlyRate: 50000, Capacity: 1}
+	startAt := time.Now().Add(72 * time.Hour).Truncate(time.Hour)
+	body := dto.CreateBookingDTO{
+		ResourceID: resource.ID.String(),
+		StartAt:    startAt,
+		EndAt:      startAt.Add(90 * time.Minute),
+	}
+
+	t.Run("When the booking starts in the past, should response status code 400 with error message", func(t *testing.T) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		bookingService := service.NewBookingService(authRepositoryMock, &resourceRepositoryMock{}, &bookingRepositoryMock{}, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), &providerRepositoryMock{}, &promoCodeRepositoryMock{}, newPaymentRepositoryMock(), newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret))
+		body := body
+		body.StartAt = time.Now().Add(-time.Hour)
+
+		res, statusCode := bookingService.CreateBooking(Username, &body)
+
+		assert.Equal(t, fiber.StatusBadRequest, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_BOOKING_IN_THE_PAST, res.ErrorMessage)
+	})
+
+	t.Run("When the slot overlaps another booking, should response status code 409 with error message", func(t *testing.T) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindCancellationPolicyByResourceID", resource.ID).Return(&entity.CancellationPolicy{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("FindPricingPolicyByResourceID", resource.ID).Return(&entity.PricingPolicy{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("FindPaymentScheduleByResourceID", resource.ID).Return(&entity.PaymentSchedule{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("IsResourceMember", resource.ID, user.ID).Return(false, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("LockResourceByID", resource.ID).Return(resource, nil)
+		bookingRepositoryMock.On("FindOverlappingBookings", resource.ID, startAt).Return([]entity.Booking{{ID: uuid.New(), StartAt: startAt, EndAt: startAt.Add(time.Hour), Seats: 1}}, nil)
+		bookingService := service.NewBookingService(authRepositoryMock, resourceRepositoryMock, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), &providerRepositoryMock{}, &promoCodeRepositoryMock{}, newPaymentRepositoryMock(), newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret))
+
+		res, statusCode := bookingService.CreateBooking(Username, &body)
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_BOOKING_SLOT_UNAVAILABLE, res.ErrorMessage)
+		bookingRepositoryMock.AssertNotCalled(t, "CreateBooking", resource.ID)
+	})
+
+	t.Run("When the resource does not exist, should response status code 404 with error message", func(t *testing.T) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindCancellationPolicyByResourceID", resource.ID).Return(&entity.CancellationPolicy{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("FindPricingPolicyByResourceID", resource.ID).Return(&entity.PricingPolicy{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("FindPaymentScheduleByResourceID", resource.ID).Return(&entity.PaymentSchedule{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("IsResourceMember", resource.ID, user.ID).Return(false, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("LockResourceByID", resource.ID).Return(&entity.Resource{}, gorm.ErrRecordNotFound)
+		bookingService := service.NewBookingService(authRepositoryMock, resourceRepositoryMock, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), &providerRepositoryMock{}, &promoCodeRepositoryMock{}, newPaymentRepositoryMock(), newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret))
+
+		res, statusCode := bookingService.CreateBooking(Username, &body)
+
+		assert.Equal(t, fiber.StatusNotFound, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_RESOURCE_NOT_FOUND, res.ErrorMessage)
+	})
+
+	t.Run("When the slot is free, should snapshot the cancellation policy and hold the slot pending payment", func(t *testing.T) {
+		rules := entity.CancellationRules{{HoursBeforeStart: 48, RefundPercent: 100}}
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindCancellationPolicyByResourceID", resource.ID).Return(&entity.CancellationPolicy{Rules: rules}, nil)
+		resourceRepositoryMock.On("FindPricingPolicyByResourceID", resource.ID).Return(&entity.PricingPolicy{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("FindPaymentScheduleByResourceID", resource.ID).Return(&entity.PaymentSchedule{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("IsResourceMember", resource.ID, user.ID).Return(false, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("LockResourceByID", resource.ID).Return(resource, nil)
+		bookingRepositoryMock.On("FindOverlappingBookings", resource.ID, startAt).Return([]entity.Booking{}, nil)
+		bookingRepositoryMock.On("CreateBooking", resource.ID).Return(nil)
+		notificationRepositoryMock := newNotificationRepositoryMock()
+		bookingService := service.NewBookingService(authRepositoryMock, resourceRepositoryMock, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), &providerRepositoryMock{}, &promoCodeRepositoryMock{}, newPaymentRepositoryMock(), newLedgerRepositoryMock(), notificationRepositoryMock, payment.NewFakeProvider(WebhookSecret))
+
+		res, statusCode := bookingService.CreateBooking(Username, &body)
+
+		isPass := assert.Equal(t, fiber.StatusCreated, statusCode)
+		if isPass {
+			booking := res.Data.(vo.BookingResponse)
+			assert.Equal(t, entity.BOOKING_STATUS_PENDING, booking.Status)
+			assert.Equal(t, int64(75000), booking.Amount)
+			assert.Equal(t, int64(75000), booking.Payment.Amount)
+			assert.NotEmpty(t, booking.Payment.ClientSecret)
+			assert.Equal(t, booking.Amount, booking.Quote.Total)
+			assert.Equal(t, 90, booking.Quote.Minutes)
+			assert.Equal(t, []entity.CancellationRule(rules), booking.CancellationPolicy)
+			// the booking is only confirmed, and the user notified, once paid
+			assert.Empty(t, notificationRepositoryMock.notifications)
+		}
+	})
+
+	t.Run("When the resource takes a deposit, should schedule the balance and ask for the deposit only", func(t *testing.T) {
+		schedule := &entity.PaymentSchedule{ResourceID: resource.ID, DepositPercent: 20, Instalments: entity.PaymentInstalments{{DaysBeforeStart: 1, Percent: 80}}, OverdueAction: entity.OVERDUE_ACTION_CANCEL}
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindCancellationPolicyByResourceID", resource.ID).Return(&entity.CancellationPolicy{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("FindPricingPolicyByResourceID", resource.ID).Return(&entity.PricingPolicy{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("FindPaymentScheduleByResourceID", resource.ID).Return(schedule, nil)
+		resourceRepositoryMock.On("IsResourceMember", resource.ID, user.ID).Return(false, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("LockResourceByID", resource.ID).Return(resource, nil)
+		bookingRepositoryMock.On("FindOverlappingBookings", resource.ID, startAt).Return([]entity.Booking{}, nil)
+		bookingRepositoryMock.On("CreateBooking", resource.ID).Return(nil)
+		ledgerRepositoryMock := newLedgerRepositoryMock()
+		bookingService := service.NewBookingService(authRepositoryMock, resourceRepositoryMock, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), &providerRepositoryMock{}, &promoCodeRepositoryMock{}, newPaymentRepositoryMock(), ledgerRepositoryMock, newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret))
+
+		res, statusCode := bookingService.CreateBooking(Username, &body)
+
+		isPass := assert.Equal(t, fiber.StatusCreated, statusCode)
+		if isPass {
+			booking := res.Data.(vo.BookingResponse)
+			assert.Equal(t, entity.BOOKING_STATUS_PENDING, booking.Status)
+			assert.Equal(t, int64(15000), booking.Payment.Amount)
+			assert.Equal(t, int64(75000), ledgerRepositoryMock.balance(resource.OrganizationID, entity.LEDGER_ACCOUNT_RECEIVABLE))
+			assert.Equal(t, int64(-75000), ledgerRepositoryMock.balance(resource.OrganizationID, entity.LEDGER_ACCOUNT_REVENUE))
+			assert.Len(t, booking.PaymentSchedule, 2)
+			assert.Equal(t, int64(60000), booking.PaymentSchedule[1].Amount)
+			assert.Equal(t, startAt.AddDate(0, 0, -1), booking.PaymentSchedule[1].DueAt)
+			assert.Equal(t, int64(75000), booking.OutstandingAmount)
+		}
+	})
+}
+
+func TestCreateBookingWithSeats(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+	resource := &entity.Resource{ID: uuid.New(), HourlyRate: 50000, Capacity: 5}
+	startAt := time.Now().Add(72 * time.Hour).Truncate(time.Hour)
+	// 3 seats are taken in the first hour, 2 in the second
+	overlapping := []entity.Booking{
+		{ID: uuid.New(), StartAt: startAt, EndAt: startAt.Add(time.Hour), Seats: 3},
+		{ID: uuid.New(), StartAt: startAt.Add(time.Hour), EndAt: startAt.Add(2 * time.Hour), Seats: 2},
+	}
+	newBookingService := func(bookingRepositoryMock *bookingRepositoryMock) service.IBookingService {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindCancellationPolicyByResourceID", resource.ID).Return(&entity.CancellationPolicy{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("FindPricingPolicyByResourceID", resource.ID).Return(&entity.PricingPolicy{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("FindPaymentScheduleByResourceID", resource.ID).Return(&entity.PaymentSchedule{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("IsResourceMember", resource.ID, user.ID).Return(false, nil)
+		resourceRepositoryMock.On("FindResourceByID", resource.ID).Return(resource, nil)
+		bookingRepositoryMock.On("LockResourceByID", resource.ID).Return(resource, nil)
+		bookingRepositoryMock.On("FindOverlappingBookings", resource.ID, mock.MatchedBy(startAt.Equal)).Return(overlapping, nil)
+		return service.NewBookingService(authRepositoryMock, resourceRepositoryMock, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), &providerRepositoryMock{}, &promoCodeRepositoryMock{}, newPaymentRepositoryMock(), newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret))
+	}
+
+	t.Run("When the seats fit the busiest moment of the period, should book them", func(t *testing.T) {
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("CreateBooking", resource.ID).Return(nil)
+		bookingService := newBookingService(bookingRepositoryMock)
+
+		res, statusCode := bookingService.CreateBooking(Username, &dto.CreateBookingDTO{
+			ResourceID: resource.ID.String(),
+			StartAt:    startAt,
+			EndAt:      startAt.Add(90 * time.Minute),
+			Seats:      2,
+		})
+
+		isPass := assert.Equal(t, fiber.StatusCreated, statusCode)
+		if isPass {
+			booking := res.Data.(vo.BookingResponse)
+			assert.Equal(t, 2, booking.Seats)
+			assert.Equal(t, int64(150000), booking.Amount)
+		}
+	})
+
+	t.Run("When the seats exceed the seats left, should response status code 409 with error message", func(t *testing.T) {
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingService := newBookingService(bookingRepositoryMock)
+
+		res, statusCode := bookingService.CreateBooking(Username, &dto.CreateBookingDTO{
+			ResourceID: resource.ID.String(),
+			StartAt:    startAt,
+			EndAt:      startAt.Add(90 * time.Minute),
+			Seats:      3,
+		})
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_BOOKING_SLOT_UNAVAILABLE, res.ErrorMessage)
+		bookingRepositoryMock.AssertNotCalled(t, "CreateBooking", resource.ID)
+	})
+
+	t.Run("When the seats exceed the capacity, should response status code 400 with error message", func(t *testing.T) {
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingService := newBookingService(bookingRepositoryMock)
+
+		res, statusCode := bookingService.CreateBooking(Username, &dto.CreateBookingDTO{
+			ResourceID: resource.ID.String(),
+			StartAt:    startAt,
+			EndAt:      startAt.Add(90 * time.Minute),
+			Seats:      6,
+		})
+
+		assert.Equal(t, fiber.StatusBadRequest, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_SEATS_EXCEED_CAPACITY, res.ErrorMessage)
+	})
+
+	t.Run("When get the availability, should response the seats left at the busiest moment", func(t *testing.T) {
+		bookingService := newBookingService(&bookingRepositoryMock{})
+
+		res, statusCode := bookingService.GetAvailability(Username, resource.ID, &dto.AvailabilityQueryDTO{
+			StartAt: startAt.Format(time.RFC3339),
+			EndAt:   startAt.Add(2 * time.Hour).Format(time.RFC3339),
+		})
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			availability := res.Data.(vo.AvailabilityResponse)
+			assert.Equal(t, 5, availability.Capacity)
+			assert.Equal(t, 3, availability.BookedSeats)
+			assert.Equal(t, 2, availability.RemainingSeats)
+		}
+	})
+}
+
+func TestCancelBooking(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+	newBooking := func(startAt time.Time) *entity.Booking {
+		return &entity.Booking{
+			ID:      uuid.New(),
+			UserID:  user.ID,
+			StartAt: startAt,
+			EndAt:   startAt.Add(time.Hour),
+			Status:  entity.BOOKING_STATUS_CONFIRMED,
+			Amount:  100000,
+			CancellationPolicy: entity.CancellationRules{
+				{HoursBeforeStart: 48, RefundPercent: 100},
+				{HoursBeforeStart: 24, RefundPercent: 50},
+			},
+		}
+	}
+
+	t.Run("When preview the cancellation, should response the refund without updating the booking", func(t *testing.T) {
+		booking := newBooking(time.Now().Add(30 * time.Hour))
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("FindBookingByID", booking.ID).Return(booking, nil)
+		bookingRepositoryMock.On("LockResourceByID", booking.ResourceID).Return(&entity.Resource{}, nil)
+		bookingRepositoryMock.On("LockBookingByID", booking.ID).Return(booking, nil)
+		bookingService := service.NewBookingService(authRepositoryMock, &resourceRepositoryMock{}, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), &providerRepositoryMock{}, &promoCodeRepositoryMock{}, newPaymentRepositoryMock(), newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret))
+
+		res, statusCode := bookingService.CancelBooking(Username, booking.ID, &dto.CancelBookingDTO{Confirm: false})
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			cancellation := res.Data.(vo.CancellationResponse)
+			assert.False(t, cancellation.Applied)
+			assert.Equal(t, int64(50000), cancellation.RefundAmount)
+			assert.Equal(t, entity.BOOKING_STATUS_CONFIRMED, cancellation.Booking.Status)
+		}
+		bookingRepositoryMock.AssertNotCalled(t, "UpdateBooking", booking.ID)
+	})
+
+	t.Run("When confirm the cancellation, should cancel the booking and refund the refund amount", func(t *testing.T) {
+		booking := newBooking(time.Now().Add(72 * time.Hour))
+		paymentProvider := payment.NewFakeProvider(WebhookSecret)
+		intent, _ := paymentProvider.CreateIntent(payment.IntentRequest{Reference: booking.ID.String(), Amount: booking.Amount, Currency: payment.CURRENCY_THB})
+		paymentProvider.Authorize(intent.ID)
+		paymentProvider.Capture(intent.ID)
+		captured := entity.Payment{ID: uuid.New(), BookingID: booking.ID, Provider: paymentProvider.Name(), IntentID: intent.ID, Amount: booking.Amount, Status: entity.PAYMENT_STATUS_CAPTURED}
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("FindBookingByID", booking.ID).Return(booking, nil)
+		bookingRepositoryMock.On("LockResourceByID", booking.ResourceID).Return(&entity.Resource{}, nil)
+		bookingRepositoryMock.On("LockBookingByID", booking.ID).Return(booking, nil)
+		bookingRepositoryMock.On("UpdateBooking", booking.ID).Return(nil)
+		paymentRepositoryMock := &paymentRepositoryMock{}
+		paymentRepositoryMock.On("FindPaymentsByBookingID", booking.ID).Return([]entity.Payment{captured}, nil)
+		paymentRepositoryMock.On("UpdatePayment", captured.ID, entity.PAYMENT_STATUS_CAPTURED).Return(nil)
+		notificationRepositoryMock := newNotificationRepositoryMock()
+		bookingService := service.NewBookingService(authRepositoryMock, &resourceRepositoryMock{}, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), &providerRepositoryMock{}, &promoCodeRepositoryMock{}, paymentRepositoryMock, newLedgerRepositoryMock(), notificationRepositoryMock, paymentProvider)
+
+		res, statusCode := bookingService.CancelBooking(Username, booking.ID, &dto.CancelBookingDTO{Confirm: true})
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			cancellation := res.Data.(vo.CancellationResponse)
+			assert.True(t, cancellation.Applied)
+			assert.Equal(t, int64(100000), cancellation.RefundAmount)
+			assert.Equal(t, entity.BOOKING_STATUS_CANCELLED, cancellation.Booking.Status)
+			assert.NotNil(t, cancellation.Booking.CancelledAt)
+			paymentRepositoryMock.AssertCalled(t, "UpdatePayment", captured.ID, entity.PAYMENT_STATUS_CAPTURED)
+			assert.ErrorIs(t, paymentProvider.Refund(intent.ID, 1), payment.ErrRefundTooLarge)
+			if assert.Len(t, notificationRepositoryMock.notifications, 1) {
+				assert.Equal(t, notification.EVENT_BOOKING_CANCELLED, notificationRepositoryMock.notifications[0].Event)
+				assert.Equal(t, Username, notificationRepositoryMock.notifications[0].Recipient)
+				assert.Contains(t, notificationRepositoryMock.notifications[0].Body, "1000.00 THB")
+			}
+		}
+	})
+
+	t.Run("When the booking is already cancelled, should response status code 409 with error message", func(t *testing.T) {
+		booking := newBooking(time.Now().Add(72 * time.Hour))
+		booking.Status = entity.BOOKING_STATUS_CANCELLED
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("FindBookingByID", booking.ID).Return(booking, nil)
+		bookingRepositoryMock.On("LockResourceByID", booking.ResourceID).Return(&entity.Resource{}, nil)
+		bookingRepositoryMock.On("LockBookingByID", booking.ID).Return(booking, nil)
+		bookingService := service.NewBookingService(authRepositoryMock, &resourceRepositoryMock{}, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), &providerRepositoryMock{}, &promoCodeRepositoryMock{}, newPaymentRepositoryMock(), newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret))
+
+		res, statusCode := bookingService.CancelBooking(Username, booking.ID, &dto.CancelBookingDTO{Confirm: true})
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_BOOKING_ALREADY_CANCELLED, res.ErrorMessage)
+	})
+
+	t.Run("When the booking has already started, should response status code 409 with error message", func(t *testing.T) {
+		booking := newBooking(time.Now().Add(-time.Minute))
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("FindBookingByID", booking.ID).Return(booking, nil)
+		bookingRepositoryMock.On("LockResourceByID", booking.ResourceID).Return(&entity.Resource{}, nil)
+		bookingRepositoryMock.On("LockBookingByID", booking.ID).Return(booking, nil)
+		bookingService := service.NewBookingService(authRepositoryMock, &resourceRepositoryMock{}, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), &providerRepositoryMock{}, &promoCodeRepositoryMock{}, newPaymentRepositoryMock(), newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret))
+
+		res, statusCode := bookingService.CancelBooking(Username, booking.ID, &dto.CancelBookingDTO{Confirm: true})
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_BOOKING_ALREADY_STARTED, res.ErrorMessage)
+	})
+
+	t.Run("When the booking belongs to another user, should response status code 404 with error message", func(t *testing.T) {
+		booking := newBooking(time.Now().Add(72 * time.Hour))
+		booking.UserID = uuid.New()
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("FindBookingByID", booking.ID).Return(booking, nil)
+		bookingRepositoryMock.On("LockResourceByID", booking.ResourceID).Return(&entity.Resource{}, nil)
+		bookingRepositoryMock.On("LockBookingByID", booking.ID).Return(booking, nil)
+		bookingService := service.NewBookingService(authRepositoryMock, &resourceRepositoryMock{}, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), &providerRepositoryMock{}, &promoCodeRepositoryMock{}, newPaymentRepositoryMock(), newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret))
+
+		res, statusCode := bookingService.CancelBooking(Username, booking.ID, &dto.CancelBookingDTO{Confirm: true})
+
+		assert.Equal(t, fiber.StatusNotFound, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_BOOKING_NOT_FOUND, res.ErrorMessage)
+	})
+}
+
+func TestHandleOverdueBookings(t *testing.T) {
+	resource := &entity.Resource{ID: uuid.New(), Capacity: 1}
+	now := time.Now()
+	newOverdueBooking := func(overdueAction string) *entity.Booking {
+		startAt := now.Add(72 * time.Hour).Truncate(time.Hour)
+		dueAt := now.Add(-time.Hour)
+		return &entity.Booking{
+			ID:                 uuid.New(),
+			ResourceID:         resource.ID,
+			StartAt:            startAt,
+			EndAt:              startAt.Add(time.Hour),
+			Status:             entity.BOOKING_STATUS_CONFIRMED,
+			Amount:             100000,
+			PaidAmount:         30000,
+			PaymentSchedule:    entity.PaymentDues{{DueAt: now.AddDate(0, 0, -7), Amount: 30000}, {DueAt: dueAt, Amount: 70000}},
+			NextDueAt:          &dueAt,
+			OverdueAction:      overdueAction,
+			CancellationPolicy: entity.CancellationRules{{HoursBeforeStart: 48, RefundPercent: 100}},
+		}
+	}
+	newBookingRepositoryMock := func(found *entity.Booking, booking *entity.Booking) *bookingRepositoryMock {
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("FindOverdueBookings", service.OVERDUE_BATCH_SIZE).Return([]entity.Booking{*found}, nil)
+		bookingRepositoryMock.On("LockResourceByID", resource.ID).Return(resource, nil)
+		bookingRepositoryMock.On("LockBookingByID", booking.ID).Return(booking, nil)
+		bookingRepositoryMock.On("UpdateBooking", booking.ID).Return(nil)
+		return bookingRepositoryMock
+	}
+
+	t.Run("When the schedule cancels overdue bookings, should cancel the booking, refund the deposit and offer the slot to the waitlist", func(t *testing.T) {
+		booking := newOverdueBooking(entity.OVERDUE_ACTION_CANCEL)
+		bookingRepositoryMock := newBookingRepositoryMock(booking, booking)
+		paymentRepositoryMock := &paymentRepositoryMock{}
+		paymentRepositoryMock.On("FindPaymentsByBookingID", booking.ID).Return([]entity.Payment{}, nil)
+		waitlistRepositoryMock := newWaitlistRepositoryMock()
+		ledgerRepositoryMock := newLedgerRepositoryMock()
+		bookingService := service.NewBookingService(&authRepositoryMock{}, &resourceRepositoryMock{}, bookingRepositoryMock, waitlistRepositoryMock, newScheduleRepositoryMock(), &providerRepositoryMock{}, &promoCodeRepositoryMock{}, paymentRepositoryMock, ledgerRepositoryMock, newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret))
+
+		err := bookingService.HandleOverdueBookings(now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.BOOKING_STATUS_CANCELLED, booking.Status)
+		assert.Equal(t, int64(30000), booking.RefundAmount)
+		assert.Equal(t, int64(100000), ledgerRepositoryMock.balance(resource.OrganizationID, entity.LEDGER_ACCOUNT_REVENUE))
+		assert.Equal(t, int64(-70000), ledgerRepositoryMock.balance(resource.OrganizationID, entity.LEDGER_ACCOUNT_RECEIVABLE))
+		assert.Equal(t, int64(-30000), ledgerRepositoryMock.balance(resource.OrganizationID, entity.LEDGER_ACCOUNT_REFUNDS_PAYABLE))
+		waitlistRepositoryMock.AssertCalled(t, "FindWaitingEntries", resource.ID)
+	})
+
+	t.Run("When the schedule flags overdue bookings, should flag the booking and keep it confirmed", func(t *testing.T) {
+		booking := newOverdueBooking(entity.OVERDUE_ACTION_FLAG)
+		bookingRepositoryMock := newBookingRepositoryMock(booking, booking)
+		bookingService := service.NewBookingService(&authRepositoryMock{}, &resourceRepositoryMock{}, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), &providerRepositoryMock{}, &promoCodeRepositoryMock{}, &paymentRepositoryMock{}, newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret))
+
+		err := bookingService.HandleOverdueBookings(now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.BOOKING_STATUS_CONFIRMED, booking.Status)
+		assert.Equal(t, &now, booking.OverdueAt)
+	})
+
+	t.Run("When the balance was paid since the booking was found, should leave the booking as it is", func(t *testing.T) {
+		booking := newOverdueBooking(entity.OVERDUE_ACTION_CANCEL)
+		paid := *booking
+		paid.PaidAmount = paid.Amount
+		paid.NextDueAt = nil
+		bookingRepositoryMock := newBookingRepositoryMock(booking, &paid)
+		bookingService := service.NewBookingService(&authRepositoryMock{}, &resourceRepositoryMock{}, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), &providerRepositoryMock{}, &promoCodeRepositoryMock{}, &paymentRepositoryMock{}, newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret))
+
+		err := bookingService.HandleOverdueBookings(now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.BOOKING_STATUS_CONFIRMED, paid.Status)
+		bookingRepositoryMock.AssertNotCalled(t, "UpdateBooking", booking.ID)
+	})
+
+	t.Run("When a booking fails, should record the failure on it, handle the rest of the batch and return the error", func(t *testing.T) {
+		failing := newOverdueBooking(entity.OVERDUE_ACTION_FLAG)
+		booking := newOverdueBooking(entity.OVERDUE_ACTION_FLAG)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("FindOverdueBookings", service.OVERDUE_BATCH_SIZE).Return([]entity.Booking{*failing, *booking}, nil)
+		bookingRepositoryMock.On("LockResourceByID", resource.ID).Return(resource, nil)
+		bookingRepositoryMock.On("LockBookingByID", failing.ID).Return(&entity.Booking{}, errors.New("connection reset"))
+		bookingRepositoryMock.On("RecordBookingFailure", failing.ID).Return(nil)
+		bookingRepositoryMock.On("LockBookingByID", booking.ID).Return(booking, nil)
+		bookingRepositoryMock.On("UpdateBooking", booking.ID).Return(nil)
+		bookingService := service.NewBookingService(&authRepositoryMock{}, &resourceRepositoryMock{}, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), &providerRepositoryMock{}, &promoCodeRepositoryMock{}, &paymentRepositoryMock{}, newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret))
+
+		err := bookingService.HandleOverdueBookings(now)
+
+		assert.ErrorContains(t, err, "connection reset")
+		bookingRepositoryMock.AssertCalled(t, "RecordBookingFailure", failing.ID)
+		assert.Equal(t, &now, booking.OverdueAt)
+	})
+}
+
+func TestExpireStaleHolds(t *testing.T) {
+	viper.Set("booking.hold_minutes", 30)
+	resource := &entity.Resource{ID: uuid.New(), Name: "Meeting Room", Capacity: 1}
+	now := time.Now()
+	newHold := func() *entity.Booking {
+		startAt := now.Add(72 * time.Hour).Truncate(time.Hour)
+		return &entity.Booking{
+			ID:              uuid.New(),
+			UserID:          uuid.New(),
+			ResourceID:      resource.ID,
+			StartAt:         startAt,
+			EndAt:           startAt.Add(time.Hour),
+			Status:          entity.BOOKING_STATUS_PENDING,
+			Amount:          100000,
+			PaymentSchedule: entity.PaymentDues{{DueAt: now.Add(-time.Hour), Amount: 30000}, {DueAt: startAt, Amount: 70000}},
+			CreatedAt:       now.Add(-time.Hour),
+		}
+	}
+
+	t.Run("When the deposit isn't paid in time, should expire the booking, void its payment, free the slot and release its no-show fees", func(t *testing.T) {
+		booking := newHold()
+		pending := entity.Payment{ID: uuid.New(), BookingID: booking.ID, Amount: 30000, Status: entity.PAYMENT_STATUS_PENDING}
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("FindStaleHolds", now.Add(-30*time.Minute)).Return([]entity.Booking{*booking}, nil)
+		bookingRepositoryMock.On("LockResourceByID", resource.ID).Return(resource, nil)
+		bookingRepositoryMock.On("LockBookingByID", booking.ID).Return(booking, nil)
+		bookingRepositoryMock.On("UpdateBooking", booking.ID).Return(nil)
+		paymentRepositoryMock := &paymentRepositoryMock{}
+		paymentRepositoryMock.On("FindPaymentsByBookingID", booking.ID).Return([]entity.Payment{pending}, nil)
+		paymentRepositoryMock.On("UpdatePayment", pending.ID, entity.PAYMENT_STATUS_VOIDED).Return(nil)
+		ledgerRepositoryMock := newLedgerRepositoryMock()
+		notificationRepositoryMock := newNotificationRepositoryMock()
+		bookingService := service.NewBookingService(&authRepositoryMock{}, &resourceRepositoryMock{}, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), &providerRepositoryMock{}, &promoCodeRepositoryMock{}, paymentRepositoryMock, ledgerRepositoryMock, notificationRepositoryMock, payment.NewFakeProvider(WebhookSecret))
+
+		err := bookingService.ExpireStaleHolds(now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.BOOKING_STATUS_EXPIRED, booking.Status)
+		assert.Equal(t, 1, booking.Sequence)
+		paymentRepositoryMock.AssertCalled(t, "UpdatePayment", pending.ID, entity.PAYMENT_STATUS_VOIDED)
+		assert.Equal(t, int64(-100000), ledgerRepositoryMock.balance(resource.OrganizationID, entity.LEDGER_ACCOUNT_RECEIVABLE))
+		assert.Equal(t, []uuid.UUID{booking.ID}, bookingRepositoryMock.released)
+		if assert.Len(t, notificationRepositoryMock.notifications, 1) {
+			assert.Equal(t, notification.EVENT_BOOKING_CANCELLED, notificationRepositoryMock.notifications[0].Event)
+		}
+	})
+
+	t.Run("When the deposit was paid since the booking was found, should keep the booking", func(t *testing.T) {
+		booking := newHold()
+		confirmed := *booking
+		confirmed.Status = entity.BOOKING_STATUS_CONFIRMED
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("FindStaleHolds", now.Add(-30*time.Minute)).Return([]entity.Booking{*booking}, nil)
+		bookingRepositoryMock.On("LockResourceByID", resource.ID).Return(resource, nil)
+		bookingRepositoryMock.On("LockBookingByID", booking.ID).Return(&confirmed, nil)
+		bookingService := service.NewBookingService(&authRepositoryMock{}, &resourceRepositoryMock{}, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), &providerRepositoryMock{}, &promoCodeRepositoryMock{}, &paymentRepositoryMock{}, newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret))
+
+		err := bookingService.ExpireStaleHolds(now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.BOOKING_STATUS_CONFIRMED, confirmed.Status)
+		bookingRepositoryMock.AssertNotCalled(t, "UpdateBooking", booking.ID)
+	})
+}
+
+func TestCreateBookingWithNoShows(t *testing.T) {
+	const Username = "example@gmail.com"
+	viper.Set("booking.no_show.window_days", 90)
+	viper.Set("booking.no_show.fee_after", 1)
+	viper.Set("booking.no_show.fee", 10000)
+	viper.Set("booking.no_show.deposit_after", 2)
+	viper.Set("booking.no_show.deposit_percent", 100)
+	viper.Set("booking.no_show.ban_after", 3)
+	viper.Set("booking.no_show.ban_days", 30)
+	t.Cleanup(func() { viper.Set("booking.no_show.window_days", 0) })
+	user := &entity.User{ID: uuid.New(), Username: Username}
+	resource := &entity.Resource{ID: uuid.New(), HourlyRate: 50000, Capacity: 1}
+	startAt := time.Now().Add(72 * time.Hour).Truncate(time.Hour)
+	body := dto.CreateBookingDTO{
+		ResourceID: resource.ID.String(),
+		StartAt:    startAt,
+		EndAt:      startAt.Add(90 * time.Minute),
+	}
+	noShows := func(daysAgo ...int) []entity.Booking {
+		bookings := []entity.Booking{}
+		for _, days := range daysAgo {
+			bookings = append(bookings, entity.Booking{ID: uuid.New(), UserID: user.ID, Status: entity.BOOKING_STATUS_NO_SHOW, StartAt: time.Now().AddDate(0, 0, -days)})
+		}
+		return bookings
+	}
+	// charged is how many no-shows the booking charges its fee for, fewer than
+	// it found when another booking charged them meanwhile
+	newBookingService := func(noShows []entity.Booking, charged int64) (service.IBookingService, *bookingRepositoryMock) {
+		schedule := &entity.PaymentSchedule{ResourceID: resource.ID, DepositPercent: 20, Instalments: entity.PaymentInstalments{{DaysBeforeStart: 1, Percent: 80}}}
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindCancellationPolicyByResourceID", resource.ID).Return(&entity.CancellationPolicy{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("FindPricingPolicyByResourceID", resource.ID).Return(&entity.PricingPolicy{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("FindPaymentScheduleByResourceID", resource.ID).Return(schedule, nil)
+		resourceRepositoryMock.On("IsResourceMember", resource.ID, user.ID).Return(false, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("FindNoShows", user.ID, mock.Anything).Return(noShows, nil)
+		bookingRepositoryMock.On("LockResourceByID", resource.ID).Return(resource, nil)
+		bookingRepositoryMock.On("FindOverlappingBookings", resource.ID, startAt).Return([]entity.Booking{}, nil)
+		bookingRepositoryMock.On("CreateBooking", resource.ID).Return(nil)
+		bookingRepositoryMock.On("ChargeNoShowFees", mock.Anything).Return(charged, nil)
+		return service.NewBookingService(authRepositoryMock, resourceRepositoryMock, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), &providerRepositoryMock{}, &promoCodeRepositoryMock{}, newPaymentRepositoryMock(), newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret)), bookingRepositoryMock
+	}
+
+	t.Run("When the user has one no-show, should charge the no-show fee", func(t *testing.T) {
+		noShows := noShows(10)
+		bookingService, bookingRepositoryMock := newBookingService(noShows, 1)
+
+		res, statusCode := bookingService.CreateBooking(Username, &body)
+
+		isPass := assert.Equal(t, fiber.StatusCreated, statusCode)
+		if isPass {
+			booking := res.Data.(vo.BookingResponse)
+			assert.Equal(t, int64(85000), booking.Amount)
+			assert.Equal(t, entity.QUOTE_LINE_NO_SHOW_FEE, booking.Quote.Lines[len(booking.Quote.Lines)-1].Type)
+			assert.Equal(t, int64(17000), booking.Payment.Amount)
+			bookingRepositoryMock.AssertCalled(t, "ChargeNoShowFees", []uuid.UUID{noShows[0].ID})
+		}
+	})
+
+	t.Run("When the fee of the no-show was charged on an earlier booking, should not charge it again", func(t *testing.T) {
+		noShows := noShows(10)
+		chargedOn := uuid.New()
+		noShows[0].NoShowFeeBookingID = &chargedOn
+		bookingService, bookingRepositoryMock := newBookingService(noShows, 0)
+
+		res, statusCode := bookingService.CreateBooking(Username, &body)
+
+		isPass := assert.Equal(t, fiber.StatusCreated, statusCode)
+		if isPass {
+			assert.Equal(t, int64(75000), res.Data.(vo.BookingResponse).Amount)
+			bookingRepositoryMock.AssertNotCalled(t, "ChargeNoShowFees", mock.Anything)
+		}
+	})
+
+	t.Run("When another booking made at the same time charged the no-show first, should roll the booking back and response status code 409 with error message", func(t *testing.T) {
+		noShows := noShows(10)
+		bookingService, bookingRepositoryMock := newBookingService(noShows, 0)
+
+		res, statusCode := bookingService.CreateBooking(Username, &body)
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_NO_SHOW_FEE_CHARGED, res.ErrorMessage)
+		bookingRepositoryMock.AssertCalled(t, "ChargeNoShowFees", []uuid.UUID{noShows[0].ID})
+	})
+
+	t.Run("When the user has two no-shows, should charge the fee of each and require the whole amount up front", func(t *testing.T) {
+		bookingService, _ := newBookingService(noShows(10, 20), 2)
+
+		res, statusCode := bookingService.CreateBooking(Username, &body)
+
+		isPass := assert.Equal(t, fiber.StatusCreated, statusCode)
+		if isPass {
+			booking := res.Data.(vo.BookingResponse)
+			assert.Equal(t, entity.BOOKING_STATUS_PENDING, booking.Status)
+			assert.Equal(t, int64(95000), booking.Payment.Amount)
+			assert.Len(t, booking.PaymentSchedule, 1)
+		}
+	})
+
+	t.Run("When the user has three no-shows and the latest is recent, should refuse the booking", func(t *testing.T) {
+		bookingService, bookingRepositoryMock := newBookingService(noShows(10, 20, 30), 3)
+
+		res, statusCode := bookingService.CreateBooking(Username, &body)
+
+		assert.Equal(t, fiber.StatusForbidden, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_BOOKING_BANNED, res.ErrorMessage)
+		bookingRepositoryMock.AssertNotCalled(t, "CreateBooking", resource.ID)
+	})
+
+	t.Run("When the ban after the latest no-show is over, should take the booking with the other penalties", func(t *testing.T) {
+		bookingService, _ := newBookingService(noShows(40, 50, 60), 3)
+
+		res, statusCode := bookingService.CreateBooking(Username, &body)
+
+		isPass := assert.Equal(t, fiber.StatusCreated, statusCode)
+		if isPass {
+			assert.Equal(t, int64(105000), res.Data.(vo.BookingResponse).Payment.Amount)
+		}
+	})
+}
+
+func TestMarkNoShows(t *testing.T) {
+	viper.Set("booking.no_show.grace_minutes", 15)
+	now := time.Now()
+	newMissed := func() *entity.Booking {
+		return &entity.Booking{ID: uuid.New(), UserID: uuid.New(), ResourceID: uuid.New(), StartAt: now.Add(-time.Hour), EndAt: now, Status: entity.BOOKING_STATUS_CONFIRMED}
+	}
+
+	t.Run("When the customer wasn't checked in within the grace period, should mark the booking no-show", func(t *testing.T) {
+		booking := newMissed()
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("FindMissedCheckIns", now.Add(-15*time.Minute)).Return([]entity.Booking{*booking}, nil)
+		bookingRepositoryMock.On("LockBookingByID", booking.ID).Return(booking, nil)
+		bookingRepositoryMock.On("UpdateBooking", booking.ID).Return(nil)
+		bookingService := service.NewBookingService(&authRepositoryMock{}, &resourceRepositoryMock{}, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), &providerRepositoryMock{}, &promoCodeRepositoryMock{}, newPaymentRepositoryMock(), newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret))
+
+		err := bookingService.MarkNoShows(now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.BOOKING_STATUS_NO_SHOW, booking.Status)
+	})
+
+	t.Run("When the customer was checked in since the booking was found, should keep the booking", func(t *testing.T) {
+		booking := newMissed()
+		checkedIn := *booking
+		checkedInAt := now.Add(-time.Minute)
+		checkedIn.CheckedInAt = &checkedInAt
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("FindMissedCheckIns", now.Add(-15*time.Minute)).Return([]entity.Booking{*booking}, nil)
+		bookingRepositoryMock.On("LockBookingByID", booking.ID).Return(&checkedIn, nil)
+		bookingService := service.NewBookingService(&authRepositoryMock{}, &resourceRepositoryMock{}, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), &providerRepositoryMock{}, &promoCodeRepositoryMock{}, newPaymentRepositoryMock(), newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret))
+
+		err := bookingService.MarkNoShows(now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.BOOKING_STATUS_CONFIRMED, checkedIn.Status)
+		bookingRepositoryMock.AssertNotCalled(t, "UpdateBooking", booking.ID)
+	})
+
+	t.Run("When a booking fails, should record the failure on it and mark the rest of the batch", func(t *testing.T) {
+		failing := newMissed()
+		booking := newMissed()
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("FindMissedCheckIns", now.Add(-15*time.Minute)).Return([]entity.Booking{*failing, *booking}, nil)
+		bookingRepositoryMock.On("LockBookingByID", failing.ID).Return(failing, nil)
+		bookingRepositoryMock.On("UpdateBooking", failing.ID).Return(errors.New("connection reset"))
+		bookingRepositoryMock.On("RecordBookingFailure", failing.ID).Return(nil)
+		bookingRepositoryMock.On("LockBookingByID", booking.ID).Return(booking, nil)
+		bookingRepositoryMock.On("UpdateBooking", booking.ID).Return(nil)
+		bookingService := service.NewBookingService(&authRepositoryMock{}, &resourceRepositoryMock{}, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), &providerRepositoryMock{}, &promoCodeRepositoryMock{}, newPaymentRepositoryMock(), newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret))
+
+		err := bookingService.MarkNoShows(now)
+
+		assert.ErrorContains(t, err, "connection reset")
+		bookingRepositoryMock.AssertCalled(t, "RecordBookingFailure", failing.ID)
+		assert.Equal(t, entity.BOOKING_STATUS_NO_SHOW, booking.Status)
+	})
+}
+
+func TestCreateAppointment(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+	resource := &entity.Resource{ID: uuid.New(), HourlyRate: 50000, Capacity: 2}
+	haircut := &entity.Service{ID: uuid.New(), ResourceID: resource.ID, Name: "Haircut", DurationMinutes: 60}
+	providers := []entity.Provider{{ID: uuid.New(), Name: "Somchai"}, {ID: uuid.New(), Name: "Malee"}}
+	providerIDs := []uuid.UUID{providers[0].ID, providers[1].ID}
+	// the second provider is quicker than the service takes
+	providerServices := []entity.ProviderService{
+		{ProviderID: providers[0].ID, ServiceID: haircut.ID},
+		{ProviderID: providers[1].ID, ServiceID: haircut.ID, DurationMinutes: 45},
+	}
+	startAt := time.Now().Add(72 * time.Hour).Truncate(24 * time.Hour).Add(15 * time.Hour)
+	body := dto.CreateBookingDTO{ServiceID: haircut.ID.String(), StartAt: startAt}
+	appointmentOf := func(provider entity.Provider, startAt time.Time, duration time.Duration) entity.Booking {
+		return entity.Booking{ID: uuid.New(), ProviderID: &provider.ID, StartAt: startAt, EndAt: startAt.Add(duration), Seats: 1}
+	}
+	newBookingService := func(appointments []entity.Booking, timeOff []entity.ProviderTimeOff) (service.IBookingService, *bookingRepositoryMock) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindCancellationPolicyByResourceID", resource.ID).Return(&entity.CancellationPolicy{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("FindPricingPolicyByResourceID", resource.ID).Return(&entity.PricingPolicy{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("FindPaymentScheduleByResourceID", resource.ID).Return(&entity.PaymentSchedule{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("IsResourceMember", resource.ID, user.ID).Return(false, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("LockResourceByID", resource.ID).Return(resource, nil)
+		bookingRepositoryMock.On("FindOverlappingBookings", resource.ID, mock.MatchedBy(startAt.Equal)).Return([]entity.Booking{}, nil)
+		bookingRepositoryMock.On("FindProviderBookings", providerIDs).Return(appointments, nil)
+		bookingRepositoryMock.On("CreateBooking", resource.ID).Return(nil)
+		providerRepositoryMock := &providerRepositoryMock{}
+		providerRepositoryMock.On("FindServiceByID", haircut.ID).Return(haircut, nil)
+		providerRepositoryMock.On("FindProviderServicesByServiceID", haircut.ID).Return(providerServices, nil)
+		providerRepositoryMock.On("LockProvidersByIDs", mock.Anything).Return(providers, nil)
+		providerRepositoryMock.On("FindProviderHours", mock.Anything).Return([]entity.ProviderHours{}, nil)
+		providerRepositoryMock.On("FindProviderTimeOff", providers[0].ID).Return(timeOff, nil)
+		providerRepositoryMock.On("FindProviderTimeOff", providers[1].ID).Return([]entity.ProviderTimeOff{}, nil)
+		return service.NewBookingService(authRepositoryMock, resourceRepositoryMock, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), providerRepositoryMock, &promoCodeRepositoryMock{}, newPaymentRepositoryMock(), newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret)), bookingRepositoryMock
+	}
+
+	t.Run("When any provider is free, should assign the provider with the fewest minutes booked that day", func(t *testing.T) {
+		appointments := []entity.Booking{appointmentOf(providers[1], startAt.Add(-4*time.Hour), 2*time.Hour)}
+		bookingService, _ := newBookingService(appointments, []entity.ProviderTimeOff{})
+
+		res, statusCode := bookingService.CreateBooking(Username, &body)
+
+		isPass := assert.Equal(t, fiber.StatusCreated, statusCode)
+		if isPass {
+			booking := res.Data.(vo.BookingResponse)
+			assert.Equal(t, providers[0].ID, *booking.ProviderID)
+			assert.Equal(t, haircut.ID, *booking.ServiceID)
+			assert.Equal(t, startAt.Add(time.Hour), booking.EndAt)
+		}
+	})
+
+	t.Run("When the provider with fewer minutes is busy, should assign another provider for as long as they take", func(t *testing.T) {
+		appointments := []entity.Booking{appointmentOf(providers[0], startAt.Add(30*time.Minute), time.Hour)}
+		bookingService, _ := newBookingService(appointments, []entity.ProviderTimeOff{})
+
+		res, statusCode := bookingService.CreateBooking(Username, &body)
+
+		isPass := assert.Equal(t, fiber.StatusCreated, statusCode)
+		if isPass {
+			booking := res.Data.(vo.BookingResponse)
+			assert.Equal(t, providers[1].ID, *booking.ProviderID)
+			assert.Equal(t, startAt.Add(45*time.Minute), booking.EndAt)
+		}
+	})
+
+	t.Run("When no provider is free, should response status code 409 with error message", func(t *testing.T) {
+		appointments := []entity.Booking{appointmentOf(providers[1], startAt.Add(-15*time.Minute), time.Hour)}
+		timeOff := []entity.ProviderTimeOff{{ProviderID: providers[0].ID, StartAt: startAt.Add(-time.Hour), EndAt: startAt.Add(8 * time.Hour)}}
+		bookingService, bookingRepositoryMock := newBookingService(appointments, timeOff)
+
+		res, statusCode := bookingService.CreateBooking(Username, &body)
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_PROVIDER_UNAVAILABLE, res.ErrorMessage)
+		bookingRepositoryMock.AssertNotCalled(t, "CreateBooking", resource.ID)
+	})
+
+	t.Run("When the provider asked for does not perform the service, should response status code 404 with error message", func(t *testing.T) {
+		bookingService, _ := newBookingService([]entity.Booking{}, []entity.ProviderTimeOff{})
+
+		res, statusCode := bookingService.CreateBooking(Username, &dto.CreateBookingDTO{ServiceID: haircut.ID.String(), ProviderID: uuid.New().String(), StartAt: startAt})
+
+		assert.Equal(t, fiber.StatusNotFound, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_PROVIDER_NOT_FOUND, res.ErrorMessage)
+	})
+}
+
+func TestRescheduleBooking(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+	resource := &entity.Resource{ID: uuid.New(), OwnerID: uuid.New(), HourlyRate: 50000, Capacity: 1}
+	startAt := time.Now().Add(72 * time.Hour).Truncate(time.Hour)
+	movedAt := startAt.Add(24 * time.Hour)
+	// the booking was paid in full at a price other than the hourly rate
+	newBooking := func(amount int64) *entity.Booking {
+		return &entity.Booking{
+			ID:              uuid.New(),
+			UserID:          user.ID,
+			ResourceID:      resource.ID,
+			StartAt:         startAt,
+			EndAt:           startAt.Add(time.Hour),
+			Status:          entity.BOOKING_STATUS_CONFIRMED,
+			Seats:           1,
+			Amount:          amount,
+			PaidAmount:      amount,
+			PaymentSchedule: entity.PaymentDues{{DueAt: time.Now().Add(-time.Hour), Amount: amount}},
+		}
+	}
+	newBookingService := func(booking *entity.Booking, rules entity.RescheduleRules, overlapping []entity.Booking, paymentRepositoryMock *paymentRepositoryMock, ledgerRepositoryMock *ledgerRepositoryMock, notificationRepositoryMock *notificationRepositoryMock, paymentProvider payment.IPaymentProvider) (service.IBookingService, *bookingRepositoryMock) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindReschedulePolicyByResourceID", resource.ID).Return(&entity.ReschedulePolicy{ResourceID: resource.ID, Rules: rules}, nil)
+		resourceRepositoryMock.On("FindPricingPolicyByResourceID", resource.ID).Return(&entity.PricingPolicy{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("IsResourceMember", resource.ID, user.ID).Return(false, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("FindBookingByID", booking.ID).Return(booking, nil)
+		bookingRepositoryMock.On("LockResourceByID", resource.ID).Return(resource, nil)
+		bookingRepositoryMock.On("LockBookingByID", booking.ID).Return(booking, nil)
+		bookingRepositoryMock.On("FindOverlappingBookings", resource.ID, mock.MatchedBy(movedAt.Equal)).Return(overlapping, nil)
+		bookingRepositoryMock.On("UpdateBooking", booking.ID).Return(nil)
+		bookingRepositoryMock.On("CreateBookingChange", booking.ID, entity.BOOKING_CHANGE_RESCHEDULED).Return(nil)
+		return service.NewBookingService(authRepositoryMock, resourceRepositoryMock, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), &providerRepositoryMock{}, &promoCodeRepositoryMock{}, paymentRepositoryMock, ledgerRepositoryMock, notificationRepositoryMock, paymentProvider), bookingRepositoryMock
+	}
+
+	t.Run("When preview the reschedule, should response the price difference without moving the booking", func(t *testing.T) {
+		booking := newBooking(40000)
+		bookingService, bookingRepositoryMock := newBookingService(booking, entity.RescheduleRules{}, []entity.Booking{}, newPaymentRepositoryMock(), newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret))
+
+		res, statusCode := bookingService.RescheduleBooking(Username, booking.ID, &dto.RescheduleBookingDTO{StartAt: movedAt})
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			reschedule := res.Data.(vo.RescheduleResponse)
+			assert.False(t, reschedule.Applied)
+			assert.Equal(t, int64(50000), reschedule.Amount)
+			assert.Equal(t, int64(10000), reschedule.AmountDue)
+			assert.Equal(t, movedAt, reschedule.Booking.StartAt)
+		}
+		bookingRepositoryMock.AssertNotCalled(t, "UpdateBooking", booking.ID)
+		bookingRepositoryMock.AssertNotCalled(t, "CreateBookingChange", booking.ID, entity.BOOKING_CHANGE_RESCHEDULED)
+	})
+
+	t.Run("When confirm a reschedule to a higher price, should move the booking and ask for the difference", func(t *testing.T) {
+		booking := newBooking(40000)
+		paymentRepositoryMock := newPaymentRepositoryMock()
+		paymentRepositoryMock.On("FindPaymentsByBookingID", booking.ID).Return([]entity.Payment{}, nil)
+		ledgerRepositoryMock := newLedgerRepositoryMock()
+		notificationRepositoryMock := newNotificationRepositoryMock()
+		bookingService, bookingRepositoryMock := newBookingService(booking, entity.RescheduleRules{}, []entity.Booking{}, paymentRepositoryMock, ledgerRepositoryMock, notificationRepositoryMock, payment.NewFakeProvider(WebhookSecret))
+
+		res, statusCode := bookingService.RescheduleBooking(Username, booking.ID, &dto.RescheduleBookingDTO{StartAt: movedAt, Confirm: true})
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			reschedule := res.Data.(vo.RescheduleResponse)
+			assert.True(t, reschedule.Applied)
+			assert.Equal(t, movedAt.Add(time.Hour), reschedule.Booking.EndAt)
+			assert.Equal(t, 1, reschedule.Booking.RescheduleCount)
+			if assert.NotNil(t, reschedule.Payment) {
+				assert.Equal(t, int64(10000), reschedule.Payment.Amount)
+			}
+			assert.Equal(t, int64(10000), ledgerRepositoryMock.balance(resource.OrganizationID, entity.LEDGER_ACCOUNT_RECEIVABLE))
+			assert.Equal(t, int64(-10000), ledgerRepositoryMock.balance(resource.OrganizationID, entity.LEDGER_ACCOUNT_REVENUE))
+			if assert.Len(t, notificationRepositoryMock.notifications, 1) {
+				assert.Equal(t, notification.EVENT_BOOKING_RESCHEDULED, notificationRepositoryMock.notifications[0].Event)
+			}
+		}
+		bookingRepositoryMock.AssertCalled(t, "CreateBookingChange", booking.ID, entity.BOOKING_CHANGE_RESCHEDULED)
+	})
+
+	t.Run("When confirm a reschedule to a lower price, should refund what was paid beyond it", func(t *testing.T) {
+		booking := newBooking(80000)
+		paymentProvider := payment.NewFakeProvider(WebhookSecret)
+		intent, _ := paymentProvider.CreateIntent(payment.IntentRequest{Reference: booking.ID.String(), Amount: booking.Amount, Currency: payment.CURRENCY_THB})
+		paymentProvider.Authorize(intent.ID)
+		paymentProvider.Capture(intent.ID)
+		captured := entity.Payment{ID: uuid.New(), BookingID: booking.ID, Provider: paymentProvider.Name(), IntentID: intent.ID, Amount: booking.Amount, Status: entity.PAYMENT_STATUS_CAPTURED}
+		paymentRepositoryMock := newPaymentRepositoryMock()
+		paymentRepositoryMock.On("FindPaymentsByBookingID", booking.ID).Return([]entity.Payment{captured}, nil)
+		paymentRepositoryMock.On("UpdatePayment", captured.ID, entity.PAYMENT_STATUS_CAPTURED).Return(nil)
+		ledgerRepositoryMock := newLedgerRepositoryMock()
+		bookingService, _ := newBookingService(booking, entity.RescheduleRules{}, []entity.Booking{}, paymentRepositoryMock, ledgerRepositoryMock, newNotificationRepositoryMock(), paymentProvider)
+
+		res, statusCode := bookingService.RescheduleBooking(Username, booking.ID, &dto.RescheduleBookingDTO{StartAt: movedAt, Confirm: true})
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			reschedule := res.Data.(vo.RescheduleResponse)
+			assert.Equal(t, int64(30000), reschedule.RefundAmount)
+			assert.Equal(t, int64(0), reschedule.AmountDue)
+			assert.Nil(t, reschedule.Payment)
+			assert.Equal(t, int64(50000), reschedule.Booking.PaidAmount)
+			assert.ErrorIs(t, paymentProvider.Refund(intent.ID, 50001), payment.ErrRefundTooLarge)
+			assert.Equal(t, int64(0), ledgerRepositoryMock.balance(resource.OrganizationID, entity.LEDGER_ACCOUNT_REFUNDS_PAYABLE))
+			assert.Equal(t, int64(30000), ledgerRepositoryMock.balance(resource.OrganizationID, entity.LEDGER_ACCOUNT_REVENUE))
+		}
+	})
+
+	t.Run("When the cutoff before the booking has passed, should response status code 409 with error message", func(t *testing.T) {
+		booking := newBooking(50000)
+		bookingService, _ := newBookingService(booking, entity.RescheduleRules{CutoffHours: 96}, []entity.Booking{}, newPaymentRepositoryMock(), newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret))
+
+		res, statusCode := bookingService.RescheduleBooking(Username, booking.ID, &dto.RescheduleBookingDTO{StartAt: movedAt, Confirm: true})
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_RESCHEDULE_CUTOFF_PASSED, res.ErrorMessage)
+	})
+
+	t.Run("When the booking was moved as many times as allowed, should response status code 409 with error message", func(t *testing.T) {
+		booking := newBooking(50000)
+		booking.RescheduleCount = 2
+		bookingService, _ := newBookingService(booking, entity.RescheduleRules{MaxReschedules: 2}, []entity.Booking{}, newPaymentRepositoryMock(), newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret))
+
+		res, statusCode := bookingService.RescheduleBooking(Username, booking.ID, &dto.RescheduleBookingDTO{StartAt: movedAt, Confirm: true})
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_RESCHEDULE_LIMIT_REACHED, res.ErrorMessage)
+	})
+
+	t.Run("When the new slot is taken, should response status code 409 and keep the booking", func(t *testing.T) {
+		booking := newBooking(50000)
+		taken := []entity.Booking{{ID: uuid.New(), StartAt: movedAt, EndAt: movedAt.Add(time.Hour), Seats: 1}}
+		bookingService, bookingRepositoryMock := newBookingService(booking, entity.RescheduleRules{}, taken, newPaymentRepositoryMock(), newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret))
+
+		res, statusCode := bookingService.RescheduleBooking(Username, booking.ID, &dto.RescheduleBookingDTO{StartAt: movedAt, Confirm: true})
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_BOOKING_SLOT_UNAVAILABLE, res.ErrorMessage)
+		bookingRepositoryMock.AssertNotCalled(t, "UpdateBooking", booking.ID)
+	})
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"booking/internal/constant"
+	"booking/internal/entity"
+	"booking/internal/ical"
+	"booking/internal/logs"
+	"booking/internal/repository"
+	"booking/internal/vo"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+const (
+	// FEED_HISTORY_DAYS is how long bookings stay in the feed after they end.
+	FEED_HISTORY_DAYS     = 90
+	FEED_REFRESH_INTERVAL = time.Hour
+)
+
+type ICalendarService interface {
+	WithContext(ctx context.Context) ICalendarService
+	GetCalendarFeed(username string) (res vo.Response, statusCode int)
+	CreateCalendarFeed(username string) (res vo.Response, statusCode int)
+	GetFeedCalendar(token string) (res vo.Response, statusCode int)
+	GetBookingCalendar(username string, bookingID uuid.UUID) (res vo.Response, statusCode int)
+}
+
+type calendarService struct {
+	authRepository     repository.IAuthRepository
+	resourceRepository repository.IResourceRepository
+	bookingRepository  repository.IBookingRepository
+	calendarRepository repository.ICalendarRepository
+	ctx                context.Context
+}
+
+func NewCalendarService(authRepository repository.IAuthRepository, resourceRepository repository.IResourceRepository, bookingRepository repository.IBookingRepository, calendarRepository repository.ICalendarRepository) *calendarService {
+	return &calendarService{authRepository: authRepository, resourceRepository: resourceRepository, bookingRepository: bookingRepository, calendarRepository: calendarRepository}
+}
+
+func (calendarService *calendarService) WithContext(ctx context.Context) ICalendarService {
+	service := *calendarService
+	service.ctx = ctx
+	service.resourceRepository = calendarService.resourceRepository.WithContext(ctx)
+	service.bookingRepository = calendarService.bookingRepository.WithContext(ctx)
+	return &service
+}
+
+func (calendarService *calendarService) GetCalendarFeed(username string) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(calendarService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	feed, err := calendarService.calendarRepository.FindCalendarFeedByUserID(user.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_CALENDAR_FEED_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(vo.NewCalendarFeedResponse(feed, feedURL(feed)))
+	return res, fiber.StatusOK
+}
+
+// CreateCalendarFeed gives the user a new secret feed URL. The URL handed out
+// before, if any, stops working.
+func (calendarService *calendarService) CreateCalendarFeed(username string) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(calendarService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	feed, err := calendarService.calendarRepository.FindCalendarFeedByUserID(user.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		feed = &entity.CalendarFeed{UserID: user.ID}
+	} else if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	feed.Token, err = newFeedToken()
+	if err == nil {
+		err = calendarService.calendarRepository.SaveCalendarFeed(feed)
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(vo.NewCalendarFeedResponse(feed, feedURL(feed)))
+	return res, fiber.StatusCreated
+}
+
+// GetFeedCalendar writes the bookings of the owner of the feed token as a
+// calendar. Cancelled and failed bookings stay in it as cancelled events so
+// subscribers remove them rather than keep a stale copy.
+func (calendarService *calendarService) GetFeedCalendar(token string) (res vo.Response, statusCode int) {
+	feed, err := calendarService.calendarRepository.FindCalendarFeedByToken(token)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_CALENDAR_FEED_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	bookings, err := calendarService.bookingRepository.FindBookingsByUserID(feed.UserID, time.Now().AddDate(0, 0, -FEED_HISTORY_DAYS))
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	calendar := &ical.Calendar{Name: "Bookings", RefreshInterval: FEED_REFRESH_INTERVAL, Events: []ical.Event{}}
+	resources := map[uuid.UUID]*entity.Resource{}
+	for i := range bookings {
+		booking := &bookings[i]
+		resource, ok := resources[booking.ResourceID]
+		if !ok {
+			resource, err = calendarService.resourceRepository.FindResourceByID(booking.ResourceID)
+			// bookings of deleted resources are left out
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				resource = nil
+			} else if err != nil {
+				logs.Error(err)
+				res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+				return res, fiber.StatusInternalServerError
+			}
+			resources[booking.ResourceID] = resource
+		}
+		if resource != nil {
+			calendar.Events = append(calendar.Events, bookingEvent(resource, booking))
+		}
+	}
+
+	res.SetData(vo.FileResponse{FileName: "bookings.ics", Content: ical.Marshal(calendar)})
+	return res, fiber.StatusOK
+}
+
+// GetBookingCalendar writes a single booking for its customer or the owner of
+// its resource to add to their calendar.
+func (calendarService *calendarService) GetBookingCalendar(username string, bookingID uuid.UUID) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(calendarService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	booking, err := calendarService.bookingRepository.FindBookingByID(bookingID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_BOOKING_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	resource, err := calendarService.resourceRepository.FindResourceByID(booking.ResourceID)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	if booking.UserID != user.ID && !isOrganizationAdmin(calendarService.ctx) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_BOOKING_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+
+	calendar := &ical.Calendar{Method: ical.METHOD_PUBLISH, Events: []ical.Event{bookingEvent(resource, booking)}}
+	res.SetData(vo.FileResponse{FileName: "booking-" + booking.ID.String() + ".ics", Content: ical.Marshal(calendar)})
+	return res, fiber.StatusOK
+}
+
+// bookingEvent is the event of the booking. Its UID is the same in every
+// calendar it is written to so an update replaces the copy subscribers have.
+func bookingEvent(resource *entity.Resource, booking *entity.Booking) ical.Event {
+	status := ical.STATUS_CANCELLED
+	switch booking.Status {
+	case entity.BOOKING_STATUS_PENDING:
+		status = ical.STATUS_TENTATIVE
+	case entity.BOOKING_STATUS_CONFIRMED:
+		status = ical.STATUS_CONFIRMED
+	}
+	return ical.Event{
+		UID:         booking.ID.String() + "@booking",
+		Summary:     resource.Name,
+		Description: resource.Description,
+		Status:      status,
+		Sequence:    booking.Sequence,
+		Start:       booking.StartAt,
+		End:         booking.EndAt,
+		TimeZone:    resource.Location(),
+		CreatedAt:   booking.CreatedAt,
+		UpdatedAt:   booking.UpdatedAt,
+	}
+}
+
+func feedURL(feed *entity.CalendarFeed) string {
+	return strings.TrimSuffix(viper.GetString("app.public_url"), "/") + "/api/v1/calendar/feeds/" + feed.Token + ".ics"
+}
+
+func newFeedToken() (string, error) {
+	token := make([]byte, 32)
+	_, err := rand.Read(token)
+	return hex.EncodeToString(token), err
+}
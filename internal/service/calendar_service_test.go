@@ -0,0 +1,122 @@
+package service_test
+
+import (
+	"booking/internal/constant"
+	"booking/internal/entity"
+	"booking/internal/service"
+	"booking/internal/vo"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestGetFeedCalendar(t *testing.T) {
+	const Token = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	feed := &entity.CalendarFeed{ID: uuid.New(), UserID: uuid.New(), Token: Token}
+	resource := &entity.Resource{ID: uuid.New(), OwnerID: uuid.New(), Name: "Meeting Room", TimeZone: "Asia/Bangkok"}
+	startAt := time.Now().Add(72 * time.Hour).Truncate(time.Hour)
+
+	t.Run("When the token is of a feed, should write the bookings of its user with cancelled ones as cancelled events", func(t *testing.T) {
+		confirmed := entity.Booking{ID: uuid.New(), UserID: feed.UserID, ResourceID: resource.ID, StartAt: startAt, EndAt: startAt.Add(time.Hour), Status: entity.BOOKING_STATUS_CONFIRMED, Sequence: 1}
+		cancelled := entity.Booking{ID: uuid.New(), UserID: feed.UserID, ResourceID: resource.ID, StartAt: startAt.Add(24 * time.Hour), EndAt: startAt.Add(25 * time.Hour), Status: entity.BOOKING_STATUS_CANCELLED, Sequence: 2}
+		calendarRepositoryMock := &calendarRepositoryMock{}
+		calendarRepositoryMock.On("FindCalendarFeedByToken", Token).Return(feed, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("FindBookingsByUserID", feed.UserID).Return([]entity.Booking{confirmed, cancelled}, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindResourceByID", resource.ID).Return(resource, nil)
+		calendarService := service.NewCalendarService(&authRepositoryMock{}, resourceRepositoryMock, bookingRepositoryMock, calendarRepositoryMock)
+
+		res, statusCode := calendarService.GetFeedCalendar(Token)
+
+		assert.Equal(t, fiber.StatusOK, statusCode)
+		content := string(res.Data.(vo.FileResponse).Content)
+		assert.Contains(t, content, "UID:"+confirmed.ID.String()+"@booking\r\n")
+		assert.Contains(t, content, "UID:"+cancelled.ID.String()+"@booking\r\n")
+		assert.Contains(t, content, "STATUS:CANCELLED\r\n")
+		assert.Contains(t, content, "SEQUENCE:2\r\n")
+		assert.Contains(t, content, "TZID:Asia/Bangkok\r\n")
+		assert.Equal(t, 1, strings.Count(content, "BEGIN:VTIMEZONE"))
+		resourceRepositoryMock.AssertNumberOfCalls(t, "FindResourceByID", 1)
+	})
+
+	t.Run("When the token is of no feed, should respond not found", func(t *testing.T) {
+		calendarRepositoryMock := &calendarRepositoryMock{}
+		calendarRepositoryMock.On("FindCalendarFeedByToken", Token).Return(&entity.CalendarFeed{}, gorm.ErrRecordNotFound)
+		calendarService := service.NewCalendarService(&authRepositoryMock{}, &resourceRepositoryMock{}, &bookingRepositoryMock{}, calendarRepositoryMock)
+
+		res, statusCode := calendarService.GetFeedCalendar(Token)
+
+		assert.Equal(t, fiber.StatusNotFound, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_CALENDAR_FEED_NOT_FOUND, res.ErrorMessage)
+	})
+}
+
+func TestCreateCalendarFeed(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+
+	t.Run("When the user has a feed, should replace its token", func(t *testing.T) {
+		feed := &entity.CalendarFeed{ID: uuid.New(), UserID: user.ID, Token: "old"}
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		calendarRepositoryMock := &calendarRepositoryMock{}
+		calendarRepositoryMock.On("FindCalendarFeedByUserID", user.ID).Return(feed, nil)
+		calendarRepositoryMock.On("SaveCalendarFeed", user.ID).Return(nil)
+		calendarService := service.NewCalendarService(authRepositoryMock, &resourceRepositoryMock{}, &bookingRepositoryMock{}, calendarRepositoryMock)
+
+		res, statusCode := calendarService.CreateCalendarFeed(Username)
+
+		assert.Equal(t, fiber.StatusCreated, statusCode)
+		assert.Len(t, feed.Token, 64)
+		assert.True(t, strings.HasSuffix(res.Data.(vo.CalendarFeedResponse).URL, "/calendar/feeds/"+feed.Token+".ics"))
+	})
+}
+
+func TestGetBookingCalendar(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+	resource := &entity.Resource{ID: uuid.New(), OwnerID: uuid.New(), Name: "Meeting Room"}
+	startAt := time.Now().Add(72 * time.Hour).Truncate(time.Hour)
+	booking := &entity.Booking{ID: uuid.New(), UserID: uuid.New(), ResourceID: resource.ID, StartAt: startAt, EndAt: startAt.Add(time.Hour), Status: entity.BOOKING_STATUS_PENDING}
+
+	t.Run("When the booking is of another user, should respond not found", func(t *testing.T) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("FindBookingByID", booking.ID).Return(booking, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindResourceByID", resource.ID).Return(resource, nil)
+		calendarService := service.NewCalendarService(authRepositoryMock, resourceRepositoryMock, bookingRepositoryMock, &calendarRepositoryMock{})
+
+		res, statusCode := calendarService.GetBookingCalendar(Username, booking.ID)
+
+		assert.Equal(t, fiber.StatusNotFound, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_BOOKING_NOT_FOUND, res.ErrorMessage)
+	})
+
+	t.Run("When the booking is of the user, should write it as a tentative event to publish", func(t *testing.T) {
+		booking := *booking
+		booking.UserID = user.ID
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("FindBookingByID", booking.ID).Return(&booking, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindResourceByID", resource.ID).Return(resource, nil)
+		calendarService := service.NewCalendarService(authRepositoryMock, resourceRepositoryMock, bookingRepositoryMock, &calendarRepositoryMock{})
+
+		res, statusCode := calendarService.GetBookingCalendar(Username, booking.ID)
+
+		assert.Equal(t, fiber.StatusOK, statusCode)
+		file := res.Data.(vo.FileResponse)
+		assert.Equal(t, "booking-"+booking.ID.String()+".ics", file.FileName)
+		assert.Contains(t, string(file.Content), "METHOD:PUBLISH\r\n")
+		assert.Contains(t, string(file.Content), "STATUS:TENTATIVE\r\n")
+	})
+}
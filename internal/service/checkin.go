@@ -0,0 +1,70 @@
+package service
+
+import (
+	"booking/internal/entity"
+	"booking/internal/repository"
+	"booking/internal/schedule"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+// checkInToken returns the token of the QR code the customer shows on
+// arrival, the ID of the booking signed with the check-in secret so that
+// staff can trust it without looking anything else up.
+func checkInToken(bookingID uuid.UUID) string {
+	return base64.RawURLEncoding.EncodeToString(bookingID[:]) + "." + base64.RawURLEncoding.EncodeToString(signCheckIn(bookingID))
+}
+
+// parseCheckInToken returns the ID of the booking of the token, or false when
+// the token was not issued by checkInToken.
+func parseCheckInToken(token string) (bookingID uuid.UUID, ok bool) {
+	id, signature, found := strings.Cut(token, ".")
+	if !found {
+		return uuid.Nil, false
+	}
+	idBytes, err := base64.RawURLEncoding.DecodeString(id)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	bookingID, err = uuid.FromBytes(idBytes)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	signatureBytes, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(signatureBytes, signCheckIn(bookingID)) {
+		return uuid.Nil, false
+	}
+	return bookingID, true
+}
+
+func signCheckIn(bookingID uuid.UUID) []byte {
+	mac := hmac.New(sha256.New, []byte(viper.GetString("checkin.secret")))
+	mac.Write([]byte("checkin:"))
+	mac.Write(bookingID[:])
+	return mac.Sum(nil)
+}
+
+// isResourceStaff reports whether the user may check customers in to the
+// resource, the owners and admins of its organization always being able to.
+func isResourceStaff(ctx context.Context, resourceRepository repository.IResourceRepository, resource *entity.Resource, user *entity.User) (bool, error) {
+	if isOrganizationAdmin(ctx) {
+		return true, nil
+	}
+	return resourceRepository.IsResourceStaff(resource.ID, user.ID)
+}
+
+// isBookingToday reports whether the booking takes place on the day of now in
+// the time zone of its resource, bookings spanning several days being
+// checked in on any of them.
+func isBookingToday(booking *entity.Booking, location *time.Location, now time.Time) bool {
+	today := now.In(location).Format(schedule.DATE_LAYOUT)
+	lastDay := booking.EndAt.Add(-time.Nanosecond)
+	return booking.StartAt.In(location).Format(schedule.DATE_LAYOUT) <= today && today <= lastDay.In(location).Format(schedule.DATE_LAYOUT)
+}
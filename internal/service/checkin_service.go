@@ -0,0 +1,246 @@
+package service
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/logs"
+	"booking/internal/promptpay"
+	"booking/internal/repository"
+	"booking/internal/vo"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	errBookingNotConfirmed     = errors.New(constant.ERROR_MESSAGE_BOOKING_NOT_CONFIRMED)
+	errBookingNotToday         = errors.New(constant.ERROR_MESSAGE_BOOKING_NOT_TODAY)
+	errBookingAlreadyCheckedIn = errors.New(constant.ERROR_MESSAGE_BOOKING_ALREADY_CHECKED_IN)
+)
+
+type ICheckInService interface {
+	WithContext(ctx context.Context) ICheckInService
+	GetCheckInCode(username string, bookingID uuid.UUID) (res vo.Response, statusCode int)
+	GetCheckInQRCode(username string, bookingID uuid.UUID) (res vo.Response, statusCode int)
+	CheckIn(username string, checkInDTO *dto.CheckInDTO) (res vo.Response, statusCode int)
+	AddResourceStaff(username string, resourceID uuid.UUID, addResourceStaffDTO *dto.AddResourceStaffDTO) (res vo.Response, statusCode int)
+	RemoveResourceStaff(username string, resourceID uuid.UUID, userID uuid.UUID) (res vo.Response, statusCode int)
+}
+
+type checkInService struct {
+	authRepository     repository.IAuthRepository
+	resourceRepository repository.IResourceRepository
+	bookingRepository  repository.IBookingRepository
+	ctx                context.Context
+}
+
+func NewCheckInService(authRepository repository.IAuthRepository, resourceRepository repository.IResourceRepository, bookingRepository repository.IBookingRepository) *checkInService {
+	return &checkInService{
+		authRepository:     authRepository,
+		resourceRepository: resourceRepository,
+		bookingRepository:  bookingRepository,
+	}
+}
+
+func (checkInService *checkInService) WithContext(ctx context.Context) ICheckInService {
+	service := *checkInService
+	service.ctx = ctx
+	service.resourceRepository = checkInService.resourceRepository.WithContext(ctx)
+	service.bookingRepository = checkInService.bookingRepository.WithContext(ctx)
+	return &service
+}
+
+// findCheckInBooking returns the confirmed booking of the user to issue a
+// check-in code for.
+func (checkInService *checkInService) findCheckInBooking(username string, bookingID uuid.UUID) (booking *entity.Booking, errorMessage string, statusCode int) {
+	user, errorMessage, statusCode := findUser(checkInService.authRepository, username)
+	if user == nil {
+		return nil, errorMessage, statusCode
+	}
+
+	booking, err := checkInService.bookingRepository.FindBookingByID(bookingID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && booking.UserID != user.ID) {
+		return nil, constant.ERROR_MESSAGE_BOOKING_NOT_FOUND, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		return nil, constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR, fiber.StatusInternalServerError
+	}
+	if booking.Status != entity.BOOKING_STATUS_CONFIRMED {
+		return nil, constant.ERROR_MESSAGE_BOOKING_NOT_CONFIRMED, fiber.StatusConflict
+	}
+	return booking, "", fiber.StatusOK
+}
+
+// GetCheckInCode returns the token the customer shows on arrival, for apps
+// rendering the QR code themselves.
+func (checkInService *checkInService) GetCheckInCode(username string, bookingID uuid.UUID) (res vo.Response, statusCode int) {
+	booking, errorMessage, statusCode := checkInService.findCheckInBooking(username, bookingID)
+	if booking == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	res.SetData(vo.CheckInCodeResponse{BookingID: booking.ID, Token: checkInToken(booking.ID)})
+	return res, fiber.StatusOK
+}
+
+// GetCheckInQRCode renders the check-in token of the booking as a PNG image
+// in the data of the response.
+func (checkInService *checkInService) GetCheckInQRCode(username string, bookingID uuid.UUID) (res vo.Response, statusCode int) {
+	booking, errorMessage, statusCode := checkInService.findCheckInBooking(username, bookingID)
+	if booking == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	png, err := promptpay.QRCode(checkInToken(booking.ID), QR_CODE_SIZE)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	res.SetData(png)
+	return res, fiber.StatusOK
+}
+
+// CheckIn records the arrival of the customer whose check-in code was
+// scanned by staff of the resource. A booking can be checked in once, on the
+// day it takes place in the time zone of the resource.
+func (checkInService *checkInService) CheckIn(username string, checkInDTO *dto.CheckInDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(checkInService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	bookingID, ok := parseCheckInToken(checkInDTO.Token)
+	if !ok {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INVALID_CHECK_IN_TOKEN)
+		return res, fiber.StatusBadRequest
+	}
+
+	booking, err := checkInService.bookingRepository.FindBookingByID(bookingID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_BOOKING_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	resource, err := checkInService.resourceRepository.FindResourceByID(booking.ResourceID)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	staff, err := isResourceStaff(checkInService.ctx, checkInService.resourceRepository, resource, user)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	if !staff {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_FORBIDDEN)
+		return res, fiber.StatusForbidden
+	}
+
+	err = checkInService.bookingRepository.Transaction(func(tx *gorm.DB) error {
+		bookingRepository := checkInService.bookingRepository.WithTx(tx)
+		booking, err = bookingRepository.LockBookingByID(bookingID)
+		if err != nil {
+			return err
+		}
+		if booking.Status != entity.BOOKING_STATUS_CONFIRMED {
+			return errBookingNotConfirmed
+		}
+		if booking.CheckedInAt != nil {
+			return errBookingAlreadyCheckedIn
+		}
+		now := time.Now()
+		if !isBookingToday(booking, resource.Location(), now) {
+			return errBookingNotToday
+		}
+
+		booking.CheckedInAt = &now
+		booking.CheckedInBy = &user.ID
+		return bookingRepository.UpdateBooking(booking)
+	})
+	if err != nil {
+		return res, setBookingError(&res, err)
+	}
+
+	res.SetData(vo.NewCheckInResponse(booking, resource))
+	return res, fiber.StatusOK
+}
+
+// AddResourceStaff lets the user check customers in to the resource.
+func (checkInService *checkInService) AddResourceStaff(username string, resourceID uuid.UUID, addResourceStaffDTO *dto.AddResourceStaffDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(checkInService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	resource, errorMessage, statusCode := findManagedResource(checkInService.ctx, checkInService.resourceRepository, resourceID)
+	if resource == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	staffUser, err := checkInService.authRepository.FindUserByUsername(addResourceStaffDTO.Username)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_USER_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	err = checkInService.resourceRepository.AddResourceStaff(&entity.ResourceStaff{ResourceID: resourceID, UserID: staffUser.ID})
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(nil)
+	return res, fiber.StatusOK
+}
+
+func (checkInService *checkInService) RemoveResourceStaff(username string, resourceID uuid.UUID, userID uuid.UUID) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(checkInService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	resource, errorMessage, statusCode := findManagedResource(checkInService.ctx, checkInService.resourceRepository, resourceID)
+	if resource == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	deleted, err := checkInService.resourceRepository.RemoveResourceStaff(resourceID, userID)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	if deleted == 0 {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_RESOURCE_STAFF_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+
+	res.SetData(nil)
+	return res, fiber.StatusOK
+}
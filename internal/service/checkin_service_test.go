@@ -0,0 +1,212 @@
+package service_test
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/service"
+	"booking/internal/vo"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCheckIn(t *testing.T) {
+	const Username = "example@gmail.com"
+	const StaffUsername = "staff@gmail.com"
+	viper.Set("checkin.secret", "checkinsecret")
+	customer := &entity.User{ID: uuid.New(), Username: Username}
+	staff := &entity.User{ID: uuid.New(), Username: StaffUsername}
+	resource := &entity.Resource{ID: uuid.New(), Name: "Meeting Room", TimeZone: "Asia/Bangkok", Capacity: 1}
+	now := time.Now()
+	newBooking := func(startAt time.Time) *entity.Booking {
+		return &entity.Booking{
+			ID:         uuid.New(),
+			UserID:     customer.ID,
+			ResourceID: resource.ID,
+			StartAt:    startAt,
+			EndAt:      startAt.Add(2 * time.Minute),
+			Status:     entity.BOOKING_STATUS_CONFIRMED,
+			Seats:      1,
+			Amount:     100000,
+			PaidAmount: 30000,
+		}
+	}
+	newCheckInService := func(booking *entity.Booking, isStaff bool) (service.ICheckInService, *bookingRepositoryMock) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(customer, nil)
+		authRepositoryMock.On("FindUserByUsername", StaffUsername).Return(staff, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindResourceByID", resource.ID).Return(resource, nil)
+		resourceRepositoryMock.On("IsResourceStaff", resource.ID, staff.ID).Return(isStaff, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("FindBookingByID", booking.ID).Return(booking, nil)
+		bookingRepositoryMock.On("LockBookingByID", booking.ID).Return(booking, nil)
+		bookingRepositoryMock.On("UpdateBooking", booking.ID).Return(nil)
+		return service.NewCheckInService(authRepositoryMock, resourceRepositoryMock, bookingRepositoryMock), bookingRepositoryMock
+	}
+	checkInCode := func(checkInService service.ICheckInService, booking *entity.Booking) string {
+		res, statusCode := checkInService.GetCheckInCode(Username, booking.ID)
+		assert.Equal(t, fiber.StatusOK, statusCode)
+		return res.Data.(vo.CheckInCodeResponse).Token
+	}
+
+	t.Run("When staff scan the code of a booking for today, should check the customer in", func(t *testing.T) {
+		booking := newBooking(now.Add(-time.Minute))
+		checkInService, _ := newCheckInService(booking, true)
+
+		res, statusCode := checkInService.CheckIn(StaffUsername, &dto.CheckInDTO{Token: checkInCode(checkInService, booking)})
+
+		assert.Equal(t, fiber.StatusOK, statusCode)
+		response := res.Data.(vo.CheckInResponse)
+		assert.Equal(t, booking.ID, response.BookingID)
+		assert.Equal(t, "Meeting Room", response.ResourceName)
+		assert.Equal(t, int64(70000), response.OutstandingAmount)
+		assert.Equal(t, staff.ID, response.CheckedInBy)
+		assert.NotNil(t, booking.CheckedInAt)
+	})
+
+	t.Run("When an admin of the organization scans the code, should check the customer in", func(t *testing.T) {
+		admin := &entity.User{ID: uuid.New(), Username: "admin@gmail.com"}
+		booking := newBooking(now.Add(-time.Minute))
+		checkInService, _ := newCheckInService(booking, false)
+		token := checkInCode(checkInService, booking)
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", admin.Username).Return(admin, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindResourceByID", resource.ID).Return(resource, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("FindBookingByID", booking.ID).Return(booking, nil)
+		bookingRepositoryMock.On("LockBookingByID", booking.ID).Return(booking, nil)
+		bookingRepositoryMock.On("UpdateBooking", booking.ID).Return(nil)
+		checkInService = service.NewCheckInService(authRepositoryMock, resourceRepositoryMock, bookingRepositoryMock).WithContext(AdminContext)
+
+		_, statusCode := checkInService.CheckIn(admin.Username, &dto.CheckInDTO{Token: token})
+
+		assert.Equal(t, fiber.StatusOK, statusCode)
+		resourceRepositoryMock.AssertNotCalled(t, "IsResourceStaff", mock.Anything, mock.Anything)
+	})
+
+	t.Run("When the token has been tampered with, should respond invalid check-in token", func(t *testing.T) {
+		booking := newBooking(now.Add(-time.Minute))
+		other := newBooking(now.Add(-time.Minute))
+		checkInService, bookingRepositoryMock := newCheckInService(booking, true)
+		bookingRepositoryMock.On("FindBookingByID", other.ID).Return(other, nil)
+		// the ID of the other booking with the signature of this one
+		forged := checkInCode(checkInService, other)[:22] + checkInCode(checkInService, booking)[22:]
+
+		for _, token := range []string{"not-a-token", forged} {
+			res, statusCode := checkInService.CheckIn(StaffUsername, &dto.CheckInDTO{Token: token})
+
+			assert.Equal(t, fiber.StatusBadRequest, statusCode)
+			assert.Equal(t, constant.ERROR_MESSAGE_INVALID_CHECK_IN_TOKEN, res.ErrorMessage)
+		}
+		assert.Nil(t, booking.CheckedInAt)
+	})
+
+	t.Run("When the user isn't staff of the resource, should respond forbidden", func(t *testing.T) {
+		booking := newBooking(now.Add(-time.Minute))
+		checkInService, bookingRepositoryMock := newCheckInService(booking, false)
+
+		res, statusCode := checkInService.CheckIn(StaffUsername, &dto.CheckInDTO{Token: checkInCode(checkInService, booking)})
+
+		assert.Equal(t, fiber.StatusForbidden, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_FORBIDDEN, res.ErrorMessage)
+		bookingRepositoryMock.AssertNotCalled(t, "UpdateBooking", booking.ID)
+	})
+
+	t.Run("When the booking isn't for today, should respond booking not today", func(t *testing.T) {
+		booking := newBooking(now.Add(48 * time.Hour))
+		checkInService, _ := newCheckInService(booking, true)
+
+		res, statusCode := checkInService.CheckIn(StaffUsername, &dto.CheckInDTO{Token: checkInCode(checkInService, booking)})
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_BOOKING_NOT_TODAY, res.ErrorMessage)
+	})
+
+	t.Run("When the booking was already checked in, should respond already checked in", func(t *testing.T) {
+		booking := newBooking(now.Add(-time.Minute))
+		checkInService, bookingRepositoryMock := newCheckInService(booking, true)
+		token := checkInCode(checkInService, booking)
+		_, statusCode := checkInService.CheckIn(StaffUsername, &dto.CheckInDTO{Token: token})
+		assert.Equal(t, fiber.StatusOK, statusCode)
+
+		res, statusCode := checkInService.CheckIn(StaffUsername, &dto.CheckInDTO{Token: token})
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_BOOKING_ALREADY_CHECKED_IN, res.ErrorMessage)
+		bookingRepositoryMock.AssertNumberOfCalls(t, "UpdateBooking", 1)
+	})
+
+	t.Run("When the booking was cancelled after the code was issued, should respond booking not confirmed", func(t *testing.T) {
+		booking := newBooking(now.Add(-time.Minute))
+		checkInService, _ := newCheckInService(booking, true)
+		token := checkInCode(checkInService, booking)
+		booking.Status = entity.BOOKING_STATUS_CANCELLED
+
+		res, statusCode := checkInService.CheckIn(StaffUsername, &dto.CheckInDTO{Token: token})
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_BOOKING_NOT_CONFIRMED, res.ErrorMessage)
+	})
+}
+
+func TestGetCheckInCode(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+
+	t.Run("When the booking belongs to another user, should respond booking not found", func(t *testing.T) {
+		booking := &entity.Booking{ID: uuid.New(), UserID: uuid.New(), Status: entity.BOOKING_STATUS_CONFIRMED}
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("FindBookingByID", booking.ID).Return(booking, nil)
+		checkInService := service.NewCheckInService(authRepositoryMock, &resourceRepositoryMock{}, bookingRepositoryMock)
+
+		res, statusCode := checkInService.GetCheckInCode(Username, booking.ID)
+
+		assert.Equal(t, fiber.StatusNotFound, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_BOOKING_NOT_FOUND, res.ErrorMessage)
+	})
+
+	t.Run("When the booking is pending payment, should respond booking not confirmed", func(t *testing.T) {
+		booking := &entity.Booking{ID: uuid.New(), UserID: user.ID, Status: entity.BOOKING_STATUS_PENDING}
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("FindBookingByID", booking.ID).Return(booking, nil)
+		checkInService := service.NewCheckInService(authRepositoryMock, &resourceRepositoryMock{}, bookingRepositoryMock)
+
+		res, statusCode := checkInService.GetCheckInQRCode(Username, booking.ID)
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_BOOKING_NOT_CONFIRMED, res.ErrorMessage)
+	})
+}
+
+func TestRemoveResourceStaff(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+	resource := &entity.Resource{ID: uuid.New()}
+
+	t.Run("When the user isn't staff of the resource, should respond resource staff not found", func(t *testing.T) {
+		staffID := uuid.New()
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindResourceByID", resource.ID).Return(resource, nil)
+		resourceRepositoryMock.On("RemoveResourceStaff", resource.ID, staffID).Return(int64(0), nil)
+		checkInService := service.NewCheckInService(authRepositoryMock, resourceRepositoryMock, &bookingRepositoryMock{}).WithContext(AdminContext)
+
+		res, statusCode := checkInService.RemoveResourceStaff(Username, resource.ID, staffID)
+
+		assert.Equal(t, fiber.StatusNotFound, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_RESOURCE_STAFF_NOT_FOUND, res.ErrorMessage)
+	})
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/ical"
+	"booking/internal/logs"
+	"booking/internal/repository"
+	"booking/internal/vo"
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// SYNC_BATCH_SIZE is how many external calendars are synced at a time in the
+// background.
+const SYNC_BATCH_SIZE = 100
+
+type IExternalCalendarService interface {
+	WithContext(ctx context.Context) IExternalCalendarService
+	GetExternalCalendars(username string, resourceID uuid.UUID) (res vo.Response, statusCode int)
+	CreateExternalCalendar(username string, resourceID uuid.UUID, createExternalCalendarDTO *dto.CreateExternalCalendarDTO) (res vo.Response, statusCode int)
+	UploadExternalCalendar(username string, resourceID uuid.UUID, name string, content []byte) (res vo.Response, statusCode int)
+	SyncExternalCalendar(username string, resourceID uuid.UUID, calendarID uuid.UUID) (res vo.Response, statusCode int)
+	DeleteExternalCalendar(username string, resourceID uuid.UUID, calendarID uuid.UUID) (res vo.Response, statusCode int)
+	SyncExternalCalendars(now time.Time) (err error)
+}
+
+type externalCalendarService struct {
+	authRepository             repository.IAuthRepository
+	resourceRepository         repository.IResourceRepository
+	externalCalendarRepository repository.IExternalCalendarRepository
+	httpClient                 *http.Client
+	ctx                        context.Context
+}
+
+func NewExternalCalendarService(authRepository repository.IAuthRepository, resourceRepository repository.IResourceRepository, externalCalendarRepository repository.IExternalCalendarRepository, httpClient *http.Client) *externalCalendarService {
+	return &externalCalendarService{authRepository: authRepository, resourceRepository: resourceRepository, externalCalendarRepository: externalCalendarRepository, httpClient: httpClient}
+}
+
+func (externalCalendarService *externalCalendarService) WithContext(ctx context.Context) IExternalCalendarService {
+	service := *externalCalendarService
+	service.ctx = ctx
+	service.resourceRepository = externalCalendarService.resourceRepository.WithContext(ctx)
+	return &service
+}
+
+func (externalCalendarService *externalCalendarService) GetExternalCalendars(username string, resourceID uuid.UUID) (res vo.Response, statusCode int) {
+	resource, errorMessage, statusCode := externalCalendarService.findManagedResource(username, resourceID)
+	if resource == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	calendars, err := externalCalendarService.externalCalendarRepository.FindExternalCalendars(resource.ID)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	responses := []vo.ExternalCalendarResponse{}
+	for i := range calendars {
+		responses = append(responses, newExternalCalendarResponse(&calendars[i]))
+	}
+	res.SetData(responses)
+	return res, fiber.StatusOK
+}
+
+// CreateExternalCalendar subscribes the resource to the calendar at the URL.
+// It is only added if it can be fetched and read, and is then synced in the
+// background.
+func (externalCalendarService *externalCalendarService) CreateExternalCalendar(username string, resourceID uuid.UUID, createExternalCalendarDTO *dto.CreateExternalCalendarDTO) (res vo.Response, statusCode int) {
+	resource, errorMessage, statusCode := externalCalendarService.findManagedResource(username, resourceID)
+	if resource == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	content, err := ical.Fetch(externalCalendarService.httpClient, createExternalCalendarDTO.URL)
+	if err != nil {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_EXTERNAL_CALENDAR_UNREACHABLE)
+		return res, fiber.StatusBadRequest
+	}
+	calendar := &entity.ExternalCalendar{ResourceID: resource.ID, Name: createExternalCalendarDTO.Name, URL: createExternalCalendarDTO.URL}
+	return externalCalendarService.createExternalCalendar(resource, calendar, content)
+}
+
+// UploadExternalCalendar imports the busy events of the ICS file as a
+// calendar of the resource.
+func (externalCalendarService *externalCalendarService) UploadExternalCalendar(username string, resourceID uuid.UUID, name string, content []byte) (res vo.Response, statusCode int) {
+	resource, errorMessage, statusCode := externalCalendarService.findManagedResource(username, resourceID)
+	if resource == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	calendar := &entity.ExternalCalendar{ResourceID: resource.ID, Name: name}
+	return externalCalendarService.createExternalCalendar(resource, calendar, content)
+}
+
+func (externalCalendarService *externalCalendarService) createExternalCalendar(resource *entity.Resource, calendar *entity.ExternalCalendar, content []byte) (res vo.Response, statusCode int) {
+	now := time.Now()
+	busy, err := readBusy(resource, content, now)
+	if err != nil {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INVALID_CALENDAR)
+		return res, fiber.StatusBadRequest
+	}
+
+	calendar.SyncedAt = &now
+	err = externalCalendarService.externalCalendarRepository.CreateExternalCalendar(calendar)
+	if err == nil {
+		err = externalCalendarService.externalCalendarRepository.ReplaceImportedBlackouts(calendar, newImportedBlackouts(calendar, busy))
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(newExternalCalendarResponse(calendar))
+	return res, fiber.StatusCreated
+}
+
+// SyncExternalCalendar syncs the calendar from its URL now rather than
+// waiting for the background sync.
+func (externalCalendarService *externalCalendarService) SyncExternalCalendar(username string, resourceID uuid.UUID, calendarID uuid.UUID) (res vo.Response, statusCode int) {
+	resource, errorMessage, statusCode := externalCalendarService.findManagedResource(username, resourceID)
+	if resource == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	calendar, err := externalCalendarService.externalCalendarRepository.FindExternalCalendarByID(resource.ID, calendarID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_EXTERNAL_CALENDAR_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	if calendar.URL == "" {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_EXTERNAL_CALENDAR_HAS_NO_URL)
+		return res, fiber.StatusConflict
+	}
+
+	err = externalCalendarService.syncCalendar(resource, calendar, time.Now())
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(newExternalCalendarResponse(calendar))
+	return res, fiber.StatusOK
+}
+
+// DeleteExternalCalendar removes the calendar and frees the periods imported
+// from it.
+func (externalCalendarService *externalCalendarService) DeleteExternalCalendar(username string, resourceID uuid.UUID, calendarID uuid.UUID) (res vo.Response, statusCode int) {
+	resource, errorMessage, statusCode := externalCalendarService.findManagedResource(username, resourceID)
+	if resource == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	deleted, err := externalCalendarService.externalCalendarRepository.DeleteExternalCalendar(resource.ID, calendarID)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	if deleted == 0 {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_EXTERNAL_CALENDAR_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+
+	res.SetData(nil)
+	return res, fiber.StatusOK
+}
+
+// SyncExternalCalendars syncs the calendars with a URL not synced within the
+// configured interval.
+func (externalCalendarService *externalCalendarService) SyncExternalCalendars(now time.Time) (err error) {
+	interval := viper.GetDuration("calendar.sync_minutes") * time.Minute
+	calendars, err := externalCalendarService.externalCalendarRepository.FindExternalCalendarsToSync(now.Add(-interval), SYNC_BATCH_SIZE)
+	if err != nil {
+		return err
+	}
+	for i := range calendars {
+		calendar := &calendars[i]
+		resource, err := externalCalendarService.resourceRepository.FindResourceByID(calendar.ResourceID)
+		// the calendars of deleted resources are left alone
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		err = externalCalendarService.syncCalendar(resource, calendar, now)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncCalendar imports the calendar again from its URL. A calendar that can't
+// be fetched or read keeps the periods imported before and records the error
+// message of why, to be tried again at the next sync. The error itself is
+// only logged, as it may tell about the network the calendar was fetched
+// from.
+func (externalCalendarService *externalCalendarService) syncCalendar(resource *entity.Resource, calendar *entity.ExternalCalendar, now time.Time) error {
+	calendar.SyncedAt = &now
+	content, err := ical.Fetch(externalCalendarService.httpClient, calendar.URL)
+	if err != nil {
+		logs.Error(err, zap.String("externalCalendar", calendar.ID.String()))
+		calendar.SyncError = constant.ERROR_MESSAGE_EXTERNAL_CALENDAR_UNREACHABLE
+		return externalCalendarService.externalCalendarRepository.UpdateExternalCalendar(calendar)
+	}
+	busy, err := readBusy(resource, content, now)
+	if err != nil {
+		logs.Error(err, zap.String("externalCalendar", calendar.ID.String()))
+		calendar.SyncError = constant.ERROR_MESSAGE_INVALID_CALENDAR
+		return externalCalendarService.externalCalendarRepository.UpdateExternalCalendar(calendar)
+	}
+
+	calendar.SyncError = ""
+	return externalCalendarService.externalCalendarRepository.ReplaceImportedBlackouts(calendar, newImportedBlackouts(calendar, busy))
+}
+
+func (externalCalendarService *externalCalendarService) findManagedResource(username string, resourceID uuid.UUID) (resource *entity.Resource, errorMessage string, statusCode int) {
+	user, errorMessage, statusCode := findUser(externalCalendarService.authRepository, username)
+	if user == nil {
+		return nil, errorMessage, statusCode
+	}
+	return findManagedResource(externalCalendarService.ctx, externalCalendarService.resourceRepository, resourceID)
+}
+
+// readBusy reads the busy periods of the calendar from now up to the horizon
+// bookings are made to, in the time zone of the resource where the calendar
+// doesn't name one.
+func readBusy(resource *entity.Resource, content []byte, now time.Time) ([]ical.Busy, error) {
+	horizon, _ := recurrenceHorizon(now)
+	return ical.ParseBusy(content, resource.Location(), now, horizon)
+}
+
+// newImportedBlackouts blocks the resource for the busy periods, under the
+// name of the calendar so the titles of its events aren't published.
+func newImportedBlackouts(calendar *entity.ExternalCalendar, busy []ical.Busy) []entity.BlackoutPeriod {
+	blackouts := []entity.BlackoutPeriod{}
+	for _, period := range busy {
+		blackouts = append(blackouts, entity.BlackoutPeriod{
+			ResourceID:         calendar.ResourceID,
+			StartAt:            period.Start,
+			EndAt:              period.End,
+			Reason:             calendar.Name,
+			ExternalCalendarID: &calendar.ID,
+		})
+	}
+	return blackouts
+}
+
+func newExternalCalendarResponse(calendar *entity.ExternalCalendar) vo.ExternalCalendarResponse {
+	return vo.ExternalCalendarResponse{
+		ID:        calendar.ID,
+		Name:      calendar.Name,
+		URL:       calendar.URL,
+		SyncedAt:  calendar.SyncedAt,
+		SyncError: calendar.SyncError,
+		CreatedAt: calendar.CreatedAt,
+	}
+}
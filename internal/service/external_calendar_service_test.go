@@ -0,0 +1,132 @@
+package service_test
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/service"
+	"booking/internal/vo"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newCalendarServer serves the ICS fixtures of the ical package.
+func newCalendarServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.FileServer(http.Dir("../ical/testdata")))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCreateExternalCalendar(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+	resource := &entity.Resource{ID: uuid.New(), Name: "Meeting Room", TimeZone: "Asia/Bangkok"}
+	server := newCalendarServer(t)
+	newExternalCalendarService := func(externalCalendarRepositoryMock *externalCalendarRepositoryMock) service.IExternalCalendarService {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindResourceByID", resource.ID).Return(resource, nil)
+		return service.NewExternalCalendarService(authRepositoryMock, resourceRepositoryMock, externalCalendarRepositoryMock, server.Client()).WithContext(AdminContext)
+	}
+
+	t.Run("When the URL serves a calendar, should block the resource for its upcoming busy events", func(t *testing.T) {
+		externalCalendarRepositoryMock := &externalCalendarRepositoryMock{}
+		externalCalendarRepositoryMock.On("CreateExternalCalendar", resource.ID).Return(nil)
+		externalCalendarRepositoryMock.On("ReplaceImportedBlackouts", mock.Anything).Return(nil)
+		externalCalendarService := newExternalCalendarService(externalCalendarRepositoryMock)
+		now := time.Now()
+
+		res, statusCode := externalCalendarService.CreateExternalCalendar(Username, resource.ID, &dto.CreateExternalCalendarDTO{Name: "Facilities", URL: server.URL + "/weekly.ics"})
+
+		assert.Equal(t, fiber.StatusCreated, statusCode)
+		calendar := res.Data.(vo.ExternalCalendarResponse)
+		assert.Empty(t, calendar.SyncError)
+		// the weekly standup recurs every Monday of the coming year
+		assert.GreaterOrEqual(t, len(externalCalendarRepositoryMock.blackouts), 51)
+		for _, blackout := range externalCalendarRepositoryMock.blackouts {
+			assert.Equal(t, resource.ID, blackout.ResourceID)
+			assert.Equal(t, calendar.ID, *blackout.ExternalCalendarID)
+			assert.Equal(t, "Facilities", blackout.Reason)
+			assert.True(t, blackout.EndAt.After(now))
+			assert.Equal(t, time.Monday, blackout.StartAt.In(newYork(t)).Weekday())
+		}
+	})
+
+	t.Run("When the URL can't be fetched, should respond bad request without adding the calendar", func(t *testing.T) {
+		externalCalendarRepositoryMock := &externalCalendarRepositoryMock{}
+		externalCalendarService := newExternalCalendarService(externalCalendarRepositoryMock)
+
+		res, statusCode := externalCalendarService.CreateExternalCalendar(Username, resource.ID, &dto.CreateExternalCalendarDTO{Name: "Facilities", URL: server.URL + "/missing.ics"})
+
+		assert.Equal(t, fiber.StatusBadRequest, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_EXTERNAL_CALENDAR_UNREACHABLE, res.ErrorMessage)
+		externalCalendarRepositoryMock.AssertNotCalled(t, "CreateExternalCalendar", mock.Anything)
+	})
+
+	t.Run("When the uploaded file is not a calendar, should respond bad request", func(t *testing.T) {
+		externalCalendarRepositoryMock := &externalCalendarRepositoryMock{}
+		externalCalendarService := newExternalCalendarService(externalCalendarRepositoryMock)
+		content, _ := os.ReadFile("../ical/testdata/invalid.ics")
+
+		res, statusCode := externalCalendarService.UploadExternalCalendar(Username, resource.ID, "Facilities", content)
+
+		assert.Equal(t, fiber.StatusBadRequest, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_INVALID_CALENDAR, res.ErrorMessage)
+		externalCalendarRepositoryMock.AssertNotCalled(t, "CreateExternalCalendar", mock.Anything)
+	})
+}
+
+func TestSyncExternalCalendars(t *testing.T) {
+	resource := &entity.Resource{ID: uuid.New(), Name: "Meeting Room", TimeZone: "Asia/Bangkok"}
+	server := newCalendarServer(t)
+	viper.Set("calendar.sync_minutes", 60)
+
+	t.Run("When a calendar is due, should replace the periods imported from it", func(t *testing.T) {
+		calendar := entity.ExternalCalendar{ID: uuid.New(), ResourceID: resource.ID, Name: "Facilities", URL: server.URL + "/weekly.ics"}
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindResourceByID", resource.ID).Return(resource, nil)
+		externalCalendarRepositoryMock := &externalCalendarRepositoryMock{}
+		externalCalendarRepositoryMock.On("FindExternalCalendarsToSync", service.SYNC_BATCH_SIZE).Return([]entity.ExternalCalendar{calendar}, nil)
+		externalCalendarRepositoryMock.On("ReplaceImportedBlackouts", calendar.ID).Return(nil)
+		externalCalendarService := service.NewExternalCalendarService(&authRepositoryMock{}, resourceRepositoryMock, externalCalendarRepositoryMock, server.Client())
+
+		err := externalCalendarService.SyncExternalCalendars(time.Now())
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, externalCalendarRepositoryMock.blackouts)
+	})
+
+	t.Run("When a calendar can't be fetched, should keep its periods and record the error message of why", func(t *testing.T) {
+		calendar := entity.ExternalCalendar{ID: uuid.New(), ResourceID: resource.ID, Name: "Facilities", URL: server.URL + "/missing.ics"}
+		calendars := []entity.ExternalCalendar{calendar}
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindResourceByID", resource.ID).Return(resource, nil)
+		externalCalendarRepositoryMock := &externalCalendarRepositoryMock{}
+		externalCalendarRepositoryMock.On("FindExternalCalendarsToSync", service.SYNC_BATCH_SIZE).Return(calendars, nil)
+		externalCalendarRepositoryMock.On("UpdateExternalCalendar", calendar.ID).Return(nil)
+		externalCalendarService := service.NewExternalCalendarService(&authRepositoryMock{}, resourceRepositoryMock, externalCalendarRepositoryMock, server.Client())
+
+		err := externalCalendarService.SyncExternalCalendars(time.Now())
+
+		assert.NoError(t, err)
+		externalCalendarRepositoryMock.AssertCalled(t, "UpdateExternalCalendar", calendar.ID)
+		externalCalendarRepositoryMock.AssertNotCalled(t, "ReplaceImportedBlackouts", mock.Anything)
+		assert.Equal(t, constant.ERROR_MESSAGE_EXTERNAL_CALENDAR_UNREACHABLE, calendars[0].SyncError)
+	})
+}
+
+func newYork(t *testing.T) *time.Location {
+	location, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+	return location
+}
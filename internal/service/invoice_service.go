@@ -0,0 +1,282 @@
+package service
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/invoice"
+	"booking/internal/logs"
+	"booking/internal/repository"
+	"booking/internal/tenant"
+	"booking/internal/vo"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type IInvoiceService interface {
+	WithContext(ctx context.Context) IInvoiceService
+	GetBillingProfile(username string) (res vo.Response, statusCode int)
+	SaveBillingProfile(username string, billingProfileDTO *dto.BillingProfileDTO) (res vo.Response, statusCode int)
+	GetOrganizationBillingProfile() (res vo.Response, statusCode int)
+	SaveOrganizationBillingProfile(billingProfileDTO *dto.BillingProfileDTO) (res vo.Response, statusCode int)
+	GetBookingInvoice(username string, bookingID uuid.UUID, language string, invoiceQueryDTO *dto.InvoiceQueryDTO) (res vo.Response, statusCode int)
+}
+
+var (
+	errBookingNotPaid         = errors.New(constant.ERROR_MESSAGE_BOOKING_NOT_PAID)
+	errBookingNotPayable      = errors.New(constant.ERROR_MESSAGE_BOOKING_NOT_PAYABLE)
+	errBillingProfileRequired = errors.New(constant.ERROR_MESSAGE_BILLING_PROFILE_REQUIRED)
+)
+
+type invoiceService struct {
+	authRepository        repository.IAuthRepository
+	resourceRepository    repository.IResourceRepository
+	bookingRepository     repository.IBookingRepository
+	taxDocumentRepository repository.ITaxDocumentRepository
+	renderer              invoice.IRenderer
+	ctx                   context.Context
+}
+
+func NewInvoiceService(authRepository repository.IAuthRepository, resourceRepository repository.IResourceRepository, bookingRepository repository.IBookingRepository, taxDocumentRepository repository.ITaxDocumentRepository, renderer invoice.IRenderer) *invoiceService {
+	return &invoiceService{authRepository: authRepository, resourceRepository: resourceRepository, bookingRepository: bookingRepository, taxDocumentRepository: taxDocumentRepository, renderer: renderer}
+}
+
+func (invoiceService *invoiceService) WithContext(ctx context.Context) IInvoiceService {
+	service := *invoiceService
+	service.ctx = ctx
+	service.resourceRepository = invoiceService.resourceRepository.WithContext(ctx)
+	service.bookingRepository = invoiceService.bookingRepository.WithContext(ctx)
+	return &service
+}
+
+func (invoiceService *invoiceService) GetBillingProfile(username string) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(invoiceService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	profile, err := invoiceService.taxDocumentRepository.FindBillingProfileByUserID(user.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_BILLING_PROFILE_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(vo.NewBillingProfileResponse(profile))
+	return res, fiber.StatusOK
+}
+
+// SaveBillingProfile sets how the user is named on the tax documents they are
+// issued as a customer. Documents already issued keep the details they were
+// issued with.
+func (invoiceService *invoiceService) SaveBillingProfile(username string, billingProfileDTO *dto.BillingProfileDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(invoiceService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	profile, err := invoiceService.taxDocumentRepository.FindBillingProfileByUserID(user.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		profile = &entity.BillingProfile{UserID: user.ID}
+	} else if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	profile.Name = strings.TrimSpace(billingProfileDTO.Name)
+	profile.TaxID = billingProfileDTO.TaxID
+	profile.BranchCode = billingProfileDTO.BranchCode
+	profile.Address = strings.TrimSpace(billingProfileDTO.Address)
+	err = invoiceService.taxDocumentRepository.SaveBillingProfile(profile)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(vo.NewBillingProfileResponse(profile))
+	return res, fiber.StatusOK
+}
+
+func (invoiceService *invoiceService) GetOrganizationBillingProfile() (res vo.Response, statusCode int) {
+	organizationID, _ := tenant.OrganizationID(invoiceService.ctx)
+	profile, err := invoiceService.taxDocumentRepository.FindOrganizationBillingProfile(organizationID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_BILLING_PROFILE_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(vo.NewOrganizationBillingProfileResponse(profile))
+	return res, fiber.StatusOK
+}
+
+// SaveOrganizationBillingProfile sets how the organization of the request is
+// named on the tax documents it issues for the bookings of its resources.
+func (invoiceService *invoiceService) SaveOrganizationBillingProfile(billingProfileDTO *dto.BillingProfileDTO) (res vo.Response, statusCode int) {
+	organizationID, _ := tenant.OrganizationID(invoiceService.ctx)
+	profile, err := invoiceService.taxDocumentRepository.FindOrganizationBillingProfile(organizationID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		profile = &entity.OrganizationBillingProfile{OrganizationID: organizationID}
+	} else if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	profile.Name = strings.TrimSpace(billingProfileDTO.Name)
+	profile.TaxID = billingProfileDTO.TaxID
+	profile.BranchCode = billingProfileDTO.BranchCode
+	profile.Address = strings.TrimSpace(billingProfileDTO.Address)
+	err = invoiceService.taxDocumentRepository.SaveOrganizationBillingProfile(profile)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(vo.NewOrganizationBillingProfileResponse(profile))
+	return res, fiber.StatusOK
+}
+
+// GetBookingInvoice renders the invoice or receipt of the booking for its
+// customer or an admin of its organization. The document is issued with the
+// next number of the organization the first time it is asked for and rendered
+// from what it was issued with afterwards.
+func (invoiceService *invoiceService) GetBookingInvoice(username string, bookingID uuid.UUID, language string, invoiceQueryDTO *dto.InvoiceQueryDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(invoiceService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	booking, err := invoiceService.bookingRepository.FindBookingByID(bookingID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_BOOKING_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	resource, err := invoiceService.resourceRepository.FindResourceByID(booking.ResourceID)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	if booking.UserID != user.ID && !isOrganizationAdmin(invoiceService.ctx) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_BOOKING_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+
+	documentType := entity.TAX_DOCUMENT_INVOICE
+	if invoiceQueryDTO.Type == "receipt" {
+		documentType = entity.TAX_DOCUMENT_RECEIPT
+	}
+	format := invoiceQueryDTO.Format
+	if format == "" {
+		format = invoice.FORMAT_PDF
+	}
+
+	var document *entity.TaxDocument
+	err = invoiceService.bookingRepository.Transaction(func(tx *gorm.DB) error {
+		taxDocumentRepository := invoiceService.taxDocumentRepository.WithTx(tx)
+		// the booking is locked so it is issued a single document of a type
+		// however many are asked for at once
+		booking, err := invoiceService.bookingRepository.WithTx(tx).LockBookingByID(booking.ID)
+		if err != nil {
+			return err
+		}
+		document, err = taxDocumentRepository.FindTaxDocument(booking.ID, documentType)
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		document, err = issueTaxDocument(taxDocumentRepository, resource, booking, documentType, time.Now())
+		return err
+	})
+	if err != nil {
+		return res, setBookingError(&res, err)
+	}
+
+	content, err := invoiceService.renderer.Render(document, language, format)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	res.SetData(vo.FileResponse{FileName: document.Number + "." + format, Content: content})
+	return res, fiber.StatusOK
+}
+
+// issueTaxDocument numbers a document of the booking and records it with the
+// billing profiles of the organization and the customer. Invoices are issued for
+// the amount of bookings still active, receipts once they are paid in full.
+func issueTaxDocument(taxDocumentRepository repository.ITaxDocumentRepository, resource *entity.Resource, booking *entity.Booking, documentType string, now time.Time) (*entity.TaxDocument, error) {
+	if booking.Amount == 0 {
+		return nil, errBookingNotPayable
+	}
+	switch {
+	case documentType == entity.TAX_DOCUMENT_RECEIPT && booking.PaidAmount < booking.Amount:
+		return nil, errBookingNotPaid
+	case documentType == entity.TAX_DOCUMENT_INVOICE && !booking.IsActive():
+		return nil, errBookingAlreadyCancelled
+	}
+
+	seller, err := taxDocumentRepository.FindOrganizationBillingProfile(resource.OrganizationID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errBillingProfileRequired
+	}
+	if err != nil {
+		return nil, err
+	}
+	// customers without a billing profile are issued an abbreviated document
+	buyer, err := taxDocumentRepository.FindBillingProfileByUserID(booking.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		buyer = &entity.BillingProfile{}
+	} else if err != nil {
+		return nil, err
+	}
+
+	location, err := time.LoadLocation(invoice.TIME_ZONE)
+	if err != nil {
+		return nil, err
+	}
+	year := now.In(location).Year()
+	sequence, err := taxDocumentRepository.NextDocumentNumber(resource.OrganizationID, documentType, year)
+	if err != nil {
+		return nil, err
+	}
+
+	document := &entity.TaxDocument{
+		OrganizationID: resource.OrganizationID,
+		BookingID:      booking.ID,
+		Type:           documentType,
+		Number:         entity.DocumentNumber(documentType, year, sequence),
+		Seller:         seller.DocumentParty,
+		Buyer:          entity.NewDocumentParty(buyer),
+		Description:    fmt.Sprintf("%s %s - %s", resource.Name, booking.StartAt.In(location).Format("2006-01-02 15:04"), booking.EndAt.In(location).Format("2006-01-02 15:04")),
+		Lines:          entity.DocumentLines(booking.Quote.Lines),
+		IssuedAt:       now,
+	}
+	document.SetTotal(booking.Amount)
+	err = taxDocumentRepository.CreateTaxDocument(document)
+	return document, err
+}
@@ -0,0 +1,130 @@
+package service_test
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/invoice"
+	"booking/internal/service"
+	"booking/internal/vo"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestGetBookingInvoice(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+	resource := &entity.Resource{ID: uuid.New(), OwnerID: uuid.New(), OrganizationID: uuid.New(), Name: "Meeting Room"}
+	seller := &entity.OrganizationBillingProfile{OrganizationID: resource.OrganizationID, DocumentParty: entity.DocumentParty{Name: "Example Co., Ltd.", TaxID: "0105561234567", BranchCode: "00000", Address: "Bangkok"}}
+	buyer := &entity.BillingProfile{UserID: user.ID, Name: "Customer Co., Ltd.", TaxID: "0105569876543", Address: "Chiang Mai"}
+	startAt := time.Now().Add(72 * time.Hour).Truncate(time.Hour)
+	newBooking := func() *entity.Booking {
+		return &entity.Booking{
+			ID:         uuid.New(),
+			UserID:     user.ID,
+			ResourceID: resource.ID,
+			StartAt:    startAt,
+			EndAt:      startAt.Add(time.Hour),
+			Status:     entity.BOOKING_STATUS_CONFIRMED,
+			Amount:     107000,
+			Quote:      entity.Quote{Lines: []entity.QuoteLine{{Name: "Base rate", Type: entity.QUOTE_LINE_BASE_RATE, Amount: 107000}}, Total: 107000},
+		}
+	}
+	newInvoiceService := func(booking *entity.Booking, taxDocumentRepositoryMock *taxDocumentRepositoryMock) service.IInvoiceService {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindResourceByID", resource.ID).Return(resource, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("FindBookingByID", booking.ID).Return(booking, nil)
+		bookingRepositoryMock.On("LockBookingByID", booking.ID).Return(booking, nil)
+		renderer, _ := invoice.NewRenderer("")
+		return service.NewInvoiceService(authRepositoryMock, resourceRepositoryMock, bookingRepositoryMock, taxDocumentRepositoryMock, renderer)
+	}
+
+	t.Run("When the invoice is asked for the first time, should issue it with the next number and the VAT of the amount", func(t *testing.T) {
+		booking := newBooking()
+		taxDocumentRepositoryMock := &taxDocumentRepositoryMock{}
+		taxDocumentRepositoryMock.On("FindTaxDocument", booking.ID, entity.TAX_DOCUMENT_INVOICE).Return(&entity.TaxDocument{}, gorm.ErrRecordNotFound)
+		taxDocumentRepositoryMock.On("FindOrganizationBillingProfile", resource.OrganizationID).Return(seller, nil)
+		taxDocumentRepositoryMock.On("FindBillingProfileByUserID", user.ID).Return(buyer, nil)
+		taxDocumentRepositoryMock.On("NextDocumentNumber", resource.OrganizationID, entity.TAX_DOCUMENT_INVOICE).Return(int64(42), nil)
+		taxDocumentRepositoryMock.On("CreateTaxDocument", booking.ID, entity.TAX_DOCUMENT_INVOICE).Return(nil)
+		invoiceService := newInvoiceService(booking, taxDocumentRepositoryMock)
+
+		res, statusCode := invoiceService.GetBookingInvoice(Username, booking.ID, invoice.LANGUAGE_EN, &dto.InvoiceQueryDTO{Format: invoice.FORMAT_HTML})
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			file := res.Data.(vo.FileResponse)
+			number := fmt.Sprintf("INV-%d-000042", time.Now().Year())
+			assert.Equal(t, number+".html", file.FileName)
+			assert.Contains(t, string(file.Content), "0105569876543")
+			assert.Contains(t, string(file.Content), "1,000.00")
+			assert.Contains(t, string(file.Content), "70.00")
+		}
+	})
+
+	t.Run("When the invoice was issued before, should render it without taking a number", func(t *testing.T) {
+		booking := newBooking()
+		issued := &entity.TaxDocument{BookingID: booking.ID, Type: entity.TAX_DOCUMENT_INVOICE, Number: "INV-2024-000007", Seller: seller.DocumentParty}
+		issued.SetTotal(booking.Amount)
+		taxDocumentRepositoryMock := &taxDocumentRepositoryMock{}
+		taxDocumentRepositoryMock.On("FindTaxDocument", booking.ID, entity.TAX_DOCUMENT_INVOICE).Return(issued, nil)
+		invoiceService := newInvoiceService(booking, taxDocumentRepositoryMock)
+
+		res, statusCode := invoiceService.GetBookingInvoice(Username, booking.ID, invoice.LANGUAGE_TH, &dto.InvoiceQueryDTO{})
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			file := res.Data.(vo.FileResponse)
+			assert.Equal(t, "INV-2024-000007.pdf", file.FileName)
+			assert.Equal(t, "%PDF", string(file.Content[:4]))
+			taxDocumentRepositoryMock.AssertNotCalled(t, "NextDocumentNumber", mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("When a receipt is asked for a booking not paid in full, should response status code 409 with error message", func(t *testing.T) {
+		booking := newBooking()
+		booking.PaidAmount = 30000
+		taxDocumentRepositoryMock := &taxDocumentRepositoryMock{}
+		taxDocumentRepositoryMock.On("FindTaxDocument", booking.ID, entity.TAX_DOCUMENT_RECEIPT).Return(&entity.TaxDocument{}, gorm.ErrRecordNotFound)
+		invoiceService := newInvoiceService(booking, taxDocumentRepositoryMock)
+
+		res, statusCode := invoiceService.GetBookingInvoice(Username, booking.ID, invoice.LANGUAGE_EN, &dto.InvoiceQueryDTO{Type: "receipt"})
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_BOOKING_NOT_PAID, res.ErrorMessage)
+	})
+
+	t.Run("When the organization has no billing profile, should response status code 409 with error message", func(t *testing.T) {
+		booking := newBooking()
+		taxDocumentRepositoryMock := &taxDocumentRepositoryMock{}
+		taxDocumentRepositoryMock.On("FindTaxDocument", booking.ID, entity.TAX_DOCUMENT_INVOICE).Return(&entity.TaxDocument{}, gorm.ErrRecordNotFound)
+		taxDocumentRepositoryMock.On("FindOrganizationBillingProfile", resource.OrganizationID).Return(&entity.OrganizationBillingProfile{}, gorm.ErrRecordNotFound)
+		invoiceService := newInvoiceService(booking, taxDocumentRepositoryMock)
+
+		res, statusCode := invoiceService.GetBookingInvoice(Username, booking.ID, invoice.LANGUAGE_EN, &dto.InvoiceQueryDTO{})
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_BILLING_PROFILE_REQUIRED, res.ErrorMessage)
+	})
+
+	t.Run("When the booking belongs to another user, should response status code 404 with error message", func(t *testing.T) {
+		booking := newBooking()
+		booking.UserID = uuid.New()
+		invoiceService := newInvoiceService(booking, &taxDocumentRepositoryMock{})
+
+		res, statusCode := invoiceService.GetBookingInvoice(Username, booking.ID, invoice.LANGUAGE_EN, &dto.InvoiceQueryDTO{})
+
+		assert.Equal(t, fiber.StatusNotFound, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_BOOKING_NOT_FOUND, res.ErrorMessage)
+	})
+}
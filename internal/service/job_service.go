@@ -0,0 +1,253 @@
+package service
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/logs"
+	"booking/internal/repository"
+	"booking/internal/vo"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	JOB_KIND_DISPATCH_NOTIFICATIONS  = "dispatch-notifications"
+	JOB_KIND_SEND_REMINDERS          = "send-reminders"
+	JOB_KIND_EXPIRE_HOLDS            = "expire-holds"
+	JOB_KIND_HANDLE_OVERDUE_BOOKINGS = "handle-overdue-bookings"
+	JOB_KIND_MARK_NO_SHOWS           = "mark-no-shows"
+	JOB_KIND_SYNC_EXTERNAL_CALENDARS = "sync-external-calendars"
+	JOB_KIND_DELETE_FINISHED_JOBS    = "delete-finished-jobs"
+)
+
+const (
+	// JOB_BATCH_SIZE is how many jobs a worker claims per run.
+	JOB_BATCH_SIZE = 20
+	JOB_PAGE_SIZE  = 20
+	// JOB_RETRY_BASE is how long a failed job waits to be retried after its
+	// first attempt, doubling with each attempt up to JOB_RETRY_MAX.
+	JOB_RETRY_BASE = 30 * time.Second
+	JOB_RETRY_MAX  = time.Hour
+)
+
+// JobHandler runs a job, now being when it started.
+type JobHandler func(job *entity.Job, now time.Time) error
+
+type IJobService interface {
+	GetJobs(username string, jobQueryDTO *dto.JobQueryDTO) (res vo.Response, statusCode int)
+	RetryJob(username string, jobID uuid.UUID) (res vo.Response, statusCode int)
+	Every(kind string, interval time.Duration, handler JobHandler)
+	RunJobs(now time.Time) (err error)
+	DeleteFinishedJobs(now time.Time) (err error)
+}
+
+type periodicJob struct {
+	interval    time.Duration
+	scheduledAt time.Time
+}
+
+type jobService struct {
+	authRepository repository.IAuthRepository
+	jobRepository  repository.IJobRepository
+	worker         string
+	mutex          sync.Mutex
+	handlers       map[string]JobHandler
+	periodic       map[string]*periodicJob
+}
+
+// NewJobService runs jobs as the worker, a name unique to the process.
+func NewJobService(authRepository repository.IAuthRepository, jobRepository repository.IJobRepository, worker string) *jobService {
+	return &jobService{authRepository: authRepository, jobRepository: jobRepository, worker: worker, handlers: map[string]JobHandler{}, periodic: map[string]*periodicJob{}}
+}
+
+// Every runs the handler once per interval, across all the processes running
+// jobs. Each run is a job of the kind due at the start of its interval.
+func (jobService *jobService) Every(kind string, interval time.Duration, handler JobHandler) {
+	jobService.mutex.Lock()
+	defer jobService.mutex.Unlock()
+	jobService.handlers[kind] = handler
+	jobService.periodic[kind] = &periodicJob{interval: interval}
+}
+
+// RunJobs schedules the periodic jobs due by now and runs the jobs due, a
+// failed job being retried with exponential backoff until it runs out of
+// attempts.
+func (jobService *jobService) RunJobs(now time.Time) (err error) {
+	jobService.mutex.Lock()
+	defer jobService.mutex.Unlock()
+
+	err = jobService.schedulePeriodicJobs(now)
+	if err != nil {
+		return err
+	}
+	lease := viper.GetDuration("jobs.lease_seconds") * time.Second
+	jobs, err := jobService.jobRepository.ClaimJobs(jobService.worker, now, lease, JOB_BATCH_SIZE)
+	if err != nil {
+		return err
+	}
+	for i := range jobs {
+		err = jobService.runJob(&jobs[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (jobService *jobService) schedulePeriodicJobs(now time.Time) error {
+	maxAttempts := viper.GetInt("jobs.max_attempts")
+	for kind, periodic := range jobService.periodic {
+		runAt := now.Truncate(periodic.interval)
+		if !runAt.After(periodic.scheduledAt) {
+			continue
+		}
+		err := jobService.jobRepository.EnqueueJob(&entity.Job{
+			Kind:        kind,
+			Key:         kind + "@" + runAt.UTC().Format(time.RFC3339),
+			Status:      entity.JOB_STATUS_PENDING,
+			RunAt:       runAt,
+			MaxAttempts: maxAttempts,
+		})
+		if err != nil {
+			return err
+		}
+		periodic.scheduledAt = runAt
+	}
+	return nil
+}
+
+func (jobService *jobService) runJob(job *entity.Job) error {
+	var err error
+	handler, ok := jobService.handlers[job.Kind]
+	switch {
+	case !ok:
+		err = fmt.Errorf("no handler for job kind %s", job.Kind)
+	// the job was retried by a worker that died on its last attempt
+	case job.Attempts > job.MaxAttempts:
+		err = errors.New(job.LastError)
+	default:
+		err = handler(job, time.Now())
+	}
+
+	finishedAt := time.Now()
+	job.LockedBy = ""
+	job.LockedUntil = nil
+	switch {
+	case err == nil:
+		job.Status = entity.JOB_STATUS_SUCCEEDED
+		job.LastError = ""
+		job.FinishedAt = &finishedAt
+	case !ok || job.Attempts >= job.MaxAttempts:
+		job.Status = entity.JOB_STATUS_FAILED
+		job.LastError = err.Error()
+		job.FinishedAt = &finishedAt
+		logs.Error(err, zap.String("job", job.ID.String()), zap.String("kind", job.Kind), zap.Int("attempts", job.Attempts))
+	default:
+		job.Status = entity.JOB_STATUS_PENDING
+		job.LastError = err.Error()
+		job.RunAt = finishedAt.Add(jobBackoff(job.Attempts))
+	}
+	// a job that ran past its lease may have been taken over by another
+	// worker, whose outcome is kept
+	_, err = jobService.jobRepository.UpdateJob(jobService.worker, job)
+	return err
+}
+
+// DeleteFinishedJobs deletes the jobs that succeeded longer ago than the
+// configured number of days. Failed jobs are kept to be looked into.
+func (jobService *jobService) DeleteFinishedJobs(now time.Time) (err error) {
+	retention := viper.GetDuration("jobs.retention_days") * 24 * time.Hour
+	_, err = jobService.jobRepository.DeleteFinishedJobs(now.Add(-retention))
+	return err
+}
+
+// jobBackoff returns how long to wait before retrying a job that failed the
+// given number of attempts.
+func jobBackoff(attempts int) time.Duration {
+	backoff := JOB_RETRY_BASE
+	for i := 1; i < attempts && backoff < JOB_RETRY_MAX; i++ {
+		backoff *= 2
+	}
+	return min(backoff, JOB_RETRY_MAX)
+}
+
+func (jobService *jobService) GetJobs(username string, jobQueryDTO *dto.JobQueryDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findAdmin(jobService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	page, size := jobQueryDTO.Page, jobQueryDTO.Size
+	if page == 0 {
+		page = 1
+	}
+	if size == 0 {
+		size = JOB_PAGE_SIZE
+	}
+	jobs, total, err := jobService.jobRepository.FindJobs(jobQueryDTO.Status, jobQueryDTO.Kind, int((page-1)*size), int(size))
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	responses := []vo.JobResponse{}
+	for i := range jobs {
+		responses = append(responses, vo.NewJobResponse(&jobs[i]))
+	}
+	res.SetDataWithPagination(responses, page, size, uint(total))
+	return res, fiber.StatusOK
+}
+
+// RetryJob runs the failed job again as soon as a worker is free.
+func (jobService *jobService) RetryJob(username string, jobID uuid.UUID) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findAdmin(jobService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	job, err := jobService.jobRepository.FindJobByID(jobID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_JOB_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	now := time.Now()
+	retried := false
+	if job.Status == entity.JOB_STATUS_FAILED {
+		retried, err = jobService.jobRepository.RetryJob(job.ID, now)
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	// the job may have been retried by someone else since it was read
+	if !retried {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_JOB_NOT_FAILED)
+		return res, fiber.StatusConflict
+	}
+
+	job.Status = entity.JOB_STATUS_PENDING
+	job.RunAt = now
+	job.Attempts = 0
+	job.FinishedAt = nil
+	res.SetData(vo.NewJobResponse(job))
+	return res, fiber.StatusOK
+}
@@ -0,0 +1,182 @@
+package service_test
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/service"
+	"booking/internal/vo"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRunJobs(t *testing.T) {
+	viper.Set("jobs.lease_seconds", 300)
+	viper.Set("jobs.max_attempts", 2)
+	now := time.Date(2026, 3, 2, 9, 0, 30, 0, time.UTC)
+
+	t.Run("When several workers run the periodic job in the same interval, should run it once", func(t *testing.T) {
+		jobRepositoryMock := newJobRepositoryMock()
+		runs := 0
+		handler := func(job *entity.Job, now time.Time) error {
+			runs++
+			return nil
+		}
+		first := service.NewJobService(&authRepositoryMock{}, jobRepositoryMock, "first")
+		first.Every(service.JOB_KIND_EXPIRE_HOLDS, time.Minute, handler)
+		second := service.NewJobService(&authRepositoryMock{}, jobRepositoryMock, "second")
+		second.Every(service.JOB_KIND_EXPIRE_HOLDS, time.Minute, handler)
+
+		assert.NoError(t, first.RunJobs(now))
+		assert.NoError(t, second.RunJobs(now.Add(5*time.Second)))
+
+		assert.Equal(t, 1, runs)
+		if assert.Len(t, jobRepositoryMock.jobs, 1) {
+			assert.Equal(t, entity.JOB_STATUS_SUCCEEDED, jobRepositoryMock.jobs[0].Status)
+			assert.Equal(t, "expire-holds@2026-03-02T09:00:00Z", jobRepositoryMock.jobs[0].Key)
+		}
+	})
+
+	t.Run("When the next interval starts, should run the periodic job again", func(t *testing.T) {
+		jobRepositoryMock := newJobRepositoryMock()
+		runs := 0
+		jobService := service.NewJobService(&authRepositoryMock{}, jobRepositoryMock, "worker")
+		jobService.Every(service.JOB_KIND_EXPIRE_HOLDS, time.Minute, func(job *entity.Job, now time.Time) error {
+			runs++
+			return nil
+		})
+
+		assert.NoError(t, jobService.RunJobs(now))
+		assert.NoError(t, jobService.RunJobs(now.Add(time.Minute)))
+
+		assert.Equal(t, 2, runs)
+	})
+
+	t.Run("When the job fails, should retry it after a backoff until it runs out of attempts", func(t *testing.T) {
+		jobRepositoryMock := newJobRepositoryMock()
+		jobService := service.NewJobService(&authRepositoryMock{}, jobRepositoryMock, "worker")
+		jobService.Every(service.JOB_KIND_EXPIRE_HOLDS, time.Hour, func(job *entity.Job, now time.Time) error {
+			return errors.New("database is down")
+		})
+
+		assert.NoError(t, jobService.RunJobs(now))
+		job := jobRepositoryMock.jobs[0]
+		assert.Equal(t, entity.JOB_STATUS_PENDING, job.Status)
+		assert.Equal(t, 1, job.Attempts)
+		assert.Equal(t, "database is down", job.LastError)
+		assert.True(t, job.RunAt.After(now))
+
+		assert.NoError(t, jobService.RunJobs(job.RunAt))
+		job = jobRepositoryMock.jobs[0]
+		assert.Equal(t, entity.JOB_STATUS_FAILED, job.Status)
+		assert.Equal(t, 2, job.Attempts)
+		assert.NotNil(t, job.FinishedAt)
+	})
+
+	t.Run("When the worker running the job died, should let another worker take it over once its lease expires", func(t *testing.T) {
+		lockedUntil := now.Add(-time.Second)
+		jobRepositoryMock := newJobRepositoryMock()
+		jobRepositoryMock.jobs = []entity.Job{{ID: uuid.New(), Kind: service.JOB_KIND_SYNC_EXTERNAL_CALENDARS, Key: "sync-external-calendars@2026-03-02T09:00:00Z", Status: entity.JOB_STATUS_RUNNING, RunAt: now.Add(-time.Hour), Attempts: 1, MaxAttempts: 2, LockedBy: "dead", LockedUntil: &lockedUntil}}
+		runs := 0
+		jobService := service.NewJobService(&authRepositoryMock{}, jobRepositoryMock, "worker")
+		jobService.Every(service.JOB_KIND_SYNC_EXTERNAL_CALENDARS, time.Hour, func(job *entity.Job, now time.Time) error {
+			runs++
+			return nil
+		})
+
+		assert.NoError(t, jobService.RunJobs(now))
+
+		assert.Equal(t, 1, runs)
+		assert.Equal(t, entity.JOB_STATUS_SUCCEEDED, jobRepositoryMock.jobs[0].Status)
+	})
+
+	t.Run("When the job has no handler, should fail it without retrying", func(t *testing.T) {
+		jobRepositoryMock := newJobRepositoryMock()
+		jobRepositoryMock.jobs = []entity.Job{{ID: uuid.New(), Kind: "unknown", Key: "unknown", Status: entity.JOB_STATUS_PENDING, RunAt: now, MaxAttempts: 2}}
+		jobService := service.NewJobService(&authRepositoryMock{}, jobRepositoryMock, "worker")
+
+		assert.NoError(t, jobService.RunJobs(now))
+
+		assert.Equal(t, entity.JOB_STATUS_FAILED, jobRepositoryMock.jobs[0].Status)
+		assert.Equal(t, "no handler for job kind unknown", jobRepositoryMock.jobs[0].LastError)
+	})
+}
+
+func TestRetryJob(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+	newJobService := func(jobRepositoryMock *jobRepositoryMock) service.IJobService {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		return service.NewJobService(authRepositoryMock, jobRepositoryMock, "worker")
+	}
+
+	t.Run("When the user is not an administrator, should response status code 403 with error message", func(t *testing.T) {
+		viper.Set("admin.usernames", []string{"admin@gmail.com"})
+		jobRepositoryMock := newJobRepositoryMock()
+		jobService := newJobService(jobRepositoryMock)
+
+		res, statusCode := jobService.RetryJob(Username, uuid.New())
+
+		assert.Equal(t, fiber.StatusForbidden, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_FORBIDDEN, res.ErrorMessage)
+	})
+
+	t.Run("When the job failed, should schedule it to run again", func(t *testing.T) {
+		viper.Set("admin.usernames", []string{Username})
+		job := &entity.Job{ID: uuid.New(), Kind: service.JOB_KIND_EXPIRE_HOLDS, Status: entity.JOB_STATUS_FAILED, Attempts: 5, MaxAttempts: 5, LastError: "database is down"}
+		jobRepositoryMock := newJobRepositoryMock()
+		jobRepositoryMock.On("FindJobByID", job.ID).Return(job, nil)
+		jobRepositoryMock.On("RetryJob", job.ID).Return(true, nil)
+		jobService := newJobService(jobRepositoryMock)
+
+		res, statusCode := jobService.RetryJob(Username, job.ID)
+
+		assert.Equal(t, fiber.StatusOK, statusCode)
+		response := res.Data.(vo.JobResponse)
+		assert.Equal(t, entity.JOB_STATUS_PENDING, response.Status)
+		assert.Equal(t, 0, response.Attempts)
+	})
+
+	t.Run("When the job has not failed, should response status code 409 with error message", func(t *testing.T) {
+		viper.Set("admin.usernames", []string{Username})
+		job := &entity.Job{ID: uuid.New(), Status: entity.JOB_STATUS_SUCCEEDED}
+		jobRepositoryMock := newJobRepositoryMock()
+		jobRepositoryMock.On("FindJobByID", job.ID).Return(job, nil)
+		jobService := newJobService(jobRepositoryMock)
+
+		res, statusCode := jobService.RetryJob(Username, job.ID)
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_JOB_NOT_FAILED, res.ErrorMessage)
+		jobRepositoryMock.AssertNotCalled(t, "RetryJob", job.ID)
+	})
+}
+
+func TestGetJobs(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+
+	t.Run("When an administrator lists the failed jobs, should response the page of jobs", func(t *testing.T) {
+		viper.Set("admin.usernames", []string{Username})
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		jobRepositoryMock := newJobRepositoryMock()
+		jobRepositoryMock.On("FindJobs", entity.JOB_STATUS_FAILED, "", 0, service.JOB_PAGE_SIZE).Return([]entity.Job{{ID: uuid.New(), Status: entity.JOB_STATUS_FAILED}}, int64(1), nil)
+		jobService := service.NewJobService(authRepositoryMock, jobRepositoryMock, "worker")
+
+		res, statusCode := jobService.GetJobs(Username, &dto.JobQueryDTO{Status: entity.JOB_STATUS_FAILED})
+
+		assert.Equal(t, fiber.StatusOK, statusCode)
+		assert.Len(t, res.Data, 1)
+		assert.Equal(t, uint(1), res.Pagination.Total)
+		jobRepositoryMock.AssertCalled(t, "FindJobs", entity.JOB_STATUS_FAILED, "", 0, mock.Anything)
+	})
+}
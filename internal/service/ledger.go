@@ -0,0 +1,143 @@
+package service
+
+import (
+	"booking/internal/entity"
+	"booking/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ledgerLine debits or credits the account of the code by an amount in
+// satang.
+type ledgerLine struct {
+	code   string
+	debit  int64
+	credit int64
+}
+
+// signedLine debits the account of the code by a positive amount or credits
+// it by a negative one.
+func signedLine(code string, amount int64) ledgerLine {
+	if amount < 0 {
+		return ledgerLine{code: code, credit: -amount}
+	}
+	return ledgerLine{code: code, debit: amount}
+}
+
+// postJournalEntry posts the lines to the accounts of the organization,
+// leaving out lines of nothing.
+func postJournalEntry(ledgerRepository repository.ILedgerRepository, organizationID uuid.UUID, entry *entity.JournalEntry, lines []ledgerLine) error {
+	entry.OrganizationID = organizationID
+	if entry.PostedAt.IsZero() {
+		entry.PostedAt = time.Now()
+	}
+	for _, line := range lines {
+		if line.debit == 0 && line.credit == 0 {
+			continue
+		}
+		account, err := ledgerRepository.FindOrCreateAccount(organizationID, line.code)
+		if err != nil {
+			return err
+		}
+		entry.Lines = append(entry.Lines, entity.JournalLine{AccountID: account.ID, Debit: line.debit, Credit: line.credit})
+	}
+	if len(entry.Lines) == 0 {
+		return nil
+	}
+	return ledgerRepository.CreateJournalEntry(entry)
+}
+
+// postBooking records the amount of the new booking as owed by the customer
+// and its promo discount as taken off the revenue.
+func postBooking(ledgerRepository repository.ILedgerRepository, organizationID uuid.UUID, booking *entity.Booking) error {
+	if !booking.HasPaymentSchedule() {
+		return nil
+	}
+	discount := booking.Quote.Discount()
+	return postJournalEntry(ledgerRepository, organizationID, &entity.JournalEntry{
+		BookingID:   &booking.ID,
+		Type:        entity.JOURNAL_ENTRY_BOOKING,
+		Description: "booking",
+	}, []ledgerLine{
+		{code: entity.LEDGER_ACCOUNT_RECEIVABLE, debit: booking.Amount},
+		{code: entity.LEDGER_ACCOUNT_DISCOUNTS, debit: discount},
+		{code: entity.LEDGER_ACCOUNT_REVENUE, credit: booking.Amount + discount},
+	})
+}
+
+// postPayment records the captured payment as cash held by its provider,
+// settling what the customer owes on the booking. Paying more than is owed,
+// or paying a cancelled booking, leaves money to give back to the customer.
+func postPayment(ledgerRepository repository.ILedgerRepository, organizationID uuid.UUID, booking *entity.Booking, record *entity.Payment, owed int64) error {
+	settled := min(record.Amount, owed)
+	return postJournalEntry(ledgerRepository, organizationID, &entity.JournalEntry{
+		BookingID:   &booking.ID,
+		PaymentID:   &record.ID,
+		Type:        entity.JOURNAL_ENTRY_PAYMENT,
+		Description: "payment " + record.IntentID,
+	}, []ledgerLine{
+		{code: entity.CashAccount(record.Provider), debit: record.Amount},
+		{code: entity.LEDGER_ACCOUNT_RECEIVABLE, credit: settled},
+		{code: entity.LEDGER_ACCOUNT_REFUNDS_PAYABLE, credit: record.Amount - settled},
+	})
+}
+
+// postCancellation reverses the booking entry of the cancelled or failed
+// booking. What was paid is owed back to the customer up to the refund
+// amount and the rest is kept as a cancellation fee.
+func postCancellation(ledgerRepository repository.ILedgerRepository, organizationID uuid.UUID, booking *entity.Booking, description string) error {
+	if !booking.HasPaymentSchedule() {
+		return nil
+	}
+	discount := booking.Quote.Discount()
+	paid := min(booking.PaidAmount, booking.Amount)
+	return postJournalEntry(ledgerRepository, organizationID, &entity.JournalEntry{
+		BookingID:   &booking.ID,
+		Type:        entity.JOURNAL_ENTRY_CANCELLATION,
+		Description: description,
+	}, []ledgerLine{
+		{code: entity.LEDGER_ACCOUNT_REVENUE, debit: booking.Amount + discount},
+		{code: entity.LEDGER_ACCOUNT_DISCOUNTS, credit: discount},
+		{code: entity.LEDGER_ACCOUNT_RECEIVABLE, credit: booking.Amount - paid},
+		{code: entity.LEDGER_ACCOUNT_REFUNDS_PAYABLE, credit: booking.RefundAmount},
+		{code: entity.LEDGER_ACCOUNT_CANCELLATION_FEES, credit: paid - booking.RefundAmount},
+	})
+}
+
+// postReschedule adjusts the booking entry by the change of the amount and
+// discount of the rescheduled booking from what they were before. What was
+// paid beyond the new amount is owed back to the customer.
+func postReschedule(ledgerRepository repository.ILedgerRepository, organizationID uuid.UUID, booking *entity.Booking, previous *entity.Booking) error {
+	if !previous.HasPaymentSchedule() {
+		return postBooking(ledgerRepository, organizationID, booking)
+	}
+	discount, previousDiscount := booking.Quote.Discount(), previous.Quote.Discount()
+	paid := previous.PaidAmount
+	owed, previousOwed := max(booking.Amount-paid, 0), max(previous.Amount-paid, 0)
+	overpaid, previousOverpaid := max(paid-booking.Amount, 0), max(paid-previous.Amount, 0)
+	return postJournalEntry(ledgerRepository, organizationID, &entity.JournalEntry{
+		BookingID:   &booking.ID,
+		Type:        entity.JOURNAL_ENTRY_RESCHEDULE,
+		Description: "reschedule",
+	}, []ledgerLine{
+		signedLine(entity.LEDGER_ACCOUNT_RECEIVABLE, owed-previousOwed),
+		signedLine(entity.LEDGER_ACCOUNT_DISCOUNTS, discount-previousDiscount),
+		signedLine(entity.LEDGER_ACCOUNT_REVENUE, (previous.Amount+previousDiscount)-(booking.Amount+discount)),
+		signedLine(entity.LEDGER_ACCOUNT_REFUNDS_PAYABLE, previousOverpaid-overpaid),
+	})
+}
+
+// postRefund records money given back to the customer by the provider of the
+// payment.
+func postRefund(ledgerRepository repository.ILedgerRepository, organizationID uuid.UUID, booking *entity.Booking, record *entity.Payment, amount int64) error {
+	return postJournalEntry(ledgerRepository, organizationID, &entity.JournalEntry{
+		BookingID:   &booking.ID,
+		PaymentID:   &record.ID,
+		Type:        entity.JOURNAL_ENTRY_REFUND,
+		Description: "refund " + record.IntentID,
+	}, []ledgerLine{
+		{code: entity.LEDGER_ACCOUNT_REFUNDS_PAYABLE, debit: amount},
+		{code: entity.CashAccount(record.Provider), credit: amount},
+	})
+}
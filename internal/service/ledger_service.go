@@ -0,0 +1,128 @@
+package service
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/logs"
+	"booking/internal/repository"
+	"booking/internal/tenant"
+	"booking/internal/vo"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type ILedgerService interface {
+	WithContext(ctx context.Context) ILedgerService
+	GetBalances(username string, ledgerBalanceQueryDTO *dto.LedgerBalanceQueryDTO) (res vo.Response, statusCode int)
+	GetStatement(username string, code string, ledgerStatementQueryDTO *dto.LedgerStatementQueryDTO) (res vo.Response, statusCode int)
+}
+
+// STATEMENT_PAGE_SIZE is how many lines a statement page has by default.
+const STATEMENT_PAGE_SIZE = 50
+
+type ledgerService struct {
+	authRepository   repository.IAuthRepository
+	ledgerRepository repository.ILedgerRepository
+	ctx              context.Context
+}
+
+func NewLedgerService(authRepository repository.IAuthRepository, ledgerRepository repository.ILedgerRepository) *ledgerService {
+	return &ledgerService{authRepository: authRepository, ledgerRepository: ledgerRepository}
+}
+
+func (ledgerService *ledgerService) WithContext(ctx context.Context) ILedgerService {
+	service := *ledgerService
+	service.ctx = ctx
+	return &service
+}
+
+// GetBalances returns the balance of every account of the ledger of the
+// organization of the request at the given time.
+func (ledgerService *ledgerService) GetBalances(username string, ledgerBalanceQueryDTO *dto.LedgerBalanceQueryDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(ledgerService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	at := time.Now()
+	if ledgerBalanceQueryDTO.At != "" {
+		var err error
+		at, err = time.Parse(time.RFC3339, ledgerBalanceQueryDTO.At)
+		if err != nil {
+			res.SetErrorMessage(constant.ERROR_MESSAGE_INVALID_PARAMETERS)
+			return res, fiber.StatusBadRequest
+		}
+	}
+
+	organizationID, _ := tenant.OrganizationID(ledgerService.ctx)
+	balances, err := ledgerService.ledgerRepository.FindAccountBalances(organizationID, at)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	response := vo.LedgerBalancesResponse{At: at, Accounts: []vo.AccountBalanceResponse{}}
+	for i := range balances {
+		response.Accounts = append(response.Accounts, vo.NewAccountBalanceResponse(&balances[i]))
+	}
+	res.SetData(response)
+	return res, fiber.StatusOK
+}
+
+// GetStatement lists the lines posted to an account of the ledger of the
+// organization of the request in the period, each with the balance of the account after it.
+func (ledgerService *ledgerService) GetStatement(username string, code string, ledgerStatementQueryDTO *dto.LedgerStatementQueryDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(ledgerService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	startAt, startErr := time.Parse(time.RFC3339, ledgerStatementQueryDTO.StartAt)
+	endAt, endErr := time.Parse(time.RFC3339, ledgerStatementQueryDTO.EndAt)
+	if startErr != nil || endErr != nil || !endAt.After(startAt) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INVALID_PARAMETERS)
+		return res, fiber.StatusBadRequest
+	}
+	page, size := ledgerStatementQueryDTO.Page, ledgerStatementQueryDTO.Size
+	if page == 0 {
+		page = 1
+	}
+	if size == 0 {
+		size = STATEMENT_PAGE_SIZE
+	}
+
+	organizationID, _ := tenant.OrganizationID(ledgerService.ctx)
+	account, err := ledgerService.ledgerRepository.FindAccountByCode(organizationID, code)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_LEDGER_ACCOUNT_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	opening, err := ledgerService.ledgerRepository.SumAccountLines(account.ID, startAt)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	lines, total, err := ledgerService.ledgerRepository.FindStatementLines(account.ID, startAt, endAt, int((page-1)*size), int(size))
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetDataWithPagination(vo.NewStatementResponse(account, startAt, endAt, opening, lines), page, size, uint(total))
+	return res, fiber.StatusOK
+}
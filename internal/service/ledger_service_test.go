@@ -0,0 +1,91 @@
+package service_test
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/service"
+	"booking/internal/tenant"
+	"booking/internal/vo"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestGetLedgerBalances(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+	organizationID := uuid.New()
+	ctx := tenant.WithOrganizationID(AdminContext, organizationID)
+
+	t.Run("When the ledger has entries, should response the balance of each account on its normal side", func(t *testing.T) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		ledgerRepositoryMock := &ledgerRepositoryMock{}
+		ledgerRepositoryMock.On("FindAccountBalances", organizationID).Return([]entity.AccountBalance{
+			{Code: entity.LEDGER_ACCOUNT_RECEIVABLE, Type: entity.LEDGER_ACCOUNT_TYPE_ASSET, Debit: 75000, Credit: 15000},
+			{Code: entity.LEDGER_ACCOUNT_REVENUE, Type: entity.LEDGER_ACCOUNT_TYPE_REVENUE, Credit: 80000},
+			{Code: entity.LEDGER_ACCOUNT_DISCOUNTS, Type: entity.LEDGER_ACCOUNT_TYPE_CONTRA_REVENUE, Debit: 5000},
+		}, nil)
+		ledgerService := service.NewLedgerService(authRepositoryMock, ledgerRepositoryMock).WithContext(ctx)
+
+		res, statusCode := ledgerService.GetBalances(Username, &dto.LedgerBalanceQueryDTO{})
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			accounts := res.Data.(vo.LedgerBalancesResponse).Accounts
+			assert.Equal(t, int64(60000), accounts[0].Balance)
+			assert.Equal(t, int64(80000), accounts[1].Balance)
+			assert.Equal(t, int64(5000), accounts[2].Balance)
+		}
+	})
+}
+
+func TestGetLedgerStatement(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+	organizationID := uuid.New()
+	ctx := tenant.WithOrganizationID(AdminContext, organizationID)
+	query := dto.LedgerStatementQueryDTO{StartAt: "2024-05-01T00:00:00+07:00", EndAt: "2024-06-01T00:00:00+07:00", Page: 2, Size: 10}
+
+	t.Run("When the account has lines in the period, should response a page of them with the opening balance", func(t *testing.T) {
+		account := &entity.LedgerAccount{ID: uuid.New(), OrganizationID: organizationID, Code: entity.LEDGER_ACCOUNT_REFUNDS_PAYABLE, Type: entity.LEDGER_ACCOUNT_TYPE_LIABILITY}
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		ledgerRepositoryMock := &ledgerRepositoryMock{}
+		ledgerRepositoryMock.On("FindAccountByCode", organizationID, account.Code).Return(account, nil)
+		ledgerRepositoryMock.On("SumAccountLines", account.ID).Return(int64(-20000), nil)
+		ledgerRepositoryMock.On("FindStatementLines", account.ID, 10, 10).Return([]entity.StatementLine{
+			{ID: uuid.New(), EntryID: uuid.New(), Type: entity.JOURNAL_ENTRY_REFUND, PostedAt: time.Now(), Debit: 5000, Balance: -15000},
+		}, int64(11), nil)
+		ledgerService := service.NewLedgerService(authRepositoryMock, ledgerRepositoryMock).WithContext(ctx)
+
+		res, statusCode := ledgerService.GetStatement(Username, account.Code, &query)
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			statement := res.Data.(vo.StatementResponse)
+			assert.Equal(t, int64(20000), statement.OpeningBalance)
+			assert.Equal(t, int64(15000), statement.Lines[0].Balance)
+			assert.Equal(t, uint(11), res.Pagination.Total)
+		}
+	})
+
+	t.Run("When the account has never been posted to, should response status code 404 with error message", func(t *testing.T) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		ledgerRepositoryMock := &ledgerRepositoryMock{}
+		ledgerRepositoryMock.On("FindAccountByCode", organizationID, mock.Anything).Return(&entity.LedgerAccount{}, gorm.ErrRecordNotFound)
+		ledgerService := service.NewLedgerService(authRepositoryMock, ledgerRepositoryMock).WithContext(ctx)
+
+		res, statusCode := ledgerService.GetStatement(Username, "unknown", &query)
+
+		assert.Equal(t, fiber.StatusNotFound, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_LEDGER_ACCOUNT_NOT_FOUND, res.ErrorMessage)
+	})
+}
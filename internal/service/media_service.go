@@ -0,0 +1,417 @@
+package service
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/logs"
+	"booking/internal/media"
+	"booking/internal/repository"
+	"booking/internal/storage"
+	"booking/internal/vo"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+const (
+	MEDIA_VARIANT_FILE      = "file"
+	MEDIA_VARIANT_THUMBNAIL = "thumbnail"
+)
+
+// MEDIA_FILE_NAME_LENGTH is the longest file name kept, in characters.
+const MEDIA_FILE_NAME_LENGTH = 255
+
+// mediaExtensions are the types of files that can be uploaded, sniffed from
+// their content rather than trusted from the client, and the extension of
+// their blobs.
+var mediaExtensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"application/pdf": ".pdf",
+}
+
+type IMediaService interface {
+	WithContext(ctx context.Context) IMediaService
+	UploadMedia(username string, resourceID uuid.UUID, fileName string, content []byte, createMediaDTO *dto.CreateMediaDTO) (res vo.Response, statusCode int)
+	GetResourceMedia(username string, resourceID uuid.UUID) (res vo.Response, statusCode int)
+	ReorderMedia(username string, resourceID uuid.UUID, reorderMediaDTO *dto.ReorderMediaDTO) (res vo.Response, statusCode int)
+	DeleteMedia(username string, resourceID uuid.UUID, mediaID uuid.UUID) (res vo.Response, statusCode int)
+	GetMediaFile(mediaID uuid.UUID, variant string, mediaFileQueryDTO *dto.MediaFileQueryDTO) (res vo.Response, statusCode int)
+}
+
+type mediaService struct {
+	ctx                context.Context
+	authRepository     repository.IAuthRepository
+	resourceRepository repository.IResourceRepository
+	mediaRepository    repository.IMediaRepository
+	blobStore          storage.BlobStore
+}
+
+func NewMediaService(authRepository repository.IAuthRepository, resourceRepository repository.IResourceRepository, mediaRepository repository.IMediaRepository, blobStore storage.BlobStore) *mediaService {
+	return &mediaService{
+		ctx:                context.Background(),
+		authRepository:     authRepository,
+		resourceRepository: resourceRepository,
+		mediaRepository:    mediaRepository,
+		blobStore:          blobStore,
+	}
+}
+
+func (mediaService *mediaService) WithContext(ctx context.Context) IMediaService {
+	service := *mediaService
+	service.ctx = ctx
+	service.resourceRepository = mediaService.resourceRepository.WithContext(ctx)
+	service.mediaRepository = mediaService.mediaRepository.WithContext(ctx)
+	return &service
+}
+
+// UploadMedia adds the file at the end of the gallery of the resource of the
+// user, with a thumbnail when it's an image.
+func (mediaService *mediaService) UploadMedia(username string, resourceID uuid.UUID, fileName string, content []byte, createMediaDTO *dto.CreateMediaDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(mediaService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+	resource, errorMessage, statusCode := findManagedResource(mediaService.ctx, mediaService.resourceRepository, resourceID)
+	if resource == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	if len(content) == 0 {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_FILE_REQUIRED)
+		return res, fiber.StatusBadRequest
+	}
+	if int64(len(content)) > viper.GetInt64("media.max_upload_bytes") {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_FILE_TOO_LARGE)
+		return res, fiber.StatusRequestEntityTooLarge
+	}
+	contentType, _, _ := strings.Cut(http.DetectContentType(content), ";")
+	extension, ok := mediaExtensions[contentType]
+	if !ok {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_UNSUPPORTED_MEDIA_TYPE)
+		return res, fiber.StatusUnsupportedMediaType
+	}
+
+	file := entity.MediaFile{
+		ID:          uuid.New(),
+		ResourceID:  resource.ID,
+		Kind:        createMediaDTO.Kind,
+		FileName:    mediaFileName(fileName, extension),
+		ContentType: contentType,
+		Size:        int64(len(content)),
+		Private:     createMediaDTO.Private,
+		UploadedBy:  user.ID,
+	}
+	if file.Kind == "" {
+		file.Kind = entity.MEDIA_KIND_PHOTO
+	}
+	file.Key = fmt.Sprintf("resources/%s/%s%s", resource.ID, file.ID, extension)
+
+	var thumbnail media.Thumbnail
+	var err error
+	if strings.HasPrefix(contentType, "image/") {
+		thumbnail, err = media.MakeThumbnail(content, viper.GetInt("media.thumbnail_px"))
+		if errors.Is(err, media.ErrImageTooLarge) {
+			res.SetErrorMessage(constant.ERROR_MESSAGE_FILE_TOO_LARGE)
+			return res, fiber.StatusRequestEntityTooLarge
+		}
+		if err != nil {
+			res.SetErrorMessage(constant.ERROR_MESSAGE_UNSUPPORTED_MEDIA_TYPE)
+			return res, fiber.StatusUnsupportedMediaType
+		}
+		file.ThumbnailKey = fmt.Sprintf("resources/%s/%s-thumbnail.jpg", resource.ID, file.ID)
+		file.Width = thumbnail.Width
+		file.Height = thumbnail.Height
+	}
+
+	err = mediaService.blobStore.Put(mediaService.ctx, file.Key, bytes.NewReader(content), file.Size, file.ContentType)
+	if err == nil && file.HasThumbnail() {
+		err = mediaService.blobStore.Put(mediaService.ctx, file.ThumbnailKey, bytes.NewReader(thumbnail.Content), int64(len(thumbnail.Content)), "image/jpeg")
+	}
+	if err == nil {
+		err = mediaService.mediaRepository.CreateMediaFile(&file)
+	}
+	if err != nil {
+		logs.Error(err)
+		mediaService.deleteBlobs(&file)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(newMediaFileResponse(&file, time.Now()))
+	return res, fiber.StatusCreated
+}
+
+// GetResourceMedia returns the gallery of the resource in order, its private
+// files only to the admins of its organization and its staff.
+func (mediaService *mediaService) GetResourceMedia(username string, resourceID uuid.UUID) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(mediaService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+	resource, err := mediaService.resourceRepository.FindResourceByID(resourceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_RESOURCE_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	files, err := mediaService.mediaRepository.FindMediaFilesByResourceID(resource.ID)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	staff := false
+	if slices.ContainsFunc(files, func(file entity.MediaFile) bool { return file.Private }) {
+		staff, err = isResourceStaff(mediaService.ctx, mediaService.resourceRepository, resource, user)
+		if err != nil {
+			logs.Error(err)
+			res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+			return res, fiber.StatusInternalServerError
+		}
+	}
+
+	now := time.Now()
+	responses := []vo.MediaFileResponse{}
+	for i := range files {
+		if files[i].Private && !staff {
+			continue
+		}
+		responses = append(responses, newMediaFileResponse(&files[i], now))
+	}
+	res.SetData(responses)
+	return res, fiber.StatusOK
+}
+
+// ReorderMedia puts the gallery of the resource of the user in the order of
+// the IDs, which list each of its files once.
+func (mediaService *mediaService) ReorderMedia(username string, resourceID uuid.UUID, reorderMediaDTO *dto.ReorderMediaDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(mediaService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+	resource, errorMessage, statusCode := findManagedResource(mediaService.ctx, mediaService.resourceRepository, resourceID)
+	if resource == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+	files, err := mediaService.mediaRepository.FindMediaFilesByResourceID(resource.ID)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	positions := map[uuid.UUID]int{}
+	ids := []uuid.UUID{}
+	for position, value := range reorderMediaDTO.IDs {
+		id, _ := uuid.Parse(value)
+		if _, found := positions[id]; found {
+			break
+		}
+		positions[id] = position
+		ids = append(ids, id)
+	}
+	valid := len(ids) == len(reorderMediaDTO.IDs) && len(ids) == len(files)
+	for i := 0; valid && i < len(files); i++ {
+		_, valid = positions[files[i].ID]
+	}
+	if !valid {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INVALID_MEDIA_ORDER)
+		return res, fiber.StatusBadRequest
+	}
+
+	err = mediaService.mediaRepository.ReorderMediaFiles(resource.ID, ids)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	for i := range files {
+		files[i].Position = positions[files[i].ID]
+	}
+	slices.SortFunc(files, func(a entity.MediaFile, b entity.MediaFile) int {
+		return a.Position - b.Position
+	})
+	now := time.Now()
+	responses := []vo.MediaFileResponse{}
+	for i := range files {
+		responses = append(responses, newMediaFileResponse(&files[i], now))
+	}
+	res.SetData(responses)
+	return res, fiber.StatusOK
+}
+
+// DeleteMedia removes the file from the gallery of the resource of the user,
+// and its blobs from the store.
+func (mediaService *mediaService) DeleteMedia(username string, resourceID uuid.UUID, mediaID uuid.UUID) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(mediaService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+	resource, errorMessage, statusCode := findManagedResource(mediaService.ctx, mediaService.resourceRepository, resourceID)
+	if resource == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+	file, err := mediaService.mediaRepository.FindMediaFileByID(mediaID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && file.ResourceID != resource.ID) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_MEDIA_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	err = mediaService.mediaRepository.DeleteMediaFile(file)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	mediaService.deleteBlobs(file)
+
+	res.SetData(nil)
+	return res, fiber.StatusOK
+}
+
+// GetMediaFile returns the content of the file or its thumbnail. It's read
+// without logging in, private files needing the signature of their URL.
+func (mediaService *mediaService) GetMediaFile(mediaID uuid.UUID, variant string, mediaFileQueryDTO *dto.MediaFileQueryDTO) (res vo.Response, statusCode int) {
+	file, err := mediaService.mediaRepository.FindMediaFileByID(mediaID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && variant == MEDIA_VARIANT_THUMBNAIL && !file.HasThumbnail()) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_MEDIA_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	if file.Private && !verifyMediaSignature(file.ID, variant, mediaFileQueryDTO.Expires, mediaFileQueryDTO.Signature, time.Now()) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INVALID_MEDIA_SIGNATURE)
+		return res, fiber.StatusForbidden
+	}
+
+	download := vo.FileResponse{FileName: file.FileName, ContentType: file.ContentType}
+	key := file.Key
+	if variant == MEDIA_VARIANT_THUMBNAIL {
+		download.FileName = strings.TrimSuffix(file.FileName, path.Ext(file.FileName)) + "-thumbnail.jpg"
+		download.ContentType = "image/jpeg"
+		key = file.ThumbnailKey
+	}
+	blob, err := mediaService.blobStore.Open(mediaService.ctx, key)
+	if err == nil {
+		download.Content, err = io.ReadAll(blob)
+		blob.Close()
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_MEDIA_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(download)
+	return res, fiber.StatusOK
+}
+
+// deleteBlobs removes the blobs of the file, a failure only leaving them
+// unused in the store.
+func (mediaService *mediaService) deleteBlobs(file *entity.MediaFile) {
+	for _, key := range []string{file.Key, file.ThumbnailKey} {
+		if key == "" {
+			continue
+		}
+		err := mediaService.blobStore.Delete(mediaService.ctx, key)
+		if err != nil {
+			logs.Error(err)
+		}
+	}
+}
+
+// mediaFileName keeps the base name of the uploaded file, the extension of
+// its type standing in for a missing name.
+func mediaFileName(fileName string, extension string) string {
+	fileName = strings.TrimSpace(path.Base(strings.ReplaceAll(fileName, "\\", "/")))
+	if fileName == "" || fileName == "." || fileName == "/" {
+		return "file" + extension
+	}
+	for utf8.RuneCountInString(fileName) > MEDIA_FILE_NAME_LENGTH {
+		_, size := utf8.DecodeLastRuneInString(fileName)
+		fileName = fileName[:len(fileName)-size]
+	}
+	return fileName
+}
+
+func newMediaFileResponse(file *entity.MediaFile, now time.Time) vo.MediaFileResponse {
+	thumbnailURL := ""
+	if file.HasThumbnail() {
+		thumbnailURL = mediaURL(file, MEDIA_VARIANT_THUMBNAIL, now)
+	}
+	return vo.NewMediaFileResponse(file, mediaURL(file, MEDIA_VARIANT_FILE, now), thumbnailURL)
+}
+
+// mediaURL returns the address of the variant of the file, private files
+// getting a signed one that expires.
+func mediaURL(file *entity.MediaFile, variant string, now time.Time) string {
+	address := strings.TrimSuffix(viper.GetString("app.public_url"), "/") + "/api/v1/media/" + file.ID.String() + "/" + variant
+	if !file.Private {
+		return address
+	}
+	expires := now.Add(viper.GetDuration("media.signed_url_minutes") * time.Minute).Unix()
+	query := url.Values{}
+	query.Set("expires", fmt.Sprint(expires))
+	query.Set("signature", base64.RawURLEncoding.EncodeToString(signMedia(file.ID, variant, expires)))
+	return address + "?" + query.Encode()
+}
+
+// verifyMediaSignature reports whether the signature was made by mediaURL
+// for the variant of the file and hasn't expired.
+func verifyMediaSignature(mediaID uuid.UUID, variant string, expires int64, signature string, now time.Time) bool {
+	if now.Unix() >= expires {
+		return false
+	}
+	signatureBytes, err := base64.RawURLEncoding.DecodeString(signature)
+	return err == nil && hmac.Equal(signatureBytes, signMedia(mediaID, variant, expires))
+}
+
+func signMedia(mediaID uuid.UUID, variant string, expires int64) []byte {
+	mac := hmac.New(sha256.New, []byte(viper.GetString("media.secret")))
+	fmt.Fprintf(mac, "media:%s:%s:%d", mediaID, variant, expires)
+	return mac.Sum(nil)
+}
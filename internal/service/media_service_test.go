@@ -0,0 +1,355 @@
+package service_test
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/service"
+	"booking/internal/storage"
+	"booking/internal/tenant"
+	"booking/internal/vo"
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"io"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const MediaOwner = "owner@gmail.com"
+
+func setMediaConfig() {
+	viper.Set("app.public_url", "https://booking.example/")
+	viper.Set("media.max_upload_bytes", 1<<20)
+	viper.Set("media.thumbnail_px", 64)
+	viper.Set("media.signed_url_minutes", 15)
+	viper.Set("media.secret", "mediasecret")
+}
+
+func pngFile(width int, height int) []byte {
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height)))
+	return buf.Bytes()
+}
+
+// newMediaService returns the media service of the requests of MediaOwner,
+// the owner of the organization of the resource, with its gallery and the
+// blob store of its files.
+func newMediaService(t *testing.T, resource *entity.Resource, files ...entity.MediaFile) (service.IMediaService, *mediaRepositoryMock, storage.BlobStore) {
+	setMediaConfig()
+	owner := &entity.User{ID: uuid.New(), Username: MediaOwner}
+	authRepositoryMock := &authRepositoryMock{}
+	authRepositoryMock.On("FindUserByUsername", MediaOwner).Return(owner, nil)
+	resourceRepositoryMock := &resourceRepositoryMock{}
+	resourceRepositoryMock.On("FindResourceByID", resource.ID).Return(resource, nil)
+	mediaRepositoryMock := newMediaRepositoryMock(files...)
+	blobStore := storage.NewLocalStore(t.TempDir())
+	mediaService := service.NewMediaService(authRepositoryMock, resourceRepositoryMock, mediaRepositoryMock, blobStore)
+	return mediaService.WithContext(tenant.WithRole(context.Background(), entity.ORGANIZATION_ROLE_OWNER)), mediaRepositoryMock, blobStore
+}
+
+func readBlob(blobStore storage.BlobStore, key string) string {
+	blob, err := blobStore.Open(context.Background(), key)
+	if err != nil {
+		return ""
+	}
+	defer blob.Close()
+	content, _ := io.ReadAll(blob)
+	return string(content)
+}
+
+func TestUploadMedia(t *testing.T) {
+	resource := &entity.Resource{ID: uuid.New()}
+
+	t.Run("When upload a photo, should store it with its thumbnail at the end of the gallery", func(t *testing.T) {
+		mediaService, mediaRepositoryMock, blobStore := newMediaService(t, resource, entity.MediaFile{ID: uuid.New(), ResourceID: resource.ID})
+		content := pngFile(400, 200)
+
+		res, statusCode := mediaService.UploadMedia(MediaOwner, resource.ID, "C:\\photos\\room.png", content, &dto.CreateMediaDTO{})
+
+		isPass := assert.Equal(t, fiber.StatusCreated, statusCode)
+		if isPass {
+			file := res.Data.(vo.MediaFileResponse)
+			assert.Equal(t, entity.MEDIA_KIND_PHOTO, file.Kind)
+			assert.Equal(t, "room.png", file.FileName)
+			assert.Equal(t, "image/png", file.ContentType)
+			assert.Equal(t, 400, file.Width)
+			assert.Equal(t, 200, file.Height)
+			assert.Equal(t, 1, file.Position)
+			assert.Equal(t, "https://booking.example/api/v1/media/"+file.ID.String()+"/file", file.URL)
+			assert.Equal(t, "https://booking.example/api/v1/media/"+file.ID.String()+"/thumbnail", file.ThumbnailURL)
+			saved, _ := mediaRepositoryMock.FindMediaFileByID(file.ID)
+			assert.Equal(t, string(content), readBlob(blobStore, saved.Key))
+			assert.NotEmpty(t, readBlob(blobStore, saved.ThumbnailKey))
+		}
+	})
+
+	t.Run("When upload a PDF floor plan, should store it without a thumbnail", func(t *testing.T) {
+		mediaService, _, _ := newMediaService(t, resource)
+
+		res, statusCode := mediaService.UploadMedia(MediaOwner, resource.ID, "plan.pdf", []byte("%PDF-1.4\n%EOF"), &dto.CreateMediaDTO{Kind: entity.MEDIA_KIND_FLOOR_PLAN})
+
+		isPass := assert.Equal(t, fiber.StatusCreated, statusCode)
+		if isPass {
+			file := res.Data.(vo.MediaFileResponse)
+			assert.Equal(t, entity.MEDIA_KIND_FLOOR_PLAN, file.Kind)
+			assert.Equal(t, "application/pdf", file.ContentType)
+			assert.Empty(t, file.ThumbnailURL)
+		}
+	})
+
+	t.Run("When upload a file that isn't an image or a PDF, should response status code 415 with error message", func(t *testing.T) {
+		mediaService, mediaRepositoryMock, _ := newMediaService(t, resource)
+
+		// the name claims an image but the content is a script
+		res, statusCode := mediaService.UploadMedia(MediaOwner, resource.ID, "room.png", []byte("<script>alert(1)</script>"), &dto.CreateMediaDTO{})
+
+		assert.Equal(t, fiber.StatusUnsupportedMediaType, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_UNSUPPORTED_MEDIA_TYPE, res.ErrorMessage)
+		assert.Empty(t, mediaRepositoryMock.files)
+	})
+
+	t.Run("When upload a file larger than the limit, should response status code 413 with error message", func(t *testing.T) {
+		mediaService, mediaRepositoryMock, _ := newMediaService(t, resource)
+		viper.Set("media.max_upload_bytes", 100)
+
+		res, statusCode := mediaService.UploadMedia(MediaOwner, resource.ID, "room.png", pngFile(400, 200), &dto.CreateMediaDTO{})
+
+		assert.Equal(t, fiber.StatusRequestEntityTooLarge, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_FILE_TOO_LARGE, res.ErrorMessage)
+		assert.Empty(t, mediaRepositoryMock.files)
+	})
+
+	t.Run("When upload no file, should response status code 400 with error message", func(t *testing.T) {
+		mediaService, _, _ := newMediaService(t, resource)
+
+		res, statusCode := mediaService.UploadMedia(MediaOwner, resource.ID, "", nil, &dto.CreateMediaDTO{})
+
+		assert.Equal(t, fiber.StatusBadRequest, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_FILE_REQUIRED, res.ErrorMessage)
+	})
+
+	t.Run("When someone who isn't an admin of the organization uploads, should response status code 403 with error message", func(t *testing.T) {
+		other := &entity.Resource{ID: uuid.New()}
+		setMediaConfig()
+		user := &entity.User{ID: uuid.New(), Username: "other@gmail.com"}
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", user.Username).Return(user, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindResourceByID", other.ID).Return(other, nil)
+		mediaService := service.NewMediaService(authRepositoryMock, resourceRepositoryMock, newMediaRepositoryMock(), storage.NewLocalStore(t.TempDir()))
+
+		res, statusCode := mediaService.UploadMedia(user.Username, other.ID, "room.png", pngFile(10, 10), &dto.CreateMediaDTO{})
+
+		assert.Equal(t, fiber.StatusForbidden, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_FORBIDDEN, res.ErrorMessage)
+	})
+}
+
+func TestGetResourceMedia(t *testing.T) {
+	resource := &entity.Resource{ID: uuid.New()}
+	photo := entity.MediaFile{ID: uuid.New(), ResourceID: resource.ID, Position: 1, ThumbnailKey: "photo-thumbnail.jpg"}
+	floorPlan := entity.MediaFile{ID: uuid.New(), ResourceID: resource.ID, Position: 0, Private: true}
+
+	t.Run("When the owner of the organization lists the gallery, should return every file in order with private ones signed", func(t *testing.T) {
+		mediaService, _, _ := newMediaService(t, resource, photo, floorPlan)
+
+		res, statusCode := mediaService.GetResourceMedia(MediaOwner, resource.ID)
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			files := res.Data.([]vo.MediaFileResponse)
+			if assert.Len(t, files, 2) {
+				assert.Equal(t, floorPlan.ID, files[0].ID)
+				assert.Contains(t, files[0].URL, "signature=")
+				assert.Equal(t, photo.ID, files[1].ID)
+				assert.NotContains(t, files[1].URL, "signature=")
+				assert.NotEmpty(t, files[1].ThumbnailURL)
+			}
+		}
+	})
+
+	t.Run("When a customer lists the gallery, should leave the private files out", func(t *testing.T) {
+		setMediaConfig()
+		customer := &entity.User{ID: uuid.New(), Username: "customer@gmail.com"}
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", customer.Username).Return(customer, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindResourceByID", resource.ID).Return(resource, nil)
+		resourceRepositoryMock.On("IsResourceStaff", resource.ID, customer.ID).Return(false, nil)
+		mediaService := service.NewMediaService(authRepositoryMock, resourceRepositoryMock, newMediaRepositoryMock(photo, floorPlan), storage.NewLocalStore(t.TempDir()))
+
+		res, statusCode := mediaService.GetResourceMedia(customer.Username, resource.ID)
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			files := res.Data.([]vo.MediaFileResponse)
+			if assert.Len(t, files, 1) {
+				assert.Equal(t, photo.ID, files[0].ID)
+			}
+		}
+	})
+}
+
+func TestReorderMedia(t *testing.T) {
+	resource := &entity.Resource{ID: uuid.New()}
+	first := entity.MediaFile{ID: uuid.New(), ResourceID: resource.ID, Position: 0}
+	second := entity.MediaFile{ID: uuid.New(), ResourceID: resource.ID, Position: 1}
+	third := entity.MediaFile{ID: uuid.New(), ResourceID: resource.ID, Position: 2}
+
+	t.Run("When reorder every file of the gallery, should return it in the new order", func(t *testing.T) {
+		mediaService, mediaRepositoryMock, _ := newMediaService(t, resource, first, second, third)
+
+		res, statusCode := mediaService.ReorderMedia(MediaOwner, resource.ID, &dto.ReorderMediaDTO{IDs: []string{third.ID.String(), first.ID.String(), second.ID.String()}})
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			files := res.Data.([]vo.MediaFileResponse)
+			assert.Equal(t, []uuid.UUID{third.ID, first.ID, second.ID}, []uuid.UUID{files[0].ID, files[1].ID, files[2].ID})
+			assert.Equal(t, []int{0, 1, 2}, []int{files[0].Position, files[1].Position, files[2].Position})
+			mediaRepositoryMock.AssertCalled(t, "ReorderMediaFiles", resource.ID, []uuid.UUID{third.ID, first.ID, second.ID})
+		}
+	})
+
+	invalidOrders := map[string][]string{
+		"missing a file":            {third.ID.String(), first.ID.String()},
+		"listing a file twice":      {third.ID.String(), first.ID.String(), first.ID.String()},
+		"listing an unknown file":   {third.ID.String(), first.ID.String(), uuid.NewString()},
+		"listing one file too many": {third.ID.String(), first.ID.String(), second.ID.String(), uuid.NewString()},
+	}
+	for name, ids := range invalidOrders {
+		t.Run("When the order is "+name+", should response status code 400 with error message", func(t *testing.T) {
+			mediaService, mediaRepositoryMock, _ := newMediaService(t, resource, first, second, third)
+
+			res, statusCode := mediaService.ReorderMedia(MediaOwner, resource.ID, &dto.ReorderMediaDTO{IDs: ids})
+
+			assert.Equal(t, fiber.StatusBadRequest, statusCode)
+			assert.Equal(t, constant.ERROR_MESSAGE_INVALID_MEDIA_ORDER, res.ErrorMessage)
+			mediaRepositoryMock.AssertNotCalled(t, "ReorderMediaFiles", resource.ID, mock.Anything)
+		})
+	}
+}
+
+func TestDeleteMedia(t *testing.T) {
+	resource := &entity.Resource{ID: uuid.New()}
+
+	t.Run("When delete a file of the gallery, should remove it and its blobs", func(t *testing.T) {
+		mediaService, mediaRepositoryMock, blobStore := newMediaService(t, resource)
+		uploaded, _ := mediaService.UploadMedia(MediaOwner, resource.ID, "room.png", pngFile(10, 10), &dto.CreateMediaDTO{})
+		file := mediaRepositoryMock.files[0]
+
+		_, statusCode := mediaService.DeleteMedia(MediaOwner, resource.ID, uploaded.Data.(vo.MediaFileResponse).ID)
+
+		assert.Equal(t, fiber.StatusOK, statusCode)
+		assert.Empty(t, mediaRepositoryMock.files)
+		assert.Empty(t, readBlob(blobStore, file.Key))
+		assert.Empty(t, readBlob(blobStore, file.ThumbnailKey))
+	})
+
+	t.Run("When delete a file of another resource, should response status code 404 with error message", func(t *testing.T) {
+		other := entity.MediaFile{ID: uuid.New(), ResourceID: uuid.New()}
+		mediaService, mediaRepositoryMock, _ := newMediaService(t, resource, other)
+
+		res, statusCode := mediaService.DeleteMedia(MediaOwner, resource.ID, other.ID)
+
+		assert.Equal(t, fiber.StatusNotFound, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_MEDIA_NOT_FOUND, res.ErrorMessage)
+		assert.Len(t, mediaRepositoryMock.files, 1)
+	})
+}
+
+func TestGetMediaFile(t *testing.T) {
+	resource := &entity.Resource{ID: uuid.New()}
+	// upload uploads the file to the gallery and returns its description
+	upload := func(mediaService service.IMediaService, private bool) vo.MediaFileResponse {
+		res, _ := mediaService.UploadMedia(MediaOwner, resource.ID, "room.png", pngFile(100, 100), &dto.CreateMediaDTO{Private: private})
+		return res.Data.(vo.MediaFileResponse)
+	}
+	signature := func(address string) *dto.MediaFileQueryDTO {
+		parsed, _ := url.Parse(address)
+		expires, _ := strconv.ParseInt(parsed.Query().Get("expires"), 10, 64)
+		return &dto.MediaFileQueryDTO{Expires: expires, Signature: parsed.Query().Get("signature")}
+	}
+
+	t.Run("When download a public file, should return its content and type", func(t *testing.T) {
+		mediaService, _, _ := newMediaService(t, resource)
+		file := upload(mediaService, false)
+
+		res, statusCode := mediaService.GetMediaFile(file.ID, service.MEDIA_VARIANT_FILE, &dto.MediaFileQueryDTO{})
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			download := res.Data.(vo.FileResponse)
+			assert.Equal(t, "room.png", download.FileName)
+			assert.Equal(t, "image/png", download.ContentType)
+			assert.Equal(t, pngFile(100, 100), download.Content)
+		}
+	})
+
+	t.Run("When download the thumbnail of a photo, should return a JPEG", func(t *testing.T) {
+		mediaService, _, _ := newMediaService(t, resource)
+		file := upload(mediaService, false)
+
+		res, statusCode := mediaService.GetMediaFile(file.ID, service.MEDIA_VARIANT_THUMBNAIL, &dto.MediaFileQueryDTO{})
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			download := res.Data.(vo.FileResponse)
+			assert.Equal(t, "room-thumbnail.jpg", download.FileName)
+			assert.Equal(t, "image/jpeg", download.ContentType)
+		}
+	})
+
+	t.Run("When download a private file with its signed URL, should return its content", func(t *testing.T) {
+		mediaService, _, _ := newMediaService(t, resource)
+		file := upload(mediaService, true)
+
+		_, statusCode := mediaService.GetMediaFile(file.ID, service.MEDIA_VARIANT_FILE, signature(file.URL))
+
+		assert.Equal(t, fiber.StatusOK, statusCode)
+	})
+
+	t.Run("When download a private file without a valid signature, should response status code 403 with error message", func(t *testing.T) {
+		mediaService, _, _ := newMediaService(t, resource)
+		file := upload(mediaService, true)
+		tampered := signature(file.URL)
+		tampered.Expires += 3600
+
+		for _, query := range []*dto.MediaFileQueryDTO{{}, tampered, signature(file.ThumbnailURL)} {
+			res, statusCode := mediaService.GetMediaFile(file.ID, service.MEDIA_VARIANT_FILE, query)
+
+			assert.Equal(t, fiber.StatusForbidden, statusCode)
+			assert.Equal(t, constant.ERROR_MESSAGE_INVALID_MEDIA_SIGNATURE, res.ErrorMessage)
+		}
+	})
+
+	t.Run("When the signed URL of a private file has expired, should response status code 403 with error message", func(t *testing.T) {
+		mediaService, _, _ := newMediaService(t, resource)
+		viper.Set("media.signed_url_minutes", -1)
+		file := upload(mediaService, true)
+
+		res, statusCode := mediaService.GetMediaFile(file.ID, service.MEDIA_VARIANT_FILE, signature(file.URL))
+
+		assert.Equal(t, fiber.StatusForbidden, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_INVALID_MEDIA_SIGNATURE, res.ErrorMessage)
+	})
+
+	t.Run("When download the thumbnail of a PDF, should response status code 404 with error message", func(t *testing.T) {
+		mediaService, _, _ := newMediaService(t, resource)
+		res, _ := mediaService.UploadMedia(MediaOwner, resource.ID, "plan.pdf", []byte("%PDF-1.4\n%EOF"), &dto.CreateMediaDTO{})
+		file := res.Data.(vo.MediaFileResponse)
+
+		res, statusCode := mediaService.GetMediaFile(file.ID, service.MEDIA_VARIANT_THUMBNAIL, &dto.MediaFileQueryDTO{})
+
+		assert.Equal(t, fiber.StatusNotFound, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_MEDIA_NOT_FOUND, res.ErrorMessage)
+	})
+}
@@ -2,11 +2,26 @@ package service_test
 
 import (
 	"booking/internal/entity"
+	"booking/internal/repository"
+	"booking/internal/tenant"
 	"booking/internal/vo"
+	"context"
+	"slices"
+	"sort"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
+// WebhookSecret signs the webhooks of the fake payment provider.
+const WebhookSecret = "webhooksecret"
+
+// AdminContext is the context of the requests of an admin of the
+// organization, which manages its resources.
+var AdminContext = tenant.WithRole(context.Background(), entity.ORGANIZATION_ROLE_ADMIN)
+
 type authRepositoryMock struct {
 	mock.Mock
 }
@@ -21,6 +36,11 @@ func (repo *authRepositoryMock) FindUserByUsername(username string) (user *entit
 	return args.Get(0).(*entity.User), args.Error(1)
 }
 
+func (repo *authRepositoryMock) FindUserByID(id uuid.UUID) (user *entity.User, err error) {
+	args := repo.Called(id)
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
 type utilMock struct {
 	mock.Mock
 }
@@ -97,3 +117,1037 @@ func (cache *cacheMock) GetAccessTokenKey(username string) string {
 	args := cache.Called(username)
 	return args.String(0)
 }
+
+func (cache *cacheMock) SaveOrganizationRole(organizationID uuid.UUID, username string, role string) error {
+	args := cache.Called(organizationID, username, role)
+	return args.Error(0)
+}
+
+func (cache *cacheMock) GetOrganizationRole(organizationID uuid.UUID, username string) (string, bool, error) {
+	args := cache.Called(organizationID, username)
+	return args.String(0), args.Bool(1), args.Error(2)
+}
+
+func (cache *cacheMock) DeleteOrganizationRole(organizationID uuid.UUID, username string) error {
+	args := cache.Called(organizationID, username)
+	return args.Error(0)
+}
+
+func (cache *cacheMock) GetOrganizationRoleKey(organizationID uuid.UUID, username string) string {
+	args := cache.Called(organizationID, username)
+	return args.String(0)
+}
+
+type resourceRepositoryMock struct {
+	mock.Mock
+}
+
+func (repo *resourceRepositoryMock) WithContext(ctx context.Context) repository.IResourceRepository {
+	return repo
+}
+
+func (repo *resourceRepositoryMock) FindResourceByID(id uuid.UUID) (resource *entity.Resource, err error) {
+	args := repo.Called(id)
+	return args.Get(0).(*entity.Resource), args.Error(1)
+}
+
+func (repo *resourceRepositoryMock) CreateResource(resource *entity.Resource) (err error) {
+	args := repo.Called(resource.Name)
+	return args.Error(0)
+}
+
+func (repo *resourceRepositoryMock) UpdateResource(resource *entity.Resource) (err error) {
+	args := repo.Called(resource.ID)
+	return args.Error(0)
+}
+
+func (repo *resourceRepositoryMock) SearchResources(search repository.ResourceSearch, offset int, limit int) (resources []entity.Resource, total int64, err error) {
+	args := repo.Called(search, offset, limit)
+	return args.Get(0).([]entity.Resource), args.Get(1).(int64), args.Error(2)
+}
+
+func (repo *resourceRepositoryMock) FindCancellationPolicyByResourceID(resourceID uuid.UUID) (policy *entity.CancellationPolicy, err error) {
+	args := repo.Called(resourceID)
+	return args.Get(0).(*entity.CancellationPolicy), args.Error(1)
+}
+
+func (repo *resourceRepositoryMock) SaveCancellationPolicy(policy *entity.CancellationPolicy) (err error) {
+	args := repo.Called(policy.ResourceID)
+	return args.Error(0)
+}
+
+func (repo *resourceRepositoryMock) FindReschedulePolicyByResourceID(resourceID uuid.UUID) (policy *entity.ReschedulePolicy, err error) {
+	args := repo.Called(resourceID)
+	return args.Get(0).(*entity.ReschedulePolicy), args.Error(1)
+}
+
+func (repo *resourceRepositoryMock) SaveReschedulePolicy(policy *entity.ReschedulePolicy) (err error) {
+	args := repo.Called(policy.ResourceID, policy.Rules)
+	return args.Error(0)
+}
+
+func (repo *resourceRepositoryMock) FindPaymentScheduleByResourceID(resourceID uuid.UUID) (schedule *entity.PaymentSchedule, err error) {
+	args := repo.Called(resourceID)
+	return args.Get(0).(*entity.PaymentSchedule), args.Error(1)
+}
+
+func (repo *resourceRepositoryMock) SavePaymentSchedule(schedule *entity.PaymentSchedule) (err error) {
+	args := repo.Called(schedule.ResourceID)
+	return args.Error(0)
+}
+
+func (repo *resourceRepositoryMock) FindPricingPolicyByResourceID(resourceID uuid.UUID) (policy *entity.PricingPolicy, err error) {
+	args := repo.Called(resourceID)
+	return args.Get(0).(*entity.PricingPolicy), args.Error(1)
+}
+
+func (repo *resourceRepositoryMock) SavePricingPolicy(policy *entity.PricingPolicy) (err error) {
+	args := repo.Called(policy.ResourceID)
+	return args.Error(0)
+}
+
+func (repo *resourceRepositoryMock) IsResourceMember(resourceID uuid.UUID, userID uuid.UUID) (member bool, err error) {
+	args := repo.Called(resourceID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (repo *resourceRepositoryMock) AddResourceMember(member *entity.ResourceMember) (err error) {
+	args := repo.Called(member.ResourceID, member.UserID)
+	return args.Error(0)
+}
+
+func (repo *resourceRepositoryMock) RemoveResourceMember(resourceID uuid.UUID, userID uuid.UUID) (deleted int64, err error) {
+	args := repo.Called(resourceID, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (repo *resourceRepositoryMock) IsResourceStaff(resourceID uuid.UUID, userID uuid.UUID) (staff bool, err error) {
+	args := repo.Called(resourceID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (repo *resourceRepositoryMock) AddResourceStaff(staff *entity.ResourceStaff) (err error) {
+	args := repo.Called(staff.ResourceID, staff.UserID)
+	return args.Error(0)
+}
+
+func (repo *resourceRepositoryMock) RemoveResourceStaff(resourceID uuid.UUID, userID uuid.UUID) (deleted int64, err error) {
+	args := repo.Called(resourceID, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// bookingRepositoryMock runs transactions inline, so WithTx returns the mock itself.
+type bookingRepositoryMock struct {
+	mock.Mock
+	released []uuid.UUID
+}
+
+func (repo *bookingRepositoryMock) Transaction(fc func(tx *gorm.DB) error) (err error) {
+	return fc(nil)
+}
+
+func (repo *bookingRepositoryMock) WithTx(tx *gorm.DB) repository.IBookingRepository {
+	return repo
+}
+
+func (repo *bookingRepositoryMock) WithContext(ctx context.Context) repository.IBookingRepository {
+	return repo
+}
+
+func (repo *bookingRepositoryMock) LockResourceByID(id uuid.UUID) (resource *entity.Resource, err error) {
+	args := repo.Called(id)
+	return args.Get(0).(*entity.Resource), args.Error(1)
+}
+
+func (repo *bookingRepositoryMock) CreateBooking(booking *entity.Booking) (err error) {
+	args := repo.Called(booking.ResourceID)
+	return args.Error(0)
+}
+
+func (repo *bookingRepositoryMock) FindBookingByID(id uuid.UUID) (booking *entity.Booking, err error) {
+	args := repo.Called(id)
+	return args.Get(0).(*entity.Booking), args.Error(1)
+}
+
+func (repo *bookingRepositoryMock) LockBookingByID(id uuid.UUID) (booking *entity.Booking, err error) {
+	args := repo.Called(id)
+	return args.Get(0).(*entity.Booking), args.Error(1)
+}
+
+func (repo *bookingRepositoryMock) UpdateBooking(booking *entity.Booking) (err error) {
+	args := repo.Called(booking.ID)
+	return args.Error(0)
+}
+
+func (repo *bookingRepositoryMock) FindOverlappingBookings(resourceID uuid.UUID, startAt time.Time, endAt time.Time) (bookings []entity.Booking, err error) {
+	args := repo.Called(resourceID, startAt)
+	return args.Get(0).([]entity.Booking), args.Error(1)
+}
+
+func (repo *bookingRepositoryMock) FindOverdueBookings(now time.Time, limit int) (bookings []entity.Booking, err error) {
+	args := repo.Called(limit)
+	return args.Get(0).([]entity.Booking), args.Error(1)
+}
+
+func (repo *bookingRepositoryMock) FindStaleHolds(createdBefore time.Time, limit int) (bookings []entity.Booking, err error) {
+	args := repo.Called(createdBefore)
+	return args.Get(0).([]entity.Booking), args.Error(1)
+}
+
+func (repo *bookingRepositoryMock) FindMissedCheckIns(startedAfter time.Time, startedBefore time.Time, limit int) (bookings []entity.Booking, err error) {
+	args := repo.Called(startedBefore)
+	return args.Get(0).([]entity.Booking), args.Error(1)
+}
+
+func (repo *bookingRepositoryMock) RecordBookingFailure(id uuid.UUID, lastError string, failedAt time.Time) (err error) {
+	args := repo.Called(id)
+	return args.Error(0)
+}
+
+func (repo *bookingRepositoryMock) FindNoShows(userID uuid.UUID, since time.Time) (bookings []entity.Booking, err error) {
+	args := repo.Called(userID, since)
+	return args.Get(0).([]entity.Booking), args.Error(1)
+}
+
+func (repo *bookingRepositoryMock) ChargeNoShowFees(ids []uuid.UUID, bookingID uuid.UUID) (charged int64, err error) {
+	args := repo.Called(ids)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// ReleaseNoShowFees is called whenever a booking is cancelled, the released
+// bookings are kept for the tests checking it.
+func (repo *bookingRepositoryMock) ReleaseNoShowFees(bookingID uuid.UUID) (err error) {
+	repo.released = append(repo.released, bookingID)
+	return nil
+}
+
+func (repo *bookingRepositoryMock) FindBookingsByUserID(userID uuid.UUID, endAfter time.Time) (bookings []entity.Booking, err error) {
+	args := repo.Called(userID)
+	return args.Get(0).([]entity.Booking), args.Error(1)
+}
+
+func (repo *bookingRepositoryMock) CreateBookingSeries(series *entity.BookingSeries) (err error) {
+	args := repo.Called(series.ResourceID)
+	return args.Error(0)
+}
+
+func (repo *bookingRepositoryMock) FindBookingSeriesByID(id uuid.UUID) (series *entity.BookingSeries, err error) {
+	args := repo.Called(id)
+	return args.Get(0).(*entity.BookingSeries), args.Error(1)
+}
+
+func (repo *bookingRepositoryMock) LockBookingSeriesByID(id uuid.UUID) (series *entity.BookingSeries, err error) {
+	args := repo.Called(id)
+	return args.Get(0).(*entity.BookingSeries), args.Error(1)
+}
+
+func (repo *bookingRepositoryMock) UpdateBookingSeries(series *entity.BookingSeries) (err error) {
+	args := repo.Called(series.ID)
+	return args.Error(0)
+}
+
+func (repo *bookingRepositoryMock) FindBookingsBySeriesID(seriesID uuid.UUID) (bookings []entity.Booking, err error) {
+	args := repo.Called(seriesID)
+	return args.Get(0).([]entity.Booking), args.Error(1)
+}
+
+func (repo *bookingRepositoryMock) CreateBookingChange(change *entity.BookingChange) (err error) {
+	args := repo.Called(change.BookingID, change.Type)
+	return args.Error(0)
+}
+
+func (repo *bookingRepositoryMock) FindBookingChanges(bookingID uuid.UUID) (changes []entity.BookingChange, err error) {
+	args := repo.Called(bookingID)
+	return args.Get(0).([]entity.BookingChange), args.Error(1)
+}
+
+func (repo *bookingRepositoryMock) FindProviderBookings(providerIDs []uuid.UUID, startAt time.Time, endAt time.Time) (bookings []entity.Booking, err error) {
+	args := repo.Called(providerIDs)
+	return args.Get(0).([]entity.Booking), args.Error(1)
+}
+
+type waitlistRepositoryMock struct {
+	mock.Mock
+}
+
+// newWaitlistRepositoryMock returns a waitlist with nobody waiting.
+func newWaitlistRepositoryMock() *waitlistRepositoryMock {
+	repo := &waitlistRepositoryMock{}
+	repo.On("ExpireEntries", mock.Anything).Return(nil)
+	repo.On("FindWaitingEntries", mock.Anything).Return([]entity.WaitlistEntry{}, nil)
+	repo.On("FindActiveOffers", mock.Anything, mock.Anything).Return([]entity.WaitlistEntry{}, nil)
+	return repo
+}
+
+func (repo *waitlistRepositoryMock) WithTx(tx *gorm.DB) repository.IWaitlistRepository {
+	return repo
+}
+
+func (repo *waitlistRepositoryMock) WithContext(ctx context.Context) repository.IWaitlistRepository {
+	return repo
+}
+
+func (repo *waitlistRepositoryMock) CreateEntry(entry *entity.WaitlistEntry) (err error) {
+	args := repo.Called(entry.ResourceID)
+	return args.Error(0)
+}
+
+func (repo *waitlistRepositoryMock) FindEntryByID(id uuid.UUID) (entry *entity.WaitlistEntry, err error) {
+	args := repo.Called(id)
+	return args.Get(0).(*entity.WaitlistEntry), args.Error(1)
+}
+
+func (repo *waitlistRepositoryMock) LockEntryByID(id uuid.UUID) (entry *entity.WaitlistEntry, err error) {
+	args := repo.Called(id)
+	return args.Get(0).(*entity.WaitlistEntry), args.Error(1)
+}
+
+func (repo *waitlistRepositoryMock) UpdateEntry(entry *entity.WaitlistEntry) (err error) {
+	args := repo.Called(entry.ID)
+	return args.Error(0)
+}
+
+func (repo *waitlistRepositoryMock) FindEntriesByUserID(userID uuid.UUID) (entries []entity.WaitlistEntry, err error) {
+	args := repo.Called(userID)
+	return args.Get(0).([]entity.WaitlistEntry), args.Error(1)
+}
+
+func (repo *waitlistRepositoryMock) FindWaitingEntries(resourceID uuid.UUID) (entries []entity.WaitlistEntry, err error) {
+	args := repo.Called(resourceID)
+	return args.Get(0).([]entity.WaitlistEntry), args.Error(1)
+}
+
+func (repo *waitlistRepositoryMock) ExpireEntries(resourceID uuid.UUID, now time.Time) (err error) {
+	args := repo.Called(resourceID)
+	return args.Error(0)
+}
+
+func (repo *waitlistRepositoryMock) FindActiveOffers(resourceID uuid.UUID, startAt time.Time, endAt time.Time, now time.Time, excludedUserID uuid.UUID) (entries []entity.WaitlistEntry, err error) {
+	args := repo.Called(resourceID, excludedUserID)
+	return args.Get(0).([]entity.WaitlistEntry), args.Error(1)
+}
+
+func (repo *waitlistRepositoryMock) CountActiveEntriesOfUser(userID uuid.UUID, resourceID uuid.UUID, startAt time.Time, endAt time.Time) (count int64, err error) {
+	args := repo.Called(userID, resourceID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (repo *waitlistRepositoryMock) CountWaitingAhead(entry *entity.WaitlistEntry) (count int64, err error) {
+	args := repo.Called(entry.ID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type scheduleRepositoryMock struct {
+	mock.Mock
+}
+
+// newScheduleRepositoryMock returns the schedule of a resource open around the
+// clock.
+func newScheduleRepositoryMock() *scheduleRepositoryMock {
+	repo := &scheduleRepositoryMock{}
+	repo.On("FindOpeningHours", mock.Anything).Return([]entity.OpeningHours{}, nil)
+	repo.On("FindDateOverrides", mock.Anything).Return([]entity.DateOverride{}, nil)
+	repo.On("FindHolidays", mock.Anything).Return([]entity.Holiday{}, nil)
+	repo.On("FindBlackoutPeriods", mock.Anything).Return([]entity.BlackoutPeriod{}, nil)
+	return repo
+}
+
+func (repo *scheduleRepositoryMock) FindOpeningHours(resourceID uuid.UUID) (hours []entity.OpeningHours, err error) {
+	args := repo.Called(resourceID)
+	return args.Get(0).([]entity.OpeningHours), args.Error(1)
+}
+
+func (repo *scheduleRepositoryMock) ReplaceOpeningHours(resourceID uuid.UUID, hours []entity.OpeningHours) (err error) {
+	args := repo.Called(resourceID, hours)
+	return args.Error(0)
+}
+
+func (repo *scheduleRepositoryMock) FindDateOverrides(resourceID uuid.UUID, fromDate string, toDate string) (overrides []entity.DateOverride, err error) {
+	args := repo.Called(resourceID)
+	return args.Get(0).([]entity.DateOverride), args.Error(1)
+}
+
+func (repo *scheduleRepositoryMock) SaveDateOverride(override *entity.DateOverride) (err error) {
+	args := repo.Called(override.ResourceID)
+	return args.Error(0)
+}
+
+func (repo *scheduleRepositoryMock) DeleteDateOverride(resourceID uuid.UUID, date string) (deleted int64, err error) {
+	args := repo.Called(resourceID, date)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (repo *scheduleRepositoryMock) FindHolidayCalendar(code string) (calendar *entity.HolidayCalendar, err error) {
+	args := repo.Called(code)
+	return args.Get(0).(*entity.HolidayCalendar), args.Error(1)
+}
+
+func (repo *scheduleRepositoryMock) FindHolidays(calendarCode string, fromDate string, toDate string) (holidays []entity.Holiday, err error) {
+	args := repo.Called(calendarCode)
+	return args.Get(0).([]entity.Holiday), args.Error(1)
+}
+
+func (repo *scheduleRepositoryMock) SaveHolidays(calendar *entity.HolidayCalendar, holidays []entity.Holiday) (err error) {
+	args := repo.Called(calendar.Code)
+	return args.Error(0)
+}
+
+func (repo *scheduleRepositoryMock) FindBlackoutPeriods(resourceID uuid.UUID, startAt time.Time, endAt time.Time) (blackouts []entity.BlackoutPeriod, err error) {
+	args := repo.Called(resourceID)
+	return args.Get(0).([]entity.BlackoutPeriod), args.Error(1)
+}
+
+func (repo *scheduleRepositoryMock) CreateBlackoutPeriod(blackout *entity.BlackoutPeriod) (err error) {
+	args := repo.Called(blackout.ResourceID)
+	return args.Error(0)
+}
+
+func (repo *scheduleRepositoryMock) DeleteBlackoutPeriod(resourceID uuid.UUID, id uuid.UUID) (deleted int64, err error) {
+	args := repo.Called(resourceID, id)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type promoCodeRepositoryMock struct {
+	mock.Mock
+}
+
+func (repo *promoCodeRepositoryMock) WithTx(tx *gorm.DB) repository.IPromoCodeRepository {
+	return repo
+}
+
+func (repo *promoCodeRepositoryMock) WithContext(ctx context.Context) repository.IPromoCodeRepository {
+	return repo
+}
+
+func (repo *promoCodeRepositoryMock) CreatePromoCode(promoCode *entity.PromoCode) (err error) {
+	args := repo.Called(promoCode.Code)
+	return args.Error(0)
+}
+
+func (repo *promoCodeRepositoryMock) FindPromoCodeByCode(code string) (promoCode *entity.PromoCode, err error) {
+	args := repo.Called(code)
+	return args.Get(0).(*entity.PromoCode), args.Error(1)
+}
+
+func (repo *promoCodeRepositoryMock) LockPromoCodeByCode(code string) (promoCode *entity.PromoCode, err error) {
+	args := repo.Called(code)
+	return args.Get(0).(*entity.PromoCode), args.Error(1)
+}
+
+func (repo *promoCodeRepositoryMock) FindPromoCodes() (promoCodes []entity.PromoCode, err error) {
+	args := repo.Called()
+	return args.Get(0).([]entity.PromoCode), args.Error(1)
+}
+
+func (repo *promoCodeRepositoryMock) CountRedemptionsOfUser(promoCodeID uuid.UUID, userID uuid.UUID) (count int64, err error) {
+	args := repo.Called(promoCodeID, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (repo *promoCodeRepositoryMock) RedeemPromoCode(promoCode *entity.PromoCode, redemption *entity.PromoRedemption) (err error) {
+	args := repo.Called(promoCode.ID)
+	return args.Error(0)
+}
+
+type paymentRepositoryMock struct {
+	mock.Mock
+}
+
+// newPaymentRepositoryMock returns a repository that records the payments
+// started.
+func newPaymentRepositoryMock() *paymentRepositoryMock {
+	repo := &paymentRepositoryMock{}
+	repo.On("CreatePayment", mock.Anything).Return(nil)
+	return repo
+}
+
+func (repo *paymentRepositoryMock) WithTx(tx *gorm.DB) repository.IPaymentRepository {
+	return repo
+}
+
+func (repo *paymentRepositoryMock) WithContext(ctx context.Context) repository.IPaymentRepository {
+	return repo
+}
+
+func (repo *paymentRepositoryMock) CreatePayment(payment *entity.Payment) (err error) {
+	args := repo.Called(payment.BookingID)
+	return args.Error(0)
+}
+
+func (repo *paymentRepositoryMock) FindPaymentByID(id uuid.UUID) (payment *entity.Payment, err error) {
+	args := repo.Called(id)
+	return args.Get(0).(*entity.Payment), args.Error(1)
+}
+
+func (repo *paymentRepositoryMock) FindPaymentByIntentID(intentID string) (payment *entity.Payment, err error) {
+	args := repo.Called(intentID)
+	return args.Get(0).(*entity.Payment), args.Error(1)
+}
+
+func (repo *paymentRepositoryMock) LockPaymentByIntentID(intentID string) (payment *entity.Payment, err error) {
+	args := repo.Called(intentID)
+	return args.Get(0).(*entity.Payment), args.Error(1)
+}
+
+func (repo *paymentRepositoryMock) FindPaymentsByBookingID(bookingID uuid.UUID) (payments []entity.Payment, err error) {
+	args := repo.Called(bookingID)
+	return args.Get(0).([]entity.Payment), args.Error(1)
+}
+
+func (repo *paymentRepositoryMock) UpdatePayment(payment *entity.Payment) (err error) {
+	args := repo.Called(payment.ID, payment.Status)
+	return args.Error(0)
+}
+
+func (repo *paymentRepositoryMock) CreatePaymentEvent(event *entity.PaymentEvent) (created bool, err error) {
+	args := repo.Called(event.EventID)
+	return args.Bool(0), args.Error(1)
+}
+
+type ledgerRepositoryMock struct {
+	mock.Mock
+	entries []entity.JournalEntry
+}
+
+// newLedgerRepositoryMock returns a ledger that records the entries posted.
+// Accounts are identified by the owner and code so they can be told apart in
+// the lines of the entries.
+func newLedgerRepositoryMock() *ledgerRepositoryMock {
+	repo := &ledgerRepositoryMock{}
+	repo.On("FindOrCreateAccount", mock.Anything).Return(nil)
+	repo.On("CreateJournalEntry", mock.Anything).Return(nil)
+	return repo
+}
+
+// balance returns the debits less the credits posted to the account of the
+// owner.
+func (repo *ledgerRepositoryMock) balance(organizationID uuid.UUID, code string) (balance int64) {
+	accountID := ledgerAccountID(organizationID, code)
+	for _, entry := range repo.entries {
+		for _, line := range entry.Lines {
+			if line.AccountID == accountID {
+				balance += line.Debit - line.Credit
+			}
+		}
+	}
+	return balance
+}
+
+func ledgerAccountID(organizationID uuid.UUID, code string) uuid.UUID {
+	return uuid.NewSHA1(organizationID, []byte(code))
+}
+
+func (repo *ledgerRepositoryMock) WithTx(tx *gorm.DB) repository.ILedgerRepository {
+	return repo
+}
+
+func (repo *ledgerRepositoryMock) FindOrCreateAccount(organizationID uuid.UUID, code string) (account *entity.LedgerAccount, err error) {
+	args := repo.Called(code)
+	return &entity.LedgerAccount{ID: ledgerAccountID(organizationID, code), OrganizationID: organizationID, Code: code, Type: entity.LedgerAccountType(code)}, args.Error(0)
+}
+
+func (repo *ledgerRepositoryMock) FindAccountByCode(organizationID uuid.UUID, code string) (account *entity.LedgerAccount, err error) {
+	args := repo.Called(organizationID, code)
+	return args.Get(0).(*entity.LedgerAccount), args.Error(1)
+}
+
+func (repo *ledgerRepositoryMock) CreateJournalEntry(entry *entity.JournalEntry) (err error) {
+	args := repo.Called(entry.Type)
+	err = entry.Validate()
+	if err != nil {
+		return err
+	}
+	repo.entries = append(repo.entries, *entry)
+	return args.Error(0)
+}
+
+func (repo *ledgerRepositoryMock) FindAccountBalances(organizationID uuid.UUID, at time.Time) (balances []entity.AccountBalance, err error) {
+	args := repo.Called(organizationID)
+	return args.Get(0).([]entity.AccountBalance), args.Error(1)
+}
+
+func (repo *ledgerRepositoryMock) SumAccountLines(accountID uuid.UUID, before time.Time) (balance int64, err error) {
+	args := repo.Called(accountID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (repo *ledgerRepositoryMock) FindStatementLines(accountID uuid.UUID, startAt time.Time, endAt time.Time, offset int, limit int) (lines []entity.StatementLine, total int64, err error) {
+	args := repo.Called(accountID, offset, limit)
+	return args.Get(0).([]entity.StatementLine), args.Get(1).(int64), args.Error(2)
+}
+
+type taxDocumentRepositoryMock struct {
+	mock.Mock
+}
+
+func (repo *taxDocumentRepositoryMock) WithTx(tx *gorm.DB) repository.ITaxDocumentRepository {
+	return repo
+}
+
+func (repo *taxDocumentRepositoryMock) FindBillingProfileByUserID(userID uuid.UUID) (profile *entity.BillingProfile, err error) {
+	args := repo.Called(userID)
+	return args.Get(0).(*entity.BillingProfile), args.Error(1)
+}
+
+func (repo *taxDocumentRepositoryMock) SaveBillingProfile(profile *entity.BillingProfile) (err error) {
+	args := repo.Called(profile.UserID)
+	return args.Error(0)
+}
+
+func (repo *taxDocumentRepositoryMock) FindOrganizationBillingProfile(organizationID uuid.UUID) (profile *entity.OrganizationBillingProfile, err error) {
+	args := repo.Called(organizationID)
+	return args.Get(0).(*entity.OrganizationBillingProfile), args.Error(1)
+}
+
+func (repo *taxDocumentRepositoryMock) SaveOrganizationBillingProfile(profile *entity.OrganizationBillingProfile) (err error) {
+	args := repo.Called(profile.OrganizationID)
+	return args.Error(0)
+}
+
+func (repo *taxDocumentRepositoryMock) FindTaxDocument(bookingID uuid.UUID, documentType string) (document *entity.TaxDocument, err error) {
+	args := repo.Called(bookingID, documentType)
+	return args.Get(0).(*entity.TaxDocument), args.Error(1)
+}
+
+func (repo *taxDocumentRepositoryMock) NextDocumentNumber(organizationID uuid.UUID, documentType string, year int) (number int64, err error) {
+	args := repo.Called(organizationID, documentType)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (repo *taxDocumentRepositoryMock) CreateTaxDocument(document *entity.TaxDocument) (err error) {
+	args := repo.Called(document.BookingID, document.Type)
+	return args.Error(0)
+}
+
+type calendarRepositoryMock struct {
+	mock.Mock
+}
+
+func (repo *calendarRepositoryMock) FindCalendarFeedByUserID(userID uuid.UUID) (feed *entity.CalendarFeed, err error) {
+	args := repo.Called(userID)
+	return args.Get(0).(*entity.CalendarFeed), args.Error(1)
+}
+
+func (repo *calendarRepositoryMock) FindCalendarFeedByToken(token string) (feed *entity.CalendarFeed, err error) {
+	args := repo.Called(token)
+	return args.Get(0).(*entity.CalendarFeed), args.Error(1)
+}
+
+func (repo *calendarRepositoryMock) SaveCalendarFeed(feed *entity.CalendarFeed) (err error) {
+	args := repo.Called(feed.UserID)
+	return args.Error(0)
+}
+
+type externalCalendarRepositoryMock struct {
+	mock.Mock
+	blackouts []entity.BlackoutPeriod
+}
+
+func (repo *externalCalendarRepositoryMock) FindExternalCalendars(resourceID uuid.UUID) (calendars []entity.ExternalCalendar, err error) {
+	args := repo.Called(resourceID)
+	return args.Get(0).([]entity.ExternalCalendar), args.Error(1)
+}
+
+func (repo *externalCalendarRepositoryMock) FindExternalCalendarByID(resourceID uuid.UUID, id uuid.UUID) (calendar *entity.ExternalCalendar, err error) {
+	args := repo.Called(resourceID, id)
+	return args.Get(0).(*entity.ExternalCalendar), args.Error(1)
+}
+
+func (repo *externalCalendarRepositoryMock) FindExternalCalendarsToSync(syncedBefore time.Time, limit int) (calendars []entity.ExternalCalendar, err error) {
+	args := repo.Called(limit)
+	return args.Get(0).([]entity.ExternalCalendar), args.Error(1)
+}
+
+func (repo *externalCalendarRepositoryMock) CreateExternalCalendar(calendar *entity.ExternalCalendar) (err error) {
+	args := repo.Called(calendar.ResourceID)
+	calendar.ID = uuid.New()
+	return args.Error(0)
+}
+
+func (repo *externalCalendarRepositoryMock) UpdateExternalCalendar(calendar *entity.ExternalCalendar) (err error) {
+	args := repo.Called(calendar.ID)
+	return args.Error(0)
+}
+
+func (repo *externalCalendarRepositoryMock) DeleteExternalCalendar(resourceID uuid.UUID, id uuid.UUID) (deleted int64, err error) {
+	args := repo.Called(resourceID, id)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// ReplaceImportedBlackouts records the blackout periods imported last.
+func (repo *externalCalendarRepositoryMock) ReplaceImportedBlackouts(calendar *entity.ExternalCalendar, blackouts []entity.BlackoutPeriod) (err error) {
+	args := repo.Called(calendar.ID)
+	repo.blackouts = blackouts
+	return args.Error(0)
+}
+
+type notificationRepositoryMock struct {
+	mock.Mock
+	preference    *entity.NotificationPreference
+	notifications []entity.Notification
+}
+
+// newNotificationRepositoryMock returns an outbox that records the
+// notifications queued, for users who are emailed by default.
+func newNotificationRepositoryMock() *notificationRepositoryMock {
+	repo := &notificationRepositoryMock{}
+	repo.On("FindRecipient", mock.Anything).Return(nil)
+	repo.On("CreateNotifications", mock.Anything).Return(nil)
+	return repo
+}
+
+func (repo *notificationRepositoryMock) WithTx(tx *gorm.DB) repository.INotificationRepository {
+	return repo
+}
+
+func (repo *notificationRepositoryMock) FindRecipient(userID uuid.UUID) (user *entity.User, preference *entity.NotificationPreference, err error) {
+	args := repo.Called(userID)
+	preference = repo.preference
+	if preference == nil {
+		preference = &entity.NotificationPreference{UserID: userID, Email: true, Language: "en"}
+	}
+	return &entity.User{ID: userID, Username: "example@gmail.com"}, preference, args.Error(0)
+}
+
+func (repo *notificationRepositoryMock) SavePreference(preference *entity.NotificationPreference) (err error) {
+	args := repo.Called(preference)
+	return args.Error(0)
+}
+
+func (repo *notificationRepositoryMock) CreateNotifications(notifications []entity.Notification) (err error) {
+	args := repo.Called(notifications)
+	repo.notifications = append(repo.notifications, notifications...)
+	return args.Error(0)
+}
+
+func (repo *notificationRepositoryMock) ClaimNotifications(now time.Time, lease time.Duration, limit int) (notifications []entity.Notification, err error) {
+	args := repo.Called(now)
+	return args.Get(0).([]entity.Notification), args.Error(1)
+}
+
+func (repo *notificationRepositoryMock) UpdateNotification(notification *entity.Notification) (err error) {
+	args := repo.Called(notification.ID)
+	return args.Error(0)
+}
+
+func (repo *notificationRepositoryMock) FindBookingsToRemind(startAfter time.Time, startBefore time.Time, limit int) (bookings []entity.Booking, err error) {
+	args := repo.Called(startAfter, startBefore)
+	return args.Get(0).([]entity.Booking), args.Error(1)
+}
+
+type jobRepositoryMock struct {
+	mock.Mock
+	jobs []entity.Job
+}
+
+// newJobRepositoryMock returns a job table that keeps the jobs enqueued and
+// hands out the due ones, recording the outcomes saved.
+func newJobRepositoryMock() *jobRepositoryMock {
+	repo := &jobRepositoryMock{}
+	repo.On("EnqueueJob", mock.Anything).Return(nil)
+	repo.On("UpdateJob", mock.Anything).Return(true, nil)
+	return repo
+}
+
+func (repo *jobRepositoryMock) EnqueueJob(job *entity.Job) (err error) {
+	args := repo.Called(job.Key)
+	for i := range repo.jobs {
+		if repo.jobs[i].Key == job.Key {
+			return args.Error(0)
+		}
+	}
+	job.ID = uuid.New()
+	repo.jobs = append(repo.jobs, *job)
+	return args.Error(0)
+}
+
+func (repo *jobRepositoryMock) ClaimJobs(worker string, now time.Time, lease time.Duration, limit int) (jobs []entity.Job, err error) {
+	for i := range repo.jobs {
+		job := &repo.jobs[i]
+		if (job.Status == entity.JOB_STATUS_PENDING && !job.RunAt.After(now)) || job.IsLeaseExpired(now) {
+			lockedUntil := now.Add(lease)
+			job.Attempts++
+			job.Status = entity.JOB_STATUS_RUNNING
+			job.LockedBy = worker
+			job.LockedUntil = &lockedUntil
+			jobs = append(jobs, *job)
+		}
+	}
+	return jobs, nil
+}
+
+func (repo *jobRepositoryMock) UpdateJob(worker string, job *entity.Job) (updated bool, err error) {
+	args := repo.Called(job.ID)
+	for i := range repo.jobs {
+		if repo.jobs[i].ID == job.ID && repo.jobs[i].LockedBy == worker {
+			repo.jobs[i] = *job
+		}
+	}
+	return args.Bool(0), args.Error(1)
+}
+
+func (repo *jobRepositoryMock) FindJobByID(id uuid.UUID) (job *entity.Job, err error) {
+	args := repo.Called(id)
+	return args.Get(0).(*entity.Job), args.Error(1)
+}
+
+func (repo *jobRepositoryMock) RetryJob(id uuid.UUID, now time.Time) (retried bool, err error) {
+	args := repo.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (repo *jobRepositoryMock) FindJobs(status string, kind string, offset int, limit int) (jobs []entity.Job, total int64, err error) {
+	args := repo.Called(status, kind, offset, limit)
+	return args.Get(0).([]entity.Job), args.Get(1).(int64), args.Error(2)
+}
+
+func (repo *jobRepositoryMock) DeleteFinishedJobs(before time.Time) (deleted int64, err error) {
+	args := repo.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type organizationRepositoryMock struct {
+	mock.Mock
+}
+
+func (repo *organizationRepositoryMock) CreateOrganization(organization *entity.Organization, owner *entity.OrganizationMember) (err error) {
+	args := repo.Called(organization.Slug, owner.UserID, owner.Role)
+	return args.Error(0)
+}
+
+func (repo *organizationRepositoryMock) FindOrganizationByID(id uuid.UUID) (organization *entity.Organization, err error) {
+	args := repo.Called(id)
+	return args.Get(0).(*entity.Organization), args.Error(1)
+}
+
+func (repo *organizationRepositoryMock) FindOrganizationBySlug(slug string) (organization *entity.Organization, err error) {
+	args := repo.Called(slug)
+	return args.Get(0).(*entity.Organization), args.Error(1)
+}
+
+func (repo *organizationRepositoryMock) FindOrganizationsByIDs(ids []uuid.UUID) (organizations []entity.Organization, err error) {
+	args := repo.Called(ids)
+	return args.Get(0).([]entity.Organization), args.Error(1)
+}
+
+func (repo *organizationRepositoryMock) FindOrganizationMember(organizationID uuid.UUID, userID uuid.UUID) (member *entity.OrganizationMember, err error) {
+	args := repo.Called(organizationID, userID)
+	return args.Get(0).(*entity.OrganizationMember), args.Error(1)
+}
+
+func (repo *organizationRepositoryMock) FindOrganizationMembersByUserID(userID uuid.UUID) (members []entity.OrganizationMember, err error) {
+	args := repo.Called(userID)
+	return args.Get(0).([]entity.OrganizationMember), args.Error(1)
+}
+
+func (repo *organizationRepositoryMock) SaveOrganizationMember(member *entity.OrganizationMember) (err error) {
+	args := repo.Called(member.OrganizationID, member.UserID, member.Role)
+	return args.Error(0)
+}
+
+func (repo *organizationRepositoryMock) RemoveOrganizationMember(organizationID uuid.UUID, userID uuid.UUID) (deleted int64, err error) {
+	args := repo.Called(organizationID, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type providerRepositoryMock struct {
+	mock.Mock
+}
+
+func (repo *providerRepositoryMock) WithTx(tx *gorm.DB) repository.IProviderRepository {
+	return repo
+}
+
+func (repo *providerRepositoryMock) WithContext(ctx context.Context) repository.IProviderRepository {
+	return repo
+}
+
+func (repo *providerRepositoryMock) CreateProvider(provider *entity.Provider) (err error) {
+	args := repo.Called(provider.Name)
+	return args.Error(0)
+}
+
+func (repo *providerRepositoryMock) FindProviderByID(id uuid.UUID) (provider *entity.Provider, err error) {
+	args := repo.Called(id)
+	return args.Get(0).(*entity.Provider), args.Error(1)
+}
+
+func (repo *providerRepositoryMock) FindProviders() (providers []entity.Provider, err error) {
+	args := repo.Called()
+	return args.Get(0).([]entity.Provider), args.Error(1)
+}
+
+func (repo *providerRepositoryMock) LockProvidersByIDs(ids []uuid.UUID) (providers []entity.Provider, err error) {
+	args := repo.Called(ids)
+	return args.Get(0).([]entity.Provider), args.Error(1)
+}
+
+func (repo *providerRepositoryMock) FindProviderHours(providerID uuid.UUID) (hours []entity.ProviderHours, err error) {
+	args := repo.Called(providerID)
+	return args.Get(0).([]entity.ProviderHours), args.Error(1)
+}
+
+func (repo *providerRepositoryMock) ReplaceProviderHours(provider *entity.Provider, hours []entity.ProviderHours) (err error) {
+	args := repo.Called(provider.ID, hours)
+	return args.Error(0)
+}
+
+func (repo *providerRepositoryMock) FindProviderTimeOff(providerID uuid.UUID, startAt time.Time, endAt time.Time) (timeOff []entity.ProviderTimeOff, err error) {
+	args := repo.Called(providerID)
+	return args.Get(0).([]entity.ProviderTimeOff), args.Error(1)
+}
+
+func (repo *providerRepositoryMock) CreateProviderTimeOff(timeOff *entity.ProviderTimeOff) (err error) {
+	args := repo.Called(timeOff.ProviderID)
+	return args.Error(0)
+}
+
+func (repo *providerRepositoryMock) DeleteProviderTimeOff(providerID uuid.UUID, id uuid.UUID) (deleted int64, err error) {
+	args := repo.Called(providerID, id)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (repo *providerRepositoryMock) CreateService(service *entity.Service) (err error) {
+	args := repo.Called(service.ResourceID)
+	return args.Error(0)
+}
+
+func (repo *providerRepositoryMock) FindServiceByID(id uuid.UUID) (service *entity.Service, err error) {
+	args := repo.Called(id)
+	return args.Get(0).(*entity.Service), args.Error(1)
+}
+
+func (repo *providerRepositoryMock) FindServices() (services []entity.Service, err error) {
+	args := repo.Called()
+	return args.Get(0).([]entity.Service), args.Error(1)
+}
+
+func (repo *providerRepositoryMock) FindProviderServicesByServiceID(serviceID uuid.UUID) (providerServices []entity.ProviderService, err error) {
+	args := repo.Called(serviceID)
+	return args.Get(0).([]entity.ProviderService), args.Error(1)
+}
+
+func (repo *providerRepositoryMock) SaveProviderService(providerService *entity.ProviderService) (err error) {
+	args := repo.Called(providerService.ProviderID, providerService.ServiceID)
+	return args.Error(0)
+}
+
+func (repo *providerRepositoryMock) RemoveProviderService(providerID uuid.UUID, serviceID uuid.UUID) (deleted int64, err error) {
+	args := repo.Called(providerID, serviceID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type reviewRepositoryMock struct {
+	mock.Mock
+	reviews []entity.Review
+}
+
+// newReviewRepositoryMock returns a review table that records the reviews
+// created and updated.
+func newReviewRepositoryMock() *reviewRepositoryMock {
+	repo := &reviewRepositoryMock{}
+	repo.On("CreateReview", mock.Anything).Return(nil)
+	repo.On("UpdateReview", mock.Anything).Return(nil)
+	return repo
+}
+
+func (repo *reviewRepositoryMock) WithContext(ctx context.Context) repository.IReviewRepository {
+	return repo
+}
+
+func (repo *reviewRepositoryMock) FindReviewByID(id uuid.UUID) (review *entity.Review, err error) {
+	args := repo.Called(id)
+	return args.Get(0).(*entity.Review), args.Error(1)
+}
+
+func (repo *reviewRepositoryMock) FindReviewByBookingID(bookingID uuid.UUID) (review *entity.Review, err error) {
+	args := repo.Called(bookingID)
+	return args.Get(0).(*entity.Review), args.Error(1)
+}
+
+func (repo *reviewRepositoryMock) FindResourceReviews(resourceID uuid.UUID, status string, offset int, limit int) (reviews []entity.Review, total int64, err error) {
+	args := repo.Called(resourceID, status, offset, limit)
+	return args.Get(0).([]entity.Review), args.Get(1).(int64), args.Error(2)
+}
+
+func (repo *reviewRepositoryMock) CreateReview(review *entity.Review) (err error) {
+	args := repo.Called(review.BookingID)
+	review.ID = uuid.New()
+	repo.reviews = append(repo.reviews, *review)
+	return args.Error(0)
+}
+
+func (repo *reviewRepositoryMock) UpdateReview(review *entity.Review) (err error) {
+	args := repo.Called(review.ID)
+	repo.reviews = append(repo.reviews, *review)
+	return args.Error(0)
+}
+
+type mediaRepositoryMock struct {
+	mock.Mock
+	files []entity.MediaFile
+}
+
+// newMediaRepositoryMock returns a gallery holding the files, in which files
+// are created, reordered and deleted.
+func newMediaRepositoryMock(files ...entity.MediaFile) *mediaRepositoryMock {
+	repo := &mediaRepositoryMock{files: files}
+	repo.On("CreateMediaFile", mock.Anything).Return(nil)
+	repo.On("ReorderMediaFiles", mock.Anything, mock.Anything).Return(nil)
+	repo.On("DeleteMediaFile", mock.Anything).Return(nil)
+	return repo
+}
+
+func (repo *mediaRepositoryMock) WithContext(ctx context.Context) repository.IMediaRepository {
+	return repo
+}
+
+func (repo *mediaRepositoryMock) FindMediaFileByID(id uuid.UUID) (file *entity.MediaFile, err error) {
+	for i := range repo.files {
+		if repo.files[i].ID == id {
+			file := repo.files[i]
+			return &file, nil
+		}
+	}
+	return &entity.MediaFile{}, gorm.ErrRecordNotFound
+}
+
+func (repo *mediaRepositoryMock) FindMediaFilesByResourceID(resourceID uuid.UUID) (files []entity.MediaFile, err error) {
+	for _, file := range repo.files {
+		if file.ResourceID == resourceID {
+			files = append(files, file)
+		}
+	}
+	sort.SliceStable(files, func(i int, j int) bool { return files[i].Position < files[j].Position })
+	return files, nil
+}
+
+func (repo *mediaRepositoryMock) CreateMediaFile(file *entity.MediaFile) (err error) {
+	args := repo.Called(file.ResourceID)
+	if args.Error(0) != nil {
+		return args.Error(0)
+	}
+	files, _ := repo.FindMediaFilesByResourceID(file.ResourceID)
+	file.Position = len(files)
+	file.CreatedAt = time.Now()
+	repo.files = append(repo.files, *file)
+	return nil
+}
+
+func (repo *mediaRepositoryMock) ReorderMediaFiles(resourceID uuid.UUID, ids []uuid.UUID) (err error) {
+	args := repo.Called(resourceID, ids)
+	for position, id := range ids {
+		for i := range repo.files {
+			if repo.files[i].ID == id && repo.files[i].ResourceID == resourceID {
+				repo.files[i].Position = position
+			}
+		}
+	}
+	return args.Error(0)
+}
+
+func (repo *mediaRepositoryMock) DeleteMediaFile(file *entity.MediaFile) (err error) {
+	args := repo.Called(file.ID)
+	repo.files = slices.DeleteFunc(repo.files, func(other entity.MediaFile) bool { return other.ID == file.ID })
+	return args.Error(0)
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"booking/internal/constant"
+	"booking/internal/entity"
+	"booking/internal/repository"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+var errNoShowFeeCharged = errors.New(constant.ERROR_MESSAGE_NO_SHOW_FEE_CHARGED)
+
+// noShowPenalty is what new bookings of a user cost for the no-shows of the
+// user in the rolling window. Each penalty applies from its own number of
+// no-shows, 0 never applying it.
+type noShowPenalty struct {
+	NoShows int
+	// Fee is what the next booking is charged for the no-shows whose fee
+	// wasn't charged yet, each being charged once.
+	Fee            int64
+	DepositPercent int
+	// BannedUntil is set while the user may not book, for ban_days after the
+	// latest no-show.
+	BannedUntil *time.Time
+	// feeNoShows are the no-shows the fee is charged for.
+	feeNoShows []uuid.UUID
+}
+
+// findNoShowPenalty returns the penalty of the user for new bookings made now.
+// No-shows aren't counted when the window is 0.
+func findNoShowPenalty(bookingRepository repository.IBookingRepository, userID uuid.UUID, now time.Time) (penalty noShowPenalty, err error) {
+	windowDays := viper.GetInt("booking.no_show.window_days")
+	if windowDays <= 0 {
+		return penalty, nil
+	}
+	noShows, err := bookingRepository.FindNoShows(userID, now.AddDate(0, 0, -windowDays))
+	if err != nil {
+		return penalty, err
+	}
+
+	penalty.NoShows = len(noShows)
+	reached := func(key string) bool {
+		after := viper.GetInt(key)
+		return after > 0 && penalty.NoShows >= after
+	}
+	if reached("booking.no_show.fee_after") {
+		// the no-shows are the latest first, the ones from the fee_after one
+		// on are charged a fee
+		for _, noShow := range noShows[:penalty.NoShows-viper.GetInt("booking.no_show.fee_after")+1] {
+			if noShow.NoShowFeeBookingID == nil {
+				penalty.feeNoShows = append(penalty.feeNoShows, noShow.ID)
+			}
+		}
+		penalty.Fee = viper.GetInt64("booking.no_show.fee") * int64(len(penalty.feeNoShows))
+	}
+	if reached("booking.no_show.deposit_after") {
+		penalty.DepositPercent = min(viper.GetInt("booking.no_show.deposit_percent"), 100)
+	}
+	if reached("booking.no_show.ban_after") {
+		bannedUntil := noShows[0].StartAt.AddDate(0, 0, viper.GetInt("booking.no_show.ban_days"))
+		if now.Before(bannedUntil) {
+			penalty.BannedUntil = &bannedUntil
+		}
+	}
+	return penalty, nil
+}
+
+// chargeFee adds the no-show fee to the quote of the new booking.
+func (penalty noShowPenalty) chargeFee(booking *entity.Booking) {
+	if penalty.Fee > 0 {
+		booking.Quote.AddNoShowFee(penalty.Fee)
+		booking.Amount = booking.Quote.Total
+	}
+}
+
+// chargeNoShows records that the no-shows were charged their fee on the new
+// booking, once it is created. The no-shows are found before the booking
+// transaction, so it fails when another booking made meanwhile charged them.
+func (penalty noShowPenalty) chargeNoShows(bookingRepository repository.IBookingRepository, booking *entity.Booking) error {
+	if penalty.Fee <= 0 {
+		return nil
+	}
+	charged, err := bookingRepository.ChargeNoShowFees(penalty.feeNoShows, booking.ID)
+	if err != nil {
+		return err
+	}
+	if charged != int64(len(penalty.feeNoShows)) {
+		return errNoShowFeeCharged
+	}
+	return nil
+}
+
+// requireDeposit raises the deposit of the new booking, whose payments have
+// been scheduled, to the deposit percent of its amount. A booking with a
+// deposit to pay is pending until it is paid.
+func (penalty noShowPenalty) requireDeposit(booking *entity.Booking) {
+	if penalty.DepositPercent <= 0 || booking.Amount <= 0 {
+		return
+	}
+	booking.PaymentSchedule = booking.PaymentSchedule.WithDeposit(booking.Amount * int64(penalty.DepositPercent) / 100)
+	booking.NextDueAt = booking.PaymentSchedule.NextDueAt(booking.PaidAmount)
+	if booking.DepositAmount() > 0 {
+		booking.Status = entity.BOOKING_STATUS_PENDING
+	}
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"booking/internal/entity"
+	"booking/internal/notification"
+	"booking/internal/repository"
+	"time"
+)
+
+// notifyBooking queues the message of the event about the booking on each
+// channel the user has turned on. It is called in the transaction changing
+// the booking, so the message is sent if and only if the change is saved.
+func notifyBooking(notificationRepository repository.INotificationRepository, resource *entity.Resource, booking *entity.Booking, event string, now time.Time) error {
+	user, preference, err := notificationRepository.FindRecipient(booking.UserID)
+	if err != nil {
+		return err
+	}
+	location := resource.Location()
+	subject, body, err := notification.RenderBooking(event, preference.Language, notification.BookingData{
+		BookingID:    booking.ID.String(),
+		ResourceName: resource.Name,
+		StartAt:      booking.StartAt.In(location),
+		EndAt:        booking.EndAt.In(location),
+		RefundAmount: booking.RefundAmount,
+	})
+	if err != nil {
+		return err
+	}
+
+	notifications := []entity.Notification{}
+	for _, recipient := range recipients(user, preference) {
+		notifications = append(notifications, entity.Notification{
+			UserID:        booking.UserID,
+			BookingID:     &booking.ID,
+			Event:         event,
+			Channel:       recipient.channel,
+			Recipient:     recipient.address,
+			Subject:       subject,
+			Body:          body,
+			Status:        entity.NOTIFICATION_STATUS_PENDING,
+			NextAttemptAt: now,
+		})
+	}
+	return notificationRepository.CreateNotifications(notifications)
+}
+
+type recipient struct {
+	channel string
+	address string
+}
+
+// recipients returns where the user wants to be notified, their username
+// being their email address.
+func recipients(user *entity.User, preference *entity.NotificationPreference) []recipient {
+	addresses := []recipient{}
+	if preference.Email {
+		addresses = append(addresses, recipient{channel: notification.CHANNEL_EMAIL, address: user.Username})
+	}
+	if preference.SMS && preference.Phone != "" {
+		addresses = append(addresses, recipient{channel: notification.CHANNEL_SMS, address: preference.Phone})
+	}
+	if preference.Line && preference.LineUserID != "" {
+		addresses = append(addresses, recipient{channel: notification.CHANNEL_LINE, address: preference.LineUserID})
+	}
+	return addresses
+}
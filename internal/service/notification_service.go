@@ -0,0 +1,189 @@
+package service
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/logs"
+	"booking/internal/notification"
+	"booking/internal/repository"
+	"booking/internal/vo"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// DISPATCH_BATCH_SIZE is how many notifications are sent per run.
+	DISPATCH_BATCH_SIZE = 100
+	// DISPATCH_LEASE is how long a claimed notification is left to the
+	// dispatcher sending it before another may try again.
+	DISPATCH_LEASE = 5 * time.Minute
+	// REMINDER_BATCH_SIZE is how many bookings are reminded of per run.
+	REMINDER_BATCH_SIZE = 100
+)
+
+type INotificationService interface {
+	GetNotificationPreference(username string) (res vo.Response, statusCode int)
+	SaveNotificationPreference(username string, notificationPreferenceDTO *dto.NotificationPreferenceDTO) (res vo.Response, statusCode int)
+	DispatchNotifications(now time.Time) (err error)
+	SendReminders(now time.Time) (err error)
+}
+
+type notificationService struct {
+	authRepository         repository.IAuthRepository
+	resourceRepository     repository.IResourceRepository
+	bookingRepository      repository.IBookingRepository
+	notificationRepository repository.INotificationRepository
+	senders                map[string]notification.ISender
+}
+
+func NewNotificationService(authRepository repository.IAuthRepository, resourceRepository repository.IResourceRepository, bookingRepository repository.IBookingRepository, notificationRepository repository.INotificationRepository, senders ...notification.ISender) *notificationService {
+	senderByChannel := map[string]notification.ISender{}
+	for _, sender := range senders {
+		senderByChannel[sender.Channel()] = sender
+	}
+	return &notificationService{authRepository: authRepository, resourceRepository: resourceRepository, bookingRepository: bookingRepository, notificationRepository: notificationRepository, senders: senderByChannel}
+}
+
+func (notificationService *notificationService) GetNotificationPreference(username string) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(notificationService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	_, preference, err := notificationService.notificationRepository.FindRecipient(user.ID)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(vo.NewNotificationPreferenceResponse(preference))
+	return res, fiber.StatusOK
+}
+
+func (notificationService *notificationService) SaveNotificationPreference(username string, notificationPreferenceDTO *dto.NotificationPreferenceDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(notificationService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	preference := &entity.NotificationPreference{
+		UserID:     user.ID,
+		Email:      notificationPreferenceDTO.Email,
+		SMS:        notificationPreferenceDTO.SMS,
+		Phone:      notificationPreferenceDTO.Phone,
+		Line:       notificationPreferenceDTO.Line,
+		LineUserID: notificationPreferenceDTO.LineUserID,
+		Language:   notificationPreferenceDTO.Language,
+	}
+	if preference.Language == "" {
+		preference.Language = notification.LANGUAGE_EN
+	}
+	err := notificationService.notificationRepository.SavePreference(preference)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(vo.NewNotificationPreferenceResponse(preference))
+	return res, fiber.StatusOK
+}
+
+// DispatchNotifications sends the notifications due by now. Failed ones are
+// retried with exponential backoff until they fail permanently or run out of
+// attempts, when they are kept as dead letters.
+func (notificationService *notificationService) DispatchNotifications(now time.Time) (err error) {
+	notifications, err := notificationService.notificationRepository.ClaimNotifications(now, DISPATCH_LEASE, DISPATCH_BATCH_SIZE)
+	if err != nil {
+		return err
+	}
+
+	maxAttempts := viper.GetInt("notification.max_attempts")
+	for i := range notifications {
+		outbox := &notifications[i]
+		err = notificationService.send(outbox)
+		outbox.Attempts++
+		switch {
+		case err == nil:
+			sentAt := time.Now()
+			outbox.Status = entity.NOTIFICATION_STATUS_SENT
+			outbox.SentAt = &sentAt
+			outbox.LastError = ""
+		case errors.Is(err, notification.ErrPermanent) || outbox.Attempts >= maxAttempts:
+			outbox.Status = entity.NOTIFICATION_STATUS_DEAD
+			outbox.LastError = err.Error()
+			logs.Error(err, zap.String("notification", outbox.ID.String()), zap.String("channel", outbox.Channel), zap.Int("attempts", outbox.Attempts))
+		default:
+			outbox.NextAttemptAt = now.Add(notification.Backoff(outbox.Attempts))
+			outbox.LastError = err.Error()
+		}
+		err = notificationService.notificationRepository.UpdateNotification(outbox)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (notificationService *notificationService) send(outbox *entity.Notification) error {
+	sender, ok := notificationService.senders[outbox.Channel]
+	if !ok {
+		return notification.Permanent(errors.New("no sender for channel " + outbox.Channel))
+	}
+	return sender.Send(notification.Message{
+		ID:      outbox.ID.String(),
+		To:      outbox.Recipient,
+		Subject: outbox.Subject,
+		Body:    outbox.Body,
+	})
+}
+
+// SendReminders queues reminders of the confirmed bookings starting within
+// the configured number of hours. Each booking is reminded of once.
+func (notificationService *notificationService) SendReminders(now time.Time) (err error) {
+	before := now.Add(viper.GetDuration("notification.reminder_hours") * time.Hour)
+	bookings, err := notificationService.notificationRepository.FindBookingsToRemind(now, before, REMINDER_BATCH_SIZE)
+	if err != nil {
+		return err
+	}
+
+	for _, upcoming := range bookings {
+		resource, err := notificationService.resourceRepository.FindResourceByID(upcoming.ResourceID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			resource = nil
+		} else if err != nil {
+			return err
+		}
+		err = notificationService.bookingRepository.Transaction(func(tx *gorm.DB) error {
+			bookingRepository := notificationService.bookingRepository.WithTx(tx)
+			booking, err := bookingRepository.LockBookingByID(upcoming.ID)
+			if err != nil {
+				return err
+			}
+			// the booking may have been cancelled or reminded of since
+			if booking.Status != entity.BOOKING_STATUS_CONFIRMED || booking.RemindedAt != nil {
+				return nil
+			}
+			booking.RemindedAt = &now
+			err = bookingRepository.UpdateBooking(booking)
+			// there is nothing to remind of for a deleted resource
+			if err != nil || resource == nil {
+				return err
+			}
+			return notifyBooking(notificationService.notificationRepository.WithTx(tx), resource, booking, notification.EVENT_BOOKING_REMINDER, now)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package service_test
+
+import (
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/notification"
+	"booking/internal/service"
+	"booking/internal/vo"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSaveNotificationPreference(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+
+	t.Run("When the user turns on LINE, should save the preference in English by default", func(t *testing.T) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		notificationRepositoryMock := newNotificationRepositoryMock()
+		notificationRepositoryMock.On("SavePreference", mock.Anything).Return(nil)
+		notificationService := service.NewNotificationService(authRepositoryMock, &resourceRepositoryMock{}, &bookingRepositoryMock{}, notificationRepositoryMock)
+
+		res, statusCode := notificationService.SaveNotificationPreference(Username, &dto.NotificationPreferenceDTO{Email: true, Line: true, LineUserID: "U4af4980629"})
+
+		assert.Equal(t, fiber.StatusOK, statusCode)
+		assert.Equal(t, vo.NotificationPreferenceResponse{Email: true, Line: true, LineUserID: "U4af4980629", Language: notification.LANGUAGE_EN}, res.Data)
+		notificationRepositoryMock.AssertCalled(t, "SavePreference", &entity.NotificationPreference{UserID: user.ID, Email: true, Line: true, LineUserID: "U4af4980629", Language: notification.LANGUAGE_EN})
+	})
+}
+
+func TestDispatchNotifications(t *testing.T) {
+	viper.Set("notification.max_attempts", 3)
+	now := time.Now()
+	newNotification := func(channel string, attempts int) entity.Notification {
+		return entity.Notification{ID: uuid.New(), Channel: channel, Recipient: "example@gmail.com", Subject: "Booking confirmed", Body: "Your booking is confirmed.", Status: entity.NOTIFICATION_STATUS_PENDING, Attempts: attempts, NextAttemptAt: now}
+	}
+	newNotificationService := func(notifications []entity.Notification, senders ...notification.ISender) (service.INotificationService, *notificationRepositoryMock) {
+		notificationRepositoryMock := newNotificationRepositoryMock()
+		notificationRepositoryMock.On("ClaimNotifications", now).Return(notifications, nil)
+		notificationRepositoryMock.On("UpdateNotification", mock.Anything).Return(nil)
+		return service.NewNotificationService(&authRepositoryMock{}, &resourceRepositoryMock{}, &bookingRepositoryMock{}, notificationRepositoryMock, senders...), notificationRepositoryMock
+	}
+
+	t.Run("When the message is delivered, should mark the notification sent", func(t *testing.T) {
+		sender := notification.NewFakeSender(notification.CHANNEL_EMAIL)
+		notifications := []entity.Notification{newNotification(notification.CHANNEL_EMAIL, 0)}
+		notificationService, _ := newNotificationService(notifications, sender)
+
+		err := notificationService.DispatchNotifications(now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.NOTIFICATION_STATUS_SENT, notifications[0].Status)
+		assert.NotNil(t, notifications[0].SentAt)
+		assert.Equal(t, 1, notifications[0].Attempts)
+		assert.Equal(t, []notification.Message{{ID: notifications[0].ID.String(), To: "example@gmail.com", Subject: "Booking confirmed", Body: "Your booking is confirmed."}}, sender.Sent())
+	})
+
+	t.Run("When the delivery fails, should retry the notification after a backoff", func(t *testing.T) {
+		sender := notification.NewFakeSender(notification.CHANNEL_SMS)
+		sender.Fail(errors.New("gateway timeout"))
+		notifications := []entity.Notification{newNotification(notification.CHANNEL_SMS, 1)}
+		notificationService, _ := newNotificationService(notifications, sender)
+
+		err := notificationService.DispatchNotifications(now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.NOTIFICATION_STATUS_PENDING, notifications[0].Status)
+		assert.Equal(t, 2, notifications[0].Attempts)
+		assert.Equal(t, now.Add(notification.Backoff(2)), notifications[0].NextAttemptAt)
+		assert.Equal(t, "gateway timeout", notifications[0].LastError)
+	})
+
+	t.Run("When the delivery fails on the last attempt, should dead-letter the notification", func(t *testing.T) {
+		sender := notification.NewFakeSender(notification.CHANNEL_SMS)
+		sender.Fail(errors.New("gateway timeout"))
+		notifications := []entity.Notification{newNotification(notification.CHANNEL_SMS, 2)}
+		notificationService, _ := newNotificationService(notifications, sender)
+
+		err := notificationService.DispatchNotifications(now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.NOTIFICATION_STATUS_DEAD, notifications[0].Status)
+		assert.Equal(t, 3, notifications[0].Attempts)
+	})
+
+	t.Run("When the delivery fails permanently, should dead-letter the notification without retrying", func(t *testing.T) {
+		sender := notification.NewFakeSender(notification.CHANNEL_LINE)
+		sender.Fail(notification.Permanent(errors.New("not a friend of the account")))
+		notifications := []entity.Notification{newNotification(notification.CHANNEL_LINE, 0)}
+		notificationService, _ := newNotificationService(notifications, sender)
+
+		err := notificationService.DispatchNotifications(now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.NOTIFICATION_STATUS_DEAD, notifications[0].Status)
+		assert.Equal(t, 1, notifications[0].Attempts)
+		assert.Equal(t, "not a friend of the account", notifications[0].LastError)
+	})
+
+	t.Run("When the channel has no sender, should dead-letter the notification", func(t *testing.T) {
+		notifications := []entity.Notification{newNotification(notification.CHANNEL_LINE, 0)}
+		notificationService, _ := newNotificationService(notifications, notification.NewFakeSender(notification.CHANNEL_EMAIL))
+
+		err := notificationService.DispatchNotifications(now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entity.NOTIFICATION_STATUS_DEAD, notifications[0].Status)
+	})
+}
+
+func TestSendReminders(t *testing.T) {
+	viper.Set("notification.reminder_hours", 24)
+	now := time.Now()
+	resource := &entity.Resource{ID: uuid.New(), Name: "Meeting Room", TimeZone: "Asia/Bangkok"}
+	newBooking := func() *entity.Booking {
+		startAt := now.Add(20 * time.Hour)
+		return &entity.Booking{ID: uuid.New(), UserID: uuid.New(), ResourceID: resource.ID, StartAt: startAt, EndAt: startAt.Add(time.Hour), Status: entity.BOOKING_STATUS_CONFIRMED}
+	}
+
+	t.Run("When a confirmed booking starts within the reminder hours, should queue its reminder once", func(t *testing.T) {
+		booking := newBooking()
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindResourceByID", resource.ID).Return(resource, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("LockBookingByID", booking.ID).Return(booking, nil)
+		bookingRepositoryMock.On("UpdateBooking", booking.ID).Return(nil)
+		notificationRepositoryMock := newNotificationRepositoryMock()
+		notificationRepositoryMock.preference = &entity.NotificationPreference{UserID: booking.UserID, Email: true, SMS: true, Phone: "+66812345678", Language: notification.LANGUAGE_TH}
+		notificationRepositoryMock.On("FindBookingsToRemind", now, now.Add(24*time.Hour)).Return([]entity.Booking{*booking}, nil)
+		notificationService := service.NewNotificationService(&authRepositoryMock{}, resourceRepositoryMock, bookingRepositoryMock, notificationRepositoryMock)
+
+		err := notificationService.SendReminders(now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &now, booking.RemindedAt)
+		if assert.Len(t, notificationRepositoryMock.notifications, 2) {
+			assert.Equal(t, notification.CHANNEL_EMAIL, notificationRepositoryMock.notifications[0].Channel)
+			assert.Equal(t, notification.CHANNEL_SMS, notificationRepositoryMock.notifications[1].Channel)
+			assert.Equal(t, "+66812345678", notificationRepositoryMock.notifications[1].Recipient)
+			assert.Equal(t, notification.EVENT_BOOKING_REMINDER, notificationRepositoryMock.notifications[1].Event)
+			assert.Contains(t, notificationRepositoryMock.notifications[1].Body, "แจ้งเตือนการจอง Meeting Room")
+		}
+	})
+
+	t.Run("When the booking was cancelled since it was found, should not remind of it", func(t *testing.T) {
+		booking := newBooking()
+		cancelled := *booking
+		cancelled.Status = entity.BOOKING_STATUS_CANCELLED
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindResourceByID", resource.ID).Return(resource, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("LockBookingByID", booking.ID).Return(&cancelled, nil)
+		notificationRepositoryMock := newNotificationRepositoryMock()
+		notificationRepositoryMock.On("FindBookingsToRemind", now, now.Add(24*time.Hour)).Return([]entity.Booking{*booking}, nil)
+		notificationService := service.NewNotificationService(&authRepositoryMock{}, resourceRepositoryMock, bookingRepositoryMock, notificationRepositoryMock)
+
+		err := notificationService.SendReminders(now)
+
+		assert.NoError(t, err)
+		assert.Empty(t, notificationRepositoryMock.notifications)
+		bookingRepositoryMock.AssertNotCalled(t, "UpdateBooking", booking.ID)
+	})
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"booking/internal/cache"
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/logs"
+	"booking/internal/repository"
+	"booking/internal/vo"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type IOrganizationService interface {
+	CreateOrganization(username string, createOrganizationDTO *dto.CreateOrganizationDTO) (res vo.Response, statusCode int)
+	GetOrganizations(username string) (res vo.Response, statusCode int)
+	SaveOrganizationMember(username string, organizationID uuid.UUID, saveOrganizationMemberDTO *dto.SaveOrganizationMemberDTO) (res vo.Response, statusCode int)
+	RemoveOrganizationMember(username string, organizationID uuid.UUID, userID uuid.UUID) (res vo.Response, statusCode int)
+}
+
+type organizationService struct {
+	cache                  cache.ICache
+	authRepository         repository.IAuthRepository
+	organizationRepository repository.IOrganizationRepository
+}
+
+func NewOrganizationService(cache cache.ICache, authRepository repository.IAuthRepository, organizationRepository repository.IOrganizationRepository) *organizationService {
+	return &organizationService{cache: cache, authRepository: authRepository, organizationRepository: organizationRepository}
+}
+
+// CreateOrganization creates an organization owned by the user.
+func (organizationService *organizationService) CreateOrganization(username string, createOrganizationDTO *dto.CreateOrganizationDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(organizationService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	_, err := organizationService.organizationRepository.FindOrganizationBySlug(createOrganizationDTO.Slug)
+	if err == nil {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_ORGANIZATION_SLUG_ALREADY_EXISTS)
+		return res, fiber.StatusConflict
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	organization := entity.Organization{Name: createOrganizationDTO.Name, Slug: createOrganizationDTO.Slug}
+	owner := entity.OrganizationMember{UserID: user.ID, Role: entity.ORGANIZATION_ROLE_OWNER}
+	err = organizationService.organizationRepository.CreateOrganization(&organization, &owner)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(vo.NewOrganizationResponse(&organization, owner.Role))
+	return res, fiber.StatusCreated
+}
+
+// GetOrganizations returns the organizations the user is a member of.
+func (organizationService *organizationService) GetOrganizations(username string) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(organizationService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	members, err := organizationService.organizationRepository.FindOrganizationMembersByUserID(user.ID)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	responses := []vo.OrganizationResponse{}
+	if len(members) == 0 {
+		res.SetData(responses)
+		return res, fiber.StatusOK
+	}
+	roles := map[uuid.UUID]string{}
+	organizationIDs := []uuid.UUID{}
+	for _, member := range members {
+		roles[member.OrganizationID] = member.Role
+		organizationIDs = append(organizationIDs, member.OrganizationID)
+	}
+	organizations, err := organizationService.organizationRepository.FindOrganizationsByIDs(organizationIDs)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	for i := range organizations {
+		responses = append(responses, vo.NewOrganizationResponse(&organizations[i], roles[organizations[i].ID]))
+	}
+	res.SetData(responses)
+	return res, fiber.StatusOK
+}
+
+// findOrganizationOwner resolves the user like findUser, only letting through
+// the owners of the organization.
+func (organizationService *organizationService) findOrganizationOwner(username string, organizationID uuid.UUID) (user *entity.User, errorMessage string, statusCode int) {
+	user, errorMessage, statusCode = findUser(organizationService.authRepository, username)
+	if user == nil {
+		return nil, errorMessage, statusCode
+	}
+
+	_, err := organizationService.organizationRepository.FindOrganizationByID(organizationID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, constant.ERROR_MESSAGE_ORGANIZATION_NOT_FOUND, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		return nil, constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR, fiber.StatusInternalServerError
+	}
+	member, err := organizationService.organizationRepository.FindOrganizationMember(organizationID, user.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || err == nil && member.Role != entity.ORGANIZATION_ROLE_OWNER {
+		return nil, constant.ERROR_MESSAGE_FORBIDDEN, fiber.StatusForbidden
+	}
+	if err != nil {
+		logs.Error(err)
+		return nil, constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR, fiber.StatusInternalServerError
+	}
+	return user, "", fiber.StatusOK
+}
+
+// SaveOrganizationMember adds the user to the organization with the role, or
+// changes their role. Owners can't change their own role so that an
+// organization isn't left without one.
+func (organizationService *organizationService) SaveOrganizationMember(username string, organizationID uuid.UUID, saveOrganizationMemberDTO *dto.SaveOrganizationMemberDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := organizationService.findOrganizationOwner(username, organizationID)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	memberUser, err := organizationService.authRepository.FindUserByUsername(saveOrganizationMemberDTO.Username)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_USER_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	if memberUser.ID == user.ID {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_CANNOT_CHANGE_OWN_MEMBERSHIP)
+		return res, fiber.StatusConflict
+	}
+
+	member := entity.OrganizationMember{OrganizationID: organizationID, UserID: memberUser.ID, Role: saveOrganizationMemberDTO.Role}
+	err = organizationService.organizationRepository.SaveOrganizationMember(&member)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	organizationService.forgetOrganizationRole(organizationID, memberUser.Username)
+
+	res.SetData(vo.OrganizationMemberResponse{OrganizationID: organizationID, UserID: memberUser.ID, Role: member.Role})
+	return res, fiber.StatusOK
+}
+
+func (organizationService *organizationService) RemoveOrganizationMember(username string, organizationID uuid.UUID, userID uuid.UUID) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := organizationService.findOrganizationOwner(username, organizationID)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+	if userID == user.ID {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_CANNOT_CHANGE_OWN_MEMBERSHIP)
+		return res, fiber.StatusConflict
+	}
+
+	memberUser, err := organizationService.authRepository.FindUserByID(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_ORGANIZATION_MEMBER_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	deleted, err := organizationService.organizationRepository.RemoveOrganizationMember(organizationID, userID)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	if deleted == 0 {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_ORGANIZATION_MEMBER_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	organizationService.forgetOrganizationRole(organizationID, memberUser.Username)
+
+	res.SetData(nil)
+	return res, fiber.StatusOK
+}
+
+// forgetOrganizationRole drops the cached role of the user so that a changed
+// membership applies from their next request.
+func (organizationService *organizationService) forgetOrganizationRole(organizationID uuid.UUID, username string) {
+	err := organizationService.cache.DeleteOrganizationRole(organizationID, username)
+	if err != nil {
+		logs.Error(err)
+	}
+}
@@ -0,0 +1,103 @@
+package service_test
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/service"
+	"booking/internal/vo"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestCreateOrganization(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+
+	t.Run("When the slug is free, should create the organization owned by the user", func(t *testing.T) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		organizationRepositoryMock := &organizationRepositoryMock{}
+		organizationRepositoryMock.On("FindOrganizationBySlug", "court-house").Return(&entity.Organization{}, gorm.ErrRecordNotFound)
+		organizationRepositoryMock.On("CreateOrganization", "court-house", user.ID, entity.ORGANIZATION_ROLE_OWNER).Return(nil)
+		organizationService := service.NewOrganizationService(&cacheMock{}, authRepositoryMock, organizationRepositoryMock)
+
+		res, statusCode := organizationService.CreateOrganization(Username, &dto.CreateOrganizationDTO{Name: "Court House", Slug: "court-house"})
+
+		assert.Equal(t, fiber.StatusCreated, statusCode)
+		assert.Equal(t, entity.ORGANIZATION_ROLE_OWNER, res.Data.(vo.OrganizationResponse).Role)
+		organizationRepositoryMock.AssertExpectations(t)
+	})
+
+	t.Run("When the slug is taken, should respond conflict", func(t *testing.T) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		organizationRepositoryMock := &organizationRepositoryMock{}
+		organizationRepositoryMock.On("FindOrganizationBySlug", "court-house").Return(&entity.Organization{ID: uuid.New()}, nil)
+		organizationService := service.NewOrganizationService(&cacheMock{}, authRepositoryMock, organizationRepositoryMock)
+
+		res, statusCode := organizationService.CreateOrganization(Username, &dto.CreateOrganizationDTO{Name: "Court House", Slug: "court-house"})
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_ORGANIZATION_SLUG_ALREADY_EXISTS, res.ErrorMessage)
+	})
+}
+
+func TestSaveOrganizationMember(t *testing.T) {
+	const Username = "example@gmail.com"
+	const MemberUsername = "member@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+	memberUser := &entity.User{ID: uuid.New(), Username: MemberUsername}
+	organization := &entity.Organization{ID: uuid.New()}
+
+	t.Run("When the owner adds a member, should save the role and forget the cached one", func(t *testing.T) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		authRepositoryMock.On("FindUserByUsername", MemberUsername).Return(memberUser, nil)
+		organizationRepositoryMock := &organizationRepositoryMock{}
+		organizationRepositoryMock.On("FindOrganizationByID", organization.ID).Return(organization, nil)
+		organizationRepositoryMock.On("FindOrganizationMember", organization.ID, user.ID).Return(&entity.OrganizationMember{Role: entity.ORGANIZATION_ROLE_OWNER}, nil)
+		organizationRepositoryMock.On("SaveOrganizationMember", organization.ID, memberUser.ID, entity.ORGANIZATION_ROLE_ADMIN).Return(nil)
+		cacheMock := &cacheMock{}
+		cacheMock.On("DeleteOrganizationRole", organization.ID, MemberUsername).Return(nil)
+		organizationService := service.NewOrganizationService(cacheMock, authRepositoryMock, organizationRepositoryMock)
+
+		_, statusCode := organizationService.SaveOrganizationMember(Username, organization.ID, &dto.SaveOrganizationMemberDTO{Username: MemberUsername, Role: entity.ORGANIZATION_ROLE_ADMIN})
+
+		assert.Equal(t, fiber.StatusOK, statusCode)
+		organizationRepositoryMock.AssertExpectations(t)
+		cacheMock.AssertExpectations(t)
+	})
+
+	t.Run("When an admin adds a member, should respond forbidden", func(t *testing.T) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		organizationRepositoryMock := &organizationRepositoryMock{}
+		organizationRepositoryMock.On("FindOrganizationByID", organization.ID).Return(organization, nil)
+		organizationRepositoryMock.On("FindOrganizationMember", organization.ID, user.ID).Return(&entity.OrganizationMember{Role: entity.ORGANIZATION_ROLE_ADMIN}, nil)
+		organizationService := service.NewOrganizationService(&cacheMock{}, authRepositoryMock, organizationRepositoryMock)
+
+		res, statusCode := organizationService.SaveOrganizationMember(Username, organization.ID, &dto.SaveOrganizationMemberDTO{Username: MemberUsername, Role: entity.ORGANIZATION_ROLE_ADMIN})
+
+		assert.Equal(t, fiber.StatusForbidden, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_FORBIDDEN, res.ErrorMessage)
+	})
+
+	t.Run("When the owner changes their own role, should respond conflict", func(t *testing.T) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		organizationRepositoryMock := &organizationRepositoryMock{}
+		organizationRepositoryMock.On("FindOrganizationByID", organization.ID).Return(organization, nil)
+		organizationRepositoryMock.On("FindOrganizationMember", organization.ID, user.ID).Return(&entity.OrganizationMember{Role: entity.ORGANIZATION_ROLE_OWNER}, nil)
+		organizationService := service.NewOrganizationService(&cacheMock{}, authRepositoryMock, organizationRepositoryMock)
+
+		res, statusCode := organizationService.SaveOrganizationMember(Username, organization.ID, &dto.SaveOrganizationMemberDTO{Username: Username, Role: entity.ORGANIZATION_ROLE_MEMBER})
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_CANNOT_CHANGE_OWN_MEMBERSHIP, res.ErrorMessage)
+	})
+}
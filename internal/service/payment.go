@@ -0,0 +1,182 @@
+package service
+
+import (
+	"booking/internal/constant"
+	"booking/internal/entity"
+	"booking/internal/payment"
+	"booking/internal/repository"
+	"booking/internal/vo"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var errPaymentAmountMismatch = errors.New(constant.ERROR_MESSAGE_PAYMENT_AMOUNT_MISMATCH)
+
+// startPayment creates an intent collecting the amount in satang for the
+// booking, which stays pending until the webhook reports the outcome.
+func startPayment(paymentRepository repository.IPaymentRepository, paymentProvider payment.IPaymentProvider, booking *entity.Booking, amount int64) (*vo.PaymentResponse, error) {
+	intent, err := paymentProvider.CreateIntent(payment.IntentRequest{
+		Reference: booking.ID.String(),
+		Amount:    amount,
+		Currency:  payment.CURRENCY_THB,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	record := entity.Payment{
+		BookingID: booking.ID,
+		UserID:    booking.UserID,
+		Provider:  paymentProvider.Name(),
+		IntentID:  intent.ID,
+		Amount:    intent.Amount,
+		Currency:  intent.Currency,
+		Status:    entity.PAYMENT_STATUS_PENDING,
+		QRPayload: intent.QRPayload,
+	}
+	err = paymentRepository.CreatePayment(&record)
+	if err != nil {
+		return nil, err
+	}
+
+	response := vo.NewPaymentResponse(&record)
+	response.ClientSecret = intent.ClientSecret
+	return &response, nil
+}
+
+// findPaymentSchedule returns the current payment schedule of the resource to
+// split the amount of new bookings with, or nil when bookings of the resource
+// are paid in full when booking.
+func findPaymentSchedule(resourceRepository repository.IResourceRepository, resourceID uuid.UUID) (*entity.PaymentSchedule, error) {
+	schedule, err := resourceRepository.FindPaymentScheduleByResourceID(resourceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return schedule, err
+}
+
+// schedulePayments splits the amount of the new booking into its dues under
+// the schedule. A booking with a deposit to pay is pending until it is paid.
+func schedulePayments(booking *entity.Booking, schedule *entity.PaymentSchedule, now time.Time) {
+	booking.PaymentSchedule = schedule.Dues(booking.Amount, now, booking.StartAt)
+	booking.NextDueAt = booking.PaymentSchedule.NextDueAt(booking.PaidAmount)
+	if schedule != nil {
+		booking.OverdueAction = schedule.OverdueAction
+	}
+	if booking.DepositAmount() > 0 {
+		booking.Status = entity.BOOKING_STATUS_PENDING
+	}
+}
+
+// nextPaymentAmount returns the amount the customer is asked to pay next: what
+// is due by now or else the rest of the balance.
+func nextPaymentAmount(booking *entity.Booking, now time.Time) int64 {
+	amountDue := booking.PaymentSchedule.AmountDue(booking.PaidAmount, now)
+	if amountDue > 0 {
+		return amountDue
+	}
+	return booking.OutstandingAmount()
+}
+
+// refundBooking refunds the refund amount of the cancelled booking.
+func refundBooking(paymentRepository repository.IPaymentRepository, ledgerRepository repository.ILedgerRepository, organizationID uuid.UUID, paymentProvider payment.IPaymentProvider, booking *entity.Booking) error {
+	return refundPayments(paymentRepository, ledgerRepository, organizationID, paymentProvider, booking, booking.RefundAmount)
+}
+
+// refundPayments refunds the refund amount in satang from the captured payments of
+// the booking with the provider, oldest first. Payments of providers refunded
+// by hand are left to the organization.
+func refundPayments(paymentRepository repository.IPaymentRepository, ledgerRepository repository.ILedgerRepository, organizationID uuid.UUID, paymentProvider payment.IPaymentProvider, booking *entity.Booking, refundAmount int64) error {
+	remaining := refundAmount
+	if remaining <= 0 {
+		return nil
+	}
+
+	payments, err := paymentRepository.FindPaymentsByBookingID(booking.ID)
+	if err != nil {
+		return err
+	}
+	for i := range payments {
+		record := &payments[i]
+		amount := min(remaining, record.Amount-record.RefundedAmount)
+		if record.Status != entity.PAYMENT_STATUS_CAPTURED || record.Provider != paymentProvider.Name() || amount <= 0 {
+			continue
+		}
+		err = paymentProvider.Refund(record.IntentID, amount)
+		if err != nil {
+			return err
+		}
+		record.RefundedAmount += amount
+		err = paymentRepository.UpdatePayment(record)
+		if err != nil {
+			return err
+		}
+		err = postRefund(ledgerRepository, organizationID, booking, record, amount)
+		if err != nil {
+			return err
+		}
+		remaining -= amount
+		if remaining == 0 {
+			break
+		}
+	}
+	return nil
+}
+
+// recordPayment adds the captured payment to the amount paid of the booking
+// and to the ledger of the organization.
+// A pending booking is confirmed once its deposit is paid and an overdue flag
+// is lifted once the booking has caught up. The other pending payments of the
+// booking are voided when it is confirmed or paid so they are not captured as
+// well.
+func recordPayment(bookingRepository repository.IBookingRepository, paymentRepository repository.IPaymentRepository, ledgerRepository repository.ILedgerRepository, organizationID uuid.UUID, booking *entity.Booking, paidBy *entity.Payment, now time.Time) error {
+	var owed int64
+	if booking.IsActive() {
+		owed = booking.OutstandingAmount()
+	}
+	err := postPayment(ledgerRepository, organizationID, booking, paidBy, owed)
+	if err != nil {
+		return err
+	}
+
+	booking.PaidAmount += paidBy.Amount
+	booking.NextDueAt = booking.PaymentSchedule.NextDueAt(booking.PaidAmount)
+	if booking.PaymentSchedule.AmountDue(booking.PaidAmount, now) == 0 {
+		booking.OverdueAt = nil
+	}
+	confirmed := booking.Status == entity.BOOKING_STATUS_PENDING && booking.PaidAmount >= booking.DepositAmount()
+	if confirmed {
+		booking.Status = entity.BOOKING_STATUS_CONFIRMED
+		booking.Sequence++
+	}
+	err = bookingRepository.UpdateBooking(booking)
+	if err != nil || !(confirmed || booking.OutstandingAmount() == 0) {
+		return err
+	}
+
+	return voidPendingPayments(paymentRepository, booking, paidBy.ID)
+}
+
+// voidPendingPayments voids the payments of the booking not made yet other
+// than the one paying it, as the booking no longer needs them.
+func voidPendingPayments(paymentRepository repository.IPaymentRepository, booking *entity.Booking, paidByID uuid.UUID) error {
+	payments, err := paymentRepository.FindPaymentsByBookingID(booking.ID)
+	if err != nil {
+		return err
+	}
+	for i := range payments {
+		record := &payments[i]
+		if record.ID == paidByID || record.Status != entity.PAYMENT_STATUS_PENDING {
+			continue
+		}
+		record.Status = entity.PAYMENT_STATUS_VOIDED
+		err = paymentRepository.UpdatePayment(record)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
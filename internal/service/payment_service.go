@@ -0,0 +1,393 @@
+package service
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/logs"
+	"booking/internal/notification"
+	"booking/internal/payment"
+	"booking/internal/promptpay"
+	"booking/internal/repository"
+	"booking/internal/vo"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type IPaymentService interface {
+	WithContext(ctx context.Context) IPaymentService
+	HandleWebhook(payload []byte, signature string) (res vo.Response, statusCode int)
+	SimulatePayment(username string, intentID string, simulatePaymentDTO *dto.SimulatePaymentDTO) (res vo.Response, statusCode int)
+	GetBookingPayments(username string, bookingID uuid.UUID) (res vo.Response, statusCode int)
+	CreatePayment(username string, bookingID uuid.UUID, createPaymentDTO *dto.CreatePaymentDTO) (res vo.Response, statusCode int)
+	CreatePromptPayPayment(username string, bookingID uuid.UUID) (res vo.Response, statusCode int)
+	GetPaymentQRCode(username string, paymentID uuid.UUID) (res vo.Response, statusCode int)
+	ReconcilePromptPay(payload []byte, signature string) (res vo.Response, statusCode int)
+}
+
+type paymentService struct {
+	authRepository         repository.IAuthRepository
+	bookingRepository      repository.IBookingRepository
+	waitlistRepository     repository.IWaitlistRepository
+	scheduleRepository     repository.IScheduleRepository
+	paymentRepository      repository.IPaymentRepository
+	ledgerRepository       repository.ILedgerRepository
+	notificationRepository repository.INotificationRepository
+	paymentProvider        payment.IPaymentProvider
+	promptPayProvider      payment.IPaymentProvider
+}
+
+// QR_CODE_SIZE is the width and height of payment QR codes in pixels.
+const QR_CODE_SIZE = 512
+
+func NewPaymentService(authRepository repository.IAuthRepository, bookingRepository repository.IBookingRepository, waitlistRepository repository.IWaitlistRepository, scheduleRepository repository.IScheduleRepository, paymentRepository repository.IPaymentRepository, ledgerRepository repository.ILedgerRepository, notificationRepository repository.INotificationRepository, paymentProvider payment.IPaymentProvider, promptPayProvider payment.IPaymentProvider) *paymentService {
+	return &paymentService{authRepository: authRepository, bookingRepository: bookingRepository, waitlistRepository: waitlistRepository, scheduleRepository: scheduleRepository, paymentRepository: paymentRepository, ledgerRepository: ledgerRepository, notificationRepository: notificationRepository, paymentProvider: paymentProvider, promptPayProvider: promptPayProvider}
+}
+
+func (paymentService *paymentService) WithContext(ctx context.Context) IPaymentService {
+	service := *paymentService
+	service.bookingRepository = paymentService.bookingRepository.WithContext(ctx)
+	service.waitlistRepository = paymentService.waitlistRepository.WithContext(ctx)
+	service.paymentRepository = paymentService.paymentRepository.WithContext(ctx)
+	return &service
+}
+
+// HandleWebhook handles a webhook of the payment provider.
+func (paymentService *paymentService) HandleWebhook(payload []byte, signature string) (res vo.Response, statusCode int) {
+	return paymentService.handleWebhook(paymentService.paymentProvider, payload, signature)
+}
+
+// ReconcilePromptPay handles a PromptPay transfer reported by the bank or read
+// from a slip, signed like a webhook.
+func (paymentService *paymentService) ReconcilePromptPay(payload []byte, signature string) (res vo.Response, statusCode int) {
+	return paymentService.handleWebhook(paymentService.promptPayProvider, payload, signature)
+}
+
+// handleWebhook settles the payment of the event and moves its booking from
+// pending to confirmed or failed. Each event is handled once and a payment is
+// settled by the first event reporting its outcome, so redeliveries are
+// acknowledged without changing anything.
+func (paymentService *paymentService) handleWebhook(paymentProvider payment.IPaymentProvider, payload []byte, signature string) (res vo.Response, statusCode int) {
+	event, err := paymentProvider.VerifyWebhook(payload, signature)
+	if errors.Is(err, payment.ErrInvalidSignature) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INVALID_WEBHOOK_SIGNATURE)
+		return res, fiber.StatusUnauthorized
+	}
+	if err != nil {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INVALID_PARAMETERS)
+		return res, fiber.StatusBadRequest
+	}
+
+	record, err := paymentService.paymentRepository.FindPaymentByIntentID(event.IntentID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && record.Provider != paymentProvider.Name()) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_PAYMENT_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	booking, err := paymentService.bookingRepository.FindBookingByID(record.BookingID)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	err = paymentService.bookingRepository.Transaction(func(tx *gorm.DB) error {
+		bookingRepository := paymentService.bookingRepository.WithTx(tx)
+		paymentRepository := paymentService.paymentRepository.WithTx(tx)
+		ledgerRepository := paymentService.ledgerRepository.WithTx(tx)
+		// the resource is locked first, as when booking, so a slot freed by a
+		// failed payment can be offered to the waitlist
+		resource, err := bookingRepository.LockResourceByID(booking.ResourceID)
+		if err != nil {
+			return err
+		}
+
+		created, err := paymentRepository.CreatePaymentEvent(&entity.PaymentEvent{
+			Provider: paymentProvider.Name(),
+			EventID:  event.ID,
+			Type:     event.Type,
+			IntentID: event.IntentID,
+		})
+		if err != nil || !created {
+			return err
+		}
+
+		record, err := paymentRepository.LockPaymentByIntentID(event.IntentID)
+		if err != nil {
+			return err
+		}
+		booking, err := bookingRepository.LockBookingByID(record.BookingID)
+		if err != nil {
+			return err
+		}
+		// money that has moved is recorded even for a payment voided because
+		// the booking was paid otherwise, so the owner can refund it
+		settled := record.Status != entity.PAYMENT_STATUS_PENDING
+		if settled && !(event.Type == payment.EVENT_PAYMENT_CAPTURED && record.Status == entity.PAYMENT_STATUS_VOIDED) {
+			return nil
+		}
+
+		switch event.Type {
+		case payment.EVENT_PAYMENT_AUTHORIZED, payment.EVENT_PAYMENT_CAPTURED:
+			if event.Amount < record.Amount {
+				return errPaymentAmountMismatch
+			}
+			if event.Type == payment.EVENT_PAYMENT_AUTHORIZED {
+				// the booking may have been cancelled while the customer paid,
+				// in which case the authorization is left to lapse
+				if !booking.IsActive() {
+					record.Status = entity.PAYMENT_STATUS_VOIDED
+					return paymentRepository.UpdatePayment(record)
+				}
+				err = paymentProvider.Capture(record.IntentID)
+				if err != nil {
+					return err
+				}
+			}
+			record.Status = entity.PAYMENT_STATUS_CAPTURED
+			err = paymentRepository.UpdatePayment(record)
+			if err != nil {
+				return err
+			}
+			pending := booking.Status == entity.BOOKING_STATUS_PENDING
+			err = recordPayment(bookingRepository, paymentRepository, ledgerRepository, resource.OrganizationID, booking, record, time.Now())
+			if err != nil || !pending || booking.Status != entity.BOOKING_STATUS_CONFIRMED {
+				return err
+			}
+			return notifyBooking(paymentService.notificationRepository.WithTx(tx), resource, booking, notification.EVENT_BOOKING_CONFIRMED, time.Now())
+		case payment.EVENT_PAYMENT_FAILED:
+			record.Status = entity.PAYMENT_STATUS_FAILED
+			record.FailureReason = event.FailureReason
+			err = paymentRepository.UpdatePayment(record)
+			if err != nil || booking.Status != entity.BOOKING_STATUS_PENDING {
+				return err
+			}
+			booking.Status = entity.BOOKING_STATUS_FAILED
+			booking.Sequence++
+			err = bookingRepository.UpdateBooking(booking)
+			if err != nil {
+				return err
+			}
+			err = bookingRepository.ReleaseNoShowFees(booking.ID)
+			if err != nil {
+				return err
+			}
+			err = postCancellation(ledgerRepository, resource.OrganizationID, booking, "payment failed")
+			if err != nil {
+				return err
+			}
+			err = notifyBooking(paymentService.notificationRepository.WithTx(tx), resource, booking, notification.EVENT_BOOKING_CANCELLED, time.Now())
+			if err != nil {
+				return err
+			}
+			return promoteWaitlist(bookingRepository, paymentService.waitlistRepository.WithTx(tx), paymentService.scheduleRepository, resource, time.Now())
+		}
+		return nil
+	})
+	if err != nil {
+		return res, setBookingError(&res, err)
+	}
+
+	res.SetData(nil)
+	return res, fiber.StatusOK
+}
+
+// SimulatePayment completes the intent of the user with a provider running
+// in-process, as the customer would, and handles the webhook it delivers.
+func (paymentService *paymentService) SimulatePayment(username string, intentID string, simulatePaymentDTO *dto.SimulatePaymentDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(paymentService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	simulator, ok := paymentService.paymentProvider.(payment.ISimulator)
+	if !ok {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_PAYMENT_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	record, err := paymentService.paymentRepository.FindPaymentByIntentID(intentID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && record.UserID != user.ID) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_PAYMENT_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	var payload []byte
+	var signature string
+	if simulatePaymentDTO.Outcome == payment.INTENT_STATUS_FAILED {
+		payload, signature, err = simulator.Decline(intentID, simulatePaymentDTO.FailureReason)
+	} else {
+		payload, signature, err = simulator.Authorize(intentID)
+	}
+	// the intents of the fake provider don't survive a restart
+	if errors.Is(err, payment.ErrIntentNotFound) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_PAYMENT_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if errors.Is(err, payment.ErrIntentCompleted) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_PAYMENT_ALREADY_COMPLETED)
+		return res, fiber.StatusConflict
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	return paymentService.HandleWebhook(payload, signature)
+}
+
+// findOwnBooking finds the booking of the user.
+func findOwnBooking(bookingRepository repository.IBookingRepository, bookingID uuid.UUID, user *entity.User) (*entity.Booking, string, int) {
+	booking, err := bookingRepository.FindBookingByID(bookingID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && booking.UserID != user.ID) {
+		return nil, constant.ERROR_MESSAGE_BOOKING_NOT_FOUND, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		return nil, constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR, fiber.StatusInternalServerError
+	}
+	return booking, "", fiber.StatusOK
+}
+
+func (paymentService *paymentService) GetBookingPayments(username string, bookingID uuid.UUID) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(paymentService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+	booking, errorMessage, statusCode := findOwnBooking(paymentService.bookingRepository, bookingID, user)
+	if booking == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	payments, err := paymentService.paymentRepository.FindPaymentsByBookingID(booking.ID)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	responses := []vo.PaymentResponse{}
+	for i := range payments {
+		responses = append(responses, vo.NewPaymentResponse(&payments[i]))
+	}
+	res.SetData(responses)
+	return res, fiber.StatusOK
+}
+
+// CreatePayment starts a payment of the booking with the payment provider,
+// of the amount asked or else of what the customer is asked to pay next, so
+// bookings can be paid in parts.
+func (paymentService *paymentService) CreatePayment(username string, bookingID uuid.UUID, createPaymentDTO *dto.CreatePaymentDTO) (res vo.Response, statusCode int) {
+	return paymentService.startBookingPayment(username, bookingID, paymentService.paymentProvider, createPaymentDTO.Amount)
+}
+
+// CreatePromptPayPayment returns a PromptPay QR payment of what the customer
+// is asked to pay next. A pending PromptPay payment of the same amount is
+// returned again so its QR code stays the same.
+func (paymentService *paymentService) CreatePromptPayPayment(username string, bookingID uuid.UUID) (res vo.Response, statusCode int) {
+	return paymentService.startBookingPayment(username, bookingID, paymentService.promptPayProvider, 0)
+}
+
+func (paymentService *paymentService) startBookingPayment(username string, bookingID uuid.UUID, paymentProvider payment.IPaymentProvider, amount int64) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(paymentService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+	booking, errorMessage, statusCode := findOwnBooking(paymentService.bookingRepository, bookingID, user)
+	if booking == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+	if !booking.IsActive() {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_BOOKING_NOT_PAYABLE)
+		return res, fiber.StatusConflict
+	}
+	if booking.OutstandingAmount() == 0 {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_BOOKING_ALREADY_PAID)
+		return res, fiber.StatusConflict
+	}
+	if amount > booking.OutstandingAmount() {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_PAYMENT_EXCEEDS_BALANCE)
+		return res, fiber.StatusBadRequest
+	}
+	if amount == 0 {
+		amount = nextPaymentAmount(booking, time.Now())
+	}
+
+	if paymentProvider.Name() == payment.PROMPTPAY_PROVIDER {
+		payments, err := paymentService.paymentRepository.FindPaymentsByBookingID(booking.ID)
+		if err != nil {
+			logs.Error(err)
+			res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+			return res, fiber.StatusInternalServerError
+		}
+		for i := range payments {
+			record := &payments[i]
+			if record.Provider == payment.PROMPTPAY_PROVIDER && record.Status == entity.PAYMENT_STATUS_PENDING && record.Amount == amount {
+				res.SetData(vo.NewPaymentResponse(record))
+				return res, fiber.StatusOK
+			}
+		}
+	}
+
+	response, err := startPayment(paymentService.paymentRepository, paymentProvider, booking, amount)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	res.SetData(*response)
+	return res, fiber.StatusCreated
+}
+
+// GetPaymentQRCode renders the QR code of the payment of the user as a PNG
+// image in the data of the response.
+func (paymentService *paymentService) GetPaymentQRCode(username string, paymentID uuid.UUID) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(paymentService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	record, err := paymentService.paymentRepository.FindPaymentByID(paymentID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && record.UserID != user.ID) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_PAYMENT_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	if record.QRPayload == "" {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_PAYMENT_HAS_NO_QR_CODE)
+		return res, fiber.StatusNotFound
+	}
+
+	png, err := promptpay.QRCode(record.QRPayload, QR_CODE_SIZE)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	res.SetData(png)
+	return res, fiber.StatusOK
+}
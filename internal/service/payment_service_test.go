@@ -0,0 +1,310 @@
+package service_test
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/payment"
+	"booking/internal/service"
+	"booking/internal/vo"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const PromptPaySecret = "promptpaysecret"
+
+func newPromptPayProvider() payment.IPaymentProvider {
+	return payment.NewPromptPayProvider("0812345678", PromptPaySecret)
+}
+
+func TestHandleWebhook(t *testing.T) {
+	resource := &entity.Resource{ID: uuid.New(), Capacity: 1}
+	startAt := time.Now().Add(72 * time.Hour).Truncate(time.Hour)
+	newPendingBooking := func(paymentProvider payment.IPaymentProvider) (*entity.Booking, *entity.Payment) {
+		booking := &entity.Booking{ID: uuid.New(), ResourceID: resource.ID, StartAt: startAt, EndAt: startAt.Add(time.Hour), Status: entity.BOOKING_STATUS_PENDING, Amount: 50000}
+		intent, _ := paymentProvider.CreateIntent(payment.IntentRequest{Reference: booking.ID.String(), Amount: booking.Amount, Currency: payment.CURRENCY_THB})
+		return booking, &entity.Payment{ID: uuid.New(), BookingID: booking.ID, Provider: paymentProvider.Name(), IntentID: intent.ID, Amount: booking.Amount, Status: entity.PAYMENT_STATUS_PENDING}
+	}
+	newRepositoryMocks := func(booking *entity.Booking, pending *entity.Payment) (*bookingRepositoryMock, *paymentRepositoryMock) {
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("FindBookingByID", booking.ID).Return(booking, nil)
+		bookingRepositoryMock.On("LockResourceByID", resource.ID).Return(resource, nil)
+		bookingRepositoryMock.On("LockBookingByID", booking.ID).Return(booking, nil)
+		bookingRepositoryMock.On("UpdateBooking", booking.ID).Return(nil)
+		paymentRepositoryMock := &paymentRepositoryMock{}
+		paymentRepositoryMock.On("FindPaymentByIntentID", pending.IntentID).Return(pending, nil)
+		paymentRepositoryMock.On("LockPaymentByIntentID", pending.IntentID).Return(pending, nil)
+		paymentRepositoryMock.On("UpdatePayment", pending.ID, mock.Anything).Return(nil)
+		paymentRepositoryMock.On("FindPaymentsByBookingID", booking.ID).Return([]entity.Payment{*pending}, nil)
+		return bookingRepositoryMock, paymentRepositoryMock
+	}
+
+	t.Run("When the signature does not match the payload, should response status code 401 with error message", func(t *testing.T) {
+		paymentProvider := payment.NewFakeProvider(WebhookSecret)
+		_, pending := newPendingBooking(paymentProvider)
+		payload, _, _ := paymentProvider.Authorize(pending.IntentID)
+		paymentService := service.NewPaymentService(&authRepositoryMock{}, &bookingRepositoryMock{}, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), &paymentRepositoryMock{}, newLedgerRepositoryMock(), newNotificationRepositoryMock(), paymentProvider, newPromptPayProvider())
+
+		res, statusCode := paymentService.HandleWebhook(payload, payment.Sign([]byte("anothersecret"), payload))
+
+		assert.Equal(t, fiber.StatusUnauthorized, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_INVALID_WEBHOOK_SIGNATURE, res.ErrorMessage)
+	})
+
+	t.Run("When the payment is authorized, should capture it and confirm the booking", func(t *testing.T) {
+		paymentProvider := payment.NewFakeProvider(WebhookSecret)
+		booking, pending := newPendingBooking(paymentProvider)
+		payload, signature, _ := paymentProvider.Authorize(pending.IntentID)
+		bookingRepositoryMock, paymentRepositoryMock := newRepositoryMocks(booking, pending)
+		paymentRepositoryMock.On("CreatePaymentEvent", mock.Anything).Return(true, nil)
+		paymentService := service.NewPaymentService(&authRepositoryMock{}, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), paymentRepositoryMock, newLedgerRepositoryMock(), newNotificationRepositoryMock(), paymentProvider, newPromptPayProvider())
+
+		res, statusCode := paymentService.HandleWebhook(payload, signature)
+
+		assert.Equal(t, fiber.StatusOK, statusCode)
+		assert.True(t, res.Success)
+		assert.Equal(t, entity.PAYMENT_STATUS_CAPTURED, pending.Status)
+		assert.Equal(t, entity.BOOKING_STATUS_CONFIRMED, booking.Status)
+		assert.NoError(t, paymentProvider.Refund(pending.IntentID, booking.Amount))
+	})
+
+	t.Run("When the balance of a confirmed booking is paid, should record it and lift the overdue flag", func(t *testing.T) {
+		paymentProvider := payment.NewFakeProvider(WebhookSecret)
+		booking, pending := newPendingBooking(paymentProvider)
+		dueAt := time.Now().Add(-time.Hour)
+		booking.Status = entity.BOOKING_STATUS_CONFIRMED
+		booking.PaymentSchedule = entity.PaymentDues{{DueAt: dueAt.AddDate(0, 0, -7), Amount: 20000}, {DueAt: dueAt, Amount: 30000}}
+		booking.PaidAmount = 20000
+		booking.NextDueAt = &dueAt
+		booking.OverdueAt = &dueAt
+		pending.Amount = 30000
+		payload, signature, _ := paymentProvider.Authorize(pending.IntentID)
+		bookingRepositoryMock, paymentRepositoryMock := newRepositoryMocks(booking, pending)
+		paymentRepositoryMock.On("CreatePaymentEvent", mock.Anything).Return(true, nil)
+		ledgerRepositoryMock := newLedgerRepositoryMock()
+		paymentService := service.NewPaymentService(&authRepositoryMock{}, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), paymentRepositoryMock, ledgerRepositoryMock, newNotificationRepositoryMock(), paymentProvider, newPromptPayProvider())
+
+		_, statusCode := paymentService.HandleWebhook(payload, signature)
+
+		assert.Equal(t, fiber.StatusOK, statusCode)
+		assert.Equal(t, entity.PAYMENT_STATUS_CAPTURED, pending.Status)
+		assert.Equal(t, entity.BOOKING_STATUS_CONFIRMED, booking.Status)
+		assert.Equal(t, int64(50000), booking.PaidAmount)
+		assert.Equal(t, int64(30000), ledgerRepositoryMock.balance(resource.OrganizationID, entity.CashAccount(paymentProvider.Name())))
+		assert.Equal(t, int64(-30000), ledgerRepositoryMock.balance(resource.OrganizationID, entity.LEDGER_ACCOUNT_RECEIVABLE))
+		assert.Nil(t, booking.NextDueAt)
+		assert.Nil(t, booking.OverdueAt)
+	})
+
+	t.Run("When the event was delivered before, should acknowledge it without changing the booking", func(t *testing.T) {
+		paymentProvider := payment.NewFakeProvider(WebhookSecret)
+		booking, pending := newPendingBooking(paymentProvider)
+		payload, signature, _ := paymentProvider.Authorize(pending.IntentID)
+		bookingRepositoryMock, paymentRepositoryMock := newRepositoryMocks(booking, pending)
+		paymentRepositoryMock.On("CreatePaymentEvent", mock.Anything).Return(false, nil)
+		paymentService := service.NewPaymentService(&authRepositoryMock{}, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), paymentRepositoryMock, newLedgerRepositoryMock(), newNotificationRepositoryMock(), paymentProvider, newPromptPayProvider())
+
+		_, statusCode := paymentService.HandleWebhook(payload, signature)
+
+		assert.Equal(t, fiber.StatusOK, statusCode)
+		assert.Equal(t, entity.BOOKING_STATUS_PENDING, booking.Status)
+		bookingRepositoryMock.AssertNotCalled(t, "UpdateBooking", booking.ID)
+		paymentRepositoryMock.AssertNotCalled(t, "UpdatePayment", pending.ID, mock.Anything)
+	})
+
+	t.Run("When the payment fails, should fail the booking and offer the slot to the waitlist", func(t *testing.T) {
+		paymentProvider := payment.NewFakeProvider(WebhookSecret)
+		booking, pending := newPendingBooking(paymentProvider)
+		payload, signature, _ := paymentProvider.Decline(pending.IntentID, "insufficient funds")
+		bookingRepositoryMock, paymentRepositoryMock := newRepositoryMocks(booking, pending)
+		paymentRepositoryMock.On("CreatePaymentEvent", mock.Anything).Return(true, nil)
+		waitlistRepositoryMock := newWaitlistRepositoryMock()
+		paymentService := service.NewPaymentService(&authRepositoryMock{}, bookingRepositoryMock, waitlistRepositoryMock, newScheduleRepositoryMock(), paymentRepositoryMock, newLedgerRepositoryMock(), newNotificationRepositoryMock(), paymentProvider, newPromptPayProvider())
+
+		_, statusCode := paymentService.HandleWebhook(payload, signature)
+
+		assert.Equal(t, fiber.StatusOK, statusCode)
+		assert.Equal(t, entity.PAYMENT_STATUS_FAILED, pending.Status)
+		assert.Equal(t, "insufficient funds", pending.FailureReason)
+		assert.Equal(t, entity.BOOKING_STATUS_FAILED, booking.Status)
+		waitlistRepositoryMock.AssertCalled(t, "FindWaitingEntries", resource.ID)
+	})
+
+	t.Run("When the booking was cancelled while paying, should leave the authorization uncaptured", func(t *testing.T) {
+		paymentProvider := payment.NewFakeProvider(WebhookSecret)
+		booking, pending := newPendingBooking(paymentProvider)
+		booking.Status = entity.BOOKING_STATUS_CANCELLED
+		payload, signature, _ := paymentProvider.Authorize(pending.IntentID)
+		bookingRepositoryMock, paymentRepositoryMock := newRepositoryMocks(booking, pending)
+		paymentRepositoryMock.On("CreatePaymentEvent", mock.Anything).Return(true, nil)
+		paymentService := service.NewPaymentService(&authRepositoryMock{}, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), paymentRepositoryMock, newLedgerRepositoryMock(), newNotificationRepositoryMock(), paymentProvider, newPromptPayProvider())
+
+		_, statusCode := paymentService.HandleWebhook(payload, signature)
+
+		assert.Equal(t, fiber.StatusOK, statusCode)
+		assert.Equal(t, entity.PAYMENT_STATUS_VOIDED, pending.Status)
+		assert.Equal(t, entity.BOOKING_STATUS_CANCELLED, booking.Status)
+		assert.ErrorIs(t, paymentProvider.Refund(pending.IntentID, booking.Amount), payment.ErrNotCaptured)
+	})
+}
+
+func TestReconcilePromptPay(t *testing.T) {
+	resource := &entity.Resource{ID: uuid.New(), Capacity: 1}
+	startAt := time.Now().Add(72 * time.Hour).Truncate(time.Hour)
+	newNotification := func(pending *entity.Payment, amount int64) ([]byte, string) {
+		payload, _ := json.Marshal(payment.Notification{Reference: pending.IntentID, TransactionID: "202610190001", Amount: amount, PaidAt: time.Now()})
+		return payload, payment.Sign([]byte(PromptPaySecret), payload)
+	}
+	newRepositoryMocks := func() (*entity.Booking, *entity.Payment, *bookingRepositoryMock, *paymentRepositoryMock) {
+		booking := &entity.Booking{ID: uuid.New(), ResourceID: resource.ID, StartAt: startAt, EndAt: startAt.Add(time.Hour), Status: entity.BOOKING_STATUS_PENDING, Amount: 50000}
+		intent, _ := newPromptPayProvider().CreateIntent(payment.IntentRequest{Reference: booking.ID.String(), Amount: booking.Amount, Currency: payment.CURRENCY_THB})
+		pending := &entity.Payment{ID: uuid.New(), BookingID: booking.ID, Provider: payment.PROMPTPAY_PROVIDER, IntentID: intent.ID, Amount: booking.Amount, Status: entity.PAYMENT_STATUS_PENDING, QRPayload: intent.QRPayload}
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("FindBookingByID", booking.ID).Return(booking, nil)
+		bookingRepositoryMock.On("LockResourceByID", resource.ID).Return(resource, nil)
+		bookingRepositoryMock.On("LockBookingByID", booking.ID).Return(booking, nil)
+		bookingRepositoryMock.On("UpdateBooking", booking.ID).Return(nil)
+		paymentRepositoryMock := &paymentRepositoryMock{}
+		paymentRepositoryMock.On("FindPaymentByIntentID", pending.IntentID).Return(pending, nil)
+		paymentRepositoryMock.On("LockPaymentByIntentID", pending.IntentID).Return(pending, nil)
+		paymentRepositoryMock.On("UpdatePayment", pending.ID, mock.Anything).Return(nil)
+		paymentRepositoryMock.On("FindPaymentsByBookingID", booking.ID).Return([]entity.Payment{*pending}, nil)
+		paymentRepositoryMock.On("CreatePaymentEvent", "202610190001").Return(true, nil)
+		return booking, pending, bookingRepositoryMock, paymentRepositoryMock
+	}
+
+	t.Run("When the transfer pays the QR code, should capture the payment and confirm the booking", func(t *testing.T) {
+		booking, pending, bookingRepositoryMock, paymentRepositoryMock := newRepositoryMocks()
+		payload, signature := newNotification(pending, booking.Amount)
+		paymentService := service.NewPaymentService(&authRepositoryMock{}, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), paymentRepositoryMock, newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret), newPromptPayProvider())
+
+		res, statusCode := paymentService.ReconcilePromptPay(payload, signature)
+
+		assert.Equal(t, fiber.StatusOK, statusCode)
+		assert.True(t, res.Success)
+		assert.Equal(t, entity.PAYMENT_STATUS_CAPTURED, pending.Status)
+		assert.Equal(t, entity.BOOKING_STATUS_CONFIRMED, booking.Status)
+	})
+
+	t.Run("When the transfer is less than the amount, should response status code 400 with error message", func(t *testing.T) {
+		booking, pending, bookingRepositoryMock, paymentRepositoryMock := newRepositoryMocks()
+		payload, signature := newNotification(pending, booking.Amount-100)
+		paymentService := service.NewPaymentService(&authRepositoryMock{}, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), paymentRepositoryMock, newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret), newPromptPayProvider())
+
+		res, statusCode := paymentService.ReconcilePromptPay(payload, signature)
+
+		assert.Equal(t, fiber.StatusBadRequest, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_PAYMENT_AMOUNT_MISMATCH, res.ErrorMessage)
+		assert.Equal(t, entity.PAYMENT_STATUS_PENDING, pending.Status)
+		assert.Equal(t, entity.BOOKING_STATUS_PENDING, booking.Status)
+	})
+}
+
+func TestCreatePromptPayPayment(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+	newBooking := func() *entity.Booking {
+		startAt := time.Now().Add(72 * time.Hour).Truncate(time.Hour)
+		return &entity.Booking{ID: uuid.New(), UserID: user.ID, StartAt: startAt, EndAt: startAt.Add(time.Hour), Status: entity.BOOKING_STATUS_PENDING, Amount: 50000}
+	}
+
+	t.Run("When the booking is pending, should response status code 201 with a QR payment of the balance", func(t *testing.T) {
+		booking := newBooking()
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("FindBookingByID", booking.ID).Return(booking, nil)
+		paymentRepositoryMock := newPaymentRepositoryMock()
+		paymentRepositoryMock.On("FindPaymentsByBookingID", booking.ID).Return([]entity.Payment{}, nil)
+		paymentService := service.NewPaymentService(authRepositoryMock, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), paymentRepositoryMock, newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret), newPromptPayProvider())
+
+		res, statusCode := paymentService.CreatePromptPayPayment(Username, booking.ID)
+
+		assert.Equal(t, fiber.StatusCreated, statusCode)
+		response := res.Data.(vo.PaymentResponse)
+		assert.Equal(t, payment.PROMPTPAY_PROVIDER, response.Provider)
+		assert.Equal(t, booking.Amount, response.Amount)
+		assert.Contains(t, response.QRPayload, "5406500.00")
+	})
+
+	t.Run("When the booking is paid, should response status code 409 with error message", func(t *testing.T) {
+		booking := newBooking()
+		booking.Status = entity.BOOKING_STATUS_CONFIRMED
+		booking.PaidAmount = booking.Amount
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("FindBookingByID", booking.ID).Return(booking, nil)
+		paymentRepositoryMock := newPaymentRepositoryMock()
+		paymentService := service.NewPaymentService(authRepositoryMock, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), paymentRepositoryMock, newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret), newPromptPayProvider())
+
+		res, statusCode := paymentService.CreatePromptPayPayment(Username, booking.ID)
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_BOOKING_ALREADY_PAID, res.ErrorMessage)
+		paymentRepositoryMock.AssertNotCalled(t, "CreatePayment", mock.Anything)
+	})
+}
+
+func TestCreatePayment(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+	startAt := time.Now().Add(30 * 24 * time.Hour).Truncate(time.Hour)
+	newBooking := func() *entity.Booking {
+		return &entity.Booking{
+			ID:              uuid.New(),
+			UserID:          user.ID,
+			StartAt:         startAt,
+			EndAt:           startAt.Add(time.Hour),
+			Status:          entity.BOOKING_STATUS_CONFIRMED,
+			Amount:          100000,
+			PaidAmount:      30000,
+			PaymentSchedule: entity.PaymentDues{{DueAt: time.Now().AddDate(0, 0, -1), Amount: 30000}, {DueAt: startAt.AddDate(0, 0, -7), Amount: 70000}},
+		}
+	}
+	newPaymentService := func(booking *entity.Booking, paymentRepositoryMock *paymentRepositoryMock) service.IPaymentService {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("FindBookingByID", booking.ID).Return(booking, nil)
+		return service.NewPaymentService(authRepositoryMock, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), paymentRepositoryMock, newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret), newPromptPayProvider())
+	}
+
+	t.Run("When no amount is given before the balance is due, should response status code 201 with a payment of the balance", func(t *testing.T) {
+		booking := newBooking()
+		paymentService := newPaymentService(booking, newPaymentRepositoryMock())
+
+		res, statusCode := paymentService.CreatePayment(Username, booking.ID, &dto.CreatePaymentDTO{})
+
+		assert.Equal(t, fiber.StatusCreated, statusCode)
+		assert.Equal(t, int64(70000), res.Data.(vo.PaymentResponse).Amount)
+	})
+
+	t.Run("When a part of the balance is paid, should response status code 201 with a payment of the part", func(t *testing.T) {
+		booking := newBooking()
+		paymentService := newPaymentService(booking, newPaymentRepositoryMock())
+
+		res, statusCode := paymentService.CreatePayment(Username, booking.ID, &dto.CreatePaymentDTO{Amount: 20000})
+
+		assert.Equal(t, fiber.StatusCreated, statusCode)
+		assert.Equal(t, int64(20000), res.Data.(vo.PaymentResponse).Amount)
+	})
+
+	t.Run("When the amount is more than the balance, should response status code 400 with error message", func(t *testing.T) {
+		booking := newBooking()
+		paymentRepositoryMock := newPaymentRepositoryMock()
+		paymentService := newPaymentService(booking, paymentRepositoryMock)
+
+		res, statusCode := paymentService.CreatePayment(Username, booking.ID, &dto.CreatePaymentDTO{Amount: 80000})
+
+		assert.Equal(t, fiber.StatusBadRequest, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_PAYMENT_EXCEEDS_BALANCE, res.ErrorMessage)
+		paymentRepositoryMock.AssertNotCalled(t, "CreatePayment", mock.Anything)
+	})
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/logs"
+	"booking/internal/pricing"
+	"booking/internal/repository"
+	"booking/internal/vo"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type IPricingService interface {
+	WithContext(ctx context.Context) IPricingService
+	GetPricingPolicy(resourceID uuid.UUID) (res vo.Response, statusCode int)
+	SavePricingPolicy(username string, resourceID uuid.UUID, pricingPolicyDTO *dto.PricingPolicyDTO) (res vo.Response, statusCode int)
+	CreateQuote(username string, createQuoteDTO *dto.CreateQuoteDTO) (res vo.Response, statusCode int)
+	AddResourceMember(username string, resourceID uuid.UUID, addResourceMemberDTO *dto.AddResourceMemberDTO) (res vo.Response, statusCode int)
+	RemoveResourceMember(username string, resourceID uuid.UUID, userID uuid.UUID) (res vo.Response, statusCode int)
+}
+
+type pricingService struct {
+	authRepository      repository.IAuthRepository
+	resourceRepository  repository.IResourceRepository
+	promoCodeRepository repository.IPromoCodeRepository
+	ctx                 context.Context
+}
+
+func NewPricingService(authRepository repository.IAuthRepository, resourceRepository repository.IResourceRepository, promoCodeRepository repository.IPromoCodeRepository) *pricingService {
+	return &pricingService{authRepository: authRepository, resourceRepository: resourceRepository, promoCodeRepository: promoCodeRepository}
+}
+
+func (pricingService *pricingService) WithContext(ctx context.Context) IPricingService {
+	service := *pricingService
+	service.ctx = ctx
+	service.resourceRepository = pricingService.resourceRepository.WithContext(ctx)
+	service.promoCodeRepository = pricingService.promoCodeRepository.WithContext(ctx)
+	return &service
+}
+
+func (pricingService *pricingService) GetPricingPolicy(resourceID uuid.UUID) (res vo.Response, statusCode int) {
+	resource, err := pricingService.resourceRepository.FindResourceByID(resourceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_RESOURCE_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	policy, err := pricingService.resourceRepository.FindPricingPolicyByResourceID(resourceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// a resource without a policy charges its hourly rate
+		policy = &entity.PricingPolicy{ResourceID: resourceID}
+	} else if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(newPricingPolicyResponse(resource, policy))
+	return res, fiber.StatusOK
+}
+
+func newPricingPolicyResponse(resource *entity.Resource, policy *entity.PricingPolicy) vo.PricingPolicyResponse {
+	rules := []entity.PricingRule(policy.Rules)
+	if rules == nil {
+		rules = []entity.PricingRule{}
+	}
+	return vo.PricingPolicyResponse{ResourceID: resource.ID, HourlyRate: resource.HourlyRate, Rules: rules}
+}
+
+// SavePricingPolicy replaces the pricing rules of the resource. Existing
+// bookings keep the quote they were made with.
+func (pricingService *pricingService) SavePricingPolicy(username string, resourceID uuid.UUID, pricingPolicyDTO *dto.PricingPolicyDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(pricingService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	resource, errorMessage, statusCode := findManagedResource(pricingService.ctx, pricingService.resourceRepository, resourceID)
+	if resource == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	rules := entity.PricingRules{}
+	for _, rule := range pricingPolicyDTO.Rules {
+		rules = append(rules, entity.PricingRule{
+			Name:       rule.Name,
+			Type:       rule.Type,
+			Percent:    rule.Percent,
+			Weekdays:   rule.Weekdays,
+			StartTime:  rule.StartTime,
+			EndTime:    rule.EndTime,
+			StartDate:  rule.StartDate,
+			EndDate:    rule.EndDate,
+			MinMinutes: rule.MinMinutes,
+			MaxMinutes: rule.MaxMinutes,
+		})
+	}
+	if err := pricing.Validate(rules); err != nil {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INVALID_PRICING_RULE)
+		res.AppendErrors(err.Error())
+		return res, fiber.StatusBadRequest
+	}
+
+	policy, err := pricingService.resourceRepository.FindPricingPolicyByResourceID(resourceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		policy = &entity.PricingPolicy{ResourceID: resourceID}
+	} else if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	policy.Rules = rules
+	err = pricingService.resourceRepository.SavePricingPolicy(policy)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(newPricingPolicyResponse(resource, policy))
+	return res, fiber.StatusOK
+}
+
+// CreateQuote prices a booking for the user the way booking it now would,
+// without checking the slot is available or redeeming the promo code.
+func (pricingService *pricingService) CreateQuote(username string, createQuoteDTO *dto.CreateQuoteDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(pricingService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	resourceID := uuid.MustParse(createQuoteDTO.ResourceID)
+	resource, err := pricingService.resourceRepository.FindResourceByID(resourceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_RESOURCE_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	seats := max(createQuoteDTO.Seats, 1)
+	if seats > resource.Capacity {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_SEATS_EXCEED_CAPACITY)
+		return res, fiber.StatusBadRequest
+	}
+
+	rules, member, err := findPricing(pricingService.resourceRepository, resourceID, user.ID)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	quote := newPricer(resource, rules, member).Quote(createQuoteDTO.StartAt, createQuoteDTO.EndAt, seats)
+	if createQuoteDTO.PromoCode != "" {
+		// the code is checked as booking would, but only redeemed by booking
+		promoCode, err := findPromoCode(pricingService.promoCodeRepository, createQuoteDTO.PromoCode, false)
+		if err == nil {
+			err = checkPromoCode(pricingService.promoCodeRepository, promoCode, resource, user.ID, &quote, time.Now())
+		}
+		if err != nil {
+			return res, setBookingError(&res, err)
+		}
+		quote.ApplyPromoCode(promoCode)
+	}
+	res.SetData(vo.NewQuoteResponse(resourceID, createQuoteDTO.StartAt, createQuoteDTO.EndAt, &quote))
+	return res, fiber.StatusOK
+}
+
+// AddResourceMember entitles the user to the member discounts of the resource.
+func (pricingService *pricingService) AddResourceMember(username string, resourceID uuid.UUID, addResourceMemberDTO *dto.AddResourceMemberDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(pricingService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	resource, errorMessage, statusCode := findManagedResource(pricingService.ctx, pricingService.resourceRepository, resourceID)
+	if resource == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	memberUser, err := pricingService.authRepository.FindUserByUsername(addResourceMemberDTO.Username)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_USER_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	err = pricingService.resourceRepository.AddResourceMember(&entity.ResourceMember{ResourceID: resourceID, UserID: memberUser.ID})
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(nil)
+	return res, fiber.StatusOK
+}
+
+func (pricingService *pricingService) RemoveResourceMember(username string, resourceID uuid.UUID, userID uuid.UUID) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(pricingService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	resource, errorMessage, statusCode := findManagedResource(pricingService.ctx, pricingService.resourceRepository, resourceID)
+	if resource == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	deleted, err := pricingService.resourceRepository.RemoveResourceMember(resourceID, userID)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	if deleted == 0 {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_RESOURCE_MEMBER_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+
+	res.SetData(nil)
+	return res, fiber.StatusOK
+}
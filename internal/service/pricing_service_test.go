@@ -0,0 +1,109 @@
+package service_test
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/service"
+	"booking/internal/vo"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestCreateQuote(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+	resource := &entity.Resource{ID: uuid.New(), HourlyRate: 60000, Capacity: 2, TimeZone: constant.DEFAULT_TIME_ZONE}
+	rules := entity.PricingRules{
+		{Name: "2 hours or more", Type: entity.PRICING_RULE_DURATION_TIER, Percent: -10, MinMinutes: 120},
+		{Name: "Member", Type: entity.PRICING_RULE_MEMBER_DISCOUNT, Percent: -20},
+	}
+	startAt := time.Now().Add(72 * time.Hour).Truncate(time.Hour)
+	body := dto.CreateQuoteDTO{ResourceID: resource.ID.String(), StartAt: startAt, EndAt: startAt.Add(2 * time.Hour)}
+
+	t.Run("When the seats exceed the capacity, should response status code 400 with error message", func(t *testing.T) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindResourceByID", resource.ID).Return(resource, nil)
+		pricingService := service.NewPricingService(authRepositoryMock, resourceRepositoryMock, &promoCodeRepositoryMock{})
+		body := body
+		body.Seats = 3
+
+		res, statusCode := pricingService.CreateQuote(Username, &body)
+
+		assert.Equal(t, fiber.StatusBadRequest, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_SEATS_EXCEED_CAPACITY, res.ErrorMessage)
+	})
+
+	t.Run("When the user is a member, should itemize the member discount", func(t *testing.T) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindResourceByID", resource.ID).Return(resource, nil)
+		resourceRepositoryMock.On("FindPricingPolicyByResourceID", resource.ID).Return(&entity.PricingPolicy{Rules: rules}, nil)
+		resourceRepositoryMock.On("IsResourceMember", resource.ID, user.ID).Return(true, nil)
+		pricingService := service.NewPricingService(authRepositoryMock, resourceRepositoryMock, &promoCodeRepositoryMock{})
+
+		res, statusCode := pricingService.CreateQuote(Username, &body)
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			quote := res.Data.(vo.QuoteResponse)
+			assert.Len(t, quote.Lines, 3)
+			assert.Equal(t, int64(-21600), quote.Lines[2].Amount)
+			assert.Equal(t, int64(86400), quote.Total)
+		}
+	})
+}
+
+func TestSavePricingPolicy(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+	resource := &entity.Resource{ID: uuid.New(), HourlyRate: 60000}
+
+	t.Run("When a rule misses the settings of its type, should response status code 400 with error message", func(t *testing.T) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindResourceByID", resource.ID).Return(resource, nil)
+		pricingService := service.NewPricingService(authRepositoryMock, resourceRepositoryMock, &promoCodeRepositoryMock{}).WithContext(AdminContext)
+
+		res, statusCode := pricingService.SavePricingPolicy(Username, resource.ID, &dto.PricingPolicyDTO{
+			Rules: []dto.PricingRuleDTO{{Name: "Peak", Type: entity.PRICING_RULE_PEAK_HOURS, Percent: 50}},
+		})
+
+		assert.Equal(t, fiber.StatusBadRequest, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_INVALID_PRICING_RULE, res.ErrorMessage)
+		resourceRepositoryMock.AssertNotCalled(t, "SavePricingPolicy", resource.ID)
+	})
+
+	t.Run("When the rules are valid, should save them in order", func(t *testing.T) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindResourceByID", resource.ID).Return(resource, nil)
+		resourceRepositoryMock.On("FindPricingPolicyByResourceID", resource.ID).Return(&entity.PricingPolicy{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("SavePricingPolicy", resource.ID).Return(nil)
+		pricingService := service.NewPricingService(authRepositoryMock, resourceRepositoryMock, &promoCodeRepositoryMock{}).WithContext(AdminContext)
+
+		res, statusCode := pricingService.SavePricingPolicy(Username, resource.ID, &dto.PricingPolicyDTO{
+			Rules: []dto.PricingRuleDTO{
+				{Name: "Peak", Type: entity.PRICING_RULE_PEAK_HOURS, Percent: 50, StartTime: "18:00", EndTime: "21:00"},
+				{Name: "Weekend", Type: entity.PRICING_RULE_WEEKEND, Percent: 20},
+			},
+		})
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			policy := res.Data.(vo.PricingPolicyResponse)
+			assert.Equal(t, "Peak", policy.Rules[0].Name)
+			assert.Equal(t, "Weekend", policy.Rules[1].Name)
+		}
+	})
+}
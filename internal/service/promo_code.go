@@ -0,0 +1,67 @@
+package service
+
+import (
+	"booking/internal/constant"
+	"booking/internal/entity"
+	"booking/internal/repository"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	errPromoCodeNotFound      = errors.New(constant.ERROR_MESSAGE_PROMO_CODE_NOT_FOUND)
+	errPromoCodeNotValid      = errors.New(constant.ERROR_MESSAGE_PROMO_CODE_NOT_VALID)
+	errPromoCodeNotApplicable = errors.New(constant.ERROR_MESSAGE_PROMO_CODE_NOT_APPLICABLE)
+	errPromoCodeMinSpend      = errors.New(constant.ERROR_MESSAGE_PROMO_CODE_MIN_SPEND_NOT_MET)
+	errPromoCodeExhausted     = errors.New(constant.ERROR_MESSAGE_PROMO_CODE_EXHAUSTED)
+	errPromoCodeLimitReached  = errors.New(constant.ERROR_MESSAGE_PROMO_CODE_LIMIT_REACHED)
+)
+
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// findPromoCode finds the promo code, locking it when it is about to be
+// redeemed so redemptions of the code are serialized.
+func findPromoCode(promoCodeRepository repository.IPromoCodeRepository, code string, lock bool) (*entity.PromoCode, error) {
+	find := promoCodeRepository.FindPromoCodeByCode
+	if lock {
+		find = promoCodeRepository.LockPromoCodeByCode
+	}
+	promoCode, err := find(normalizePromoCode(code))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errPromoCodeNotFound
+	}
+	return promoCode, err
+}
+
+// checkPromoCode returns why the promo code cannot discount the quote of a
+// booking of the resource by the user, or nil when it can.
+func checkPromoCode(promoCodeRepository repository.IPromoCodeRepository, promoCode *entity.PromoCode, resource *entity.Resource, userID uuid.UUID, quote *entity.Quote, now time.Time) error {
+	if !promoCode.IsValidAt(now) {
+		return errPromoCodeNotValid
+	}
+	if !promoCode.AppliesTo(resource) {
+		return errPromoCodeNotApplicable
+	}
+	if quote.Total < promoCode.MinSpend {
+		return errPromoCodeMinSpend
+	}
+	if promoCode.IsExhausted() {
+		return errPromoCodeExhausted
+	}
+	if promoCode.MaxRedemptionsPerUser > 0 {
+		count, err := promoCodeRepository.CountRedemptionsOfUser(promoCode.ID, userID)
+		if err != nil {
+			return err
+		}
+		if count >= int64(promoCode.MaxRedemptionsPerUser) {
+			return errPromoCodeLimitReached
+		}
+	}
+	return nil
+}
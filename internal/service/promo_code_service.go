@@ -0,0 +1,132 @@
+package service
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/logs"
+	"booking/internal/repository"
+	"booking/internal/vo"
+	"context"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type IPromoCodeService interface {
+	WithContext(ctx context.Context) IPromoCodeService
+	CreatePromoCode(username string, createPromoCodeDTO *dto.CreatePromoCodeDTO) (res vo.Response, statusCode int)
+	GetPromoCodes(username string) (res vo.Response, statusCode int)
+}
+
+type promoCodeService struct {
+	authRepository      repository.IAuthRepository
+	resourceRepository  repository.IResourceRepository
+	promoCodeRepository repository.IPromoCodeRepository
+	ctx                 context.Context
+}
+
+func NewPromoCodeService(authRepository repository.IAuthRepository, resourceRepository repository.IResourceRepository, promoCodeRepository repository.IPromoCodeRepository) *promoCodeService {
+	return &promoCodeService{authRepository: authRepository, resourceRepository: resourceRepository, promoCodeRepository: promoCodeRepository}
+}
+
+func (promoCodeService *promoCodeService) WithContext(ctx context.Context) IPromoCodeService {
+	service := *promoCodeService
+	service.ctx = ctx
+	service.resourceRepository = promoCodeService.resourceRepository.WithContext(ctx)
+	service.promoCodeRepository = promoCodeService.promoCodeRepository.WithContext(ctx)
+	return &service
+}
+
+// CreatePromoCode creates a promo code for the resources of the user, which
+// the code may be restricted to some of.
+func (promoCodeService *promoCodeService) CreatePromoCode(username string, createPromoCodeDTO *dto.CreatePromoCodeDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(promoCodeService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	if createPromoCodeDTO.DiscountType == entity.PROMO_DISCOUNT_PERCENT && createPromoCodeDTO.DiscountValue > 100 {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INVALID_PARAMETERS)
+		return res, fiber.StatusBadRequest
+	}
+	if createPromoCodeDTO.ValidFrom != nil && createPromoCodeDTO.ValidUntil != nil && !createPromoCodeDTO.ValidUntil.After(*createPromoCodeDTO.ValidFrom) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INVALID_PARAMETERS)
+		return res, fiber.StatusBadRequest
+	}
+
+	resourceIDs := entity.UUIDList{}
+	for _, id := range createPromoCodeDTO.ResourceIDs {
+		resource, errorMessage, statusCode := findManagedResource(promoCodeService.ctx, promoCodeService.resourceRepository, uuid.MustParse(id))
+		if resource == nil {
+			res.SetErrorMessage(errorMessage)
+			return res, statusCode
+		}
+		resourceIDs = append(resourceIDs, resource.ID)
+	}
+
+	code := normalizePromoCode(createPromoCodeDTO.Code)
+	_, err := promoCodeService.promoCodeRepository.FindPromoCodeByCode(code)
+	if err == nil {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_PROMO_CODE_ALREADY_EXISTS)
+		return res, fiber.StatusConflict
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	promoCode := entity.PromoCode{
+		OwnerID:               user.ID,
+		Code:                  code,
+		DiscountType:          createPromoCodeDTO.DiscountType,
+		DiscountValue:         createPromoCodeDTO.DiscountValue,
+		ValidFrom:             createPromoCodeDTO.ValidFrom,
+		ValidUntil:            createPromoCodeDTO.ValidUntil,
+		MinSpend:              createPromoCodeDTO.MinSpend,
+		MaxRedemptions:        createPromoCodeDTO.MaxRedemptions,
+		MaxRedemptionsPerUser: createPromoCodeDTO.MaxRedemptionsPerUser,
+		ResourceIDs:           resourceIDs,
+		Categories:            createPromoCodeDTO.Categories,
+	}
+	err = promoCodeService.promoCodeRepository.CreatePromoCode(&promoCode)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(vo.NewPromoCodeResponse(&promoCode))
+	return res, fiber.StatusCreated
+}
+
+func (promoCodeService *promoCodeService) GetPromoCodes(username string) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(promoCodeService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	if !isOrganizationAdmin(promoCodeService.ctx) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_FORBIDDEN)
+		return res, fiber.StatusForbidden
+	}
+
+	promoCodes, err := promoCodeService.promoCodeRepository.FindPromoCodes()
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	responses := []vo.PromoCodeResponse{}
+	for i := range promoCodes {
+		responses = append(responses, vo.NewPromoCodeResponse(&promoCodes[i]))
+	}
+	res.SetData(responses)
+	return res, fiber.StatusOK
+}
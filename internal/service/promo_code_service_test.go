@@ -0,0 +1,148 @@
+package service_test
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/payment"
+	"booking/internal/service"
+	"booking/internal/vo"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestCreateBookingWithPromoCode(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+	resource := &entity.Resource{ID: uuid.New(), OrganizationID: uuid.New(), HourlyRate: 50000, Capacity: 1, Category: "meeting-room"}
+	startAt := time.Now().Add(72 * time.Hour).Truncate(time.Hour)
+	body := dto.CreateBookingDTO{
+		ResourceID: resource.ID.String(),
+		StartAt:    startAt,
+		EndAt:      startAt.Add(2 * time.Hour),
+		PromoCode:  " save10 ",
+	}
+	newBookingService := func(bookingRepositoryMock *bookingRepositoryMock, promoCodeRepositoryMock *promoCodeRepositoryMock) service.IBookingService {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindCancellationPolicyByResourceID", resource.ID).Return(&entity.CancellationPolicy{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("FindPricingPolicyByResourceID", resource.ID).Return(&entity.PricingPolicy{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("FindPaymentScheduleByResourceID", resource.ID).Return(&entity.PaymentSchedule{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("IsResourceMember", resource.ID, user.ID).Return(false, nil)
+		bookingRepositoryMock.On("LockResourceByID", resource.ID).Return(resource, nil)
+		bookingRepositoryMock.On("FindOverlappingBookings", resource.ID, startAt).Return([]entity.Booking{}, nil)
+		return service.NewBookingService(authRepositoryMock, resourceRepositoryMock, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), &providerRepositoryMock{}, promoCodeRepositoryMock, newPaymentRepositoryMock(), newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret))
+	}
+
+	t.Run("When the code has been redeemed as many times as it can be, should response status code 409 with error message", func(t *testing.T) {
+		promoCode := &entity.PromoCode{ID: uuid.New(), OrganizationID: resource.OrganizationID, Code: "SAVE10", DiscountType: entity.PROMO_DISCOUNT_PERCENT, DiscountValue: 10, MaxRedemptions: 5, RedemptionCount: 5}
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		promoCodeRepositoryMock := &promoCodeRepositoryMock{}
+		promoCodeRepositoryMock.On("LockPromoCodeByCode", "SAVE10").Return(promoCode, nil)
+		bookingService := newBookingService(bookingRepositoryMock, promoCodeRepositoryMock)
+
+		res, statusCode := bookingService.CreateBooking(Username, &body)
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_PROMO_CODE_EXHAUSTED, res.ErrorMessage)
+		bookingRepositoryMock.AssertNotCalled(t, "CreateBooking", resource.ID)
+	})
+
+	t.Run("When the user has redeemed the code as many times as they can, should response status code 409 with error message", func(t *testing.T) {
+		promoCode := &entity.PromoCode{ID: uuid.New(), OrganizationID: resource.OrganizationID, Code: "SAVE10", DiscountType: entity.PROMO_DISCOUNT_PERCENT, DiscountValue: 10, MaxRedemptionsPerUser: 1}
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		promoCodeRepositoryMock := &promoCodeRepositoryMock{}
+		promoCodeRepositoryMock.On("LockPromoCodeByCode", "SAVE10").Return(promoCode, nil)
+		promoCodeRepositoryMock.On("CountRedemptionsOfUser", promoCode.ID, user.ID).Return(int64(1), nil)
+		bookingService := newBookingService(bookingRepositoryMock, promoCodeRepositoryMock)
+
+		res, statusCode := bookingService.CreateBooking(Username, &body)
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_PROMO_CODE_LIMIT_REACHED, res.ErrorMessage)
+		bookingRepositoryMock.AssertNotCalled(t, "CreateBooking", resource.ID)
+	})
+
+	t.Run("When the booking costs less than the minimum spend, should response status code 400 with error message", func(t *testing.T) {
+		promoCode := &entity.PromoCode{ID: uuid.New(), OrganizationID: resource.OrganizationID, Code: "SAVE10", DiscountType: entity.PROMO_DISCOUNT_PERCENT, DiscountValue: 10, MinSpend: 200000}
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		promoCodeRepositoryMock := &promoCodeRepositoryMock{}
+		promoCodeRepositoryMock.On("LockPromoCodeByCode", "SAVE10").Return(promoCode, nil)
+		bookingService := newBookingService(bookingRepositoryMock, promoCodeRepositoryMock)
+
+		res, statusCode := bookingService.CreateBooking(Username, &body)
+
+		assert.Equal(t, fiber.StatusBadRequest, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_PROMO_CODE_MIN_SPEND_NOT_MET, res.ErrorMessage)
+	})
+
+	t.Run("When the code is restricted to another category, should response status code 400 with error message", func(t *testing.T) {
+		promoCode := &entity.PromoCode{ID: uuid.New(), OrganizationID: resource.OrganizationID, Code: "SAVE10", DiscountType: entity.PROMO_DISCOUNT_PERCENT, DiscountValue: 10, Categories: entity.StringList{"court"}}
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		promoCodeRepositoryMock := &promoCodeRepositoryMock{}
+		promoCodeRepositoryMock.On("LockPromoCodeByCode", "SAVE10").Return(promoCode, nil)
+		bookingService := newBookingService(bookingRepositoryMock, promoCodeRepositoryMock)
+
+		res, statusCode := bookingService.CreateBooking(Username, &body)
+
+		assert.Equal(t, fiber.StatusBadRequest, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_PROMO_CODE_NOT_APPLICABLE, res.ErrorMessage)
+	})
+
+	t.Run("When the code applies, should discount the booking and redeem the code", func(t *testing.T) {
+		promoCode := &entity.PromoCode{ID: uuid.New(), OrganizationID: resource.OrganizationID, Code: "SAVE10", DiscountType: entity.PROMO_DISCOUNT_PERCENT, DiscountValue: 10, MaxRedemptions: 5, RedemptionCount: 4}
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("CreateBooking", resource.ID).Return(nil)
+		promoCodeRepositoryMock := &promoCodeRepositoryMock{}
+		promoCodeRepositoryMock.On("LockPromoCodeByCode", "SAVE10").Return(promoCode, nil)
+		promoCodeRepositoryMock.On("RedeemPromoCode", promoCode.ID).Return(nil)
+		bookingService := newBookingService(bookingRepositoryMock, promoCodeRepositoryMock)
+
+		res, statusCode := bookingService.CreateBooking(Username, &body)
+
+		isPass := assert.Equal(t, fiber.StatusCreated, statusCode)
+		if isPass {
+			booking := res.Data.(vo.BookingResponse)
+			assert.Equal(t, int64(90000), booking.Amount)
+			assert.Equal(t, "SAVE10", booking.Quote.PromoCode)
+			assert.Equal(t, entity.QUOTE_LINE_PROMO_CODE, booking.Quote.Lines[len(booking.Quote.Lines)-1].Type)
+			promoCodeRepositoryMock.AssertCalled(t, "RedeemPromoCode", promoCode.ID)
+		}
+	})
+}
+
+func TestCreatePromoCode(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+
+	t.Run("When the code is taken, should response status code 409 with error message", func(t *testing.T) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		promoCodeRepositoryMock := &promoCodeRepositoryMock{}
+		promoCodeRepositoryMock.On("FindPromoCodeByCode", "SAVE10").Return(&entity.PromoCode{}, nil)
+		promoCodeService := service.NewPromoCodeService(authRepositoryMock, &resourceRepositoryMock{}, promoCodeRepositoryMock)
+
+		res, statusCode := promoCodeService.CreatePromoCode(Username, &dto.CreatePromoCodeDTO{Code: "save10", DiscountType: entity.PROMO_DISCOUNT_FIXED, DiscountValue: 10000})
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_PROMO_CODE_ALREADY_EXISTS, res.ErrorMessage)
+		promoCodeRepositoryMock.AssertNotCalled(t, "CreatePromoCode", "SAVE10")
+	})
+
+	t.Run("When the percent is over 100, should response status code 400 with error message", func(t *testing.T) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		promoCodeService := service.NewPromoCodeService(authRepositoryMock, &resourceRepositoryMock{}, &promoCodeRepositoryMock{})
+
+		res, statusCode := promoCodeService.CreatePromoCode(Username, &dto.CreatePromoCodeDTO{Code: "save10", DiscountType: entity.PROMO_DISCOUNT_PERCENT, DiscountValue: 110})
+
+		assert.Equal(t, fiber.StatusBadRequest, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_INVALID_PARAMETERS, res.ErrorMessage)
+	})
+}
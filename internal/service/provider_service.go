@@ -0,0 +1,509 @@
+package service
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/logs"
+	"booking/internal/repository"
+	"booking/internal/schedule"
+	"booking/internal/vo"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+type IProviderService interface {
+	WithContext(ctx context.Context) IProviderService
+	CreateProvider(createProviderDTO *dto.CreateProviderDTO) (res vo.Response, statusCode int)
+	GetProviders() (res vo.Response, statusCode int)
+	GetProvider(providerID uuid.UUID) (res vo.Response, statusCode int)
+	UpdateProviderHours(providerID uuid.UUID, updateProviderHoursDTO *dto.UpdateProviderHoursDTO) (res vo.Response, statusCode int)
+	CreateProviderTimeOff(providerID uuid.UUID, createProviderTimeOffDTO *dto.CreateProviderTimeOffDTO) (res vo.Response, statusCode int)
+	DeleteProviderTimeOff(providerID uuid.UUID, timeOffID uuid.UUID) (res vo.Response, statusCode int)
+	CreateService(createServiceDTO *dto.CreateServiceDTO) (res vo.Response, statusCode int)
+	GetServices() (res vo.Response, statusCode int)
+	SaveProviderService(providerID uuid.UUID, serviceID uuid.UUID, saveProviderServiceDTO *dto.SaveProviderServiceDTO) (res vo.Response, statusCode int)
+	RemoveProviderService(providerID uuid.UUID, serviceID uuid.UUID) (res vo.Response, statusCode int)
+	GetAppointmentSlots(username string, serviceID uuid.UUID, appointmentSlotQueryDTO *dto.AppointmentSlotQueryDTO) (res vo.Response, statusCode int)
+}
+
+type providerService struct {
+	authRepository     repository.IAuthRepository
+	resourceRepository repository.IResourceRepository
+	bookingRepository  repository.IBookingRepository
+	waitlistRepository repository.IWaitlistRepository
+	scheduleRepository repository.IScheduleRepository
+	providerRepository repository.IProviderRepository
+}
+
+func NewProviderService(authRepository repository.IAuthRepository, resourceRepository repository.IResourceRepository, bookingRepository repository.IBookingRepository, waitlistRepository repository.IWaitlistRepository, scheduleRepository repository.IScheduleRepository, providerRepository repository.IProviderRepository) *providerService {
+	return &providerService{authRepository: authRepository, resourceRepository: resourceRepository, bookingRepository: bookingRepository, waitlistRepository: waitlistRepository, scheduleRepository: scheduleRepository, providerRepository: providerRepository}
+}
+
+func (providerService *providerService) WithContext(ctx context.Context) IProviderService {
+	service := *providerService
+	service.resourceRepository = providerService.resourceRepository.WithContext(ctx)
+	service.bookingRepository = providerService.bookingRepository.WithContext(ctx)
+	service.waitlistRepository = providerService.waitlistRepository.WithContext(ctx)
+	service.providerRepository = providerService.providerRepository.WithContext(ctx)
+	return &service
+}
+
+// findProvider returns the provider, or the error message and status code to
+// respond with.
+func (providerService *providerService) findProvider(providerID uuid.UUID) (provider *entity.Provider, errorMessage string, statusCode int) {
+	provider, err := providerService.providerRepository.FindProviderByID(providerID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, constant.ERROR_MESSAGE_PROVIDER_NOT_FOUND, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		return nil, constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR, fiber.StatusInternalServerError
+	}
+	return provider, "", fiber.StatusOK
+}
+
+// newProviderResponse loads the working hours and the upcoming time off of the
+// provider into their response.
+func (providerService *providerService) newProviderResponse(provider *entity.Provider, now time.Time) (vo.ProviderResponse, error) {
+	response := vo.ProviderResponse{
+		ID:       provider.ID,
+		UserID:   provider.UserID,
+		Name:     provider.Name,
+		TimeZone: provider.Location().String(),
+		Hours:    []vo.WeekdayHoursResponse{},
+		TimeOff:  []vo.ProviderTimeOffResponse{},
+	}
+	hours, err := providerService.providerRepository.FindProviderHours(provider.ID)
+	if err != nil {
+		return response, err
+	}
+	for _, weekdayHours := range hours {
+		response.Hours = append(response.Hours, vo.WeekdayHoursResponse{
+			Weekday: weekdayHours.Weekday,
+			Periods: newOpeningPeriodResponses(weekdayHours.Periods),
+		})
+	}
+	timeOff, err := providerService.providerRepository.FindProviderTimeOff(provider.ID, now, now.AddDate(1, 0, 0))
+	if err != nil {
+		return response, err
+	}
+	for i := range timeOff {
+		response.TimeOff = append(response.TimeOff, newProviderTimeOffResponse(&timeOff[i]))
+	}
+	return response, nil
+}
+
+func newProviderTimeOffResponse(timeOff *entity.ProviderTimeOff) vo.ProviderTimeOffResponse {
+	return vo.ProviderTimeOffResponse{
+		ID:      timeOff.ID,
+		StartAt: timeOff.StartAt,
+		EndAt:   timeOff.EndAt,
+		Reason:  timeOff.Reason,
+	}
+}
+
+func newServiceResponse(service *entity.Service) vo.ServiceResponse {
+	return vo.ServiceResponse{
+		ID:              service.ID,
+		ResourceID:      service.ResourceID,
+		Name:            service.Name,
+		DurationMinutes: service.DurationMinutes,
+	}
+}
+
+func (providerService *providerService) CreateProvider(createProviderDTO *dto.CreateProviderDTO) (res vo.Response, statusCode int) {
+	provider := entity.Provider{Name: createProviderDTO.Name, TimeZone: constant.DEFAULT_TIME_ZONE}
+	if createProviderDTO.Username != "" {
+		user, err := providerService.authRepository.FindUserByUsername(createProviderDTO.Username)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			res.SetErrorMessage(constant.ERROR_MESSAGE_USER_NOT_FOUND)
+			return res, fiber.StatusNotFound
+		}
+		if err != nil {
+			logs.Error(err)
+			res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+			return res, fiber.StatusInternalServerError
+		}
+		provider.UserID = &user.ID
+	}
+
+	err := providerService.providerRepository.CreateProvider(&provider)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	response, err := providerService.newProviderResponse(&provider, time.Now())
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	res.SetData(response)
+	return res, fiber.StatusCreated
+}
+
+func (providerService *providerService) GetProviders() (res vo.Response, statusCode int) {
+	providers, err := providerService.providerRepository.FindProviders()
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	now := time.Now()
+	responses := []vo.ProviderResponse{}
+	for i := range providers {
+		response, err := providerService.newProviderResponse(&providers[i], now)
+		if err != nil {
+			logs.Error(err)
+			res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+			return res, fiber.StatusInternalServerError
+		}
+		responses = append(responses, response)
+	}
+	res.SetData(responses)
+	return res, fiber.StatusOK
+}
+
+// GetProvider returns the provider with their working hours and the time off
+// of the coming year.
+func (providerService *providerService) GetProvider(providerID uuid.UUID) (res vo.Response, statusCode int) {
+	provider, errorMessage, statusCode := providerService.findProvider(providerID)
+	if provider == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	response, err := providerService.newProviderResponse(provider, time.Now())
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	res.SetData(response)
+	return res, fiber.StatusOK
+}
+
+// UpdateProviderHours replaces the weekly working hours and time zone of the
+// provider. Appointments already made are kept.
+func (providerService *providerService) UpdateProviderHours(providerID uuid.UUID, updateProviderHoursDTO *dto.UpdateProviderHoursDTO) (res vo.Response, statusCode int) {
+	provider, errorMessage, statusCode := providerService.findProvider(providerID)
+	if provider == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	timeZone := updateProviderHoursDTO.TimeZone
+	if timeZone == "" {
+		timeZone = constant.DEFAULT_TIME_ZONE
+	}
+	if _, err := time.LoadLocation(timeZone); err != nil {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INVALID_TIME_ZONE)
+		return res, fiber.StatusBadRequest
+	}
+
+	hours := []entity.ProviderHours{}
+	weekdays := map[int]bool{}
+	for _, weekdayHours := range updateProviderHoursDTO.Hours {
+		periods, err := parseOpeningPeriods(weekdayHours.Periods)
+		if err != nil || weekdays[weekdayHours.Weekday] {
+			res.SetErrorMessage(constant.ERROR_MESSAGE_INVALID_OPENING_HOURS)
+			return res, fiber.StatusBadRequest
+		}
+		weekdays[weekdayHours.Weekday] = true
+		hours = append(hours, entity.ProviderHours{ProviderID: providerID, Weekday: weekdayHours.Weekday, Periods: periods})
+	}
+
+	provider.TimeZone = timeZone
+	err := providerService.providerRepository.ReplaceProviderHours(provider, hours)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	return providerService.GetProvider(providerID)
+}
+
+func (providerService *providerService) CreateProviderTimeOff(providerID uuid.UUID, createProviderTimeOffDTO *dto.CreateProviderTimeOffDTO) (res vo.Response, statusCode int) {
+	provider, errorMessage, statusCode := providerService.findProvider(providerID)
+	if provider == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	timeOff := entity.ProviderTimeOff{
+		ProviderID: provider.ID,
+		StartAt:    createProviderTimeOffDTO.StartAt,
+		EndAt:      createProviderTimeOffDTO.EndAt,
+		Reason:     createProviderTimeOffDTO.Reason,
+	}
+	err := providerService.providerRepository.CreateProviderTimeOff(&timeOff)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(newProviderTimeOffResponse(&timeOff))
+	return res, fiber.StatusCreated
+}
+
+func (providerService *providerService) DeleteProviderTimeOff(providerID uuid.UUID, timeOffID uuid.UUID) (res vo.Response, statusCode int) {
+	provider, errorMessage, statusCode := providerService.findProvider(providerID)
+	if provider == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	deleted, err := providerService.providerRepository.DeleteProviderTimeOff(provider.ID, timeOffID)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	if deleted == 0 {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_PROVIDER_TIME_OFF_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+
+	res.SetData(nil)
+	return res, fiber.StatusOK
+}
+
+func (providerService *providerService) CreateService(createServiceDTO *dto.CreateServiceDTO) (res vo.Response, statusCode int) {
+	resource, err := providerService.resourceRepository.FindResourceByID(uuid.MustParse(createServiceDTO.ResourceID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_RESOURCE_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	service := entity.Service{ResourceID: resource.ID, Name: createServiceDTO.Name, DurationMinutes: createServiceDTO.DurationMinutes}
+	err = providerService.providerRepository.CreateService(&service)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(newServiceResponse(&service))
+	return res, fiber.StatusCreated
+}
+
+func (providerService *providerService) GetServices() (res vo.Response, statusCode int) {
+	services, err := providerService.providerRepository.FindServices()
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	responses := []vo.ServiceResponse{}
+	for i := range services {
+		responses = append(responses, newServiceResponse(&services[i]))
+	}
+	res.SetData(responses)
+	return res, fiber.StatusOK
+}
+
+// SaveProviderService lets the provider perform the service, in the time they
+// take for it.
+func (providerService *providerService) SaveProviderService(providerID uuid.UUID, serviceID uuid.UUID, saveProviderServiceDTO *dto.SaveProviderServiceDTO) (res vo.Response, statusCode int) {
+	provider, errorMessage, statusCode := providerService.findProvider(providerID)
+	if provider == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+	service, err := providerService.providerRepository.FindServiceByID(serviceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_SERVICE_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	providerServiceEntry := entity.ProviderService{ProviderID: provider.ID, ServiceID: service.ID, DurationMinutes: saveProviderServiceDTO.DurationMinutes}
+	err = providerService.providerRepository.SaveProviderService(&providerServiceEntry)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(vo.ProviderServiceResponse{
+		ProviderID:      provider.ID,
+		ServiceID:       service.ID,
+		DurationMinutes: int(providerServiceEntry.Duration(service) / time.Minute),
+	})
+	return res, fiber.StatusOK
+}
+
+func (providerService *providerService) RemoveProviderService(providerID uuid.UUID, serviceID uuid.UUID) (res vo.Response, statusCode int) {
+	provider, errorMessage, statusCode := providerService.findProvider(providerID)
+	if provider == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	deleted, err := providerService.providerRepository.RemoveProviderService(provider.ID, serviceID)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	if deleted == 0 {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_PROVIDER_SERVICE_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+
+	res.SetData(nil)
+	return res, fiber.StatusOK
+}
+
+// GetAppointmentSlots lists the times of the local date the service can be
+// booked at, each with the providers free for as long as they take and a seat
+// of the resource free for that time. Slots start every
+// booking.appointment.slot_minutes from midnight, or every duration of the
+// service when it isn't set.
+func (providerService *providerService) GetAppointmentSlots(username string, serviceID uuid.UUID, appointmentSlotQueryDTO *dto.AppointmentSlotQueryDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(providerService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	var providerID *uuid.UUID
+	if appointmentSlotQueryDTO.ProviderID != "" {
+		id := uuid.MustParse(appointmentSlotQueryDTO.ProviderID)
+		providerID = &id
+	}
+	appointment, err := findAppointment(providerService.providerRepository, serviceID, providerID)
+	if errors.Is(err, errProviderUnavailable) {
+		res.SetData([]vo.AppointmentSlotResponse{})
+		return res, fiber.StatusOK
+	}
+	if err != nil {
+		return res, setBookingError(&res, err)
+	}
+	resource, err := providerService.resourceRepository.FindResourceByID(appointment.service.ResourceID)
+	if err != nil {
+		return res, setBookingError(&res, err)
+	}
+
+	now := time.Now()
+	location := resource.Location()
+	dayStartAt, err := time.ParseInLocation(schedule.DATE_LAYOUT, appointmentSlotQueryDTO.Date, location)
+	if err != nil {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INVALID_PARAMETERS)
+		return res, fiber.StatusBadRequest
+	}
+	dayEndAt := dayStartAt.AddDate(0, 0, 1)
+	searchEndAt := dayEndAt.Add(appointment.longest())
+
+	slots, err := providerService.findAppointmentSlots(resource, appointment, dayStartAt, dayEndAt, searchEndAt, now, user.ID)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	res.SetData(slots)
+	return res, fiber.StatusOK
+}
+
+func (providerService *providerService) findAppointmentSlots(resource *entity.Resource, appointment *appointment, dayStartAt time.Time, dayEndAt time.Time, searchEndAt time.Time, now time.Time, userID uuid.UUID) ([]vo.AppointmentSlotResponse, error) {
+	resourceSchedule, err := loadSchedule(providerService.scheduleRepository, resource, dayStartAt, searchEndAt)
+	if err != nil {
+		return nil, err
+	}
+	bookings, err := providerService.bookingRepository.FindOverlappingBookings(resource.ID, dayStartAt, searchEndAt)
+	if err != nil {
+		return nil, err
+	}
+	offers, err := providerService.waitlistRepository.FindActiveOffers(resource.ID, dayStartAt, searchEndAt, now, userID)
+	if err != nil {
+		return nil, err
+	}
+	seats := []entity.SeatPeriod{}
+	for i := range bookings {
+		seats = append(seats, bookings[i].SeatPeriod())
+	}
+	for i := range offers {
+		seats = append(seats, offers[i].SeatPeriod())
+	}
+
+	providerIDs := []uuid.UUID{}
+	for _, providerService := range appointment.providers {
+		providerIDs = append(providerIDs, providerService.ProviderID)
+	}
+	appointments, err := providerService.bookingRepository.FindProviderBookings(providerIDs, dayStartAt, searchEndAt)
+	if err != nil {
+		return nil, err
+	}
+	appointmentsOf := map[uuid.UUID][]entity.Booking{}
+	for _, other := range appointments {
+		appointmentsOf[*other.ProviderID] = append(appointmentsOf[*other.ProviderID], other)
+	}
+	type candidate struct {
+		provider *entity.Provider
+		schedule *schedule.Schedule
+		duration time.Duration
+	}
+	candidates := []candidate{}
+	for i := range appointment.providers {
+		provider, err := providerService.providerRepository.FindProviderByID(appointment.providers[i].ProviderID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		providerSchedule, err := loadProviderSchedule(providerService.providerRepository, provider, appointmentsOf[provider.ID], dayStartAt, searchEndAt)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, candidate{provider: provider, schedule: providerSchedule, duration: appointment.providers[i].Duration(appointment.service)})
+	}
+
+	step := time.Duration(viper.GetInt("booking.appointment.slot_minutes")) * time.Minute
+	if step <= 0 {
+		step = time.Duration(appointment.service.DurationMinutes) * time.Minute
+	}
+	slots := []vo.AppointmentSlotResponse{}
+	for startAt := dayStartAt; startAt.Before(dayEndAt) && step > 0; startAt = startAt.Add(step) {
+		if !startAt.After(now) {
+			continue
+		}
+		slot := vo.AppointmentSlotResponse{StartAt: startAt, Providers: []vo.AppointmentProviderResponse{}}
+		for _, candidate := range candidates {
+			endAt := startAt.Add(candidate.duration)
+			if resourceSchedule.Check(startAt, endAt) != nil || resource.Capacity-entity.PeakSeats(seats, startAt, endAt) < 1 {
+				continue
+			}
+			if candidate.schedule.Check(startAt, endAt) != nil {
+				continue
+			}
+			slot.Providers = append(slot.Providers, vo.AppointmentProviderResponse{ProviderID: candidate.provider.ID, Name: candidate.provider.Name, EndAt: endAt})
+		}
+		if len(slot.Providers) > 0 {
+			slots = append(slots, slot)
+		}
+	}
+	return slots, nil
+}
@@ -0,0 +1,101 @@
+package service_test
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/service"
+	"booking/internal/vo"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestGetAppointmentSlots(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+	resource := &entity.Resource{ID: uuid.New(), Capacity: 1, TimeZone: constant.DEFAULT_TIME_ZONE}
+	haircut := &entity.Service{ID: uuid.New(), ResourceID: resource.ID, Name: "Haircut", DurationMinutes: 60}
+	providers := []entity.Provider{{ID: uuid.New(), Name: "Somchai", TimeZone: constant.DEFAULT_TIME_ZONE}, {ID: uuid.New(), Name: "Malee", TimeZone: constant.DEFAULT_TIME_ZONE}}
+	providerServices := []entity.ProviderService{
+		{ProviderID: providers[0].ID, ServiceID: haircut.ID},
+		{ProviderID: providers[1].ID, ServiceID: haircut.ID},
+	}
+	location := resource.Location()
+	day := time.Now().In(location).AddDate(0, 0, 7)
+	dayStartAt := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, location)
+	query := dto.AppointmentSlotQueryDTO{Date: dayStartAt.Format("2006-01-02")}
+	newProviderService := func(bookings []entity.Booking) service.IProviderService {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindResourceByID", resource.ID).Return(resource, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("FindOverlappingBookings", resource.ID, mock.Anything).Return(bookings, nil)
+		bookingRepositoryMock.On("FindProviderBookings", mock.Anything).Return([]entity.Booking{}, nil)
+		providerRepositoryMock := &providerRepositoryMock{}
+		providerRepositoryMock.On("FindServiceByID", haircut.ID).Return(haircut, nil)
+		providerRepositoryMock.On("FindProviderServicesByServiceID", haircut.ID).Return(providerServices, nil)
+		providerRepositoryMock.On("FindProviderByID", providers[0].ID).Return(&providers[0], nil)
+		providerRepositoryMock.On("FindProviderByID", providers[1].ID).Return(&providers[1], nil)
+		// the first provider works 09:00-12:00, the second 10:00-12:00
+		providerRepositoryMock.On("FindProviderHours", providers[0].ID).Return([]entity.ProviderHours{{ProviderID: providers[0].ID, Weekday: int(dayStartAt.Weekday()), Periods: entity.OpeningPeriods{{OpensAt: 9 * 60, ClosesAt: 12 * 60}}}}, nil)
+		providerRepositoryMock.On("FindProviderHours", providers[1].ID).Return([]entity.ProviderHours{{ProviderID: providers[1].ID, Weekday: int(dayStartAt.Weekday()), Periods: entity.OpeningPeriods{{OpensAt: 10 * 60, ClosesAt: 12 * 60}}}}, nil)
+		providerRepositoryMock.On("FindProviderTimeOff", mock.Anything).Return([]entity.ProviderTimeOff{}, nil)
+		return service.NewProviderService(authRepositoryMock, resourceRepositoryMock, bookingRepositoryMock, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), providerRepositoryMock)
+	}
+
+	t.Run("When the providers work different hours, should list each slot with the providers free for it", func(t *testing.T) {
+		providerService := newProviderService([]entity.Booking{})
+
+		res, statusCode := providerService.GetAppointmentSlots(Username, haircut.ID, &query)
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			slots := res.Data.([]vo.AppointmentSlotResponse)
+			// haircuts start every hour as no slot step is configured
+			if assert.Len(t, slots, 3) {
+				assert.Equal(t, dayStartAt.Add(9*time.Hour), slots[0].StartAt)
+				assert.Len(t, slots[0].Providers, 1)
+				assert.Equal(t, providers[0].ID, slots[0].Providers[0].ProviderID)
+				assert.Len(t, slots[1].Providers, 2)
+				assert.Equal(t, dayStartAt.Add(12*time.Hour), slots[2].Providers[1].EndAt)
+			}
+		}
+	})
+
+	t.Run("When the resource is fully booked at a time, should leave out the slot", func(t *testing.T) {
+		booked := []entity.Booking{{ID: uuid.New(), StartAt: dayStartAt.Add(10 * time.Hour), EndAt: dayStartAt.Add(11 * time.Hour), Seats: 1}}
+		providerService := newProviderService(booked)
+
+		res, statusCode := providerService.GetAppointmentSlots(Username, haircut.ID, &query)
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			slots := res.Data.([]vo.AppointmentSlotResponse)
+			if assert.Len(t, slots, 2) {
+				assert.Equal(t, dayStartAt.Add(9*time.Hour), slots[0].StartAt)
+				assert.Equal(t, dayStartAt.Add(11*time.Hour), slots[1].StartAt)
+			}
+		}
+	})
+
+	t.Run("When the service does not exist, should response status code 404 with error message", func(t *testing.T) {
+		serviceID := uuid.New()
+		providerRepositoryMock := &providerRepositoryMock{}
+		providerRepositoryMock.On("FindServiceByID", serviceID).Return(&entity.Service{}, gorm.ErrRecordNotFound)
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		providerService := service.NewProviderService(authRepositoryMock, &resourceRepositoryMock{}, &bookingRepositoryMock{}, newWaitlistRepositoryMock(), newScheduleRepositoryMock(), providerRepositoryMock)
+
+		res, statusCode := providerService.GetAppointmentSlots(Username, serviceID, &query)
+
+		assert.Equal(t, fiber.StatusNotFound, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_SERVICE_NOT_FOUND, res.ErrorMessage)
+	})
+}
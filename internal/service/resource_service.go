@@ -0,0 +1,443 @@
+package service
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/logs"
+	"booking/internal/repository"
+	"booking/internal/vo"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type IResourceService interface {
+	WithContext(ctx context.Context) IResourceService
+	CreateResource(username string, createResourceDTO *dto.CreateResourceDTO) (res vo.Response, statusCode int)
+	GetResource(resourceID uuid.UUID) (res vo.Response, statusCode int)
+	SearchResources(resourceSearchQueryDTO *dto.ResourceSearchQueryDTO) (res vo.Response, statusCode int)
+	GetCancellationPolicy(resourceID uuid.UUID) (res vo.Response, statusCode int)
+	SaveCancellationPolicy(username string, resourceID uuid.UUID, cancellationPolicyDTO *dto.CancellationPolicyDTO) (res vo.Response, statusCode int)
+	GetReschedulePolicy(resourceID uuid.UUID) (res vo.Response, statusCode int)
+	SaveReschedulePolicy(username string, resourceID uuid.UUID, reschedulePolicyDTO *dto.ReschedulePolicyDTO) (res vo.Response, statusCode int)
+	GetPaymentSchedule(resourceID uuid.UUID) (res vo.Response, statusCode int)
+	SavePaymentSchedule(username string, resourceID uuid.UUID, paymentScheduleDTO *dto.PaymentScheduleDTO) (res vo.Response, statusCode int)
+}
+
+// RESOURCE_PAGE_SIZE is how many resources a page of search results has by
+// default.
+const RESOURCE_PAGE_SIZE = 20
+
+// AVAILABILITY_BATCH_SIZE is how many resources are read at a time to check
+// their schedules when searching resources available in a period.
+const AVAILABILITY_BATCH_SIZE = 100
+
+type resourceService struct {
+	authRepository     repository.IAuthRepository
+	resourceRepository repository.IResourceRepository
+	scheduleRepository repository.IScheduleRepository
+	ctx                context.Context
+}
+
+func NewResourceService(authRepository repository.IAuthRepository, resourceRepository repository.IResourceRepository, scheduleRepository repository.IScheduleRepository) *resourceService {
+	return &resourceService{authRepository: authRepository, resourceRepository: resourceRepository, scheduleRepository: scheduleRepository}
+}
+
+// WithContext returns the service with its queries scoped to the
+// organization of the context.
+func (resourceService *resourceService) WithContext(ctx context.Context) IResourceService {
+	service := *resourceService
+	service.ctx = ctx
+	service.resourceRepository = resourceService.resourceRepository.WithContext(ctx)
+	return &service
+}
+
+func newResourceResponse(resource *entity.Resource) vo.ResourceResponse {
+	return vo.ResourceResponse{
+		ID:              resource.ID,
+		OrganizationID:  resource.OrganizationID,
+		OwnerID:         resource.OwnerID,
+		Name:            resource.Name,
+		Description:     resource.Description,
+		Category:        resource.Category,
+		Amenities:       resource.Amenities,
+		HourlyRate:      resource.HourlyRate,
+		Address:         resource.Address,
+		Latitude:        resource.Latitude,
+		Longitude:       resource.Longitude,
+		Capacity:        resource.Capacity,
+		TimeZone:        resource.TimeZone,
+		HolidayCalendar: resource.HolidayCalendarCode,
+		RatingAverage:   resource.RatingAverage,
+		RatingCount:     resource.RatingCount,
+		CreatedAt:       resource.CreatedAt,
+	}
+}
+
+func (resourceService *resourceService) CreateResource(username string, createResourceDTO *dto.CreateResourceDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(resourceService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	timeZone := createResourceDTO.TimeZone
+	if timeZone == "" {
+		timeZone = constant.DEFAULT_TIME_ZONE
+	}
+	if _, err := time.LoadLocation(timeZone); err != nil {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INVALID_TIME_ZONE)
+		return res, fiber.StatusBadRequest
+	}
+
+	resource := entity.Resource{
+		OwnerID:     user.ID,
+		Name:        createResourceDTO.Name,
+		Description: createResourceDTO.Description,
+		Category:    createResourceDTO.Category,
+		Amenities:   normalizeAmenities(createResourceDTO.Amenities),
+		HourlyRate:  createResourceDTO.HourlyRate,
+		Address:     createResourceDTO.Address,
+		Latitude:    createResourceDTO.Latitude,
+		Longitude:   createResourceDTO.Longitude,
+		Capacity:    max(createResourceDTO.Capacity, 1),
+		TimeZone:    timeZone,
+	}
+	err := resourceService.resourceRepository.CreateResource(&resource)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(newResourceResponse(&resource))
+	return res, fiber.StatusCreated
+}
+
+func (resourceService *resourceService) GetResource(resourceID uuid.UUID) (res vo.Response, statusCode int) {
+	resource, err := resourceService.resourceRepository.FindResourceByID(resourceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_RESOURCE_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(newResourceResponse(resource))
+	return res, fiber.StatusOK
+}
+
+// normalizeAmenities returns the amenities trimmed, in lower case and without
+// duplicates, as they are stored and searched.
+func normalizeAmenities(amenities []string) entity.StringList {
+	normalized := entity.StringList{}
+	for _, amenity := range amenities {
+		amenity = strings.ToLower(strings.TrimSpace(amenity))
+		if amenity != "" && !slices.Contains(normalized, amenity) {
+			normalized = append(normalized, amenity)
+		}
+	}
+	return normalized
+}
+
+func (resourceService *resourceService) SearchResources(resourceSearchQueryDTO *dto.ResourceSearchQueryDTO) (res vo.Response, statusCode int) {
+	search := repository.ResourceSearch{
+		Query:     strings.TrimSpace(resourceSearchQueryDTO.Q),
+		Category:  resourceSearchQueryDTO.Category,
+		Capacity:  resourceSearchQueryDTO.Capacity,
+		MinPrice:  resourceSearchQueryDTO.MinPrice,
+		MaxPrice:  resourceSearchQueryDTO.MaxPrice,
+		Amenities: normalizeAmenities(resourceSearchQueryDTO.Amenities),
+		MinRating: resourceSearchQueryDTO.MinRating,
+		RadiusKm:  resourceSearchQueryDTO.RadiusKm,
+		Sort:      resourceSearchQueryDTO.Sort,
+	}
+	if resourceSearchQueryDTO.Lat != nil && resourceSearchQueryDTO.Lng != nil {
+		search.Near = &entity.GeoPoint{Latitude: *resourceSearchQueryDTO.Lat, Longitude: *resourceSearchQueryDTO.Lng}
+	}
+	if resourceSearchQueryDTO.MinLat != nil && resourceSearchQueryDTO.MaxLat != nil && resourceSearchQueryDTO.MinLng != nil && resourceSearchQueryDTO.MaxLng != nil {
+		search.Bounds = &repository.GeoBounds{
+			MinLatitude:  *resourceSearchQueryDTO.MinLat,
+			MaxLatitude:  *resourceSearchQueryDTO.MaxLat,
+			MinLongitude: *resourceSearchQueryDTO.MinLng,
+			MaxLongitude: *resourceSearchQueryDTO.MaxLng,
+		}
+	}
+	if search.Sort == "" && search.Query != "" {
+		search.Sort = repository.RESOURCE_SORT_RELEVANCE
+	} else if search.Sort == "" && search.Near != nil {
+		search.Sort = repository.RESOURCE_SORT_DISTANCE
+	}
+	if (search.MinPrice != nil && search.MaxPrice != nil && *search.MinPrice > *search.MaxPrice) ||
+		(search.Bounds != nil && search.Bounds.MinLatitude > search.Bounds.MaxLatitude) ||
+		(search.Sort == repository.RESOURCE_SORT_DISTANCE && search.Near == nil) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INVALID_PARAMETERS)
+		return res, fiber.StatusBadRequest
+	}
+	if resourceSearchQueryDTO.From != "" {
+		from, fromErr := time.Parse(time.RFC3339, resourceSearchQueryDTO.From)
+		to, toErr := time.Parse(time.RFC3339, resourceSearchQueryDTO.To)
+		if fromErr != nil || toErr != nil || !to.After(from) {
+			res.SetErrorMessage(constant.ERROR_MESSAGE_INVALID_PARAMETERS)
+			return res, fiber.StatusBadRequest
+		}
+		search.AvailableFrom, search.AvailableTo = &from, &to
+	}
+
+	page, size := resourceSearchQueryDTO.Page, resourceSearchQueryDTO.Size
+	if page == 0 {
+		page = 1
+	}
+	if size == 0 {
+		size = RESOURCE_PAGE_SIZE
+	}
+	var resources []entity.Resource
+	var total int64
+	var err error
+	if search.AvailableFrom != nil {
+		resources, total, err = resourceService.searchAvailableResources(search, int((page-1)*size), int(size))
+	} else {
+		resources, total, err = resourceService.resourceRepository.SearchResources(search, int((page-1)*size), int(size))
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	responses := []vo.ResourceResponse{}
+	for i := range resources {
+		response := newResourceResponse(&resources[i])
+		if point, ok := resources[i].GeoPoint(); ok && search.Near != nil {
+			distanceKm := search.Near.DistanceKm(point)
+			response.DistanceKm = &distanceKm
+		}
+		responses = append(responses, response)
+	}
+	res.SetDataWithPagination(responses, page, size, uint(total))
+	return res, fiber.StatusOK
+}
+
+// searchAvailableResources returns a page of the resources matching the
+// search which are open for the whole period under their opening hours, date
+// overrides and holidays, as booking checks them. The schedules can't be
+// checked in the query, so every resource it finds is checked to count them.
+func (resourceService *resourceService) searchAvailableResources(search repository.ResourceSearch, offset int, limit int) (resources []entity.Resource, total int64, err error) {
+	resources = []entity.Resource{}
+	for batchOffset := 0; ; batchOffset += AVAILABILITY_BATCH_SIZE {
+		batch, _, err := resourceService.resourceRepository.SearchResources(search, batchOffset, AVAILABILITY_BATCH_SIZE)
+		if err != nil {
+			return nil, 0, err
+		}
+		for i := range batch {
+			resourceSchedule, err := loadSchedule(resourceService.scheduleRepository, &batch[i], *search.AvailableFrom, *search.AvailableTo)
+			if err != nil {
+				return nil, 0, err
+			}
+			if resourceSchedule.Check(*search.AvailableFrom, *search.AvailableTo) != nil {
+				continue
+			}
+			if total >= int64(offset) && len(resources) < limit {
+				resources = append(resources, batch[i])
+			}
+			total++
+		}
+		if len(batch) < AVAILABILITY_BATCH_SIZE {
+			return resources, total, nil
+		}
+	}
+}
+
+func (resourceService *resourceService) GetCancellationPolicy(resourceID uuid.UUID) (res vo.Response, statusCode int) {
+	policy, err := resourceService.resourceRepository.FindCancellationPolicyByResourceID(resourceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// a resource without a policy never refunds
+		res.SetData(vo.CancellationPolicyResponse{ResourceID: resourceID, Rules: []entity.CancellationRule{}})
+		return res, fiber.StatusOK
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(vo.CancellationPolicyResponse{ResourceID: resourceID, Rules: policy.Rules})
+	return res, fiber.StatusOK
+}
+
+func (resourceService *resourceService) SaveCancellationPolicy(username string, resourceID uuid.UUID, cancellationPolicyDTO *dto.CancellationPolicyDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(resourceService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	resource, errorMessage, statusCode := findManagedResource(resourceService.ctx, resourceService.resourceRepository, resourceID)
+	if resource == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	policy, err := resourceService.resourceRepository.FindCancellationPolicyByResourceID(resourceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		policy = &entity.CancellationPolicy{ResourceID: resourceID}
+	} else if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	policy.Rules = entity.CancellationRules{}
+	for _, rule := range cancellationPolicyDTO.Rules {
+		policy.Rules = append(policy.Rules, entity.CancellationRule{
+			HoursBeforeStart: rule.HoursBeforeStart,
+			RefundPercent:    rule.RefundPercent,
+		})
+	}
+
+	err = resourceService.resourceRepository.SaveCancellationPolicy(policy)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(vo.CancellationPolicyResponse{ResourceID: resourceID, Rules: policy.Rules})
+	return res, fiber.StatusOK
+}
+
+func (resourceService *resourceService) GetReschedulePolicy(resourceID uuid.UUID) (res vo.Response, statusCode int) {
+	policy, err := resourceService.resourceRepository.FindReschedulePolicyByResourceID(resourceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// a resource without a policy lets bookings be moved until they start
+		res.SetData(vo.ReschedulePolicyResponse{ResourceID: resourceID})
+		return res, fiber.StatusOK
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(vo.ReschedulePolicyResponse{ResourceID: resourceID, Rules: policy.Rules})
+	return res, fiber.StatusOK
+}
+
+func (resourceService *resourceService) SaveReschedulePolicy(username string, resourceID uuid.UUID, reschedulePolicyDTO *dto.ReschedulePolicyDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(resourceService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	resource, errorMessage, statusCode := findManagedResource(resourceService.ctx, resourceService.resourceRepository, resourceID)
+	if resource == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	policy, err := resourceService.resourceRepository.FindReschedulePolicyByResourceID(resourceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		policy = &entity.ReschedulePolicy{ResourceID: resourceID}
+	} else if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	policy.Rules = entity.RescheduleRules{
+		Disabled:       reschedulePolicyDTO.Disabled,
+		CutoffHours:    reschedulePolicyDTO.CutoffHours,
+		MaxReschedules: reschedulePolicyDTO.MaxReschedules,
+	}
+	err = resourceService.resourceRepository.SaveReschedulePolicy(policy)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(vo.ReschedulePolicyResponse{ResourceID: resourceID, Rules: policy.Rules})
+	return res, fiber.StatusOK
+}
+
+func (resourceService *resourceService) GetPaymentSchedule(resourceID uuid.UUID) (res vo.Response, statusCode int) {
+	schedule, err := resourceService.resourceRepository.FindPaymentScheduleByResourceID(resourceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// a resource without a schedule is paid in full when booking
+		res.SetData(vo.NewPaymentScheduleResponse(&entity.PaymentSchedule{ResourceID: resourceID, DepositPercent: 100}))
+		return res, fiber.StatusOK
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(vo.NewPaymentScheduleResponse(schedule))
+	return res, fiber.StatusOK
+}
+
+// SavePaymentSchedule replaces the payment schedule of the resource. Existing
+// bookings keep the dues they were booked with.
+func (resourceService *resourceService) SavePaymentSchedule(username string, resourceID uuid.UUID, paymentScheduleDTO *dto.PaymentScheduleDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(resourceService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+	resource, errorMessage, statusCode := findManagedResource(resourceService.ctx, resourceService.resourceRepository, resourceID)
+	if resource == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	total := paymentScheduleDTO.DepositPercent
+	for _, instalment := range paymentScheduleDTO.Instalments {
+		total += instalment.Percent
+	}
+	if total != 100 {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INVALID_PAYMENT_SCHEDULE)
+		return res, fiber.StatusBadRequest
+	}
+
+	schedule, err := resourceService.resourceRepository.FindPaymentScheduleByResourceID(resourceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		schedule = &entity.PaymentSchedule{ResourceID: resourceID}
+	} else if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	schedule.MinAmount = paymentScheduleDTO.MinAmount
+	schedule.DepositPercent = paymentScheduleDTO.DepositPercent
+	schedule.OverdueAction = paymentScheduleDTO.OverdueAction
+	schedule.Instalments = entity.PaymentInstalments{}
+	for _, instalment := range paymentScheduleDTO.Instalments {
+		schedule.Instalments = append(schedule.Instalments, entity.PaymentInstalment{
+			DaysBeforeStart: instalment.DaysBeforeStart,
+			Percent:         instalment.Percent,
+		})
+	}
+
+	err = resourceService.resourceRepository.SavePaymentSchedule(schedule)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(vo.NewPaymentScheduleResponse(schedule))
+	return res, fiber.StatusOK
+}
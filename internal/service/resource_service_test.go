@@ -0,0 +1,235 @@
+package service_test
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/repository"
+	"booking/internal/service"
+	"booking/internal/vo"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestSaveCancellationPolicy(t *testing.T) {
+	const Username = "owner@gmail.com"
+	owner := &entity.User{ID: uuid.New(), Username: Username}
+	body := dto.CancellationPolicyDTO{
+		Rules: []dto.CancellationRuleDTO{
+			{HoursBeforeStart: 48, RefundPercent: 100},
+			{HoursBeforeStart: 24, RefundPercent: 50},
+		},
+	}
+
+	t.Run("When the user isn't an admin of the organization of the resource, should response status code 403 with error message", func(t *testing.T) {
+		resource := &entity.Resource{ID: uuid.New()}
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(owner, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindResourceByID", resource.ID).Return(resource, nil)
+		resourceService := service.NewResourceService(authRepositoryMock, resourceRepositoryMock, newScheduleRepositoryMock())
+
+		res, statusCode := resourceService.SaveCancellationPolicy(Username, resource.ID, &body)
+
+		assert.Equal(t, fiber.StatusForbidden, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_FORBIDDEN, res.ErrorMessage)
+	})
+
+	t.Run("When the resource does not have a policy yet, should create the policy", func(t *testing.T) {
+		resource := &entity.Resource{ID: uuid.New()}
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(owner, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindResourceByID", resource.ID).Return(resource, nil)
+		resourceRepositoryMock.On("FindCancellationPolicyByResourceID", resource.ID).Return(&entity.CancellationPolicy{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("SaveCancellationPolicy", resource.ID).Return(nil)
+		resourceService := service.NewResourceService(authRepositoryMock, resourceRepositoryMock, newScheduleRepositoryMock()).WithContext(AdminContext)
+
+		res, statusCode := resourceService.SaveCancellationPolicy(Username, resource.ID, &body)
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			policy := res.Data.(vo.CancellationPolicyResponse)
+			assert.Len(t, policy.Rules, 2)
+		}
+		resourceRepositoryMock.AssertCalled(t, "SaveCancellationPolicy", resource.ID)
+	})
+}
+
+func TestSavePaymentSchedule(t *testing.T) {
+	const Username = "owner@gmail.com"
+	owner := &entity.User{ID: uuid.New(), Username: Username}
+	newResourceService := func(resource *entity.Resource) (service.IResourceService, *resourceRepositoryMock) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(owner, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindResourceByID", resource.ID).Return(resource, nil)
+		resourceRepositoryMock.On("FindPaymentScheduleByResourceID", resource.ID).Return(&entity.PaymentSchedule{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("SavePaymentSchedule", resource.ID).Return(nil)
+		return service.NewResourceService(authRepositoryMock, resourceRepositoryMock, newScheduleRepositoryMock()).WithContext(AdminContext), resourceRepositoryMock
+	}
+
+	t.Run("When the deposit and the instalments don't add up to 100 percent, should response status code 400 with error message", func(t *testing.T) {
+		resource := &entity.Resource{ID: uuid.New()}
+		resourceService, resourceRepositoryMock := newResourceService(resource)
+		body := dto.PaymentScheduleDTO{DepositPercent: 30, Instalments: []dto.PaymentInstalmentDTO{{DaysBeforeStart: 7, Percent: 60}}, OverdueAction: entity.OVERDUE_ACTION_CANCEL}
+
+		res, statusCode := resourceService.SavePaymentSchedule(Username, resource.ID, &body)
+
+		assert.Equal(t, fiber.StatusBadRequest, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_INVALID_PAYMENT_SCHEDULE, res.ErrorMessage)
+		resourceRepositoryMock.AssertNotCalled(t, "SavePaymentSchedule", resource.ID)
+	})
+
+	t.Run("When the resource does not have a schedule yet, should create the schedule", func(t *testing.T) {
+		resource := &entity.Resource{ID: uuid.New()}
+		resourceService, resourceRepositoryMock := newResourceService(resource)
+		body := dto.PaymentScheduleDTO{MinAmount: 500000, DepositPercent: 30, Instalments: []dto.PaymentInstalmentDTO{{DaysBeforeStart: 7, Percent: 70}}, OverdueAction: entity.OVERDUE_ACTION_FLAG}
+
+		res, statusCode := resourceService.SavePaymentSchedule(Username, resource.ID, &body)
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			schedule := res.Data.(vo.PaymentScheduleResponse)
+			assert.Equal(t, 30, schedule.DepositPercent)
+			assert.Equal(t, []entity.PaymentInstalment{{DaysBeforeStart: 7, Percent: 70}}, schedule.Instalments)
+		}
+		resourceRepositoryMock.AssertCalled(t, "SavePaymentSchedule", resource.ID)
+	})
+}
+
+func TestSearchResources(t *testing.T) {
+	minPrice, maxPrice := int64(20000), int64(50000)
+
+	t.Run("When search with filters, should pass them on normalized and response a page of resources", func(t *testing.T) {
+		from, _ := time.Parse(time.RFC3339, "2024-05-01T16:00:00+07:00")
+		to := from.Add(2 * time.Hour)
+		search := repository.ResourceSearch{
+			Query:         "ห้องประชุม",
+			Category:      "meeting-room",
+			Capacity:      4,
+			MinPrice:      &minPrice,
+			MaxPrice:      &maxPrice,
+			Amenities:     []string{"projector", "whiteboard"},
+			MinRating:     4,
+			AvailableFrom: &from,
+			AvailableTo:   &to,
+			Sort:          repository.RESOURCE_SORT_RATING,
+		}
+		resource := entity.Resource{ID: uuid.New(), Name: "ห้องประชุม A", Amenities: entity.StringList{"projector", "whiteboard"}, RatingAverage: 4.5, RatingCount: 2}
+		found := []entity.Resource{}
+		for i := 0; i < 10; i++ {
+			found = append(found, entity.Resource{ID: uuid.New()})
+		}
+		found = append(found, resource)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("SearchResources", search, 0, service.AVAILABILITY_BATCH_SIZE).Return(found, int64(11), nil)
+		resourceService := service.NewResourceService(&authRepositoryMock{}, resourceRepositoryMock, newScheduleRepositoryMock())
+
+		res, statusCode := resourceService.SearchResources(&dto.ResourceSearchQueryDTO{
+			Q:         " ห้องประชุม ",
+			Category:  "meeting-room",
+			Capacity:  4,
+			MinPrice:  &minPrice,
+			MaxPrice:  &maxPrice,
+			Amenities: []string{"Projector", "whiteboard", "projector "},
+			MinRating: 4,
+			From:      "2024-05-01T16:00:00+07:00",
+			To:        "2024-05-01T18:00:00+07:00",
+			Sort:      repository.RESOURCE_SORT_RATING,
+			Page:      2,
+			Size:      10,
+		})
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			resources := res.Data.([]vo.ResourceResponse)
+			if assert.Len(t, resources, 1) {
+				assert.Equal(t, resource.ID, resources[0].ID)
+				assert.Equal(t, 4.5, resources[0].RatingAverage)
+			}
+			assert.Equal(t, vo.Pagination{Page: 2, Size: 10, Total: 11}, res.Pagination)
+		}
+	})
+
+	t.Run("When a resource is closed in the period, should leave it out of the resources available", func(t *testing.T) {
+		from, _ := time.Parse(time.RFC3339, "2024-05-01T10:00:00+07:00")
+		to := from.Add(2 * time.Hour)
+		search := repository.ResourceSearch{Amenities: entity.StringList{}, AvailableFrom: &from, AvailableTo: &to}
+		open := entity.Resource{ID: uuid.New()}
+		// 2024-05-01 is National Labour Day
+		closed := entity.Resource{ID: uuid.New(), HolidayCalendarCode: "TH"}
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("SearchResources", search, 0, service.AVAILABILITY_BATCH_SIZE).Return([]entity.Resource{closed, open}, int64(2), nil)
+		scheduleRepositoryMock := &scheduleRepositoryMock{}
+		scheduleRepositoryMock.On("FindOpeningHours", mock.Anything).Return([]entity.OpeningHours{}, nil)
+		scheduleRepositoryMock.On("FindDateOverrides", mock.Anything).Return([]entity.DateOverride{}, nil)
+		scheduleRepositoryMock.On("FindHolidays", "TH").Return([]entity.Holiday{{CalendarCode: "TH", Date: "2024-05-01", Name: "National Labour Day"}}, nil)
+		scheduleRepositoryMock.On("FindBlackoutPeriods", mock.Anything).Return([]entity.BlackoutPeriod{}, nil)
+		resourceService := service.NewResourceService(&authRepositoryMock{}, resourceRepositoryMock, scheduleRepositoryMock)
+
+		res, statusCode := resourceService.SearchResources(&dto.ResourceSearchQueryDTO{From: "2024-05-01T10:00:00+07:00", To: "2024-05-01T12:00:00+07:00"})
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			resources := res.Data.([]vo.ResourceResponse)
+			if assert.Len(t, resources, 1) {
+				assert.Equal(t, open.ID, resources[0].ID)
+			}
+			assert.Equal(t, uint(1), res.Pagination.Total)
+		}
+	})
+
+	t.Run("When search around a point, should sort by distance and response how far each resource is", func(t *testing.T) {
+		latitude, longitude := 13.7462, 100.5347
+		near := entity.GeoPoint{Latitude: latitude, Longitude: longitude}
+		nearbyLatitude, nearbyLongitude := 13.7563, 100.5018
+		nearby := entity.Resource{ID: uuid.New(), Latitude: &nearbyLatitude, Longitude: &nearbyLongitude}
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("SearchResources", repository.ResourceSearch{Amenities: entity.StringList{}, Near: &near, RadiusKm: 10, Sort: repository.RESOURCE_SORT_DISTANCE}, 0, service.RESOURCE_PAGE_SIZE).Return([]entity.Resource{nearby}, int64(1), nil)
+		resourceService := service.NewResourceService(&authRepositoryMock{}, resourceRepositoryMock, newScheduleRepositoryMock())
+
+		res, statusCode := resourceService.SearchResources(&dto.ResourceSearchQueryDTO{Lat: &latitude, Lng: &longitude, RadiusKm: 10})
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			resources := res.Data.([]vo.ResourceResponse)
+			if assert.Len(t, resources, 1) && assert.NotNil(t, resources[0].DistanceKm) {
+				assert.InDelta(t, 3.7, *resources[0].DistanceKm, 0.1)
+			}
+		}
+	})
+
+	t.Run("When sort by distance without a point, should response status code 400 with error message", func(t *testing.T) {
+		resourceService := service.NewResourceService(&authRepositoryMock{}, &resourceRepositoryMock{}, newScheduleRepositoryMock())
+
+		res, statusCode := resourceService.SearchResources(&dto.ResourceSearchQueryDTO{Sort: repository.RESOURCE_SORT_DISTANCE})
+
+		assert.Equal(t, fiber.StatusBadRequest, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_INVALID_PARAMETERS, res.ErrorMessage)
+	})
+
+	t.Run("When the minimum price is above the maximum, should response status code 400 with error message", func(t *testing.T) {
+		resourceService := service.NewResourceService(&authRepositoryMock{}, &resourceRepositoryMock{}, newScheduleRepositoryMock())
+
+		res, statusCode := resourceService.SearchResources(&dto.ResourceSearchQueryDTO{MinPrice: &maxPrice, MaxPrice: &minPrice})
+
+		assert.Equal(t, fiber.StatusBadRequest, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_INVALID_PARAMETERS, res.ErrorMessage)
+	})
+
+	t.Run("When the period ends before it starts, should response status code 400 with error message", func(t *testing.T) {
+		resourceService := service.NewResourceService(&authRepositoryMock{}, &resourceRepositoryMock{}, newScheduleRepositoryMock())
+
+		res, statusCode := resourceService.SearchResources(&dto.ResourceSearchQueryDTO{From: "2024-05-01T18:00:00+07:00", To: "2024-05-01T16:00:00+07:00"})
+
+		assert.Equal(t, fiber.StatusBadRequest, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_INVALID_PARAMETERS, res.ErrorMessage)
+	})
+}
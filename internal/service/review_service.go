@@ -0,0 +1,222 @@
+package service
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/logs"
+	"booking/internal/repository"
+	"booking/internal/vo"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// REVIEW_PAGE_SIZE is how many reviews a page has by default.
+const REVIEW_PAGE_SIZE = 20
+
+type IReviewService interface {
+	WithContext(ctx context.Context) IReviewService
+	CreateReview(username string, bookingID uuid.UUID, createReviewDTO *dto.CreateReviewDTO) (res vo.Response, statusCode int)
+	GetResourceReviews(resourceID uuid.UUID, reviewQueryDTO *dto.ReviewQueryDTO) (res vo.Response, statusCode int)
+	ReplyReview(username string, reviewID uuid.UUID, replyReviewDTO *dto.ReplyReviewDTO) (res vo.Response, statusCode int)
+	ModerateReview(username string, reviewID uuid.UUID, moderateReviewDTO *dto.ModerateReviewDTO) (res vo.Response, statusCode int)
+}
+
+type reviewService struct {
+	authRepository     repository.IAuthRepository
+	resourceRepository repository.IResourceRepository
+	bookingRepository  repository.IBookingRepository
+	reviewRepository   repository.IReviewRepository
+	ctx                context.Context
+}
+
+func NewReviewService(authRepository repository.IAuthRepository, resourceRepository repository.IResourceRepository, bookingRepository repository.IBookingRepository, reviewRepository repository.IReviewRepository) *reviewService {
+	return &reviewService{
+		authRepository:     authRepository,
+		resourceRepository: resourceRepository,
+		bookingRepository:  bookingRepository,
+		reviewRepository:   reviewRepository,
+	}
+}
+
+func (reviewService *reviewService) WithContext(ctx context.Context) IReviewService {
+	service := *reviewService
+	service.ctx = ctx
+	service.resourceRepository = reviewService.resourceRepository.WithContext(ctx)
+	service.bookingRepository = reviewService.bookingRepository.WithContext(ctx)
+	service.reviewRepository = reviewService.reviewRepository.WithContext(ctx)
+	return &service
+}
+
+// CreateReview rates the resource of the completed booking of the user.
+func (reviewService *reviewService) CreateReview(username string, bookingID uuid.UUID, createReviewDTO *dto.CreateReviewDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(reviewService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	booking, err := reviewService.bookingRepository.FindBookingByID(bookingID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && booking.UserID != user.ID) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_BOOKING_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	if !booking.IsCompleted(time.Now()) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_BOOKING_NOT_COMPLETED)
+		return res, fiber.StatusConflict
+	}
+
+	_, err = reviewService.reviewRepository.FindReviewByBookingID(booking.ID)
+	if err == nil {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_BOOKING_ALREADY_REVIEWED)
+		return res, fiber.StatusConflict
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	review := entity.Review{
+		ResourceID: booking.ResourceID,
+		BookingID:  booking.ID,
+		UserID:     user.ID,
+		Rating:     createReviewDTO.Rating,
+		Text:       createReviewDTO.Text,
+		Status:     entity.REVIEW_STATUS_PUBLISHED,
+	}
+	err = reviewService.reviewRepository.CreateReview(&review)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(vo.NewReviewResponse(&review))
+	return res, fiber.StatusCreated
+}
+
+// GetResourceReviews returns the published reviews of the resource, the
+// latest first.
+func (reviewService *reviewService) GetResourceReviews(resourceID uuid.UUID, reviewQueryDTO *dto.ReviewQueryDTO) (res vo.Response, statusCode int) {
+	_, err := reviewService.resourceRepository.FindResourceByID(resourceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_RESOURCE_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	page, size := reviewQueryDTO.Page, reviewQueryDTO.Size
+	if page == 0 {
+		page = 1
+	}
+	if size == 0 {
+		size = REVIEW_PAGE_SIZE
+	}
+	reviews, total, err := reviewService.reviewRepository.FindResourceReviews(resourceID, entity.REVIEW_STATUS_PUBLISHED, int((page-1)*size), int(size))
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	responses := []vo.ReviewResponse{}
+	for i := range reviews {
+		responses = append(responses, vo.NewReviewResponse(&reviews[i]))
+	}
+	res.SetDataWithPagination(responses, page, size, uint(total))
+	return res, fiber.StatusOK
+}
+
+// findReview returns the review, or the error message and status code to
+// respond with.
+func (reviewService *reviewService) findReview(reviewID uuid.UUID) (review *entity.Review, errorMessage string, statusCode int) {
+	review, err := reviewService.reviewRepository.FindReviewByID(reviewID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, constant.ERROR_MESSAGE_REVIEW_NOT_FOUND, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		return nil, constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR, fiber.StatusInternalServerError
+	}
+	return review, "", fiber.StatusOK
+}
+
+// ReplyReview answers the review as an admin of the organization of its
+// resource.
+func (reviewService *reviewService) ReplyReview(username string, reviewID uuid.UUID, replyReviewDTO *dto.ReplyReviewDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(reviewService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+	review, errorMessage, statusCode := reviewService.findReview(reviewID)
+	if review == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+	resource, errorMessage, statusCode := findManagedResource(reviewService.ctx, reviewService.resourceRepository, review.ResourceID)
+	if resource == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	review.Reply = replyReviewDTO.Reply
+	review.RepliedAt = nil
+	if review.Reply != "" {
+		now := time.Now()
+		review.RepliedAt = &now
+	}
+	err := reviewService.reviewRepository.UpdateReview(review)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(vo.NewReviewResponse(review))
+	return res, fiber.StatusOK
+}
+
+// ModerateReview publishes or hides the review, which the rating of its
+// resource follows. Only organization admins are routed here.
+func (reviewService *reviewService) ModerateReview(username string, reviewID uuid.UUID, moderateReviewDTO *dto.ModerateReviewDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(reviewService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+	review, errorMessage, statusCode := reviewService.findReview(reviewID)
+	if review == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	now := time.Now()
+	review.Status = moderateReviewDTO.Status
+	review.ModeratedBy = &user.ID
+	review.ModeratedAt = &now
+	err := reviewService.reviewRepository.UpdateReview(review)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(vo.NewReviewResponse(review))
+	return res, fiber.StatusOK
+}
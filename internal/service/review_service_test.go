@@ -0,0 +1,186 @@
+package service_test
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/service"
+	"booking/internal/tenant"
+	"booking/internal/vo"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestCreateReview(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+	resourceID := uuid.New()
+	newBooking := func(status string, endAt time.Time) *entity.Booking {
+		return &entity.Booking{
+			ID:         uuid.New(),
+			UserID:     user.ID,
+			ResourceID: resourceID,
+			StartAt:    endAt.Add(-time.Hour),
+			EndAt:      endAt,
+			Status:     status,
+		}
+	}
+	newReviewService := func(booking *entity.Booking, reviewed bool) (service.IReviewService, *reviewRepositoryMock) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("FindBookingByID", booking.ID).Return(booking, nil)
+		reviewRepositoryMock := newReviewRepositoryMock()
+		if reviewed {
+			reviewRepositoryMock.On("FindReviewByBookingID", booking.ID).Return(&entity.Review{ID: uuid.New(), BookingID: booking.ID}, nil)
+		} else {
+			reviewRepositoryMock.On("FindReviewByBookingID", booking.ID).Return(&entity.Review{}, gorm.ErrRecordNotFound)
+		}
+		return service.NewReviewService(authRepositoryMock, &resourceRepositoryMock{}, bookingRepositoryMock, reviewRepositoryMock), reviewRepositoryMock
+	}
+
+	t.Run("When review a completed booking, should publish the review of its resource", func(t *testing.T) {
+		booking := newBooking(entity.BOOKING_STATUS_CONFIRMED, time.Now().Add(-time.Hour))
+		reviewService, reviewRepositoryMock := newReviewService(booking, false)
+
+		res, statusCode := reviewService.CreateReview(Username, booking.ID, &dto.CreateReviewDTO{Rating: 4, Text: "Quiet and clean"})
+
+		isPass := assert.Equal(t, fiber.StatusCreated, statusCode)
+		if isPass {
+			review := res.Data.(vo.ReviewResponse)
+			assert.Equal(t, resourceID, review.ResourceID)
+			assert.Equal(t, 4, review.Rating)
+			assert.Equal(t, entity.REVIEW_STATUS_PUBLISHED, review.Status)
+			assert.Len(t, reviewRepositoryMock.reviews, 1)
+		}
+	})
+
+	t.Run("When review a booking that hasn't ended, should response status code 409 with error message", func(t *testing.T) {
+		booking := newBooking(entity.BOOKING_STATUS_CONFIRMED, time.Now().Add(time.Hour))
+		reviewService, reviewRepositoryMock := newReviewService(booking, false)
+
+		res, statusCode := reviewService.CreateReview(Username, booking.ID, &dto.CreateReviewDTO{Rating: 5})
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_BOOKING_NOT_COMPLETED, res.ErrorMessage)
+		assert.Empty(t, reviewRepositoryMock.reviews)
+	})
+
+	t.Run("When review a cancelled booking, should response status code 409 with error message", func(t *testing.T) {
+		booking := newBooking(entity.BOOKING_STATUS_CANCELLED, time.Now().Add(-time.Hour))
+		reviewService, _ := newReviewService(booking, false)
+
+		res, statusCode := reviewService.CreateReview(Username, booking.ID, &dto.CreateReviewDTO{Rating: 5})
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_BOOKING_NOT_COMPLETED, res.ErrorMessage)
+	})
+
+	t.Run("When review a booking twice, should response status code 409 with error message", func(t *testing.T) {
+		booking := newBooking(entity.BOOKING_STATUS_CONFIRMED, time.Now().Add(-time.Hour))
+		reviewService, reviewRepositoryMock := newReviewService(booking, true)
+
+		res, statusCode := reviewService.CreateReview(Username, booking.ID, &dto.CreateReviewDTO{Rating: 5})
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_BOOKING_ALREADY_REVIEWED, res.ErrorMessage)
+		assert.Empty(t, reviewRepositoryMock.reviews)
+	})
+
+	t.Run("When review the booking of another user, should response status code 404 with error message", func(t *testing.T) {
+		booking := newBooking(entity.BOOKING_STATUS_CONFIRMED, time.Now().Add(-time.Hour))
+		booking.UserID = uuid.New()
+		reviewService, _ := newReviewService(booking, false)
+
+		res, statusCode := reviewService.CreateReview(Username, booking.ID, &dto.CreateReviewDTO{Rating: 5})
+
+		assert.Equal(t, fiber.StatusNotFound, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_BOOKING_NOT_FOUND, res.ErrorMessage)
+	})
+}
+
+func TestReplyReview(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+	newReviewService := func(ctx context.Context, resource *entity.Resource, review *entity.Review) (service.IReviewService, *reviewRepositoryMock) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindResourceByID", resource.ID).Return(resource, nil)
+		reviewRepositoryMock := newReviewRepositoryMock()
+		reviewRepositoryMock.On("FindReviewByID", review.ID).Return(review, nil)
+		return service.NewReviewService(authRepositoryMock, resourceRepositoryMock, &bookingRepositoryMock{}, reviewRepositoryMock).WithContext(ctx), reviewRepositoryMock
+	}
+
+	t.Run("When an admin of the organization replies, should save the reply on the review", func(t *testing.T) {
+		resource := &entity.Resource{ID: uuid.New()}
+		review := &entity.Review{ID: uuid.New(), ResourceID: resource.ID, Rating: 2, Status: entity.REVIEW_STATUS_PUBLISHED}
+		reviewService, reviewRepositoryMock := newReviewService(AdminContext, resource, review)
+
+		res, statusCode := reviewService.ReplyReview(Username, review.ID, &dto.ReplyReviewDTO{Reply: "Sorry about the noise"})
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			reply := res.Data.(vo.ReviewResponse)
+			assert.Equal(t, "Sorry about the noise", reply.Reply)
+			assert.NotNil(t, reply.RepliedAt)
+			reviewRepositoryMock.AssertCalled(t, "UpdateReview", review.ID)
+		}
+	})
+
+	t.Run("When someone who isn't an admin of the organization replies, should response status code 403 with error message", func(t *testing.T) {
+		resource := &entity.Resource{ID: uuid.New()}
+		review := &entity.Review{ID: uuid.New(), ResourceID: resource.ID, Rating: 2, Status: entity.REVIEW_STATUS_PUBLISHED}
+		reviewService, reviewRepositoryMock := newReviewService(tenant.WithRole(context.Background(), entity.ORGANIZATION_ROLE_MEMBER), resource, review)
+
+		res, statusCode := reviewService.ReplyReview(Username, review.ID, &dto.ReplyReviewDTO{Reply: "Thanks"})
+
+		assert.Equal(t, fiber.StatusForbidden, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_FORBIDDEN, res.ErrorMessage)
+		reviewRepositoryMock.AssertNotCalled(t, "UpdateReview", review.ID)
+	})
+}
+
+func TestModerateReview(t *testing.T) {
+	const Username = "example@gmail.com"
+	admin := &entity.User{ID: uuid.New(), Username: Username}
+
+	t.Run("When an admin hides a review, should record who moderated it", func(t *testing.T) {
+		review := &entity.Review{ID: uuid.New(), ResourceID: uuid.New(), Rating: 1, Status: entity.REVIEW_STATUS_PUBLISHED}
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(admin, nil)
+		reviewRepositoryMock := newReviewRepositoryMock()
+		reviewRepositoryMock.On("FindReviewByID", review.ID).Return(review, nil)
+		reviewService := service.NewReviewService(authRepositoryMock, &resourceRepositoryMock{}, &bookingRepositoryMock{}, reviewRepositoryMock)
+
+		res, statusCode := reviewService.ModerateReview(Username, review.ID, &dto.ModerateReviewDTO{Status: entity.REVIEW_STATUS_HIDDEN})
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			assert.Equal(t, entity.REVIEW_STATUS_HIDDEN, res.Data.(vo.ReviewResponse).Status)
+			if assert.Len(t, reviewRepositoryMock.reviews, 1) {
+				assert.Equal(t, &admin.ID, reviewRepositoryMock.reviews[0].ModeratedBy)
+			}
+		}
+	})
+
+	t.Run("When the review doesn't exist, should response status code 404 with error message", func(t *testing.T) {
+		reviewID := uuid.New()
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(admin, nil)
+		reviewRepositoryMock := newReviewRepositoryMock()
+		reviewRepositoryMock.On("FindReviewByID", reviewID).Return(&entity.Review{}, gorm.ErrRecordNotFound)
+		reviewService := service.NewReviewService(authRepositoryMock, &resourceRepositoryMock{}, &bookingRepositoryMock{}, reviewRepositoryMock)
+
+		res, statusCode := reviewService.ModerateReview(Username, reviewID, &dto.ModerateReviewDTO{Status: entity.REVIEW_STATUS_HIDDEN})
+
+		assert.Equal(t, fiber.StatusNotFound, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_REVIEW_NOT_FOUND, res.ErrorMessage)
+	})
+}
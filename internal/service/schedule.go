@@ -0,0 +1,146 @@
+package service
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/logs"
+	"booking/internal/repository"
+	"booking/internal/schedule"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+var errInvalidOpeningHours = errors.New(constant.ERROR_MESSAGE_INVALID_OPENING_HOURS)
+
+// loadSchedule loads the opening hours, date overrides, holidays and blackouts
+// of the resource that apply between startAt and endAt.
+func loadSchedule(scheduleRepository repository.IScheduleRepository, resource *entity.Resource, startAt time.Time, endAt time.Time) (*schedule.Schedule, error) {
+	location := resource.Location()
+	resourceSchedule := &schedule.Schedule{
+		Location:  location,
+		Weekly:    map[time.Weekday][]schedule.Period{},
+		Overrides: map[string][]schedule.Period{},
+		Holidays:  map[string]bool{},
+	}
+
+	hours, err := scheduleRepository.FindOpeningHours(resource.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, weekdayHours := range hours {
+		resourceSchedule.Weekly[time.Weekday(weekdayHours.Weekday)] = toSchedulePeriods(weekdayHours.Periods)
+	}
+
+	fromDate := startAt.In(location).Format(schedule.DATE_LAYOUT)
+	toDate := endAt.In(location).Format(schedule.DATE_LAYOUT)
+	overrides, err := scheduleRepository.FindDateOverrides(resource.ID, fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+	for _, override := range overrides {
+		resourceSchedule.Overrides[override.Date] = toSchedulePeriods(override.Periods)
+	}
+
+	if resource.HolidayCalendarCode != "" {
+		holidays, err := scheduleRepository.FindHolidays(resource.HolidayCalendarCode, fromDate, toDate)
+		if err != nil {
+			return nil, err
+		}
+		for _, holiday := range holidays {
+			resourceSchedule.Holidays[holiday.Date] = true
+		}
+	}
+
+	blackouts, err := scheduleRepository.FindBlackoutPeriods(resource.ID, startAt, endAt)
+	if err != nil {
+		return nil, err
+	}
+	for _, blackout := range blackouts {
+		resourceSchedule.Blackouts = append(resourceSchedule.Blackouts, schedule.Range{StartAt: blackout.StartAt, EndAt: blackout.EndAt})
+	}
+	return resourceSchedule, nil
+}
+
+func toSchedulePeriods(periods entity.OpeningPeriods) []schedule.Period {
+	schedulePeriods := make([]schedule.Period, len(periods))
+	for i, period := range periods {
+		schedulePeriods[i] = schedule.Period{OpensAt: period.OpensAt, ClosesAt: period.ClosesAt}
+	}
+	return schedulePeriods
+}
+
+// parseOpeningPeriods parses the periods of a day, which must be in order and
+// not overlap.
+func parseOpeningPeriods(periodDTOs []dto.OpeningPeriodDTO) (entity.OpeningPeriods, error) {
+	periods := entity.OpeningPeriods{}
+	for _, periodDTO := range periodDTOs {
+		opensAt, err := schedule.ParseClock(periodDTO.OpensAt)
+		if err != nil {
+			return nil, errInvalidOpeningHours
+		}
+		closesAt, err := schedule.ParseClock(periodDTO.ClosesAt)
+		if err != nil || closesAt <= opensAt {
+			return nil, errInvalidOpeningHours
+		}
+		if len(periods) > 0 && opensAt < periods[len(periods)-1].ClosesAt {
+			return nil, errInvalidOpeningHours
+		}
+		periods = append(periods, entity.OpeningPeriod{OpensAt: opensAt, ClosesAt: closesAt})
+	}
+	return periods, nil
+}
+
+// scheduleConflict returns the reason the resource cannot be booked for the
+// period under its schedule, or an empty string when it is open.
+func scheduleConflict(resourceSchedule *schedule.Schedule, startAt time.Time, endAt time.Time) string {
+	err := resourceSchedule.Check(startAt, endAt)
+	switch {
+	case errors.Is(err, schedule.ErrClosed):
+		return constant.ERROR_MESSAGE_RESOURCE_CLOSED
+	case errors.Is(err, schedule.ErrBlackout):
+		return constant.ERROR_MESSAGE_RESOURCE_BLACKED_OUT
+	}
+	return ""
+}
+
+// SeedHolidayCalendars stores the Thai public holidays of the current and the
+// next year, with the Buddhist holidays configured in holiday.thai_buddhist.
+// A year without known Buddhist holidays is seeded without them and logged, as
+// they are announced only a year or so ahead. Seeding is idempotent, so it
+// runs on every start.
+func SeedHolidayCalendars(scheduleRepository repository.IScheduleRepository, now time.Time) error {
+	configured := []schedule.Holiday{}
+	err := viper.UnmarshalKey("holiday.thai_buddhist", &configured)
+	if err != nil {
+		return err
+	}
+	for _, holiday := range configured {
+		_, err = time.Parse(schedule.DATE_LAYOUT, holiday.Date)
+		if err != nil {
+			return fmt.Errorf("holiday.thai_buddhist: %w", err)
+		}
+	}
+
+	calendar := entity.HolidayCalendar{Code: schedule.THAI_HOLIDAY_CALENDAR, Name: "Thai public holidays"}
+	holidays := []entity.Holiday{}
+	for _, year := range []int{now.Year(), now.Year() + 1} {
+		yearHolidays, ok := schedule.ThaiPublicHolidays(year, configured)
+		if !ok {
+			logs.Error("the Buddhist holidays are not known, add them to holiday.thai_buddhist", zap.Int("year", year))
+		}
+		for _, holiday := range yearHolidays {
+			holidays = append(holidays, entity.Holiday{
+				CalendarCode: calendar.Code,
+				Date:         holiday.Date,
+				Name:         holiday.Name,
+				NameTH:       holiday.NameTH,
+			})
+		}
+	}
+	return scheduleRepository.SaveHolidays(&calendar, holidays)
+}
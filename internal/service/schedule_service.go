@@ -0,0 +1,390 @@
+package service
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/logs"
+	"booking/internal/repository"
+	"booking/internal/schedule"
+	"booking/internal/tenant"
+	"booking/internal/vo"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type IScheduleService interface {
+	WithContext(ctx context.Context) IScheduleService
+	GetSchedule(resourceID uuid.UUID) (res vo.Response, statusCode int)
+	UpdateOpeningHours(username string, resourceID uuid.UUID, updateOpeningHoursDTO *dto.UpdateOpeningHoursDTO) (res vo.Response, statusCode int)
+	SaveDateOverride(username string, resourceID uuid.UUID, date string, dateOverrideDTO *dto.DateOverrideDTO) (res vo.Response, statusCode int)
+	DeleteDateOverride(username string, resourceID uuid.UUID, date string) (res vo.Response, statusCode int)
+	CreateBlackoutPeriod(username string, resourceID uuid.UUID, createBlackoutPeriodDTO *dto.CreateBlackoutPeriodDTO) (res vo.Response, statusCode int)
+	DeleteBlackoutPeriod(username string, resourceID uuid.UUID, blackoutID uuid.UUID) (res vo.Response, statusCode int)
+	GetHolidays(code string, year int) (res vo.Response, statusCode int)
+}
+
+type scheduleService struct {
+	authRepository     repository.IAuthRepository
+	resourceRepository repository.IResourceRepository
+	scheduleRepository repository.IScheduleRepository
+	ctx                context.Context
+}
+
+func NewScheduleService(authRepository repository.IAuthRepository, resourceRepository repository.IResourceRepository, scheduleRepository repository.IScheduleRepository) *scheduleService {
+	return &scheduleService{authRepository: authRepository, resourceRepository: resourceRepository, scheduleRepository: scheduleRepository}
+}
+
+func (scheduleService *scheduleService) WithContext(ctx context.Context) IScheduleService {
+	service := *scheduleService
+	service.ctx = ctx
+	service.resourceRepository = scheduleService.resourceRepository.WithContext(ctx)
+	return &service
+}
+
+// findManagedResource finds the resource for an owner or admin of the
+// organization of the request to manage, the resources of other
+// organizations not being found. It returns a nil resource with the error
+// message and status code to respond with otherwise.
+func findManagedResource(ctx context.Context, resourceRepository repository.IResourceRepository, resourceID uuid.UUID) (resource *entity.Resource, errorMessage string, statusCode int) {
+	resource, err := resourceRepository.FindResourceByID(resourceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, constant.ERROR_MESSAGE_RESOURCE_NOT_FOUND, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		return nil, constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR, fiber.StatusInternalServerError
+	}
+	if !isOrganizationAdmin(ctx) {
+		return nil, constant.ERROR_MESSAGE_FORBIDDEN, fiber.StatusForbidden
+	}
+	return resource, "", fiber.StatusOK
+}
+
+// isOrganizationAdmin reports whether the user of the request is an owner or
+// admin of its organization.
+func isOrganizationAdmin(ctx context.Context) bool {
+	role := tenant.Role(ctx)
+	return role == entity.ORGANIZATION_ROLE_OWNER || role == entity.ORGANIZATION_ROLE_ADMIN
+}
+
+func newOpeningPeriodResponses(periods entity.OpeningPeriods) []vo.OpeningPeriodResponse {
+	responses := []vo.OpeningPeriodResponse{}
+	for _, period := range periods {
+		responses = append(responses, vo.OpeningPeriodResponse{
+			OpensAt:  schedule.FormatClock(period.OpensAt),
+			ClosesAt: schedule.FormatClock(period.ClosesAt),
+		})
+	}
+	return responses
+}
+
+func newDateOverrideResponse(override *entity.DateOverride) vo.DateOverrideResponse {
+	return vo.DateOverrideResponse{
+		Date:    override.Date,
+		Periods: newOpeningPeriodResponses(override.Periods),
+		Reason:  override.Reason,
+	}
+}
+
+func newBlackoutPeriodResponse(blackout *entity.BlackoutPeriod) vo.BlackoutPeriodResponse {
+	return vo.BlackoutPeriodResponse{
+		ID:      blackout.ID,
+		StartAt: blackout.StartAt,
+		EndAt:   blackout.EndAt,
+		Reason:  blackout.Reason,
+	}
+}
+
+// GetSchedule returns the opening hours of the resource with the date
+// overrides and blackouts of the coming year.
+func (scheduleService *scheduleService) GetSchedule(resourceID uuid.UUID) (res vo.Response, statusCode int) {
+	resource, err := scheduleService.resourceRepository.FindResourceByID(resourceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_RESOURCE_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	now := time.Now()
+	until := now.AddDate(1, 0, 0)
+	location := resource.Location()
+	hours, err := scheduleService.scheduleRepository.FindOpeningHours(resourceID)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	overrides, err := scheduleService.scheduleRepository.FindDateOverrides(resourceID, now.In(location).Format(schedule.DATE_LAYOUT), until.In(location).Format(schedule.DATE_LAYOUT))
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	blackouts, err := scheduleService.scheduleRepository.FindBlackoutPeriods(resourceID, now, until)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	response := vo.ScheduleResponse{
+		ResourceID:      resource.ID,
+		TimeZone:        location.String(),
+		HolidayCalendar: resource.HolidayCalendarCode,
+		Hours:           []vo.WeekdayHoursResponse{},
+		DateOverrides:   []vo.DateOverrideResponse{},
+		Blackouts:       []vo.BlackoutPeriodResponse{},
+	}
+	for _, weekdayHours := range hours {
+		response.Hours = append(response.Hours, vo.WeekdayHoursResponse{
+			Weekday: weekdayHours.Weekday,
+			Periods: newOpeningPeriodResponses(weekdayHours.Periods),
+		})
+	}
+	for i := range overrides {
+		response.DateOverrides = append(response.DateOverrides, newDateOverrideResponse(&overrides[i]))
+	}
+	for i := range blackouts {
+		response.Blackouts = append(response.Blackouts, newBlackoutPeriodResponse(&blackouts[i]))
+	}
+	res.SetData(response)
+	return res, fiber.StatusOK
+}
+
+// UpdateOpeningHours replaces the weekly opening hours, time zone and holiday
+// calendar of the resource. Bookings already made are kept.
+func (scheduleService *scheduleService) UpdateOpeningHours(username string, resourceID uuid.UUID, updateOpeningHoursDTO *dto.UpdateOpeningHoursDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(scheduleService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	resource, errorMessage, statusCode := findManagedResource(scheduleService.ctx, scheduleService.resourceRepository, resourceID)
+	if resource == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	timeZone := updateOpeningHoursDTO.TimeZone
+	if timeZone == "" {
+		timeZone = constant.DEFAULT_TIME_ZONE
+	}
+	if _, err := time.LoadLocation(timeZone); err != nil {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INVALID_TIME_ZONE)
+		return res, fiber.StatusBadRequest
+	}
+
+	if updateOpeningHoursDTO.HolidayCalendar != "" {
+		_, err := scheduleService.scheduleRepository.FindHolidayCalendar(updateOpeningHoursDTO.HolidayCalendar)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			res.SetErrorMessage(constant.ERROR_MESSAGE_HOLIDAY_CALENDAR_NOT_FOUND)
+			return res, fiber.StatusBadRequest
+		}
+		if err != nil {
+			logs.Error(err)
+			res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+			return res, fiber.StatusInternalServerError
+		}
+	}
+
+	hours := []entity.OpeningHours{}
+	weekdays := map[int]bool{}
+	for _, weekdayHours := range updateOpeningHoursDTO.Hours {
+		periods, err := parseOpeningPeriods(weekdayHours.Periods)
+		if err != nil || weekdays[weekdayHours.Weekday] {
+			res.SetErrorMessage(constant.ERROR_MESSAGE_INVALID_OPENING_HOURS)
+			return res, fiber.StatusBadRequest
+		}
+		weekdays[weekdayHours.Weekday] = true
+		hours = append(hours, entity.OpeningHours{ResourceID: resourceID, Weekday: weekdayHours.Weekday, Periods: periods})
+	}
+
+	err := scheduleService.scheduleRepository.ReplaceOpeningHours(resourceID, hours)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	resource.TimeZone = timeZone
+	resource.HolidayCalendarCode = updateOpeningHoursDTO.HolidayCalendar
+	err = scheduleService.resourceRepository.UpdateResource(resource)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	return scheduleService.GetSchedule(resourceID)
+}
+
+// SaveDateOverride sets the opening periods of the resource on a local date,
+// replacing its weekly hours and any holiday on that date.
+func (scheduleService *scheduleService) SaveDateOverride(username string, resourceID uuid.UUID, date string, dateOverrideDTO *dto.DateOverrideDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(scheduleService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	resource, errorMessage, statusCode := findManagedResource(scheduleService.ctx, scheduleService.resourceRepository, resourceID)
+	if resource == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	periods, err := parseOpeningPeriods(dateOverrideDTO.Periods)
+	if err != nil {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INVALID_OPENING_HOURS)
+		return res, fiber.StatusBadRequest
+	}
+
+	override := entity.DateOverride{
+		ResourceID: resourceID,
+		Date:       date,
+		Periods:    periods,
+		Reason:     dateOverrideDTO.Reason,
+	}
+	err = scheduleService.scheduleRepository.SaveDateOverride(&override)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(newDateOverrideResponse(&override))
+	return res, fiber.StatusOK
+}
+
+func (scheduleService *scheduleService) DeleteDateOverride(username string, resourceID uuid.UUID, date string) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(scheduleService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	resource, errorMessage, statusCode := findManagedResource(scheduleService.ctx, scheduleService.resourceRepository, resourceID)
+	if resource == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	deleted, err := scheduleService.scheduleRepository.DeleteDateOverride(resourceID, date)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	if deleted == 0 {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_DATE_OVERRIDE_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+
+	res.SetData(nil)
+	return res, fiber.StatusOK
+}
+
+// CreateBlackoutPeriod blocks new bookings of the resource for the period.
+// Bookings already made in the period are kept.
+func (scheduleService *scheduleService) CreateBlackoutPeriod(username string, resourceID uuid.UUID, createBlackoutPeriodDTO *dto.CreateBlackoutPeriodDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(scheduleService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	resource, errorMessage, statusCode := findManagedResource(scheduleService.ctx, scheduleService.resourceRepository, resourceID)
+	if resource == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	blackout := entity.BlackoutPeriod{
+		ResourceID: resourceID,
+		StartAt:    createBlackoutPeriodDTO.StartAt,
+		EndAt:      createBlackoutPeriodDTO.EndAt,
+		Reason:     createBlackoutPeriodDTO.Reason,
+	}
+	err := scheduleService.scheduleRepository.CreateBlackoutPeriod(&blackout)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(newBlackoutPeriodResponse(&blackout))
+	return res, fiber.StatusCreated
+}
+
+func (scheduleService *scheduleService) DeleteBlackoutPeriod(username string, resourceID uuid.UUID, blackoutID uuid.UUID) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(scheduleService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	resource, errorMessage, statusCode := findManagedResource(scheduleService.ctx, scheduleService.resourceRepository, resourceID)
+	if resource == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	deleted, err := scheduleService.scheduleRepository.DeleteBlackoutPeriod(resourceID, blackoutID)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	if deleted == 0 {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_BLACKOUT_PERIOD_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+
+	res.SetData(nil)
+	return res, fiber.StatusOK
+}
+
+// GetHolidays lists the holidays of the calendar in the year, the current
+// year when zero.
+func (scheduleService *scheduleService) GetHolidays(code string, year int) (res vo.Response, statusCode int) {
+	calendar, err := scheduleService.scheduleRepository.FindHolidayCalendar(code)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_HOLIDAY_CALENDAR_NOT_FOUND)
+		return res, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	if year == 0 {
+		year = time.Now().Year()
+	}
+	fromDate := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC).Format(schedule.DATE_LAYOUT)
+	toDate := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC).Format(schedule.DATE_LAYOUT)
+	holidays, err := scheduleService.scheduleRepository.FindHolidays(code, fromDate, toDate)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	response := vo.HolidayCalendarResponse{Code: calendar.Code, Name: calendar.Name, Holidays: []vo.HolidayResponse{}}
+	for _, holiday := range holidays {
+		response.Holidays = append(response.Holidays, vo.HolidayResponse{Date: holiday.Date, Name: holiday.Name, NameTH: holiday.NameTH})
+	}
+	res.SetData(response)
+	return res, fiber.StatusOK
+}
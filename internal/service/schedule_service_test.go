@@ -0,0 +1,211 @@
+package service_test
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/payment"
+	"booking/internal/service"
+	"booking/internal/vo"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestUpdateOpeningHours(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+	resource := &entity.Resource{ID: uuid.New(), TimeZone: constant.DEFAULT_TIME_ZONE}
+	body := dto.UpdateOpeningHoursDTO{
+		TimeZone:        "Asia/Bangkok",
+		HolidayCalendar: "TH",
+		Hours: []dto.WeekdayHoursDTO{
+			{Weekday: 1, Periods: []dto.OpeningPeriodDTO{{OpensAt: "09:00", ClosesAt: "12:00"}, {OpensAt: "13:00", ClosesAt: "18:00"}}},
+			{Weekday: 6, Periods: []dto.OpeningPeriodDTO{{OpensAt: "10:00", ClosesAt: "24:00"}}},
+		},
+	}
+
+	t.Run("When the user isn't an admin of the organization of the resource, should response status code 403 with error message", func(t *testing.T) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindResourceByID", resource.ID).Return(resource, nil)
+		scheduleRepositoryMock := newScheduleRepositoryMock()
+		scheduleService := service.NewScheduleService(authRepositoryMock, resourceRepositoryMock, scheduleRepositoryMock)
+
+		res, statusCode := scheduleService.UpdateOpeningHours(Username, resource.ID, &body)
+
+		assert.Equal(t, fiber.StatusForbidden, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_FORBIDDEN, res.ErrorMessage)
+		scheduleRepositoryMock.AssertNotCalled(t, "ReplaceOpeningHours", mock.Anything, mock.Anything)
+	})
+
+	t.Run("When the periods of a day overlap, should response status code 400 with error message", func(t *testing.T) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindResourceByID", resource.ID).Return(resource, nil)
+		scheduleRepositoryMock := newScheduleRepositoryMock()
+		scheduleRepositoryMock.On("FindHolidayCalendar", "TH").Return(&entity.HolidayCalendar{Code: "TH"}, nil)
+		scheduleService := service.NewScheduleService(authRepositoryMock, resourceRepositoryMock, scheduleRepositoryMock).WithContext(AdminContext)
+		body := body
+		body.Hours = []dto.WeekdayHoursDTO{
+			{Weekday: 1, Periods: []dto.OpeningPeriodDTO{{OpensAt: "09:00", ClosesAt: "14:00"}, {OpensAt: "13:00", ClosesAt: "18:00"}}},
+		}
+
+		res, statusCode := scheduleService.UpdateOpeningHours(Username, resource.ID, &body)
+
+		assert.Equal(t, fiber.StatusBadRequest, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_INVALID_OPENING_HOURS, res.ErrorMessage)
+	})
+
+	t.Run("When the holiday calendar does not exist, should response status code 400 with error message", func(t *testing.T) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindResourceByID", resource.ID).Return(resource, nil)
+		scheduleRepositoryMock := newScheduleRepositoryMock()
+		scheduleRepositoryMock.On("FindHolidayCalendar", "XX").Return(&entity.HolidayCalendar{}, gorm.ErrRecordNotFound)
+		scheduleService := service.NewScheduleService(authRepositoryMock, resourceRepositoryMock, scheduleRepositoryMock).WithContext(AdminContext)
+		body := body
+		body.HolidayCalendar = "XX"
+
+		res, statusCode := scheduleService.UpdateOpeningHours(Username, resource.ID, &body)
+
+		assert.Equal(t, fiber.StatusBadRequest, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_HOLIDAY_CALENDAR_NOT_FOUND, res.ErrorMessage)
+	})
+
+	t.Run("When the hours are valid, should replace them and response status code 200 with the schedule", func(t *testing.T) {
+		resource := *resource
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindResourceByID", resource.ID).Return(&resource, nil)
+		resourceRepositoryMock.On("UpdateResource", resource.ID).Return(nil)
+		scheduleRepositoryMock := &scheduleRepositoryMock{}
+		scheduleRepositoryMock.On("FindHolidayCalendar", "TH").Return(&entity.HolidayCalendar{Code: "TH"}, nil)
+		scheduleRepositoryMock.On("ReplaceOpeningHours", resource.ID, []entity.OpeningHours{
+			{ResourceID: resource.ID, Weekday: 1, Periods: entity.OpeningPeriods{{OpensAt: 540, ClosesAt: 720}, {OpensAt: 780, ClosesAt: 1080}}},
+			{ResourceID: resource.ID, Weekday: 6, Periods: entity.OpeningPeriods{{OpensAt: 600, ClosesAt: 1440}}},
+		}).Return(nil)
+		scheduleRepositoryMock.On("FindOpeningHours", resource.ID).Return([]entity.OpeningHours{
+			{ResourceID: resource.ID, Weekday: 6, Periods: entity.OpeningPeriods{{OpensAt: 600, ClosesAt: 1440}}},
+		}, nil)
+		scheduleRepositoryMock.On("FindDateOverrides", resource.ID).Return([]entity.DateOverride{}, nil)
+		scheduleRepositoryMock.On("FindBlackoutPeriods", resource.ID).Return([]entity.BlackoutPeriod{}, nil)
+		scheduleService := service.NewScheduleService(authRepositoryMock, resourceRepositoryMock, scheduleRepositoryMock).WithContext(AdminContext)
+
+		res, statusCode := scheduleService.UpdateOpeningHours(Username, resource.ID, &body)
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			schedule := res.Data.(vo.ScheduleResponse)
+			assert.Equal(t, "TH", schedule.HolidayCalendar)
+			assert.Equal(t, "Asia/Bangkok", schedule.TimeZone)
+			assert.Equal(t, []vo.OpeningPeriodResponse{{OpensAt: "10:00", ClosesAt: "24:00"}}, schedule.Hours[0].Periods)
+		}
+	})
+}
+
+func TestCreateBookingWithinSchedule(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+	resource := &entity.Resource{ID: uuid.New(), HourlyRate: 50000, Capacity: 1, TimeZone: "Asia/Bangkok", HolidayCalendarCode: "TH"}
+	location, _ := time.LoadLocation("Asia/Bangkok")
+	day := time.Now().In(location).AddDate(0, 0, 7)
+	startAt := time.Date(day.Year(), day.Month(), day.Day(), 10, 0, 0, 0, location)
+	date := startAt.Format("2006-01-02")
+	body := dto.CreateBookingDTO{
+		ResourceID: resource.ID.String(),
+		StartAt:    startAt,
+		EndAt:      startAt.Add(2 * time.Hour),
+	}
+	// the resource opens 09:00-11:00 and 11:00-17:00 on the weekday of the booking
+	openingHours := []entity.OpeningHours{
+		{ResourceID: resource.ID, Weekday: int(startAt.Weekday()), Periods: entity.OpeningPeriods{{OpensAt: 540, ClosesAt: 660}, {OpensAt: 660, ClosesAt: 1020}}},
+	}
+	newBookingService := func(scheduleRepositoryMock *scheduleRepositoryMock, bookingRepositoryMock *bookingRepositoryMock) service.IBookingService {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindCancellationPolicyByResourceID", resource.ID).Return(&entity.CancellationPolicy{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("FindPricingPolicyByResourceID", resource.ID).Return(&entity.PricingPolicy{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("FindPaymentScheduleByResourceID", resource.ID).Return(&entity.PaymentSchedule{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("IsResourceMember", resource.ID, user.ID).Return(false, nil)
+		bookingRepositoryMock.On("LockResourceByID", resource.ID).Return(resource, nil)
+		bookingRepositoryMock.On("FindOverlappingBookings", resource.ID, mock.MatchedBy(startAt.Equal)).Return([]entity.Booking{}, nil)
+		bookingRepositoryMock.On("CreateBooking", resource.ID).Return(nil)
+		return service.NewBookingService(authRepositoryMock, resourceRepositoryMock, bookingRepositoryMock, newWaitlistRepositoryMock(), scheduleRepositoryMock, &providerRepositoryMock{}, &promoCodeRepositoryMock{}, newPaymentRepositoryMock(), newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret))
+	}
+	newScheduleOf := func(hours []entity.OpeningHours, overrides []entity.DateOverride, holidays []entity.Holiday, blackouts []entity.BlackoutPeriod) *scheduleRepositoryMock {
+		scheduleRepositoryMock := &scheduleRepositoryMock{}
+		scheduleRepositoryMock.On("FindOpeningHours", resource.ID).Return(hours, nil)
+		scheduleRepositoryMock.On("FindDateOverrides", resource.ID).Return(overrides, nil)
+		scheduleRepositoryMock.On("FindHolidays", "TH").Return(holidays, nil)
+		scheduleRepositoryMock.On("FindBlackoutPeriods", resource.ID).Return(blackouts, nil)
+		return scheduleRepositoryMock
+	}
+
+	t.Run("When the booking spans consecutive opening periods, should book it", func(t *testing.T) {
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingService := newBookingService(newScheduleOf(openingHours, nil, nil, nil), bookingRepositoryMock)
+
+		_, statusCode := bookingService.CreateBooking(Username, &body)
+
+		assert.Equal(t, fiber.StatusCreated, statusCode)
+		bookingRepositoryMock.AssertCalled(t, "CreateBooking", resource.ID)
+	})
+
+	t.Run("When the resource is closed on the weekday, should response status code 409 with error message", func(t *testing.T) {
+		closedHours := []entity.OpeningHours{
+			{ResourceID: resource.ID, Weekday: int(startAt.AddDate(0, 0, 1).Weekday()), Periods: entity.OpeningPeriods{{OpensAt: 0, ClosesAt: 1440}}},
+		}
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingService := newBookingService(newScheduleOf(closedHours, nil, nil, nil), bookingRepositoryMock)
+
+		res, statusCode := bookingService.CreateBooking(Username, &body)
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_RESOURCE_CLOSED, res.ErrorMessage)
+		bookingRepositoryMock.AssertNotCalled(t, "CreateBooking", resource.ID)
+	})
+
+	t.Run("When the date is a public holiday, should response status code 409 with error message", func(t *testing.T) {
+		holidays := []entity.Holiday{{CalendarCode: "TH", Date: date, Name: "Holiday"}}
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingService := newBookingService(newScheduleOf(openingHours, nil, holidays, nil), bookingRepositoryMock)
+
+		res, statusCode := bookingService.CreateBooking(Username, &body)
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_RESOURCE_CLOSED, res.ErrorMessage)
+	})
+
+	t.Run("When the holiday is overridden to open, should book it", func(t *testing.T) {
+		holidays := []entity.Holiday{{CalendarCode: "TH", Date: date, Name: "Holiday"}}
+		overrides := []entity.DateOverride{{ResourceID: resource.ID, Date: date, Periods: entity.OpeningPeriods{{OpensAt: 600, ClosesAt: 720}}}}
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingService := newBookingService(newScheduleOf(openingHours, overrides, holidays, nil), bookingRepositoryMock)
+
+		_, statusCode := bookingService.CreateBooking(Username, &body)
+
+		assert.Equal(t, fiber.StatusCreated, statusCode)
+	})
+
+	t.Run("When the booking overlaps a blackout, should response status code 409 with error message", func(t *testing.T) {
+		blackouts := []entity.BlackoutPeriod{{ResourceID: resource.ID, StartAt: startAt.Add(time.Hour), EndAt: startAt.Add(3 * time.Hour)}}
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingService := newBookingService(newScheduleOf(openingHours, nil, nil, blackouts), bookingRepositoryMock)
+
+		res, statusCode := bookingService.CreateBooking(Username, &body)
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_RESOURCE_BLACKED_OUT, res.ErrorMessage)
+	})
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"booking/internal/constant"
+	"booking/internal/entity"
+	"booking/internal/logs"
+	"booking/internal/repository"
+	"errors"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+// findUser resolves the username of the access token into the user record.
+// When it fails, it returns the error message and status code to respond with.
+func findUser(authRepository repository.IAuthRepository, username string) (user *entity.User, errorMessage string, statusCode int) {
+	user, err := authRepository.FindUserByUsername(username)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, constant.ERROR_MESSAGE_UNAUTHORIZED, fiber.StatusUnauthorized
+	}
+	if err != nil {
+		logs.Error(err)
+		return nil, constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR, fiber.StatusInternalServerError
+	}
+	return user, "", fiber.StatusOK
+}
+
+// findAdmin resolves the username into the user record like findUser, only
+// letting through the administrators listed in the configuration.
+func findAdmin(authRepository repository.IAuthRepository, username string) (user *entity.User, errorMessage string, statusCode int) {
+	user, errorMessage, statusCode = findUser(authRepository, username)
+	if user == nil {
+		return nil, errorMessage, statusCode
+	}
+	if !slices.Contains(viper.GetStringSlice("admin.usernames"), user.Username) {
+		return nil, constant.ERROR_MESSAGE_FORBIDDEN, fiber.StatusForbidden
+	}
+	return user, "", fiber.StatusOK
+}
@@ -0,0 +1,452 @@
+package service
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/logs"
+	"booking/internal/notification"
+	"booking/internal/payment"
+	"booking/internal/repository"
+	"booking/internal/vo"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+type IWaitlistService interface {
+	WithContext(ctx context.Context) IWaitlistService
+	JoinWaitlist(username string, joinWaitlistDTO *dto.JoinWaitlistDTO) (res vo.Response, statusCode int)
+	GetWaitlistEntries(username string) (res vo.Response, statusCode int)
+	LeaveWaitlist(username string, entryID uuid.UUID) (res vo.Response, statusCode int)
+	ClaimOffer(username string, entryID uuid.UUID) (res vo.Response, statusCode int)
+}
+
+type waitlistService struct {
+	authRepository         repository.IAuthRepository
+	resourceRepository     repository.IResourceRepository
+	bookingRepository      repository.IBookingRepository
+	waitlistRepository     repository.IWaitlistRepository
+	scheduleRepository     repository.IScheduleRepository
+	paymentRepository      repository.IPaymentRepository
+	ledgerRepository       repository.ILedgerRepository
+	notificationRepository repository.INotificationRepository
+	paymentProvider        payment.IPaymentProvider
+}
+
+var (
+	errWaitlistEntryNotFound     = errors.New(constant.ERROR_MESSAGE_WAITLIST_ENTRY_NOT_FOUND)
+	errWaitlistOfferNotAvailable = errors.New(constant.ERROR_MESSAGE_WAITLIST_OFFER_NOT_AVAILABLE)
+	errAlreadyWaitlisted         = errors.New(constant.ERROR_MESSAGE_ALREADY_WAITLISTED)
+	errBookingSlotAvailable      = errors.New(constant.ERROR_MESSAGE_BOOKING_SLOT_AVAILABLE)
+	errWaitlistEntryClosed       = errors.New(constant.ERROR_MESSAGE_WAITLIST_ENTRY_CLOSED)
+)
+
+func NewWaitlistService(authRepository repository.IAuthRepository, resourceRepository repository.IResourceRepository, bookingRepository repository.IBookingRepository, waitlistRepository repository.IWaitlistRepository, scheduleRepository repository.IScheduleRepository, paymentRepository repository.IPaymentRepository, ledgerRepository repository.ILedgerRepository, notificationRepository repository.INotificationRepository, paymentProvider payment.IPaymentProvider) *waitlistService {
+	return &waitlistService{authRepository: authRepository, resourceRepository: resourceRepository, bookingRepository: bookingRepository, waitlistRepository: waitlistRepository, scheduleRepository: scheduleRepository, paymentRepository: paymentRepository, ledgerRepository: ledgerRepository, notificationRepository: notificationRepository, paymentProvider: paymentProvider}
+}
+
+func (waitlistService *waitlistService) WithContext(ctx context.Context) IWaitlistService {
+	service := *waitlistService
+	service.resourceRepository = waitlistService.resourceRepository.WithContext(ctx)
+	service.bookingRepository = waitlistService.bookingRepository.WithContext(ctx)
+	service.waitlistRepository = waitlistService.waitlistRepository.WithContext(ctx)
+	service.paymentRepository = waitlistService.paymentRepository.WithContext(ctx)
+	return &service
+}
+
+// claimWindow returns how long a waitlisted user has to claim an offered slot.
+func claimWindow() time.Duration {
+	claimMinutes := viper.GetInt("booking.waitlist.claim_minutes")
+	if claimMinutes <= 0 {
+		claimMinutes = 15
+	}
+	return time.Duration(claimMinutes) * time.Minute
+}
+
+// promoteWaitlist expires the offers and entries of the resource that are past
+// their stored expiry, then offers every range that is free again to the user
+// who has waited for it the longest. It must run in a transaction holding the
+// resource lock so offers and bookings of the resource are serialized.
+func promoteWaitlist(bookingRepository repository.IBookingRepository, waitlistRepository repository.IWaitlistRepository, scheduleRepository repository.IScheduleRepository, resource *entity.Resource, now time.Time) error {
+	err := waitlistRepository.ExpireEntries(resource.ID, now)
+	if err != nil {
+		return err
+	}
+
+	entries, err := waitlistRepository.FindWaitingEntries(resource.ID)
+	if err != nil || len(entries) == 0 {
+		return err
+	}
+
+	startAt, endAt := entries[0].StartAt, entries[0].EndAt
+	for _, entry := range entries {
+		if entry.StartAt.Before(startAt) {
+			startAt = entry.StartAt
+		}
+		if entry.EndAt.After(endAt) {
+			endAt = entry.EndAt
+		}
+	}
+	resourceSchedule, err := loadSchedule(scheduleRepository, resource, startAt, endAt)
+	if err != nil {
+		return err
+	}
+
+	for i := range entries {
+		// an entry waiting for more seats than are free doesn't hold back
+		// smaller entries behind it, nor does one the resource closed for
+		entry := &entries[i]
+		if scheduleConflict(resourceSchedule, entry.StartAt, entry.EndAt) != "" {
+			continue
+		}
+		available, err := findAvailableSeats(bookingRepository, waitlistRepository, resource, entry.StartAt, entry.EndAt, now, uuid.Nil, nil)
+		if err != nil {
+			return err
+		}
+		if available < entry.Seats {
+			continue
+		}
+
+		offeredAt := now
+		offerExpiresAt := now.Add(claimWindow())
+		if offerExpiresAt.After(entry.StartAt) {
+			offerExpiresAt = entry.StartAt
+		}
+		entry.Status = entity.WAITLIST_STATUS_OFFERED
+		entry.OfferedAt = &offeredAt
+		entry.OfferExpiresAt = &offerExpiresAt
+		err = waitlistRepository.UpdateEntry(entry)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (waitlistService *waitlistService) newWaitlistEntryResponse(entry *entity.WaitlistEntry) (vo.WaitlistEntryResponse, error) {
+	response := vo.NewWaitlistEntryResponse(entry)
+	if entry.Status != entity.WAITLIST_STATUS_WAITING {
+		return response, nil
+	}
+	ahead, err := waitlistService.waitlistRepository.CountWaitingAhead(entry)
+	if err != nil {
+		return response, err
+	}
+	response.Position = ahead + 1
+	return response, nil
+}
+
+// JoinWaitlist queues the user for a range of the resource that is not
+// available to book right now.
+func (waitlistService *waitlistService) JoinWaitlist(username string, joinWaitlistDTO *dto.JoinWaitlistDTO) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(waitlistService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	now := time.Now()
+	if !joinWaitlistDTO.StartAt.After(now) {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_BOOKING_IN_THE_PAST)
+		return res, fiber.StatusBadRequest
+	}
+
+	resourceID := uuid.MustParse(joinWaitlistDTO.ResourceID)
+	entry := entity.WaitlistEntry{
+		UserID:     user.ID,
+		ResourceID: resourceID,
+		StartAt:    joinWaitlistDTO.StartAt,
+		EndAt:      joinWaitlistDTO.EndAt,
+		Seats:      max(joinWaitlistDTO.Seats, 1),
+		Status:     entity.WAITLIST_STATUS_WAITING,
+	}
+	err := waitlistService.bookingRepository.Transaction(func(tx *gorm.DB) error {
+		bookingRepository := waitlistService.bookingRepository.WithTx(tx)
+		waitlistRepository := waitlistService.waitlistRepository.WithTx(tx)
+		resource, err := bookingRepository.LockResourceByID(resourceID)
+		if err != nil {
+			return err
+		}
+		if entry.Seats > resource.Capacity {
+			return errSeatsExceedCapacity
+		}
+
+		err = promoteWaitlist(bookingRepository, waitlistRepository, waitlistService.scheduleRepository, resource, now)
+		if err != nil {
+			return err
+		}
+
+		count, err := waitlistRepository.CountActiveEntriesOfUser(user.ID, resourceID, entry.StartAt, entry.EndAt)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return errAlreadyWaitlisted
+		}
+
+		resourceSchedule, err := loadSchedule(waitlistService.scheduleRepository, resource, entry.StartAt, entry.EndAt)
+		if err != nil {
+			return err
+		}
+		reason, err := findSlotConflict(bookingRepository, waitlistRepository, resource, resourceSchedule, entry.StartAt, entry.EndAt, entry.Seats, now, user.ID, nil)
+		if err != nil {
+			return err
+		}
+		if reason == "" {
+			return errBookingSlotAvailable
+		}
+		// waiting is pointless while the resource is closed
+		if reason != constant.ERROR_MESSAGE_BOOKING_SLOT_UNAVAILABLE {
+			return slotConflictError(reason)
+		}
+
+		return waitlistRepository.CreateEntry(&entry)
+	})
+	if err != nil {
+		return res, setBookingError(&res, err)
+	}
+
+	response, err := waitlistService.newWaitlistEntryResponse(&entry)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	res.SetData(response)
+	return res, fiber.StatusCreated
+}
+
+// GetWaitlistEntries lists the entries of the user. Offers past their expiry
+// are moved on first, so the list shows the current state without depending
+// on a timer having fired.
+func (waitlistService *waitlistService) GetWaitlistEntries(username string) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(waitlistService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	entries, err := waitlistService.waitlistRepository.FindEntriesByUserID(user.ID)
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+
+	now := time.Now()
+	promoted := map[uuid.UUID]bool{}
+	for _, entry := range entries {
+		isOpen := entry.Status == entity.WAITLIST_STATUS_WAITING || entry.Status == entity.WAITLIST_STATUS_OFFERED
+		if !isOpen || promoted[entry.ResourceID] {
+			continue
+		}
+		promoted[entry.ResourceID] = true
+		err = waitlistService.bookingRepository.Transaction(func(tx *gorm.DB) error {
+			bookingRepository := waitlistService.bookingRepository.WithTx(tx)
+			resource, err := bookingRepository.LockResourceByID(entry.ResourceID)
+			if err != nil {
+				return err
+			}
+			return promoteWaitlist(bookingRepository, waitlistService.waitlistRepository.WithTx(tx), waitlistService.scheduleRepository, resource, now)
+		})
+		if err != nil {
+			logs.Error(err)
+			res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+			return res, fiber.StatusInternalServerError
+		}
+	}
+	if len(promoted) > 0 {
+		entries, err = waitlistService.waitlistRepository.FindEntriesByUserID(user.ID)
+		if err != nil {
+			logs.Error(err)
+			res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+			return res, fiber.StatusInternalServerError
+		}
+	}
+
+	responses := []vo.WaitlistEntryResponse{}
+	for i := range entries {
+		response, err := waitlistService.newWaitlistEntryResponse(&entries[i])
+		if err != nil {
+			logs.Error(err)
+			res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+			return res, fiber.StatusInternalServerError
+		}
+		responses = append(responses, response)
+	}
+
+	res.SetData(responses)
+	return res, fiber.StatusOK
+}
+
+// lockOwnEntry locks the waitlist entry of the user after locking its resource.
+func lockOwnEntry(bookingRepository repository.IBookingRepository, waitlistRepository repository.IWaitlistRepository, entryID uuid.UUID, user *entity.User) (*entity.Resource, *entity.WaitlistEntry, error) {
+	entry, err := waitlistRepository.FindEntryByID(entryID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && entry.UserID != user.ID) {
+		return nil, nil, errWaitlistEntryNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resource, err := bookingRepository.LockResourceByID(entry.ResourceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	entry, err = waitlistRepository.LockEntryByID(entryID)
+	return resource, entry, err
+}
+
+func (waitlistService *waitlistService) LeaveWaitlist(username string, entryID uuid.UUID) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(waitlistService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	var entry *entity.WaitlistEntry
+	err := waitlistService.bookingRepository.Transaction(func(tx *gorm.DB) error {
+		bookingRepository := waitlistService.bookingRepository.WithTx(tx)
+		waitlistRepository := waitlistService.waitlistRepository.WithTx(tx)
+		resource, lockedEntry, err := lockOwnEntry(bookingRepository, waitlistRepository, entryID, user)
+		if err != nil {
+			return err
+		}
+		entry = lockedEntry
+		if entry.Status != entity.WAITLIST_STATUS_WAITING && entry.Status != entity.WAITLIST_STATUS_OFFERED {
+			return errWaitlistEntryClosed
+		}
+
+		entry.Status = entity.WAITLIST_STATUS_CANCELLED
+		err = waitlistRepository.UpdateEntry(entry)
+		if err != nil {
+			return err
+		}
+
+		// a declined offer goes to the next user in line
+		return promoteWaitlist(bookingRepository, waitlistRepository, waitlistService.scheduleRepository, resource, time.Now())
+	})
+	if err != nil {
+		return res, setBookingError(&res, err)
+	}
+
+	res.SetData(vo.NewWaitlistEntryResponse(entry))
+	return res, fiber.StatusOK
+}
+
+// ClaimOffer books the slot offered to the user before the offer expires.
+func (waitlistService *waitlistService) ClaimOffer(username string, entryID uuid.UUID) (res vo.Response, statusCode int) {
+	user, errorMessage, statusCode := findUser(waitlistService.authRepository, username)
+	if user == nil {
+		res.SetErrorMessage(errorMessage)
+		return res, statusCode
+	}
+
+	penalty, err := findNoShowPenalty(waitlistService.bookingRepository, user.ID, time.Now())
+	if err != nil {
+		logs.Error(err)
+		res.SetErrorMessage(constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR)
+		return res, fiber.StatusInternalServerError
+	}
+	if penalty.BannedUntil != nil {
+		res.SetErrorMessage(constant.ERROR_MESSAGE_BOOKING_BANNED)
+		return res, fiber.StatusForbidden
+	}
+
+	var booking *entity.Booking
+	var paymentResponse *vo.PaymentResponse
+	err = waitlistService.bookingRepository.Transaction(func(tx *gorm.DB) error {
+		bookingRepository := waitlistService.bookingRepository.WithTx(tx)
+		waitlistRepository := waitlistService.waitlistRepository.WithTx(tx)
+		resource, entry, err := lockOwnEntry(bookingRepository, waitlistRepository, entryID, user)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if entry.Status != entity.WAITLIST_STATUS_OFFERED || !now.Before(*entry.OfferExpiresAt) {
+			return errWaitlistOfferNotAvailable
+		}
+
+		resourceSchedule, err := loadSchedule(waitlistService.scheduleRepository, resource, entry.StartAt, entry.EndAt)
+		if err != nil {
+			return err
+		}
+		reason, err := findSlotConflict(bookingRepository, waitlistRepository, resource, resourceSchedule, entry.StartAt, entry.EndAt, entry.Seats, now, user.ID, nil)
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			return errWaitlistOfferNotAvailable
+		}
+
+		cancellationRules, err := findCancellationRules(waitlistService.resourceRepository, entry.ResourceID)
+		if err != nil {
+			return err
+		}
+		pricingRules, member, err := findPricing(waitlistService.resourceRepository, entry.ResourceID, user.ID)
+		if err != nil {
+			return err
+		}
+		paymentSchedule, err := findPaymentSchedule(waitlistService.resourceRepository, entry.ResourceID)
+		if err != nil {
+			return err
+		}
+		quote := newPricer(resource, pricingRules, member).Quote(entry.StartAt, entry.EndAt, entry.Seats)
+		booking = &entity.Booking{
+			UserID:             user.ID,
+			ResourceID:         entry.ResourceID,
+			StartAt:            entry.StartAt,
+			EndAt:              entry.EndAt,
+			Status:             entity.BOOKING_STATUS_CONFIRMED,
+			Seats:              entry.Seats,
+			Amount:             quote.Total,
+			Quote:              quote,
+			CancellationPolicy: cancellationRules,
+		}
+		penalty.chargeFee(booking)
+		// paid bookings hold the slot while pending until the deposit is paid
+		schedulePayments(booking, paymentSchedule, now)
+		penalty.requireDeposit(booking)
+		err = bookingRepository.CreateBooking(booking)
+		if err != nil {
+			return err
+		}
+		err = penalty.chargeNoShows(bookingRepository, booking)
+		if err != nil {
+			return err
+		}
+
+		entry.Status = entity.WAITLIST_STATUS_CLAIMED
+		entry.BookingID = &booking.ID
+		err = waitlistRepository.UpdateEntry(entry)
+		if err != nil {
+			return err
+		}
+		err = postBooking(waitlistService.ledgerRepository.WithTx(tx), resource.OrganizationID, booking)
+		if err != nil {
+			return err
+		}
+
+		if booking.Status != entity.BOOKING_STATUS_PENDING {
+			return notifyBooking(waitlistService.notificationRepository.WithTx(tx), resource, booking, notification.EVENT_BOOKING_CONFIRMED, now)
+		}
+		paymentResponse, err = startPayment(waitlistService.paymentRepository.WithTx(tx), waitlistService.paymentProvider, booking, booking.DepositAmount())
+		return err
+	})
+	if err != nil {
+		return res, setBookingError(&res, err)
+	}
+
+	response := vo.NewBookingResponse(booking)
+	response.Payment = paymentResponse
+	res.SetData(response)
+	return res, fiber.StatusCreated
+}
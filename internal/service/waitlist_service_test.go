@@ -0,0 +1,242 @@
+package service_test
+
+import (
+	"booking/internal/constant"
+	"booking/internal/dto"
+	"booking/internal/entity"
+	"booking/internal/payment"
+	"booking/internal/service"
+	"booking/internal/vo"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestJoinWaitlist(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+	resource := &entity.Resource{ID: uuid.New(), HourlyRate: 50000, Capacity: 1}
+	startAt := time.Now().Add(72 * time.Hour).Truncate(time.Hour)
+	body := dto.JoinWaitlistDTO{
+		ResourceID: resource.ID.String(),
+		StartAt:    startAt,
+		EndAt:      startAt.Add(time.Hour),
+	}
+
+	t.Run("When the slot is free, should response status code 409 with error message", func(t *testing.T) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("LockResourceByID", resource.ID).Return(resource, nil)
+		bookingRepositoryMock.On("FindOverlappingBookings", resource.ID, mock.Anything).Return([]entity.Booking{}, nil)
+		waitlistRepositoryMock := newWaitlistRepositoryMock()
+		waitlistRepositoryMock.On("CountActiveEntriesOfUser", user.ID, resource.ID).Return(int64(0), nil)
+		waitlistService := service.NewWaitlistService(authRepositoryMock, &resourceRepositoryMock{}, bookingRepositoryMock, waitlistRepositoryMock, newScheduleRepositoryMock(), newPaymentRepositoryMock(), newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret))
+
+		res, statusCode := waitlistService.JoinWaitlist(Username, &body)
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_BOOKING_SLOT_AVAILABLE, res.ErrorMessage)
+		waitlistRepositoryMock.AssertNotCalled(t, "CreateEntry", resource.ID)
+	})
+
+	t.Run("When the slot is booked, should queue the user as waiting", func(t *testing.T) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("LockResourceByID", resource.ID).Return(resource, nil)
+		bookingRepositoryMock.On("FindOverlappingBookings", resource.ID, mock.Anything).Return([]entity.Booking{{ID: uuid.New(), StartAt: startAt, EndAt: startAt.Add(time.Hour), Seats: 1}}, nil)
+		waitlistRepositoryMock := newWaitlistRepositoryMock()
+		waitlistRepositoryMock.On("CountActiveEntriesOfUser", user.ID, resource.ID).Return(int64(0), nil)
+		waitlistRepositoryMock.On("CreateEntry", resource.ID).Return(nil)
+		waitlistRepositoryMock.On("CountWaitingAhead", mock.Anything).Return(int64(2), nil)
+		waitlistService := service.NewWaitlistService(authRepositoryMock, &resourceRepositoryMock{}, bookingRepositoryMock, waitlistRepositoryMock, newScheduleRepositoryMock(), newPaymentRepositoryMock(), newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret))
+
+		res, statusCode := waitlistService.JoinWaitlist(Username, &body)
+
+		isPass := assert.Equal(t, fiber.StatusCreated, statusCode)
+		if isPass {
+			entry := res.Data.(vo.WaitlistEntryResponse)
+			assert.Equal(t, entity.WAITLIST_STATUS_WAITING, entry.Status)
+			assert.Equal(t, int64(3), entry.Position)
+		}
+	})
+
+	t.Run("When the user is already waiting for the slot, should response status code 409 with error message", func(t *testing.T) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("LockResourceByID", resource.ID).Return(resource, nil)
+		waitlistRepositoryMock := newWaitlistRepositoryMock()
+		waitlistRepositoryMock.On("CountActiveEntriesOfUser", user.ID, resource.ID).Return(int64(1), nil)
+		waitlistService := service.NewWaitlistService(authRepositoryMock, &resourceRepositoryMock{}, bookingRepositoryMock, waitlistRepositoryMock, newScheduleRepositoryMock(), newPaymentRepositoryMock(), newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret))
+
+		res, statusCode := waitlistService.JoinWaitlist(Username, &body)
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_ALREADY_WAITLISTED, res.ErrorMessage)
+	})
+}
+
+func TestClaimOffer(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+	resource := &entity.Resource{ID: uuid.New(), HourlyRate: 50000, Capacity: 1}
+	newOffer := func(expiresAt time.Time) *entity.WaitlistEntry {
+		startAt := time.Now().Add(72 * time.Hour).Truncate(time.Hour)
+		offeredAt := expiresAt.Add(-15 * time.Minute)
+		return &entity.WaitlistEntry{
+			ID:             uuid.New(),
+			UserID:         user.ID,
+			ResourceID:     resource.ID,
+			StartAt:        startAt,
+			EndAt:          startAt.Add(2 * time.Hour),
+			Seats:          1,
+			Status:         entity.WAITLIST_STATUS_OFFERED,
+			OfferedAt:      &offeredAt,
+			OfferExpiresAt: &expiresAt,
+		}
+	}
+
+	t.Run("When claim an offer in time, should book the slot pending payment and mark the entry claimed", func(t *testing.T) {
+		entry := newOffer(time.Now().Add(10 * time.Minute))
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindCancellationPolicyByResourceID", resource.ID).Return(&entity.CancellationPolicy{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("FindPricingPolicyByResourceID", resource.ID).Return(&entity.PricingPolicy{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("FindPaymentScheduleByResourceID", resource.ID).Return(&entity.PaymentSchedule{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("IsResourceMember", resource.ID, user.ID).Return(false, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("LockResourceByID", resource.ID).Return(resource, nil)
+		bookingRepositoryMock.On("FindOverlappingBookings", resource.ID, mock.Anything).Return([]entity.Booking{}, nil)
+		bookingRepositoryMock.On("CreateBooking", resource.ID).Return(nil)
+		waitlistRepositoryMock := newWaitlistRepositoryMock()
+		waitlistRepositoryMock.On("FindEntryByID", entry.ID).Return(entry, nil)
+		waitlistRepositoryMock.On("LockEntryByID", entry.ID).Return(entry, nil)
+		waitlistRepositoryMock.On("UpdateEntry", entry.ID).Return(nil)
+		waitlistService := service.NewWaitlistService(authRepositoryMock, resourceRepositoryMock, bookingRepositoryMock, waitlistRepositoryMock, newScheduleRepositoryMock(), newPaymentRepositoryMock(), newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret))
+
+		res, statusCode := waitlistService.ClaimOffer(Username, entry.ID)
+
+		isPass := assert.Equal(t, fiber.StatusCreated, statusCode)
+		if isPass {
+			booking := res.Data.(vo.BookingResponse)
+			assert.Equal(t, entity.BOOKING_STATUS_PENDING, booking.Status)
+			assert.Equal(t, int64(100000), booking.Amount)
+			assert.Equal(t, int64(100000), booking.Payment.Amount)
+			assert.Equal(t, entity.WAITLIST_STATUS_CLAIMED, entry.Status)
+		}
+	})
+
+	t.Run("When the offer has expired, should response status code 409 with error message", func(t *testing.T) {
+		entry := newOffer(time.Now().Add(-time.Minute))
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("LockResourceByID", resource.ID).Return(resource, nil)
+		waitlistRepositoryMock := newWaitlistRepositoryMock()
+		waitlistRepositoryMock.On("FindEntryByID", entry.ID).Return(entry, nil)
+		waitlistRepositoryMock.On("LockEntryByID", entry.ID).Return(entry, nil)
+		waitlistService := service.NewWaitlistService(authRepositoryMock, &resourceRepositoryMock{}, bookingRepositoryMock, waitlistRepositoryMock, newScheduleRepositoryMock(), newPaymentRepositoryMock(), newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret))
+
+		res, statusCode := waitlistService.ClaimOffer(Username, entry.ID)
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_WAITLIST_OFFER_NOT_AVAILABLE, res.ErrorMessage)
+		bookingRepositoryMock.AssertNotCalled(t, "CreateBooking", resource.ID)
+	})
+
+	t.Run("When the entry belongs to another user, should response status code 404 with error message", func(t *testing.T) {
+		entry := newOffer(time.Now().Add(10 * time.Minute))
+		entry.UserID = uuid.New()
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		waitlistRepositoryMock := newWaitlistRepositoryMock()
+		waitlistRepositoryMock.On("FindEntryByID", entry.ID).Return(entry, nil)
+		waitlistService := service.NewWaitlistService(authRepositoryMock, &resourceRepositoryMock{}, &bookingRepositoryMock{}, waitlistRepositoryMock, newScheduleRepositoryMock(), newPaymentRepositoryMock(), newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret))
+
+		res, statusCode := waitlistService.ClaimOffer(Username, entry.ID)
+
+		assert.Equal(t, fiber.StatusNotFound, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_WAITLIST_ENTRY_NOT_FOUND, res.ErrorMessage)
+	})
+}
+
+func TestWaitlistPromotion(t *testing.T) {
+	const Username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: Username}
+	resource := &entity.Resource{ID: uuid.New(), Capacity: 1}
+	startAt := time.Now().Add(72 * time.Hour).Truncate(time.Hour)
+
+	t.Run("When a booking is cancelled, should offer the slot to the first waiting user until the claim window ends", func(t *testing.T) {
+		booking := &entity.Booking{
+			ID:         uuid.New(),
+			UserID:     user.ID,
+			ResourceID: resource.ID,
+			StartAt:    startAt,
+			EndAt:      startAt.Add(time.Hour),
+			Status:     entity.BOOKING_STATUS_CONFIRMED,
+			Seats:      1,
+		}
+		entries := []entity.WaitlistEntry{
+			{ID: uuid.New(), UserID: uuid.New(), ResourceID: resource.ID, StartAt: booking.StartAt, EndAt: booking.EndAt, Seats: 1, Status: entity.WAITLIST_STATUS_WAITING},
+		}
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("FindBookingByID", booking.ID).Return(booking, nil)
+		bookingRepositoryMock.On("LockResourceByID", resource.ID).Return(resource, nil)
+		bookingRepositoryMock.On("LockBookingByID", booking.ID).Return(booking, nil)
+		bookingRepositoryMock.On("UpdateBooking", booking.ID).Return(nil)
+		bookingRepositoryMock.On("FindOverlappingBookings", resource.ID, startAt).Return([]entity.Booking{}, nil)
+		waitlistRepositoryMock := &waitlistRepositoryMock{}
+		waitlistRepositoryMock.On("ExpireEntries", resource.ID).Return(nil)
+		waitlistRepositoryMock.On("FindWaitingEntries", resource.ID).Return(entries, nil)
+		waitlistRepositoryMock.On("FindActiveOffers", resource.ID, uuid.Nil).Return([]entity.WaitlistEntry{}, nil)
+		waitlistRepositoryMock.On("UpdateEntry", entries[0].ID).Return(nil)
+		bookingService := service.NewBookingService(authRepositoryMock, &resourceRepositoryMock{}, bookingRepositoryMock, waitlistRepositoryMock, newScheduleRepositoryMock(), &providerRepositoryMock{}, &promoCodeRepositoryMock{}, newPaymentRepositoryMock(), newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret))
+
+		_, statusCode := bookingService.CancelBooking(Username, booking.ID, &dto.CancelBookingDTO{Confirm: true})
+
+		isPass := assert.Equal(t, fiber.StatusOK, statusCode)
+		if isPass {
+			assert.Equal(t, entity.WAITLIST_STATUS_OFFERED, entries[0].Status)
+			assert.WithinDuration(t, time.Now().Add(15*time.Minute), *entries[0].OfferExpiresAt, time.Minute)
+		}
+		waitlistRepositoryMock.AssertCalled(t, "UpdateEntry", entries[0].ID)
+	})
+
+	t.Run("When another user holds an offer for the slot, should response status code 409 with error message", func(t *testing.T) {
+		authRepositoryMock := &authRepositoryMock{}
+		authRepositoryMock.On("FindUserByUsername", Username).Return(user, nil)
+		resourceRepositoryMock := &resourceRepositoryMock{}
+		resourceRepositoryMock.On("FindCancellationPolicyByResourceID", resource.ID).Return(&entity.CancellationPolicy{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("FindPricingPolicyByResourceID", resource.ID).Return(&entity.PricingPolicy{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("FindPaymentScheduleByResourceID", resource.ID).Return(&entity.PaymentSchedule{}, gorm.ErrRecordNotFound)
+		resourceRepositoryMock.On("IsResourceMember", resource.ID, user.ID).Return(false, nil)
+		bookingRepositoryMock := &bookingRepositoryMock{}
+		bookingRepositoryMock.On("LockResourceByID", resource.ID).Return(resource, nil)
+		bookingRepositoryMock.On("FindOverlappingBookings", resource.ID, startAt).Return([]entity.Booking{}, nil)
+		waitlistRepositoryMock := &waitlistRepositoryMock{}
+		waitlistRepositoryMock.On("ExpireEntries", resource.ID).Return(nil)
+		waitlistRepositoryMock.On("FindWaitingEntries", resource.ID).Return([]entity.WaitlistEntry{}, nil)
+		waitlistRepositoryMock.On("FindActiveOffers", resource.ID, user.ID).Return([]entity.WaitlistEntry{{StartAt: startAt, EndAt: startAt.Add(time.Hour), Seats: 1}}, nil)
+		bookingService := service.NewBookingService(authRepositoryMock, resourceRepositoryMock, bookingRepositoryMock, waitlistRepositoryMock, newScheduleRepositoryMock(), &providerRepositoryMock{}, &promoCodeRepositoryMock{}, newPaymentRepositoryMock(), newLedgerRepositoryMock(), newNotificationRepositoryMock(), payment.NewFakeProvider(WebhookSecret))
+
+		res, statusCode := bookingService.CreateBooking(Username, &dto.CreateBookingDTO{
+			ResourceID: resource.ID.String(),
+			StartAt:    startAt,
+			EndAt:      startAt.Add(time.Hour),
+		})
+
+		assert.Equal(t, fiber.StatusConflict, statusCode)
+		assert.Equal(t, constant.ERROR_MESSAGE_BOOKING_SLOT_UNAVAILABLE, res.ErrorMessage)
+		bookingRepositoryMock.AssertNotCalled(t, "CreateBooking", resource.ID)
+	})
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStore keeps blobs as files under a directory.
+type LocalStore struct {
+	root string
+}
+
+func NewLocalStore(root string) *LocalStore {
+	return &LocalStore{root: root}
+}
+
+// path returns the file of the blob, or ErrInvalidKey for keys leaving the
+// directory of the store.
+func (store *LocalStore) path(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if key == "" || strings.HasSuffix(key, "/") || cleaned != "/"+key {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(store.root, filepath.FromSlash(cleaned)), nil
+}
+
+// Put writes the blob to a temporary file renamed over the previous one, so
+// readers never see it half written.
+func (store *LocalStore) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	name, err := store.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(name), 0o755)
+	if err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	_, err = io.Copy(file, body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), name)
+}
+
+func (store *LocalStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	name, err := store.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+func (store *LocalStore) Delete(ctx context.Context, key string) error {
+	name, err := store.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
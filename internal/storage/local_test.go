@@ -0,0 +1,48 @@
+package storage_test
+
+import (
+	"booking/internal/storage"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("When put a blob, should open it with the same content and delete it", func(t *testing.T) {
+		store := storage.NewLocalStore(t.TempDir())
+
+		err := store.Put(ctx, "resources/a/photo.jpg", strings.NewReader("photo"), 5, "image/jpeg")
+
+		if assert.NoError(t, err) {
+			file, err := store.Open(ctx, "resources/a/photo.jpg")
+			if assert.NoError(t, err) {
+				content, _ := io.ReadAll(file)
+				file.Close()
+				assert.Equal(t, "photo", string(content))
+			}
+			assert.NoError(t, store.Delete(ctx, "resources/a/photo.jpg"))
+			_, err = store.Open(ctx, "resources/a/photo.jpg")
+			assert.ErrorIs(t, err, storage.ErrNotFound)
+		}
+	})
+
+	t.Run("When delete a blob that doesn't exist, should not fail", func(t *testing.T) {
+		store := storage.NewLocalStore(t.TempDir())
+
+		assert.NoError(t, store.Delete(ctx, "resources/a/missing.jpg"))
+	})
+
+	t.Run("When the key leaves the directory of the store, should return ErrInvalidKey", func(t *testing.T) {
+		store := storage.NewLocalStore(t.TempDir())
+
+		for _, key := range []string{"", "../outside.jpg", "resources/../../outside.jpg", "/etc/passwd", "resources/"} {
+			err := store.Put(ctx, key, strings.NewReader("x"), 1, "text/plain")
+			assert.ErrorIs(t, err, storage.ErrInvalidKey, key)
+		}
+	})
+}
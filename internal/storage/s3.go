@@ -0,0 +1,189 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// s3EmptyPayloadHash is the SHA-256 of an empty body, the payload of
+	// reads and deletes.
+	s3EmptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	// s3UnsignedPayload leaves uploads out of their signature so they are
+	// streamed rather than read twice.
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3DateLayout      = "20060102T150405Z"
+)
+
+// S3Store keeps blobs as objects of a bucket of an S3-compatible store, such
+// as Amazon S3 or MinIO, addressed by path: <endpoint>/<bucket>/<key>.
+// Requests are signed with AWS Signature Version 4.
+type S3Store struct {
+	client    *http.Client
+	endpoint  string
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	now       func() time.Time
+}
+
+func NewS3Store(client *http.Client, endpoint string, region string, bucket string, accessKey string, secretKey string) *S3Store {
+	return &S3Store{
+		client:    client,
+		endpoint:  strings.TrimSuffix(endpoint, "/"),
+		region:    region,
+		bucket:    bucket,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		now:       time.Now,
+	}
+}
+
+func (store *S3Store) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	req, err := store.newRequest(ctx, http.MethodPut, key, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Amz-Content-Sha256", s3UnsignedPayload)
+	res, err := store.do(req)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+func (store *S3Store) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := store.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Amz-Content-Sha256", s3EmptyPayloadHash)
+	res, err := store.do(req)
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+func (store *S3Store) Delete(ctx context.Context, key string) error {
+	req, err := store.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Amz-Content-Sha256", s3EmptyPayloadHash)
+	res, err := store.do(req)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+func (store *S3Store) newRequest(ctx context.Context, method string, key string, body io.Reader) (*http.Request, error) {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return nil, ErrInvalidKey
+	}
+	u, err := url.Parse(store.endpoint + "/" + s3EscapePath(store.bucket+"/"+key))
+	if err != nil {
+		return nil, err
+	}
+	return http.NewRequestWithContext(ctx, method, u.String(), body)
+}
+
+// do signs and sends the request, turning error statuses into errors.
+func (store *S3Store) do(req *http.Request) (*http.Response, error) {
+	signS3Request(req, store.accessKey, store.secretKey, store.region, store.now())
+	res, err := store.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusNotFound {
+		res.Body.Close()
+		return nil, ErrNotFound
+	}
+	if res.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		res.Body.Close()
+		return nil, fmt.Errorf("s3 %s %s: %s: %s", req.Method, req.URL.Path, res.Status, message)
+	}
+	return res, nil
+}
+
+// signS3Request adds the Authorization header signing the host and the other
+// headers of the request, whose X-Amz-Content-Sha256 must be set.
+func signS3Request(req *http.Request, accessKey string, secretKey string, region string, now time.Time) {
+	amzDate := now.UTC().Format(s3DateLayout)
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	canonicalHeaders := ""
+	for _, name := range names {
+		canonicalHeaders += name + ":" + headers[name] + "\n"
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3EscapePath(req.URL.Path),
+		req.URL.Query().Encode(),
+		canonicalHeaders,
+		signedHeaders,
+		req.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+	scope := date + "/" + region + "/s3/aws4_request"
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+accessKey+"/"+scope+", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3EscapePath escapes the path the way S3 expects in URLs and signatures,
+// every byte but the unreserved characters and the slashes.
+func s3EscapePath(p string) string {
+	var escaped strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-_.~/", c) >= 0 {
+			escaped.WriteByte(c)
+		} else {
+			fmt.Fprintf(&escaped, "%%%02X", c)
+		}
+	}
+	return escaped.String()
+}
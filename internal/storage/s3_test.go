@@ -0,0 +1,158 @@
+package storage_test
+
+import (
+	"booking/internal/storage"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	S3AccessKey = "minioadmin"
+	S3SecretKey = "miniosecret"
+	S3Region    = "ap-southeast-1"
+	S3Bucket    = "media"
+)
+
+// s3StandIn is a bucket of an S3-compatible store in memory, refusing the
+// requests not signed with S3SecretKey.
+type s3StandIn struct {
+	mu      sync.Mutex
+	objects map[string]string
+	types   map[string]string
+}
+
+func (s3 *s3StandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s3.verifySignature(r) {
+		http.Error(w, "SignatureDoesNotMatch", http.StatusForbidden)
+		return
+	}
+	key, found := strings.CutPrefix(r.URL.Path, "/"+S3Bucket+"/")
+	if !found {
+		http.Error(w, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+	s3.mu.Lock()
+	defer s3.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		s3.objects[key] = string(body)
+		s3.types[key] = r.Header.Get("Content-Type")
+	case http.MethodGet:
+		object, ok := s3.objects[key]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		io.WriteString(w, object)
+	case http.MethodDelete:
+		delete(s3.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// verifySignature checks the Signature Version 4 of the request the way S3
+// does, from what was received.
+func (s3 *s3StandIn) verifySignature(r *http.Request) bool {
+	authorization, found := strings.CutPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ")
+	if !found {
+		return false
+	}
+	fields := map[string]string{}
+	for _, field := range strings.Split(authorization, ", ") {
+		name, value, _ := strings.Cut(field, "=")
+		fields[name] = value
+	}
+	credential := strings.Split(fields["Credential"], "/")
+	if len(credential) != 5 || credential[0] != S3AccessKey || credential[2] != S3Region {
+		return false
+	}
+	signedHeaders := strings.Split(fields["SignedHeaders"], ";")
+	if !sort.StringsAreSorted(signedHeaders) {
+		return false
+	}
+	canonicalHeaders := ""
+	for _, name := range signedHeaders {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		canonicalHeaders += name + ":" + value + "\n"
+	}
+	canonicalRequest := strings.Join([]string{r.Method, r.URL.EscapedPath(), r.URL.RawQuery, canonicalHeaders, fields["SignedHeaders"], r.Header.Get("X-Amz-Content-Sha256")}, "\n")
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	scope := strings.Join(credential[1:], "/")
+	stringToSign := "AWS4-HMAC-SHA256\n" + r.Header.Get("X-Amz-Date") + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+	key := []byte("AWS4" + S3SecretKey)
+	for _, part := range credential[1:] {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(part))
+		key = mac.Sum(nil)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(stringToSign))
+	return hmac.Equal([]byte(hex.EncodeToString(mac.Sum(nil))), []byte(fields["Signature"]))
+}
+
+func TestS3Store(t *testing.T) {
+	ctx := context.Background()
+	newS3 := func() (*s3StandIn, *httptest.Server) {
+		s3 := &s3StandIn{objects: map[string]string{}, types: map[string]string{}}
+		server := httptest.NewServer(s3)
+		t.Cleanup(server.Close)
+		return s3, server
+	}
+
+	t.Run("When put a blob, should store it in the bucket and open it with the same content", func(t *testing.T) {
+		s3, server := newS3()
+		store := storage.NewS3Store(server.Client(), server.URL, S3Region, S3Bucket, S3AccessKey, S3SecretKey)
+
+		err := store.Put(ctx, "resources/a/floor plan (1).pdf", strings.NewReader("%PDF-1.4"), 8, "application/pdf")
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, "%PDF-1.4", s3.objects["resources/a/floor plan (1).pdf"])
+			assert.Equal(t, "application/pdf", s3.types["resources/a/floor plan (1).pdf"])
+			file, err := store.Open(ctx, "resources/a/floor plan (1).pdf")
+			if assert.NoError(t, err) {
+				content, _ := io.ReadAll(file)
+				file.Close()
+				assert.Equal(t, "%PDF-1.4", string(content))
+			}
+		}
+	})
+
+	t.Run("When delete a blob, should remove it and no longer open it", func(t *testing.T) {
+		s3, server := newS3()
+		s3.objects["resources/a/photo.jpg"] = "photo"
+		store := storage.NewS3Store(server.Client(), server.URL, S3Region, S3Bucket, S3AccessKey, S3SecretKey)
+
+		err := store.Delete(ctx, "resources/a/photo.jpg")
+
+		assert.NoError(t, err)
+		assert.Empty(t, s3.objects)
+		_, err = store.Open(ctx, "resources/a/photo.jpg")
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("When the secret key is wrong, should return the error of the store", func(t *testing.T) {
+		_, server := newS3()
+		store := storage.NewS3Store(server.Client(), server.URL, S3Region, S3Bucket, S3AccessKey, "wrongsecret")
+
+		err := store.Put(ctx, "resources/a/photo.jpg", strings.NewReader("photo"), 5, "image/jpeg")
+
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "403")
+		}
+	})
+}
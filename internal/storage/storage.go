@@ -0,0 +1,28 @@
+// Package storage keeps the files uploaded to the API, such as the photos of
+// resources, in a blob store: a directory of the local filesystem or a bucket
+// of an S3-compatible object store.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var (
+	// ErrNotFound is returned when reading a blob that doesn't exist.
+	ErrNotFound = errors.New("blob not found")
+	// ErrInvalidKey is returned for keys that are empty or leave the store.
+	ErrInvalidKey = errors.New("invalid blob key")
+)
+
+// BlobStore stores blobs under keys made of slash-separated names, e.g.
+// "resources/<id>/<file>".
+type BlobStore interface {
+	// Put stores the blob, replacing the one with the same key.
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Open returns the content of the blob, which the caller closes.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob, deleting one that doesn't exist not failing.
+	Delete(ctx context.Context, key string) error
+}
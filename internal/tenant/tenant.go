@@ -0,0 +1,156 @@
+// Package tenant scopes the tables of organizations to the organization of
+// the request. Statements whose context carries an organization only read,
+// change and delete the rows of that organization, and the rows they create
+// are given to it. Statements without one, those of background jobs, see
+// every organization.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// FIELD is the field of the models scoped to an organization.
+const FIELD = "OrganizationID"
+
+// ErrOtherOrganization is returned when creating a row for another
+// organization than the one of the statement.
+var ErrOtherOrganization = errors.New("row belongs to another organization")
+
+type organizationKey struct{}
+
+type roleKey struct{}
+
+// WithOrganizationID returns the context scoping statements to the
+// organization.
+func WithOrganizationID(ctx context.Context, organizationID uuid.UUID) context.Context {
+	return context.WithValue(ctx, organizationKey{}, organizationID)
+}
+
+// OrganizationID returns the organization the context is scoped to.
+func OrganizationID(ctx context.Context) (organizationID uuid.UUID, ok bool) {
+	if ctx == nil {
+		return uuid.Nil, false
+	}
+	organizationID, ok = ctx.Value(organizationKey{}).(uuid.UUID)
+	return organizationID, ok
+}
+
+// WithRole returns the context carrying the role of the user of the request
+// in its organization, empty for users who only book its resources.
+func WithRole(ctx context.Context, role string) context.Context {
+	return context.WithValue(ctx, roleKey{}, role)
+}
+
+// Role returns the role of the user of the request in its organization,
+// empty when the context carries none.
+func Role(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	role, _ := ctx.Value(roleKey{}).(string)
+	return role
+}
+
+// Plugin registers the callbacks scoping statements, with
+// db.Use(tenant.Plugin{}).
+type Plugin struct{}
+
+func (Plugin) Name() string {
+	return "tenant"
+}
+
+func (Plugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	err := callback.Create().Before("gorm:create").Register("tenant:create", assignOrganization)
+	if err != nil {
+		return err
+	}
+	err = callback.Query().Before("gorm:query").Register("tenant:query", scopeOrganization)
+	if err != nil {
+		return err
+	}
+	err = callback.Row().Before("gorm:row").Register("tenant:row", scopeOrganization)
+	if err != nil {
+		return err
+	}
+	err = callback.Update().Before("gorm:update").Register("tenant:update", func(db *gorm.DB) {
+		assignOrganization(db)
+		scopeOrganization(db)
+	})
+	if err != nil {
+		return err
+	}
+	return callback.Delete().Before("gorm:delete").Register("tenant:delete", scopeOrganization)
+}
+
+// statementOrganization returns the organization the statement is scoped to
+// and the field of its model, or false when either is missing.
+func statementOrganization(db *gorm.DB) (organizationID uuid.UUID, field *schema.Field, ok bool) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return uuid.Nil, nil, false
+	}
+	organizationID, ok = OrganizationID(db.Statement.Context)
+	if !ok {
+		return uuid.Nil, nil, false
+	}
+	f := db.Statement.Schema.LookUpField(FIELD)
+	if f == nil {
+		return uuid.Nil, nil, false
+	}
+	return organizationID, f, true
+}
+
+func scopeOrganization(db *gorm.DB) {
+	organizationID, field, ok := statementOrganization(db)
+	if !ok {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: organizationID},
+	}})
+}
+
+// assignOrganization gives the rows written by the statement to its
+// organization. Rows upserted over an existing one only replace it when it
+// belongs to the organization.
+func assignOrganization(db *gorm.DB) {
+	organizationID, field, ok := statementOrganization(db)
+	if !ok {
+		return
+	}
+
+	assign := func(value reflect.Value) {
+		current, zero := field.ValueOf(db.Statement.Context, value)
+		if !zero && current != organizationID {
+			db.AddError(ErrOtherOrganization)
+			return
+		}
+		db.AddError(field.Set(db.Statement.Context, value, organizationID))
+	}
+	value := reflect.Indirect(db.Statement.ReflectValue)
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			assign(reflect.Indirect(value.Index(i)))
+		}
+	case reflect.Struct:
+		assign(value)
+	}
+
+	if c, ok := db.Statement.Clauses["ON CONFLICT"]; ok {
+		if onConflict, ok := c.Expression.(clause.OnConflict); ok && !onConflict.DoNothing {
+			onConflict.Where.Exprs = append(onConflict.Where.Exprs, clause.Eq{
+				Column: clause.Column{Table: db.Statement.Table, Name: field.DBName},
+				Value:  organizationID,
+			})
+			db.Statement.AddClause(onConflict)
+		}
+	}
+}
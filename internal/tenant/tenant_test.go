@@ -0,0 +1,123 @@
+package tenant_test
+
+import (
+	"booking/internal/tenant"
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type venue struct {
+	ID             uuid.UUID
+	OrganizationID uuid.UUID
+	Name           string
+}
+
+type country struct {
+	Code string `gorm:"primarykey"`
+}
+
+// newDB returns a database printing the statements it is given rather than
+// running them.
+func newDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.Use(tenant.Plugin{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestPlugin(t *testing.T) {
+	organizationA := uuid.New()
+	organizationB := uuid.New()
+	ctxA := tenant.WithOrganizationID(context.Background(), organizationA)
+
+	t.Run("When the statement is scoped to an organization, should only read the rows of that organization", func(t *testing.T) {
+		db := newDB(t)
+		var venues []venue
+
+		stmt := db.WithContext(ctxA).Where("name = ?", "Hall").Find(&venues).Statement
+
+		assert.Equal(t, `SELECT * FROM "venues" WHERE name = $1 AND "venues"."organization_id" = $2`, stmt.SQL.String())
+		assert.Equal(t, []interface{}{"Hall", organizationA}, stmt.Vars)
+	})
+
+	t.Run("When another organization's row is looked up by ID, should not find it", func(t *testing.T) {
+		db := newDB(t)
+		id := uuid.New()
+
+		stmt := db.WithContext(ctxA).First(&venue{}, "id = ?", id).Statement
+
+		assert.Contains(t, stmt.SQL.String(), `"venues"."organization_id" = $2`)
+		assert.Equal(t, []interface{}{id, organizationA, 1}, stmt.Vars)
+	})
+
+	t.Run("When rows are counted, updated or deleted, should only touch the rows of the organization", func(t *testing.T) {
+		db := newDB(t)
+		var count int64
+
+		countStmt := db.WithContext(ctxA).Model(&venue{}).Count(&count).Statement
+		updateStmt := db.WithContext(ctxA).Model(&venue{}).Where("name = ?", "Hall").Update("name", "Room").Statement
+		deleteStmt := db.WithContext(ctxA).Where("name = ?", "Hall").Delete(&venue{}).Statement
+
+		assert.Equal(t, `SELECT count(*) FROM "venues" WHERE "venues"."organization_id" = $1`, countStmt.SQL.String())
+		assert.Equal(t, `UPDATE "venues" SET "name"=$1 WHERE name = $2 AND "venues"."organization_id" = $3`, updateStmt.SQL.String())
+		assert.Equal(t, `DELETE FROM "venues" WHERE name = $1 AND "venues"."organization_id" = $2`, deleteStmt.SQL.String())
+		assert.Equal(t, organizationA, deleteStmt.Vars[1])
+	})
+
+	t.Run("When a row is created, should give it to the organization", func(t *testing.T) {
+		db := newDB(t)
+		row := venue{ID: uuid.New(), Name: "Hall"}
+
+		err := db.WithContext(ctxA).Create(&row).Error
+
+		assert.NoError(t, err)
+		assert.Equal(t, organizationA, row.OrganizationID)
+	})
+
+	t.Run("When a row of another organization is created or saved, should refuse it", func(t *testing.T) {
+		db := newDB(t)
+
+		createErr := db.WithContext(ctxA).Create(&venue{ID: uuid.New(), OrganizationID: organizationB}).Error
+		saveErr := db.WithContext(ctxA).Save(&venue{ID: uuid.New(), OrganizationID: organizationB}).Error
+
+		assert.ErrorIs(t, createErr, tenant.ErrOtherOrganization)
+		assert.ErrorIs(t, saveErr, tenant.ErrOtherOrganization)
+	})
+
+	t.Run("When a row is upserted, should only replace a row of the organization", func(t *testing.T) {
+		db := newDB(t)
+
+		stmt := db.WithContext(ctxA).Clauses(clause.OnConflict{UpdateAll: true}).Create(&venue{ID: uuid.New(), Name: "Hall"}).Statement
+
+		assert.Contains(t, stmt.SQL.String(), `WHERE "venues"."organization_id" = $4`)
+		assert.Equal(t, organizationA, stmt.Vars[3])
+	})
+
+	t.Run("When the statement has no organization, should see every organization", func(t *testing.T) {
+		db := newDB(t)
+		var venues []venue
+
+		stmt := db.Find(&venues).Statement
+
+		assert.Equal(t, `SELECT * FROM "venues"`, stmt.SQL.String())
+	})
+
+	t.Run("When the table isn't owned by organizations, should leave the statement alone", func(t *testing.T) {
+		db := newDB(t)
+		var countries []country
+
+		stmt := db.WithContext(ctxA).Find(&countries).Statement
+
+		assert.Equal(t, `SELECT * FROM "countries"`, stmt.SQL.String())
+	})
+}
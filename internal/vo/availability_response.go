@@ -0,0 +1,22 @@
+package vo
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AvailabilityResponse struct {
+	ResourceID uuid.UUID `json:"resourceId"`
+	StartAt    time.Time `json:"startAt"`
+	EndAt      time.Time `json:"endAt"`
+	Capacity   int       `json:"capacity"`
+	// BookedSeats is the most seats taken at the same time in the period,
+	// including seats held for users offered a slot from the waitlist.
+	BookedSeats    int `json:"bookedSeats"`
+	RemainingSeats int `json:"remainingSeats"`
+	// Open is false when the opening hours, holidays or blackouts of the
+	// resource leave no seats to book for the whole period.
+	Open         bool   `json:"open"`
+	ClosedReason string `json:"closedReason,omitempty"`
+}
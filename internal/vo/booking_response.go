@@ -0,0 +1,88 @@
+package vo
+
+import (
+	"booking/internal/entity"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BookingResponse struct {
+	ID                 uuid.UUID                 `json:"id"`
+	UserID             uuid.UUID                 `json:"userId"`
+	ResourceID         uuid.UUID                 `json:"resourceId"`
+	StartAt            time.Time                 `json:"startAt"`
+	EndAt              time.Time                 `json:"endAt"`
+	Status             string                    `json:"status"`
+	Seats              int                       `json:"seats"`
+	SeriesID           *uuid.UUID                `json:"seriesId,omitempty"`
+	RecurrenceID       *time.Time                `json:"recurrenceId,omitempty"`
+	ServiceID          *uuid.UUID                `json:"serviceId,omitempty"`
+	ProviderID         *uuid.UUID                `json:"providerId,omitempty"`
+	Amount             int64                     `json:"amount"`
+	RefundAmount       int64                     `json:"refundAmount"`
+	Quote              entity.Quote              `json:"quote"`
+	CancellationPolicy []entity.CancellationRule `json:"cancellationPolicy"`
+	PaymentSchedule    []PaymentDueResponse      `json:"paymentSchedule"`
+	PaidAmount         int64                     `json:"paidAmount"`
+	OutstandingAmount  int64                     `json:"outstandingAmount"`
+	NextDueAt          *time.Time                `json:"nextDueAt,omitempty"`
+	OverdueAt          *time.Time                `json:"overdueAt,omitempty"`
+	RescheduleCount    int                       `json:"rescheduleCount"`
+	CheckedInAt        *time.Time                `json:"checkedInAt,omitempty"`
+	CancelledAt        *time.Time                `json:"cancelledAt,omitempty"`
+	CreatedAt          time.Time                 `json:"createdAt"`
+	// Payment is the payment started for a booking pending payment.
+	Payment *PaymentResponse `json:"payment,omitempty"`
+}
+
+func NewBookingResponse(booking *entity.Booking) BookingResponse {
+	cancellationPolicy := []entity.CancellationRule(booking.CancellationPolicy)
+	if cancellationPolicy == nil {
+		cancellationPolicy = []entity.CancellationRule{}
+	}
+	return BookingResponse{
+		ID:                 booking.ID,
+		UserID:             booking.UserID,
+		ResourceID:         booking.ResourceID,
+		StartAt:            booking.StartAt,
+		EndAt:              booking.EndAt,
+		Status:             booking.Status,
+		Seats:              booking.Seats,
+		SeriesID:           booking.SeriesID,
+		RecurrenceID:       booking.RecurrenceID,
+		ServiceID:          booking.ServiceID,
+		ProviderID:         booking.ProviderID,
+		Amount:             booking.Amount,
+		RefundAmount:       booking.RefundAmount,
+		Quote:              booking.Quote,
+		CancellationPolicy: cancellationPolicy,
+		PaymentSchedule:    newPaymentDueResponses(booking.PaymentSchedule, booking.PaidAmount),
+		PaidAmount:         booking.PaidAmount,
+		OutstandingAmount:  booking.OutstandingAmount(),
+		NextDueAt:          booking.NextDueAt,
+		OverdueAt:          booking.OverdueAt,
+		RescheduleCount:    booking.RescheduleCount,
+		CheckedInAt:        booking.CheckedInAt,
+		CancelledAt:        booking.CancelledAt,
+		CreatedAt:          booking.CreatedAt,
+	}
+}
+
+// PaymentDueResponse is a due of the booking with how much of it has been
+// paid, the dues being paid oldest first.
+type PaymentDueResponse struct {
+	DueAt      time.Time `json:"dueAt"`
+	Amount     int64     `json:"amount"`
+	PaidAmount int64     `json:"paidAmount"`
+}
+
+func newPaymentDueResponses(dues entity.PaymentDues, paidAmount int64) []PaymentDueResponse {
+	responses := []PaymentDueResponse{}
+	for _, due := range dues {
+		paid := min(due.Amount, paidAmount)
+		paidAmount -= paid
+		responses = append(responses, PaymentDueResponse{DueAt: due.DueAt, Amount: due.Amount, PaidAmount: paid})
+	}
+	return responses
+}
@@ -0,0 +1,61 @@
+package vo
+
+import (
+	"booking/internal/entity"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BookingSeriesResponse struct {
+	ID              uuid.UUID         `json:"id"`
+	UserID          uuid.UUID         `json:"userId"`
+	ResourceID      uuid.UUID         `json:"resourceId"`
+	StartAt         time.Time         `json:"startAt"`
+	DurationMinutes int               `json:"durationMinutes"`
+	Seats           int               `json:"seats"`
+	RRule           string            `json:"rrule"`
+	ExDates         []time.Time       `json:"exdates"`
+	TimeZone        string            `json:"timeZone"`
+	Status          string            `json:"status"`
+	Occurrences     []BookingResponse `json:"occurrences"`
+}
+
+func NewBookingSeriesResponse(series *entity.BookingSeries, bookings []entity.Booking) BookingSeriesResponse {
+	exdates := []time.Time(series.ExDates)
+	if exdates == nil {
+		exdates = []time.Time{}
+	}
+	occurrences := []BookingResponse{}
+	for i := range bookings {
+		occurrences = append(occurrences, NewBookingResponse(&bookings[i]))
+	}
+	return BookingSeriesResponse{
+		ID:              series.ID,
+		UserID:          series.UserID,
+		ResourceID:      series.ResourceID,
+		StartAt:         series.StartAt,
+		DurationMinutes: series.DurationMinutes,
+		Seats:           series.Seats,
+		RRule:           series.RRule,
+		ExDates:         exdates,
+		TimeZone:        series.TimeZone,
+		Status:          series.Status,
+		Occurrences:     occurrences,
+	}
+}
+
+// OccurrenceConflict reports why an occurrence of a recurring booking cannot
+// be booked.
+type OccurrenceConflict struct {
+	StartAt time.Time `json:"startAt"`
+	EndAt   time.Time `json:"endAt"`
+	Reason  string    `json:"reason"`
+}
+
+type BookingSeriesCancellationResponse struct {
+	// Applied is false when the cancellation was only previewed.
+	Applied      bool                   `json:"applied"`
+	RefundAmount int64                  `json:"refundAmount"`
+	Occurrences  []CancellationResponse `json:"occurrences"`
+}
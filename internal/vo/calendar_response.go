@@ -0,0 +1,16 @@
+package vo
+
+import (
+	"booking/internal/entity"
+	"time"
+)
+
+// CalendarFeedResponse is the secret URL calendar applications subscribe to.
+type CalendarFeedResponse struct {
+	URL       string    `json:"url"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func NewCalendarFeedResponse(feed *entity.CalendarFeed, url string) CalendarFeedResponse {
+	return CalendarFeedResponse{URL: url, UpdatedAt: feed.UpdatedAt}
+}
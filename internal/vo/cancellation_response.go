@@ -0,0 +1,12 @@
+package vo
+
+import "booking/internal/entity"
+
+type CancellationResponse struct {
+	// Applied is false when the cancellation was only previewed.
+	Applied      bool                     `json:"applied"`
+	Amount       int64                    `json:"amount"`
+	RefundAmount int64                    `json:"refundAmount"`
+	MatchedRule  *entity.CancellationRule `json:"matchedRule,omitempty"`
+	Booking      BookingResponse          `json:"booking"`
+}
@@ -0,0 +1,45 @@
+package vo
+
+import (
+	"booking/internal/entity"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CheckInCodeResponse struct {
+	BookingID uuid.UUID `json:"bookingId"`
+	Token     string    `json:"token"`
+}
+
+// CheckInResponse is what staff need to see of the booking once the customer
+// has been checked in.
+type CheckInResponse struct {
+	BookingID         uuid.UUID `json:"bookingId"`
+	UserID            uuid.UUID `json:"userId"`
+	ResourceID        uuid.UUID `json:"resourceId"`
+	ResourceName      string    `json:"resourceName"`
+	StartAt           time.Time `json:"startAt"`
+	EndAt             time.Time `json:"endAt"`
+	Seats             int       `json:"seats"`
+	Status            string    `json:"status"`
+	OutstandingAmount int64     `json:"outstandingAmount"`
+	CheckedInAt       time.Time `json:"checkedInAt"`
+	CheckedInBy       uuid.UUID `json:"checkedInBy"`
+}
+
+func NewCheckInResponse(booking *entity.Booking, resource *entity.Resource) CheckInResponse {
+	return CheckInResponse{
+		BookingID:         booking.ID,
+		UserID:            booking.UserID,
+		ResourceID:        resource.ID,
+		ResourceName:      resource.Name,
+		StartAt:           booking.StartAt,
+		EndAt:             booking.EndAt,
+		Seats:             booking.Seats,
+		Status:            booking.Status,
+		OutstandingAmount: booking.OutstandingAmount(),
+		CheckedInAt:       *booking.CheckedInAt,
+		CheckedInBy:       *booking.CheckedInBy,
+	}
+}
@@ -0,0 +1,16 @@
+package vo
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ExternalCalendarResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	Name      string     `json:"name"`
+	URL       string     `json:"url"`
+	SyncedAt  *time.Time `json:"syncedAt"`
+	SyncError string     `json:"syncError"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
@@ -0,0 +1,42 @@
+package vo
+
+import (
+	"booking/internal/entity"
+	"time"
+)
+
+type BillingProfileResponse struct {
+	Name       string    `json:"name"`
+	TaxID      string    `json:"taxId"`
+	BranchCode string    `json:"branchCode"`
+	Address    string    `json:"address"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+func NewBillingProfileResponse(profile *entity.BillingProfile) BillingProfileResponse {
+	return BillingProfileResponse{
+		Name:       profile.Name,
+		TaxID:      profile.TaxID,
+		BranchCode: profile.BranchCode,
+		Address:    profile.Address,
+		UpdatedAt:  profile.UpdatedAt,
+	}
+}
+
+func NewOrganizationBillingProfileResponse(profile *entity.OrganizationBillingProfile) BillingProfileResponse {
+	return BillingProfileResponse{
+		Name:       profile.Name,
+		TaxID:      profile.TaxID,
+		BranchCode: profile.BranchCode,
+		Address:    profile.Address,
+		UpdatedAt:  profile.UpdatedAt,
+	}
+}
+
+// FileResponse is a rendered file to download. ContentType is set when the
+// type isn't implied by the endpoint, as for uploaded files.
+type FileResponse struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
@@ -0,0 +1,36 @@
+package vo
+
+import (
+	"booking/internal/entity"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type JobResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	Kind        string     `json:"kind"`
+	Status      string     `json:"status"`
+	RunAt       time.Time  `json:"runAt"`
+	Attempts    int        `json:"attempts"`
+	MaxAttempts int        `json:"maxAttempts"`
+	LockedBy    string     `json:"lockedBy,omitempty"`
+	LastError   string     `json:"lastError,omitempty"`
+	StartedAt   *time.Time `json:"startedAt,omitempty"`
+	FinishedAt  *time.Time `json:"finishedAt,omitempty"`
+}
+
+func NewJobResponse(job *entity.Job) JobResponse {
+	return JobResponse{
+		ID:          job.ID,
+		Kind:        job.Kind,
+		Status:      job.Status,
+		RunAt:       job.RunAt,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		LockedBy:    job.LockedBy,
+		LastError:   job.LastError,
+		StartedAt:   job.StartedAt,
+		FinishedAt:  job.FinishedAt,
+	}
+}
@@ -0,0 +1,88 @@
+package vo
+
+import (
+	"booking/internal/entity"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Amounts are in satang. Balances are positive when the account holds what
+// its type normally does, debits for assets and credits for liabilities and
+// revenue.
+
+type AccountBalanceResponse struct {
+	Code    string `json:"code"`
+	Type    string `json:"type"`
+	Debit   int64  `json:"debit"`
+	Credit  int64  `json:"credit"`
+	Balance int64  `json:"balance"`
+}
+
+type LedgerBalancesResponse struct {
+	At       time.Time                `json:"at"`
+	Accounts []AccountBalanceResponse `json:"accounts"`
+}
+
+type StatementLineResponse struct {
+	EntryID     uuid.UUID  `json:"entryId"`
+	BookingID   *uuid.UUID `json:"bookingId,omitempty"`
+	Type        string     `json:"type"`
+	Description string     `json:"description"`
+	PostedAt    time.Time  `json:"postedAt"`
+	Debit       int64      `json:"debit"`
+	Credit      int64      `json:"credit"`
+	Balance     int64      `json:"balance"`
+}
+
+type StatementResponse struct {
+	Code           string                  `json:"code"`
+	Type           string                  `json:"type"`
+	StartAt        time.Time               `json:"startAt"`
+	EndAt          time.Time               `json:"endAt"`
+	OpeningBalance int64                   `json:"openingBalance"`
+	Lines          []StatementLineResponse `json:"lines"`
+}
+
+// signedBalance turns debits less credits into the balance of an account of
+// the type.
+func signedBalance(accountType string, balance int64) int64 {
+	if entity.IsDebitNormal(accountType) {
+		return balance
+	}
+	return -balance
+}
+
+func NewAccountBalanceResponse(balance *entity.AccountBalance) AccountBalanceResponse {
+	return AccountBalanceResponse{
+		Code:    balance.Code,
+		Type:    balance.Type,
+		Debit:   balance.Debit,
+		Credit:  balance.Credit,
+		Balance: signedBalance(balance.Type, balance.Debit-balance.Credit),
+	}
+}
+
+func NewStatementResponse(account *entity.LedgerAccount, startAt time.Time, endAt time.Time, opening int64, lines []entity.StatementLine) StatementResponse {
+	response := StatementResponse{
+		Code:           account.Code,
+		Type:           account.Type,
+		StartAt:        startAt,
+		EndAt:          endAt,
+		OpeningBalance: signedBalance(account.Type, opening),
+		Lines:          []StatementLineResponse{},
+	}
+	for _, line := range lines {
+		response.Lines = append(response.Lines, StatementLineResponse{
+			EntryID:     line.EntryID,
+			BookingID:   line.BookingID,
+			Type:        line.Type,
+			Description: line.Description,
+			PostedAt:    line.PostedAt,
+			Debit:       line.Debit,
+			Credit:      line.Credit,
+			Balance:     signedBalance(account.Type, line.Balance),
+		})
+	}
+	return response
+}
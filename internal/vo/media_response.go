@@ -0,0 +1,44 @@
+package vo
+
+import (
+	"booking/internal/entity"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MediaFileResponse struct {
+	ID           uuid.UUID `json:"id"`
+	ResourceID   uuid.UUID `json:"resourceId"`
+	Kind         string    `json:"kind"`
+	FileName     string    `json:"fileName"`
+	ContentType  string    `json:"contentType"`
+	Size         int64     `json:"size"`
+	Width        int       `json:"width,omitempty"`
+	Height       int       `json:"height,omitempty"`
+	Position     int       `json:"position"`
+	Private      bool      `json:"private"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnailUrl,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// NewMediaFileResponse describes the file, with the URLs to download it and
+// its thumbnail, signed for private files.
+func NewMediaFileResponse(file *entity.MediaFile, url string, thumbnailURL string) MediaFileResponse {
+	return MediaFileResponse{
+		ID:           file.ID,
+		ResourceID:   file.ResourceID,
+		Kind:         file.Kind,
+		FileName:     file.FileName,
+		ContentType:  file.ContentType,
+		Size:         file.Size,
+		Width:        file.Width,
+		Height:       file.Height,
+		Position:     file.Position,
+		Private:      file.Private,
+		URL:          url,
+		ThumbnailURL: thumbnailURL,
+		CreatedAt:    file.CreatedAt,
+	}
+}
@@ -0,0 +1,14 @@
+package vo
+
+import "time"
+
+// NoShowRecordResponse is the no-shows of the user in the last WindowDays
+// days and the penalties new bookings of the user get for them. Fee is in
+// satang, what the next booking is charged for the no-shows not charged yet.
+type NoShowRecordResponse struct {
+	NoShows        int        `json:"noShows"`
+	WindowDays     int        `json:"windowDays"`
+	Fee            int64      `json:"fee"`
+	DepositPercent int        `json:"depositPercent"`
+	BannedUntil    *time.Time `json:"bannedUntil,omitempty"`
+}
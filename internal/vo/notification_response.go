@@ -0,0 +1,23 @@
+package vo
+
+import "booking/internal/entity"
+
+type NotificationPreferenceResponse struct {
+	Email      bool   `json:"email"`
+	SMS        bool   `json:"sms"`
+	Phone      string `json:"phone"`
+	Line       bool   `json:"line"`
+	LineUserID string `json:"lineUserId"`
+	Language   string `json:"language"`
+}
+
+func NewNotificationPreferenceResponse(preference *entity.NotificationPreference) NotificationPreferenceResponse {
+	return NotificationPreferenceResponse{
+		Email:      preference.Email,
+		SMS:        preference.SMS,
+		Phone:      preference.Phone,
+		Line:       preference.Line,
+		LineUserID: preference.LineUserID,
+		Language:   preference.Language,
+	}
+}
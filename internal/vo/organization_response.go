@@ -0,0 +1,33 @@
+package vo
+
+import (
+	"booking/internal/entity"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrganizationResponse is an organization with the role of the user in it.
+type OrganizationResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func NewOrganizationResponse(organization *entity.Organization, role string) OrganizationResponse {
+	return OrganizationResponse{
+		ID:        organization.ID,
+		Name:      organization.Name,
+		Slug:      organization.Slug,
+		Role:      role,
+		CreatedAt: organization.CreatedAt,
+	}
+}
+
+type OrganizationMemberResponse struct {
+	OrganizationID uuid.UUID `json:"organizationId"`
+	UserID         uuid.UUID `json:"userId"`
+	Role           string    `json:"role"`
+}
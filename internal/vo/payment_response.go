@@ -0,0 +1,41 @@
+package vo
+
+import (
+	"booking/internal/entity"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PaymentResponse struct {
+	ID        uuid.UUID `json:"id"`
+	BookingID uuid.UUID `json:"bookingId"`
+	Provider  string    `json:"provider"`
+	IntentID  string    `json:"intentId"`
+	// ClientSecret completes the intent with the provider and is only
+	// returned when the payment is started.
+	ClientSecret   string    `json:"clientSecret,omitempty"`
+	Amount         int64     `json:"amount"`
+	RefundedAmount int64     `json:"refundedAmount"`
+	Currency       string    `json:"currency"`
+	Status         string    `json:"status"`
+	FailureReason  string    `json:"failureReason,omitempty"`
+	QRPayload      string    `json:"qrPayload,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+func NewPaymentResponse(payment *entity.Payment) PaymentResponse {
+	return PaymentResponse{
+		ID:             payment.ID,
+		BookingID:      payment.BookingID,
+		Provider:       payment.Provider,
+		IntentID:       payment.IntentID,
+		Amount:         payment.Amount,
+		RefundedAmount: payment.RefundedAmount,
+		Currency:       payment.Currency,
+		Status:         payment.Status,
+		FailureReason:  payment.FailureReason,
+		QRPayload:      payment.QRPayload,
+		CreatedAt:      payment.CreatedAt,
+	}
+}
@@ -0,0 +1,29 @@
+package vo
+
+import (
+	"booking/internal/entity"
+
+	"github.com/google/uuid"
+)
+
+type PaymentScheduleResponse struct {
+	ResourceID     uuid.UUID                  `json:"resourceId"`
+	MinAmount      int64                      `json:"minAmount"`
+	DepositPercent int                        `json:"depositPercent"`
+	Instalments    []entity.PaymentInstalment `json:"instalments"`
+	OverdueAction  string                     `json:"overdueAction,omitempty"`
+}
+
+func NewPaymentScheduleResponse(schedule *entity.PaymentSchedule) PaymentScheduleResponse {
+	instalments := []entity.PaymentInstalment(schedule.Instalments)
+	if instalments == nil {
+		instalments = []entity.PaymentInstalment{}
+	}
+	return PaymentScheduleResponse{
+		ResourceID:     schedule.ResourceID,
+		MinAmount:      schedule.MinAmount,
+		DepositPercent: schedule.DepositPercent,
+		Instalments:    instalments,
+		OverdueAction:  schedule.OverdueAction,
+	}
+}
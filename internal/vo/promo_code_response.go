@@ -0,0 +1,50 @@
+package vo
+
+import (
+	"booking/internal/entity"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PromoCodeResponse struct {
+	ID                    uuid.UUID   `json:"id"`
+	Code                  string      `json:"code"`
+	DiscountType          string      `json:"discountType"`
+	DiscountValue         int64       `json:"discountValue"`
+	ValidFrom             *time.Time  `json:"validFrom,omitempty"`
+	ValidUntil            *time.Time  `json:"validUntil,omitempty"`
+	MinSpend              int64       `json:"minSpend"`
+	MaxRedemptions        int         `json:"maxRedemptions"`
+	MaxRedemptionsPerUser int         `json:"maxRedemptionsPerUser"`
+	RedemptionCount       int         `json:"redemptionCount"`
+	ResourceIDs           []uuid.UUID `json:"resourceIds"`
+	Categories            []string    `json:"categories"`
+	CreatedAt             time.Time   `json:"createdAt"`
+}
+
+func NewPromoCodeResponse(promoCode *entity.PromoCode) PromoCodeResponse {
+	resourceIDs := []uuid.UUID(promoCode.ResourceIDs)
+	if resourceIDs == nil {
+		resourceIDs = []uuid.UUID{}
+	}
+	categories := []string(promoCode.Categories)
+	if categories == nil {
+		categories = []string{}
+	}
+	return PromoCodeResponse{
+		ID:                    promoCode.ID,
+		Code:                  promoCode.Code,
+		DiscountType:          promoCode.DiscountType,
+		DiscountValue:         promoCode.DiscountValue,
+		ValidFrom:             promoCode.ValidFrom,
+		ValidUntil:            promoCode.ValidUntil,
+		MinSpend:              promoCode.MinSpend,
+		MaxRedemptions:        promoCode.MaxRedemptions,
+		MaxRedemptionsPerUser: promoCode.MaxRedemptionsPerUser,
+		RedemptionCount:       promoCode.RedemptionCount,
+		ResourceIDs:           resourceIDs,
+		Categories:            categories,
+		CreatedAt:             promoCode.CreatedAt,
+	}
+}
@@ -0,0 +1,50 @@
+package vo
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ProviderResponse struct {
+	ID       uuid.UUID              `json:"id"`
+	UserID   *uuid.UUID             `json:"userId,omitempty"`
+	Name     string                 `json:"name"`
+	TimeZone string                 `json:"timeZone"`
+	Hours    []WeekdayHoursResponse `json:"hours"`
+	// TimeOff lists the upcoming time off.
+	TimeOff []ProviderTimeOffResponse `json:"timeOff"`
+}
+
+type ProviderTimeOffResponse struct {
+	ID      uuid.UUID `json:"id"`
+	StartAt time.Time `json:"startAt"`
+	EndAt   time.Time `json:"endAt"`
+	Reason  string    `json:"reason"`
+}
+
+type ServiceResponse struct {
+	ID              uuid.UUID `json:"id"`
+	ResourceID      uuid.UUID `json:"resourceId"`
+	Name            string    `json:"name"`
+	DurationMinutes int       `json:"durationMinutes"`
+}
+
+type ProviderServiceResponse struct {
+	ProviderID      uuid.UUID `json:"providerId"`
+	ServiceID       uuid.UUID `json:"serviceId"`
+	DurationMinutes int       `json:"durationMinutes"`
+}
+
+// AppointmentSlotResponse is a start time of an appointment with the
+// providers free from it, each until they would be done.
+type AppointmentSlotResponse struct {
+	StartAt   time.Time                     `json:"startAt"`
+	Providers []AppointmentProviderResponse `json:"providers"`
+}
+
+type AppointmentProviderResponse struct {
+	ProviderID uuid.UUID `json:"providerId"`
+	Name       string    `json:"name"`
+	EndAt      time.Time `json:"endAt"`
+}
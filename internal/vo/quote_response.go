@@ -0,0 +1,40 @@
+package vo
+
+import (
+	"booking/internal/entity"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type QuoteResponse struct {
+	ResourceID uuid.UUID          `json:"resourceId"`
+	StartAt    time.Time          `json:"startAt"`
+	EndAt      time.Time          `json:"endAt"`
+	Seats      int                `json:"seats"`
+	HourlyRate int64              `json:"hourlyRate"`
+	Minutes    int                `json:"minutes"`
+	Member     bool               `json:"member"`
+	Lines      []entity.QuoteLine `json:"lines"`
+	Total      int64              `json:"total"`
+}
+
+func NewQuoteResponse(resourceID uuid.UUID, startAt time.Time, endAt time.Time, quote *entity.Quote) QuoteResponse {
+	return QuoteResponse{
+		ResourceID: resourceID,
+		StartAt:    startAt,
+		EndAt:      endAt,
+		Seats:      quote.Seats,
+		HourlyRate: quote.HourlyRate,
+		Minutes:    quote.Minutes,
+		Member:     quote.Member,
+		Lines:      quote.Lines,
+		Total:      quote.Total,
+	}
+}
+
+type PricingPolicyResponse struct {
+	ResourceID uuid.UUID            `json:"resourceId"`
+	HourlyRate int64                `json:"hourlyRate"`
+	Rules      []entity.PricingRule `json:"rules"`
+}
@@ -0,0 +1,12 @@
+package vo
+
+import (
+	"booking/internal/entity"
+
+	"github.com/google/uuid"
+)
+
+type ReschedulePolicyResponse struct {
+	ResourceID uuid.UUID              `json:"resourceId"`
+	Rules      entity.RescheduleRules `json:"rules"`
+}
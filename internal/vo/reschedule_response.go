@@ -0,0 +1,51 @@
+package vo
+
+import (
+	"booking/internal/entity"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RescheduleResponse struct {
+	// Applied is false when the reschedule was only previewed.
+	Applied         bool      `json:"applied"`
+	PreviousStartAt time.Time `json:"previousStartAt"`
+	PreviousEndAt   time.Time `json:"previousEndAt"`
+	PreviousAmount  int64     `json:"previousAmount"`
+	Amount          int64     `json:"amount"`
+	// AmountDue is charged now for the booking at its new price and
+	// RefundAmount is what was paid beyond it, given back.
+	AmountDue    int64            `json:"amountDue"`
+	RefundAmount int64            `json:"refundAmount"`
+	Booking      BookingResponse  `json:"booking"`
+	Payment      *PaymentResponse `json:"payment,omitempty"`
+}
+
+type BookingChangeResponse struct {
+	ID              uuid.UUID `json:"id"`
+	Type            string    `json:"type"`
+	ChangedBy       uuid.UUID `json:"changedBy"`
+	PreviousStartAt time.Time `json:"previousStartAt"`
+	PreviousEndAt   time.Time `json:"previousEndAt"`
+	StartAt         time.Time `json:"startAt"`
+	EndAt           time.Time `json:"endAt"`
+	PreviousAmount  int64     `json:"previousAmount"`
+	Amount          int64     `json:"amount"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+func NewBookingChangeResponse(change *entity.BookingChange) BookingChangeResponse {
+	return BookingChangeResponse{
+		ID:              change.ID,
+		Type:            change.Type,
+		ChangedBy:       change.ChangedBy,
+		PreviousStartAt: change.PreviousStartAt,
+		PreviousEndAt:   change.PreviousEndAt,
+		StartAt:         change.StartAt,
+		EndAt:           change.EndAt,
+		PreviousAmount:  change.PreviousAmount,
+		Amount:          change.Amount,
+		CreatedAt:       change.CreatedAt,
+	}
+}
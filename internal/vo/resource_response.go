@@ -0,0 +1,32 @@
+package vo
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ResourceResponse struct {
+	ID             uuid.UUID `json:"id"`
+	OrganizationID uuid.UUID `json:"organizationId"`
+	OwnerID        uuid.UUID `json:"ownerId"`
+	Name           string    `json:"name"`
+	Description    string    `json:"description"`
+	Category       string    `json:"category"`
+	Amenities      []string  `json:"amenities"`
+	HourlyRate     int64     `json:"hourlyRate"`
+	Address        string    `json:"address"`
+	Latitude       *float64  `json:"latitude"`
+	Longitude      *float64  `json:"longitude"`
+	// DistanceKm is how far the resource is from the point searched around.
+	DistanceKm *float64 `json:"distanceKm,omitempty"`
+	Capacity   int      `json:"capacity"`
+	TimeZone   string   `json:"timeZone"`
+	// HolidayCalendar is the code of the holiday calendar the resource closes
+	// on, if any.
+	HolidayCalendar string `json:"holidayCalendar"`
+	// RatingAverage is the mean of the stars of the published reviews.
+	RatingAverage float64   `json:"ratingAverage"`
+	RatingCount   int       `json:"ratingCount"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
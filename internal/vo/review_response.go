@@ -0,0 +1,36 @@
+package vo
+
+import (
+	"booking/internal/entity"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReviewResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	ResourceID uuid.UUID  `json:"resourceId"`
+	BookingID  uuid.UUID  `json:"bookingId"`
+	UserID     uuid.UUID  `json:"userId"`
+	Rating     int        `json:"rating"`
+	Text       string     `json:"text"`
+	Status     string     `json:"status"`
+	Reply      string     `json:"reply,omitempty"`
+	RepliedAt  *time.Time `json:"repliedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+func NewReviewResponse(review *entity.Review) ReviewResponse {
+	return ReviewResponse{
+		ID:         review.ID,
+		ResourceID: review.ResourceID,
+		BookingID:  review.BookingID,
+		UserID:     review.UserID,
+		Rating:     review.Rating,
+		Text:       review.Text,
+		Status:     review.Status,
+		Reply:      review.Reply,
+		RepliedAt:  review.RepliedAt,
+		CreatedAt:  review.CreatedAt,
+	}
+}
@@ -0,0 +1,52 @@
+package vo
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OpeningPeriodResponse struct {
+	OpensAt  string `json:"opensAt"`
+	ClosesAt string `json:"closesAt"`
+}
+
+type WeekdayHoursResponse struct {
+	Weekday int                     `json:"weekday"`
+	Periods []OpeningPeriodResponse `json:"periods"`
+}
+
+type DateOverrideResponse struct {
+	Date    string                  `json:"date"`
+	Periods []OpeningPeriodResponse `json:"periods"`
+	Reason  string                  `json:"reason"`
+}
+
+type BlackoutPeriodResponse struct {
+	ID      uuid.UUID `json:"id"`
+	StartAt time.Time `json:"startAt"`
+	EndAt   time.Time `json:"endAt"`
+	Reason  string    `json:"reason"`
+}
+
+type ScheduleResponse struct {
+	ResourceID      uuid.UUID              `json:"resourceId"`
+	TimeZone        string                 `json:"timeZone"`
+	HolidayCalendar string                 `json:"holidayCalendar"`
+	Hours           []WeekdayHoursResponse `json:"hours"`
+	// DateOverrides and Blackouts list the upcoming ones.
+	DateOverrides []DateOverrideResponse   `json:"dateOverrides"`
+	Blackouts     []BlackoutPeriodResponse `json:"blackouts"`
+}
+
+type HolidayResponse struct {
+	Date   string `json:"date"`
+	Name   string `json:"name"`
+	NameTH string `json:"nameTh"`
+}
+
+type HolidayCalendarResponse struct {
+	Code     string            `json:"code"`
+	Name     string            `json:"name"`
+	Holidays []HolidayResponse `json:"holidays"`
+}
@@ -0,0 +1,35 @@
+package vo
+
+import (
+	"booking/internal/entity"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WaitlistEntryResponse struct {
+	ID             uuid.UUID  `json:"id"`
+	ResourceID     uuid.UUID  `json:"resourceId"`
+	StartAt        time.Time  `json:"startAt"`
+	EndAt          time.Time  `json:"endAt"`
+	Seats          int        `json:"seats"`
+	Status         string     `json:"status"`
+	Position       int64      `json:"position,omitempty"`
+	OfferExpiresAt *time.Time `json:"offerExpiresAt,omitempty"`
+	BookingID      *uuid.UUID `json:"bookingId,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+func NewWaitlistEntryResponse(entry *entity.WaitlistEntry) WaitlistEntryResponse {
+	return WaitlistEntryResponse{
+		ID:             entry.ID,
+		ResourceID:     entry.ResourceID,
+		StartAt:        entry.StartAt,
+		EndAt:          entry.EndAt,
+		Seats:          entry.Seats,
+		Status:         entry.Status,
+		OfferExpiresAt: entry.OfferExpiresAt,
+		BookingID:      entry.BookingID,
+		CreatedAt:      entry.CreatedAt,
+	}
+}
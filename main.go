@@ -4,8 +4,13 @@ import (
 	"booking/config"
 	"booking/internal/app"
 	"booking/internal/cache"
+	"booking/internal/entity"
 	"booking/internal/environment"
 	"booking/internal/logs"
+	"booking/internal/repository"
+	"booking/internal/service"
+	"booking/internal/tenant"
+	"time"
 
 	"github.com/gofiber/storage/redis/v3"
 	"github.com/spf13/viper"
@@ -25,6 +30,103 @@ func main() {
 		logs.Error(err)
 		panic("Cannot connect to the database")
 	}
+	err = db.Use(tenant.Plugin{})
+	if err != nil {
+		logs.Error(err)
+		panic("Cannot register the tenant plugin")
+	}
+	// promo codes were unique across the deployment before organizations
+	if db.Migrator().HasIndex(&entity.PromoCode{}, "idx_promo_codes_code") {
+		err = db.Migrator().DropIndex(&entity.PromoCode{}, "idx_promo_codes_code")
+		if err != nil {
+			logs.Error(err)
+			panic("Cannot migrate the database")
+		}
+	}
+	// the ledger and the tax documents were kept per resource owner before
+	// they were kept per organization
+	for _, model := range []interface{}{&entity.LedgerAccount{}, &entity.JournalEntry{}, &entity.DocumentSequence{}, &entity.TaxDocument{}} {
+		if !db.Migrator().HasColumn(model, "owner_id") {
+			continue
+		}
+		err = db.Migrator().RenameColumn(model, "owner_id", "organization_id")
+		if err != nil {
+			logs.Error(err)
+			panic("Cannot migrate the database")
+		}
+	}
+	for model, index := range map[interface{}]string{&entity.LedgerAccount{}: "idx_ledger_accounts_owner_code", &entity.TaxDocument{}: "idx_tax_documents_owner_number"} {
+		if !db.Migrator().HasIndex(model, index) {
+			continue
+		}
+		err = db.Migrator().DropIndex(model, index)
+		if err != nil {
+			logs.Error(err)
+			panic("Cannot migrate the database")
+		}
+	}
+	err = db.AutoMigrate(
+		&entity.Organization{},
+		&entity.OrganizationMember{},
+		&entity.Resource{},
+		&entity.CancellationPolicy{},
+		&entity.ReschedulePolicy{},
+		&entity.Booking{},
+		&entity.BookingSeries{},
+		&entity.WaitlistEntry{},
+		&entity.OpeningHours{},
+		&entity.DateOverride{},
+		&entity.HolidayCalendar{},
+		&entity.Holiday{},
+		&entity.BlackoutPeriod{},
+		&entity.PricingPolicy{},
+		&entity.PaymentSchedule{},
+		&entity.ResourceMember{},
+		&entity.PromoCode{},
+		&entity.PromoRedemption{},
+		&entity.Payment{},
+		&entity.PaymentEvent{},
+		&entity.LedgerAccount{},
+		&entity.JournalEntry{},
+		&entity.JournalLine{},
+		&entity.BillingProfile{},
+		&entity.OrganizationBillingProfile{},
+		&entity.DocumentSequence{},
+		&entity.TaxDocument{},
+		&entity.CalendarFeed{},
+		&entity.ExternalCalendar{},
+		&entity.NotificationPreference{},
+		&entity.Notification{},
+		&entity.Job{},
+		&entity.ResourceStaff{},
+		&entity.Provider{},
+		&entity.ProviderHours{},
+		&entity.ProviderTimeOff{},
+		&entity.Service{},
+		&entity.ProviderService{},
+		&entity.BookingChange{},
+		&entity.Review{},
+		&entity.MediaFile{},
+	)
+	if err != nil {
+		logs.Error(err)
+		panic("Cannot migrate the database")
+	}
+	err = repository.MigrateLedger(db)
+	if err != nil {
+		logs.Error(err)
+		panic("Cannot migrate the ledger")
+	}
+	err = repository.MigrateResourceSearch(db)
+	if err != nil {
+		logs.Error(err)
+		panic("Cannot migrate the resource search")
+	}
+	err = service.SeedHolidayCalendars(repository.NewScheduleRepository(db), time.Now())
+	if err != nil {
+		logs.Error(err)
+		panic("Cannot seed the holiday calendars")
+	}
 
 	app := app.GetApp(cache, db)
 	port := viper.GetString("app.port")
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"booking/internal/cache"
+	"booking/internal/constant"
+	"booking/internal/logs"
+	"booking/internal/repository"
+	"booking/internal/tenant"
+	"booking/internal/vo"
+	"errors"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ORGANIZATION_HEADER names the organization a request is made in.
+const ORGANIZATION_HEADER = "X-Organization-ID"
+
+// VerifyOrganization scopes the request to the organization of its header,
+// the queries of the services called with the user context of the request
+// only seeing the rows of that organization. It follows VerifyUser, and
+// resolves the role of the user in the organization, empty for users who
+// only book its resources.
+func VerifyOrganization(cache cache.ICache, authRepository repository.IAuthRepository, organizationRepository repository.IOrganizationRepository) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		res := vo.Response{}
+		organizationID, err := uuid.Parse(c.Get(ORGANIZATION_HEADER))
+		if err != nil {
+			res.SetErrorMessage(constant.ERROR_MESSAGE_ORGANIZATION_REQUIRED)
+			return c.Status(fiber.StatusBadRequest).JSON(res)
+		}
+		accessToken, ok := c.Locals(constant.LOCAL_KEY_ACCESS_TOKEN).(*vo.AccessToken)
+		if !ok {
+			res.SetErrorMessage(constant.ERROR_MESSAGE_UNAUTHORIZED)
+			return c.Status(fiber.StatusUnauthorized).JSON(res)
+		}
+
+		role, cached, err := cache.GetOrganizationRole(organizationID, accessToken.Username)
+		if err != nil || !cached {
+			var errorMessage string
+			var statusCode int
+			role, errorMessage, statusCode = findOrganizationRole(authRepository, organizationRepository, organizationID, accessToken.Username)
+			if statusCode != fiber.StatusOK {
+				res.SetErrorMessage(errorMessage)
+				return c.Status(statusCode).JSON(res)
+			}
+			err = cache.SaveOrganizationRole(organizationID, accessToken.Username, role)
+			if err != nil {
+				logs.Error(err)
+			}
+		}
+
+		c.Locals(constant.LOCAL_KEY_ORGANIZATION_ID, organizationID)
+		c.Locals(constant.LOCAL_KEY_ORGANIZATION_ROLE, role)
+		c.SetUserContext(tenant.WithRole(tenant.WithOrganizationID(c.UserContext(), organizationID), role))
+		return c.Next()
+	}
+}
+
+// RequireOrganizationRole lets through the users having one of the roles in
+// the organization verified by VerifyOrganization.
+func RequireOrganizationRole(roles ...string) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		role, _ := c.Locals(constant.LOCAL_KEY_ORGANIZATION_ROLE).(string)
+		if !slices.Contains(roles, role) {
+			res := vo.Response{}
+			res.SetErrorMessage(constant.ERROR_MESSAGE_FORBIDDEN)
+			return c.Status(fiber.StatusForbidden).JSON(res)
+		}
+		return c.Next()
+	}
+}
+
+func findOrganizationRole(authRepository repository.IAuthRepository, organizationRepository repository.IOrganizationRepository, organizationID uuid.UUID, username string) (role string, errorMessage string, statusCode int) {
+	_, err := organizationRepository.FindOrganizationByID(organizationID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", constant.ERROR_MESSAGE_ORGANIZATION_NOT_FOUND, fiber.StatusNotFound
+	}
+	if err != nil {
+		logs.Error(err)
+		return "", constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR, fiber.StatusInternalServerError
+	}
+	user, err := authRepository.FindUserByUsername(username)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", constant.ERROR_MESSAGE_UNAUTHORIZED, fiber.StatusUnauthorized
+	}
+	if err != nil {
+		logs.Error(err)
+		return "", constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR, fiber.StatusInternalServerError
+	}
+	member, err := organizationRepository.FindOrganizationMember(organizationID, user.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", "", fiber.StatusOK
+	}
+	if err != nil {
+		logs.Error(err)
+		return "", constant.ERROR_MESSAGE_INTERNAL_SERVER_ERROR, fiber.StatusInternalServerError
+	}
+	return member.Role, "", fiber.StatusOK
+}
@@ -0,0 +1,173 @@
+package middleware_test
+
+import (
+	"booking/internal/cache"
+	"booking/internal/constant"
+	"booking/internal/entity"
+	"booking/internal/tenant"
+	"booking/internal/vo"
+	"booking/middleware"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/storage/memory/v2"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type authRepositoryStub struct {
+	users map[string]*entity.User
+}
+
+func (repo *authRepositoryStub) FindUserByUsername(username string) (user *entity.User, err error) {
+	user, ok := repo.users[username]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return user, nil
+}
+
+func (repo *authRepositoryStub) FindUserByID(id uuid.UUID) (user *entity.User, err error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (repo *authRepositoryStub) CreateAccount(user *entity.User) (err error) {
+	return nil
+}
+
+// organizationRepositoryStub counts the lookups of members to tell cached
+// roles apart.
+type organizationRepositoryStub struct {
+	organizations map[uuid.UUID]*entity.Organization
+	members       []entity.OrganizationMember
+	memberLookups int
+}
+
+func (repo *organizationRepositoryStub) CreateOrganization(organization *entity.Organization, owner *entity.OrganizationMember) (err error) {
+	return nil
+}
+
+func (repo *organizationRepositoryStub) FindOrganizationByID(id uuid.UUID) (organization *entity.Organization, err error) {
+	organization, ok := repo.organizations[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return organization, nil
+}
+
+func (repo *organizationRepositoryStub) FindOrganizationBySlug(slug string) (organization *entity.Organization, err error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (repo *organizationRepositoryStub) FindOrganizationsByIDs(ids []uuid.UUID) (organizations []entity.Organization, err error) {
+	return nil, nil
+}
+
+func (repo *organizationRepositoryStub) FindOrganizationMember(organizationID uuid.UUID, userID uuid.UUID) (member *entity.OrganizationMember, err error) {
+	repo.memberLookups++
+	for i := range repo.members {
+		if repo.members[i].OrganizationID == organizationID && repo.members[i].UserID == userID {
+			return &repo.members[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (repo *organizationRepositoryStub) FindOrganizationMembersByUserID(userID uuid.UUID) (members []entity.OrganizationMember, err error) {
+	return nil, nil
+}
+
+func (repo *organizationRepositoryStub) SaveOrganizationMember(member *entity.OrganizationMember) (err error) {
+	return nil
+}
+
+func (repo *organizationRepositoryStub) RemoveOrganizationMember(organizationID uuid.UUID, userID uuid.UUID) (deleted int64, err error) {
+	return 0, nil
+}
+
+func TestVerifyOrganization(t *testing.T) {
+	viper.Set("organization.role_cache_seconds", 60)
+	viper.Set("redis.prefix_key.organization_role", "ORGANIZATION_ROLE")
+	const username = "example@gmail.com"
+	user := &entity.User{ID: uuid.New(), Username: username}
+	organizationA := &entity.Organization{ID: uuid.New(), Name: "A"}
+	organizationB := &entity.Organization{ID: uuid.New(), Name: "B"}
+	authRepository := &authRepositoryStub{users: map[string]*entity.User{username: user}}
+	organizationRepository := &organizationRepositoryStub{
+		organizations: map[uuid.UUID]*entity.Organization{organizationA.ID: organizationA, organizationB.ID: organizationB},
+		members:       []entity.OrganizationMember{{OrganizationID: organizationA.ID, UserID: user.ID, Role: entity.ORGANIZATION_ROLE_ADMIN}},
+	}
+	cache := cache.New(memory.New())
+
+	app := fiber.New()
+	const URL = "/test"
+	setUser := func(c *fiber.Ctx) error {
+		c.Locals(constant.LOCAL_KEY_ACCESS_TOKEN, &vo.AccessToken{RefreshToken: vo.RefreshToken{Username: username}})
+		return c.Next()
+	}
+	app.Get(URL, setUser, middleware.VerifyOrganization(cache, authRepository, organizationRepository), func(c *fiber.Ctx) error {
+		organizationID, _ := tenant.OrganizationID(c.UserContext())
+		return c.JSON(fiber.Map{"organizationId": organizationID, "role": tenant.Role(c.UserContext())})
+	})
+	app.Post(URL, setUser, middleware.VerifyOrganization(cache, authRepository, organizationRepository), middleware.RequireOrganizationRole(entity.ORGANIZATION_ROLE_OWNER, entity.ORGANIZATION_ROLE_ADMIN), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusCreated)
+	})
+	request := func(method string, organizationID string) (statusCode int, body string) {
+		req := httptest.NewRequest(method, URL, nil)
+		if organizationID != "" {
+			req.Header.Set(middleware.ORGANIZATION_HEADER, organizationID)
+		}
+		res, err := app.Test(req)
+		if !assert.NoError(t, err) {
+			return 0, ""
+		}
+		bodyBytes, _ := io.ReadAll(res.Body)
+		return res.StatusCode, string(bodyBytes)
+	}
+
+	t.Run("When the organization header is missing or invalid, should response status code 400", func(t *testing.T) {
+		statusCode, _ := request(fiber.MethodGet, "")
+		assert.Equal(t, fiber.StatusBadRequest, statusCode)
+		statusCode, _ = request(fiber.MethodGet, "not-a-uuid")
+		assert.Equal(t, fiber.StatusBadRequest, statusCode)
+	})
+
+	t.Run("When the organization doesn't exist, should response status code 404", func(t *testing.T) {
+		statusCode, _ := request(fiber.MethodGet, uuid.NewString())
+		assert.Equal(t, fiber.StatusNotFound, statusCode)
+	})
+
+	t.Run("When the user is a member of the organization, should scope the request to it with the role of the user", func(t *testing.T) {
+		statusCode, body := request(fiber.MethodGet, organizationA.ID.String())
+
+		assert.Equal(t, fiber.StatusOK, statusCode)
+		assert.JSONEq(t, `{"organizationId":"`+organizationA.ID.String()+`","role":"ADMIN"}`, body)
+	})
+
+	t.Run("When the role of the user is asked again, should read it from the cache", func(t *testing.T) {
+		lookups := organizationRepository.memberLookups
+
+		request(fiber.MethodGet, organizationA.ID.String())
+
+		assert.Equal(t, lookups, organizationRepository.memberLookups)
+	})
+
+	t.Run("When the user isn't a member of the organization, should scope the request to it without a role", func(t *testing.T) {
+		_, body := request(fiber.MethodGet, organizationB.ID.String())
+		_, cachedBody := request(fiber.MethodGet, organizationB.ID.String())
+
+		assert.JSONEq(t, `{"organizationId":"`+organizationB.ID.String()+`","role":""}`, body)
+		assert.JSONEq(t, body, cachedBody)
+	})
+
+	t.Run("When the user lacks the required role in the organization, should response status code 403", func(t *testing.T) {
+		statusCode, _ := request(fiber.MethodPost, organizationA.ID.String())
+		assert.Equal(t, fiber.StatusCreated, statusCode)
+		statusCode, _ = request(fiber.MethodPost, organizationB.ID.String())
+		assert.Equal(t, fiber.StatusForbidden, statusCode)
+	})
+}